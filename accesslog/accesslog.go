@@ -0,0 +1,135 @@
+package accesslog
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/models/v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// NewFileLogger returns a logger that appends one JSON line per log entry to
+// the file at path.
+func NewFileLogger(path string) (lager.Logger, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := lager.NewLogger("locket-access")
+	logger.RegisterSink(lager.NewWriterSink(file, lager.INFO))
+	return logger, nil
+}
+
+type accessLogger struct {
+	logger     lager.Logger
+	clock      clock.Clock
+	sampleRate float64
+	sample     func() float64
+}
+
+// NewInterceptor returns a unary interceptor that logs every failed RPC and
+// a sampleRate fraction of the successful ones.
+func NewInterceptor(logger lager.Logger, clock clock.Clock, sampleRate float64) grpc.UnaryServerInterceptor {
+	a := &accessLogger{
+		logger:     logger,
+		clock:      clock,
+		sampleRate: sampleRate,
+		sample:     rand.Float64,
+	}
+	return a.intercept
+}
+
+func (a *accessLogger) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := a.clock.Now()
+	resp, err := handler(ctx, req)
+
+	code := grpc.Code(err)
+	if code == codes.OK && a.sample() >= a.sampleRate {
+		return resp, err
+	}
+
+	data := lager.Data{
+		"method":     info.FullMethod,
+		"peer":       peerAddress(ctx),
+		"identity":   PeerIdentity(ctx),
+		"key":        requestKey(req),
+		"duration":   a.clock.Since(start).Nanoseconds(),
+		"status":     code.String(),
+		"start-time": start.Format(time.RFC3339Nano),
+	}
+	a.logger.Info("request", data)
+
+	return resp, err
+}
+
+// PeerIdentity returns the common name of the client certificate presented on
+// the connection, or an empty string if there is none.
+func PeerIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return ""
+	}
+
+	return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+}
+
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+func requestKey(req interface{}) string {
+	switch r := req.(type) {
+	case *models.LockGroupRequest:
+		keys := make([]string, 0, len(r.GetResources()))
+		for _, resource := range r.GetResources() {
+			keys = append(keys, resource.GetKey())
+		}
+		return strings.Join(keys, ",")
+	case *v2.LockGroupRequest:
+		keys := make([]string, 0, len(r.GetResources()))
+		for _, resource := range r.GetResources() {
+			keys = append(keys, resource.GetKey())
+		}
+		return strings.Join(keys, ",")
+	case *models.LockRequest:
+		return r.GetResource().GetKey()
+	case *models.ReleaseRequest:
+		return r.GetResource().GetKey()
+	case *models.FetchRequest:
+		return r.GetKey()
+	case *models.WaitersRequest:
+		return r.GetKey()
+	case *models.BarrierRequest:
+		return r.GetKey()
+	case *v2.LockRequest:
+		return r.GetResource().GetKey()
+	case *v2.ReleaseRequest:
+		return r.GetResource().GetKey()
+	case *v2.FetchRequest:
+		return r.GetKey()
+	case *admin.ExpireLockRequest:
+		return r.GetKey()
+	default:
+		return ""
+	}
+}
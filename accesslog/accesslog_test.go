@@ -0,0 +1,135 @@
+package accesslog_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/accesslog"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var _ = Describe("Interceptor", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeClock   *fakeclock.FakeClock
+		sampleRate  float64
+		ctx         context.Context
+		req         interface{}
+		handlerErr  error
+		interceptor grpc.UnaryServerInterceptor
+		info        *grpc.UnaryServerInfo
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("access")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		sampleRate = 1.0
+		handlerErr = nil
+		info = &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}
+		req = &models.LockRequest{
+			Resource: &models.Resource{Key: "some-key", Owner: "some-owner"},
+		}
+
+		ctx = peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{
+						{Subject: pkix.Name{CommonName: "some-client"}},
+					},
+				},
+			},
+		})
+	})
+
+	JustBeforeEach(func() {
+		interceptor = accesslog.NewInterceptor(logger, fakeClock, sampleRate)
+		_, err := interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			fakeClock.Increment(time.Second)
+			return &models.LockResponse{}, handlerErr
+		})
+		Expect(err).To(Equal(handlerErr))
+	})
+
+	It("logs one line describing the request", func() {
+		logs := logger.Logs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Message).To(Equal("access.request"))
+		Expect(logs[0].LogLevel).To(Equal(lager.INFO))
+		Expect(logs[0].Data).To(HaveKeyWithValue("method", "/models.Locket/Lock"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("peer", "10.0.0.1:1234"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("identity", "some-client"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("key", "some-key"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("duration", float64(time.Second)))
+		Expect(logs[0].Data).To(HaveKeyWithValue("status", "OK"))
+	})
+
+	Context("when the request is a fetch", func() {
+		BeforeEach(func() {
+			info.FullMethod = "/models.Locket/Fetch"
+			req = &models.FetchRequest{Key: "other-key"}
+		})
+
+		It("logs the requested key", func() {
+			Expect(logger.Logs()).To(HaveLen(1))
+			Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("key", "other-key"))
+		})
+	})
+
+	Context("when the sample rate is zero", func() {
+		BeforeEach(func() {
+			sampleRate = 0
+		})
+
+		It("does not log successful requests", func() {
+			Expect(logger.Logs()).To(BeEmpty())
+		})
+
+		Context("when the request fails", func() {
+			BeforeEach(func() {
+				handlerErr = models.ErrLockCollision
+			})
+
+			It("logs the request with its status", func() {
+				Expect(logger.Logs()).To(HaveLen(1))
+				Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("status", "AlreadyExists"))
+			})
+		})
+
+		Context("when the request fails with a non grpc error", func() {
+			BeforeEach(func() {
+				handlerErr = errors.New("boom")
+			})
+
+			It("logs the request as unknown", func() {
+				Expect(logger.Logs()).To(HaveLen(1))
+				Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("status", "Unknown"))
+			})
+		})
+	})
+
+	Context("when there is no peer information", func() {
+		BeforeEach(func() {
+			ctx = context.Background()
+		})
+
+		It("logs empty peer fields", func() {
+			Expect(logger.Logs()).To(HaveLen(1))
+			Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("peer", ""))
+			Expect(logger.Logs()[0].Data).To(HaveKeyWithValue("identity", ""))
+		})
+	})
+})
@@ -0,0 +1 @@
+package accesslog // import "code.cloudfoundry.org/locket/accesslog"
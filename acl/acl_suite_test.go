@@ -0,0 +1,13 @@
+package acl_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAcl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ACL Suite")
+}
@@ -0,0 +1,238 @@
+package acl
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/models/v2"
+	"code.cloudfoundry.org/locket/tokenauth"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+var ErrPermissionDenied = grpc.Errorf(codes.PermissionDenied, "not authorized")
+
+// Authorizer evaluates requests against a policy file. Run it as an ifrit
+// runner to reload the policy whenever the file changes.
+type Authorizer struct {
+	logger         lager.Logger
+	auditLogger    lager.Logger
+	clock          clock.Clock
+	policyPath     string
+	reloadInterval time.Duration
+
+	policy      *Policy
+	modTime     time.Time
+	policyMutex *sync.RWMutex
+}
+
+func NewAuthorizer(logger, auditLogger lager.Logger, clock clock.Clock, policyPath string, reloadInterval time.Duration) (*Authorizer, error) {
+	a := &Authorizer{
+		logger:         logger.Session("acl"),
+		auditLogger:    auditLogger.Session("acl-audit"),
+		clock:          clock,
+		policyPath:     policyPath,
+		reloadInterval: reloadInterval,
+		policyMutex:    &sync.RWMutex{},
+	}
+
+	err := a.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reload reads the policy file. The current policy is kept if the new one is
+// invalid.
+func (a *Authorizer) Reload() error {
+	info, err := os.Stat(a.policyPath)
+	if err != nil {
+		return err
+	}
+
+	policy, err := LoadPolicy(a.policyPath)
+	if err != nil {
+		return err
+	}
+
+	a.policyMutex.Lock()
+	a.policy = policy
+	a.modTime = info.ModTime()
+	a.policyMutex.Unlock()
+
+	a.logger.Info("loaded-policy", lager.Data{"path": a.policyPath, "rules": len(policy.Rules), "default-action": policy.DefaultAction})
+	return nil
+}
+
+func (a *Authorizer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := a.logger.Session("policy-reloader")
+
+	ticker := a.clock.NewTicker(a.reloadInterval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+		case <-ticker.C():
+			if !a.changed() {
+				continue
+			}
+
+			err := a.Reload()
+			if err != nil {
+				logger.Error("failed-to-reload-policy", err)
+			}
+		}
+	}
+}
+
+// NewInterceptor returns a unary interceptor that rejects requests the policy
+// does not allow with ErrPermissionDenied.
+func (a *Authorizer) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		err := a.authorize(ctx, operationName(info.FullMethod), req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamInterceptor checks every message a client sends on a stream
+// against the policy, and ends the stream with ErrPermissionDenied as soon as
+// one is not allowed.
+func (a *Authorizer) NewStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &authorizedStream{
+			ServerStream: stream,
+			authorizer:   a,
+			operation:    operationName(info.FullMethod),
+		})
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	authorizer *Authorizer
+	operation  string
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+	return s.authorizer.authorize(s.Context(), s.operation, m)
+}
+
+func (a *Authorizer) authorize(ctx context.Context, operation string, req interface{}) error {
+	identity := tokenauth.Identity(ctx)
+
+	// a lock group is only allowed if every key in it is
+	for _, key := range requestKeys(req) {
+		a.policyMutex.RLock()
+		allowed, rule := a.policy.Decide(identity, operation, key)
+		a.policyMutex.RUnlock()
+
+		a.auditLogger.Info("decision", lager.Data{
+			"identity":  identity,
+			"operation": operation,
+			"key":       key,
+			"allowed":   allowed,
+			"rule":      rule,
+		})
+
+		if !allowed {
+			return ErrPermissionDenied
+		}
+	}
+	return nil
+}
+
+func operationName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+func (a *Authorizer) changed() bool {
+	info, err := os.Stat(a.policyPath)
+	if err != nil {
+		a.logger.Error("failed-to-stat-policy", err)
+		return false
+	}
+
+	a.policyMutex.RLock()
+	defer a.policyMutex.RUnlock()
+	return !info.ModTime().Equal(a.modTime)
+}
+
+func requestKeys(req interface{}) []string {
+	switch r := req.(type) {
+	case *models.LockGroupRequest:
+		keys := make([]string, 0, len(r.GetResources()))
+		for _, resource := range r.GetResources() {
+			keys = append(keys, resource.GetKey())
+		}
+		return keys
+	case *v2.LockGroupRequest:
+		keys := make([]string, 0, len(r.GetResources()))
+		for _, resource := range r.GetResources() {
+			keys = append(keys, resource.GetKey())
+		}
+		return keys
+	case *models.KeepAliveRequest:
+		keys := make([]string, 0, len(r.GetLocks())+len(r.GetReleases()))
+		for _, lock := range r.GetLocks() {
+			keys = append(keys, lock.GetResource().GetKey())
+		}
+		return append(keys, r.GetReleases()...)
+	default:
+		return []string{requestKey(req)}
+	}
+}
+
+func requestKey(req interface{}) string {
+	switch r := req.(type) {
+	case *models.LockRequest:
+		return r.GetResource().GetKey()
+	case *models.ReleaseRequest:
+		return r.GetResource().GetKey()
+	case *models.FetchRequest:
+		return r.GetKey()
+	case *models.WaitersRequest:
+		return r.GetKey()
+	case *models.BarrierRequest:
+		return r.GetKey()
+	case *v2.LockRequest:
+		return r.GetResource().GetKey()
+	case *v2.ReleaseRequest:
+		return r.GetResource().GetKey()
+	case *v2.FetchRequest:
+		return r.GetKey()
+	case *admin.ExpireLockRequest:
+		return r.GetKey()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,212 @@
+package acl_test
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/acl"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/v2"
+	"code.cloudfoundry.org/locket/tokenauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ = Describe("Authorizer", func() {
+	var (
+		logger      *lagertest.TestLogger
+		auditLogger *lagertest.TestLogger
+		fakeClock   *fakeclock.FakeClock
+		policyPath  string
+		authorizer  *acl.Authorizer
+		ctx         context.Context
+		called      bool
+	)
+
+	writePolicy := func(policy string) {
+		Expect(ioutil.WriteFile(policyPath, []byte(policy), 0644)).To(Succeed())
+	}
+
+	intercept := func(method string, req interface{}) error {
+		interceptor := authorizer.NewInterceptor()
+		_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+		return err
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("acl")
+		auditLogger = lagertest.NewTestLogger("audit")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		called = false
+
+		f, err := ioutil.TempFile("", "policy")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+		policyPath = f.Name()
+		writePolicy(`{"rules": [{"identities": ["rep-*"], "operations": ["Lock"], "key_prefixes": ["presence/cell/"]}]}`)
+
+		authenticator, err := tokenauth.NewAuthenticator(logger, tokenauth.Config{
+			StaticTokens: map[string]string{"rep-z1-0": "rep-secret"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer rep-secret"))
+		authInterceptor := authenticator.NewInterceptor()
+		_, err = authInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(authedCtx context.Context, req interface{}) (interface{}, error) {
+			ctx = authedCtx
+			return nil, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		authorizer, err = acl.NewAuthorizer(logger, auditLogger, fakeClock, policyPath, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(policyPath)
+	})
+
+	It("allows requests the policy allows", func() {
+		err := intercept("/models.Locket/Lock", &models.LockRequest{Resource: &models.Resource{Key: "presence/cell/cell-1"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(called).To(BeTrue())
+		Expect(auditLogger).To(gbytes.Say(`"allowed":true`))
+	})
+
+	It("rejects requests the policy does not allow", func() {
+		err := intercept("/models.Locket/Lock", &models.LockRequest{Resource: &models.Resource{Key: "bbs"}})
+		Expect(err).To(Equal(acl.ErrPermissionDenied))
+		Expect(called).To(BeFalse())
+		Expect(auditLogger).To(gbytes.Say(`"allowed":false,"identity":"rep-z1-0","key":"bbs","operation":"Lock"`))
+	})
+
+	It("applies the policy to the v2 api", func() {
+		err := intercept("/locket.v2.Locket/Lock", &v2.LockRequest{Resource: &v2.Resource{Key: "presence/cell/cell-1"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = intercept("/locket.v2.Locket/Lock", &v2.LockRequest{Resource: &v2.Resource{Key: "bbs"}})
+		Expect(err).To(Equal(acl.ErrPermissionDenied))
+	})
+
+	It("requires every key of a lock group to be allowed", func() {
+		writePolicy(`{"rules": [{"identities": ["rep-*"], "operations": ["LockGroup"], "key_prefixes": ["presence/cell/"]}]}`)
+		Expect(authorizer.Reload()).To(Succeed())
+
+		err := intercept("/models.Locket/LockGroup", &models.LockGroupRequest{Resources: []*models.Resource{
+			{Key: "presence/cell/cell-1"},
+			{Key: "presence/cell/cell-2"},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+
+		called = false
+		err = intercept("/models.Locket/LockGroup", &models.LockGroupRequest{Resources: []*models.Resource{
+			{Key: "presence/cell/cell-1"},
+			{Key: "bbs"},
+		}})
+		Expect(err).To(Equal(acl.ErrPermissionDenied))
+		Expect(called).To(BeFalse())
+	})
+
+	It("does not check health checks", func() {
+		Expect(intercept("/grpc.health.v1.Health/Check", nil)).To(Succeed())
+	})
+
+	Context("on streams", func() {
+		var received []error
+
+		streamIntercept := func(method string, reqs ...*models.KeepAliveRequest) error {
+			received = nil
+			interceptor := authorizer.NewStreamInterceptor()
+			return interceptor(nil, &requestStream{ctx: ctx, requests: reqs}, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+				for range reqs {
+					err := stream.RecvMsg(&models.KeepAliveRequest{})
+					received = append(received, err)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+
+		BeforeEach(func() {
+			writePolicy(`{"rules": [{"identities": ["rep-*"], "operations": ["KeepAlive"], "key_prefixes": ["presence/cell/"]}]}`)
+			Expect(authorizer.Reload()).To(Succeed())
+		})
+
+		It("checks every key added or released on the stream", func() {
+			err := streamIntercept("/models.Locket/KeepAlive",
+				&models.KeepAliveRequest{Locks: []*models.LockRequest{{Resource: &models.Resource{Key: "presence/cell/cell-1"}}}},
+				&models.KeepAliveRequest{},
+				&models.KeepAliveRequest{Releases: []string{"bbs"}},
+			)
+			Expect(err).To(Equal(acl.ErrPermissionDenied))
+			Expect(received).To(Equal([]error{nil, nil, acl.ErrPermissionDenied}))
+			Expect(auditLogger).To(gbytes.Say(`"allowed":false,"identity":"rep-z1-0","key":"bbs","operation":"KeepAlive"`))
+		})
+	})
+
+	Context("when the policy file changes", func() {
+		var process ifrit.Process
+
+		BeforeEach(func() {
+			process = ginkgomon.Invoke(authorizer)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Kill(process)
+		})
+
+		It("reloads the policy", func() {
+			req := &models.FetchRequest{Key: "bbs"}
+			Expect(intercept("/models.Locket/Fetch", req)).To(Equal(acl.ErrPermissionDenied))
+
+			writePolicy(`{"rules": [{"identities": ["rep-*"], "operations": ["*"]}]}`)
+			future := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(policyPath, future, future)).To(Succeed())
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(func() error { return intercept("/models.Locket/Fetch", req) }).Should(Succeed())
+		})
+
+		It("keeps the old policy if the new one is invalid", func() {
+			writePolicy(`{"default_action": "maybe"}`)
+			future := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(policyPath, future, future)).To(Succeed())
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(logger).Should(gbytes.Say("failed-to-reload-policy"))
+
+			err := intercept("/models.Locket/Lock", &models.LockRequest{Resource: &models.Resource{Key: "presence/cell/cell-1"}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
+
+type requestStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*models.KeepAliveRequest
+}
+
+func (s *requestStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *requestStream) RecvMsg(m interface{}) error {
+	*m.(*models.KeepAliveRequest) = *s.requests[0]
+	s.requests = s.requests[1:]
+	return nil
+}
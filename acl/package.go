@@ -0,0 +1 @@
+package acl // import "code.cloudfoundry.org/locket/acl"
@@ -0,0 +1,137 @@
+package acl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+
+	anyOperation = "*"
+)
+
+var ErrInvalidDefaultAction = errors.New("default_action must be allow or deny")
+
+// Policy maps client identities to the operations they may perform on key
+// prefixes. Identities are glob patterns such as "rep-*". A client that
+// matches at least one rule may only do what its rules allow; any other
+// client gets the default action.
+type Policy struct {
+	DefaultAction string `json:"default_action,omitempty"`
+	Rules         []Rule `json:"rules"`
+}
+
+type Rule struct {
+	Identities  []string `json:"identities"`
+	Operations  []string `json:"operations"`
+	KeyPrefixes []string `json:"key_prefixes,omitempty"`
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(policyPath string) (*Policy, error) {
+	data, err := ioutil.ReadFile(policyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	err = json.Unmarshal(data, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.validate()
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Decide returns whether identity may perform operation on key, and the index
+// of the rule that allowed it or -1. Operations that do not act on a single
+// key, such as FetchAll, pass an empty key and only match rules without key
+// prefixes.
+func (p *Policy) Decide(identity, operation, key string) (bool, int) {
+	identityMatched := false
+
+	for i, rule := range p.Rules {
+		if !rule.matchesIdentity(identity) {
+			continue
+		}
+		identityMatched = true
+
+		if rule.matchesOperation(operation) && rule.matchesKey(key) {
+			return true, i
+		}
+	}
+
+	if identityMatched {
+		return false, -1
+	}
+	return p.DefaultAction == ActionAllow, -1
+}
+
+func (p *Policy) validate() error {
+	switch p.DefaultAction {
+	case "":
+		p.DefaultAction = ActionDeny
+	case ActionAllow, ActionDeny:
+	default:
+		return ErrInvalidDefaultAction
+	}
+
+	for i, rule := range p.Rules {
+		if len(rule.Identities) == 0 || len(rule.Operations) == 0 {
+			return fmt.Errorf("rule %d must have identities and operations", i)
+		}
+
+		for _, pattern := range rule.Identities {
+			_, err := path.Match(pattern, "")
+			if err != nil {
+				return fmt.Errorf("rule %d has an invalid identity pattern %q: %s", i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) matchesIdentity(identity string) bool {
+	for _, pattern := range r.Identities {
+		if matched, _ := path.Match(pattern, identity); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchesOperation(operation string) bool {
+	for _, op := range r.Operations {
+		if op == anyOperation || op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchesKey(key string) bool {
+	if len(r.KeyPrefixes) == 0 {
+		return true
+	}
+
+	if key == "" {
+		return false
+	}
+
+	for _, prefix := range r.KeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
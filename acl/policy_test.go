@@ -0,0 +1,116 @@
+package acl_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"code.cloudfoundry.org/locket/acl"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	var policy *acl.Policy
+
+	BeforeEach(func() {
+		policy = &acl.Policy{
+			DefaultAction: acl.ActionDeny,
+			Rules: []acl.Rule{
+				{Identities: []string{"rep-*"}, Operations: []string{"Lock", "Release"}, KeyPrefixes: []string{"presence/cell/"}},
+				{Identities: []string{"bbs"}, Operations: []string{"*"}},
+			},
+		}
+	})
+
+	It("allows operations on keys under the rule's prefixes", func() {
+		allowed, rule := policy.Decide("rep-z1-0", "Lock", "presence/cell/cell-1")
+		Expect(allowed).To(BeTrue())
+		Expect(rule).To(Equal(0))
+	})
+
+	It("allows keyless operations through rules without prefixes", func() {
+		allowed, rule := policy.Decide("bbs", "FetchAll", "")
+		Expect(allowed).To(BeTrue())
+		Expect(rule).To(Equal(1))
+	})
+
+	It("denies keys outside the rule's prefixes", func() {
+		allowed, rule := policy.Decide("rep-z1-0", "Lock", "bbs")
+		Expect(allowed).To(BeFalse())
+		Expect(rule).To(Equal(-1))
+	})
+
+	It("denies operations the rule does not list", func() {
+		allowed, _ := policy.Decide("rep-z1-0", "Fetch", "presence/cell/cell-1")
+		Expect(allowed).To(BeFalse())
+	})
+
+	It("does not allow keyless operations through rules with prefixes", func() {
+		policy.Rules[0].Operations = append(policy.Rules[0].Operations, "FetchAll")
+		allowed, _ := policy.Decide("rep-z1-0", "FetchAll", "")
+		Expect(allowed).To(BeFalse())
+	})
+
+	It("applies the default action to unknown identities", func() {
+		allowed, _ := policy.Decide("auctioneer", "Lock", "auctioneer")
+		Expect(allowed).To(BeFalse())
+
+		policy.DefaultAction = acl.ActionAllow
+		allowed, _ = policy.Decide("auctioneer", "Lock", "auctioneer")
+		Expect(allowed).To(BeTrue())
+	})
+
+	Describe("LoadPolicy", func() {
+		var policyPath, policyJSON string
+
+		JustBeforeEach(func() {
+			f, err := ioutil.TempFile("", "policy")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = f.WriteString(policyJSON)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+			policyPath = f.Name()
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(policyPath)
+		})
+
+		Context("with a valid policy", func() {
+			BeforeEach(func() {
+				policyJSON = `{"rules": [{"identities": ["rep-*"], "operations": ["Lock"], "key_prefixes": ["presence/cell/"]}]}`
+			})
+
+			It("defaults to denying unknown identities", func() {
+				policy, err := acl.LoadPolicy(policyPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy.DefaultAction).To(Equal(acl.ActionDeny))
+				Expect(policy.Rules).To(Equal([]acl.Rule{
+					{Identities: []string{"rep-*"}, Operations: []string{"Lock"}, KeyPrefixes: []string{"presence/cell/"}},
+				}))
+			})
+		})
+
+		Context("with an invalid default action", func() {
+			BeforeEach(func() {
+				policyJSON = `{"default_action": "maybe", "rules": []}`
+			})
+
+			It("returns an error", func() {
+				_, err := acl.LoadPolicy(policyPath)
+				Expect(err).To(Equal(acl.ErrInvalidDefaultAction))
+			})
+		})
+
+		Context("with a rule without operations", func() {
+			BeforeEach(func() {
+				policyJSON = `{"rules": [{"identities": ["bbs"]}]}`
+			})
+
+			It("returns an error", func() {
+				_, err := acl.LoadPolicy(policyPath)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,198 @@
+package allowlist
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	"google.golang.org/grpc/credentials"
+)
+
+var ErrSourceNotAllowed = errors.New("connection source is not allowed")
+
+type Config struct {
+	CIDRs      []string            `json:"cidrs,omitempty"`
+	Identities map[string][]string `json:"identities,omitempty"`
+}
+
+// Enabled returns true if any network restrictions are configured.
+func (c Config) Enabled() bool {
+	return len(c.CIDRs) > 0 || len(c.Identities) > 0
+}
+
+// Allowlist restricts the networks connections may come from. Connections
+// from outside the global CIDRs are closed as soon as they are accepted, and
+// clients whose certificate common name has its own CIDRs are rejected
+// during the TLS handshake if they connect from anywhere else.
+type Allowlist struct {
+	logger     lager.Logger
+	networks   []*net.IPNet
+	identities map[string][]*net.IPNet
+}
+
+func New(logger lager.Logger, config Config) (*Allowlist, error) {
+	networks, err := parseCIDRs(config.CIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := make(map[string][]*net.IPNet, len(config.Identities))
+	for identity, cidrs := range config.Identities {
+		identities[identity], err = parseCIDRs(cidrs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Allowlist{
+		logger:     logger.Session("allowlist"),
+		networks:   networks,
+		identities: identities,
+	}, nil
+}
+
+// Allowed returns true if a client with the given identity may connect from
+// addr. An empty identity only checks the global CIDRs.
+func (a *Allowlist) Allowed(addr net.Addr, identity string) bool {
+	ip := addrIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	if len(a.networks) > 0 && !contains(a.networks, ip) {
+		return false
+	}
+
+	if networks, ok := a.identities[identity]; ok && identity != "" {
+		return contains(networks, ip)
+	}
+	return true
+}
+
+// Listener wraps lis so that connections from outside the global CIDRs are
+// closed without being handed to the server.
+func (a *Allowlist) Listener(lis net.Listener) net.Listener {
+	return &listener{Listener: lis, allowlist: a}
+}
+
+// Credentials wraps creds so that the server handshake fails for clients
+// connecting from outside the CIDRs configured for their identity.
+func (a *Allowlist) Credentials(creds credentials.TransportCredentials) credentials.TransportCredentials {
+	return &transportCredentials{TransportCredentials: creds, allowlist: a}
+}
+
+// Handler wraps h so that http requests are rejected unless their client
+// may connect from the request's remote address. It is used for listeners
+// that terminate TLS themselves instead of through Credentials.
+func (a *Allowlist) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := ""
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			identity = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+
+		var addr net.Addr
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err == nil {
+			addr = &net.TCPAddr{IP: net.ParseIP(host)}
+		}
+
+		if !a.Allowed(addr, identity) {
+			a.logger.Info("rejected-identity", lager.Data{"address": r.RemoteAddr, "identity": identity})
+			http.Error(w, ErrSourceNotAllowed.Error(), http.StatusForbidden)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+type listener struct {
+	net.Listener
+	allowlist *Allowlist
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.allowlist.Allowed(conn.RemoteAddr(), "") {
+			return conn, nil
+		}
+
+		l.allowlist.logger.Info("rejected-connection", lager.Data{"address": conn.RemoteAddr().String()})
+		conn.Close()
+	}
+}
+
+type transportCredentials struct {
+	credentials.TransportCredentials
+	allowlist *Allowlist
+}
+
+func (c *transportCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, authInfo, err := c.TransportCredentials.ServerHandshake(rawConn)
+	if err != nil {
+		return conn, authInfo, err
+	}
+
+	identity := ""
+	if tlsInfo, ok := authInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		identity = tlsInfo.State.PeerCertificates[0].Subject.CommonName
+	}
+
+	if !c.allowlist.Allowed(rawConn.RemoteAddr(), identity) {
+		c.allowlist.logger.Info("rejected-identity", lager.Data{"address": rawConn.RemoteAddr().String(), "identity": identity})
+		conn.Close()
+		return nil, nil, ErrSourceNotAllowed
+	}
+
+	return conn, authInfo, nil
+}
+
+func (c *transportCredentials) Clone() credentials.TransportCredentials {
+	return &transportCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		allowlist:            c.allowlist,
+	}
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case nil:
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
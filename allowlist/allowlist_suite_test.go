@@ -0,0 +1,13 @@
+package allowlist_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAllowlist(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Allowlist Suite")
+}
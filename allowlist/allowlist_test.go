@@ -0,0 +1,165 @@
+package allowlist_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/allowlist"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Allowlist", func() {
+	var (
+		logger *lagertest.TestLogger
+		config allowlist.Config
+		list   *allowlist.Allowlist
+		err    error
+	)
+
+	addr := func(ip string) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("allowlist")
+		config = allowlist.Config{
+			CIDRs: []string{"10.0.0.0/8", "127.0.0.1/32"},
+			Identities: map[string][]string{
+				"auctioneer": {"10.0.16.0/20"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		list, err = allowlist.New(logger, config)
+	})
+
+	It("allows connections from the configured networks", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Allowed(addr("10.1.2.3"), "")).To(BeTrue())
+		Expect(list.Allowed(addr("127.0.0.1"), "bbs")).To(BeTrue())
+	})
+
+	It("rejects connections from other networks", func() {
+		Expect(list.Allowed(addr("192.168.1.1"), "")).To(BeFalse())
+		Expect(list.Allowed(addr("127.0.0.2"), "bbs")).To(BeFalse())
+	})
+
+	It("restricts identities with their own networks", func() {
+		Expect(list.Allowed(addr("10.0.17.1"), "auctioneer")).To(BeTrue())
+		Expect(list.Allowed(addr("10.1.2.3"), "auctioneer")).To(BeFalse())
+	})
+
+	Context("when only identities are restricted", func() {
+		BeforeEach(func() {
+			config.CIDRs = nil
+		})
+
+		It("allows anyone else from anywhere", func() {
+			Expect(list.Allowed(addr("192.168.1.1"), "bbs")).To(BeTrue())
+			Expect(list.Allowed(addr("192.168.1.1"), "auctioneer")).To(BeFalse())
+		})
+	})
+
+	Context("when a cidr is invalid", func() {
+		BeforeEach(func() {
+			config.Identities["bbs"] = []string{"not-a-cidr"}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Listener", func() {
+		var lis net.Listener
+
+		JustBeforeEach(func() {
+			rawListener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			lis = list.Listener(rawListener)
+
+			go func() {
+				defer GinkgoRecover()
+				for {
+					conn, err := lis.Accept()
+					if err != nil {
+						return
+					}
+					conn.Write([]byte("hi"))
+					conn.Close()
+				}
+			}()
+		})
+
+		AfterEach(func() {
+			lis.Close()
+		})
+
+		readFrom := func() (int, error) {
+			conn, err := net.Dial("tcp", lis.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			return conn.Read(make([]byte, 2))
+		}
+
+		It("hands allowed connections to the server", func() {
+			n, err := readFrom()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(2))
+		})
+
+		Context("when the source is not allowed", func() {
+			BeforeEach(func() {
+				config.CIDRs = []string{"10.0.0.0/8"}
+			})
+
+			It("closes the connection", func() {
+				_, err := readFrom()
+				Expect(err).To(HaveOccurred())
+				Eventually(logger).Should(gbytes.Say("rejected-connection"))
+			})
+		})
+	})
+
+	Describe("Handler", func() {
+		serve := func(remoteAddr, identity string) *httptest.ResponseRecorder {
+			handler := list.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			}))
+
+			req := httptest.NewRequest("POST", "/models.Locket/Fetch", nil)
+			req.RemoteAddr = remoteAddr
+			if identity != "" {
+				req.TLS = &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: identity}}},
+				}
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		It("serves allowed requests", func() {
+			Expect(serve("10.0.16.1:1234", "auctioneer").Code).To(Equal(http.StatusTeapot))
+			Expect(serve("10.1.0.1:1234", "").Code).To(Equal(http.StatusTeapot))
+		})
+
+		It("rejects requests from outside the client's networks", func() {
+			Expect(serve("192.168.0.1:1234", "").Code).To(Equal(http.StatusForbidden))
+			Expect(serve("10.1.0.1:1234", "auctioneer").Code).To(Equal(http.StatusForbidden))
+			Expect(logger).To(gbytes.Say("rejected-identity"))
+		})
+	})
+})
@@ -0,0 +1 @@
+package allowlist // import "code.cloudfoundry.org/locket/allowlist"
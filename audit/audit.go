@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// ErrNoACLPolicy is returned for audit sinks configured without an ACL
+// policy, whose decisions are the only audit events, so that the sinks are
+// not left silently empty.
+var ErrNoACLPolicy = errors.New("audit sinks need an acl policy file")
+
+// Config sends audit events to syslog, to a rotating local file, or both,
+// in addition to the server's own log.
+type Config struct {
+	Syslog SyslogConfig `json:"syslog"`
+	File   FileConfig   `json:"file"`
+}
+
+// Enabled returns true if any audit sink is configured.
+func (c Config) Enabled() bool {
+	return c.Syslog.Address != "" || c.File.Path != ""
+}
+
+// FileConfig is a local file of audit events, one JSON line each, that is
+// rotated once it reaches MaxSizeInMegabytes. MaxBackups and MaxAgeInDays
+// limit the rotated files that are kept, and are unlimited when 0.
+type FileConfig struct {
+	Path               string `json:"path,omitempty"`
+	MaxSizeInMegabytes int    `json:"max_size_in_megabytes,omitempty"`
+	MaxBackups         int    `json:"max_backups,omitempty"`
+	MaxAgeInDays       int    `json:"max_age_in_days,omitempty"`
+	Compress           bool   `json:"compress,omitempty"`
+}
+
+const DefaultMaxSizeInMegabytes = 100
+
+// NewFileSink returns a sink that appends audit events to the configured
+// file, rotating it as it grows.
+func NewFileSink(config FileConfig) lager.Sink {
+	maxSize := config.MaxSizeInMegabytes
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSizeInMegabytes
+	}
+
+	return lager.NewWriterSink(&lumberjack.Logger{
+		Filename:   config.Path,
+		MaxSize:    maxSize,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAgeInDays,
+		Compress:   config.Compress,
+	}, lager.INFO)
+}
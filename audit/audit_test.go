@@ -0,0 +1,56 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/audit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config", func() {
+	It("is enabled when a sink is configured", func() {
+		Expect(audit.Config{}.Enabled()).To(BeFalse())
+		Expect(audit.Config{File: audit.FileConfig{Path: "audit.log"}}.Enabled()).To(BeTrue())
+		Expect(audit.Config{Syslog: audit.SyslogConfig{Address: "syslog:6514"}}.Enabled()).To(BeTrue())
+	})
+})
+
+var _ = Describe("FileSink", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "audit")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("appends audit events as JSON lines", func() {
+		path := filepath.Join(tmpDir, "audit.log")
+		logger := lager.NewLogger("locket")
+		logger.RegisterSink(audit.NewFileSink(audit.FileConfig{Path: path}))
+
+		logger.Session("acl-audit").Info("decision", lager.Data{"identity": "bbs", "allowed": true})
+		logger.Session("acl-audit").Info("decision", lager.Data{"identity": "tps", "allowed": false})
+		logger.Debug("not-an-audit-event")
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		var event lager.LogFormat
+		Expect(json.Unmarshal([]byte(lines[1]), &event)).To(Succeed())
+		Expect(event.Message).To(Equal("locket.acl-audit.decision"))
+		Expect(event.Data).To(HaveKeyWithValue("identity", "tps"))
+	})
+})
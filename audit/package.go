@@ -0,0 +1 @@
+package audit // import "code.cloudfoundry.org/locket/audit"
@@ -0,0 +1,248 @@
+package audit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	DefaultAppName = "locket"
+
+	// rfc5424TimeFormat is RFC3339 with at most the six fractional digits
+	// RFC 5424 allows.
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+	// facilityLogAudit is the RFC 5424 "log audit" facility.
+	facilityLogAudit = 13
+
+	syslogQueueSize   = 4096
+	syslogDialTimeout = 10 * time.Second
+	syslogMinBackoff  = time.Second
+	syslogMaxBackoff  = 30 * time.Second
+	maxMessageIDLen   = 32
+)
+
+var ErrInvalidCACert = errors.New("syslog ca cert file has no certificates")
+
+// SyslogConfig is a syslog server that receives audit events as RFC 5424
+// messages over TLS, framed as in RFC 5425. CertFile and KeyFile are the
+// client certificate, for servers that require one.
+type SyslogConfig struct {
+	Address    string `json:"address,omitempty"`
+	CACertFile string `json:"ca_cert_file,omitempty"`
+	CertFile   string `json:"cert_file,omitempty"`
+	KeyFile    string `json:"key_file,omitempty"`
+	AppName    string `json:"app_name,omitempty"`
+}
+
+// SyslogSink queues audit events for a syslog server, so that a slow or
+// unreachable server does not hold up requests. Run it as an ifrit runner to
+// send them. Events are dropped when the queue is full, and the number
+// dropped is logged once the server is reachable again.
+type SyslogSink struct {
+	logger    lager.Logger
+	clock     clock.Clock
+	address   string
+	tlsConfig *tls.Config
+	hostname  string
+	appName   string
+	procID    string
+
+	messages chan []byte
+	dropped  int64
+}
+
+func NewSyslogSink(logger lager.Logger, clock clock.Clock, config SyslogConfig) (*SyslogSink, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CACertFile != "" {
+		caCert, err := ioutil.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, ErrInvalidCACert
+		}
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	appName := config.AppName
+	if appName == "" {
+		appName = DefaultAppName
+	}
+
+	return &SyslogSink{
+		logger:    logger.Session("audit-syslog", lager.Data{"address": config.Address}),
+		clock:     clock,
+		address:   config.Address,
+		tlsConfig: tlsConfig,
+		hostname:  hostname,
+		appName:   appName,
+		procID:    fmt.Sprintf("%d", os.Getpid()),
+		messages:  make(chan []byte, syslogQueueSize),
+	}, nil
+}
+
+func (s *SyslogSink) Log(log lager.LogFormat) {
+	select {
+	case s.messages <- s.format(log):
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+func (s *SyslogSink) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := s.logger
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	close(ready)
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	var pending []byte
+	backoff := syslogMinBackoff
+
+	for {
+		if pending == nil {
+			select {
+			case sig := <-signals:
+				logger.Info("signalled", lager.Data{"signal": sig})
+				if conn != nil {
+					s.flush(logger, conn)
+				}
+				return nil
+			case pending = <-s.messages:
+			}
+		}
+
+		var err error
+		if conn == nil {
+			conn, err = tls.DialWithDialer(&net.Dialer{Timeout: syslogDialTimeout}, "tcp", s.address, s.tlsConfig)
+			if err != nil {
+				logger.Error("failed-to-connect", err, lager.Data{"retry-in": backoff.String()})
+				conn = nil
+			}
+		}
+
+		if conn != nil {
+			err = s.write(conn, pending)
+			if err != nil {
+				logger.Error("failed-to-send", err, lager.Data{"retry-in": backoff.String()})
+				conn.Close()
+				conn = nil
+			}
+		}
+
+		if err != nil {
+			timer := s.clock.NewTimer(backoff)
+			select {
+			case sig := <-signals:
+				timer.Stop()
+				logger.Info("signalled", lager.Data{"signal": sig})
+				return nil
+			case <-timer.C():
+			}
+
+			backoff *= 2
+			if backoff > syslogMaxBackoff {
+				backoff = syslogMaxBackoff
+			}
+			continue
+		}
+
+		pending = nil
+		backoff = syslogMinBackoff
+		if dropped := atomic.SwapInt64(&s.dropped, 0); dropped > 0 {
+			logger.Error("dropped-audit-events", errors.New("audit syslog queue is full"), lager.Data{"dropped": dropped})
+		}
+	}
+}
+
+// flush sends the events still queued on shutdown, giving up at the first
+// failure.
+func (s *SyslogSink) flush(logger lager.Logger, conn net.Conn) {
+	for {
+		select {
+		case message := <-s.messages:
+			err := s.write(conn, message)
+			if err != nil {
+				logger.Error("failed-to-flush", err)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// write sends message with octet counting framing.
+func (s *SyslogSink) write(conn net.Conn, message []byte) error {
+	conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+	_, err := fmt.Fprintf(conn, "%d %s", len(message), message)
+	return err
+}
+
+// format returns log as an RFC 5424 message from the log audit facility,
+// with the log message as the MSGID and the JSON log line as the MSG.
+func (s *SyslogSink) format(log lager.LogFormat) []byte {
+	messageID := log.Message
+	if len(messageID) > maxMessageIDLen {
+		messageID = messageID[:maxMessageIDLen]
+	}
+	if messageID == "" {
+		messageID = "-"
+	}
+
+	priority := facilityLogAudit*8 + severity(log.LogLevel)
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s - ",
+		priority,
+		s.clock.Now().UTC().Format(rfc5424TimeFormat),
+		s.hostname,
+		s.appName,
+		s.procID,
+		messageID,
+	)
+	return append([]byte(header), log.ToJSON()...)
+}
+
+func severity(level lager.LogLevel) int {
+	switch level {
+	case lager.DEBUG:
+		return 7
+	case lager.INFO:
+		return 6
+	case lager.ERROR:
+		return 3
+	default:
+		return 2
+	}
+}
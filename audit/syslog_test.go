@@ -0,0 +1,185 @@
+package audit_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/audit"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("SyslogSink", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		tmpDir    string
+		listener  net.Listener
+		received  chan string
+		config    audit.SyslogConfig
+
+		sink    *audit.SyslogSink
+		process ifrit.Process
+	)
+
+	// readFrames reads octet counted messages from conn until it is closed
+	readFrames := func(conn net.Conn) {
+		defer GinkgoRecover()
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(length[:len(length)-1])
+			Expect(err).NotTo(HaveOccurred())
+
+			message := make([]byte, n)
+			_, err = io.ReadFull(reader, message)
+			if err != nil {
+				return
+			}
+			received <- string(message)
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		logger = lagertest.NewTestLogger("audit")
+		fakeClock = fakeclock.NewFakeClock(time.Date(2026, 10, 16, 12, 0, 0, 123456789, time.UTC))
+		received = make(chan string, 10)
+
+		tmpDir, err = ioutil.TempDir("", "audit-syslog")
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "syslog"},
+			IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		Expect(err).NotTo(HaveOccurred())
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+		caFile := filepath.Join(tmpDir, "ca.crt")
+		Expect(ioutil.WriteFile(caFile, certPEM, 0600)).To(Succeed())
+
+		serverCert, err := tls.X509KeyPair(certPEM, keyPEM)
+		Expect(err).NotTo(HaveOccurred())
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}})
+		Expect(err).NotTo(HaveOccurred())
+
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go readFrames(conn)
+			}
+		}()
+
+		config = audit.SyslogConfig{
+			Address:    listener.Addr().String(),
+			CACertFile: caFile,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		sink, err = audit.NewSyslogSink(logger, fakeClock, config)
+		Expect(err).NotTo(HaveOccurred())
+		process = ginkgomon.Invoke(sink)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+		listener.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("sends audit events as RFC 5424 messages from the log audit facility", func() {
+		sink.Log(lager.LogFormat{
+			Message:  "locket.acl-audit.decision",
+			LogLevel: lager.INFO,
+			Data:     lager.Data{"identity": "bbs"},
+		})
+
+		var message string
+		Eventually(received).Should(Receive(&message))
+
+		hostname, _ := os.Hostname()
+		header := fmt.Sprintf("<110>1 2026-10-16T12:00:00.123456Z %s locket %d locket.acl-audit.decision - ", regexp.QuoteMeta(hostname), os.Getpid())
+		Expect(message).To(MatchRegexp("^" + header + `\{.*"identity":"bbs".*\}$`))
+	})
+
+	Context("when an app name is configured", func() {
+		BeforeEach(func() {
+			config.AppName = "locket-z1"
+		})
+
+		It("uses it", func() {
+			sink.Log(lager.LogFormat{Message: "decision", LogLevel: lager.ERROR})
+
+			var message string
+			Eventually(received).Should(Receive(&message))
+			Expect(message).To(MatchRegexp(`^<107>1 \S+ \S+ locket-z1 `))
+		})
+	})
+
+	Context("when the server is unreachable", func() {
+		BeforeEach(func() {
+			listener.Close()
+		})
+
+		It("keeps the event and retries with backoff", func() {
+			sink.Log(lager.LogFormat{Message: "decision", LogLevel: lager.INFO})
+			Eventually(logger).Should(gbytes.Say(`failed-to-connect.*"retry-in":"1s"`))
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(logger).Should(gbytes.Say(`failed-to-connect.*"retry-in":"2s"`))
+		})
+	})
+
+	It("rejects a CA file without certificates", func() {
+		badCA := filepath.Join(tmpDir, "bad.crt")
+		Expect(ioutil.WriteFile(badCA, []byte("not a cert"), 0600)).To(Succeed())
+
+		_, err := audit.NewSyslogSink(logger, fakeClock, audit.SyslogConfig{Address: config.Address, CACertFile: badCA})
+		Expect(err).To(Equal(audit.ErrInvalidCACert))
+	})
+})
@@ -0,0 +1,80 @@
+// This file was generated by counterfeiter
+package backupfakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/backup"
+)
+
+type FakeExtender struct {
+	ExtendExpiriesStub        func(logger lager.Logger, by time.Duration) error
+	extendExpiriesMutex       sync.RWMutex
+	extendExpiriesArgsForCall []struct {
+		logger lager.Logger
+		by     time.Duration
+	}
+	extendExpiriesReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeExtender) ExtendExpiries(logger lager.Logger, by time.Duration) error {
+	fake.extendExpiriesMutex.Lock()
+	fake.extendExpiriesArgsForCall = append(fake.extendExpiriesArgsForCall, struct {
+		logger lager.Logger
+		by     time.Duration
+	}{logger, by})
+	fake.recordInvocation("ExtendExpiries", []interface{}{logger, by})
+	fake.extendExpiriesMutex.Unlock()
+	if fake.ExtendExpiriesStub != nil {
+		return fake.ExtendExpiriesStub(logger, by)
+	} else {
+		return fake.extendExpiriesReturns.result1
+	}
+}
+
+func (fake *FakeExtender) ExtendExpiriesCallCount() int {
+	fake.extendExpiriesMutex.RLock()
+	defer fake.extendExpiriesMutex.RUnlock()
+	return len(fake.extendExpiriesArgsForCall)
+}
+
+func (fake *FakeExtender) ExtendExpiriesArgsForCall(i int) (lager.Logger, time.Duration) {
+	fake.extendExpiriesMutex.RLock()
+	defer fake.extendExpiriesMutex.RUnlock()
+	return fake.extendExpiriesArgsForCall[i].logger, fake.extendExpiriesArgsForCall[i].by
+}
+
+func (fake *FakeExtender) ExtendExpiriesReturns(result1 error) {
+	fake.ExtendExpiriesStub = nil
+	fake.extendExpiriesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExtender) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.extendExpiriesMutex.RLock()
+	defer fake.extendExpiriesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeExtender) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ backup.Extender = new(FakeExtender)
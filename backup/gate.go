@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+)
+
+// DefaultQuiesceTimeout is below the default ttl of locks, so that a client
+// does not give up on a heartbeat that the gate holds until it times out.
+const DefaultQuiesceTimeout = 10 * time.Second
+
+// ErrExpirationPaused is returned for the expirer's writes while expiration
+// is paused after a quiesce.
+var ErrExpirationPaused = errors.New("expiration is paused by a backup")
+
+//go:generate counterfeiter . Extender
+
+// Extender pushes back the expiry of every lock.
+type Extender interface {
+	ExtendExpiries(logger lager.Logger, by time.Duration) error
+}
+
+// Gate holds lock writes while a backup or restore is in progress, so that
+// the backup captures the locks between heartbeats rather than in the
+// middle of one. Writes wait rather than fail, so that a short backup only
+// delays heartbeats. In case the unlock never comes, the gate opens by
+// itself after the quiesce timeout.
+//
+// So that no lock expires because its heartbeats were held, expiration is
+// paused from the quiesce until as long after the unlock as writes were
+// held, and the expiry of every lock is pushed back by that long before the
+// held writes go through.
+type Gate struct {
+	logger         lager.Logger
+	lockDB         db.LockDB
+	extender       Extender
+	clock          clock.Clock
+	quiesceTimeout time.Duration
+
+	writes sync.RWMutex
+
+	mutex      sync.Mutex
+	quiesced   bool
+	quiescedAt time.Time
+	unlocked   chan struct{}
+	timer      clock.Timer
+
+	// pause is separate from mutex, which Quiesce holds while it waits for
+	// the writes in flight
+	pause            sync.Mutex
+	quiesces         int
+	expirationPaused bool
+	expirationResume time.Time
+}
+
+// NewGate returns an open gate. lockDB must not be gated, since the gate
+// expires locks with it while writes are held. extender, if not nil, pushes
+// back the expiry of the locks when the gate is unlocked.
+func NewGate(logger lager.Logger, lockDB db.LockDB, extender Extender, clock clock.Clock, quiesceTimeout time.Duration) *Gate {
+	if quiesceTimeout <= 0 {
+		quiesceTimeout = DefaultQuiesceTimeout
+	}
+
+	return &Gate{
+		logger:         logger.Session("backup-gate"),
+		lockDB:         lockDB,
+		extender:       extender,
+		clock:          clock,
+		quiesceTimeout: quiesceTimeout,
+	}
+}
+
+// LockDB returns lockDB with its writes held while the gate is quiesced.
+func (g *Gate) LockDB(lockDB db.LockDB) db.LockDB {
+	return &gatedLockDB{LockDB: lockDB, gate: g}
+}
+
+// ExpirerLockDB is like LockDB, but for the expirer: its releases and
+// expirations fail with ErrExpirationPaused instead of going through when
+// they were held, or while expiration is paused after an unlock. The lock
+// pick keeps the locks whose release failed and tries them again, and the
+// sweeper expires them on its next sweep, so they are expired once
+// expiration resumes, if their owners have not refreshed them by then.
+func (g *Gate) ExpirerLockDB(lockDB db.LockDB) db.LockDB {
+	return &gatedExpirerLockDB{gatedLockDB: gatedLockDB{LockDB: lockDB, gate: g}}
+}
+
+// Quiesced reports whether writes are being held.
+func (g *Gate) Quiesced() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.quiesced
+}
+
+// Quiesce waits for the writes in flight to finish and holds new ones. It
+// then expires the locks whose ttl has passed, so that a backup does not
+// capture locks that are only waiting to be expired. Quiescing a quiesced
+// gate does nothing.
+func (g *Gate) Quiesce() error {
+	logger := g.logger.Session("quiesce")
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.quiesced {
+		logger.Info("already-quiesced")
+		return nil
+	}
+
+	logger.Info("waiting-for-writes")
+	g.writes.Lock()
+
+	expired, err := g.lockDB.ExpireLocks(logger)
+	if err != nil {
+		logger.Error("failed-to-expire-locks", err)
+		g.writes.Unlock()
+		return err
+	}
+
+	g.pause.Lock()
+	g.quiesces++
+	g.expirationPaused = true
+	g.pause.Unlock()
+
+	g.quiesced = true
+	g.quiescedAt = g.clock.Now()
+	g.unlocked = make(chan struct{})
+	g.timer = g.clock.NewTimer(g.quiesceTimeout)
+	go g.unlockAfterTimeout(g.unlocked, g.timer)
+
+	logger.Info("quiesced", lager.Data{"expired-locks": len(expired), "timeout": g.quiesceTimeout.String()})
+	return nil
+}
+
+// Unlock lets held writes through. Unlocking an open gate does nothing.
+func (g *Gate) Unlock() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !g.quiesced {
+		return
+	}
+	g.unlock()
+	g.logger.Info("unlocked")
+}
+
+func (g *Gate) unlock() {
+	g.timer.Stop()
+	close(g.unlocked)
+	g.quiesced = false
+
+	held := g.clock.Since(g.quiescedAt)
+	g.pause.Lock()
+	g.expirationPaused = false
+	g.expirationResume = g.clock.Now().Add(held)
+	g.pause.Unlock()
+
+	if g.extender != nil {
+		err := g.extender.ExtendExpiries(g.logger, held)
+		if err != nil {
+			g.logger.Error("failed-to-extend-expiries", err, lager.Data{"held": held.String()})
+		}
+	}
+
+	g.writes.Unlock()
+}
+
+func (g *Gate) unlockAfterTimeout(unlocked chan struct{}, timer clock.Timer) {
+	select {
+	case <-unlocked:
+	case <-timer.C():
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+
+		// Unlock may have won the race for the mutex
+		select {
+		case <-unlocked:
+			return
+		default:
+		}
+
+		g.unlock()
+		g.logger.Info("unlocked-after-timeout", lager.Data{"timeout": g.quiesceTimeout.String()})
+	}
+}
+
+// hold is called by every write, and returns the function that ends it.
+func (g *Gate) hold() func() {
+	g.writes.RLock()
+	return g.writes.RUnlock
+}
+
+// holdExpiration is like hold, but for the expirer's writes. It also reports
+// whether expiration is paused, in which case the write must not go through.
+func (g *Gate) holdExpiration() (func(), bool) {
+	g.pause.Lock()
+	quiesces, held := g.quiesces, g.expirationPaused
+	g.pause.Unlock()
+
+	g.writes.RLock()
+
+	g.pause.Lock()
+	defer g.pause.Unlock()
+	paused := held || g.quiesces != quiesces || g.clock.Now().Before(g.expirationResume)
+	return g.writes.RUnlock, paused
+}
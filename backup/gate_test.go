@@ -0,0 +1,251 @@
+package backup_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/backup"
+	"code.cloudfoundry.org/locket/backup/backupfakes"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Gate", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeClock    *fakeclock.FakeClock
+		flushDB      *dbfakes.FakeLockDB
+		fakeExtender *backupfakes.FakeExtender
+		fakeLockDB   *dbfakes.FakeLockDB
+		gate         *backup.Gate
+		gatedLockDB  db.LockDB
+		expirerDB    db.LockDB
+		resource     *models.Resource
+	)
+
+	// lock takes the lock in the background and reports when it is done
+	lock := func() <-chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			_, err := gatedLockDB.Lock(logger, resource, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			close(done)
+		}()
+		return done
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("backup")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		flushDB = &dbfakes.FakeLockDB{}
+		fakeExtender = &backupfakes.FakeExtender{}
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		resource = &models.Resource{Key: "key", Owner: "owner"}
+
+		gate = backup.NewGate(logger, flushDB, fakeExtender, fakeClock, time.Minute)
+		gatedLockDB = gate.LockDB(fakeLockDB)
+		expirerDB = gate.ExpirerLockDB(fakeLockDB)
+	})
+
+	AfterEach(func() {
+		gate.Unlock()
+	})
+
+	It("passes writes through while open", func() {
+		Eventually(lock()).Should(BeClosed())
+		Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		Expect(gate.Quiesced()).To(BeFalse())
+	})
+
+	It("passes the expirer's writes through while open", func() {
+		Expect(expirerDB.Release(logger, resource)).To(Succeed())
+		_, err := expirerDB.ExpireLocks(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+		Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(1))
+		Expect(fakeExtender.ExtendExpiriesCallCount()).To(Equal(0))
+	})
+
+	Context("when quiesced", func() {
+		BeforeEach(func() {
+			flushDB.ExpireLocksReturns([]*db.Lock{{Resource: resource}}, nil)
+			Expect(gate.Quiesce()).To(Succeed())
+		})
+
+		It("expires the locks whose ttl has passed", func() {
+			Expect(flushDB.ExpireLocksCallCount()).To(Equal(1))
+			Expect(gate.Quiesced()).To(BeTrue())
+			Expect(logger).To(gbytes.Say(`"expired-locks":1`))
+		})
+
+		It("holds writes until unlocked", func() {
+			done := lock()
+			Consistently(done).ShouldNot(BeClosed())
+
+			gate.Unlock()
+			Eventually(done).Should(BeClosed())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+			Expect(gate.Quiesced()).To(BeFalse())
+		})
+
+		It("holds the expirer's writes", func() {
+			done := make(chan struct{})
+			go func() {
+				gatedLockDB.ExpireLocks(logger)
+				close(done)
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+
+			gate.Unlock()
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("lets reads through", func() {
+			_, err := gatedLockDB.Fetch(logger, "key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(1))
+		})
+
+		It("does nothing when quiesced again", func() {
+			Expect(gate.Quiesce()).To(Succeed())
+			Expect(flushDB.ExpireLocksCallCount()).To(Equal(1))
+		})
+
+		It("unlocks by itself after the timeout", func() {
+			done := lock()
+
+			fakeClock.Increment(time.Minute)
+			Eventually(done).Should(BeClosed())
+			Expect(gate.Quiesced()).To(BeFalse())
+			Expect(logger).To(gbytes.Say("unlocked-after-timeout"))
+		})
+
+		It("pushes back the expiry of the locks by as long as writes were held, before letting them through", func() {
+			fakeExtender.ExtendExpiriesStub = func(lager.Logger, time.Duration) error {
+				Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+				return nil
+			}
+			done := lock()
+
+			fakeClock.Increment(5 * time.Second)
+			gate.Unlock()
+			Eventually(done).Should(BeClosed())
+
+			Expect(fakeExtender.ExtendExpiriesCallCount()).To(Equal(1))
+			_, by := fakeExtender.ExtendExpiriesArgsForCall(0)
+			Expect(by).To(Equal(5 * time.Second))
+		})
+
+		It("unlocks even when the expiry of the locks cannot be pushed back", func() {
+			fakeExtender.ExtendExpiriesReturns(errors.New("boom"))
+			done := lock()
+
+			gate.Unlock()
+			Eventually(done).Should(BeClosed())
+			Expect(logger).To(gbytes.Say("failed-to-extend-expiries"))
+		})
+
+		It("drops the expirer's writes it held", func() {
+			released := make(chan error, 1)
+			go func() {
+				released <- expirerDB.Release(logger, resource)
+			}()
+			Consistently(released).ShouldNot(Receive())
+
+			gate.Unlock()
+			Eventually(released).Should(Receive(Equal(backup.ErrExpirationPaused)))
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
+		})
+
+		It("pauses expiration for as long after the unlock as writes were held", func() {
+			fakeClock.Increment(5 * time.Second)
+			gate.Unlock()
+
+			_, err := expirerDB.ExpireLocks(logger)
+			Expect(err).To(Equal(backup.ErrExpirationPaused))
+
+			fakeClock.Increment(5 * time.Second)
+			_, err = expirerDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(1))
+		})
+
+		It("expires a lock that was due during the pause once expiration resumes", func() {
+			expiring := &db.Lock{Resource: resource, ModifiedIndex: 1, ModifiedId: "id", TtlInSeconds: 5}
+			fakeLockDB.FetchIncludingExpiredReturns(expiring, nil)
+
+			lockPick := expiration.NewLockPick(expirerDB, fakeClock, new(mfakes.FakeIngressClient), nil)
+			lockPick.RegisterTTL(logger, expiring)
+			Eventually(fakeClock.WatcherCount).Should(Equal(2))
+
+			fakeClock.Increment(5 * time.Second)
+			Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+			gate.Unlock()
+
+			Consistently(fakeLockDB.ReleaseCallCount).Should(Equal(0))
+			Eventually(func() int {
+				fakeClock.Increment(time.Second)
+				return fakeLockDB.ReleaseCallCount()
+			}).Should(Equal(1))
+			Eventually(lockPick.RegisteredTTLs).Should(BeEmpty())
+		})
+
+		It("times out each quiesce on its own", func() {
+			fakeClock.Increment(30 * time.Second)
+			gate.Unlock()
+			Expect(gate.Quiesce()).To(Succeed())
+
+			fakeClock.Increment(30 * time.Second)
+			Consistently(gate.Quiesced).Should(BeTrue())
+
+			fakeClock.Increment(30 * time.Second)
+			Eventually(gate.Quiesced).Should(BeFalse())
+		})
+	})
+
+	Context("when a write is in flight", func() {
+		It("quiesces once it is done", func() {
+			release := make(chan struct{})
+			fakeLockDB.LockStub = func(lager.Logger, *models.Resource, time.Duration) (*db.Lock, error) {
+				<-release
+				return nil, nil
+			}
+			done := lock()
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+			quiesced := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				Expect(gate.Quiesce()).To(Succeed())
+				close(quiesced)
+			}()
+			Consistently(quiesced).ShouldNot(BeClosed())
+
+			close(release)
+			Eventually(done).Should(BeClosed())
+			Eventually(quiesced).Should(BeClosed())
+		})
+	})
+
+	Context("when the locks cannot be expired", func() {
+		BeforeEach(func() {
+			flushDB.ExpireLocksReturns(nil, errors.New("boom"))
+		})
+
+		It("fails and stays open", func() {
+			Expect(gate.Quiesce()).To(MatchError("boom"))
+			Expect(gate.Quiesced()).To(BeFalse())
+			Eventually(lock()).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// Paths served by the debug server for the BOSH backup and restore scripts.
+const (
+	StatusPath  = "/debug/backup"
+	QuiescePath = "/debug/backup/quiesce"
+	UnlockPath  = "/debug/backup/unlock"
+)
+
+// Status is the gate's state as served by the handlers.
+type Status struct {
+	Quiesced bool `json:"quiesced"`
+}
+
+type handler struct {
+	logger lager.Logger
+	gate   *Gate
+}
+
+// NewHandlers returns the handlers for StatusPath, QuiescePath and
+// UnlockPath, keyed by path. Quiescing and unlocking take a POST, and all
+// three respond with the gate's Status.
+func NewHandlers(logger lager.Logger, gate *Gate) map[string]http.Handler {
+	h := &handler{logger: logger.Session("backup-handler"), gate: gate}
+	return map[string]http.Handler{
+		StatusPath:  http.HandlerFunc(h.status),
+		QuiescePath: http.HandlerFunc(h.quiesce),
+		UnlockPath:  http.HandlerFunc(h.unlock),
+	}
+}
+
+func (h *handler) status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	h.writeStatus(w)
+}
+
+func (h *handler) quiesce(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := h.gate.Quiesce()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeStatus(w)
+}
+
+func (h *handler) unlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.gate.Unlock()
+	h.writeStatus(w)
+}
+
+func (h *handler) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(Status{Quiesced: h.gate.Quiesced()})
+	if err != nil {
+		h.logger.Error("failed-to-encode-status", err)
+	}
+}
@@ -0,0 +1,60 @@
+package backup_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/backup"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handlers", func() {
+	var (
+		gate     *backup.Gate
+		handlers map[string]http.Handler
+	)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handlers[path].ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("backup")
+		gate = backup.NewGate(logger, &dbfakes.FakeLockDB{}, nil, fakeclock.NewFakeClock(time.Now()), time.Minute)
+		handlers = backup.NewHandlers(logger, gate)
+	})
+
+	AfterEach(func() {
+		gate.Unlock()
+	})
+
+	It("quiesces and unlocks the gate", func() {
+		response := serve("GET", backup.StatusPath)
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(MatchJSON(`{"quiesced": false}`))
+
+		response = serve("POST", backup.QuiescePath)
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(MatchJSON(`{"quiesced": true}`))
+		Expect(gate.Quiesced()).To(BeTrue())
+
+		response = serve("POST", backup.UnlockPath)
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(MatchJSON(`{"quiesced": false}`))
+		Expect(gate.Quiesced()).To(BeFalse())
+	})
+
+	It("only quiesces and unlocks on a POST", func() {
+		Expect(serve("GET", backup.QuiescePath).Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(serve("GET", backup.UnlockPath).Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(serve("POST", backup.StatusPath).Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(gate.Quiesced()).To(BeFalse())
+	})
+})
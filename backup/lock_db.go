@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+)
+
+type gatedLockDB struct {
+	db.LockDB
+	gate *Gate
+}
+
+func (l *gatedLockDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	defer l.gate.hold()()
+	return l.LockDB.Lock(logger, resource, ttl)
+}
+
+func (l *gatedLockDB) Release(logger lager.Logger, resource *models.Resource) error {
+	defer l.gate.hold()()
+	return l.LockDB.Release(logger, resource)
+}
+
+func (l *gatedLockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	defer l.gate.hold()()
+	return l.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (l *gatedLockDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error) {
+	defer l.gate.hold()()
+	return l.LockDB.LockGroup(logger, resources, ttl)
+}
+
+func (l *gatedLockDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	defer l.gate.hold()()
+	return l.LockDB.LockShared(logger, resource, ttl)
+}
+
+func (l *gatedLockDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	defer l.gate.hold()()
+	return l.LockDB.ReleaseShared(logger, resource)
+}
+
+func (l *gatedLockDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	defer l.gate.hold()()
+	return l.LockDB.LockSemaphore(logger, resource, capacity, ttl)
+}
+
+func (l *gatedLockDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	defer l.gate.hold()()
+	return l.LockDB.LockWithGrace(logger, resource, ttl, grace)
+}
+
+func (l *gatedLockDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	defer l.gate.hold()()
+	return l.LockDB.ExpireLocks(logger)
+}
+
+type gatedExpirerLockDB struct {
+	gatedLockDB
+}
+
+func (l *gatedExpirerLockDB) Release(logger lager.Logger, resource *models.Resource) error {
+	done, paused := l.gate.holdExpiration()
+	defer done()
+	if paused {
+		return ErrExpirationPaused
+	}
+	return l.LockDB.Release(logger, resource)
+}
+
+func (l *gatedExpirerLockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	done, paused := l.gate.holdExpiration()
+	defer done()
+	if paused {
+		return ErrExpirationPaused
+	}
+	return l.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (l *gatedExpirerLockDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	done, paused := l.gate.holdExpiration()
+	defer done()
+	if paused {
+		return nil, ErrExpirationPaused
+	}
+	return l.LockDB.ExpireLocks(logger)
+}
@@ -0,0 +1 @@
+package backup // import "code.cloudfoundry.org/locket/backup"
@@ -0,0 +1,169 @@
+package chaos
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// adminServicePrefix is the method prefix of the Chaos admin service, whose
+// calls are never faulted so that faults can always be turned off again.
+const adminServicePrefix = "/locket.admin.Chaos/"
+
+var ErrInvalidFaults = errors.New("probabilities must be between 0 and 1 and the max delay must not be negative")
+
+// ErrInjectedDBError is returned by the database wrapped by an Injector in
+// place of the real result.
+var ErrInjectedDBError = errors.New("chaos: injected database error")
+
+// ErrDroppedResponse is returned to the client in place of the response of
+// a call that was handled by the server.
+var ErrDroppedResponse = grpc.Errorf(codes.Unavailable, "chaos: dropped response")
+
+// Injector injects faults into a locket server for game-days and tests. It
+// starts with every fault turned off.
+type Injector struct {
+	logger lager.Logger
+	lockDB db.LockDB
+	clock  clock.Clock
+
+	mutex  sync.Mutex
+	faults admin.Faults
+	random *rand.Rand
+}
+
+func NewInjector(logger lager.Logger, lockDB db.LockDB, clock clock.Clock, seed int64) *Injector {
+	return &Injector{
+		logger: logger.Session("chaos"),
+		lockDB: lockDB,
+		clock:  clock,
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Faults returns the faults currently being injected.
+func (i *Injector) Faults() *admin.Faults {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	faults := i.faults
+	return &faults
+}
+
+// SetFaults replaces the faults being injected and returns the previous
+// ones. A nil faults turns every fault off.
+func (i *Injector) SetFaults(faults *admin.Faults) (*admin.Faults, error) {
+	if faults == nil {
+		faults = &admin.Faults{}
+	}
+	if !validProbability(faults.DelayProbability) ||
+		!validProbability(faults.DropProbability) ||
+		!validProbability(faults.DbErrorProbability) ||
+		faults.MaxDelayInMilliseconds < 0 {
+		return nil, ErrInvalidFaults
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	previous := i.faults
+	i.faults = *faults
+	i.logger.Info("set-faults", lager.Data{"faults": faults})
+	return &previous, nil
+}
+
+// ExpireLock expires the lock on key right away, as if its owner had
+// stopped refreshing it, and returns the lock that was expired. The lock is
+// only released if it has not changed hands since it was fetched.
+func (i *Injector) ExpireLock(logger lager.Logger, key string) (*db.Lock, error) {
+	logger = logger.Session("expire-lock", lager.Data{"key": key})
+
+	lock, err := i.lockDB.Fetch(logger, key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.lockDB.ReleaseIf(logger, &models.Resource{Key: lock.Key, Owner: lock.Owner}, db.ReleaseCondition{FencingToken: lock.FencingToken})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("expired-lock", lager.Data{"owner": lock.Owner, "fencing-token": lock.FencingToken})
+	return lock, nil
+}
+
+// NewInterceptor returns a unary interceptor that delays calls before they
+// are handled and drops their responses once they have been, according to
+// the current faults.
+func (i *Injector) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		delay := i.delay()
+		if delay > 0 {
+			timer := i.clock.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, grpc.Errorf(codes.DeadlineExceeded, ctx.Err().Error())
+			case <-timer.C():
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if i.roll(func(faults admin.Faults) float64 { return faults.DropProbability }) {
+			i.logger.Info("dropped-response", lager.Data{"method": info.FullMethod})
+			return nil, ErrDroppedResponse
+		}
+		return resp, err
+	}
+}
+
+// LockDB returns the injector's database wrapped so that its operations
+// fail with ErrInjectedDBError according to the current faults.
+func (i *Injector) LockDB() db.LockDB {
+	return &lockDB{LockDB: i.lockDB, injector: i}
+}
+
+func (i *Injector) delay() time.Duration {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.faults.MaxDelayInMilliseconds == 0 || i.random.Float64() >= i.faults.DelayProbability {
+		return 0
+	}
+	return time.Duration(i.random.Int63n(i.faults.MaxDelayInMilliseconds)+1) * time.Millisecond
+}
+
+func (i *Injector) roll(probability func(admin.Faults) float64) bool {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	p := probability(i.faults)
+	return p > 0 && i.random.Float64() < p
+}
+
+func (i *Injector) dbError(logger lager.Logger, operation string) error {
+	if !i.roll(func(faults admin.Faults) float64 { return faults.DbErrorProbability }) {
+		return nil
+	}
+	logger.Info("injected-db-error", lager.Data{"operation": operation})
+	return ErrInjectedDBError
+}
+
+func validProbability(p float64) bool {
+	return p >= 0 && p <= 1
+}
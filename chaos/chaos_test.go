@@ -0,0 +1,227 @@
+package chaos_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/chaos"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var _ = Describe("Chaos", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		fakeLockDB *dbfakes.FakeLockDB
+		injector   *chaos.Injector
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("chaos")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		injector = chaos.NewInjector(logger, fakeLockDB, fakeClock, 1)
+	})
+
+	Context("SetFaults", func() {
+		It("starts with every fault turned off", func() {
+			Expect(injector.Faults()).To(Equal(&admin.Faults{}))
+		})
+
+		It("returns the previous faults", func() {
+			_, err := injector.SetFaults(&admin.Faults{DropProbability: 0.5})
+			Expect(err).NotTo(HaveOccurred())
+
+			previous, err := injector.SetFaults(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(previous).To(Equal(&admin.Faults{DropProbability: 0.5}))
+			Expect(injector.Faults()).To(Equal(&admin.Faults{}))
+		})
+
+		It("rejects invalid faults", func() {
+			_, err := injector.SetFaults(&admin.Faults{DbErrorProbability: 1.5})
+			Expect(err).To(Equal(chaos.ErrInvalidFaults))
+
+			_, err = injector.SetFaults(&admin.Faults{MaxDelayInMilliseconds: -1})
+			Expect(err).To(Equal(chaos.ErrInvalidFaults))
+		})
+	})
+
+	Context("the interceptor", func() {
+		var (
+			interceptor grpc.UnaryServerInterceptor
+			method      string
+			handled     chan struct{}
+		)
+
+		BeforeEach(func() {
+			interceptor = injector.NewInterceptor()
+			method = "/models.Locket/Lock"
+			handled = make(chan struct{}, 1)
+		})
+
+		intercept := func() (interface{}, error) {
+			return interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				handled <- struct{}{}
+				return "response", nil
+			})
+		}
+
+		It("passes calls through when no faults are set", func() {
+			Expect(intercept()).To(Equal("response"))
+			Expect(handled).To(Receive())
+		})
+
+		Context("when responses are dropped", func() {
+			BeforeEach(func() {
+				_, err := injector.SetFaults(&admin.Faults{DropProbability: 1})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("handles the call but returns Unavailable", func() {
+				_, err := intercept()
+				Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+				Expect(handled).To(Receive())
+			})
+
+			It("does not fault the admin service", func() {
+				method = "/locket.admin.Chaos/SetFaults"
+				Expect(intercept()).To(Equal("response"))
+			})
+		})
+
+		Context("when calls are delayed", func() {
+			BeforeEach(func() {
+				_, err := injector.SetFaults(&admin.Faults{DelayProbability: 1, MaxDelayInMilliseconds: 1000})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("waits up to the max delay before handling the call", func() {
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					Expect(intercept()).To(Equal("response"))
+				}()
+
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+				Consistently(handled).ShouldNot(Receive())
+
+				fakeClock.Increment(time.Second)
+				Eventually(handled).Should(Receive())
+				Eventually(done).Should(BeClosed())
+			})
+		})
+	})
+
+	Context("the wrapped database", func() {
+		var lockDB db.LockDB
+
+		BeforeEach(func() {
+			lockDB = injector.LockDB()
+			fakeLockDB.FetchReturns(&db.Lock{Resource: &models.Resource{Key: "quack"}}, nil)
+		})
+
+		It("passes operations through when no faults are set", func() {
+			lock, err := lockDB.Fetch(logger, "quack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Key).To(Equal("quack"))
+		})
+
+		It("fails operations with an injected error", func() {
+			_, err := injector.SetFaults(&admin.Faults{DbErrorProbability: 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = lockDB.Fetch(logger, "quack")
+			Expect(err).To(Equal(chaos.ErrInjectedDBError))
+			_, err = lockDB.Lock(logger, &models.Resource{Key: "quack"}, time.Second)
+			Expect(err).To(Equal(chaos.ErrInjectedDBError))
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(0))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("ExpireLock", func() {
+		BeforeEach(func() {
+			fakeLockDB.FetchReturns(&db.Lock{
+				Resource:     &models.Resource{Key: "quack", Owner: "jim"},
+				FencingToken: 7,
+			}, nil)
+		})
+
+		It("releases the lock as long as it has not changed hands", func() {
+			lock, err := injector.ExpireLock(logger, "quack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Owner).To(Equal("jim"))
+
+			Expect(fakeLockDB.ReleaseIfCallCount()).To(Equal(1))
+			_, resource, condition := fakeLockDB.ReleaseIfArgsForCall(0)
+			Expect(resource).To(Equal(&models.Resource{Key: "quack", Owner: "jim"}))
+			Expect(condition).To(Equal(db.ReleaseCondition{FencingToken: 7}))
+		})
+
+		It("is not affected by injected database errors", func() {
+			_, err := injector.SetFaults(&admin.Faults{DbErrorProbability: 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = injector.ExpireLock(logger, "quack")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the error when the lock cannot be fetched", func() {
+			fakeLockDB.FetchReturns(nil, models.ErrResourceNotFound)
+
+			_, err := injector.ExpireLock(logger, "quack")
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+			Expect(fakeLockDB.ReleaseIfCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("the admin handler", func() {
+		var handler admin.ChaosServer
+
+		BeforeEach(func() {
+			handler = chaos.NewHandler(logger, injector)
+		})
+
+		It("sets and gets faults", func() {
+			_, err := handler.SetFaults(context.Background(), &admin.SetFaultsRequest{Faults: &admin.Faults{DropProbability: 0.25}})
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := handler.GetFaults(context.Background(), &admin.GetFaultsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Faults.DropProbability).To(Equal(0.25))
+		})
+
+		It("returns InvalidArgument for invalid faults", func() {
+			_, err := handler.SetFaults(context.Background(), &admin.SetFaultsRequest{Faults: &admin.Faults{DropProbability: -1}})
+			Expect(grpc.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+
+		It("expires locks", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: &models.Resource{Key: "quack", Owner: "jim"}, FencingToken: 3}, nil)
+
+			resp, err := handler.ExpireLock(context.Background(), &admin.ExpireLockRequest{Key: "quack"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(Equal(&admin.ExpireLockResponse{Owner: "jim", FencingToken: 3}))
+		})
+
+		It("returns the error when a lock cannot be expired", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: &models.Resource{Key: "quack", Owner: "jim"}, FencingToken: 3}, nil)
+			fakeLockDB.ReleaseIfReturns(errors.New("boom"))
+
+			_, err := handler.ExpireLock(context.Background(), &admin.ExpireLockRequest{Key: "quack"})
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package chaos
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models/admin"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type handler struct {
+	logger   lager.Logger
+	injector *Injector
+}
+
+// NewHandler returns the Chaos admin service, which controls injector.
+func NewHandler(logger lager.Logger, injector *Injector) admin.ChaosServer {
+	return &handler{
+		logger:   logger.Session("chaos-handler"),
+		injector: injector,
+	}
+}
+
+func (h *handler) SetFaults(ctx context.Context, req *admin.SetFaultsRequest) (*admin.SetFaultsResponse, error) {
+	previous, err := h.injector.SetFaults(req.Faults)
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+	return &admin.SetFaultsResponse{Previous: previous}, nil
+}
+
+func (h *handler) GetFaults(ctx context.Context, req *admin.GetFaultsRequest) (*admin.GetFaultsResponse, error) {
+	return &admin.GetFaultsResponse{Faults: h.injector.Faults()}, nil
+}
+
+func (h *handler) ExpireLock(ctx context.Context, req *admin.ExpireLockRequest) (*admin.ExpireLockResponse, error) {
+	lock, err := h.injector.ExpireLock(h.logger, req.Key)
+	if err != nil {
+		h.logger.Error("failed-to-expire-lock", err, lager.Data{"key": req.Key})
+		return nil, err
+	}
+	return &admin.ExpireLockResponse{Owner: lock.Owner, FencingToken: lock.FencingToken}, nil
+}
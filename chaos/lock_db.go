@@ -0,0 +1,93 @@
+package chaos
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// lockDB fails the operations the handlers use with ErrInjectedDBError.
+// ExpireLocks is passed through, as the handlers do not call it.
+type lockDB struct {
+	db.LockDB
+	injector *Injector
+}
+
+func (l *lockDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	if err := l.injector.dbError(logger, "lock"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.Lock(logger, resource, ttl)
+}
+
+func (l *lockDB) Release(logger lager.Logger, resource *models.Resource) error {
+	if err := l.injector.dbError(logger, "release"); err != nil {
+		return err
+	}
+	return l.LockDB.Release(logger, resource)
+}
+
+func (l *lockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	if err := l.injector.dbError(logger, "release-if"); err != nil {
+		return err
+	}
+	return l.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (l *lockDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error) {
+	if err := l.injector.dbError(logger, "lock-group"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.LockGroup(logger, resources, ttl)
+}
+
+func (l *lockDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	if err := l.injector.dbError(logger, "lock-shared"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.LockShared(logger, resource, ttl)
+}
+
+func (l *lockDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	if err := l.injector.dbError(logger, "release-shared"); err != nil {
+		return err
+	}
+	return l.LockDB.ReleaseShared(logger, resource)
+}
+
+func (l *lockDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	if err := l.injector.dbError(logger, "lock-semaphore"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.LockSemaphore(logger, resource, capacity, ttl)
+}
+
+func (l *lockDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	if err := l.injector.dbError(logger, "lock-with-grace"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.LockWithGrace(logger, resource, ttl, grace)
+}
+
+func (l *lockDB) Fetch(logger lager.Logger, key string) (*db.Lock, error) {
+	if err := l.injector.dbError(logger, "fetch"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.Fetch(logger, key)
+}
+
+func (l *lockDB) FetchAll(logger lager.Logger, lockType string) ([]*db.Lock, error) {
+	if err := l.injector.dbError(logger, "fetch-all"); err != nil {
+		return nil, err
+	}
+	return l.LockDB.FetchAll(logger, lockType)
+}
+
+func (l *lockDB) Count(logger lager.Logger, lockType string) (int, error) {
+	if err := l.injector.dbError(logger, "count"); err != nil {
+		return 0, err
+	}
+	return l.LockDB.Count(logger, lockType)
+}
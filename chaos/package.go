@@ -0,0 +1 @@
+package chaos // import "code.cloudfoundry.org/locket/chaos"
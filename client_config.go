@@ -0,0 +1,128 @@
+package locket
+
+import (
+	"errors"
+	"flag"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/locket/compression"
+	"code.cloudfoundry.org/locket/vaultpki"
+)
+
+// DefaultDialTimeout is how long NewClient waits to connect when
+// LocketDialTimeout is not set.
+const DefaultDialTimeout = time.Second
+
+var (
+	ErrMissingLocketAddress  = errors.New("locket address is required")
+	ErrInvalidLocketAddress  = errors.New("locket address must be a host:port")
+	ErrMissingLocketCACert   = errors.New("locket ca cert file is required")
+	ErrMissingLocketCreds    = errors.New("one of a locket client cert and key, auth token or vault config is required")
+	ErrMismatchedLocketCreds = errors.New("locket client cert file and key file must be set together")
+	ErrNegativeLocketTimeout = errors.New("locket timeouts and intervals cannot be negative")
+)
+
+// ClientLocketConfig is the configuration of a locket client, meant to be
+// embedded in the configuration of the components that use locket.
+type ClientLocketConfig struct {
+	LocketAddress        string `json:"locket_address,omitempty" yaml:"locket_address,omitempty"`
+	LocketCACertFile     string `json:"locket_ca_cert_file,omitempty" yaml:"locket_ca_cert_file,omitempty"`
+	LocketClientCertFile string `json:"locket_client_cert_file,omitempty" yaml:"locket_client_cert_file,omitempty"`
+	LocketClientKeyFile  string `json:"locket_client_key_file,omitempty" yaml:"locket_client_key_file,omitempty"`
+	LocketAuthToken      string `json:"locket_auth_token,omitempty" yaml:"locket_auth_token,omitempty"`
+
+	// LocketCompression asks for FetchAll responses to be compressed with
+	// compression.Gzip or compression.Snappy.
+	LocketCompression string `json:"locket_compression,omitempty" yaml:"locket_compression,omitempty"`
+
+	// LocketDialTimeout bounds how long NewClient waits to connect, and
+	// defaults to DefaultDialTimeout.
+	LocketDialTimeout durationjson.Duration `json:"locket_dial_timeout,omitempty" yaml:"locket_dial_timeout,omitempty"`
+
+	// LocketKeepaliveTime pings the server after the connection has been
+	// idle that long, and LocketKeepaliveTimeout closes the connection if
+	// the ping is not answered in time. Both are off when 0.
+	LocketKeepaliveTime    durationjson.Duration `json:"locket_keepalive_time,omitempty" yaml:"locket_keepalive_time,omitempty"`
+	LocketKeepaliveTimeout durationjson.Duration `json:"locket_keepalive_timeout,omitempty" yaml:"locket_keepalive_timeout,omitempty"`
+
+	// LocketRetryInterval is how long lock and presence runners wait
+	// between attempts, and defaults to RetryInterval.
+	LocketRetryInterval durationjson.Duration `json:"locket_retry_interval,omitempty" yaml:"locket_retry_interval,omitempty"`
+
+	// LocketServerPins accepts only servers whose certificate matches one
+	// of these pins, as well as being signed by the CA. See PublicKeyPin
+	// and CertificatePin.
+	LocketServerPins []string `json:"locket_server_pins,omitempty" yaml:"locket_server_pins,omitempty"`
+
+	// LocketVault requests the client certificate from Vault's PKI engine
+	// in place of LocketClientCertFile and LocketClientKeyFile.
+	LocketVault vaultpki.Config `json:"locket_vault,omitempty" yaml:"locket_vault,omitempty"`
+}
+
+// Validate returns an error if a client cannot be made with c.
+func (c ClientLocketConfig) Validate() error {
+	if c.LocketAddress == "" {
+		return ErrMissingLocketAddress
+	}
+	if _, _, err := net.SplitHostPort(c.LocketAddress); err != nil {
+		return ErrInvalidLocketAddress
+	}
+
+	if c.LocketCACertFile == "" {
+		return ErrMissingLocketCACert
+	}
+	if (c.LocketClientCertFile == "") != (c.LocketClientKeyFile == "") {
+		return ErrMismatchedLocketCreds
+	}
+	if c.LocketClientCertFile == "" && c.LocketAuthToken == "" && !c.LocketVault.Enabled() {
+		return ErrMissingLocketCreds
+	}
+
+	if c.LocketDialTimeout < 0 || c.LocketKeepaliveTime < 0 || c.LocketKeepaliveTimeout < 0 || c.LocketRetryInterval < 0 {
+		return ErrNegativeLocketTimeout
+	}
+
+	err := validatePins(c.LocketServerPins)
+	if err != nil {
+		return err
+	}
+
+	return compression.Validate(c.LocketCompression)
+}
+
+// DialTimeout returns LocketDialTimeout, or DefaultDialTimeout if it is not
+// set.
+func (c ClientLocketConfig) DialTimeout() time.Duration {
+	if c.LocketDialTimeout == 0 {
+		return DefaultDialTimeout
+	}
+	return time.Duration(c.LocketDialTimeout)
+}
+
+// RetryInterval returns LocketRetryInterval, or RetryInterval if it is not
+// set.
+func (c ClientLocketConfig) RetryInterval() time.Duration {
+	if c.LocketRetryInterval == 0 {
+		return RetryInterval
+	}
+	return time.Duration(c.LocketRetryInterval)
+}
+
+// RegisterFlags registers a flag for each setting of c other than
+// LocketServerPins and LocketVault, such as -locketAddress. The flags default to the current
+// values of c, so a config file loaded into c before the flags are parsed
+// is overridden only by the flags that are given.
+func (c *ClientLocketConfig) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.LocketAddress, "locketAddress", c.LocketAddress, "Address of the locket server")
+	flags.StringVar(&c.LocketCACertFile, "locketCACertFile", c.LocketCACertFile, "Path to the CA certificate of the locket server")
+	flags.StringVar(&c.LocketClientCertFile, "locketClientCertFile", c.LocketClientCertFile, "Path to the locket client certificate")
+	flags.StringVar(&c.LocketClientKeyFile, "locketClientKeyFile", c.LocketClientKeyFile, "Path to the locket client key")
+	flags.StringVar(&c.LocketAuthToken, "locketAuthToken", c.LocketAuthToken, "Token to authenticate with instead of a client certificate")
+	flags.StringVar(&c.LocketCompression, "locketCompression", c.LocketCompression, "Compression to ask for FetchAll responses in, gzip or snappy")
+	flags.DurationVar((*time.Duration)(&c.LocketDialTimeout), "locketDialTimeout", time.Duration(c.LocketDialTimeout), "How long to wait to connect to the locket server, 1s if 0")
+	flags.DurationVar((*time.Duration)(&c.LocketKeepaliveTime), "locketKeepaliveTime", time.Duration(c.LocketKeepaliveTime), "Ping the locket server after the connection is idle for this long, never if 0")
+	flags.DurationVar((*time.Duration)(&c.LocketKeepaliveTimeout), "locketKeepaliveTimeout", time.Duration(c.LocketKeepaliveTimeout), "Close the connection if a ping is not answered in this long")
+	flags.DurationVar((*time.Duration)(&c.LocketRetryInterval), "locketRetryInterval", time.Duration(c.LocketRetryInterval), "How long to wait between attempts to take a lock, 5s if 0")
+}
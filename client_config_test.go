@@ -0,0 +1,106 @@
+package locket_test
+
+import (
+	"flag"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/vaultpki"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientLocketConfig", func() {
+	var config locket.ClientLocketConfig
+
+	BeforeEach(func() {
+		config = locket.ClientLocketConfig{
+			LocketAddress:        "locket.service.cf.internal:8891",
+			LocketCACertFile:     "ca.crt",
+			LocketClientCertFile: "client.crt",
+			LocketClientKeyFile:  "client.key",
+		}
+	})
+
+	Describe("Validate", func() {
+		It("accepts a client certificate", func() {
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("accepts an auth token", func() {
+			config.LocketClientCertFile = ""
+			config.LocketClientKeyFile = ""
+			config.LocketAuthToken = "token"
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("accepts a vault config", func() {
+			config.LocketClientCertFile = ""
+			config.LocketClientKeyFile = ""
+			config.LocketVault = vaultpki.Config{Address: "https://vault:8200"}
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("requires an address", func() {
+			config.LocketAddress = ""
+			Expect(config.Validate()).To(Equal(locket.ErrMissingLocketAddress))
+		})
+
+		It("requires the address to have a port", func() {
+			config.LocketAddress = "locket.service.cf.internal"
+			Expect(config.Validate()).To(Equal(locket.ErrInvalidLocketAddress))
+		})
+
+		It("requires a ca cert", func() {
+			config.LocketCACertFile = ""
+			Expect(config.Validate()).To(Equal(locket.ErrMissingLocketCACert))
+		})
+
+		It("requires credentials", func() {
+			config.LocketClientCertFile = ""
+			config.LocketClientKeyFile = ""
+			Expect(config.Validate()).To(Equal(locket.ErrMissingLocketCreds))
+		})
+
+		It("requires the client cert and key together", func() {
+			config.LocketClientKeyFile = ""
+			Expect(config.Validate()).To(Equal(locket.ErrMismatchedLocketCreds))
+		})
+
+		It("rejects negative durations", func() {
+			config.LocketKeepaliveTime = durationjson.Duration(-time.Second)
+			Expect(config.Validate()).To(Equal(locket.ErrNegativeLocketTimeout))
+		})
+
+		It("rejects unknown compression", func() {
+			config.LocketCompression = "zstd"
+			Expect(config.Validate()).NotTo(Succeed())
+		})
+	})
+
+	It("defaults the dial timeout and retry interval", func() {
+		Expect(config.DialTimeout()).To(Equal(locket.DefaultDialTimeout))
+		Expect(config.RetryInterval()).To(Equal(locket.RetryInterval))
+
+		config.LocketDialTimeout = durationjson.Duration(3 * time.Second)
+		config.LocketRetryInterval = durationjson.Duration(time.Second)
+		Expect(config.DialTimeout()).To(Equal(3 * time.Second))
+		Expect(config.RetryInterval()).To(Equal(time.Second))
+	})
+
+	Describe("RegisterFlags", func() {
+		It("overrides only the settings given as flags", func() {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			config.RegisterFlags(flags)
+
+			err := flags.Parse([]string{"-locketAddress", "10.0.0.1:8891", "-locketKeepaliveTime", "30s"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(config.LocketAddress).To(Equal("10.0.0.1:8891"))
+			Expect(config.LocketKeepaliveTime).To(Equal(durationjson.Duration(30 * time.Second)))
+			Expect(config.LocketCACertFile).To(Equal("ca.crt"))
+			Expect(config.LocketClientCertFile).To(Equal("client.crt"))
+		})
+	})
+})
@@ -3,32 +3,127 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"code.cloudfoundry.org/debugserver"
+	"code.cloudfoundry.org/durationjson"
 	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
 	"code.cloudfoundry.org/lager/lagerflags"
+	"code.cloudfoundry.org/locket/allowlist"
+	"code.cloudfoundry.org/locket/audit"
+	"code.cloudfoundry.org/locket/backup"
+	"code.cloudfoundry.org/locket/dbtuning"
+	"code.cloudfoundry.org/locket/deadline"
+	"code.cloudfoundry.org/locket/handoff"
+	"code.cloudfoundry.org/locket/iamauth"
+	"code.cloudfoundry.org/locket/loadreport"
+	"code.cloudfoundry.org/locket/logsampling"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/sinks"
+	"code.cloudfoundry.org/locket/natsbridge"
+	"code.cloudfoundry.org/locket/overload"
+	"code.cloudfoundry.org/locket/partition"
+	"code.cloudfoundry.org/locket/raftdb"
+	"code.cloudfoundry.org/locket/replication"
+	"code.cloudfoundry.org/locket/revocation"
+	"code.cloudfoundry.org/locket/secrets"
+	"code.cloudfoundry.org/locket/tokenauth"
+	"code.cloudfoundry.org/locket/tracing"
+	"code.cloudfoundry.org/locket/vaultpki"
+	"code.cloudfoundry.org/locket/webhook"
+)
+
+const (
+	SQLStorageMode  = "sql"
+	RaftStorageMode = "raft"
+)
+
+const (
+	SyncedDatabaseTime = "synced"
+	DatabaseNowTime    = "now"
 )
 
 type LocketConfig struct {
+	AccessLogPath              string                `json:"access_log_path,omitempty"`
+	ACLAuditLogPath            string                `json:"acl_audit_log_path,omitempty"`
+	ACLPolicyFile              string                `json:"acl_policy_file,omitempty"`
+	AccessLogSampleRate        float64               `json:"access_log_sample_rate,omitempty"`
+	BackupQuiesceTimeout       durationjson.Duration `json:"backup_quiesce_timeout,omitempty"`
 	CaFile                     string                `json:"ca_file"`
 	CertFile                   string                `json:"cert_file"`
+	ChaosEnabled               bool                  `json:"chaos_enabled,omitempty"`
 	ConsulCluster              string                `json:"consul_cluster,omitempty"`
+	DatabaseClockSkewThreshold durationjson.Duration `json:"database_clock_skew_threshold,omitempty"`
+	DatabaseClockSyncInterval  durationjson.Duration `json:"database_clock_sync_interval,omitempty"`
 	DatabaseConnectionString   string                `json:"database_connection_string"`
 	MaxOpenDatabaseConnections int                   `json:"max_open_database_connections,omitempty"`
 	DatabaseDriver             string                `json:"database_driver,omitempty"`
+	DatabaseTimeMode           string                `json:"database_time_mode,omitempty"`
 	DropsondePort              int                   `json:"dropsonde_port,omitempty"`
+	EventOutboxInterval        durationjson.Duration `json:"event_outbox_interval,omitempty"`
+	FIPSMode                   bool                  `json:"fips_mode,omitempty"`
+	GRPCWebAllowedOrigins      []string              `json:"grpc_web_allowed_origins,omitempty"`
+	GRPCWebListenAddress       string                `json:"grpc_web_listen_address,omitempty"`
+	GroupCommitWindow          durationjson.Duration `json:"group_commit_window,omitempty"`
+	HealthDrainDelay           durationjson.Duration `json:"health_drain_delay,omitempty"`
+	HotKeyWindow               durationjson.Duration `json:"hot_key_window,omitempty"`
 	KeyFile                    string                `json:"key_file"`
+	LeaderElection             bool                  `json:"leader_election,omitempty"`
 	ListenAddress              string                `json:"listen_address"`
+	ReadCacheMaxStaleness      durationjson.Duration `json:"read_cache_max_staleness,omitempty"`
+	RequestIDWindow            durationjson.Duration `json:"request_id_window,omitempty"`
+	ResponseCompression        string                `json:"response_compression,omitempty"`
+	SessionAdminEnabled        bool                  `json:"session_admin_enabled,omitempty"`
+	SessionAdminIdentities     []string              `json:"session_admin_identities,omitempty"`
+	SlowQueryThreshold         durationjson.Duration `json:"slow_query_threshold,omitempty"`
+	SlowRPCThreshold           durationjson.Duration `json:"slow_rpc_threshold,omitempty"`
 	SQLCACertFile              string                `json:"sql_ca_cert_file,omitempty"`
+	StatelessExpiration        bool                  `json:"stateless_expiration,omitempty"`
+	StorageMode                string                `json:"storage_mode,omitempty"`
+	SystemdSocketActivation    bool                  `json:"systemd_socket_activation,omitempty"`
+	TrafficRecordMaxMegabytes  int                   `json:"traffic_record_max_megabytes,omitempty"`
+	TrafficRecordPath          string                `json:"traffic_record_path,omitempty"`
+	AllowlistConfig            allowlist.Config      `json:"allowlist"`
+	AuditConfig                audit.Config          `json:"audit"`
+	CredHubConfig              secrets.Config        `json:"credhub"`
+	DatabaseIAMConfig          iamauth.Config        `json:"database_iam"`
+	DatabaseTuningConfig       dbtuning.Config       `json:"database_tuning"`
+	HandoffConfig              handoff.Config        `json:"handoff"`
+	LoadReportingConfig        loadreport.Config     `json:"load_reporting"`
+	LogSampling                logsampling.Rules     `json:"log_sampling,omitempty"`
 	LoggregatorConfig          loggregator_v2.Config `json:"loggregator"`
+	MaxHoldConfig              MaxHoldConfig         `json:"max_hold"`
+	MetricKeyPrefixes          []metrics.KeyPrefix   `json:"metric_key_prefixes,omitempty"`
+	MetricsConfig              sinks.Config          `json:"metrics"`
+	NATSConfig                 natsbridge.Config     `json:"nats"`
+	OverloadConfig             overload.Config       `json:"overload"`
+	PartitionDetectionConfig   partition.Config      `json:"partition_detection"`
+	RaftConfig                 raftdb.Config         `json:"raft"`
+	RequestTimeoutConfig       deadline.Config       `json:"request_timeouts"`
+	RevocationConfig           revocation.Config     `json:"revocation"`
+	TokenAuthConfig            tokenauth.Config      `json:"token_auth"`
+	TracingConfig              tracing.Config        `json:"tracing"`
+	VaultConfig                vaultpki.Config       `json:"vault"`
+	WarmStandbyConfig          replication.Config    `json:"warm_standby"`
+	Webhooks                   []webhook.Config      `json:"webhooks,omitempty"`
 	debugserver.DebugServerConfig
 	lagerflags.LagerConfig
 }
 
+// MaxHoldConfig limits how long an owner can keep refreshing a lock. Keys
+// overrides Default for individual keys, and a limit of 0 disables it.
+type MaxHoldConfig struct {
+	Default durationjson.Duration            `json:"default,omitempty"`
+	Keys    map[string]durationjson.Duration `json:"keys,omitempty"`
+}
+
 func DefaultLocketConfig() LocketConfig {
 	return LocketConfig{
-		LagerConfig:    lagerflags.DefaultLagerConfig(),
-		DatabaseDriver: "mysql",
+		LagerConfig:          lagerflags.DefaultLagerConfig(),
+		DatabaseDriver:       "mysql",
+		AccessLogSampleRate:  1.0,
+		RequestIDWindow:      durationjson.Duration(time.Minute),
+		BackupQuiesceTimeout: durationjson.Duration(backup.DefaultQuiesceTimeout),
 	}
 }
 
@@ -4,11 +4,34 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"code.cloudfoundry.org/debugserver"
+	"code.cloudfoundry.org/durationjson"
 	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
 	"code.cloudfoundry.org/lager/lagerflags"
+	"code.cloudfoundry.org/locket/allowlist"
+	"code.cloudfoundry.org/locket/audit"
 	"code.cloudfoundry.org/locket/cmd/locket/config"
+	"code.cloudfoundry.org/locket/dbtuning"
+	"code.cloudfoundry.org/locket/deadline"
+	"code.cloudfoundry.org/locket/handoff"
+	"code.cloudfoundry.org/locket/iamauth"
+	"code.cloudfoundry.org/locket/loadreport"
+	"code.cloudfoundry.org/locket/logsampling"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/sinks"
+	"code.cloudfoundry.org/locket/natsbridge"
+	"code.cloudfoundry.org/locket/overload"
+	"code.cloudfoundry.org/locket/partition"
+	"code.cloudfoundry.org/locket/raftdb"
+	"code.cloudfoundry.org/locket/replication"
+	"code.cloudfoundry.org/locket/revocation"
+	"code.cloudfoundry.org/locket/secrets"
+	"code.cloudfoundry.org/locket/tokenauth"
+	"code.cloudfoundry.org/locket/tracing"
+	"code.cloudfoundry.org/locket/vaultpki"
+	"code.cloudfoundry.org/locket/webhook"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -24,12 +47,171 @@ var _ = Describe("LocketConfig", func() {
 			"database_driver": "mysql",
 			"max_open_database_connections": 1000,
 			"database_connection_string": "stuff",
+			"database_clock_sync_interval": "30s",
+			"database_clock_skew_threshold": "500ms",
+			"database_time_mode": "now",
 			"debug_address": "some-more-stuff",
 			"consul_cluster": "http://127.0.0.1:1234,http://127.0.0.1:12345",
 			"ca_file": "i am a ca file",
 			"cert_file": "i am a cert file",
 			"key_file": "i am a key file",
 			"sql_ca_cert_file": "/var/vcap/jobs/locket/config/sql.ca",
+			"systemd_socket_activation": true,
+			"access_log_path": "/var/vcap/sys/log/locket/access.log",
+			"access_log_sample_rate": 0.25,
+			"backup_quiesce_timeout": "2m",
+			"acl_policy_file": "/var/vcap/jobs/locket/config/acl.json",
+			"acl_audit_log_path": "/var/vcap/sys/log/locket/acl-audit.log",
+			"event_outbox_interval": "1s",
+			"fips_mode": true,
+			"grpc_web_listen_address": "1.2.3.4:9091",
+			"grpc_web_allowed_origins": ["https://dashboard.example.com"],
+			"group_commit_window": "5ms",
+			"health_drain_delay": "15s",
+			"hot_key_window": "2s",
+			"load_reporting": {
+				"enabled": true,
+				"interval": "2s"
+			},
+			"log_sampling": {
+				"locket.lock": {"one_in": 100}
+			},
+			"stateless_expiration": true,
+			"leader_election": true,
+			"slow_query_threshold": "500ms",
+			"slow_rpc_threshold": "1s",
+			"session_admin_enabled": true,
+			"session_admin_identities": ["operator-*"],
+			"read_cache_max_staleness": "250ms",
+			"request_id_window": "30s",
+			"response_compression": "snappy",
+			"traffic_record_path": "/var/vcap/data/locket/traffic.jsonl",
+			"traffic_record_max_megabytes": 50,
+			"storage_mode": "raft",
+			"max_hold": {
+				"default": "1h",
+				"keys": {"auctioneer": "5m"}
+			},
+			"metric_key_prefixes": [
+				{"name": "bbs", "prefix": "bbs"},
+				{"name": "auctioneer", "prefix": "auctioneer"}
+			],
+			"metrics": {
+				"sinks": ["loggregator", "prometheus", "statsd"],
+				"tags": {"instance_id": "locket-0", "az": "z1"},
+				"prometheus": {"listen_address": "127.0.0.1:9090", "namespace": "locket"},
+				"statsd": {"address": "127.0.0.1:8125", "prefix": "locket."}
+			},
+			"nats": {
+				"servers": ["nats://10.0.0.5:4222"],
+				"subject_prefix": "cf.locket",
+				"ca_cert_file": "/var/vcap/jobs/locket/config/nats.ca"
+			},
+			"overload": {
+				"enabled": true,
+				"min_limit": 20,
+				"max_limit": 500,
+				"retry_after": "2s"
+			},
+			"partition_detection": {
+				"interval": "5s",
+				"failure_threshold": 4
+			},
+			"raft": {
+				"bind_address": "10.0.0.1:8892",
+				"data_dir": "/var/vcap/store/locket/raft",
+				"peers": ["10.0.0.1:8892", "10.0.0.2:8892"]
+			},
+			"credhub": {
+				"url": "https://credhub.service.cf.internal:8844",
+				"ca_cert_file": "/var/vcap/jobs/locket/config/credhub.ca",
+				"client_id": "locket",
+				"client_secret": "uaa-secret",
+				"database_credential_name": "/cf/locket/database",
+				"tls_credential_name": "/cf/locket/server",
+				"refresh_interval": "10m"
+			},
+			"database_iam": {
+				"provider": "aws",
+				"username": "locket",
+				"region": "us-east-1",
+				"endpoint": "locket.abc123.us-east-1.rds.amazonaws.com:3306"
+			},
+			"database_tuning": {
+				"size": "large",
+				"isolation": "repeatable-read",
+				"connect_attempts": 3
+			},
+			"handoff": {
+				"socket_path": "/var/vcap/data/locket/handoff.sock"
+			},
+			"allowlist": {
+				"cidrs": ["10.0.0.0/8"],
+				"identities": {
+					"auctioneer": ["10.0.16.0/20"]
+				}
+			},
+			"audit": {
+				"syslog": {
+					"address": "syslog.example.com:6514",
+					"ca_cert_file": "/var/vcap/jobs/locket/config/syslog.ca",
+					"cert_file": "/var/vcap/jobs/locket/config/syslog.crt",
+					"key_file": "/var/vcap/jobs/locket/config/syslog.key",
+					"app_name": "locket-z1"
+				},
+				"file": {
+					"path": "/var/vcap/sys/log/locket/audit.log",
+					"max_size_in_megabytes": 50,
+					"max_backups": 10,
+					"max_age_in_days": 30,
+					"compress": true
+				}
+			},
+			"request_timeouts": {
+				"default": "10s",
+				"methods": {"FetchAll": "30s"}
+			},
+			"revocation": {
+				"crl_file": "/var/vcap/jobs/locket/config/ca.crl",
+				"crl_url": "https://ca.example.com/ca.crl",
+				"crl_refresh_interval": "1h",
+				"ocsp": true,
+				"fail_open": true
+			},
+			"token_auth": {
+				"static_tokens": {"bbs": "some-secret"},
+				"jwt_issuer": "https://uaa.example.com/oauth/token",
+				"jwt_audience": "locket",
+				"jwt_verification_key_file": "/var/vcap/jobs/locket/config/uaa.pub"
+			},
+			"webhooks": [{
+				"url": "https://pager.example.com/hooks/locket",
+				"secret": "hook-secret",
+				"key_prefixes": ["auctioneer", "bbs"],
+				"ca_cert_file": "/var/vcap/jobs/locket/config/pager.ca",
+				"max_retries": 3
+			}],
+			"vault": {
+				"address": "https://vault.service.cf.internal:8200",
+				"ca_cert_file": "/var/vcap/jobs/locket/config/vault.ca",
+				"approle_role_id": "locket-role",
+				"approle_secret_id": "locket-secret",
+				"mount": "pki_int",
+				"role": "locket-server",
+				"common_name": "locket.service.cf.internal",
+				"alt_names": ["*.locket.service.cf.internal"],
+				"ip_sans": ["10.0.0.1"],
+				"ttl": "24h"
+			},
+			"warm_standby": {
+				"peer_addresses": ["10.0.0.2:8891"],
+				"snapshot_interval": "2s"
+			},
+			"tracing": {
+				"otlp_endpoint": "localhost:4317",
+				"insecure": true,
+				"sample_ratio": 0.5
+			},
 			"loggregator": {
 			  "loggregator_use_v2_api": true,
 			  "loggregator_api_port": 1234,
@@ -70,6 +252,9 @@ var _ = Describe("LocketConfig", func() {
 			ListenAddress:              "1.2.3.4:9090",
 			DatabaseConnectionString:   "stuff",
 			MaxOpenDatabaseConnections: 1000,
+			DatabaseClockSyncInterval:  durationjson.Duration(30 * time.Second),
+			DatabaseClockSkewThreshold: durationjson.Duration(500 * time.Millisecond),
+			DatabaseTimeMode:           "now",
 			ConsulCluster:              "http://127.0.0.1:1234,http://127.0.0.1:12345",
 			LagerConfig: lagerflags.LagerConfig{
 				LogLevel: "debug",
@@ -77,10 +262,176 @@ var _ = Describe("LocketConfig", func() {
 			DebugServerConfig: debugserver.DebugServerConfig{
 				DebugAddress: "some-more-stuff",
 			},
-			CaFile:        "i am a ca file",
-			CertFile:      "i am a cert file",
-			KeyFile:       "i am a key file",
-			SQLCACertFile: "/var/vcap/jobs/locket/config/sql.ca",
+			CaFile:                    "i am a ca file",
+			CertFile:                  "i am a cert file",
+			KeyFile:                   "i am a key file",
+			SQLCACertFile:             "/var/vcap/jobs/locket/config/sql.ca",
+			SystemdSocketActivation:   true,
+			AccessLogPath:             "/var/vcap/sys/log/locket/access.log",
+			AccessLogSampleRate:       0.25,
+			BackupQuiesceTimeout:      durationjson.Duration(2 * time.Minute),
+			ACLPolicyFile:             "/var/vcap/jobs/locket/config/acl.json",
+			ACLAuditLogPath:           "/var/vcap/sys/log/locket/acl-audit.log",
+			EventOutboxInterval:       durationjson.Duration(time.Second),
+			FIPSMode:                  true,
+			GRPCWebListenAddress:      "1.2.3.4:9091",
+			GRPCWebAllowedOrigins:     []string{"https://dashboard.example.com"},
+			GroupCommitWindow:         durationjson.Duration(5 * time.Millisecond),
+			HealthDrainDelay:          durationjson.Duration(15 * time.Second),
+			HotKeyWindow:              durationjson.Duration(2 * time.Second),
+			StatelessExpiration:       true,
+			LeaderElection:            true,
+			SlowQueryThreshold:        durationjson.Duration(500 * time.Millisecond),
+			SlowRPCThreshold:          durationjson.Duration(time.Second),
+			SessionAdminEnabled:       true,
+			SessionAdminIdentities:    []string{"operator-*"},
+			ReadCacheMaxStaleness:     durationjson.Duration(250 * time.Millisecond),
+			RequestIDWindow:           durationjson.Duration(30 * time.Second),
+			ResponseCompression:       "snappy",
+			TrafficRecordPath:         "/var/vcap/data/locket/traffic.jsonl",
+			TrafficRecordMaxMegabytes: 50,
+			StorageMode:               "raft",
+			MaxHoldConfig: config.MaxHoldConfig{
+				Default: durationjson.Duration(time.Hour),
+				Keys: map[string]durationjson.Duration{
+					"auctioneer": durationjson.Duration(5 * time.Minute),
+				},
+			},
+			MetricKeyPrefixes: []metrics.KeyPrefix{
+				{Name: "bbs", Prefix: "bbs"},
+				{Name: "auctioneer", Prefix: "auctioneer"},
+			},
+			MetricsConfig: sinks.Config{
+				Sinks: []string{"loggregator", "prometheus", "statsd"},
+				Tags:  map[string]string{"instance_id": "locket-0", "az": "z1"},
+				Prometheus: sinks.PrometheusConfig{
+					ListenAddress: "127.0.0.1:9090",
+					Namespace:     "locket",
+				},
+				StatsD: sinks.StatsDConfig{
+					Address: "127.0.0.1:8125",
+					Prefix:  "locket.",
+				},
+			},
+			NATSConfig: natsbridge.Config{
+				Servers:       []string{"nats://10.0.0.5:4222"},
+				SubjectPrefix: "cf.locket",
+				CACertFile:    "/var/vcap/jobs/locket/config/nats.ca",
+			},
+			OverloadConfig: overload.Config{
+				Enabled:    true,
+				MinLimit:   20,
+				MaxLimit:   500,
+				RetryAfter: durationjson.Duration(2 * time.Second),
+			},
+			PartitionDetectionConfig: partition.Config{
+				Interval:         durationjson.Duration(5 * time.Second),
+				FailureThreshold: 4,
+			},
+			RaftConfig: raftdb.Config{
+				BindAddress: "10.0.0.1:8892",
+				DataDir:     "/var/vcap/store/locket/raft",
+				Peers:       []string{"10.0.0.1:8892", "10.0.0.2:8892"},
+			},
+			CredHubConfig: secrets.Config{
+				URL:                    "https://credhub.service.cf.internal:8844",
+				CACertFile:             "/var/vcap/jobs/locket/config/credhub.ca",
+				ClientID:               "locket",
+				ClientSecret:           "uaa-secret",
+				DatabaseCredentialName: "/cf/locket/database",
+				TLSCredentialName:      "/cf/locket/server",
+				RefreshInterval:        durationjson.Duration(10 * time.Minute),
+			},
+			DatabaseIAMConfig: iamauth.Config{
+				Provider: "aws",
+				Username: "locket",
+				Region:   "us-east-1",
+				Endpoint: "locket.abc123.us-east-1.rds.amazonaws.com:3306",
+			},
+			DatabaseTuningConfig: dbtuning.Config{
+				Size:            "large",
+				Isolation:       "repeatable-read",
+				ConnectAttempts: 3,
+			},
+			HandoffConfig: handoff.Config{
+				SocketPath: "/var/vcap/data/locket/handoff.sock",
+			},
+			AllowlistConfig: allowlist.Config{
+				CIDRs: []string{"10.0.0.0/8"},
+				Identities: map[string][]string{
+					"auctioneer": {"10.0.16.0/20"},
+				},
+			},
+			AuditConfig: audit.Config{
+				Syslog: audit.SyslogConfig{
+					Address:    "syslog.example.com:6514",
+					CACertFile: "/var/vcap/jobs/locket/config/syslog.ca",
+					CertFile:   "/var/vcap/jobs/locket/config/syslog.crt",
+					KeyFile:    "/var/vcap/jobs/locket/config/syslog.key",
+					AppName:    "locket-z1",
+				},
+				File: audit.FileConfig{
+					Path:               "/var/vcap/sys/log/locket/audit.log",
+					MaxSizeInMegabytes: 50,
+					MaxBackups:         10,
+					MaxAgeInDays:       30,
+					Compress:           true,
+				},
+			},
+			RequestTimeoutConfig: deadline.Config{
+				Default: durationjson.Duration(10 * time.Second),
+				Methods: map[string]durationjson.Duration{
+					"FetchAll": durationjson.Duration(30 * time.Second),
+				},
+			},
+			RevocationConfig: revocation.Config{
+				CRLFile:            "/var/vcap/jobs/locket/config/ca.crl",
+				CRLURL:             "https://ca.example.com/ca.crl",
+				CRLRefreshInterval: durationjson.Duration(time.Hour),
+				OCSP:               true,
+				FailOpen:           true,
+			},
+			TokenAuthConfig: tokenauth.Config{
+				StaticTokens:           map[string]string{"bbs": "some-secret"},
+				JWTIssuer:              "https://uaa.example.com/oauth/token",
+				JWTAudience:            "locket",
+				JWTVerificationKeyFile: "/var/vcap/jobs/locket/config/uaa.pub",
+			},
+			TracingConfig: tracing.Config{
+				OTLPEndpoint: "localhost:4317",
+				Insecure:     true,
+				SampleRatio:  0.5,
+			},
+			VaultConfig: vaultpki.Config{
+				Address:         "https://vault.service.cf.internal:8200",
+				CACertFile:      "/var/vcap/jobs/locket/config/vault.ca",
+				AppRoleID:       "locket-role",
+				AppRoleSecretID: "locket-secret",
+				Mount:           "pki_int",
+				Role:            "locket-server",
+				CommonName:      "locket.service.cf.internal",
+				AltNames:        []string{"*.locket.service.cf.internal"},
+				IPSANs:          []string{"10.0.0.1"},
+				TTL:             durationjson.Duration(24 * time.Hour),
+			},
+			WarmStandbyConfig: replication.Config{
+				PeerAddresses:    []string{"10.0.0.2:8891"},
+				SnapshotInterval: durationjson.Duration(2 * time.Second),
+			},
+			Webhooks: []webhook.Config{{
+				URL:         "https://pager.example.com/hooks/locket",
+				Secret:      "hook-secret",
+				KeyPrefixes: []string{"auctioneer", "bbs"},
+				CACertFile:  "/var/vcap/jobs/locket/config/pager.ca",
+				MaxRetries:  3,
+			}},
+			LoadReportingConfig: loadreport.Config{
+				Enabled:  true,
+				Interval: durationjson.Duration(2 * time.Second),
+			},
+			LogSampling: logsampling.Rules{
+				"locket.lock": {OneIn: 100},
+			},
 			LoggregatorConfig: loggregator_v2.Config{
 				UseV2API:      true,
 				APIPort:       1234,
@@ -98,6 +449,63 @@ var _ = Describe("LocketConfig", func() {
 		Expect(locketConfig).To(Equal(config))
 	})
 
+	Context("NewStrictLocketConfig", func() {
+		It("parses the config file as NewLocketConfig does", func() {
+			strictConfig, err := config.NewStrictLocketConfig(configFilePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			locketConfig, err := config.NewLocketConfig(configFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strictConfig).To(Equal(locketConfig))
+		})
+
+		Context("when a field is misspelt", func() {
+			BeforeEach(func() {
+				configData = `{"datbase_driver": "postgres"}`
+			})
+
+			It("names the field", func() {
+				_, err := config.NewStrictLocketConfig(configFilePath)
+				Expect(err).To(MatchError("unknown field datbase_driver"))
+			})
+		})
+
+		Context("when a nested field is unknown", func() {
+			BeforeEach(func() {
+				configData = `{"webhooks": [{"url": "https://example.com"}, {"ulr": "https://example.com"}]}`
+			})
+
+			It("names the path to the field", func() {
+				_, err := config.NewStrictLocketConfig(configFilePath)
+				Expect(err).To(MatchError("unknown field webhooks[1].ulr"))
+			})
+		})
+
+		Context("when a value has the wrong type", func() {
+			BeforeEach(func() {
+				configData = `{"overload": {"min_limit": "ten"}}`
+			})
+
+			It("names the path to the field", func() {
+				_, err := config.NewStrictLocketConfig(configFilePath)
+				Expect(err).To(MatchError(ContainSubstring("overload.min_limit")))
+			})
+		})
+
+		Context("when serialized from LocketConfig", func() {
+			BeforeEach(func() {
+				bytes, err := json.Marshal(config.DefaultLocketConfig())
+				Expect(err).NotTo(HaveOccurred())
+				configData = string(bytes)
+			})
+
+			It("accepts every field", func() {
+				_, err := config.NewStrictLocketConfig(configFilePath)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
 	Context("when the file does not exist", func() {
 		It("returns an error", func() {
 			_, err := config.NewLocketConfig("foobar")
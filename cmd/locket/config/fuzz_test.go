@@ -0,0 +1,27 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.cloudfoundry.org/locket/cmd/locket/config"
+)
+
+// FuzzNewLocketConfig checks that no config file makes the parser panic.
+// The corpus is kept in testdata/fuzz.
+func FuzzNewLocketConfig(f *testing.F) {
+	f.Add([]byte(`{"listen_address": "1.2.3.4:9090", "database_driver": "mysql", "log_level": "debug"}`))
+	f.Add([]byte(`{"request_id_window": "30s", "slow_query_threshold": "1s", "max_hold": {"default": "1h", "keys": {"key": "5m"}}}`))
+	f.Add([]byte(`{"storage_mode": "raft", "raft": {}, "allowlist": {}, "token_auth": {}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		path := filepath.Join(t.TempDir(), "locket.json")
+		err := os.WriteFile(path, data, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config.NewLocketConfig(path)
+	})
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// NewStrictLocketConfig is like NewLocketConfig, but rejects fields that the
+// config does not have, such as a misspelt "datbase_driver", and values of
+// the wrong type. Its errors name the path to the offending field, for
+// example "max_hold.keys[bbs]".
+func NewStrictLocketConfig(configPath string) (LocketConfig, error) {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return LocketConfig{}, err
+	}
+
+	err = checkFields(data, reflect.TypeOf(LocketConfig{}), "")
+	if err != nil {
+		return LocketConfig{}, err
+	}
+
+	locketConfig := DefaultLocketConfig()
+	err = json.Unmarshal(data, &locketConfig)
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field != "" {
+		return LocketConfig{}, fmt.Errorf("invalid value for %s: cannot use %s as %s", typeErr.Field, typeErr.Value, typeErr.Type)
+	}
+	if err != nil {
+		return LocketConfig{}, err
+	}
+
+	return locketConfig, nil
+}
+
+// checkFields returns an error for the first field in data that t does not
+// have. It leaves invalid JSON and values of the wrong type for
+// json.Unmarshal to report.
+func checkFields(data []byte, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) ||
+		t.Implements(textUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return nil
+		}
+
+		fields := jsonFields(t)
+		for name, value := range object {
+			field, ok := fields[name]
+			if !ok {
+				field, ok = matchFold(fields, name)
+			}
+			fieldPath := joinPath(path, name)
+			if !ok {
+				return fmt.Errorf("unknown field %s", fieldPath)
+			}
+
+			err := checkFields(value, field, fieldPath)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return nil
+		}
+
+		for key, value := range object {
+			err := checkFields(value, t.Elem(), fmt.Sprintf("%s[%s]", path, key))
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var array []json.RawMessage
+		if json.Unmarshal(data, &array) != nil {
+			return nil
+		}
+
+		for i, value := range array {
+			err := checkFields(value, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonFields returns the types of the fields of t by JSON name, including
+// those of embedded structs, as encoding/json sees them.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for name, typ := range jsonFields(embedded) {
+					if _, ok := fields[name]; !ok {
+						fields[name] = typ
+					}
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// matchFold finds the field whose name matches name case-insensitively, as
+// encoding/json does when there is no exact match.
+func matchFold(fields map[string]reflect.Type, name string) (reflect.Type, bool) {
+	for fieldName, typ := range fields {
+		if strings.EqualFold(fieldName, name) {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
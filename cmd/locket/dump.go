@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+)
+
+// dumpLocks writes every lock in sqlDB to path as JSON.
+func dumpLocks(logger lager.Logger, sqlDB *db.SQLDB, path string) error {
+	dump, err := sqlDB.Dump(logger)
+	if err != nil {
+		return err
+	}
+
+	dumpFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(dumpFile)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(dump)
+	if err != nil {
+		dumpFile.Close()
+		return err
+	}
+	return dumpFile.Close()
+}
+
+// restoreLocks reads a dump written by dumpLocks from path into sqlDB.
+func restoreLocks(logger lager.Logger, sqlDB *db.SQLDB, path string) error {
+	dumpFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dumpFile.Close()
+
+	var dump db.Dump
+	err = json.NewDecoder(dumpFile).Decode(&dump)
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Restore(logger, &dump)
+}
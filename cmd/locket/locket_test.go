@@ -25,6 +25,9 @@ import (
 	"github.com/onsi/gomega/gbytes"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/ginkgomon"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 var _ = Describe("Locket", func() {
@@ -247,6 +250,40 @@ var _ = Describe("Locket", func() {
 			Expect(err).To(HaveOccurred())
 		})
 
+		It("returns the fencing token and expiry of the acquired lock", func() {
+			requestedResource := &models.Resource{Key: "test", Value: "test-data", Owner: "jim", Type: "lock"}
+			resp, err := locketClient.Lock(context.Background(), &models.LockRequest{
+				Resource:     requestedResource,
+				TtlInSeconds: 10,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Resource.Owner).To(Equal("jim"))
+			Expect(resp.FencingToken).To(BeNumerically(">", 0))
+			Expect(resp.ExpiresAt).To(BeNumerically(">", time.Now().UnixNano()))
+		})
+
+		It("returns the current holder when the lock is taken", func() {
+			_, err := locketClient.Lock(context.Background(), &models.LockRequest{
+				Resource:     &models.Resource{Key: "test", Value: "test-data", Owner: "jim", Type: "lock"},
+				TtlInSeconds: 10,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			var trailer metadata.MD
+			_, err = locketClient.Lock(context.Background(), &models.LockRequest{
+				Resource:     &models.Resource{Key: "test", Value: "test-data", Owner: "nima", Type: "lock"},
+				TtlInSeconds: 10,
+			}, grpc.Trailer(&trailer))
+			Expect(grpc.Code(err)).To(Equal(codes.AlreadyExists))
+
+			holder, err := models.LockHolderFromTrailer(trailer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(holder.Key).To(Equal("test"))
+			Expect(holder.Owner).To(Equal("jim"))
+			Expect(holder.RemainingTtlInMilliseconds).To(BeNumerically(">", 0))
+			Expect(holder.RemainingTtlInMilliseconds).To(BeNumerically("<=", 10000))
+		})
+
 		It("expires after a ttl", func() {
 			requestedResource := &models.Resource{Key: "test", Value: "test-data", Owner: "jim", Type: "lock"}
 			_, err := locketClient.Lock(context.Background(), &models.LockRequest{
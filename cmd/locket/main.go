@@ -4,10 +4,12 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"time"
 
@@ -19,27 +21,76 @@ import (
 	"github.com/lib/pq"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/grouper"
+	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"code.cloudfoundry.org/bbs/guidprovider"
 	"code.cloudfoundry.org/cfhttp"
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/consuladapter"
-	"code.cloudfoundry.org/debugserver"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagerflags"
 	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/accesslog"
+	"code.cloudfoundry.org/locket/acl"
+	"code.cloudfoundry.org/locket/allowlist"
+	"code.cloudfoundry.org/locket/audit"
+	"code.cloudfoundry.org/locket/backup"
+	"code.cloudfoundry.org/locket/chaos"
 	"code.cloudfoundry.org/locket/cmd/locket/config"
+	"code.cloudfoundry.org/locket/compression"
+	"code.cloudfoundry.org/locket/contention"
 	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/dbtuning"
+	"code.cloudfoundry.org/locket/deadline"
+	"code.cloudfoundry.org/locket/deadlock"
+	"code.cloudfoundry.org/locket/election"
+	"code.cloudfoundry.org/locket/events"
 	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/fips"
 	"code.cloudfoundry.org/locket/grpcserver"
 	"code.cloudfoundry.org/locket/handlers"
+	"code.cloudfoundry.org/locket/handoff"
+	"code.cloudfoundry.org/locket/iamauth"
+	"code.cloudfoundry.org/locket/loadreport"
+	"code.cloudfoundry.org/locket/logsampling"
 	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/metrics/sinks"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/natsbridge"
+	"code.cloudfoundry.org/locket/overload"
+	"code.cloudfoundry.org/locket/partition"
+	"code.cloudfoundry.org/locket/raftdb"
+	"code.cloudfoundry.org/locket/readcache"
+	"code.cloudfoundry.org/locket/replication"
+	"code.cloudfoundry.org/locket/revocation"
+	"code.cloudfoundry.org/locket/secrets"
+	"code.cloudfoundry.org/locket/sessions"
+	"code.cloudfoundry.org/locket/slowlog"
+	"code.cloudfoundry.org/locket/statedump"
+	"code.cloudfoundry.org/locket/tokenauth"
+	"code.cloudfoundry.org/locket/tracing"
+	"code.cloudfoundry.org/locket/traffic"
+	"code.cloudfoundry.org/locket/vaultpki"
+	"code.cloudfoundry.org/locket/webhook"
 )
 
 const (
 	dropsondeOrigin = "locket"
 	metricsInterval = 10 * time.Second
+
+	contentionWindow = 5 * time.Minute
+	contentionTopN   = 5
+
+	deadlockDetectionInterval = time.Second
+
+	revocationTimeout = 5 * time.Second
+	aclReloadInterval = 10 * time.Second
 )
 
 var configFilePath = flag.String(
@@ -48,10 +99,34 @@ var configFilePath = flag.String(
 	"Path to Locket JSON Configuration file",
 )
 
+var strictConfig = flag.Bool(
+	"strictConfig",
+	false,
+	"Reject configuration files with unknown fields or values of the wrong type",
+)
+
+var dumpFilePath = flag.String(
+	"dump",
+	"",
+	"Write every lock in the SQL database to this JSON file and exit",
+)
+
+var restoreFilePath = flag.String(
+	"restore",
+	"",
+	"Restore the locks in this JSON file, written by -dump, into the SQL database and exit",
+)
+
+var copyToConfigFilePath = flag.String(
+	"copyTo",
+	"",
+	"Copy every lock in the SQL database to the SQL database in this Locket JSON Configuration file and exit",
+)
+
 func main() {
 	flag.Parse()
 
-	cfg, err := config.NewLocketConfig(*configFilePath)
+	cfg, err := loadConfig(*configFilePath)
 	if err != nil {
 		logger, _ := lagerflags.New("locket")
 		logger.Fatal("invalid-config-file", err)
@@ -59,44 +134,127 @@ func main() {
 
 	logger, reconfigurableSink := lagerflags.NewFromConfig("locket", cfg.LagerConfig)
 
-	metronClient, err := initializeMetron(logger, cfg)
+	if flag.Arg(0) == "preflight" {
+		if !runPreflight(logger, cfg, clock.NewClock()) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	metricsEmitter, prometheusMetrics, err := initializeMetrics(logger, cfg)
 	if err != nil {
-		logger.Error("failed-to-initialize-metron-client", err)
+		logger.Error("failed-to-initialize-metrics", err)
 		os.Exit(1)
 	}
 
+	shutdownTracing, err := tracing.Initialize(logger, "locket", cfg.TracingConfig)
+	if err != nil {
+		logger.Fatal("failed-to-initialize-tracing", err)
+	}
+	logger.RegisterSink(tracing.NewFatalFlushSink(shutdownTracing))
+	defer tracing.Flush(shutdownTracing)
+
 	clock := clock.NewClock()
 
-	connectionString := appendExtraConnectionStringParam(
-		logger,
-		cfg.DatabaseDriver,
-		cfg.DatabaseConnectionString,
-		cfg.SQLCACertFile,
-	)
+	if *dumpFilePath != "" || *restoreFilePath != "" {
+		dumpOrRestore(logger, cfg, clock)
+		return
+	}
 
-	sqlConn, err := sql.Open(cfg.DatabaseDriver, connectionString)
-	if err != nil {
-		logger.Fatal("failed-to-open-sql", err)
+	if *copyToConfigFilePath != "" {
+		copyLocks(logger, cfg, clock)
+		return
 	}
-	defer sqlConn.Close()
 
-	sqlConn.SetMaxIdleConns(cfg.MaxOpenDatabaseConnections)
-	sqlConn.SetMaxOpenConns(cfg.MaxOpenDatabaseConnections)
+	credHub := initializeCredHub(logger, cfg, clock)
 
-	err = sqlConn.Ping()
-	if err != nil {
-		logger.Fatal("sql-failed-to-connect", err)
+	maxHolds := make(map[string]time.Duration, len(cfg.MaxHoldConfig.Keys))
+	for key, maxHold := range cfg.MaxHoldConfig.Keys {
+		maxHolds[key] = time.Duration(maxHold)
 	}
+	defaultMaxHold := time.Duration(cfg.MaxHoldConfig.Default)
 
-	sqlDB := db.NewSQLDB(
-		sqlConn,
-		cfg.DatabaseDriver,
-		guidprovider.DefaultGuidProvider,
-	)
+	var lockDB db.LockDB
+	// with SQL storage, the handlers get their own database, which enforces
+	// the maximum hold time of the locks, and with the event outbox, the
+	// handlers and the expirer record their changes in the database instead
+	// of sharing lockDB
+	var outboxSQLDB *db.SQLDB
+	var handlerBaseDB, expirerBaseDB db.LockDB
+	// handlerMaxHold is whether the handlers have to enforce the maximum
+	// hold time because the database cannot
+	handlerMaxHold := false
+	// sentinelDB is where the partition detector checks that the server can
+	// still write
+	var sentinelDB *db.SQLDB
+	var dbClock *db.DatabaseClock
+	// expiryExtender pushes back the expiry of the locks after a backup
+	var expiryExtender backup.Extender
+	switch cfg.StorageMode {
+	case config.RaftStorageMode:
+		raftDB, err := raftdb.NewRaftDB(logger, cfg.RaftConfig, guidprovider.DefaultGuidProvider, clock)
+		if err != nil {
+			logger.Fatal("failed-to-start-raft", err)
+		}
+		defer raftDB.Shutdown()
+		lockDB = raftDB
+		handlerMaxHold = true
+	case config.SQLStorageMode, "":
+		sqlConn, sqlDB, sqlClock := initializeSQLDB(logger, cfg, clock, credHub)
+		defer sqlConn.Close()
+		lockDB = sqlDB
+		sentinelDB = sqlDB
+		dbClock = sqlClock
+		expiryExtender = sqlDB
+		if cfg.GroupCommitWindow > 0 {
+			lockDB = db.NewGroupCommitDB(sqlDB, sqlDB, clock, time.Duration(cfg.GroupCommitWindow))
+		}
+		handlerSQLDB := sqlDB.WithMaxHold(defaultMaxHold, maxHolds)
+		if cfg.EventOutboxInterval > 0 {
+			outboxSQLDB = sqlDB
+			handlerSQLDB = handlerSQLDB.WithOutbox(db.LockReleasedEvent)
+			expirerBaseDB = sqlDB.WithOutbox(db.LockExpiredEvent)
+		}
+		handlerBaseDB = handlerSQLDB
+		if cfg.GroupCommitWindow > 0 {
+			handlerBaseDB = db.NewGroupCommitDB(handlerSQLDB, handlerSQLDB, clock, time.Duration(cfg.GroupCommitWindow))
+		}
+	default:
+		logger.Fatal("invalid-storage-mode", fmt.Errorf("unknown storage mode: %s", cfg.StorageMode))
+	}
 
-	err = sqlDB.CreateLockTable(logger)
-	if err != nil {
-		logger.Fatal("failed-to-create-lock-table", err)
+	if dbClock != nil {
+		dbClock.AlertOnSkew(metricsEmitter, time.Duration(cfg.DatabaseClockSkewThreshold))
+		// the expiry timestamps in the database are in its time, which the
+		// handlers, the expirer and the read cache compare them with
+		clock = dbClock
+	}
+
+	var limiter *overload.Limiter
+	if cfg.OverloadConfig.Enabled {
+		limiter = overload.NewLimiter(logger, clock, metricsEmitter, cfg.OverloadConfig)
+	}
+
+	// observe adds the slow query log and overload protection to a database
+	observe := func(lockDB db.LockDB) db.LockDB {
+		if cfg.SlowQueryThreshold > 0 {
+			lockDB = db.NewSlowQueryDB(lockDB, clock, time.Duration(cfg.SlowQueryThreshold))
+		}
+		if limiter != nil {
+			lockDB = limiter.LockDB(lockDB)
+		}
+		return lockDB
+	}
+	lockDB = observe(lockDB)
+	if handlerBaseDB != nil {
+		handlerBaseDB = observe(handlerBaseDB)
+	} else {
+		handlerBaseDB = lockDB
+	}
+	if expirerBaseDB != nil {
+		expirerBaseDB = observe(expirerBaseDB)
+	} else {
+		expirerBaseDB = lockDB
 	}
 
 	consulClient, err := consuladapter.NewClientFromUrl(cfg.ConsulCluster)
@@ -104,7 +262,46 @@ func main() {
 		logger.Fatal("new-consul-client-failed", err)
 	}
 
-	_, portString, err := net.SplitHostPort(cfg.ListenAddress)
+	listenAddress := cfg.ListenAddress
+	var listener net.Listener
+	if cfg.SystemdSocketActivation {
+		listener, err = grpcserver.SystemdListener()
+		if err != nil {
+			logger.Fatal("failed-to-acquire-systemd-listener", err)
+		}
+		listenAddress = listener.Addr().String()
+		logger.Info("using-systemd-listener", lager.Data{"address": listenAddress})
+	}
+
+	// a process started while another serves handoffs takes over its socket
+	// and expiration timers instead of resetting them
+	var handoffListener net.Listener
+	var handoffTTLs []expiration.RegisteredTTL
+	if cfg.HandoffConfig.SocketPath != "" {
+		inherited, ttls, err := handoff.Receive(logger, cfg.HandoffConfig.SocketPath)
+		switch err {
+		case nil:
+			handoffTTLs = ttls
+			if listener != nil {
+				inherited.Close()
+			} else {
+				listener = inherited
+			}
+		case handoff.ErrNoPredecessor:
+			if listener == nil {
+				listener, err = net.Listen("tcp", cfg.ListenAddress)
+				if err != nil {
+					logger.Fatal("failed-to-listen", err)
+				}
+			}
+		default:
+			logger.Fatal("failed-to-receive-handoff", err)
+		}
+		handoffListener = listener
+		listenAddress = listener.Addr().String()
+	}
+
+	_, portString, err := net.SplitHostPort(listenAddress)
 	if err != nil {
 		logger.Fatal("failed-invalid-listen-address", err)
 	}
@@ -114,28 +311,461 @@ func main() {
 		logger.Fatal("failed-invalid-listen-port", err)
 	}
 
-	tlsConfig, err := cfhttp.NewTLSConfig(cfg.CertFile, cfg.KeyFile, cfg.CaFile)
+	var vaultRotator *vaultpki.Rotator
+	var tlsConfig *tls.Config
+	if cfg.VaultConfig.Enabled() {
+		vaultRotator = initializeVaultRotator(logger, cfg, clock)
+		tlsConfig, err = newRotatingTLSConfig(cfg.CaFile, vaultRotator.GetCertificate)
+	} else if credHub != nil && credHub.HasCertificate() {
+		tlsConfig, err = newRotatingTLSConfig(cfg.CaFile, credHub.GetCertificate)
+	} else {
+		tlsConfig, err = cfhttp.NewTLSConfig(cfg.CertFile, cfg.KeyFile, cfg.CaFile)
+	}
 	if err != nil {
 		logger.Fatal("invalid-tls-config", err)
 	}
 
-	metricsNotifier := metrics.NewMetricsNotifier(logger, clock, metronClient, metricsInterval, sqlDB)
-	lockPick := expiration.NewLockPick(sqlDB, clock)
-	burglar := expiration.NewBurglar(logger, sqlDB, lockPick, clock, locket.RetryInterval)
+	if cfg.FIPSMode {
+		fips.Restrict(tlsConfig)
+		err = fips.Verify(tlsConfig)
+		if err != nil {
+			logger.Fatal("failed-fips-verification", err)
+		}
+	}
+
+	var revocationChecker *revocation.Checker
+	if cfg.RevocationConfig.Enabled() {
+		revocationChecker, err = revocation.NewChecker(logger, clock, cfg.RevocationConfig, &http.Client{Timeout: revocationTimeout})
+		if err != nil {
+			logger.Fatal("failed-to-initialize-revocation-checker", err)
+		}
+		tlsConfig.VerifyPeerCertificate = revocationChecker.VerifyPeerCertificate
+	}
+
+	var authenticator *tokenauth.Authenticator
+	if cfg.TokenAuthConfig.Enabled() {
+		authenticator, err = tokenauth.NewAuthenticator(logger, cfg.TokenAuthConfig)
+		if err != nil {
+			logger.Fatal("failed-to-initialize-token-auth", err)
+		}
+		// clients authenticating with a token do not have a certificate
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	logger.Info("crypto-mode", lager.Data{"fips-mode": cfg.FIPSMode, "boringcrypto": fips.BoringCrypto})
+
+	// chaos only faults the database used to handle requests, so that the
+	// expirer, elector and metrics keep working during a game-day
+	var injector *chaos.Injector
+	handlerDB := handlerBaseDB
+	if cfg.ChaosEnabled {
+		logger.Info("chaos-enabled")
+		injector = chaos.NewInjector(logger, handlerBaseDB, clock, clock.Now().UnixNano())
+		handlerDB = injector.LockDB()
+	}
+
+	var sinks events.Sinks
+	if cfg.NATSConfig.Enabled() {
+		natsConn, err := natsbridge.Connect(logger, cfg.NATSConfig)
+		if err != nil {
+			logger.Fatal("failed-to-connect-to-nats", err)
+		}
+		defer natsConn.Close()
+		sinks = append(sinks, natsbridge.NewBridge(logger, natsConn, cfg.NATSConfig.SubjectPrefix))
+	}
+
+	var webhookNotifier *webhook.Notifier
+	if len(cfg.Webhooks) > 0 {
+		webhookNotifier, err = webhook.NewNotifier(logger, clock, cfg.Webhooks)
+		if err != nil {
+			logger.Fatal("invalid-webhook-config", err)
+		}
+		sinks = append(sinks, webhookNotifier)
+	}
+
+	expirerDB := expirerBaseDB
+	var outboxDispatcher ifrit.Runner
+	if outboxSQLDB != nil {
+		outboxDispatcher = events.NewOutboxDispatcher(logger, clock, outboxSQLDB, sinks, time.Duration(cfg.EventOutboxInterval))
+	} else if len(sinks) > 0 {
+		handlerDB = events.NewLockDB(handlerDB, clock, sinks)
+		expirerDB = events.NewExpirerLockDB(expirerDB, clock, sinks)
+	}
+
+	// writes wait while a backup is in progress, and expiration pauses, so
+	// that no lock expires because its heartbeats were held
+	backupGate := backup.NewGate(logger, expirerDB, expiryExtender, clock, time.Duration(cfg.BackupQuiesceTimeout))
+	handlerDB = backupGate.LockDB(handlerDB)
+	expirerDB = backupGate.ExpirerLockDB(expirerDB)
+
+	if cfg.ReadCacheMaxStaleness > 0 {
+		handlerDB = readcache.NewLockDB(handlerDB, clock, metricsEmitter, time.Duration(cfg.ReadCacheMaxStaleness))
+	}
+
+	metricsNotifier := metrics.NewMetricsNotifier(logger, clock, metricsEmitter, metricsInterval, lockDB)
+	keyTagger := metrics.NewKeyTagger(cfg.MetricKeyPrefixes)
+
+	// with leader election only the active server expires locks. The elector
+	// needs the lock pick, so it is created further down.
+	var activeElector interface {
+		IsActive() bool
+	}
+	isActive := election.ActiveFunc(func() bool { return activeElector.IsActive() })
+	if cfg.LeaderElection {
+		expirerDB = election.NewExpirerLockDB(expirerDB, isActive)
+	}
+
+	var lockPick expiration.LockPick
+	var expirer ifrit.Runner
+	if cfg.StatelessExpiration || cfg.StorageMode == config.RaftStorageMode {
+		lockPick = expiration.NewNoopLockPick()
+		expirer = expiration.NewSweeper(logger, expirerDB, clock, metricsEmitter, keyTagger, locket.RetryInterval)
+	} else {
+		lockPick = expiration.NewLockPick(expirerDB, clock, metricsEmitter, keyTagger)
+		expirer = expiration.NewBurglar(logger, expirerDB, lockPick, clock, metricsEmitter, keyTagger, locket.RetryInterval)
+	}
+	for _, ttl := range handoffTTLs {
+		lockPick.RestoreTTL(logger, ttl)
+	}
+	if cfg.LeaderElection {
+		expirer = election.WhileActive(logger, isActive, expirer, clock, locket.RetryInterval)
+	}
+
 	exitCh := make(chan struct{})
-	handler := handlers.NewLocketHandler(logger, sqlDB, lockPick, exitCh)
-	server := grpcserver.NewGRPCServer(logger, cfg.ListenAddress, tlsConfig, handler)
+	contentionTracker := contention.NewTracker(logger, clock, metricsEmitter, keyTagger, contentionWindow)
+	contentionNotifier := contention.NewNotifier(logger, contentionTracker, clock, metricsEmitter, metricsInterval, contentionTopN)
+	deadlockDetector := deadlock.NewDetector(logger, clock, deadlockDetectionInterval)
+
+	// the handler logs every call, so its logs go through a sampler that can
+	// thin out those of frequent calls such as heartbeats
+	logSampler, err := logsampling.NewSampler(reconfigurableSink, cfg.LogSampling)
+	if err != nil {
+		logger.Fatal("invalid-log-sampling", err)
+	}
+	handlerLogger := lager.NewLogger("locket")
+	handlerLogger.RegisterSink(logSampler)
+	handler := handlers.NewLocketHandler(handlerLogger, handlerDB, lockPick, contentionTracker, deadlockDetector, clock, exitCh)
+
+	if handlerMaxHold {
+		handler = handler.WithMaxHold(defaultMaxHold, maxHolds)
+	}
+	handler = handler.WithRequestIDWindow(time.Duration(cfg.RequestIDWindow))
+	handler = handler.WithHotKeyWindow(time.Duration(cfg.HotKeyWindow))
+
+	healthServer := grpcserver.NewHealthServer("models.Locket", "locket.v2.Locket")
+
+	// statuses go through the partition detector, so that it can keep the
+	// server unhealthy while it cannot write to the database
+	var health election.HealthStatusSetter = healthServer
+	var partitionDetector *partition.Detector
+	if cfg.PartitionDetectionConfig.Enabled() {
+		if sentinelDB == nil {
+			logger.Info("partition-detection-requires-sql-storage")
+		} else {
+			id, err := guidprovider.DefaultGuidProvider.NextGUID()
+			if err != nil {
+				logger.Fatal("failed-to-generate-sentinel-id", err)
+			}
+			partitionDetector = partition.NewDetector(logger, sentinelDB, healthServer, id, clock, cfg.PartitionDetectionConfig)
+			health = partitionDetector
+		}
+	}
+	health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		otelgrpc.UnaryServerInterceptor(),
+		metrics.NewRequestMetricsInterceptor(logger, clock, metricsEmitter, keyTagger),
+	}
+	var streamInterceptors []grpc.StreamServerInterceptor
+
+	// before the interceptors that can hold a request up, so that the time
+	// they take shows as queue wait
+	if cfg.SlowRPCThreshold > 0 {
+		interceptors = append(interceptors, slowlog.NewInterceptor(logger, clock, time.Duration(cfg.SlowRPCThreshold)))
+	}
+
+	if cfg.RequestTimeoutConfig.Enabled() {
+		interceptors = append(interceptors, deadline.NewInterceptor(logger, cfg.RequestTimeoutConfig))
+	}
+
+	if limiter != nil {
+		interceptors = append(interceptors, limiter.NewInterceptor())
+	}
+
+	if authenticator != nil {
+		interceptors = append(interceptors, authenticator.NewInterceptor())
+		streamInterceptors = append(streamInterceptors, authenticator.NewStreamInterceptor())
+	}
+
+	var authorizer *acl.Authorizer
+	var auditSyslog *audit.SyslogSink
+	if cfg.ACLPolicyFile == "" && (cfg.AuditConfig.Enabled() || cfg.ACLAuditLogPath != "") {
+		logger.Fatal("invalid-audit-config", audit.ErrNoACLPolicy)
+	}
+	if cfg.ACLPolicyFile != "" {
+		auditLogger := logger
+		if cfg.ACLAuditLogPath != "" {
+			auditLogger, err = accesslog.NewFileLogger(cfg.ACLAuditLogPath)
+			if err != nil {
+				logger.Fatal("failed-to-open-acl-audit-log", err)
+			}
+		} else if cfg.AuditConfig.Enabled() {
+			// a logger of its own, so that only audit events reach the audit sinks
+			auditLogger = lager.NewLogger("locket")
+			auditLogger.RegisterSink(reconfigurableSink)
+		}
+
+		if cfg.AuditConfig.File.Path != "" {
+			auditLogger.RegisterSink(audit.NewFileSink(cfg.AuditConfig.File))
+		}
+		if cfg.AuditConfig.Syslog.Address != "" {
+			auditSyslog, err = audit.NewSyslogSink(logger, clock, cfg.AuditConfig.Syslog)
+			if err != nil {
+				logger.Fatal("invalid-audit-syslog-config", err)
+			}
+			auditLogger.RegisterSink(auditSyslog)
+		}
+
+		authorizer, err = acl.NewAuthorizer(logger, auditLogger, clock, cfg.ACLPolicyFile, aclReloadInterval)
+		if err != nil {
+			logger.Fatal("failed-to-load-acl-policy", err)
+		}
+		interceptors = append(interceptors, authorizer.NewInterceptor())
+		streamInterceptors = append(streamInterceptors, authorizer.NewStreamInterceptor())
+	}
+
+	// after authentication, so that sessions get the identity clients
+	// authenticated as
+	var sessionTracker *sessions.Tracker
+	if cfg.SessionAdminEnabled {
+		err = sessions.ValidateAdminIdentities(cfg.SessionAdminIdentities)
+		if err != nil {
+			logger.Fatal("invalid-session-admin-config", err)
+		}
+		sessionTracker = sessions.NewTracker(logger, clock)
+		interceptors = append(interceptors, sessions.NewAdminInterceptor(cfg.SessionAdminIdentities), sessionTracker.NewInterceptor())
+	}
+
+	if cfg.ResponseCompression != "" {
+		err = compression.Validate(cfg.ResponseCompression)
+		if err != nil {
+			logger.Fatal("invalid-response-compression", err)
+		}
+		interceptors = append(interceptors, compression.NewUnaryServerInterceptor(cfg.ResponseCompression))
+	}
+
+	if cfg.AccessLogPath != "" {
+		accessLogger, err := accesslog.NewFileLogger(cfg.AccessLogPath)
+		if err != nil {
+			logger.Fatal("failed-to-open-access-log", err)
+		}
+		interceptors = append(interceptors, accesslog.NewInterceptor(accessLogger, clock, cfg.AccessLogSampleRate))
+	}
+
+	var trafficRecorder *traffic.Recorder
+	if cfg.TrafficRecordPath != "" {
+		trafficRecorder, err = traffic.NewFileRecorder(logger, clock, cfg.TrafficRecordPath, cfg.TrafficRecordMaxMegabytes)
+		if err != nil {
+			logger.Fatal("failed-to-open-traffic-record", err)
+		}
+		interceptors = append(interceptors, trafficRecorder.NewInterceptor())
+	}
+
+	if injector != nil {
+		interceptors = append(interceptors, injector.NewInterceptor())
+	}
+
+	if partitionDetector != nil {
+		interceptors = append(interceptors, partitionDetector.NewInterceptor())
+		streamInterceptors = append(streamInterceptors, partitionDetector.NewStreamInterceptor())
+	}
+
+	var elector, replicator ifrit.Runner
+	if cfg.LeaderElection {
+		owner, err := guidprovider.DefaultGuidProvider.NextGUID()
+		if err != nil {
+			logger.Fatal("failed-to-generate-elector-owner", err)
+		}
+		e := election.NewElector(logger, lockDB, lockPick, health, owner, locket.DefaultSessionTTLInSeconds, clock, locket.RetryInterval)
+		interceptors = append(interceptors, election.NewInterceptor(e))
+		streamInterceptors = append(streamInterceptors, election.NewStreamInterceptor(e))
+		elector = e
+		activeElector = e
+
+		if cfg.WarmStandbyConfig.Enabled() {
+			// a fresh FetchAll result keeps the read cache warm for when the
+			// standby takes over
+			var warm func(lager.Logger)
+			if cfg.ReadCacheMaxStaleness > 0 {
+				warm = func(logger lager.Logger) {
+					handlerDB.FetchAll(logger, "")
+				}
+			}
+			replicator = initializeReplicator(logger, cfg, tlsConfig, lockPick, e, warm, clock)
+		}
+	}
+
+	if cfg.SlowRPCThreshold > 0 {
+		interceptors = append(interceptors, slowlog.NewHandlerStartInterceptor())
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.UnaryInterceptor(grpcserver.ChainUnaryInterceptors(interceptors...)),
+		grpc.StreamInterceptor(grpcserver.ChainStreamInterceptors(streamInterceptors...)),
+	}
+
+	var loadReporter *loadreport.Reporter
+	if cfg.LoadReportingConfig.Enabled {
+		loadReporter = loadreport.NewReporter(logger, clock, time.Duration(cfg.LoadReportingConfig.Interval), loadreport.ProcessCPUTime)
+		serverOptions = append(serverOptions, loadReporter.ServerOptions()...)
+	}
+
+	var list *allowlist.Allowlist
+	if cfg.AllowlistConfig.Enabled() {
+		list, err = allowlist.New(logger, cfg.AllowlistConfig)
+		if err != nil {
+			logger.Fatal("invalid-allowlist", err)
+		}
+
+		if listener == nil {
+			listener, err = net.Listen("tcp", cfg.ListenAddress)
+			if err != nil {
+				logger.Fatal("failed-to-listen", err)
+			}
+		}
+		listener = list.Listener(listener)
+
+		// replaces the server's default credentials, as grpc uses the last
+		// Creds option it is given
+		serverOptions = append(serverOptions, grpc.Creds(list.Credentials(credentials.NewTLS(tlsConfig))))
+	}
+
+	if sessionTracker != nil {
+		if listener == nil {
+			listener, err = net.Listen("tcp", cfg.ListenAddress)
+			if err != nil {
+				logger.Fatal("failed-to-listen", err)
+			}
+		}
+		listener = sessionTracker.Listener(listener)
+	}
+
+	v2Handler := handlers.NewV2Handler(handler)
+
+	grpcServer := grpcserver.NewGRPCServer(logger, cfg.ListenAddress, tlsConfig, handler, serverOptions...)
+	if listener != nil {
+		grpcServer = grpcserver.NewGRPCServerWithListener(logger, listener, tlsConfig, handler, serverOptions...)
+	}
+	grpcServer = grpcServer.WithHealthServer(healthServer).WithDrainDelay(time.Duration(cfg.HealthDrainDelay)).WithV2Server(v2Handler)
+	if loadReporter != nil {
+		grpcServer = grpcServer.WithLoadReporting(loadReporter.Provider())
+	}
+	if injector != nil {
+		grpcServer = grpcServer.WithChaosServer(chaos.NewHandler(logger, injector))
+	}
+	if sessionTracker != nil {
+		grpcServer = grpcServer.WithSessionsServer(sessions.NewHandler(logger, sessionTracker, handler))
+	}
+	if cfg.WarmStandbyConfig.Enabled() {
+		grpcServer = grpcServer.WithReplicationServer(replication.NewServer(logger, lockPick, clock, time.Duration(cfg.WarmStandbyConfig.SnapshotInterval)))
+	}
+
+	if cfg.GRPCWebListenAddress != "" {
+		webListener, err := net.Listen("tcp", cfg.GRPCWebListenAddress)
+		if err != nil {
+			logger.Fatal("failed-to-listen-for-grpc-web", err)
+		}
+
+		var wrap func(http.Handler) http.Handler
+		if list != nil {
+			webListener = list.Listener(webListener)
+			wrap = list.Handler
+		}
+		grpcServer = grpcServer.WithGRPCWeb(webListener, cfg.GRPCWebAllowedOrigins, wrap)
+	}
+
 	registrationRunner := initializeRegistrationRunner(logger, consulClient, portNum, clock)
 	members := grouper.Members{
-		{"server", server},
-		{"burglar", burglar},
+		{"server", grpcServer},
+		{"expiration", expirer},
 		{"metrics-notifier", metricsNotifier},
+		{"contention-notifier", contentionNotifier},
+		{"deadlock-detector", deadlockDetector},
 		{"registration-runner", registrationRunner},
 	}
 
+	if authorizer != nil {
+		members = append(members, grouper.Member{"acl-reloader", authorizer})
+	}
+
+	if partitionDetector != nil {
+		members = append(members, grouper.Member{"partition-detector", partitionDetector})
+	}
+
+	if dbClock != nil {
+		members = append(members, grouper.Member{"database-clock", dbClock})
+	}
+
+	if loadReporter != nil {
+		members = append(members, grouper.Member{"load-reporter", loadReporter})
+	}
+
+	if handoffListener != nil {
+		members = append(members, grouper.Member{"handoff", handoff.NewServer(logger, cfg.HandoffConfig.SocketPath, handoffListener, lockPick)})
+	}
+
+	if auditSyslog != nil {
+		members = append(members, grouper.Member{"audit-syslog", auditSyslog})
+	}
+
+	if webhookNotifier != nil {
+		members = append(members, grouper.Member{"webhook-notifier", webhookNotifier})
+	}
+
+	if outboxDispatcher != nil {
+		members = append(members, grouper.Member{"outbox-dispatcher", outboxDispatcher})
+	}
+
+	if trafficRecorder != nil {
+		members = append(members, grouper.Member{"traffic-recorder", trafficRecorder})
+	}
+
+	if credHub != nil {
+		members = append(members, grouper.Member{"credhub-refresher", credHub})
+	}
+
+	if vaultRotator != nil {
+		members = append(members, grouper.Member{"vault-pki-rotator", vaultRotator})
+	}
+
+	if prometheusMetrics != nil && cfg.MetricsConfig.Prometheus.ListenAddress != "" {
+		members = append(members, grouper.Member{"prometheus-server", http_server.New(cfg.MetricsConfig.Prometheus.ListenAddress, prometheusMetrics)})
+	}
+
+	if revocationChecker != nil {
+		members = append(grouper.Members{
+			{"revocation-checker", revocationChecker},
+		}, members...)
+	}
+
+	if elector != nil {
+		members = append(grouper.Members{
+			{"elector", elector},
+		}, members...)
+	}
+
+	if replicator != nil {
+		members = append(members, grouper.Member{"replicator", replicator})
+	}
+
 	if cfg.DebugAddress != "" {
+		debugHandlers := backup.NewHandlers(logger, backupGate)
+		debugHandlers[logsampling.RulesPath] = logsampling.NewHandler(logger, logSampler)
+		if prometheusMetrics != nil && cfg.MetricsConfig.Prometheus.ListenAddress == "" {
+			debugHandlers[sinks.PrometheusPath] = prometheusMetrics
+		}
 		members = append(grouper.Members{
-			{"debug-server", debugserver.Runner(cfg.DebugAddress, reconfigurableSink)},
+			{"debug-server", statedump.Runner(cfg.DebugAddress, reconfigurableSink, statedump.NewHandler(logger, lockPick, contentionTracker), debugHandlers)},
 		}, members...)
 	}
 
@@ -153,10 +783,283 @@ func main() {
 	err = <-monitor.Wait()
 	if err != nil {
 		logger.Error("exited-with-failure", err)
+		tracing.Flush(shutdownTracing)
 		os.Exit(1)
 	}
 }
 
+func dumpOrRestore(logger lager.Logger, cfg config.LocketConfig, clock clock.Clock) {
+	if cfg.StorageMode == config.RaftStorageMode {
+		logger.Fatal("invalid-storage-mode", errors.New("dump and restore require sql storage"))
+	}
+
+	sqlConn, sqlDB, _ := initializeSQLDB(logger, cfg, clock, initializeCredHub(logger, cfg, clock))
+	defer sqlConn.Close()
+
+	if *dumpFilePath != "" {
+		err := dumpLocks(logger, sqlDB, *dumpFilePath)
+		if err != nil {
+			logger.Fatal("failed-to-dump-locks", err)
+		}
+	}
+
+	if *restoreFilePath != "" {
+		err := restoreLocks(logger, sqlDB, *restoreFilePath)
+		if err != nil {
+			logger.Fatal("failed-to-restore-locks", err)
+		}
+	}
+}
+
+func copyLocks(logger lager.Logger, cfg config.LocketConfig, clock clock.Clock) {
+	destinationCfg, err := loadConfig(*copyToConfigFilePath)
+	if err != nil {
+		logger.Fatal("invalid-copy-to-config-file", err)
+	}
+
+	if cfg.StorageMode == config.RaftStorageMode || destinationCfg.StorageMode == config.RaftStorageMode {
+		logger.Fatal("invalid-storage-mode", errors.New("copying locks requires sql storage"))
+	}
+
+	sourceConn, sourceDB, _ := initializeSQLDB(logger, cfg, clock, initializeCredHub(logger, cfg, clock))
+	defer sourceConn.Close()
+
+	destinationLogger := logger.Session("destination")
+	destinationConn, destinationDB, _ := initializeSQLDB(destinationLogger, destinationCfg, clock, initializeCredHub(destinationLogger, destinationCfg, clock))
+	defer destinationConn.Close()
+
+	err = sourceDB.CopyTo(logger, destinationDB)
+	if err != nil {
+		logger.Fatal("failed-to-copy-locks", err)
+	}
+}
+
+func loadConfig(path string) (config.LocketConfig, error) {
+	if *strictConfig {
+		return config.NewStrictLocketConfig(path)
+	}
+	return config.NewLocketConfig(path)
+}
+
+func initializeCredHub(logger lager.Logger, cfg config.LocketConfig, clock clock.Clock) *secrets.Store {
+	if !cfg.CredHubConfig.Enabled() {
+		return nil
+	}
+
+	client, err := secrets.NewClient(cfg.CredHubConfig)
+	if err != nil {
+		logger.Fatal("failed-to-create-credhub-client", err)
+	}
+
+	credHub, err := secrets.NewStore(logger, client, cfg.CredHubConfig, clock)
+	if err != nil {
+		logger.Fatal("failed-to-fetch-credhub-credentials", err)
+	}
+	return credHub
+}
+
+// initializeReplicator connects to the peers in cfg with the server's own
+// certificate, for the standby to follow whichever of them is active.
+func initializeReplicator(
+	logger lager.Logger,
+	cfg config.LocketConfig,
+	tlsConfig *tls.Config,
+	lockPick expiration.LockPick,
+	active replication.ActiveChecker,
+	warm func(lager.Logger),
+	clock clock.Clock,
+) ifrit.Runner {
+	peerTLSConfig := tlsConfig.Clone()
+	if peerTLSConfig.RootCAs == nil {
+		peerTLSConfig.RootCAs = tlsConfig.ClientCAs
+	}
+	if tlsConfig.GetCertificate != nil {
+		peerTLSConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+		}
+	}
+
+	peers := make([]admin.ReplicationClient, 0, len(cfg.WarmStandbyConfig.PeerAddresses))
+	for _, address := range cfg.WarmStandbyConfig.PeerAddresses {
+		conn, err := grpc.Dial(address, grpc.WithTransportCredentials(credentials.NewTLS(peerTLSConfig)))
+		if err != nil {
+			logger.Fatal("failed-to-dial-peer", err, lager.Data{"address": address})
+		}
+		peers = append(peers, admin.NewReplicationClient(conn))
+	}
+
+	return replication.NewReplicator(logger, peers, lockPick, active, warm, clock, locket.RetryInterval)
+}
+
+func initializeVaultRotator(logger lager.Logger, cfg config.LocketConfig, clock clock.Clock) *vaultpki.Rotator {
+	issuer, err := vaultpki.NewIssuer(cfg.VaultConfig)
+	if err != nil {
+		logger.Fatal("failed-to-create-vault-issuer", err)
+	}
+
+	rotator, err := vaultpki.NewRotator(logger, issuer, clock)
+	if err != nil {
+		logger.Fatal("failed-to-issue-vault-certificate", err)
+	}
+	return rotator
+}
+
+// newRotatingTLSConfig is like cfhttp.NewTLSConfig, but serves whichever
+// certificate getCertificate returns at the time of each handshake.
+func newRotatingTLSConfig(caFile string, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) (*tls.Config, error) {
+	caCert, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPool := x509.NewCertPool()
+	if ok := caCertPool.AppendCertsFromPEM(caCert); !ok {
+		return nil, errors.New("unable to load ca cert file")
+	}
+
+	return &tls.Config{
+		GetCertificate: getCertificate,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      caCertPool,
+		RootCAs:        caCertPool,
+		MinVersion:     tls.VersionTLS12,
+	}, nil
+}
+
+// initializeSQLDB connects to the database in cfg. The returned SQLDB writes
+// expiry timestamps in the database's time, with the returned clock, which
+// must be run to keep following it.
+func initializeSQLDB(logger lager.Logger, cfg config.LocketConfig, clock clock.Clock, credHub *secrets.Store) (*sql.DB, *db.SQLDB, *db.DatabaseClock) {
+	sqlConn, err := openSQLConn(logger, cfg, credHub)
+	if err != nil {
+		logger.Fatal("failed-to-open-sql", err)
+	}
+
+	profile, err := databaseProfile(cfg)
+	if err != nil {
+		logger.Fatal("invalid-database-tuning", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = sqlConn.Ping()
+		if err == nil {
+			break
+		}
+		if attempt >= profile.ConnectAttempts {
+			logger.Fatal("sql-failed-to-connect", err)
+		}
+		logger.Error("sql-failed-to-connect-retrying", err, lager.Data{"attempt": attempt})
+		clock.Sleep(profile.ConnectRetryInterval)
+	}
+
+	// a failed sync is logged, and leaves the clock on this host's time until
+	// the next one
+	dbClock := db.NewDatabaseClock(logger, sqlConn, cfg.DatabaseDriver, clock, time.Duration(cfg.DatabaseClockSyncInterval))
+	dbClock.Sync(logger)
+
+	sqlDB := db.NewSQLDB(
+		sqlConn,
+		cfg.DatabaseDriver,
+		guidprovider.DefaultGuidProvider,
+		dbClock,
+	)
+
+	switch cfg.DatabaseTimeMode {
+	case config.DatabaseNowTime:
+		sqlDB = sqlDB.WithDatabaseNow()
+	case config.SyncedDatabaseTime, "":
+	default:
+		logger.Fatal("invalid-database-time-mode", fmt.Errorf("unknown database time mode: %s", cfg.DatabaseTimeMode))
+	}
+
+	err = sqlDB.CreateLockTable(logger)
+	if err != nil {
+		logger.Fatal("failed-to-create-lock-table", err)
+	}
+
+	return sqlConn, sqlDB, dbClock
+}
+
+// openSQLConn opens a connection pool to the database in cfg, without
+// connecting to it.
+func openSQLConn(logger lager.Logger, cfg config.LocketConfig, credHub *secrets.Store) (*sql.DB, error) {
+	connectionString := appendExtraConnectionStringParam(
+		logger,
+		cfg.DatabaseDriver,
+		cfg.DatabaseConnectionString,
+		cfg.SQLCACertFile,
+	)
+
+	profile, err := databaseProfile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionString, err = profile.ConnectionString(cfg.DatabaseDriver, connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlConn, err := sql.Open(cfg.DatabaseDriver, connectionString)
+	if err != nil {
+		return nil, err
+	}
+	driver := sqlConn.Driver()
+
+	dsn := func() (string, error) { return connectionString, nil }
+	if credHub != nil && credHub.HasDatabaseCredentials() {
+		dsn = func() (string, error) {
+			username, password := credHub.DatabaseCredentials()
+			return secrets.WithDatabaseCredentials(cfg.DatabaseDriver, connectionString, username, password)
+		}
+		// retire connections opened with credentials that may have been rotated
+		if profile.ConnectionMaxLifetime == 0 || credHub.RefreshInterval() < profile.ConnectionMaxLifetime {
+			profile.ConnectionMaxLifetime = credHub.RefreshInterval()
+		}
+	} else if cfg.DatabaseIAMConfig.Enabled() {
+		tokenSource, err := iamauth.NewTokenSource(cfg.DatabaseIAMConfig)
+		if err != nil {
+			sqlConn.Close()
+			return nil, fmt.Errorf("invalid database iam config: %s", err)
+		}
+
+		// tokens only need to be valid to log in, so each new connection gets
+		// a fresh one and open connections are unaffected by expiry
+		dsn = func() (string, error) {
+			token, err := tokenSource.Token()
+			if err != nil {
+				logger.Error("failed-to-get-database-iam-token", err, lager.Data{"provider": cfg.DatabaseIAMConfig.Provider})
+				return "", err
+			}
+			return iamauth.WithToken(cfg.DatabaseDriver, connectionString, cfg.DatabaseIAMConfig.Username, token)
+		}
+	}
+
+	connector := profile.Connector(cfg.DatabaseDriver, secrets.NewConnector(driver, dsn))
+	sqlConn.Close()
+	sqlConn = sql.OpenDB(connector)
+
+	profile.Apply(sqlConn)
+
+	return sqlConn, nil
+}
+
+// databaseProfile returns the tuning profile for the database in cfg. The
+// older max_open_database_connections still sizes the pool when the tuning
+// config does not.
+func databaseProfile(cfg config.LocketConfig) (dbtuning.Profile, error) {
+	tuning := cfg.DatabaseTuningConfig
+	if cfg.MaxOpenDatabaseConnections > 0 {
+		if tuning.MaxOpenConnections == 0 {
+			tuning.MaxOpenConnections = cfg.MaxOpenDatabaseConnections
+		}
+		if tuning.MaxIdleConnections == 0 {
+			tuning.MaxIdleConnections = cfg.MaxOpenDatabaseConnections
+		}
+	}
+	return dbtuning.NewProfile(cfg.DatabaseDriver, tuning)
+}
+
 func initializeDropsonde(logger lager.Logger, dropsondePort int) {
 	dropsondeDestination := fmt.Sprint("localhost:", dropsondePort)
 	err := dropsonde.Initialize(dropsondeDestination, dropsondeOrigin)
@@ -165,15 +1068,63 @@ func initializeDropsonde(logger lager.Logger, dropsondePort int) {
 	}
 }
 
-func initializeMetron(logger lager.Logger, locketConfig config.LocketConfig) (loggregator_v2.IngressClient, error) {
+// initializeMetrics returns an emitter for every configured metrics sink,
+// and the Prometheus registry if metrics are served to Prometheus.
+func initializeMetrics(logger lager.Logger, locketConfig config.LocketConfig) (emitter.Emitter, *sinks.Prometheus, error) {
+	names, err := locketConfig.MetricsConfig.SinkNames()
+	if err != nil {
+		return nil, nil, err
+	}
+	err = locketConfig.MetricsConfig.ValidateTags()
+	if err != nil {
+		return nil, nil, err
+	}
+	tags := locketConfig.MetricsConfig.Tags
+
+	var emitters []emitter.Emitter
+	var prometheus *sinks.Prometheus
+	for _, name := range names {
+		switch name {
+		case sinks.LoggregatorSink:
+			client, err := initializeMetron(logger, locketConfig)
+			if err != nil {
+				return nil, nil, err
+			}
+			emitters = append(emitters, client)
+		case sinks.PrometheusSink:
+			prometheus = sinks.NewPrometheus(locketConfig.MetricsConfig.Prometheus, tags)
+			emitters = append(emitters, prometheus)
+		case sinks.StatsDSink:
+			statsD, err := sinks.NewStatsD(locketConfig.MetricsConfig.StatsD, tags)
+			if err != nil {
+				return nil, nil, err
+			}
+			emitters = append(emitters, statsD)
+		}
+	}
+
+	logger.Info("initialized-metrics", lager.Data{"sinks": names, "tags": tags})
+	return emitter.Multi(emitters...), prometheus, nil
+}
+
+func initializeMetron(logger lager.Logger, locketConfig config.LocketConfig) (emitter.Emitter, error) {
+	if tags := locketConfig.MetricsConfig.Tags; len(tags) > 0 {
+		client, err := sinks.NewTaggedLoggregator(locketConfig.LoggregatorConfig, tags)
+		if err != nil {
+			return nil, err
+		}
+		go runtimeemitter.New(client).Run()
+		return client, nil
+	}
+
 	client, err := loggregator_v2.NewIngressClient(locketConfig.LoggregatorConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	if locketConfig.LoggregatorConfig.UseV2API {
-		emitter := runtimeemitter.NewV1(client)
-		go emitter.Run()
+		runtimeEmitter := runtimeemitter.NewV1(client)
+		go runtimeEmitter.Run()
 	} else {
 		initializeDropsonde(logger, locketConfig.DropsondePort)
 	}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+
+	"code.cloudfoundry.org/bbs/guidprovider"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/cmd/locket/config"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/preflight"
+	"code.cloudfoundry.org/locket/secrets"
+)
+
+// consulServiceHostname is the name locket registers itself under in consul,
+// which its certificate must be valid for when a consul cluster is set.
+const consulServiceHostname = "locket.service.cf.internal"
+
+// runPreflight checks that locket could start with cfg, without starting it
+// or changing the database, and writes a report of the checks to stdout. It
+// returns whether every check passed.
+func runPreflight(logger lager.Logger, cfg config.LocketConfig, clock clock.Clock) bool {
+	logger = logger.Session("preflight")
+	report := preflight.NewReport()
+
+	var credHub *secrets.Store
+	if cfg.CredHubConfig.Enabled() {
+		client, err := secrets.NewClient(cfg.CredHubConfig)
+		if err == nil {
+			credHub, err = secrets.NewStore(logger, client, cfg.CredHubConfig, clock)
+		}
+		if err != nil {
+			report.Add(preflight.Check{Name: "credhub", Status: preflight.StatusFail, Detail: err.Error()})
+		} else {
+			report.Add(preflight.Check{Name: "credhub", Status: preflight.StatusPass})
+		}
+	}
+
+	switch cfg.StorageMode {
+	case config.RaftStorageMode:
+		report.Add(preflight.Skip("database-connectivity", "raft storage has no database"))
+	case config.SQLStorageMode, "":
+		sqlConn, err := openSQLConn(logger, cfg, credHub)
+		if err != nil {
+			report.Add(preflight.Check{Name: "database-connectivity", Status: preflight.StatusFail, Detail: err.Error()})
+			break
+		}
+		defer sqlConn.Close()
+		sqlDB := db.NewSQLDB(sqlConn, cfg.DatabaseDriver, guidprovider.DefaultGuidProvider, clock)
+		report.Add(preflight.CheckDatabase(logger, sqlDB)...)
+	default:
+		report.Add(preflight.Check{Name: "storage-mode", Status: preflight.StatusFail, Detail: "unknown storage mode: " + cfg.StorageMode})
+	}
+
+	if cfg.VaultConfig.Enabled() || (credHub != nil && credHub.HasCertificate()) {
+		report.Add(preflight.Skip("tls", "the certificate is issued at startup"))
+	} else {
+		report.Add(preflight.CheckTLS(cfg.CertFile, cfg.KeyFile, cfg.CaFile, preflightHostnames(cfg), clock.Now()))
+	}
+
+	if cfg.SystemdSocketActivation {
+		report.Add(preflight.Skip("listen-address", "systemd provides the listening socket"))
+	} else {
+		check := preflight.CheckListen(cfg.ListenAddress)
+		// the address is expected to be taken while the process being
+		// upgraded serves handoffs
+		if check.Status == preflight.StatusFail && cfg.HandoffConfig.SocketPath != "" {
+			if _, err := os.Stat(cfg.HandoffConfig.SocketPath); err == nil {
+				check = preflight.Skip(check.Name, "taken over from the running process: "+check.Detail)
+			}
+		}
+		report.Add(check)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(report)
+	if err != nil {
+		logger.Error("failed-to-write-report", err)
+		return false
+	}
+
+	if !report.Passed {
+		logger.Error("failed", errors.New("preflight checks failed"))
+	}
+	return report.Passed
+}
+
+// preflightHostnames are the names clients use to reach locket with cfg.
+func preflightHostnames(cfg config.LocketConfig) []string {
+	hostnames := []string{}
+	host, _, err := net.SplitHostPort(cfg.ListenAddress)
+	if err == nil && host != "" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsUnspecified() {
+			hostnames = append(hostnames, host)
+		}
+	}
+	if cfg.ConsulCluster != "" {
+		hostnames = append(hostnames, consulServiceHostname)
+	}
+	return hostnames
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var errUsage = errors.New("usage")
+
+type command struct {
+	usage string
+	run   func(c *ctl, ctx context.Context, args []string) error
+}
+
+var commands = map[string]command{
+	"lock": {
+		usage: "lock [-value VALUE] [-type lock|presence] [-ttl DURATION] KEY OWNER",
+		run:   (*ctl).lock,
+	},
+	"release": {
+		usage: "release KEY OWNER",
+		run:   (*ctl).release,
+	},
+	"force-release": {
+		usage: "force-release KEY",
+		run:   (*ctl).forceRelease,
+	},
+	"fetch": {
+		usage: "fetch KEY",
+		run:   (*ctl).fetch,
+	},
+	"list": {
+		usage: "list [-type lock|presence] [-owner OWNER] [-prefix PREFIX]",
+		run:   (*ctl).list,
+	},
+	"watch": {
+		usage: "watch [-interval DURATION] KEY",
+		run:   (*ctl).watch,
+	},
+	"run": {
+		usage: "run -key KEY -owner OWNER [-value VALUE] [-ttl SECONDS] [-wait DURATION] -- COMMAND [ARGS]",
+		run:   (*ctl).runWithLock,
+	},
+	"migrate-consul": {
+		usage: "migrate-consul -consulCluster URL [-presencePrefixes PREFIX,...] [-verify]",
+		run:   (*ctl).migrateConsul,
+	},
+	"loadgen": {
+		usage: "loadgen [-clients N] [-keys N] [-distribution uniform|zipf] [-ttl SECONDS] [-heartbeats N] [-interval DURATION] [-duration DURATION] [-seed N]",
+		run:   (*ctl).loadgen,
+	},
+	"replay": {
+		usage: "replay [-speed FACTOR] [-prefix PREFIX] FILE",
+		run:   (*ctl).replay,
+	},
+	"top": {
+		usage: "top [-interval DURATION] [-prefix PREFIX] [-events N]",
+		run:   (*ctl).top,
+	},
+	"what-if": {
+		usage: "what-if OWNER [OWNER...]",
+		run:   (*ctl).whatIf,
+	},
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ctl runs locketctl commands against a locket server, writing their
+// results to out as one JSON document per line.
+type ctl struct {
+	logger  lager.Logger
+	client  models.LocketClient
+	out     io.Writer
+	timeout time.Duration
+	clock   clock.Clock
+}
+
+func newCtl(logger lager.Logger, client models.LocketClient, out io.Writer, timeout time.Duration, clock clock.Clock) *ctl {
+	return &ctl{
+		logger:  logger,
+		client:  client,
+		out:     out,
+		timeout: timeout,
+		clock:   clock,
+	}
+}
+
+func (c *ctl) run(ctx context.Context, name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		return errUsage
+	}
+	return cmd.run(c, ctx, args)
+}
+
+func (c *ctl) lock(ctx context.Context, args []string) error {
+	flags := newFlagSet("lock")
+	value := flags.String("value", "", "value to store with the lock")
+	lockType := flags.String("type", models.LockType, "type of the lock, lock or presence")
+	ttl := flags.Duration("ttl", 15*time.Second, "ttl of the lock")
+	if flags.Parse(args) != nil || flags.NArg() != 2 || *ttl <= 0 {
+		return errUsage
+	}
+
+	typeCode, err := parseType(*lockType)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Lock(ctx, &models.LockRequest{
+		Resource: &models.Resource{
+			Key:      flags.Arg(0),
+			Owner:    flags.Arg(1),
+			Value:    *value,
+			Type:     *lockType,
+			TypeCode: typeCode,
+		},
+		TtlInSeconds:      int64((*ttl + time.Second - 1) / time.Second),
+		TtlInMilliseconds: int64(*ttl / time.Millisecond),
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(resp)
+}
+
+func (c *ctl) release(ctx context.Context, args []string) error {
+	flags := newFlagSet("release")
+	if flags.Parse(args) != nil || flags.NArg() != 2 {
+		return errUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	_, err := c.client.Release(ctx, &models.ReleaseRequest{
+		Resource: &models.Resource{Key: flags.Arg(0), Owner: flags.Arg(1)},
+	})
+	return err
+}
+
+// forceRelease releases a lock on behalf of whoever holds it. When the lock
+// has a value, the release only succeeds if the value has not changed since
+// it was fetched, so that a new holder is not released by mistake.
+func (c *ctl) forceRelease(ctx context.Context, args []string) error {
+	flags := newFlagSet("force-release")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Fetch(ctx, &models.FetchRequest{Key: flags.Arg(0)})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Release(ctx, &models.ReleaseRequest{
+		Resource:      &models.Resource{Key: resp.Resource.Key, Owner: resp.Resource.Owner},
+		ExpectedValue: resp.Resource.Value,
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.Resource)
+}
+
+func (c *ctl) fetch(ctx context.Context, args []string) error {
+	flags := newFlagSet("fetch")
+	if flags.Parse(args) != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.Fetch(ctx, &models.FetchRequest{Key: flags.Arg(0)})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.Resource)
+}
+
+func (c *ctl) list(ctx context.Context, args []string) error {
+	flags := newFlagSet("list")
+	lockType := flags.String("type", "", "only list locks of this type, lock or presence")
+	owner := flags.String("owner", "", "only list locks held by this owner")
+	prefix := flags.String("prefix", "", "only list locks whose key starts with this prefix")
+	if flags.Parse(args) != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	req := &models.FetchAllRequest{}
+	if *lockType != "" {
+		typeCode, err := parseType(*lockType)
+		if err != nil {
+			return err
+		}
+		req.Type = *lockType
+		req.TypeCode = typeCode
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.client.FetchAll(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(resp.Resources, func(i, j int) bool {
+		return resp.Resources[i].Key < resp.Resources[j].Key
+	})
+
+	for _, resource := range resp.Resources {
+		if *owner != "" && resource.Owner != *owner {
+			continue
+		}
+		if !strings.HasPrefix(resource.Key, *prefix) {
+			continue
+		}
+
+		err = c.print(resource)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchEvent is printed by watch whenever the lock changes. Resource is nil
+// when the lock is not held.
+type watchEvent struct {
+	Key      string           `json:"key"`
+	Held     bool             `json:"held"`
+	Resource *models.Resource `json:"resource,omitempty"`
+}
+
+// watch polls the lock on a key and prints an event every time it changes,
+// until ctx is done.
+func (c *ctl) watch(ctx context.Context, args []string) error {
+	flags := newFlagSet("watch")
+	interval := flags.Duration("interval", time.Second, "how often to poll the lock")
+	if flags.Parse(args) != nil || flags.NArg() != 1 || *interval <= 0 {
+		return errUsage
+	}
+	key := flags.Arg(0)
+
+	var last *models.Resource
+	first := true
+
+	timer := c.clock.NewTimer(*interval)
+	defer timer.Stop()
+
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		resp, err := c.client.Fetch(fetchCtx, &models.FetchRequest{Key: key})
+		cancel()
+
+		var current *models.Resource
+		if err == nil {
+			current = resp.Resource
+		} else if ctx.Err() != nil {
+			return nil
+		} else if grpc.Code(err) != codes.NotFound {
+			return err
+		}
+
+		if first || !current.Equal(last) {
+			err = c.print(watchEvent{Key: key, Held: current != nil, Resource: current})
+			if err != nil {
+				return err
+			}
+			first = false
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C():
+			timer.Reset(*interval)
+		}
+	}
+}
+
+func (c *ctl) print(v interface{}) error {
+	return json.NewEncoder(c.out).Encode(v)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	return flags
+}
+
+func parseType(lockType string) (models.TypeCode, error) {
+	switch lockType {
+	case models.LockType:
+		return models.LOCK, nil
+	case models.PresenceType:
+		return models.PRESENCE, nil
+	default:
+		return models.UNKNOWN, fmt.Errorf("unknown lock type %q, must be %s or %s", lockType, models.LockType, models.PresenceType)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Commands", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		fakeClock  *fakeclock.FakeClock
+		out        *bytes.Buffer
+		c          *ctl
+	)
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, fakeClock)
+	})
+
+	It("rejects unknown commands", func() {
+		Expect(c.run(context.Background(), "frobnicate", nil)).To(Equal(errUsage))
+	})
+
+	Context("lock", func() {
+		It("locks the key for the owner", func() {
+			fakeClient.LockReturns(&models.LockResponse{FencingToken: 4}, nil)
+
+			err := c.run(context.Background(), "lock", []string{"-value", "v", "-ttl", "1500ms", "key", "owner"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.LockCallCount()).To(Equal(1))
+			_, req, _ := fakeClient.LockArgsForCall(0)
+			Expect(req.Resource).To(Equal(&models.Resource{Key: "key", Owner: "owner", Value: "v", Type: "lock", TypeCode: models.LOCK}))
+			Expect(req.TtlInSeconds).To(BeEquivalentTo(2))
+			Expect(req.TtlInMilliseconds).To(BeEquivalentTo(1500))
+			Expect(out.String()).To(ContainSubstring(`"fencing_token":4`))
+		})
+
+		It("rejects unknown types", func() {
+			err := c.run(context.Background(), "lock", []string{"-type", "mutex", "key", "owner"})
+			Expect(err).To(MatchError(ContainSubstring("unknown lock type")))
+			Expect(fakeClient.LockCallCount()).To(Equal(0))
+		})
+
+		It("requires a key and an owner", func() {
+			Expect(c.run(context.Background(), "lock", []string{"key"})).To(Equal(errUsage))
+		})
+	})
+
+	Context("release", func() {
+		It("releases the key for the owner", func() {
+			err := c.run(context.Background(), "release", []string{"key", "owner"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, req, _ := fakeClient.ReleaseArgsForCall(0)
+			Expect(req.Resource).To(Equal(&models.Resource{Key: "key", Owner: "owner"}))
+		})
+	})
+
+	Context("force-release", func() {
+		BeforeEach(func() {
+			fakeClient.FetchReturns(&models.FetchResponse{
+				Resource: &models.Resource{Key: "key", Owner: "someone", Value: "v"},
+			}, nil)
+		})
+
+		It("releases the lock as its current holder if it has not changed", func() {
+			err := c.run(context.Background(), "force-release", []string{"key"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, fetchReq, _ := fakeClient.FetchArgsForCall(0)
+			Expect(fetchReq.Key).To(Equal("key"))
+
+			_, req, _ := fakeClient.ReleaseArgsForCall(0)
+			Expect(req.Resource).To(Equal(&models.Resource{Key: "key", Owner: "someone"}))
+			Expect(req.ExpectedValue).To(Equal("v"))
+		})
+
+		It("returns fetch errors without releasing", func() {
+			fakeClient.FetchReturns(nil, models.ErrResourceNotFound)
+
+			err := c.run(context.Background(), "force-release", []string{"key"})
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+			Expect(fakeClient.ReleaseCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("fetch", func() {
+		It("prints the lock", func() {
+			fakeClient.FetchReturns(&models.FetchResponse{Resource: &models.Resource{Key: "key", Owner: "someone"}}, nil)
+
+			err := c.run(context.Background(), "fetch", []string{"key"})
+			Expect(err).NotTo(HaveOccurred())
+
+			var resource models.Resource
+			Expect(json.Unmarshal(out.Bytes(), &resource)).To(Succeed())
+			Expect(resource.Owner).To(Equal("someone"))
+		})
+	})
+
+	Context("list", func() {
+		BeforeEach(func() {
+			fakeClient.FetchAllReturns(&models.FetchAllResponse{Resources: []*models.Resource{
+				{Key: "cell-2", Owner: "b"},
+				{Key: "auctioneer", Owner: "a"},
+				{Key: "cell-1", Owner: "a"},
+			}}, nil)
+		})
+
+		It("prints every lock ordered by key", func() {
+			err := c.run(context.Background(), "list", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys(out)).To(Equal([]string{"auctioneer", "cell-1", "cell-2"}))
+		})
+
+		It("filters by type, owner and prefix", func() {
+			err := c.run(context.Background(), "list", []string{"-type", "presence", "-owner", "a", "-prefix", "cell-"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(keys(out)).To(Equal([]string{"cell-1"}))
+
+			_, req, _ := fakeClient.FetchAllArgsForCall(0)
+			Expect(req.TypeCode).To(Equal(models.PRESENCE))
+		})
+	})
+
+	Context("watch", func() {
+		It("prints an event every time the lock changes until it is cancelled", func() {
+			fakeClient.FetchReturnsOnCall(0, nil, models.ErrResourceNotFound)
+			fakeClient.FetchReturnsOnCall(1, &models.FetchResponse{Resource: &models.Resource{Key: "key", Owner: "a"}}, nil)
+			fakeClient.FetchReturnsOnCall(2, &models.FetchResponse{Resource: &models.Resource{Key: "key", Owner: "a"}}, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			errCh := make(chan error)
+			go func() {
+				errCh <- c.run(ctx, "watch", []string{"-interval", "1s", "key"})
+			}()
+
+			Eventually(fakeClient.FetchCallCount).Should(Equal(1))
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(fakeClient.FetchCallCount).Should(Equal(2))
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(fakeClient.FetchCallCount).Should(Equal(3))
+
+			cancel()
+			Eventually(errCh).Should(Receive(BeNil()))
+
+			Expect(out.String()).To(Equal(
+				`{"key":"key","held":false}` + "\n" +
+					`{"key":"key","held":true,"resource":{"key":"key","owner":"a"}}` + "\n",
+			))
+		})
+
+		It("stops on other errors", func() {
+			fakeClient.FetchReturns(nil, errors.New("boom"))
+			Expect(c.run(context.Background(), "watch", []string{"key"})).To(MatchError("boom"))
+		})
+	})
+})
+
+func keys(out *bytes.Buffer) []string {
+	var keys []string
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var resource models.Resource
+		Expect(decoder.Decode(&resource)).To(Succeed())
+		keys = append(keys, resource.Key)
+	}
+	return keys
+}
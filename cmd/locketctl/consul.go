@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/consuladapter"
+	"code.cloudfoundry.org/locket/consulmigration"
+	"golang.org/x/net/context"
+)
+
+var newConsulClient = consuladapter.NewClientFromUrl
+
+// migrateConsul seeds locket with the locks and presences held in consul,
+// then prints every consul entry that is not held the same way in locket.
+// With -verify locket is only compared, not seeded. It exits with status 1
+// when there are differences.
+func (c *ctl) migrateConsul(ctx context.Context, args []string) error {
+	flags := newFlagSet("migrate-consul")
+	consulCluster := flags.String("consulCluster", "", "url of the consul cluster to migrate from")
+	presencePrefixes := flags.String("presencePrefixes", "cell/", "comma separated prefixes of the keys, relative to v1/locks, that are presences")
+	verify := flags.Bool("verify", false, "only compare consul with locket, without seeding locket")
+	if flags.Parse(args) != nil || flags.NArg() != 0 || *consulCluster == "" {
+		return errUsage
+	}
+
+	consulClient, err := newConsulClient(*consulCluster)
+	if err != nil {
+		return err
+	}
+
+	var prefixes []string
+	if *presencePrefixes != "" {
+		prefixes = strings.Split(*presencePrefixes, ",")
+	}
+
+	entries, err := consulmigration.Read(c.logger, consulClient, prefixes)
+	if err != nil {
+		return err
+	}
+
+	if !*verify {
+		_, err = consulmigration.Seed(c.logger, ctx, c.client, entries)
+		if err != nil {
+			return err
+		}
+	}
+
+	mismatches, err := consulmigration.Verify(c.logger, ctx, c.client, entries)
+	if err != nil {
+		return err
+	}
+
+	for _, mismatch := range mismatches {
+		err = c.print(mismatch)
+		if err != nil {
+			return err
+		}
+	}
+	if len(mismatches) > 0 {
+		return exitCodeError(1)
+	}
+	return nil
+}
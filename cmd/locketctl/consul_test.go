@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/consuladapter"
+	"code.cloudfoundry.org/consuladapter/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"github.com/hashicorp/consul/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("migrate-consul", func() {
+	var (
+		fakeClient     *modelsfakes.FakeLocketClient
+		fakeComponents *fakes.FakeClientComponents
+		consulURL      string
+		out            *bytes.Buffer
+		c              *ctl
+	)
+
+	BeforeEach(func() {
+		var consulClient *fakes.FakeClient
+		consulClient, fakeComponents = fakes.NewFakeClient()
+		fakeComponents.KV.ListReturns(api.KVPairs{
+			{Key: "v1/locks/bbs_lock", Value: []byte("bbs-value"), Session: "session-1"},
+		}, nil, nil)
+		fakeComponents.Session.ListReturns([]*api.SessionEntry{
+			{ID: "session-1", Name: "bbs-uuid", TTL: "15s"},
+		}, nil, nil)
+
+		newConsulClient = func(url string) (consuladapter.Client, error) {
+			consulURL = url
+			return consulClient, nil
+		}
+
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.FetchReturns(&models.FetchResponse{
+			Resource: &models.Resource{Key: "bbs_lock", Owner: "bbs-uuid", Value: "bbs-value", Type: models.LockType},
+		}, nil)
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, fakeclock.NewFakeClock(time.Now()))
+	})
+
+	AfterEach(func() {
+		newConsulClient = consuladapter.NewClientFromUrl
+	})
+
+	It("seeds locket from consul and verifies it", func() {
+		err := c.run(context.Background(), "migrate-consul", []string{"-consulCluster", "http://consul:8500"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(consulURL).To(Equal("http://consul:8500"))
+
+		Expect(fakeClient.LockCallCount()).To(Equal(1))
+		_, req, _ := fakeClient.LockArgsForCall(0)
+		Expect(req.Resource.Key).To(Equal("bbs_lock"))
+		Expect(req.Resource.Owner).To(Equal("bbs-uuid"))
+		Expect(req.TtlInSeconds).To(BeEquivalentTo(15))
+
+		Expect(fakeClient.FetchCallCount()).To(Equal(1))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("only compares with -verify, and exits 1 on differences", func() {
+		fakeClient.FetchReturns(nil, models.ErrResourceNotFound)
+
+		err := c.run(context.Background(), "migrate-consul", []string{"-consulCluster", "http://consul:8500", "-verify"})
+		Expect(err).To(Equal(exitCodeError(1)))
+
+		Expect(fakeClient.LockCallCount()).To(Equal(0))
+		Expect(out.String()).To(ContainSubstring(`"reason":"missing"`))
+		Expect(out.String()).To(ContainSubstring(`"key":"bbs_lock"`))
+	})
+
+	It("returns consul errors", func() {
+		fakeComponents.KV.ListReturns(nil, nil, errors.New("boom"))
+		err := c.run(context.Background(), "migrate-consul", []string{"-consulCluster", "http://consul:8500"})
+		Expect(err).To(MatchError("boom"))
+	})
+
+	It("requires a consul cluster", func() {
+		Expect(c.run(context.Background(), "migrate-consul", nil)).To(Equal(errUsage))
+	})
+})
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/locket/loadgen"
+	"golang.org/x/net/context"
+)
+
+// loadgen simulates many clients contending for locks for a while and
+// prints a report of what happened.
+func (c *ctl) loadgen(ctx context.Context, args []string) error {
+	flags := newFlagSet("loadgen")
+	cfg := loadgen.Config{}
+	flags.IntVar(&cfg.Clients, "clients", 10, "number of simulated clients")
+	flags.IntVar(&cfg.Keys, "keys", 10, "number of keys the clients contend for")
+	flags.StringVar(&cfg.Distribution, "distribution", loadgen.UniformDistribution, "how clients pick keys, uniform or zipf")
+	flags.Int64Var(&cfg.TtlInSeconds, "ttl", 15, "ttl of the locks in seconds")
+	flags.IntVar(&cfg.Heartbeats, "heartbeats", 3, "number of heartbeats before a client releases a lock it got")
+	flags.DurationVar(&cfg.Interval, "interval", time.Second, "time between heartbeats, and between attempts after a collision")
+	flags.Int64Var(&cfg.Seed, "seed", 0, "seed for picking keys")
+	duration := flags.Duration("duration", time.Minute, "how long to generate load")
+	if flags.Parse(args) != nil || flags.NArg() != 0 || *duration <= 0 || cfg.Validate() != nil {
+		return errUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	report, err := loadgen.Run(c.logger, ctx, c.client, cfg, c.clock)
+	if err != nil {
+		return err
+	}
+	return c.print(report)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/loadgen"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("loadgen", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		out        *bytes.Buffer
+		c          *ctl
+	)
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.LockReturns(&models.LockResponse{}, nil)
+		fakeClient.ReleaseReturns(&models.ReleaseResponse{}, nil)
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, clock.NewClock())
+	})
+
+	It("generates load for the duration and prints a report", func() {
+		err := c.run(context.Background(), "loadgen", []string{"-clients", "2", "-interval", "1ms", "-duration", "20ms"})
+		Expect(err).NotTo(HaveOccurred())
+
+		var report loadgen.Report
+		Expect(json.Unmarshal(out.Bytes(), &report)).To(Succeed())
+		Expect(report.Clients).To(Equal(2))
+		Expect(report.Acquisitions).To(BeNumerically(">", 0))
+		Expect(fakeClient.LockCallCount()).To(BeNumerically(">", 0))
+	})
+
+	It("rejects invalid settings", func() {
+		Expect(c.run(context.Background(), "loadgen", []string{"-distribution", "gaussian"})).To(Equal(errUsage))
+		Expect(c.run(context.Background(), "loadgen", []string{"-clients", "0"})).To(Equal(errUsage))
+	})
+})
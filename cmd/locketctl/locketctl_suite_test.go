@@ -0,0 +1,13 @@
+package main
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLocketctl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Locketctl Suite")
+}
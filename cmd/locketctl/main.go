@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket"
+	"golang.org/x/net/context"
+)
+
+var configFilePath = flag.String(
+	"config",
+	"",
+	"Path to a JSON file with the locket client configuration (locket_address, locket_ca_cert_file, ...)",
+)
+
+var locketAddress = flag.String(
+	"locketAddress",
+	"",
+	"Address of the locket server, overrides the config file",
+)
+
+var caCertFile = flag.String(
+	"caCertFile",
+	"",
+	"Path to the CA certificate of the locket server, overrides the config file",
+)
+
+var clientCertFile = flag.String(
+	"clientCertFile",
+	"",
+	"Path to the client certificate, overrides the config file",
+)
+
+var clientKeyFile = flag.String(
+	"clientKeyFile",
+	"",
+	"Path to the client key, overrides the config file",
+)
+
+var authToken = flag.String(
+	"authToken",
+	"",
+	"Token to authenticate with instead of a client certificate, overrides the config file",
+)
+
+var skipCertVerify = flag.Bool(
+	"skipCertVerify",
+	false,
+	"Do not verify the certificate of the locket server",
+)
+
+var timeout = flag.Duration(
+	"timeout",
+	10*time.Second,
+	"Timeout of each request to the locket server",
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := clientConfig()
+	if err != nil {
+		fail(err)
+	}
+
+	logger := lager.NewLogger("locketctl")
+	logger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.ERROR))
+
+	newClient := locket.NewClient
+	if *skipCertVerify {
+		newClient = locket.NewClientSkipCertVerify
+	}
+
+	client, err := newClient(logger, cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	c := newCtl(logger, client, os.Stdout, *timeout, clock.NewClock())
+	err = c.run(ctx, flag.Arg(0), flag.Args()[1:])
+	if err == errUsage {
+		usage()
+		os.Exit(2)
+	}
+	if code, ok := err.(exitCodeError); ok {
+		os.Exit(int(code))
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func clientConfig() (locket.ClientLocketConfig, error) {
+	var cfg locket.ClientLocketConfig
+
+	if *configFilePath != "" {
+		configFile, err := os.Open(*configFilePath)
+		if err != nil {
+			return cfg, err
+		}
+		defer configFile.Close()
+
+		err = json.NewDecoder(configFile).Decode(&cfg)
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	if *locketAddress != "" {
+		cfg.LocketAddress = *locketAddress
+	}
+	if *caCertFile != "" {
+		cfg.LocketCACertFile = *caCertFile
+	}
+	if *clientCertFile != "" {
+		cfg.LocketClientCertFile = *clientCertFile
+	}
+	if *clientKeyFile != "" {
+		cfg.LocketClientKeyFile = *clientKeyFile
+	}
+	if *authToken != "" {
+		cfg.LocketAuthToken = *authToken
+	}
+
+	// the settings are validated by NewClient
+	return cfg, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: locketctl [flags] <command> [command flags] [args]\n\ncommands:\n")
+	for _, name := range commandNames() {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nflags:\n")
+	flag.PrintDefaults()
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "locketctl: %s\n", err)
+	os.Exit(1)
+}
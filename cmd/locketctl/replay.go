@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/locket/traffic"
+	"golang.org/x/net/context"
+)
+
+// replay replays traffic recorded by a locket server and prints a report
+// comparing it with the recording.
+func (c *ctl) replay(ctx context.Context, args []string) error {
+	flags := newFlagSet("replay")
+	cfg := traffic.ReplayConfig{Timeout: c.timeout}
+	flags.Float64Var(&cfg.Speed, "speed", 1, "pace of the replay relative to the recording")
+	flags.StringVar(&cfg.KeyPrefix, "prefix", "replay-", "prefix of the replayed keys and owners")
+	if flags.Parse(args) != nil || flags.NArg() != 1 || cfg.Validate() != nil {
+		return errUsage
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := traffic.ReadRecords(file)
+	if err != nil {
+		return err
+	}
+
+	report, err := traffic.Replay(c.logger, ctx, c.client, records, cfg, c.clock)
+	if err != nil {
+		return err
+	}
+	return c.print(report)
+}
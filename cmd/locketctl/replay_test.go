@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/locket/traffic"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("replay", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		out        *bytes.Buffer
+		c          *ctl
+		recordPath string
+	)
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.LockReturns(&models.LockResponse{}, nil)
+		fakeClient.ReleaseReturns(&models.ReleaseResponse{}, nil)
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, clock.NewClock())
+
+		recordFile, err := ioutil.TempFile("", "traffic")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = recordFile.WriteString(`{"offset_ns":0,"method":"Lock","key_hash":"k1","owner_hash":"o1","ttl_in_milliseconds":15000,"duration_ns":1000,"status":"OK"}
+{"offset_ns":1000000,"method":"Release","key_hash":"k1","owner_hash":"o1","duration_ns":1000,"status":"OK"}
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recordFile.Close()).To(Succeed())
+		recordPath = recordFile.Name()
+	})
+
+	AfterEach(func() {
+		Expect(os.Remove(recordPath)).To(Succeed())
+	})
+
+	It("replays the recording and prints a report", func() {
+		err := c.run(context.Background(), "replay", []string{"-speed", "10", "-prefix", "test-", recordPath})
+		Expect(err).NotTo(HaveOccurred())
+
+		var report traffic.Report
+		Expect(json.Unmarshal(out.Bytes(), &report)).To(Succeed())
+		Expect(report.Requests).To(Equal(2))
+		Expect(report.Methods).To(HaveKey(traffic.LockMethod))
+
+		_, lockReq, _ := fakeClient.LockArgsForCall(0)
+		Expect(lockReq.Resource.Key).To(Equal("test-k1"))
+	})
+
+	It("rejects invalid settings", func() {
+		Expect(c.run(context.Background(), "replay", []string{"-speed", "0", recordPath})).To(Equal(errUsage))
+		Expect(c.run(context.Background(), "replay", []string{})).To(Equal(errUsage))
+	})
+})
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/locket/lock"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/tedsuo/ifrit"
+	"golang.org/x/net/context"
+)
+
+// killTimeout is how long a child is given to exit after SIGTERM before it
+// is killed.
+const killTimeout = 10 * time.Second
+
+// exitCodeError makes locketctl exit with a given status, such as the exit
+// code of a child that failed.
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return "command exited with a non-zero status"
+}
+
+// runWithLock runs a command while holding a lock. The lock is refreshed in
+// the background for as long as the command runs, and released when it
+// exits. If the lock is lost the command is terminated.
+func (c *ctl) runWithLock(ctx context.Context, args []string) error {
+	flags := newFlagSet("run")
+	key := flags.String("key", "", "key of the lock")
+	owner := flags.String("owner", "", "owner of the lock")
+	value := flags.String("value", "", "value to store with the lock")
+	ttl := flags.Int64("ttl", 15, "ttl of the lock in seconds")
+	wait := flags.Duration("wait", 0, "how long to wait for the lock when it is held by someone else")
+	if flags.Parse(args) != nil || flags.NArg() == 0 || *key == "" || *owner == "" || *ttl <= 0 {
+		return errUsage
+	}
+
+	resource := &models.Resource{
+		Key:      *key,
+		Owner:    *owner,
+		Value:    *value,
+		Type:     models.LockType,
+		TypeCode: models.LOCK,
+	}
+
+	if *wait == 0 {
+		lockCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		_, err := c.client.Lock(lockCtx, &models.LockRequest{Resource: resource, TtlInSeconds: *ttl})
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	retryInterval := time.Duration(*ttl) * time.Second / 3
+	lockProcess := ifrit.Background(lock.NewLockRunner(c.logger, c.client, resource, *ttl, c.clock, retryInterval))
+	defer func() {
+		lockProcess.Signal(os.Interrupt)
+		<-lockProcess.Wait()
+	}()
+
+	// the lock is already held when not waiting, so the runner is only
+	// refreshing it
+	var waitTimeout <-chan time.Time
+	if *wait > 0 {
+		waitTimer := c.clock.NewTimer(*wait)
+		defer waitTimer.Stop()
+		waitTimeout = waitTimer.C()
+	}
+
+	select {
+	case <-lockProcess.Ready():
+	case err := <-lockProcess.Wait():
+		return err
+	case <-waitTimeout:
+		return models.ErrLockCollision
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	cmd := exec.Command(flags.Arg(0), flags.Args()[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = c.out
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-exited:
+		return exitCode(err)
+	case err = <-lockProcess.Wait():
+		c.logger.Error("lost-lock-terminating-command", err)
+		c.terminate(cmd, exited)
+		return err
+	case <-ctx.Done():
+		c.terminate(cmd, exited)
+		return ctx.Err()
+	}
+}
+
+// terminate asks the command to exit and kills it if it has not done so
+// within killTimeout.
+func (c *ctl) terminate(cmd *exec.Cmd, exited <-chan error) {
+	cmd.Process.Signal(syscall.SIGTERM)
+
+	timer := c.clock.NewTimer(killTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-exited:
+	case <-timer.C():
+		cmd.Process.Kill()
+		<-exited
+	}
+}
+
+func exitCode(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return exitCodeError(status.ExitStatus())
+		}
+	}
+	return err
+}
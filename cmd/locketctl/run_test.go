@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("run", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		fakeClock  *fakeclock.FakeClock
+		out        *bytes.Buffer
+		c          *ctl
+	)
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, fakeClock)
+	})
+
+	It("runs the command while holding the lock and releases it afterwards", func() {
+		err := c.run(context.Background(), "run", []string{"-key", "cron", "-owner", "me", "--", "sh", "-c", "echo ran"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(Equal("ran\n"))
+
+		_, lockReq, _ := fakeClient.LockArgsForCall(0)
+		Expect(lockReq.Resource.Key).To(Equal("cron"))
+		Expect(lockReq.Resource.Owner).To(Equal("me"))
+		Expect(lockReq.TtlInSeconds).To(BeEquivalentTo(15))
+
+		Expect(fakeClient.ReleaseCallCount()).To(Equal(1))
+		_, releaseReq, _ := fakeClient.ReleaseArgsForCall(0)
+		Expect(releaseReq.Resource.Key).To(Equal("cron"))
+	})
+
+	It("exits with the exit code of the command", func() {
+		err := c.run(context.Background(), "run", []string{"-key", "cron", "-owner", "me", "--", "sh", "-c", "exit 3"})
+		Expect(err).To(Equal(exitCodeError(3)))
+		Expect(fakeClient.ReleaseCallCount()).To(Equal(1))
+	})
+
+	It("does not run the command when the lock is held by someone else", func() {
+		fakeClient.LockReturns(nil, models.ErrLockCollision)
+
+		err := c.run(context.Background(), "run", []string{"-key", "cron", "-owner", "me", "--", "sh", "-c", "echo ran"})
+		Expect(err).To(Equal(models.ErrLockCollision))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("requires a key, an owner and a command", func() {
+		Expect(c.run(context.Background(), "run", []string{"-key", "cron", "--", "true"})).To(Equal(errUsage))
+		Expect(c.run(context.Background(), "run", []string{"-key", "cron", "-owner", "me"})).To(Equal(errUsage))
+	})
+
+	Context("when waiting for the lock", func() {
+		BeforeEach(func() {
+			fakeClient.LockReturnsOnCall(0, nil, models.ErrLockCollision)
+		})
+
+		It("runs the command once the lock is acquired", func() {
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- c.run(context.Background(), "run", []string{"-key", "cron", "-owner", "me", "-ttl", "3", "-wait", "1m", "--", "true"})
+			}()
+
+			Eventually(fakeClient.LockCallCount).Should(Equal(1))
+			fakeClock.WaitForNWatchersAndIncrement(time.Second, 2)
+
+			Eventually(errCh).Should(Receive(BeNil()))
+		})
+	})
+
+	Context("when the lock is lost", func() {
+		BeforeEach(func() {
+			fakeClient.LockReturnsOnCall(2, nil, errors.New("lost"))
+		})
+
+		It("terminates the command", func() {
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- c.run(context.Background(), "run", []string{"-key", "cron", "-owner", "me", "-ttl", "3", "--", "sleep", "10"})
+			}()
+
+			Eventually(fakeClient.LockCallCount).Should(Equal(2))
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+
+			Eventually(errCh, 5*time.Second).Should(Receive(MatchError("lost")))
+		})
+	})
+})
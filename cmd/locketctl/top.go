@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// clearScreen moves the cursor home and clears the terminal, so that each
+// refresh of top is drawn in place.
+const clearScreen = "\033[H\033[2J"
+
+const maxTopValueLength = 40
+
+type topEvent struct {
+	at      time.Time
+	message string
+}
+
+// topSnapshot is everything top shows from a single refresh.
+type topSnapshot struct {
+	at        time.Time
+	resources []*models.Resource
+	remaining map[string]time.Duration
+	contended []*models.ContendedKey
+}
+
+// top shows the locks and presences held, how long until they expire, the
+// most contended keys and recent changes, refreshing in place every
+// interval until ctx is done.
+func (c *ctl) top(ctx context.Context, args []string) error {
+	flags := newFlagSet("top")
+	interval := flags.Duration("interval", time.Second, "how often to refresh")
+	prefix := flags.String("prefix", "", "only show locks whose key starts with this prefix")
+	maxEvents := flags.Int("events", 10, "number of recent events to show")
+	if flags.Parse(args) != nil || flags.NArg() != 0 || *interval <= 0 || *maxEvents < 0 {
+		return errUsage
+	}
+
+	var last map[string]*models.Resource
+	var events []topEvent
+
+	timer := c.clock.NewTimer(*interval)
+	defer timer.Stop()
+
+	for {
+		snapshot, err := c.topSnapshot(ctx, *prefix)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		current := map[string]*models.Resource{}
+		for _, resource := range snapshot.resources {
+			current[resource.Key] = resource
+		}
+		if last != nil {
+			events = append(events, topEvents(snapshot.at, last, current)...)
+			if len(events) > *maxEvents {
+				events = events[len(events)-*maxEvents:]
+			}
+		}
+		last = current
+
+		err = c.renderTop(snapshot, events)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C():
+			timer.Reset(*interval)
+		}
+	}
+}
+
+func (c *ctl) topSnapshot(ctx context.Context, prefix string) (*topSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	snapshot := &topSnapshot{
+		at:        c.clock.Now(),
+		remaining: map[string]time.Duration{},
+	}
+
+	for _, typeCode := range []models.TypeCode{models.LOCK, models.PRESENCE} {
+		resp, err := c.client.FetchAll(ctx, &models.FetchAllRequest{TypeCode: typeCode})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range resp.Resources {
+			if strings.HasPrefix(resource.Key, prefix) {
+				snapshot.resources = append(snapshot.resources, resource)
+			}
+		}
+		for _, holder := range resp.Holders {
+			snapshot.remaining[holder.Key] = time.Duration(holder.RemainingTtlInMilliseconds) * time.Millisecond
+		}
+	}
+
+	sort.Slice(snapshot.resources, func(i, j int) bool {
+		return snapshot.resources[i].Key < snapshot.resources[j].Key
+	})
+
+	// servers without contention tracking do not implement Stats
+	stats, err := c.client.Stats(ctx, &models.StatsRequest{})
+	if err != nil && grpc.Code(err) != codes.Unimplemented {
+		return nil, err
+	}
+	if err == nil {
+		for _, key := range stats.ContendedKeys {
+			if strings.HasPrefix(key.Key, prefix) {
+				snapshot.contended = append(snapshot.contended, key)
+			}
+		}
+	}
+
+	return snapshot, nil
+}
+
+// topEvents describes what changed between two refreshes, in key order.
+func topEvents(at time.Time, last, current map[string]*models.Resource) []topEvent {
+	var messages []string
+	for key, resource := range current {
+		previous, ok := last[key]
+		switch {
+		case !ok:
+			messages = append(messages, fmt.Sprintf("%s acquired by %s", key, resource.Owner))
+		case previous.Owner != resource.Owner:
+			messages = append(messages, fmt.Sprintf("%s moved from %s to %s", key, previous.Owner, resource.Owner))
+		case previous.Value != resource.Value:
+			messages = append(messages, fmt.Sprintf("%s value changed by %s", key, resource.Owner))
+		}
+	}
+	for key, previous := range last {
+		if _, ok := current[key]; !ok {
+			messages = append(messages, fmt.Sprintf("%s released by %s", key, previous.Owner))
+		}
+	}
+	sort.Strings(messages)
+
+	events := make([]topEvent, 0, len(messages))
+	for _, message := range messages {
+		events = append(events, topEvent{at: at, message: message})
+	}
+	return events
+}
+
+func (c *ctl) renderTop(snapshot *topSnapshot, events []topEvent) error {
+	var locks, presences int
+	for _, resource := range snapshot.resources {
+		if models.GetType(resource) == models.PresenceType {
+			presences++
+		} else {
+			locks++
+		}
+	}
+
+	frame := &bytes.Buffer{}
+	frame.WriteString(clearScreen)
+	fmt.Fprintf(frame, "locket top - %s - %d locks, %d presences\n\n", snapshot.at.Format("15:04:05"), locks, presences)
+
+	table := tabwriter.NewWriter(frame, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "KEY\tOWNER\tTYPE\tTTL\tVALUE")
+	for _, resource := range snapshot.resources {
+		ttl := "-"
+		if remaining, ok := snapshot.remaining[resource.Key]; ok {
+			ttl = remaining.Truncate(100 * time.Millisecond).String()
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", resource.Key, resource.Owner, models.GetType(resource), ttl, truncate(resource.Value, maxTopValueLength))
+	}
+	table.Flush()
+
+	if len(snapshot.contended) > 0 {
+		fmt.Fprintln(frame)
+		table = tabwriter.NewWriter(frame, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "CONTENDED KEY\tFAILED ATTEMPTS\tWAITERS\tOWNERSHIP CHANGES")
+		for _, key := range snapshot.contended {
+			fmt.Fprintf(table, "%s\t%d\t%d\t%d\n", key.Key, key.FailedAttempts, key.Waiters, key.OwnershipChanges)
+		}
+		table.Flush()
+	}
+
+	fmt.Fprintln(frame, "\nRECENT EVENTS")
+	for i := len(events) - 1; i >= 0; i-- {
+		fmt.Fprintf(frame, "%s  %s\n", events[i].at.Format("15:04:05"), events[i].message)
+	}
+
+	_, err := c.out.Write(frame.Bytes())
+	return err
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length-3] + "..."
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var _ = Describe("top", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		fakeClock  *fakeclock.FakeClock
+		out        *bytes.Buffer
+		c          *ctl
+		locks      []*models.Resource
+	)
+
+	BeforeEach(func() {
+		locks = []*models.Resource{
+			{Key: "auctioneer", Owner: "auctioneer-0", Value: "10.0.0.1", TypeCode: models.LOCK},
+		}
+
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.FetchAllStub = func(ctx context.Context, req *models.FetchAllRequest, opts ...grpc.CallOption) (*models.FetchAllResponse, error) {
+			if req.TypeCode == models.PRESENCE {
+				return &models.FetchAllResponse{
+					Resources: []*models.Resource{{Key: "cell-1", Owner: "cell-1", TypeCode: models.PRESENCE}},
+					Holders:   []*models.LockHolder{{Key: "cell-1", Owner: "cell-1", RemainingTtlInMilliseconds: 9000}},
+				}, nil
+			}
+			var holders []*models.LockHolder
+			for _, lock := range locks {
+				holders = append(holders, &models.LockHolder{Key: lock.Key, Owner: lock.Owner, RemainingTtlInMilliseconds: 12345})
+			}
+			return &models.FetchAllResponse{Resources: locks, Holders: holders}, nil
+		}
+		fakeClient.StatsReturns(&models.StatsResponse{
+			ContendedKeys: []*models.ContendedKey{{Key: "auctioneer", FailedAttempts: 7, Waiters: 2, OwnershipChanges: 1}},
+		}, nil)
+
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, fakeClock)
+	})
+
+	It("draws the locks, presences and contended keys", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(c.run(ctx, "top", nil)).To(Succeed())
+
+		Expect(out.String()).To(HavePrefix(clearScreen))
+		Expect(out.String()).To(ContainSubstring("1 locks, 1 presences"))
+		Expect(out.String()).To(MatchRegexp(`auctioneer\s+auctioneer-0\s+lock\s+12.3s\s+10.0.0.1`))
+		Expect(out.String()).To(MatchRegexp(`cell-1\s+cell-1\s+presence\s+9s`))
+		Expect(out.String()).To(MatchRegexp(`auctioneer\s+7\s+2\s+1`))
+	})
+
+	It("shows changes between refreshes as events", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- c.run(ctx, "top", []string{"-interval", "1s"})
+		}()
+
+		Eventually(fakeClient.StatsCallCount).Should(Equal(1))
+		locks = []*models.Resource{
+			{Key: "auctioneer", Owner: "auctioneer-1", TypeCode: models.LOCK},
+		}
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(fakeClient.StatsCallCount).Should(Equal(2))
+
+		cancel()
+		Eventually(errCh).Should(Receive(BeNil()))
+		Expect(out.String()).To(ContainSubstring("auctioneer moved from auctioneer-0 to auctioneer-1"))
+	})
+
+	It("works with servers that do not implement Stats", func() {
+		fakeClient.StatsReturns(nil, grpc.Errorf(codes.Unimplemented, "unknown method"))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(c.run(ctx, "top", nil)).To(Succeed())
+		Expect(out.String()).NotTo(ContainSubstring("CONTENDED KEY"))
+	})
+
+	It("returns fetch errors", func() {
+		fakeClient.FetchAllStub = nil
+		fakeClient.FetchAllReturns(nil, errors.New("boom"))
+		Expect(c.run(context.Background(), "top", nil)).To(MatchError("boom"))
+	})
+})
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// expiry is printed by what-if for each lock or presence held by an owner
+// that is about to be drained. Successor is empty when no one else is
+// waiting for the lock.
+type expiry struct {
+	Key               string    `json:"key"`
+	Owner             string    `json:"owner"`
+	Type              string    `json:"type"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	ExpiresInSeconds  float64   `json:"expires_in_seconds"`
+	Successor         string    `json:"successor,omitempty"`
+	SuccessorIsQueued bool      `json:"successor_is_queued,omitempty"`
+	OtherWaiters      int       `json:"other_waiters,omitempty"`
+}
+
+// whatIf reports what happens if the given owners stop heartbeating now:
+// which of their locks and presences expire and when, and who is likely to
+// take each lock next based on its waiters. Waiters that are also being
+// drained are not considered successors.
+func (c *ctl) whatIf(ctx context.Context, args []string) error {
+	flags := newFlagSet("what-if")
+	if flags.Parse(args) != nil || flags.NArg() == 0 {
+		return errUsage
+	}
+
+	draining := map[string]bool{}
+	for _, owner := range flags.Args() {
+		draining[owner] = true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	now := c.clock.Now()
+	var expiries []*expiry
+	for _, typeCode := range []models.TypeCode{models.LOCK, models.PRESENCE} {
+		resp, err := c.client.FetchAll(ctx, &models.FetchAllRequest{TypeCode: typeCode})
+		if err != nil {
+			return err
+		}
+
+		remaining := map[string]time.Duration{}
+		for _, holder := range resp.Holders {
+			remaining[holder.Key] = time.Duration(holder.RemainingTtlInMilliseconds) * time.Millisecond
+		}
+
+		for _, resource := range resp.Resources {
+			if !draining[resource.Owner] {
+				continue
+			}
+			expiries = append(expiries, &expiry{
+				Key:              resource.Key,
+				Owner:            resource.Owner,
+				Type:             models.GetType(resource),
+				ExpiresAt:        now.Add(remaining[resource.Key]),
+				ExpiresInSeconds: remaining[resource.Key].Seconds(),
+			})
+		}
+	}
+
+	sort.Slice(expiries, func(i, j int) bool {
+		if !expiries[i].ExpiresAt.Equal(expiries[j].ExpiresAt) {
+			return expiries[i].ExpiresAt.Before(expiries[j].ExpiresAt)
+		}
+		return expiries[i].Key < expiries[j].Key
+	})
+
+	for _, e := range expiries {
+		if e.Type == models.LockType {
+			err := c.findSuccessor(ctx, e, draining)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := c.print(e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findSuccessor sets the successor of e to the first waiter on its key that
+// is not being drained.
+func (c *ctl) findSuccessor(ctx context.Context, e *expiry, draining map[string]bool) error {
+	resp, err := c.client.Waiters(ctx, &models.WaitersRequest{Key: e.Key})
+	if grpc.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, waiter := range resp.Waiters {
+		if draining[waiter.Owner] || waiter.Owner == e.Owner {
+			continue
+		}
+		if e.Successor == "" {
+			e.Successor = waiter.Owner
+			e.SuccessorIsQueued = waiter.Queued
+			continue
+		}
+		e.OtherWaiters++
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var _ = Describe("what-if", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		fakeClock  *fakeclock.FakeClock
+		out        *bytes.Buffer
+		c          *ctl
+	)
+
+	expiries := func() []expiry {
+		var result []expiry
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			var e expiry
+			Expect(json.Unmarshal([]byte(line), &e)).To(Succeed())
+			result = append(result, e)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.FetchAllStub = func(ctx context.Context, req *models.FetchAllRequest, opts ...grpc.CallOption) (*models.FetchAllResponse, error) {
+			if req.TypeCode == models.PRESENCE {
+				return &models.FetchAllResponse{
+					Resources: []*models.Resource{{Key: "cell-1", Owner: "cell-1", TypeCode: models.PRESENCE}},
+					Holders:   []*models.LockHolder{{Key: "cell-1", Owner: "cell-1", RemainingTtlInMilliseconds: 3000}},
+				}, nil
+			}
+			return &models.FetchAllResponse{
+				Resources: []*models.Resource{
+					{Key: "auctioneer", Owner: "cell-1", TypeCode: models.LOCK},
+					{Key: "bbs", Owner: "cell-2", TypeCode: models.LOCK},
+				},
+				Holders: []*models.LockHolder{
+					{Key: "auctioneer", Owner: "cell-1", RemainingTtlInMilliseconds: 10000},
+					{Key: "bbs", Owner: "cell-2", RemainingTtlInMilliseconds: 5000},
+				},
+			}, nil
+		}
+		fakeClient.WaitersReturns(&models.WaitersResponse{Waiters: []*models.Waiter{
+			{Owner: "cell-2"},
+			{Owner: "cell-3", Queued: true},
+			{Owner: "cell-4"},
+		}}, nil)
+
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		out = &bytes.Buffer{}
+		c = newCtl(lagertest.NewTestLogger("locketctl"), fakeClient, out, time.Second, fakeClock)
+	})
+
+	It("reports what the drained owners hold, in expiry order, with likely successors", func() {
+		Expect(c.run(context.Background(), "what-if", []string{"cell-1", "cell-2"})).To(Succeed())
+
+		result := expiries()
+		Expect(result).To(HaveLen(3))
+
+		Expect(result[0].Key).To(Equal("cell-1"))
+		Expect(result[0].Type).To(Equal(models.PresenceType))
+		Expect(result[0].ExpiresInSeconds).To(Equal(3.0))
+		Expect(result[0].ExpiresAt).To(BeTemporally("==", fakeClock.Now().Add(3*time.Second)))
+		Expect(result[0].Successor).To(BeEmpty())
+
+		Expect(result[1].Key).To(Equal("bbs"))
+		Expect(result[1].Successor).To(Equal("cell-3"))
+		Expect(result[1].SuccessorIsQueued).To(BeTrue())
+		Expect(result[1].OtherWaiters).To(Equal(1))
+
+		Expect(result[2].Key).To(Equal("auctioneer"))
+		Expect(result[2].ExpiresInSeconds).To(Equal(10.0))
+
+		Expect(fakeClient.WaitersCallCount()).To(Equal(2))
+	})
+
+	It("reports nothing for owners that hold nothing", func() {
+		Expect(c.run(context.Background(), "what-if", []string{"cell-9"})).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("leaves out successors when the server does not list waiters", func() {
+		fakeClient.WaitersReturns(nil, grpc.Errorf(codes.Unimplemented, "unknown method"))
+		Expect(c.run(context.Background(), "what-if", []string{"cell-2"})).To(Succeed())
+		Expect(expiries()[0].Successor).To(BeEmpty())
+	})
+
+	It("returns other errors", func() {
+		fakeClient.WaitersReturns(nil, errors.New("boom"))
+		Expect(c.run(context.Background(), "what-if", []string{"cell-2"})).To(MatchError("boom"))
+	})
+
+	It("requires at least one owner", func() {
+		Expect(c.run(context.Background(), "what-if", nil)).To(Equal(errUsage))
+	})
+})
@@ -0,0 +1,115 @@
+package compression
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+const (
+	// Gzip compresses best, at the cost of more cpu.
+	Gzip = "gzip"
+	// Snappy is much cheaper to compress and decompress than gzip, but the
+	// result is larger.
+	Snappy = "snappy"
+)
+
+// compressedMethods are the RPCs whose responses are compressed. They return
+// every lock of a type, which for presences on a large foundation is several
+// megabytes. The other RPCs return too little to be worth compressing.
+var compressedMethods = map[string]bool{
+	"/models.Locket/FetchAll":    true,
+	"/locket.v2.Locket/FetchAll": true,
+}
+
+func init() {
+	encoding.RegisterCompressor(&snappyCompressor{})
+}
+
+// Validate returns an error unless algorithm is Gzip, Snappy or empty, which
+// turns compression off.
+func Validate(algorithm string) error {
+	switch algorithm {
+	case "", Gzip, Snappy:
+		return nil
+	default:
+		return fmt.Errorf("unknown compression algorithm: %s", algorithm)
+	}
+}
+
+// NewUnaryServerInterceptor compresses the responses of FetchAll with
+// algorithm when the client accepts it. Responses to clients that do not
+// are sent uncompressed.
+func NewUnaryServerInterceptor(algorithm string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if compressedMethods[info.FullMethod] && accepts(ctx, algorithm) {
+			// a failure only means the response is sent uncompressed
+			_ = grpc.SetSendCompressor(ctx, algorithm)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewUnaryClientInterceptor asks for the responses of FetchAll to be
+// compressed with algorithm. Servers compress a response with the algorithm
+// of its request, so this works with servers that do not compress on their
+// own, as long as they support algorithm.
+func NewUnaryClientInterceptor(algorithm string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if compressedMethods[method] {
+			opts = append(opts, grpc.UseCompressor(algorithm))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func accepts(ctx context.Context, algorithm string) bool {
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return false
+	}
+	for _, name := range supported {
+		if name == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+type snappyCompressor struct {
+	writers sync.Pool
+}
+
+func (c *snappyCompressor) Name() string {
+	return Snappy
+}
+
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	writer, ok := c.writers.Get().(*snappyWriter)
+	if !ok {
+		return &snappyWriter{Writer: snappy.NewBufferedWriter(w), pool: &c.writers}, nil
+	}
+	writer.Reset(w)
+	return writer, nil
+}
+
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
+
+// snappyWriter returns itself to the pool once the message is written.
+type snappyWriter struct {
+	*snappy.Writer
+	pool *sync.Pool
+}
+
+func (w *snappyWriter) Close() error {
+	defer w.pool.Put(w)
+	return w.Writer.Close()
+}
@@ -0,0 +1,81 @@
+package compression_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	"code.cloudfoundry.org/locket/compression"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+var _ = Describe("Compression", func() {
+	Describe("Validate", func() {
+		It("accepts the supported algorithms", func() {
+			Expect(compression.Validate("")).To(Succeed())
+			Expect(compression.Validate(compression.Gzip)).To(Succeed())
+			Expect(compression.Validate(compression.Snappy)).To(Succeed())
+		})
+
+		It("rejects anything else", func() {
+			Expect(compression.Validate("zstd")).To(MatchError("unknown compression algorithm: zstd"))
+		})
+	})
+
+	Describe("snappy", func() {
+		It("is registered with grpc", func() {
+			compressor := encoding.GetCompressor(compression.Snappy)
+			Expect(compressor).NotTo(BeNil())
+
+			payload := strings.Repeat("cell-00001 rep ", 1000)
+			for i := 0; i < 2; i++ {
+				var compressed bytes.Buffer
+				writer, err := compressor.Compress(&compressed)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = writer.Write([]byte(payload))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(writer.Close()).To(Succeed())
+				Expect(compressed.Len()).To(BeNumerically("<", len(payload)))
+
+				reader, err := compressor.Decompress(&compressed)
+				Expect(err).NotTo(HaveOccurred())
+				decompressed, err := ioutil.ReadAll(reader)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(decompressed)).To(Equal(payload))
+			}
+		})
+	})
+
+	Describe("NewUnaryClientInterceptor", func() {
+		var (
+			interceptor grpc.UnaryClientInterceptor
+			callOptions []grpc.CallOption
+			invoker     grpc.UnaryInvoker
+		)
+
+		BeforeEach(func() {
+			interceptor = compression.NewUnaryClientInterceptor(compression.Gzip)
+			invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				callOptions = opts
+				return nil
+			}
+		})
+
+		It("compresses FetchAll", func() {
+			Expect(interceptor(context.Background(), "/models.Locket/FetchAll", nil, nil, nil, invoker)).To(Succeed())
+			Expect(callOptions).To(ConsistOf(grpc.CompressorCallOption{CompressorType: compression.Gzip}))
+
+			Expect(interceptor(context.Background(), "/locket.v2.Locket/FetchAll", nil, nil, nil, invoker)).To(Succeed())
+			Expect(callOptions).To(ConsistOf(grpc.CompressorCallOption{CompressorType: compression.Gzip}))
+		})
+
+		It("leaves other requests alone", func() {
+			Expect(interceptor(context.Background(), "/models.Locket/Lock", nil, nil, nil, invoker)).To(Succeed())
+			Expect(callOptions).To(BeEmpty())
+		})
+	})
+})
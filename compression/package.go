@@ -0,0 +1 @@
+package compression // import "code.cloudfoundry.org/locket/compression"
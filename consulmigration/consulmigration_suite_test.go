@@ -0,0 +1,13 @@
+package consulmigration_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConsulmigration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Consulmigration Suite")
+}
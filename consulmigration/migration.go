@@ -0,0 +1,206 @@
+package consulmigration
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/consuladapter"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Entry is a lock or presence held in consul, as it should be held in
+// locket.
+type Entry struct {
+	Resource     *models.Resource `json:"resource"`
+	TtlInSeconds int64            `json:"ttl_in_seconds"`
+}
+
+// Read returns the locks and presences held in consul under
+// locket.LockSchemaRoot, sorted by key. Keys without a session are no
+// longer held and are skipped. Keys starting with one of presencePrefixes,
+// relative to the schema root, become presences and every other key becomes
+// a lock. The owner is the name of the holding session, which the consul
+// lock and presence runners set to a fresh UUID, and the ttl is the
+// session's ttl.
+func Read(logger lager.Logger, client consuladapter.Client, presencePrefixes []string) ([]Entry, error) {
+	logger = logger.Session("read-consul")
+
+	pairs, _, err := client.KV().List(locket.LockSchemaRoot, nil)
+	if err != nil {
+		logger.Error("failed-to-list-keys", err)
+		return nil, err
+	}
+
+	sessionEntries, _, err := client.Session().List(nil)
+	if err != nil {
+		logger.Error("failed-to-list-sessions", err)
+		return nil, err
+	}
+	sessions := make(map[string]*consulSession, len(sessionEntries))
+	for _, session := range sessionEntries {
+		sessions[session.ID] = &consulSession{name: session.Name, ttl: session.TTL}
+	}
+
+	entries := []Entry{}
+	for _, pair := range pairs {
+		if pair.Session == "" {
+			continue
+		}
+
+		session, ok := sessions[pair.Session]
+		if !ok {
+			logger.Info("skipping-key-without-live-session", lager.Data{"key": pair.Key, "session": pair.Session})
+			continue
+		}
+
+		key := strings.TrimPrefix(strings.TrimPrefix(pair.Key, locket.LockSchemaRoot), "/")
+		owner := session.name
+		if owner == "" {
+			owner = pair.Session
+		}
+
+		entries = append(entries, Entry{
+			Resource: &models.Resource{
+				Key:      key,
+				Owner:    owner,
+				Value:    string(pair.Value),
+				Type:     lockType(key, presencePrefixes),
+				TypeCode: typeCode(key, presencePrefixes),
+			},
+			TtlInSeconds: session.ttlInSeconds(),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Resource.Key < entries[j].Resource.Key
+	})
+
+	logger.Info("read", lager.Data{"entries": len(entries)})
+	return entries, nil
+}
+
+// Seed takes each entry in locket with its consul ttl. Entries already held
+// in locket by the same owner are refreshed; entries held by another owner
+// are left alone and returned, since the component has already moved to
+// locket.
+func Seed(logger lager.Logger, ctx context.Context, client models.LocketClient, entries []Entry) ([]Entry, error) {
+	logger = logger.Session("seed")
+
+	collisions := []Entry{}
+	for _, entry := range entries {
+		_, err := client.Lock(ctx, &models.LockRequest{
+			Resource:     entry.Resource,
+			TtlInSeconds: entry.TtlInSeconds,
+		})
+		if grpc.Code(err) == codes.AlreadyExists {
+			logger.Info("already-held", lager.Data{"key": entry.Resource.Key})
+			collisions = append(collisions, entry)
+			continue
+		}
+		if err != nil {
+			logger.Error("failed-to-seed", err, lager.Data{"key": entry.Resource.Key})
+			return nil, err
+		}
+	}
+
+	logger.Info("seeded", lager.Data{"entries": len(entries) - len(collisions), "already-held": len(collisions)})
+	return collisions, nil
+}
+
+const (
+	MissingReason       = "missing"
+	OwnerMismatchReason = "owner-mismatch"
+	ValueMismatchReason = "value-mismatch"
+	TypeMismatchReason  = "type-mismatch"
+)
+
+// Mismatch is a consul entry that is not held the same way in locket.
+// Locket is nil when the key is not held in locket.
+type Mismatch struct {
+	Key    string           `json:"key"`
+	Reason string           `json:"reason"`
+	Consul *models.Resource `json:"consul"`
+	Locket *models.Resource `json:"locket,omitempty"`
+}
+
+// Verify compares each entry with the lock held in locket and returns the
+// entries that are missing or held with a different owner, value or type.
+func Verify(logger lager.Logger, ctx context.Context, client models.LocketClient, entries []Entry) ([]Mismatch, error) {
+	logger = logger.Session("verify")
+
+	mismatches := []Mismatch{}
+	for _, entry := range entries {
+		expected := entry.Resource
+
+		resp, err := client.Fetch(ctx, &models.FetchRequest{Key: expected.Key})
+		if grpc.Code(err) == codes.NotFound {
+			mismatches = append(mismatches, Mismatch{Key: expected.Key, Reason: MissingReason, Consul: expected})
+			continue
+		}
+		if err != nil {
+			logger.Error("failed-to-fetch", err, lager.Data{"key": expected.Key})
+			return nil, err
+		}
+
+		actual := resp.Resource
+		var reason string
+		switch {
+		case actual.Owner != expected.Owner:
+			reason = OwnerMismatchReason
+		case actual.Value != expected.Value:
+			reason = ValueMismatchReason
+		case actual.Type != expected.Type:
+			reason = TypeMismatchReason
+		default:
+			continue
+		}
+		mismatches = append(mismatches, Mismatch{Key: expected.Key, Reason: reason, Consul: expected, Locket: actual})
+	}
+
+	logger.Info("verified", lager.Data{"entries": len(entries), "mismatches": len(mismatches)})
+	return mismatches, nil
+}
+
+type consulSession struct {
+	name string
+	ttl  string
+}
+
+// ttlInSeconds parses the session ttl, such as "15s", falling back to
+// locket.DefaultSessionTTLInSeconds.
+func (s *consulSession) ttlInSeconds() int64 {
+	ttl, err := time.ParseDuration(s.ttl)
+	if err != nil || ttl < time.Second {
+		return locket.DefaultSessionTTLInSeconds
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
+
+func lockType(key string, presencePrefixes []string) string {
+	if isPresence(key, presencePrefixes) {
+		return models.PresenceType
+	}
+	return models.LockType
+}
+
+func typeCode(key string, presencePrefixes []string) models.TypeCode {
+	if isPresence(key, presencePrefixes) {
+		return models.PRESENCE
+	}
+	return models.LOCK
+}
+
+func isPresence(key string, presencePrefixes []string) bool {
+	for _, prefix := range presencePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package consulmigration_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/consuladapter/fakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/consulmigration"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"github.com/hashicorp/consul/api"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Consul migration", func() {
+	var (
+		logger *lagertest.TestLogger
+		lock   *models.Resource
+		cell   *models.Resource
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("consul-migration")
+		lock = &models.Resource{Key: "bbs_lock", Owner: "bbs-uuid", Value: "bbs-value", Type: models.LockType, TypeCode: models.LOCK}
+		cell = &models.Resource{Key: "cell/cell-1", Owner: "cell-uuid", Value: "cell-value", Type: models.PresenceType, TypeCode: models.PRESENCE}
+	})
+
+	Context("Read", func() {
+		var (
+			client         *fakes.FakeClient
+			fakeComponents *fakes.FakeClientComponents
+		)
+
+		BeforeEach(func() {
+			client, fakeComponents = fakes.NewFakeClient()
+			fakeComponents.KV.ListReturns(api.KVPairs{
+				{Key: "v1/locks/cell/cell-1", Value: []byte("cell-value"), Session: "session-2"},
+				{Key: "v1/locks/bbs_lock", Value: []byte("bbs-value"), Session: "session-1"},
+				{Key: "v1/locks/released", Value: []byte("value")},
+				{Key: "v1/locks/orphaned", Value: []byte("value"), Session: "session-gone"},
+			}, nil, nil)
+			fakeComponents.Session.ListReturns([]*api.SessionEntry{
+				{ID: "session-1", Name: "bbs-uuid", TTL: "15s"},
+				{ID: "session-2", Name: "cell-uuid", TTL: "10s"},
+			}, nil, nil)
+		})
+
+		It("returns the held locks and presences sorted by key", func() {
+			entries, err := consulmigration.Read(logger, client, []string{"cell/"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeComponents.KV.ListCallCount()).To(Equal(1))
+			prefix, _ := fakeComponents.KV.ListArgsForCall(0)
+			Expect(prefix).To(Equal("v1/locks"))
+
+			Expect(entries).To(Equal([]consulmigration.Entry{
+				{Resource: lock, TtlInSeconds: 15},
+				{Resource: cell, TtlInSeconds: 10},
+			}))
+		})
+
+		It("treats every key as a lock without presence prefixes", func() {
+			entries, err := consulmigration.Read(logger, client, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries[1].Resource.Type).To(Equal(models.LockType))
+			Expect(entries[1].Resource.TypeCode).To(Equal(models.LOCK))
+		})
+
+		It("falls back to the session ID and the default ttl", func() {
+			fakeComponents.Session.ListReturns([]*api.SessionEntry{
+				{ID: "session-1", TTL: "bogus"},
+			}, nil, nil)
+
+			entries, err := consulmigration.Read(logger, client, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Resource.Owner).To(Equal("session-1"))
+			Expect(entries[0].TtlInSeconds).To(BeEquivalentTo(15))
+		})
+
+		It("returns an error when the keys cannot be listed", func() {
+			fakeComponents.KV.ListReturns(nil, nil, errors.New("boom"))
+			_, err := consulmigration.Read(logger, client, nil)
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+
+	Context("Seed", func() {
+		var fakeClient *modelsfakes.FakeLocketClient
+
+		BeforeEach(func() {
+			fakeClient = &modelsfakes.FakeLocketClient{}
+		})
+
+		It("locks every entry with its ttl", func() {
+			collisions, err := consulmigration.Seed(logger, context.Background(), fakeClient, []consulmigration.Entry{
+				{Resource: lock, TtlInSeconds: 15},
+				{Resource: cell, TtlInSeconds: 10},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(collisions).To(BeEmpty())
+
+			Expect(fakeClient.LockCallCount()).To(Equal(2))
+			_, req, _ := fakeClient.LockArgsForCall(0)
+			Expect(req).To(Equal(&models.LockRequest{Resource: lock, TtlInSeconds: 15}))
+			_, req, _ = fakeClient.LockArgsForCall(1)
+			Expect(req).To(Equal(&models.LockRequest{Resource: cell, TtlInSeconds: 10}))
+		})
+
+		It("returns the entries already held by another owner", func() {
+			fakeClient.LockStub = func(ctx context.Context, req *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+				if req.Resource.Key == "bbs_lock" {
+					return nil, models.ErrLockCollision
+				}
+				return &models.LockResponse{}, nil
+			}
+
+			collisions, err := consulmigration.Seed(logger, context.Background(), fakeClient, []consulmigration.Entry{
+				{Resource: lock, TtlInSeconds: 15},
+				{Resource: cell, TtlInSeconds: 10},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(collisions).To(Equal([]consulmigration.Entry{{Resource: lock, TtlInSeconds: 15}}))
+			Expect(fakeClient.LockCallCount()).To(Equal(2))
+		})
+
+		It("stops on other errors", func() {
+			fakeClient.LockReturns(nil, errors.New("boom"))
+
+			_, err := consulmigration.Seed(logger, context.Background(), fakeClient, []consulmigration.Entry{
+				{Resource: lock, TtlInSeconds: 15},
+				{Resource: cell, TtlInSeconds: 10},
+			})
+			Expect(err).To(MatchError("boom"))
+			Expect(fakeClient.LockCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("Verify", func() {
+		var fakeClient *modelsfakes.FakeLocketClient
+
+		BeforeEach(func() {
+			fakeClient = &modelsfakes.FakeLocketClient{}
+		})
+
+		fetchReturns := func(resources ...*models.Resource) {
+			fakeClient.FetchStub = func(ctx context.Context, req *models.FetchRequest, opts ...grpc.CallOption) (*models.FetchResponse, error) {
+				for _, resource := range resources {
+					if resource.Key == req.Key {
+						return &models.FetchResponse{Resource: resource}, nil
+					}
+				}
+				return nil, models.ErrResourceNotFound
+			}
+		}
+
+		It("reports nothing when locket matches consul", func() {
+			fetchReturns(lock, cell)
+
+			mismatches, err := consulmigration.Verify(logger, context.Background(), fakeClient, []consulmigration.Entry{
+				{Resource: lock}, {Resource: cell},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(BeEmpty())
+		})
+
+		It("reports missing and differing locks", func() {
+			other := &models.Resource{Key: "cell/cell-1", Owner: "someone-else", Value: "cell-value", Type: models.PresenceType}
+			fetchReturns(other)
+
+			mismatches, err := consulmigration.Verify(logger, context.Background(), fakeClient, []consulmigration.Entry{
+				{Resource: lock}, {Resource: cell},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(Equal([]consulmigration.Mismatch{
+				{Key: "bbs_lock", Reason: consulmigration.MissingReason, Consul: lock},
+				{Key: "cell/cell-1", Reason: consulmigration.OwnerMismatchReason, Consul: cell, Locket: other},
+			}))
+		})
+
+		It("returns other fetch errors", func() {
+			fakeClient.FetchReturns(nil, errors.New("boom"))
+			_, err := consulmigration.Verify(logger, context.Background(), fakeClient, []consulmigration.Entry{{Resource: lock}})
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+})
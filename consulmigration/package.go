@@ -0,0 +1 @@
+package consulmigration // import "code.cloudfoundry.org/locket/consulmigration"
@@ -0,0 +1,124 @@
+package consulshim
+
+import (
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/lock"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/nu7hatch/gouuid"
+	"github.com/tedsuo/ifrit"
+)
+
+var ErrLockLost = locket.ErrLockLost
+
+// Lock and Presence are the consul runners of the locket package, backed by
+// a locket server instead. A component switches backends by importing
+// consulshim in place of locket and passing a models.LocketClient in place
+// of its consuladapter.Client.
+type Lock struct {
+	runner ifrit.Runner
+}
+
+// NewLock is locket.NewLock backed by locket. Like the consul lock, it
+// becomes ready once it holds lockKey and exits with ErrLockLost if it
+// loses it.
+func NewLock(
+	logger lager.Logger,
+	locketClient models.LocketClient,
+	lockKey string,
+	lockValue []byte,
+	clock clock.Clock,
+	retryInterval time.Duration,
+	lockTTL time.Duration,
+) Lock {
+	resource := newResource(logger, lockKey, lockValue, models.LockType, models.LOCK)
+	return Lock{
+		runner: lock.NewLockRunner(logger, locketClient, resource, ttlInSeconds(lockTTL), clock, retryInterval),
+	}
+}
+
+func (l Lock) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	acquired := make(chan struct{})
+	exited := make(chan error, 1)
+	go func() {
+		exited <- l.runner.Run(signals, acquired)
+	}()
+
+	select {
+	case <-acquired:
+		close(ready)
+	case err := <-exited:
+		return err
+	}
+
+	err := <-exited
+	if err != nil {
+		return ErrLockLost
+	}
+	return nil
+}
+
+type Presence struct {
+	runner ifrit.Runner
+}
+
+// NewPresence is locket.NewPresence backed by locket. Like the consul
+// presence, it becomes ready once it is first registered and keeps trying
+// to register again after losing it.
+func NewPresence(
+	logger lager.Logger,
+	locketClient models.LocketClient,
+	lockKey string,
+	lockValue []byte,
+	clock clock.Clock,
+	retryInterval time.Duration,
+	lockTTL time.Duration,
+) Presence {
+	resource := newResource(logger, lockKey, lockValue, models.PresenceType, models.PRESENCE)
+	return Presence{
+		runner: lock.NewPresenceRunner(logger, locketClient, resource, ttlInSeconds(lockTTL), clock, retryInterval),
+	}
+}
+
+func (p Presence) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	return p.runner.Run(signals, ready)
+}
+
+// Key returns the locket key for a consul key. Consul keys live under
+// locket.LockSchemaRoot, which locket keys leave out, so that the keys
+// match those copied by the consulmigration package.
+func Key(consulKey string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(consulKey, locket.LockSchemaRoot), "/")
+}
+
+// newResource owns the key with a fresh UUID, as the consul runners named
+// their sessions.
+func newResource(logger lager.Logger, lockKey string, lockValue []byte, lockType string, typeCode models.TypeCode) *models.Resource {
+	uuid, err := uuid.NewV4()
+	if err != nil {
+		logger.Fatal("create-uuid-failed", err)
+	}
+
+	return &models.Resource{
+		Key:      Key(lockKey),
+		Owner:    uuid.String(),
+		Value:    string(lockValue),
+		Type:     lockType,
+		TypeCode: typeCode,
+	}
+}
+
+// ttlInSeconds rounds lockTTL up to whole seconds, since locket ttls are
+// whole seconds like consul session ttls.
+func ttlInSeconds(lockTTL time.Duration) int64 {
+	if lockTTL <= 0 {
+		return locket.DefaultSessionTTLInSeconds
+	}
+	return int64(math.Ceil(lockTTL.Seconds()))
+}
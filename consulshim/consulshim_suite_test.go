@@ -0,0 +1,13 @@
+package consulshim_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestConsulShim(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ConsulShim Suite")
+}
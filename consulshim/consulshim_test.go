@@ -0,0 +1,142 @@
+package consulshim_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/consulshim"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Consul shim", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeLocker *modelsfakes.FakeLocketClient
+		fakeClock  *fakeclock.FakeClock
+		lockTTL    time.Duration
+
+		runner  ifrit.Runner
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("consul-shim")
+		fakeLocker = &modelsfakes.FakeLocketClient{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		lockTTL = locket.DefaultSessionTTL
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+	})
+
+	Context("NewLock", func() {
+		JustBeforeEach(func() {
+			runner = consulshim.NewLock(logger, fakeLocker, locket.LockSchemaPath("auctioneer_lock"), []byte("auctioneer-value"), fakeClock, locket.RetryInterval, lockTTL)
+			process = ifrit.Background(runner)
+		})
+
+		It("holds the key in locket without the consul schema root", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			Expect(fakeLocker.LockCallCount()).To(Equal(1))
+			_, req, _ := fakeLocker.LockArgsForCall(0)
+			Expect(req.Resource.Key).To(Equal("auctioneer_lock"))
+			Expect(req.Resource.Owner).NotTo(BeEmpty())
+			Expect(req.Resource.Value).To(Equal("auctioneer-value"))
+			Expect(req.Resource.Type).To(Equal(models.LockType))
+			Expect(req.Resource.TypeCode).To(Equal(models.LOCK))
+			Expect(req.TtlInSeconds).To(Equal(locket.DefaultSessionTTLInSeconds))
+		})
+
+		Context("when the ttl is not a whole number of seconds", func() {
+			BeforeEach(func() {
+				lockTTL = 1500 * time.Millisecond
+			})
+
+			It("rounds it up", func() {
+				Eventually(fakeLocker.LockCallCount).Should(Equal(1))
+				_, req, _ := fakeLocker.LockArgsForCall(0)
+				Expect(req.TtlInSeconds).To(Equal(int64(2)))
+			})
+		})
+
+		Context("when the lock is held by someone else", func() {
+			BeforeEach(func() {
+				fakeLocker.LockReturns(nil, errors.New("lock-collision"))
+			})
+
+			It("keeps retrying without becoming ready", func() {
+				fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+				Eventually(fakeLocker.LockCallCount).Should(Equal(2))
+				Consistently(process.Ready()).ShouldNot(BeClosed())
+			})
+		})
+
+		Context("when the lock is lost", func() {
+			It("exits with ErrLockLost", func() {
+				Eventually(process.Ready()).Should(BeClosed())
+
+				fakeLocker.LockReturns(nil, errors.New("lock-collision"))
+				fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+
+				Eventually(process.Wait()).Should(Receive(Equal(consulshim.ErrLockLost)))
+			})
+		})
+
+		It("releases the lock when signalled", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			ginkgomon.Interrupt(process)
+			Expect(fakeLocker.ReleaseCallCount()).To(Equal(1))
+			_, req, _ := fakeLocker.ReleaseArgsForCall(0)
+			Expect(req.Resource.Key).To(Equal("auctioneer_lock"))
+		})
+	})
+
+	Context("NewPresence", func() {
+		JustBeforeEach(func() {
+			runner = consulshim.NewPresence(logger, fakeLocker, locket.LockSchemaPath("cell", "cell-1"), []byte("cell-value"), fakeClock, locket.RetryInterval, lockTTL)
+			process = ifrit.Background(runner)
+		})
+
+		It("registers the key as a presence", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			_, req, _ := fakeLocker.LockArgsForCall(0)
+			Expect(req.Resource.Key).To(Equal("cell/cell-1"))
+			Expect(req.Resource.Value).To(Equal("cell-value"))
+			Expect(req.Resource.Type).To(Equal(models.PresenceType))
+			Expect(req.Resource.TypeCode).To(Equal(models.PRESENCE))
+		})
+
+		Context("when the presence is lost", func() {
+			It("keeps trying to register it", func() {
+				Eventually(process.Ready()).Should(BeClosed())
+
+				fakeLocker.LockReturns(nil, errors.New("lock-collision"))
+				fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+				Eventually(fakeLocker.LockCallCount).Should(Equal(2))
+
+				fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+				Eventually(fakeLocker.LockCallCount).Should(Equal(3))
+				Consistently(process.Wait()).ShouldNot(Receive())
+			})
+		})
+	})
+})
+
+var _ = Describe("Key", func() {
+	It("leaves out the consul schema root", func() {
+		Expect(consulshim.Key("v1/locks/bbs_lock")).To(Equal("bbs_lock"))
+		Expect(consulshim.Key("bbs_lock")).To(Equal("bbs_lock"))
+	})
+})
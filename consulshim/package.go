@@ -0,0 +1 @@
+package consulshim // import "code.cloudfoundry.org/locket/consulshim"
@@ -0,0 +1,13 @@
+package contention_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestContention(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Contention Suite")
+}
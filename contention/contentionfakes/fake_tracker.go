@@ -0,0 +1,214 @@
+// This file was generated by counterfeiter
+package contentionfakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/models"
+)
+
+type FakeTracker struct {
+	RecordCollisionStub        func(key string, owner string)
+	recordCollisionMutex       sync.RWMutex
+	recordCollisionArgsForCall []struct {
+		key   string
+		owner string
+	}
+	RecordAcquiredStub        func(key string, owner string, ttl time.Duration)
+	recordAcquiredMutex       sync.RWMutex
+	recordAcquiredArgsForCall []struct {
+		key   string
+		owner string
+		ttl   time.Duration
+	}
+	RecordReleasedStub        func(key string, owner string)
+	recordReleasedMutex       sync.RWMutex
+	recordReleasedArgsForCall []struct {
+		key   string
+		owner string
+	}
+	TopStub        func(n int) []*models.ContendedKey
+	topMutex       sync.RWMutex
+	topArgsForCall []struct {
+		n int
+	}
+	topReturns struct {
+		result1 []*models.ContendedKey
+	}
+	WaitQueuesStub        func() map[string][]contention.Waiter
+	waitQueuesMutex       sync.RWMutex
+	waitQueuesArgsForCall []struct {
+	}
+	waitQueuesReturns struct {
+		result1 map[string][]contention.Waiter
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTracker) RecordCollision(key string, owner string) {
+	fake.recordCollisionMutex.Lock()
+	fake.recordCollisionArgsForCall = append(fake.recordCollisionArgsForCall, struct {
+		key   string
+		owner string
+	}{key, owner})
+	fake.recordInvocation("RecordCollision", []interface{}{key, owner})
+	fake.recordCollisionMutex.Unlock()
+	if fake.RecordCollisionStub != nil {
+		fake.RecordCollisionStub(key, owner)
+	}
+}
+
+func (fake *FakeTracker) RecordCollisionCallCount() int {
+	fake.recordCollisionMutex.RLock()
+	defer fake.recordCollisionMutex.RUnlock()
+	return len(fake.recordCollisionArgsForCall)
+}
+
+func (fake *FakeTracker) RecordCollisionArgsForCall(i int) (string, string) {
+	fake.recordCollisionMutex.RLock()
+	defer fake.recordCollisionMutex.RUnlock()
+	return fake.recordCollisionArgsForCall[i].key, fake.recordCollisionArgsForCall[i].owner
+}
+
+func (fake *FakeTracker) RecordAcquired(key string, owner string, ttl time.Duration) {
+	fake.recordAcquiredMutex.Lock()
+	fake.recordAcquiredArgsForCall = append(fake.recordAcquiredArgsForCall, struct {
+		key   string
+		owner string
+		ttl   time.Duration
+	}{key, owner, ttl})
+	fake.recordInvocation("RecordAcquired", []interface{}{key, owner, ttl})
+	fake.recordAcquiredMutex.Unlock()
+	if fake.RecordAcquiredStub != nil {
+		fake.RecordAcquiredStub(key, owner, ttl)
+	}
+}
+
+func (fake *FakeTracker) RecordAcquiredCallCount() int {
+	fake.recordAcquiredMutex.RLock()
+	defer fake.recordAcquiredMutex.RUnlock()
+	return len(fake.recordAcquiredArgsForCall)
+}
+
+func (fake *FakeTracker) RecordAcquiredArgsForCall(i int) (string, string, time.Duration) {
+	fake.recordAcquiredMutex.RLock()
+	defer fake.recordAcquiredMutex.RUnlock()
+	return fake.recordAcquiredArgsForCall[i].key, fake.recordAcquiredArgsForCall[i].owner, fake.recordAcquiredArgsForCall[i].ttl
+}
+
+func (fake *FakeTracker) RecordReleased(key string, owner string) {
+	fake.recordReleasedMutex.Lock()
+	fake.recordReleasedArgsForCall = append(fake.recordReleasedArgsForCall, struct {
+		key   string
+		owner string
+	}{key, owner})
+	fake.recordInvocation("RecordReleased", []interface{}{key, owner})
+	fake.recordReleasedMutex.Unlock()
+	if fake.RecordReleasedStub != nil {
+		fake.RecordReleasedStub(key, owner)
+	}
+}
+
+func (fake *FakeTracker) RecordReleasedCallCount() int {
+	fake.recordReleasedMutex.RLock()
+	defer fake.recordReleasedMutex.RUnlock()
+	return len(fake.recordReleasedArgsForCall)
+}
+
+func (fake *FakeTracker) RecordReleasedArgsForCall(i int) (string, string) {
+	fake.recordReleasedMutex.RLock()
+	defer fake.recordReleasedMutex.RUnlock()
+	return fake.recordReleasedArgsForCall[i].key, fake.recordReleasedArgsForCall[i].owner
+}
+
+func (fake *FakeTracker) Top(n int) []*models.ContendedKey {
+	fake.topMutex.Lock()
+	fake.topArgsForCall = append(fake.topArgsForCall, struct {
+		n int
+	}{n})
+	fake.recordInvocation("Top", []interface{}{n})
+	fake.topMutex.Unlock()
+	if fake.TopStub != nil {
+		return fake.TopStub(n)
+	} else {
+		return fake.topReturns.result1
+	}
+}
+
+func (fake *FakeTracker) TopCallCount() int {
+	fake.topMutex.RLock()
+	defer fake.topMutex.RUnlock()
+	return len(fake.topArgsForCall)
+}
+
+func (fake *FakeTracker) TopArgsForCall(i int) int {
+	fake.topMutex.RLock()
+	defer fake.topMutex.RUnlock()
+	return fake.topArgsForCall[i].n
+}
+
+func (fake *FakeTracker) TopReturns(result1 []*models.ContendedKey) {
+	fake.TopStub = nil
+	fake.topReturns = struct {
+		result1 []*models.ContendedKey
+	}{result1}
+}
+
+func (fake *FakeTracker) WaitQueues() map[string][]contention.Waiter {
+	fake.waitQueuesMutex.Lock()
+	fake.waitQueuesArgsForCall = append(fake.waitQueuesArgsForCall, struct {
+	}{})
+	fake.recordInvocation("WaitQueues", []interface{}{})
+	fake.waitQueuesMutex.Unlock()
+	if fake.WaitQueuesStub != nil {
+		return fake.WaitQueuesStub()
+	} else {
+		return fake.waitQueuesReturns.result1
+	}
+}
+
+func (fake *FakeTracker) WaitQueuesCallCount() int {
+	fake.waitQueuesMutex.RLock()
+	defer fake.waitQueuesMutex.RUnlock()
+	return len(fake.waitQueuesArgsForCall)
+}
+
+func (fake *FakeTracker) WaitQueuesReturns(result1 map[string][]contention.Waiter) {
+	fake.WaitQueuesStub = nil
+	fake.waitQueuesReturns = struct {
+		result1 map[string][]contention.Waiter
+	}{result1}
+}
+
+func (fake *FakeTracker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.recordCollisionMutex.RLock()
+	defer fake.recordCollisionMutex.RUnlock()
+	fake.recordAcquiredMutex.RLock()
+	defer fake.recordAcquiredMutex.RUnlock()
+	fake.recordReleasedMutex.RLock()
+	defer fake.recordReleasedMutex.RUnlock()
+	fake.topMutex.RLock()
+	defer fake.topMutex.RUnlock()
+	fake.waitQueuesMutex.RLock()
+	defer fake.waitQueuesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeTracker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ contention.Tracker = new(FakeTracker)
@@ -0,0 +1 @@
+package contentionfakes // import "code.cloudfoundry.org/locket/contention/contentionfakes"
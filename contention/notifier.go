@@ -0,0 +1,86 @@
+package contention
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"github.com/tedsuo/ifrit"
+)
+
+const (
+	maxFailedAttempts = "LockContentionMaxFailedAttempts"
+	maxWaiters        = "LockContentionMaxWaiters"
+)
+
+type notifier struct {
+	logger          lager.Logger
+	tracker         Tracker
+	clock           clock.Clock
+	metronClient    emitter.Emitter
+	metricsInterval time.Duration
+	topN            int
+}
+
+// NewNotifier returns a runner that periodically emits the worst contention
+// seen over the tracker's window and logs the most contended keys.
+func NewNotifier(logger lager.Logger, tracker Tracker, clock clock.Clock, metronClient emitter.Emitter, metricsInterval time.Duration, topN int) ifrit.Runner {
+	return &notifier{
+		logger:          logger,
+		tracker:         tracker,
+		clock:           clock,
+		metronClient:    metronClient,
+		metricsInterval: metricsInterval,
+		topN:            topN,
+	}
+}
+
+func (n *notifier) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := n.logger.Session("contention-notifier")
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	tick := n.clock.NewTicker(n.metricsInterval)
+	defer tick.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-tick.C():
+			n.emit(logger)
+		}
+	}
+}
+
+func (n *notifier) emit(logger lager.Logger) {
+	contended := n.tracker.Top(n.topN)
+
+	var failures, waiters int64
+	for _, key := range contended {
+		if key.FailedAttempts > failures {
+			failures = key.FailedAttempts
+		}
+		if key.Waiters > waiters {
+			waiters = key.Waiters
+		}
+	}
+
+	err := n.metronClient.SendMetric(maxFailedAttempts, int(failures))
+	if err != nil {
+		logger.Error("failed-sending-max-failed-attempts", err)
+	}
+
+	err = n.metronClient.SendMetric(maxWaiters, int(waiters))
+	if err != nil {
+		logger.Error("failed-sending-max-waiters", err)
+	}
+
+	if len(contended) > 0 {
+		logger.Info("contended-keys", lager.Data{"keys": contended})
+	}
+}
@@ -0,0 +1,70 @@
+package contention_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/contention/contentionfakes"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Notifier", func() {
+	var (
+		process          ifrit.Process
+		fakeTracker      *contentionfakes.FakeTracker
+		fakeMetronClient *mfakes.FakeIngressClient
+		fakeClock        *fakeclock.FakeClock
+		logger           *lagertest.TestLogger
+		metricsInterval  time.Duration
+	)
+
+	BeforeEach(func() {
+		fakeTracker = &contentionfakes.FakeTracker{}
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("contention")
+		metricsInterval = 10 * time.Second
+
+		fakeTracker.TopReturns([]*models.ContendedKey{
+			{Key: "auctioneer", FailedAttempts: 12, Waiters: 2},
+			{Key: "bbs", FailedAttempts: 3, Waiters: 4},
+		})
+	})
+
+	JustBeforeEach(func() {
+		runner := contention.NewNotifier(logger, fakeTracker, fakeClock, fakeMetronClient, metricsInterval, 5)
+		process = ginkgomon.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("emits the worst contention on an interval", func() {
+		fakeClock.Increment(metricsInterval)
+
+		Eventually(fakeMetronClient.SendMetricCallCount).Should(Equal(2))
+		name, value := fakeMetronClient.SendMetricArgsForCall(0)
+		Expect(name).To(Equal("LockContentionMaxFailedAttempts"))
+		Expect(value).To(Equal(12))
+
+		name, value = fakeMetronClient.SendMetricArgsForCall(1)
+		Expect(name).To(Equal("LockContentionMaxWaiters"))
+		Expect(value).To(Equal(4))
+
+		Expect(fakeTracker.TopArgsForCall(0)).To(Equal(5))
+	})
+
+	It("logs the contended keys", func() {
+		fakeClock.Increment(metricsInterval)
+		Eventually(logger).Should(gbytes.Say("contended-keys"))
+	})
+})
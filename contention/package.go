@@ -0,0 +1 @@
+package contention // import "code.cloudfoundry.org/locket/contention"
@@ -0,0 +1,262 @@
+package contention
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/models"
+)
+
+const (
+	lockHeldDuration     = "LockHeldDuration"
+	lockOwnershipChanges = "LockOwnershipChanges"
+)
+
+//go:generate counterfeiter . Tracker
+type Tracker interface {
+	RecordCollision(key, owner string)
+	RecordAcquired(key, owner string, ttl time.Duration)
+	RecordReleased(key, owner string)
+	Top(n int) []*models.ContendedKey
+	WaitQueues() map[string][]Waiter
+}
+
+// Waiter is an owner that has failed to acquire a key and has not yet
+// acquired it or aged out of the window.
+type Waiter struct {
+	Owner        string    `json:"owner"`
+	WaitingSince time.Time `json:"waiting_since"`
+}
+
+type holder struct {
+	owner     string
+	since     time.Time
+	expiresAt time.Time
+}
+
+type keyContention struct {
+	failures         []time.Time
+	ownershipChanges []time.Time
+	waiters          map[string]time.Time
+}
+
+type tracker struct {
+	logger       lager.Logger
+	clock        clock.Clock
+	metronClient emitter.Emitter
+	tagger       *metrics.KeyTagger
+	window       time.Duration
+	keys         map[string]*keyContention
+	holders      map[string]holder
+	lastPrune    time.Time
+	mutex        *sync.Mutex
+}
+
+// NewTracker returns a Tracker that counts failed acquisition attempts,
+// distinct waiting owners and ownership changes per key over a rolling
+// window. It also emits how long each lock was held and a counter every time
+// a lock changes hands, and the same metrics tagged by tagger with the key.
+// A lock that is neither refreshed nor released is taken to have been held
+// until its TTL ran out, and is forgotten then.
+func NewTracker(logger lager.Logger, clock clock.Clock, metronClient emitter.Emitter, tagger *metrics.KeyTagger, window time.Duration) Tracker {
+	return &tracker{
+		logger:       logger.Session("contention-tracker"),
+		clock:        clock,
+		metronClient: metronClient,
+		tagger:       tagger,
+		window:       window,
+		keys:         make(map[string]*keyContention),
+		holders:      make(map[string]holder),
+		mutex:        &sync.Mutex{},
+	}
+}
+
+func (t *tracker) RecordCollision(key, owner string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	t.pruneEveryWindow(now)
+
+	k := t.keyContention(key)
+	k.failures = append(k.failures, now)
+	k.waiters[owner] = now
+}
+
+func (t *tracker) RecordAcquired(key, owner string, ttl time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	t.pruneEveryWindow(now)
+
+	if k, ok := t.keys[key]; ok {
+		delete(k.waiters, owner)
+	}
+
+	current, held := t.holders[key]
+	if held && current.owner == owner {
+		current.expiresAt = now.Add(ttl)
+		t.holders[key] = current
+		return
+	}
+
+	if held {
+		t.sendHeldDuration(key, current, now)
+
+		k := t.keyContention(key)
+		k.ownershipChanges = append(k.ownershipChanges, now)
+
+		err := t.metronClient.IncrementCounter(lockOwnershipChanges)
+		if err != nil {
+			t.logger.Error("failed-sending-ownership-change", err)
+		}
+		t.tagger.IncrementCounter(t.logger, t.metronClient, lockOwnershipChanges, key)
+	}
+
+	t.holders[key] = holder{owner: owner, since: now, expiresAt: now.Add(ttl)}
+}
+
+func (t *tracker) RecordReleased(key, owner string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	current, held := t.holders[key]
+	if !held || current.owner != owner {
+		return
+	}
+
+	t.sendHeldDuration(key, current, t.clock.Now())
+	delete(t.holders, key)
+}
+
+func (t *tracker) Top(n int) []*models.ContendedKey {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.prune()
+
+	contended := make([]*models.ContendedKey, 0, len(t.keys))
+	for key, k := range t.keys {
+		contended = append(contended, &models.ContendedKey{
+			Key:              key,
+			FailedAttempts:   int64(len(k.failures)),
+			Waiters:          int64(len(k.waiters)),
+			OwnershipChanges: int64(len(k.ownershipChanges)),
+		})
+	}
+
+	sort.Slice(contended, func(i, j int) bool {
+		if contended[i].FailedAttempts != contended[j].FailedAttempts {
+			return contended[i].FailedAttempts > contended[j].FailedAttempts
+		}
+		if contended[i].Waiters != contended[j].Waiters {
+			return contended[i].Waiters > contended[j].Waiters
+		}
+		if contended[i].OwnershipChanges != contended[j].OwnershipChanges {
+			return contended[i].OwnershipChanges > contended[j].OwnershipChanges
+		}
+		return contended[i].Key < contended[j].Key
+	})
+
+	if n > 0 && len(contended) > n {
+		contended = contended[:n]
+	}
+	return contended
+}
+
+// WaitQueues returns the owners currently waiting on each key, oldest first.
+func (t *tracker) WaitQueues() map[string][]Waiter {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.prune()
+
+	queues := make(map[string][]Waiter)
+	for key, k := range t.keys {
+		if len(k.waiters) == 0 {
+			continue
+		}
+
+		waiters := make([]Waiter, 0, len(k.waiters))
+		for owner, since := range k.waiters {
+			waiters = append(waiters, Waiter{Owner: owner, WaitingSince: since})
+		}
+
+		sort.Slice(waiters, func(i, j int) bool {
+			if !waiters[i].WaitingSince.Equal(waiters[j].WaitingSince) {
+				return waiters[i].WaitingSince.Before(waiters[j].WaitingSince)
+			}
+			return waiters[i].Owner < waiters[j].Owner
+		})
+		queues[key] = waiters
+	}
+	return queues
+}
+
+func (t *tracker) keyContention(key string) *keyContention {
+	k, ok := t.keys[key]
+	if !ok {
+		k = &keyContention{waiters: make(map[string]time.Time)}
+		t.keys[key] = k
+	}
+	return k
+}
+
+func (t *tracker) sendHeldDuration(key string, h holder, now time.Time) {
+	err := t.metronClient.SendDuration(lockHeldDuration, now.Sub(h.since))
+	if err != nil {
+		t.logger.Error("failed-sending-held-duration", err, lager.Data{"key": key})
+	}
+	t.tagger.SendDuration(t.logger, t.metronClient, lockHeldDuration, key, now.Sub(h.since))
+}
+
+// pruneEveryWindow prunes at most once per window, so that keys and holders
+// nobody asks about are forgotten without walking them on every call.
+func (t *tracker) pruneEveryWindow(now time.Time) {
+	if now.Sub(t.lastPrune) < t.window {
+		return
+	}
+	t.prune()
+}
+
+func (t *tracker) prune() {
+	now := t.clock.Now()
+	t.lastPrune = now
+	cutoff := now.Add(-t.window)
+
+	for key, h := range t.holders {
+		if now.After(h.expiresAt) {
+			t.sendHeldDuration(key, h, h.expiresAt)
+			delete(t.holders, key)
+		}
+	}
+
+	for key, k := range t.keys {
+		k.failures = pruneTimes(k.failures, cutoff)
+		k.ownershipChanges = pruneTimes(k.ownershipChanges, cutoff)
+
+		for owner, seen := range k.waiters {
+			if !seen.After(cutoff) {
+				delete(k.waiters, owner)
+			}
+		}
+
+		if len(k.failures) == 0 && len(k.waiters) == 0 && len(k.ownershipChanges) == 0 {
+			delete(t.keys, key)
+		}
+	}
+}
+
+func pruneTimes(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,169 @@
+package contention_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		tracker          contention.Tracker
+		fakeClock        *fakeclock.FakeClock
+		fakeMetronClient *mfakes.FakeIngressClient
+		window           time.Duration
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		window = time.Minute
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		tracker = contention.NewTracker(lagertest.NewTestLogger("contention"), fakeClock, fakeMetronClient, nil, window)
+
+		tracker.RecordCollision("auctioneer", "cell-1")
+		tracker.RecordCollision("auctioneer", "cell-2")
+		tracker.RecordCollision("auctioneer", "cell-2")
+		tracker.RecordCollision("bbs", "bbs-1")
+	})
+
+	It("returns the most contended keys first", func() {
+		Expect(tracker.Top(10)).To(Equal([]*models.ContendedKey{
+			{Key: "auctioneer", FailedAttempts: 3, Waiters: 2},
+			{Key: "bbs", FailedAttempts: 1, Waiters: 1},
+		}))
+	})
+
+	It("limits the result to n keys", func() {
+		Expect(tracker.Top(1)).To(Equal([]*models.ContendedKey{
+			{Key: "auctioneer", FailedAttempts: 3, Waiters: 2},
+		}))
+	})
+
+	It("returns the waiters for each key, oldest first", func() {
+		start := fakeClock.Now()
+		fakeClock.Increment(time.Second)
+		tracker.RecordCollision("auctioneer", "cell-3")
+
+		Expect(tracker.WaitQueues()).To(Equal(map[string][]contention.Waiter{
+			"auctioneer": {
+				{Owner: "cell-1", WaitingSince: start},
+				{Owner: "cell-2", WaitingSince: start},
+				{Owner: "cell-3", WaitingSince: start.Add(time.Second)},
+			},
+			"bbs": {
+				{Owner: "bbs-1", WaitingSince: start},
+			},
+		}))
+	})
+
+	Context("when a waiter acquires the lock", func() {
+		BeforeEach(func() {
+			tracker.RecordAcquired("auctioneer", "cell-2", time.Minute)
+		})
+
+		It("is no longer counted as a waiter", func() {
+			Expect(tracker.Top(1)[0].Waiters).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("when the window passes", func() {
+		BeforeEach(func() {
+			fakeClock.Increment(window / 2)
+			tracker.RecordCollision("bbs", "bbs-2")
+			fakeClock.Increment(window / 2)
+		})
+
+		It("forgets attempts outside of the window", func() {
+			Expect(tracker.Top(10)).To(Equal([]*models.ContendedKey{
+				{Key: "bbs", FailedAttempts: 1, Waiters: 1},
+			}))
+		})
+	})
+	Context("when locks are acquired and released", func() {
+		BeforeEach(func() {
+			tracker.RecordAcquired("bbs", "bbs-1", time.Minute)
+			fakeClock.Increment(30 * time.Second)
+		})
+
+		It("does not count renewals by the same owner as ownership changes", func() {
+			tracker.RecordAcquired("bbs", "bbs-1", time.Minute)
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(0))
+		})
+
+		It("emits the hold duration when the lock is released", func() {
+			tracker.RecordReleased("bbs", "bbs-1")
+
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+			name, value := fakeMetronClient.SendDurationArgsForCall(0)
+			Expect(name).To(Equal("LockHeldDuration"))
+			Expect(value).To(Equal(30 * time.Second))
+		})
+
+		It("ignores releases by other owners", func() {
+			tracker.RecordReleased("bbs", "bbs-2")
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(0))
+		})
+
+		Context("when the lock expires without being released", func() {
+			BeforeEach(func() {
+				fakeClock.Increment(time.Minute)
+			})
+
+			It("emits the hold duration up to the expiry and forgets the holder", func() {
+				tracker.Top(10)
+
+				Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+				_, value := fakeMetronClient.SendDurationArgsForCall(0)
+				Expect(value).To(Equal(time.Minute))
+
+				tracker.RecordAcquired("bbs", "bbs-2", time.Minute)
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+			})
+
+			It("forgets the holder when recording, once per window", func() {
+				tracker.RecordCollision("auctioneer", "cell-1")
+				Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+			})
+		})
+
+		It("keeps holders that keep refreshing", func() {
+			fakeClock.Increment(15 * time.Second)
+			tracker.RecordAcquired("bbs", "bbs-1", time.Minute)
+			fakeClock.Increment(45 * time.Second)
+			tracker.Top(10)
+
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(0))
+		})
+
+		Context("when another owner takes over the lock", func() {
+			BeforeEach(func() {
+				tracker.RecordAcquired("bbs", "bbs-2", time.Minute)
+			})
+
+			It("emits the previous owner's hold duration and an ownership change", func() {
+				Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+				_, value := fakeMetronClient.SendDurationArgsForCall(0)
+				Expect(value).To(Equal(30 * time.Second))
+
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockOwnershipChanges"))
+			})
+
+			It("reports the ownership change for the key", func() {
+				Expect(tracker.Top(10)).To(ContainElement(&models.ContendedKey{
+					Key:              "bbs",
+					FailedAttempts:   1,
+					Waiters:          0,
+					OwnershipChanges: 1,
+				}))
+			})
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package locket
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+	"google.golang.org/grpc"
+)
+
+// DefaultRequestTimeout bounds the requests of a ContextClient made with a
+// context that has no deadline.
+const DefaultRequestTimeout = 10 * time.Second
+
+// ContextClient wraps a models.LocketClient for callers using the standard
+// library's context. A request whose context has no deadline is given the
+// client's timeout, and errors the server returns are turned back into the
+// models Err values, so they can be compared with ==. KeepAlive streams
+// are not timed out, so they are left to the wrapped client.
+type ContextClient struct {
+	client  models.LocketClient
+	timeout time.Duration
+}
+
+// NewContextClient wraps client. A timeout of 0 uses DefaultRequestTimeout.
+func NewContextClient(client models.LocketClient, timeout time.Duration) *ContextClient {
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	return &ContextClient{client: client, timeout: timeout}
+}
+
+// Client returns the wrapped client.
+func (c *ContextClient) Client() models.LocketClient {
+	return c.client
+}
+
+func (c *ContextClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+func (c *ContextClient) Lock(ctx context.Context, req *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.Lock(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+// Acquire waits in line for the lock, so it usually needs a longer deadline
+// than the client's timeout.
+func (c *ContextClient) Acquire(ctx context.Context, req *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.Acquire(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+func (c *ContextClient) LockGroup(ctx context.Context, req *models.LockGroupRequest, opts ...grpc.CallOption) (*models.LockGroupResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.LockGroup(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+func (c *ContextClient) Release(ctx context.Context, req *models.ReleaseRequest, opts ...grpc.CallOption) (*models.ReleaseResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.Release(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+func (c *ContextClient) Fetch(ctx context.Context, req *models.FetchRequest, opts ...grpc.CallOption) (*models.FetchResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.Fetch(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+func (c *ContextClient) FetchAll(ctx context.Context, req *models.FetchAllRequest, opts ...grpc.CallOption) (*models.FetchAllResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.FetchAll(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+func (c *ContextClient) Stats(ctx context.Context, req *models.StatsRequest, opts ...grpc.CallOption) (*models.StatsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.Stats(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+func (c *ContextClient) Waiters(ctx context.Context, req *models.WaitersRequest, opts ...grpc.CallOption) (*models.WaitersResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.Waiters(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
+
+// ArriveAtBarrier waits for the rest of the parties, so it usually needs a
+// longer deadline than the client's timeout.
+func (c *ContextClient) ArriveAtBarrier(ctx context.Context, req *models.BarrierRequest, opts ...grpc.CallOption) (*models.BarrierResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.client.ArriveAtBarrier(ctx, req, opts...)
+	return resp, models.ErrorFrom(err)
+}
@@ -0,0 +1,74 @@
+package locket_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var _ = Describe("ContextClient", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		client     *locket.ContextClient
+	)
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		client = locket.NewContextClient(fakeClient, time.Minute)
+	})
+
+	It("gives a request without a deadline the client's timeout", func() {
+		_, err := client.Fetch(context.Background(), &models.FetchRequest{Key: "key"})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, req, _ := fakeClient.FetchArgsForCall(0)
+		Expect(req.Key).To(Equal("key"))
+		deadline, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+	})
+
+	It("keeps the deadline of the caller's context", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		_, err := client.Lock(ctx, &models.LockRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		lockCtx, _, _ := fakeClient.LockArgsForCall(0)
+		deadline, _ := lockCtx.Deadline()
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+	})
+
+	It("cancels the request once it returns", func() {
+		_, err := client.Release(context.Background(), &models.ReleaseRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, _, _ := fakeClient.ReleaseArgsForCall(0)
+		Expect(ctx.Err()).To(Equal(context.Canceled))
+	})
+
+	It("returns the models error the server sent", func() {
+		fakeClient.LockReturns(nil, grpc.Errorf(codes.AlreadyExists, "lock-collision"))
+
+		_, err := client.Lock(context.Background(), &models.LockRequest{})
+		Expect(err).To(BeIdenticalTo(models.ErrLockCollision))
+	})
+
+	It("uses the default timeout when none is given", func() {
+		client = locket.NewContextClient(fakeClient, 0)
+		_, err := client.FetchAll(context.Background(), &models.FetchAllRequest{})
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, _, _ := fakeClient.FetchAllArgsForCall(0)
+		deadline, _ := ctx.Deadline()
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(locket.DefaultRequestTimeout), time.Second))
+	})
+})
@@ -0,0 +1,251 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/tracing"
+)
+
+const (
+	DefaultClockSyncInterval = time.Minute
+	DefaultSkewThreshold     = time.Second
+
+	databaseClockSkew = "DatabaseClockSkew"
+)
+
+var ErrClockSkew = errors.New("host clock is too far from the database clock")
+
+// DatabaseClock is a clock that tells the database's time rather than the
+// host's. It reads the time from the database every sync interval, and in
+// between advances it by the time measured on the host's monotonic clock,
+// so that steps of the host's wall clock, from NTP or a VM being paused and
+// resumed, do not move it. Expiry timestamps written with it are therefore
+// in the database's time, which every server sharing the database agrees
+// on, and are not expired early or kept forever when the clock of the
+// server that wrote them jumps. Each sync re-bases the clock on the time it
+// reads, so when it finds the database behind, Now steps back to follow it,
+// and the step is logged. Timers and tickers are those of the wrapped clock.
+type DatabaseClock struct {
+	clock.Clock
+	logger       lager.Logger
+	db           *sql.DB
+	flavor       string
+	syncInterval time.Duration
+
+	mutex         sync.Mutex
+	base          time.Time
+	sampledAt     time.Time
+	last          time.Time
+	metronClient  emitter.Emitter
+	skewThreshold time.Duration
+}
+
+// NewDatabaseClock returns a clock that follows the time of db. Until it is
+// first synced it tells the time of clock.
+func NewDatabaseClock(logger lager.Logger, db *sql.DB, flavor string, clock clock.Clock, syncInterval time.Duration) *DatabaseClock {
+	if syncInterval <= 0 {
+		syncInterval = DefaultClockSyncInterval
+	}
+
+	return &DatabaseClock{
+		Clock:        clock,
+		logger:       logger.Session("database-clock"),
+		db:           db,
+		flavor:       flavor,
+		syncInterval: syncInterval,
+	}
+}
+
+func (c *DatabaseClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.base.IsZero() {
+		return c.Clock.Now()
+	}
+
+	// Since measures the elapsed time on the monotonic clock, as sampledAt
+	// was read from the wrapped clock
+	now := c.base.Add(c.Clock.Since(c.sampledAt))
+	if now.After(c.last) {
+		c.last = now
+	}
+	return now
+}
+
+func (c *DatabaseClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// AlertOnSkew makes each sync report how far apart the host's wall clock
+// and the database's clock are as the DatabaseClockSkew metric, and log an
+// error when they are more than threshold apart.
+func (c *DatabaseClock) AlertOnSkew(metronClient emitter.Emitter, threshold time.Duration) {
+	if threshold <= 0 {
+		threshold = DefaultSkewThreshold
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.metronClient = metronClient
+	c.skewThreshold = threshold
+}
+
+// Sync reads the time from the database. The time read is taken to be that
+// of halfway through the query, to make up for the round trip.
+func (c *DatabaseClock) Sync(logger lager.Logger) error {
+	start := c.Clock.Now()
+	base, err := readDatabaseTime(c.db, c.flavor)
+	if err != nil {
+		logger.Error("failed-to-read-database-time", err)
+		return err
+	}
+	end := c.Clock.Now()
+
+	sampledAt := start.Add(end.Sub(start) / 2)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// the wall clock reading, without the monotonic one, to compare with
+	// the database's
+	skew := base.Sub(sampledAt.Round(0))
+	if !c.base.IsZero() {
+		drift := base.Sub(c.base.Add(sampledAt.Sub(c.sampledAt)))
+		if drift < -time.Second || drift > time.Second {
+			logger.Info("database-clock-drifted", lager.Data{"drift": drift.String()})
+		}
+	}
+	// holding at the last time told until the database catches up would
+	// stop expiries for as long as the database was behind
+	if now := base.Add(c.Clock.Since(sampledAt)); now.Before(c.last) {
+		logger.Info("database-clock-stepped-back", lager.Data{"step": c.last.Sub(now).String()})
+	}
+	logger.Debug("synced", lager.Data{"skew": skew.String()})
+
+	if c.metronClient != nil {
+		absSkew := skew
+		if absSkew < 0 {
+			absSkew = -absSkew
+		}
+		sendErr := c.metronClient.SendDuration(databaseClockSkew, absSkew)
+		if sendErr != nil {
+			logger.Error("failed-sending-database-clock-skew", sendErr)
+		}
+		if absSkew > c.skewThreshold {
+			logger.Error("database-clock-skew-too-high", ErrClockSkew, lager.Data{"skew": skew.String(), "threshold": c.skewThreshold.String()})
+		}
+	}
+
+	c.base = base
+	c.sampledAt = sampledAt
+	return nil
+}
+
+// Run syncs the clock every sync interval. A failed sync leaves the clock
+// advancing from the last one.
+func (c *DatabaseClock) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := c.logger
+	logger.Info("started", lager.Data{"sync-interval": c.syncInterval.String()})
+	defer logger.Info("complete")
+
+	ticker := c.Clock.NewTicker(c.syncInterval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+		case <-ticker.C():
+			c.Sync(logger)
+		}
+	}
+}
+
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// readDatabaseTime reads the current time from the database, to the
+// microsecond.
+func readDatabaseTime(q rowQuerier, flavor string) (time.Time, error) {
+	query := `SELECT CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS SIGNED)`
+	if flavor == helpers.Postgres {
+		query = `SELECT (EXTRACT(EPOCH FROM clock_timestamp()) * 1000000)::BIGINT`
+	}
+
+	var micros int64
+	err := q.QueryRow(query).Scan(&micros)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, micros*int64(time.Microsecond)), nil
+}
+
+// WithDatabaseNow returns a copy of the SQLDB that reads the time from the
+// database at the start of each transaction, and uses it for every expiry
+// it writes or compares in that transaction, instead of asking its clock.
+// It costs a query per transaction, but no server's clock matters then,
+// not even for the time between two syncs of a DatabaseClock.
+func (db *SQLDB) WithDatabaseNow() *SQLDB {
+	withDatabaseNow := *db
+	withDatabaseNow.databaseNow = true
+	return &withDatabaseNow
+}
+
+// atDatabaseNow returns the SQLDB to use for a query on q. With
+// WithDatabaseNow, it is a copy whose clock is stopped at the database's
+// time. Otherwise it is the SQLDB itself.
+func (db *SQLDB) atDatabaseNow(logger lager.Logger, q rowQuerier) (*SQLDB, error) {
+	if !db.databaseNow {
+		return db, nil
+	}
+
+	_, span := tracing.StartLoggerSpan(logger, "sql.read-database-time")
+	now, err := readDatabaseTime(q, db.flavor)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		logger.Error("failed-to-read-database-time", err)
+		return nil, err
+	}
+
+	atNow := *db
+	atNow.clock = stoppedClock{Clock: db.clock, now: now}
+	return &atNow, nil
+}
+
+// transact runs f in a transaction, like tracedTransact, with the SQLDB
+// that f should use for it.
+func (db *SQLDB) transact(logger lager.Logger, f func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error) error {
+	return db.tracedTransact(logger, func(logger lager.Logger, tx *sql.Tx) error {
+		txDB, err := db.atDatabaseNow(logger, tx)
+		if err != nil {
+			return err
+		}
+		return f(logger, tx, txDB)
+	})
+}
+
+type stoppedClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c stoppedClock) Now() time.Time {
+	return c.now
+}
+
+func (c stoppedClock) Since(t time.Time) time.Duration {
+	return c.now.Sub(t)
+}
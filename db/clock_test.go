@@ -0,0 +1,117 @@
+package db_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	sqldb "code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("DatabaseClock", func() {
+	var (
+		hostClock *fakeclock.FakeClock
+		dbClock   *sqldb.DatabaseClock
+	)
+
+	BeforeEach(func() {
+		// far from the database's time, as after a clock jump
+		hostClock = fakeclock.NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+		dbClock = sqldb.NewDatabaseClock(logger, rawDB, dbFlavor, hostClock, time.Minute)
+	})
+
+	It("tells the host's time until it is synced", func() {
+		Expect(dbClock.Now()).To(Equal(hostClock.Now()))
+	})
+
+	Context("once synced", func() {
+		BeforeEach(func() {
+			Expect(dbClock.Sync(logger)).To(Succeed())
+		})
+
+		It("tells the database's time", func() {
+			Expect(dbClock.Now()).To(BeTemporally("~", time.Now(), 5*time.Second))
+		})
+
+		It("advances with the host's clock", func() {
+			now := dbClock.Now()
+			hostClock.Increment(time.Second)
+			Expect(dbClock.Now()).To(Equal(now.Add(time.Second)))
+			Expect(dbClock.Since(now)).To(Equal(time.Second))
+		})
+
+		It("steps back to the database's time when a sync finds the database behind", func() {
+			hostClock.Increment(time.Hour)
+			now := dbClock.Now()
+
+			Expect(dbClock.Sync(logger)).To(Succeed())
+			Expect(dbClock.Now()).To(BeTemporally("~", now.Add(-time.Hour), 5*time.Second))
+			Expect(logger).To(gbytes.Say("database-clock-drifted"))
+			Expect(logger).To(gbytes.Say("database-clock-stepped-back"))
+
+			hostClock.Increment(time.Second)
+			Expect(dbClock.Now()).To(BeTemporally("~", now.Add(-time.Hour+time.Second), 5*time.Second))
+		})
+	})
+
+	Context("when alerting on skew", func() {
+		var fakeMetronClient *mfakes.FakeIngressClient
+
+		BeforeEach(func() {
+			fakeMetronClient = &mfakes.FakeIngressClient{}
+			dbClock.AlertOnSkew(fakeMetronClient, time.Minute)
+		})
+
+		It("reports how far the host's clock is from the database's", func() {
+			Expect(dbClock.Sync(logger)).To(Succeed())
+
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+			name, skew := fakeMetronClient.SendDurationArgsForCall(0)
+			Expect(name).To(Equal("DatabaseClockSkew"))
+			Expect(skew).To(BeNumerically("~", time.Since(hostClock.Now()), time.Minute))
+			Expect(logger).To(gbytes.Say("database-clock-skew-too-high"))
+		})
+	})
+})
+
+var _ = Describe("WithDatabaseNow", func() {
+	var (
+		nowDB    *sqldb.SQLDB
+		resource *models.Resource
+	)
+
+	BeforeEach(func() {
+		nowDB = sqlDB.WithDatabaseNow()
+		resource = &models.Resource{Key: "quack", Owner: "iamthelizardking", Value: "i can do anything", Type: models.LockType}
+
+		// the host's clock jumps an hour ahead of the database's
+		fakeClock.Increment(time.Hour)
+	})
+
+	It("writes expiries in the database's time", func() {
+		lock, err := nowDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Unix(0, lock.ExpiresAt)).To(BeTemporally("~", time.Now().Add(10*time.Second), 5*time.Second))
+	})
+
+	It("compares expiries with the database's time", func() {
+		_, err := nowDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		// to the host, the lock expired almost an hour ago
+		_, err = sqlDB.Fetch(logger, resource.Key)
+		Expect(err).To(Equal(models.ErrResourceNotFound))
+
+		_, err = nowDB.Fetch(logger, resource.Key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nowDB.Count(logger, models.LockType)).To(Equal(1))
+
+		expired, err := nowDB.ExpireLocks(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired).To(BeEmpty())
+	})
+})
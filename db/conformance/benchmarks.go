@@ -0,0 +1,58 @@
+package conformance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// BenchmarkHeartbeat measures refreshing a lock that is already held, which
+// every lock and presence holder does about once per ttl. backend must hold
+// no locks.
+func BenchmarkHeartbeat(b *testing.B, backend Backend) {
+	logger := lager.NewLogger("benchmark")
+	resource := &models.Resource{Key: "heartbeat", Owner: "owner", Value: "value", Type: models.PresenceType}
+
+	_, err := backend.LockDB.Lock(logger, resource, 10*time.Second)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := backend.LockDB.Lock(logger, resource, 10*time.Second)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFetchAll measures fetching every lock of a type while locks of
+// that type are held. backend must hold no locks.
+func BenchmarkFetchAll(b *testing.B, backend Backend, locks int) {
+	logger := lager.NewLogger("benchmark")
+
+	for i := 0; i < locks; i++ {
+		resource := &models.Resource{Key: fmt.Sprintf("cell-%05d", i), Owner: fmt.Sprintf("owner-%05d", i), Value: "value", Type: models.PresenceType}
+		_, err := backend.LockDB.Lock(logger, resource, time.Hour)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fetched, err := backend.LockDB.FetchAll(logger, models.PresenceType)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(fetched) != locks {
+			b.Fatalf("fetched %d locks, expected %d", len(fetched), locks)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package conformance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Backend is a LockDB under test. Clock must be the clock the LockDB reads
+// the time from.
+type Backend struct {
+	LockDB db.LockDB
+	Clock  *fakeclock.FakeClock
+}
+
+// ItBehavesLikeALockDB declares Ginkgo specs for the db.LockDB contract that
+// every backend must meet. It must be called inside a Describe or Context.
+// newBackend is called before each spec and must return a backend holding
+// no locks.
+func ItBehavesLikeALockDB(newBackend func() Backend) {
+	var (
+		logger   *lagertest.TestLogger
+		lockDB   db.LockDB
+		clock    *fakeclock.FakeClock
+		resource *models.Resource
+		ttl      time.Duration
+	)
+
+	otherOwner := func(r *models.Resource, owner string) *models.Resource {
+		return &models.Resource{Key: r.Key, Owner: owner, Value: r.Value, Type: r.Type}
+	}
+
+	BeforeEach(func() {
+		backend := newBackend()
+		lockDB, clock = backend.LockDB, backend.Clock
+		logger = lagertest.NewTestLogger("conformance")
+		resource = &models.Resource{Key: "conformance", Owner: "owner", Value: "value", Type: models.LockType}
+		ttl = 10 * time.Second
+	})
+
+	Context("Lock", func() {
+		It("acquires a free key", func() {
+			lock, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Key).To(Equal(resource.Key))
+			Expect(lock.Owner).To(Equal(resource.Owner))
+			Expect(lock.Value).To(Equal(resource.Value))
+			Expect(lock.Type).To(Equal(resource.Type))
+			Expect(lock.TtlInSeconds).To(BeEquivalentTo(10))
+			Expect(lock.ExpiresAt).To(Equal(clock.Now().Add(ttl).UnixNano()))
+			Expect(lock.ModifiedIndex).To(BeNumerically(">", 0))
+			Expect(lock.FencingToken).To(BeNumerically(">", 0))
+
+			fetched, err := lockDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.Owner).To(Equal(resource.Owner))
+			Expect(fetched.Value).To(Equal(resource.Value))
+			Expect(fetched.FencingToken).To(Equal(lock.FencingToken))
+		})
+
+		It("refreshes the lock for the same owner", func() {
+			lock, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.Increment(5 * time.Second)
+
+			refreshed, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refreshed.ModifiedIndex).To(BeNumerically(">", lock.ModifiedIndex))
+			Expect(refreshed.ModifiedId).To(Equal(lock.ModifiedId))
+			Expect(refreshed.FencingToken).To(Equal(lock.FencingToken))
+			Expect(refreshed.ExpiresAt).To(Equal(clock.Now().Add(ttl).UnixNano()))
+		})
+
+		It("returns the holder with a collision when another owner holds the key", func() {
+			_, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			holder, err := lockDB.Lock(logger, otherOwner(resource, "other"), ttl)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder).NotTo(BeNil())
+			Expect(holder.Owner).To(Equal(resource.Owner))
+		})
+
+		It("lets another owner take the key once the lock expires, with a greater fencing token", func() {
+			lock, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.Increment(ttl + time.Second)
+
+			_, err = lockDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+
+			taken, err := lockDB.Lock(logger, otherOwner(resource, "other"), ttl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(taken.Owner).To(Equal("other"))
+			Expect(taken.FencingToken).To(BeNumerically(">", lock.FencingToken))
+		})
+
+		It("grants the key to exactly one of many concurrent owners", func() {
+			const owners = 10
+
+			var wg sync.WaitGroup
+			errs := make(chan error, owners)
+			for i := 0; i < owners; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, err := lockDB.Lock(logger, otherOwner(resource, fmt.Sprintf("owner-%d", i)), ttl)
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			var granted, collided int
+			for err := range errs {
+				switch err {
+				case nil:
+					granted++
+				case models.ErrLockCollision:
+					collided++
+				default:
+					Fail(fmt.Sprintf("unexpected error: %s", err))
+				}
+			}
+			Expect(granted).To(Equal(1))
+			Expect(collided).To(Equal(owners - 1))
+		})
+	})
+
+	Context("Release", func() {
+		It("frees the key for other owners", func() {
+			lock, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(lockDB.Release(logger, resource)).To(Succeed())
+
+			_, err = lockDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+
+			taken, err := lockDB.Lock(logger, otherOwner(resource, "other"), ttl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(taken.FencingToken).To(BeNumerically(">", lock.FencingToken))
+		})
+
+		It("does not release a lock held by another owner", func() {
+			_, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = lockDB.Release(logger, otherOwner(resource, "other"))
+			Expect(err).To(Equal(models.ErrLockCollision))
+
+			_, err = lockDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails for a key that is not held", func() {
+			Expect(lockDB.Release(logger, resource)).NotTo(Succeed())
+		})
+
+		It("only releases conditionally when the condition matches", func() {
+			_, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = lockDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: "stale"})
+			Expect(err).To(Equal(models.ErrReleaseConditionFailed))
+
+			Expect(lockDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: resource.Value})).To(Succeed())
+		})
+	})
+
+	Context("LockGroup", func() {
+		It("locks every key or none of them", func() {
+			second := &models.Resource{Key: "conformance-2", Owner: "owner", Type: models.LockType}
+			_, err := lockDB.Lock(logger, otherOwner(second, "other"), ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = lockDB.LockGroup(logger, []*models.Resource{resource, second}, ttl)
+			Expect(err).To(Equal(models.ErrLockCollision))
+
+			_, err = lockDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+
+			Expect(lockDB.Release(logger, otherOwner(second, "other"))).To(Succeed())
+
+			locks, err := lockDB.LockGroup(logger, []*models.Resource{resource, second}, ttl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(2))
+			Expect(locks[0].Key).To(Equal(resource.Key))
+			Expect(locks[1].Key).To(Equal(second.Key))
+		})
+	})
+
+	Context("LockShared", func() {
+		It("shares the key between owners but not with an exclusive owner", func() {
+			_, err := lockDB.LockShared(logger, otherOwner(resource, "reader-1"), ttl)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = lockDB.LockShared(logger, otherOwner(resource, "reader-2"), ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = lockDB.Lock(logger, resource, ttl)
+			Expect(err).To(Equal(models.ErrLockCollision))
+
+			Expect(lockDB.ReleaseShared(logger, otherOwner(resource, "reader-1"))).To(Succeed())
+			Expect(lockDB.ReleaseShared(logger, otherOwner(resource, "reader-2"))).To(Succeed())
+
+			_, err = lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("FetchAll and Count", func() {
+		It("only return live locks of the given type", func() {
+			presence := &models.Resource{Key: "conformance-presence", Owner: "cell", Type: models.PresenceType}
+			_, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = lockDB.Lock(logger, presence, ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			locks, err := lockDB.FetchAll(logger, models.LockType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+			Expect(locks[0].Key).To(Equal(resource.Key))
+
+			count, err := lockDB.Count(logger, models.PresenceType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+
+			clock.Increment(ttl + time.Second)
+
+			locks, err = lockDB.FetchAll(logger, models.LockType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(BeEmpty())
+		})
+	})
+
+	Context("ExpireLocks", func() {
+		It("removes and returns only expired locks", func() {
+			live := &models.Resource{Key: "conformance-live", Owner: "owner", Type: models.LockType}
+			_, err := lockDB.Lock(logger, resource, ttl)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = lockDB.Lock(logger, live, 2*ttl)
+			Expect(err).NotTo(HaveOccurred())
+
+			clock.Increment(ttl + time.Second)
+
+			expired, err := lockDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(HaveLen(1))
+			Expect(expired[0].Key).To(Equal(resource.Key))
+
+			_, err = lockDB.Fetch(logger, live.Key)
+			Expect(err).NotTo(HaveOccurred())
+
+			expired, err = lockDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(BeEmpty())
+		})
+	})
+}
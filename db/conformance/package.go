@@ -0,0 +1 @@
+package conformance // import "code.cloudfoundry.org/locket/db/conformance"
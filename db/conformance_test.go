@@ -0,0 +1,13 @@
+package db_test
+
+import (
+	"code.cloudfoundry.org/locket/db/conformance"
+	. "github.com/onsi/ginkgo"
+)
+
+var _ = Describe("SQLDB conformance", func() {
+	conformance.ItBehavesLikeALockDB(func() conformance.Backend {
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+		return conformance.Backend{LockDB: sqlDB, Clock: fakeClock}
+	})
+})
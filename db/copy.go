@@ -0,0 +1,110 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrInconsistentCopy = errors.New("destination does not hold the copied locks")
+
+// CopyTo copies every lock, shared hold and fencing token from db into
+// destination, which may use another database flavor, and then checks that
+// destination holds what was copied. Destination must already have the lock
+// tables, see CreateLockTable. Locks held in destination on the same keys
+// are replaced.
+func (db *SQLDB) CopyTo(logger lager.Logger, destination *SQLDB) error {
+	logger = logger.Session("copy-to", lager.Data{"source-flavor": db.flavor, "destination-flavor": destination.flavor})
+	logger.Info("starting")
+	defer logger.Info("complete")
+
+	source, err := db.Dump(logger)
+	if err != nil {
+		return err
+	}
+
+	err = destination.Restore(logger, source)
+	if err != nil {
+		return err
+	}
+
+	copied, err := destination.Dump(logger)
+	if err != nil {
+		return err
+	}
+
+	differences := DiffDumps(source, copied)
+	if len(differences) > 0 {
+		logger.Error("failed-consistency-check", ErrInconsistentCopy, lager.Data{"differences": differences})
+		return ErrInconsistentCopy
+	}
+
+	return nil
+}
+
+// DiffDumps describes every lock, shared hold or fencing token in source
+// that copied does not hold the same way. Locks in source that had expired
+// by the time copied was dumped are not expected in it, and locks in copied
+// that are not in source are ignored.
+func DiffDumps(source, copied *Dump) []string {
+	differences := []string{}
+
+	locks := map[string]DumpedLock{}
+	for _, lock := range copied.Locks {
+		locks[lock.Key] = lock
+	}
+	for _, lock := range source.Locks {
+		differences = append(differences, diffLock("lock", lock, locks[lock.Key], copied.DumpedAt)...)
+	}
+
+	sharedLocks := map[string]DumpedLock{}
+	for _, lock := range copied.SharedLocks {
+		sharedLocks[lock.Key+"/"+lock.Owner] = lock
+	}
+	for _, lock := range source.SharedLocks {
+		differences = append(differences, diffLock("shared lock", lock, sharedLocks[lock.Key+"/"+lock.Owner], copied.DumpedAt)...)
+	}
+
+	for key, token := range source.FencingTokens {
+		if copied.FencingTokens[key] < token {
+			differences = append(differences, fmt.Sprintf("fencing token for %s is %d, expected at least %d", key, copied.FencingTokens[key], token))
+		}
+	}
+
+	return differences
+}
+
+func diffLock(kind string, expected, actual DumpedLock, now int64) []string {
+	if expected.ExpiresAt > 0 && expected.ExpiresAt <= now && expected.ReservedUntil <= now {
+		return nil
+	}
+
+	if actual.Key == "" {
+		return []string{fmt.Sprintf("%s %s held by %s is missing", kind, expected.Key, expected.Owner)}
+	}
+
+	var differences []string
+	different := func(field string, expectedValue, actualValue interface{}) {
+		if fmt.Sprint(expectedValue) != fmt.Sprint(actualValue) {
+			differences = append(differences, fmt.Sprintf("%s %s has %s %v, expected %v", kind, expected.Key, field, actualValue, expectedValue))
+		}
+	}
+
+	different("owner", expected.Owner, actual.Owner)
+	different("value", expected.Value, actual.Value)
+	different("type", expected.Type, actual.Type)
+	different("metadata", expected.Metadata, actual.Metadata)
+	different("modified index", expected.ModifiedIndex, actual.ModifiedIndex)
+	different("modified id", expected.ModifiedId, actual.ModifiedId)
+	different("ttl", expected.TtlInSeconds, actual.TtlInSeconds)
+	different("ttl in milliseconds", expected.TtlInMilliseconds, actual.TtlInMilliseconds)
+	different("fencing token", expected.FencingToken, actual.FencingToken)
+	if expected.ExpiresAt > 0 {
+		different("expiry", expected.ExpiresAt, actual.ExpiresAt)
+	}
+	different("acquired at", expected.AcquiredAt, actual.AcquiredAt)
+	different("reservation", expected.ReservedUntil, actual.ReservedUntil)
+
+	return differences
+}
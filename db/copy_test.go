@@ -0,0 +1,124 @@
+package db_test
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Copy", func() {
+	var resource *models.Resource
+
+	BeforeEach(func() {
+		resource = &models.Resource{Key: "quack", Owner: "iamthelizardking", Value: "i can do anything", Type: models.LockType}
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+	})
+
+	Context("CopyTo", func() {
+		var (
+			destinationName  string
+			destinationRawDB *sql.DB
+			destinationDB    *db.SQLDB
+		)
+
+		BeforeEach(func() {
+			destinationName = fmt.Sprintf("diego_copy_%d", GinkgoParallelNode())
+
+			baseDB, err := sql.Open(dbDriverName, dbBaseConnectionString)
+			Expect(err).NotTo(HaveOccurred())
+			defer baseDB.Close()
+			_, err = baseDB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", destinationName))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = baseDB.Exec(fmt.Sprintf("CREATE DATABASE %s", destinationName))
+			Expect(err).NotTo(HaveOccurred())
+
+			destinationRawDB, err = sql.Open(dbDriverName, dbBaseConnectionString+destinationName)
+			Expect(err).NotTo(HaveOccurred())
+			destinationDB = db.NewSQLDB(destinationRawDB, dbFlavor, fakeGUIDProvider, fakeClock)
+			Expect(destinationDB.CreateLockTable(logger)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(destinationRawDB.Close()).To(Succeed())
+
+			baseDB, err := sql.Open(dbDriverName, dbBaseConnectionString)
+			Expect(err).NotTo(HaveOccurred())
+			defer baseDB.Close()
+			_, err = baseDB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", destinationName))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("copies the locks, shared holds and fencing tokens", func() {
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sqlDB.LockShared(logger, &models.Resource{Key: "shared", Owner: "reader", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sqlDB.CopyTo(logger, destinationDB)).To(Succeed())
+
+			copied, err := destinationDB.Fetch(logger, "quack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(copied.Owner).To(Equal("iamthelizardking"))
+			Expect(copied.FencingToken).To(Equal(lock.FencingToken))
+			Expect(copied.ExpiresAt).To(Equal(lock.ExpiresAt))
+
+			_, err = destinationDB.Lock(logger, &models.Resource{Key: "shared", Owner: "writer", Type: models.LockType}, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+		})
+	})
+
+	Context("DiffDumps", func() {
+		var source, copied *db.Dump
+
+		BeforeEach(func() {
+			now := fakeClock.Now().UnixNano()
+			lock := db.DumpedLock{Key: "quack", Owner: "iamthelizardking", Value: "v", ModifiedIndex: 2, FencingToken: 3, ExpiresAt: now + int64(time.Minute)}
+			shared := db.DumpedLock{Key: "shared", Owner: "reader", ExpiresAt: now + int64(time.Minute)}
+
+			source = &db.Dump{
+				DumpedAt:      now,
+				Locks:         []db.DumpedLock{lock},
+				SharedLocks:   []db.DumpedLock{shared},
+				FencingTokens: map[string]int64{"quack": 3},
+			}
+			copied = &db.Dump{
+				DumpedAt:      now,
+				Locks:         []db.DumpedLock{lock, {Key: "extra", Owner: "someone"}},
+				SharedLocks:   []db.DumpedLock{shared},
+				FencingTokens: map[string]int64{"quack": 3},
+			}
+		})
+
+		It("finds no differences between matching dumps", func() {
+			Expect(db.DiffDumps(source, copied)).To(BeEmpty())
+		})
+
+		It("reports missing and different locks", func() {
+			copied.Locks[0].Value = "other"
+			copied.SharedLocks = nil
+
+			Expect(db.DiffDumps(source, copied)).To(ConsistOf(
+				"lock quack has value other, expected v",
+				"shared lock shared held by reader is missing",
+			))
+		})
+
+		It("reports fencing tokens that went backwards", func() {
+			copied.FencingTokens["quack"] = 2
+			Expect(db.DiffDumps(source, copied)).To(ConsistOf("fencing token for quack is 2, expected at least 3"))
+		})
+
+		It("does not expect locks that expired before the copy was dumped", func() {
+			copied.DumpedAt += int64(2 * time.Minute)
+			copied.Locks = nil
+			copied.SharedLocks = nil
+
+			Expect(db.DiffDumps(source, copied)).To(BeEmpty())
+		})
+	})
+})
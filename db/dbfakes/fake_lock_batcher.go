@@ -0,0 +1,86 @@
+// This file was generated by counterfeiter
+package dbfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+)
+
+type FakeLockBatcher struct {
+	LockBatchStub        func(logger lager.Logger, locks []db.BatchedLock) ([]db.BatchedLockResult, error)
+	lockBatchMutex       sync.RWMutex
+	lockBatchArgsForCall []struct {
+		logger lager.Logger
+		locks  []db.BatchedLock
+	}
+	lockBatchReturns struct {
+		result1 []db.BatchedLockResult
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeLockBatcher) LockBatch(logger lager.Logger, locks []db.BatchedLock) ([]db.BatchedLockResult, error) {
+	var locksCopy []db.BatchedLock
+	if locks != nil {
+		locksCopy = make([]db.BatchedLock, len(locks))
+		copy(locksCopy, locks)
+	}
+	fake.lockBatchMutex.Lock()
+	fake.lockBatchArgsForCall = append(fake.lockBatchArgsForCall, struct {
+		logger lager.Logger
+		locks  []db.BatchedLock
+	}{logger, locksCopy})
+	fake.recordInvocation("LockBatch", []interface{}{logger, locksCopy})
+	fake.lockBatchMutex.Unlock()
+	if fake.LockBatchStub != nil {
+		return fake.LockBatchStub(logger, locks)
+	} else {
+		return fake.lockBatchReturns.result1, fake.lockBatchReturns.result2
+	}
+}
+
+func (fake *FakeLockBatcher) LockBatchCallCount() int {
+	fake.lockBatchMutex.RLock()
+	defer fake.lockBatchMutex.RUnlock()
+	return len(fake.lockBatchArgsForCall)
+}
+
+func (fake *FakeLockBatcher) LockBatchArgsForCall(i int) (lager.Logger, []db.BatchedLock) {
+	fake.lockBatchMutex.RLock()
+	defer fake.lockBatchMutex.RUnlock()
+	return fake.lockBatchArgsForCall[i].logger, fake.lockBatchArgsForCall[i].locks
+}
+
+func (fake *FakeLockBatcher) LockBatchReturns(result1 []db.BatchedLockResult, result2 error) {
+	fake.LockBatchStub = nil
+	fake.lockBatchReturns = struct {
+		result1 []db.BatchedLockResult
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLockBatcher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.lockBatchMutex.RLock()
+	defer fake.lockBatchMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeLockBatcher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ db.LockBatcher = new(FakeLockBatcher)
@@ -3,6 +3,7 @@ package dbfakes
 
 import (
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/db"
@@ -10,12 +11,12 @@ import (
 )
 
 type FakeLockDB struct {
-	LockStub        func(logger lager.Logger, resource *models.Resource, ttl int64) (*db.Lock, error)
+	LockStub        func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error)
 	lockMutex       sync.RWMutex
 	lockArgsForCall []struct {
 		logger   lager.Logger
 		resource *models.Resource
-		ttl      int64
+		ttl      time.Duration
 	}
 	lockReturns struct {
 		result1 *db.Lock
@@ -30,6 +31,71 @@ type FakeLockDB struct {
 	releaseReturns struct {
 		result1 error
 	}
+	ReleaseIfStub        func(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error
+	releaseIfMutex       sync.RWMutex
+	releaseIfArgsForCall []struct {
+		logger    lager.Logger
+		resource  *models.Resource
+		condition db.ReleaseCondition
+	}
+	releaseIfReturns struct {
+		result1 error
+	}
+	LockGroupStub        func(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error)
+	lockGroupMutex       sync.RWMutex
+	lockGroupArgsForCall []struct {
+		logger    lager.Logger
+		resources []*models.Resource
+		ttl       time.Duration
+	}
+	lockGroupReturns struct {
+		result1 []*db.Lock
+		result2 error
+	}
+	LockSharedStub        func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error)
+	lockSharedMutex       sync.RWMutex
+	lockSharedArgsForCall []struct {
+		logger   lager.Logger
+		resource *models.Resource
+		ttl      time.Duration
+	}
+	lockSharedReturns struct {
+		result1 *db.Lock
+		result2 error
+	}
+	ReleaseSharedStub        func(logger lager.Logger, resource *models.Resource) error
+	releaseSharedMutex       sync.RWMutex
+	releaseSharedArgsForCall []struct {
+		logger   lager.Logger
+		resource *models.Resource
+	}
+	releaseSharedReturns struct {
+		result1 error
+	}
+	LockSemaphoreStub        func(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error)
+	lockSemaphoreMutex       sync.RWMutex
+	lockSemaphoreArgsForCall []struct {
+		logger   lager.Logger
+		resource *models.Resource
+		capacity int
+		ttl      time.Duration
+	}
+	lockSemaphoreReturns struct {
+		result1 *db.Lock
+		result2 error
+	}
+	LockWithGraceStub        func(logger lager.Logger, resource *models.Resource, ttl time.Duration, grace time.Duration) (*db.Lock, error)
+	lockWithGraceMutex       sync.RWMutex
+	lockWithGraceArgsForCall []struct {
+		logger   lager.Logger
+		resource *models.Resource
+		ttl      time.Duration
+		grace    time.Duration
+	}
+	lockWithGraceReturns struct {
+		result1 *db.Lock
+		result2 error
+	}
 	FetchStub        func(logger lager.Logger, key string) (*db.Lock, error)
 	fetchMutex       sync.RWMutex
 	fetchArgsForCall []struct {
@@ -40,6 +106,16 @@ type FakeLockDB struct {
 		result1 *db.Lock
 		result2 error
 	}
+	FetchIncludingExpiredStub        func(logger lager.Logger, key string) (*db.Lock, error)
+	fetchIncludingExpiredMutex       sync.RWMutex
+	fetchIncludingExpiredArgsForCall []struct {
+		logger lager.Logger
+		key    string
+	}
+	fetchIncludingExpiredReturns struct {
+		result1 *db.Lock
+		result2 error
+	}
 	FetchAllStub        func(logger lager.Logger, lockType string) ([]*db.Lock, error)
 	fetchAllMutex       sync.RWMutex
 	fetchAllArgsForCall []struct {
@@ -60,16 +136,25 @@ type FakeLockDB struct {
 		result1 int
 		result2 error
 	}
+	ExpireLocksStub        func(logger lager.Logger) ([]*db.Lock, error)
+	expireLocksMutex       sync.RWMutex
+	expireLocksArgsForCall []struct {
+		logger lager.Logger
+	}
+	expireLocksReturns struct {
+		result1 []*db.Lock
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeLockDB) Lock(logger lager.Logger, resource *models.Resource, ttl int64) (*db.Lock, error) {
+func (fake *FakeLockDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
 	fake.lockMutex.Lock()
 	fake.lockArgsForCall = append(fake.lockArgsForCall, struct {
 		logger   lager.Logger
 		resource *models.Resource
-		ttl      int64
+		ttl      time.Duration
 	}{logger, resource, ttl})
 	fake.recordInvocation("Lock", []interface{}{logger, resource, ttl})
 	fake.lockMutex.Unlock()
@@ -86,7 +171,7 @@ func (fake *FakeLockDB) LockCallCount() int {
 	return len(fake.lockArgsForCall)
 }
 
-func (fake *FakeLockDB) LockArgsForCall(i int) (lager.Logger, *models.Resource, int64) {
+func (fake *FakeLockDB) LockArgsForCall(i int) (lager.Logger, *models.Resource, time.Duration) {
 	fake.lockMutex.RLock()
 	defer fake.lockMutex.RUnlock()
 	return fake.lockArgsForCall[i].logger, fake.lockArgsForCall[i].resource, fake.lockArgsForCall[i].ttl
@@ -134,6 +219,226 @@ func (fake *FakeLockDB) ReleaseReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeLockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	fake.releaseIfMutex.Lock()
+	fake.releaseIfArgsForCall = append(fake.releaseIfArgsForCall, struct {
+		logger    lager.Logger
+		resource  *models.Resource
+		condition db.ReleaseCondition
+	}{logger, resource, condition})
+	fake.recordInvocation("ReleaseIf", []interface{}{logger, resource, condition})
+	fake.releaseIfMutex.Unlock()
+	if fake.ReleaseIfStub != nil {
+		return fake.ReleaseIfStub(logger, resource, condition)
+	} else {
+		return fake.releaseIfReturns.result1
+	}
+}
+
+func (fake *FakeLockDB) ReleaseIfCallCount() int {
+	fake.releaseIfMutex.RLock()
+	defer fake.releaseIfMutex.RUnlock()
+	return len(fake.releaseIfArgsForCall)
+}
+
+func (fake *FakeLockDB) ReleaseIfArgsForCall(i int) (lager.Logger, *models.Resource, db.ReleaseCondition) {
+	fake.releaseIfMutex.RLock()
+	defer fake.releaseIfMutex.RUnlock()
+	return fake.releaseIfArgsForCall[i].logger, fake.releaseIfArgsForCall[i].resource, fake.releaseIfArgsForCall[i].condition
+}
+
+func (fake *FakeLockDB) ReleaseIfReturns(result1 error) {
+	fake.ReleaseIfStub = nil
+	fake.releaseIfReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLockDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error) {
+	var resourcesCopy []*models.Resource
+	if resources != nil {
+		resourcesCopy = make([]*models.Resource, len(resources))
+		copy(resourcesCopy, resources)
+	}
+	fake.lockGroupMutex.Lock()
+	fake.lockGroupArgsForCall = append(fake.lockGroupArgsForCall, struct {
+		logger    lager.Logger
+		resources []*models.Resource
+		ttl       time.Duration
+	}{logger, resourcesCopy, ttl})
+	fake.recordInvocation("LockGroup", []interface{}{logger, resourcesCopy, ttl})
+	fake.lockGroupMutex.Unlock()
+	if fake.LockGroupStub != nil {
+		return fake.LockGroupStub(logger, resources, ttl)
+	} else {
+		return fake.lockGroupReturns.result1, fake.lockGroupReturns.result2
+	}
+}
+
+func (fake *FakeLockDB) LockGroupCallCount() int {
+	fake.lockGroupMutex.RLock()
+	defer fake.lockGroupMutex.RUnlock()
+	return len(fake.lockGroupArgsForCall)
+}
+
+func (fake *FakeLockDB) LockGroupArgsForCall(i int) (lager.Logger, []*models.Resource, time.Duration) {
+	fake.lockGroupMutex.RLock()
+	defer fake.lockGroupMutex.RUnlock()
+	return fake.lockGroupArgsForCall[i].logger, fake.lockGroupArgsForCall[i].resources, fake.lockGroupArgsForCall[i].ttl
+}
+
+func (fake *FakeLockDB) LockGroupReturns(result1 []*db.Lock, result2 error) {
+	fake.LockGroupStub = nil
+	fake.lockGroupReturns = struct {
+		result1 []*db.Lock
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLockDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	fake.lockSharedMutex.Lock()
+	fake.lockSharedArgsForCall = append(fake.lockSharedArgsForCall, struct {
+		logger   lager.Logger
+		resource *models.Resource
+		ttl      time.Duration
+	}{logger, resource, ttl})
+	fake.recordInvocation("LockShared", []interface{}{logger, resource, ttl})
+	fake.lockSharedMutex.Unlock()
+	if fake.LockSharedStub != nil {
+		return fake.LockSharedStub(logger, resource, ttl)
+	} else {
+		return fake.lockSharedReturns.result1, fake.lockSharedReturns.result2
+	}
+}
+
+func (fake *FakeLockDB) LockSharedCallCount() int {
+	fake.lockSharedMutex.RLock()
+	defer fake.lockSharedMutex.RUnlock()
+	return len(fake.lockSharedArgsForCall)
+}
+
+func (fake *FakeLockDB) LockSharedArgsForCall(i int) (lager.Logger, *models.Resource, time.Duration) {
+	fake.lockSharedMutex.RLock()
+	defer fake.lockSharedMutex.RUnlock()
+	return fake.lockSharedArgsForCall[i].logger, fake.lockSharedArgsForCall[i].resource, fake.lockSharedArgsForCall[i].ttl
+}
+
+func (fake *FakeLockDB) LockSharedReturns(result1 *db.Lock, result2 error) {
+	fake.LockSharedStub = nil
+	fake.lockSharedReturns = struct {
+		result1 *db.Lock
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLockDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	fake.releaseSharedMutex.Lock()
+	fake.releaseSharedArgsForCall = append(fake.releaseSharedArgsForCall, struct {
+		logger   lager.Logger
+		resource *models.Resource
+	}{logger, resource})
+	fake.recordInvocation("ReleaseShared", []interface{}{logger, resource})
+	fake.releaseSharedMutex.Unlock()
+	if fake.ReleaseSharedStub != nil {
+		return fake.ReleaseSharedStub(logger, resource)
+	} else {
+		return fake.releaseSharedReturns.result1
+	}
+}
+
+func (fake *FakeLockDB) ReleaseSharedCallCount() int {
+	fake.releaseSharedMutex.RLock()
+	defer fake.releaseSharedMutex.RUnlock()
+	return len(fake.releaseSharedArgsForCall)
+}
+
+func (fake *FakeLockDB) ReleaseSharedArgsForCall(i int) (lager.Logger, *models.Resource) {
+	fake.releaseSharedMutex.RLock()
+	defer fake.releaseSharedMutex.RUnlock()
+	return fake.releaseSharedArgsForCall[i].logger, fake.releaseSharedArgsForCall[i].resource
+}
+
+func (fake *FakeLockDB) ReleaseSharedReturns(result1 error) {
+	fake.ReleaseSharedStub = nil
+	fake.releaseSharedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLockDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	fake.lockSemaphoreMutex.Lock()
+	fake.lockSemaphoreArgsForCall = append(fake.lockSemaphoreArgsForCall, struct {
+		logger   lager.Logger
+		resource *models.Resource
+		capacity int
+		ttl      time.Duration
+	}{logger, resource, capacity, ttl})
+	fake.recordInvocation("LockSemaphore", []interface{}{logger, resource, capacity, ttl})
+	fake.lockSemaphoreMutex.Unlock()
+	if fake.LockSemaphoreStub != nil {
+		return fake.LockSemaphoreStub(logger, resource, capacity, ttl)
+	} else {
+		return fake.lockSemaphoreReturns.result1, fake.lockSemaphoreReturns.result2
+	}
+}
+
+func (fake *FakeLockDB) LockSemaphoreCallCount() int {
+	fake.lockSemaphoreMutex.RLock()
+	defer fake.lockSemaphoreMutex.RUnlock()
+	return len(fake.lockSemaphoreArgsForCall)
+}
+
+func (fake *FakeLockDB) LockSemaphoreArgsForCall(i int) (lager.Logger, *models.Resource, int, time.Duration) {
+	fake.lockSemaphoreMutex.RLock()
+	defer fake.lockSemaphoreMutex.RUnlock()
+	return fake.lockSemaphoreArgsForCall[i].logger, fake.lockSemaphoreArgsForCall[i].resource, fake.lockSemaphoreArgsForCall[i].capacity, fake.lockSemaphoreArgsForCall[i].ttl
+}
+
+func (fake *FakeLockDB) LockSemaphoreReturns(result1 *db.Lock, result2 error) {
+	fake.LockSemaphoreStub = nil
+	fake.lockSemaphoreReturns = struct {
+		result1 *db.Lock
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLockDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl time.Duration, grace time.Duration) (*db.Lock, error) {
+	fake.lockWithGraceMutex.Lock()
+	fake.lockWithGraceArgsForCall = append(fake.lockWithGraceArgsForCall, struct {
+		logger   lager.Logger
+		resource *models.Resource
+		ttl      time.Duration
+		grace    time.Duration
+	}{logger, resource, ttl, grace})
+	fake.recordInvocation("LockWithGrace", []interface{}{logger, resource, ttl, grace})
+	fake.lockWithGraceMutex.Unlock()
+	if fake.LockWithGraceStub != nil {
+		return fake.LockWithGraceStub(logger, resource, ttl, grace)
+	} else {
+		return fake.lockWithGraceReturns.result1, fake.lockWithGraceReturns.result2
+	}
+}
+
+func (fake *FakeLockDB) LockWithGraceCallCount() int {
+	fake.lockWithGraceMutex.RLock()
+	defer fake.lockWithGraceMutex.RUnlock()
+	return len(fake.lockWithGraceArgsForCall)
+}
+
+func (fake *FakeLockDB) LockWithGraceArgsForCall(i int) (lager.Logger, *models.Resource, time.Duration, time.Duration) {
+	fake.lockWithGraceMutex.RLock()
+	defer fake.lockWithGraceMutex.RUnlock()
+	return fake.lockWithGraceArgsForCall[i].logger, fake.lockWithGraceArgsForCall[i].resource, fake.lockWithGraceArgsForCall[i].ttl, fake.lockWithGraceArgsForCall[i].grace
+}
+
+func (fake *FakeLockDB) LockWithGraceReturns(result1 *db.Lock, result2 error) {
+	fake.LockWithGraceStub = nil
+	fake.lockWithGraceReturns = struct {
+		result1 *db.Lock
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLockDB) Fetch(logger lager.Logger, key string) (*db.Lock, error) {
 	fake.fetchMutex.Lock()
 	fake.fetchArgsForCall = append(fake.fetchArgsForCall, struct {
@@ -169,6 +474,41 @@ func (fake *FakeLockDB) FetchReturns(result1 *db.Lock, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeLockDB) FetchIncludingExpired(logger lager.Logger, key string) (*db.Lock, error) {
+	fake.fetchIncludingExpiredMutex.Lock()
+	fake.fetchIncludingExpiredArgsForCall = append(fake.fetchIncludingExpiredArgsForCall, struct {
+		logger lager.Logger
+		key    string
+	}{logger, key})
+	fake.recordInvocation("FetchIncludingExpired", []interface{}{logger, key})
+	fake.fetchIncludingExpiredMutex.Unlock()
+	if fake.FetchIncludingExpiredStub != nil {
+		return fake.FetchIncludingExpiredStub(logger, key)
+	} else {
+		return fake.fetchIncludingExpiredReturns.result1, fake.fetchIncludingExpiredReturns.result2
+	}
+}
+
+func (fake *FakeLockDB) FetchIncludingExpiredCallCount() int {
+	fake.fetchIncludingExpiredMutex.RLock()
+	defer fake.fetchIncludingExpiredMutex.RUnlock()
+	return len(fake.fetchIncludingExpiredArgsForCall)
+}
+
+func (fake *FakeLockDB) FetchIncludingExpiredArgsForCall(i int) (lager.Logger, string) {
+	fake.fetchIncludingExpiredMutex.RLock()
+	defer fake.fetchIncludingExpiredMutex.RUnlock()
+	return fake.fetchIncludingExpiredArgsForCall[i].logger, fake.fetchIncludingExpiredArgsForCall[i].key
+}
+
+func (fake *FakeLockDB) FetchIncludingExpiredReturns(result1 *db.Lock, result2 error) {
+	fake.FetchIncludingExpiredStub = nil
+	fake.fetchIncludingExpiredReturns = struct {
+		result1 *db.Lock
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLockDB) FetchAll(logger lager.Logger, lockType string) ([]*db.Lock, error) {
 	fake.fetchAllMutex.Lock()
 	fake.fetchAllArgsForCall = append(fake.fetchAllArgsForCall, struct {
@@ -239,6 +579,40 @@ func (fake *FakeLockDB) CountReturns(result1 int, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeLockDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	fake.expireLocksMutex.Lock()
+	fake.expireLocksArgsForCall = append(fake.expireLocksArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("ExpireLocks", []interface{}{logger})
+	fake.expireLocksMutex.Unlock()
+	if fake.ExpireLocksStub != nil {
+		return fake.ExpireLocksStub(logger)
+	} else {
+		return fake.expireLocksReturns.result1, fake.expireLocksReturns.result2
+	}
+}
+
+func (fake *FakeLockDB) ExpireLocksCallCount() int {
+	fake.expireLocksMutex.RLock()
+	defer fake.expireLocksMutex.RUnlock()
+	return len(fake.expireLocksArgsForCall)
+}
+
+func (fake *FakeLockDB) ExpireLocksArgsForCall(i int) lager.Logger {
+	fake.expireLocksMutex.RLock()
+	defer fake.expireLocksMutex.RUnlock()
+	return fake.expireLocksArgsForCall[i].logger
+}
+
+func (fake *FakeLockDB) ExpireLocksReturns(result1 []*db.Lock, result2 error) {
+	fake.ExpireLocksStub = nil
+	fake.expireLocksReturns = struct {
+		result1 []*db.Lock
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLockDB) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -246,12 +620,28 @@ func (fake *FakeLockDB) Invocations() map[string][][]interface{} {
 	defer fake.lockMutex.RUnlock()
 	fake.releaseMutex.RLock()
 	defer fake.releaseMutex.RUnlock()
+	fake.releaseIfMutex.RLock()
+	defer fake.releaseIfMutex.RUnlock()
+	fake.lockGroupMutex.RLock()
+	defer fake.lockGroupMutex.RUnlock()
+	fake.lockSharedMutex.RLock()
+	defer fake.lockSharedMutex.RUnlock()
+	fake.releaseSharedMutex.RLock()
+	defer fake.releaseSharedMutex.RUnlock()
+	fake.lockSemaphoreMutex.RLock()
+	defer fake.lockSemaphoreMutex.RUnlock()
+	fake.lockWithGraceMutex.RLock()
+	defer fake.lockWithGraceMutex.RUnlock()
 	fake.fetchMutex.RLock()
 	defer fake.fetchMutex.RUnlock()
+	fake.fetchIncludingExpiredMutex.RLock()
+	defer fake.fetchIncludingExpiredMutex.RUnlock()
 	fake.fetchAllMutex.RLock()
 	defer fake.fetchAllMutex.RUnlock()
 	fake.countMutex.RLock()
 	defer fake.countMutex.RUnlock()
+	fake.expireLocksMutex.RLock()
+	defer fake.expireLocksMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,83 @@
+// This file was generated by counterfeiter
+package dbfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+)
+
+type FakeOutbox struct {
+	DispatchEventsStub        func(logger lager.Logger, limit int, dispatch func([]db.OutboxEvent) int) (int, error)
+	dispatchEventsMutex       sync.RWMutex
+	dispatchEventsArgsForCall []struct {
+		logger   lager.Logger
+		limit    int
+		dispatch func([]db.OutboxEvent) int
+	}
+	dispatchEventsReturns struct {
+		result1 int
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeOutbox) DispatchEvents(logger lager.Logger, limit int, dispatch func([]db.OutboxEvent) int) (int, error) {
+	fake.dispatchEventsMutex.Lock()
+	fake.dispatchEventsArgsForCall = append(fake.dispatchEventsArgsForCall, struct {
+		logger   lager.Logger
+		limit    int
+		dispatch func([]db.OutboxEvent) int
+	}{logger, limit, dispatch})
+	fake.recordInvocation("DispatchEvents", []interface{}{logger, limit, dispatch})
+	fake.dispatchEventsMutex.Unlock()
+	if fake.DispatchEventsStub != nil {
+		return fake.DispatchEventsStub(logger, limit, dispatch)
+	} else {
+		return fake.dispatchEventsReturns.result1, fake.dispatchEventsReturns.result2
+	}
+}
+
+func (fake *FakeOutbox) DispatchEventsCallCount() int {
+	fake.dispatchEventsMutex.RLock()
+	defer fake.dispatchEventsMutex.RUnlock()
+	return len(fake.dispatchEventsArgsForCall)
+}
+
+func (fake *FakeOutbox) DispatchEventsArgsForCall(i int) (lager.Logger, int, func([]db.OutboxEvent) int) {
+	fake.dispatchEventsMutex.RLock()
+	defer fake.dispatchEventsMutex.RUnlock()
+	return fake.dispatchEventsArgsForCall[i].logger, fake.dispatchEventsArgsForCall[i].limit, fake.dispatchEventsArgsForCall[i].dispatch
+}
+
+func (fake *FakeOutbox) DispatchEventsReturns(result1 int, result2 error) {
+	fake.DispatchEventsStub = nil
+	fake.dispatchEventsReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeOutbox) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.dispatchEventsMutex.RLock()
+	defer fake.dispatchEventsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeOutbox) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ db.Outbox = new(FakeOutbox)
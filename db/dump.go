@@ -0,0 +1,262 @@
+package db
+
+import (
+	"database/sql"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/lager"
+)
+
+// Dump is a snapshot of the lock tables that can be restored into another
+// database. Expiry times are absolute, so a restored lock expires when the
+// original would have rather than getting a fresh ttl.
+type Dump struct {
+	DumpedAt      int64            `json:"dumped_at"`
+	Locks         []DumpedLock     `json:"locks"`
+	SharedLocks   []DumpedLock     `json:"shared_locks,omitempty"`
+	FencingTokens map[string]int64 `json:"fencing_tokens,omitempty"`
+}
+
+type DumpedLock struct {
+	Key               string            `json:"key"`
+	Owner             string            `json:"owner"`
+	Value             string            `json:"value,omitempty"`
+	Type              string            `json:"type,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	ModifiedIndex     int64             `json:"modified_index"`
+	ModifiedId        string            `json:"modified_id"`
+	TtlInSeconds      int64             `json:"ttl_in_seconds"`
+	TtlInMilliseconds int64             `json:"ttl_in_milliseconds,omitempty"`
+	FencingToken      int64             `json:"fencing_token,omitempty"`
+	ExpiresAt         int64             `json:"expires_at"`
+	AcquiredAt        int64             `json:"acquired_at,omitempty"`
+	ReservedUntil     int64             `json:"reserved_until,omitempty"`
+}
+
+// Dump reads every lock, shared hold and fencing token in a single
+// transaction.
+func (db *SQLDB) Dump(logger lager.Logger) (*Dump, error) {
+	logger = logger.Session("dump")
+	var dump *Dump
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		dump = &Dump{
+			DumpedAt:      db.clock.Now().UnixNano(),
+			Locks:         []DumpedLock{},
+			FencingTokens: map[string]int64{},
+		}
+
+		rows, err := db.helper.All(logger, tx, "locks",
+			helpers.ColumnList{"path", "owner", "value", "type", "metadata", "modified_index", "modified_id", "ttl", "ttl_in_milliseconds", "fencing_token", "expires_at", "acquired_at", "reserved_until"},
+			helpers.NoLockRow, "owner <> ?", "",
+		)
+		if err != nil {
+			logger.Error("failed-to-fetch-locks", err)
+			return err
+		}
+		for rows.Next() {
+			var lock DumpedLock
+			var metadata string
+			err := rows.Scan(&lock.Key, &lock.Owner, &lock.Value, &lock.Type, &metadata, &lock.ModifiedIndex, &lock.ModifiedId,
+				&lock.TtlInSeconds, &lock.TtlInMilliseconds, &lock.FencingToken, &lock.ExpiresAt, &lock.AcquiredAt, &lock.ReservedUntil)
+			if err != nil {
+				rows.Close()
+				logger.Error("failed-to-scan-lock", err)
+				return err
+			}
+			lock.Metadata = decodeMetadata(logger, metadata)
+			dump.Locks = append(dump.Locks, lock)
+		}
+		rows.Close()
+
+		rows, err = db.helper.All(logger, tx, "shared_locks", sharedLockColumns, helpers.NoLockRow, "")
+		if err != nil {
+			logger.Error("failed-to-fetch-shared-locks", err)
+			return err
+		}
+		for rows.Next() {
+			lock, err := db.scanSharedLock(logger, rows)
+			if err != nil {
+				rows.Close()
+				logger.Error("failed-to-scan-shared-lock", err)
+				return err
+			}
+			dump.SharedLocks = append(dump.SharedLocks, DumpedLock{
+				Key:               lock.Key,
+				Owner:             lock.Owner,
+				Value:             lock.Value,
+				Type:              lock.Type,
+				Metadata:          lock.Metadata,
+				ModifiedIndex:     lock.ModifiedIndex,
+				ModifiedId:        lock.ModifiedId,
+				TtlInSeconds:      lock.TtlInSeconds,
+				TtlInMilliseconds: lock.TtlInMilliseconds,
+				ExpiresAt:         lock.ExpiresAt,
+			})
+		}
+		rows.Close()
+
+		rows, err = db.helper.All(logger, tx, "fencing_tokens", helpers.ColumnList{"path", "token"}, helpers.NoLockRow, "token > 0")
+		if err != nil {
+			logger.Error("failed-to-fetch-fencing-tokens", err)
+			return err
+		}
+		for rows.Next() {
+			var key string
+			var token int64
+			err := rows.Scan(&key, &token)
+			if err != nil {
+				rows.Close()
+				logger.Error("failed-to-scan-fencing-token", err)
+				return err
+			}
+			dump.FencingTokens[key] = token
+		}
+		rows.Close()
+
+		return nil
+	})
+	if err != nil {
+		return nil, db.helper.ConvertSQLError(err)
+	}
+
+	logger.Info("dumped", lager.Data{"locks": len(dump.Locks), "shared-locks": len(dump.SharedLocks)})
+	return dump, nil
+}
+
+// Restore writes the locks and shared holds in dump, replacing any held on
+// the same keys, in a single transaction. Locks that have expired since the
+// dump was taken are skipped, and locks without a stored expiry get their
+// full ttl from now. Fencing tokens never go backwards, so holders that
+// took a lock after the dump still have the greater token.
+func (db *SQLDB) Restore(logger lager.Logger, dump *Dump) error {
+	logger = logger.Session("restore", lager.Data{"dumped-at": dump.DumpedAt})
+	var restored, skipped int
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		restored, skipped = 0, 0
+		now := db.clock.Now().UnixNano()
+
+		for key, token := range dump.FencingTokens {
+			err := db.restoreFencingToken(logger, tx, key, token)
+			if err != nil {
+				logger.Error("failed-to-restore-fencing-token", err, lager.Data{"key": key})
+				return err
+			}
+		}
+
+		for _, lock := range dump.Locks {
+			lock.ExpiresAt = restoredExpiresAt(lock, now)
+			if lock.ExpiresAt <= now && lock.ReservedUntil <= now {
+				skipped++
+				continue
+			}
+
+			err := db.restoreLock(logger, tx, "locks", &lock, helpers.SQLAttributes{
+				"fencing_token":  lock.FencingToken,
+				"acquired_at":    lock.AcquiredAt,
+				"reserved_until": lock.ReservedUntil,
+			}, "path = ?", lock.Key)
+			if err != nil {
+				logger.Error("failed-to-restore-lock", err, lager.Data{"key": lock.Key})
+				return err
+			}
+			restored++
+		}
+
+		for _, lock := range dump.SharedLocks {
+			lock.ExpiresAt = restoredExpiresAt(lock, now)
+			if lock.ExpiresAt <= now && lock.ReservedUntil <= now {
+				skipped++
+				continue
+			}
+
+			err := db.restoreLock(logger, tx, "shared_locks", &lock, helpers.SQLAttributes{},
+				"path = ? AND owner = ?", lock.Key, lock.Owner)
+			if err != nil {
+				logger.Error("failed-to-restore-shared-lock", err, lager.Data{"key": lock.Key, "owner": lock.Owner})
+				return err
+			}
+			restored++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return db.helper.ConvertSQLError(err)
+	}
+
+	logger.Info("restored", lager.Data{"restored": restored, "skipped-expired": skipped})
+	return nil
+}
+
+// restoredExpiresAt returns when a restored lock expires. Locks dumped
+// without a stored expiry get their full ttl from now.
+func restoredExpiresAt(lock DumpedLock, now int64) int64 {
+	if lock.ExpiresAt > 0 {
+		return lock.ExpiresAt
+	}
+	ttl := (&Lock{TtlInSeconds: lock.TtlInSeconds, TtlInMilliseconds: lock.TtlInMilliseconds}).TTL()
+	return now + int64(ttl)
+}
+
+func (db *SQLDB) restoreLock(logger lager.Logger, tx *sql.Tx, table string, lock *DumpedLock, extra helpers.SQLAttributes, wheres string, whereBindings ...interface{}) error {
+	metadata, err := encodeMetadata(lock.Metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.helper.Delete(logger, tx, table, wheres, whereBindings...)
+	if err != nil {
+		return err
+	}
+
+	attributes := helpers.SQLAttributes{
+		"path":                lock.Key,
+		"owner":               lock.Owner,
+		"value":               lock.Value,
+		"type":                lock.Type,
+		"metadata":            metadata,
+		"modified_index":      lock.ModifiedIndex,
+		"modified_id":         lock.ModifiedId,
+		"ttl":                 lock.TtlInSeconds,
+		"ttl_in_milliseconds": lock.TtlInMilliseconds,
+		"expires_at":          lock.ExpiresAt,
+	}
+	for column, value := range extra {
+		attributes[column] = value
+	}
+
+	_, err = db.helper.Insert(logger, tx, table, attributes)
+	return err
+}
+
+func (db *SQLDB) restoreFencingToken(logger lager.Logger, tx *sql.Tx, key string, token int64) error {
+	row := db.helper.One(logger, tx, "fencing_tokens",
+		helpers.ColumnList{"token"},
+		helpers.LockRow,
+		"path = ?", key,
+	)
+
+	var existing int64
+	err := row.Scan(&existing)
+	if err != nil {
+		if db.helper.ConvertSQLError(err) != helpers.ErrResourceNotFound {
+			return err
+		}
+		_, err = db.helper.Insert(logger, tx, "fencing_tokens",
+			helpers.SQLAttributes{"path": key, "token": token},
+		)
+		return err
+	}
+
+	if existing >= token {
+		return nil
+	}
+
+	_, err = db.helper.Update(logger, tx, "fencing_tokens",
+		helpers.SQLAttributes{"token": token},
+		"path = ?", key,
+	)
+	return err
+}
@@ -0,0 +1,105 @@
+package db_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dump", func() {
+	var resource, reader *models.Resource
+
+	BeforeEach(func() {
+		resource = &models.Resource{Key: "quack", Owner: "iamthelizardking", Value: "i can do anything", Type: models.LockType}
+		reader = &models.Resource{Key: "shared", Owner: "reader-1", Value: "r", Type: models.LockType}
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+	})
+
+	Context("Dump", func() {
+		It("includes locks, shared holds and fencing tokens", func() {
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			dump, err := sqlDB.Dump(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dump.DumpedAt).To(Equal(fakeClock.Now().UnixNano()))
+			Expect(dump.Locks).To(HaveLen(1))
+			Expect(dump.Locks[0].Key).To(Equal("quack"))
+			Expect(dump.Locks[0].Owner).To(Equal("iamthelizardking"))
+			Expect(dump.Locks[0].Value).To(Equal("i can do anything"))
+			Expect(dump.Locks[0].FencingToken).To(Equal(lock.FencingToken))
+			Expect(dump.Locks[0].ExpiresAt).To(Equal(lock.ExpiresAt))
+
+			Expect(dump.SharedLocks).To(HaveLen(1))
+			Expect(dump.SharedLocks[0].Key).To(Equal("shared"))
+			Expect(dump.SharedLocks[0].Owner).To(Equal("reader-1"))
+
+			Expect(dump.FencingTokens).To(HaveKeyWithValue("quack", lock.FencingToken))
+		})
+	})
+
+	Context("Restore", func() {
+		var dump *db.Dump
+
+		BeforeEach(func() {
+			_, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sqlDB.LockShared(logger, reader, 20*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			dump, err = sqlDB.Dump(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			truncateTables(rawDB)
+		})
+
+		It("recreates the locks with their original expiry", func() {
+			fakeClock.Increment(5 * time.Second)
+
+			Expect(sqlDB.Restore(logger, dump)).To(Succeed())
+
+			lock, err := sqlDB.Fetch(logger, "quack")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Owner).To(Equal("iamthelizardking"))
+			Expect(lock.ExpiresAt).To(Equal(dump.Locks[0].ExpiresAt))
+			Expect(lock.FencingToken).To(Equal(dump.Locks[0].FencingToken))
+
+			_, err = sqlDB.LockShared(logger, &models.Resource{Key: "shared", Owner: "reader-2", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sqlDB.Lock(logger, &models.Resource{Key: "shared", Owner: "writer", Type: models.LockType}, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+		})
+
+		It("skips locks that expired since the dump", func() {
+			fakeClock.Increment(15 * time.Second)
+
+			Expect(sqlDB.Restore(logger, dump)).To(Succeed())
+
+			_, err := sqlDB.Fetch(logger, "quack")
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+
+		It("does not move fencing tokens backwards", func() {
+			var tokens []int64
+			for i := 0; i < 3; i++ {
+				lock, err := sqlDB.Lock(logger, &models.Resource{Key: "quack", Owner: "someone-else", Type: models.LockType}, 10*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+				tokens = append(tokens, lock.FencingToken)
+				Expect(sqlDB.Release(logger, &models.Resource{Key: "quack", Owner: "someone-else"})).To(Succeed())
+			}
+
+			Expect(sqlDB.Restore(logger, dump)).To(Succeed())
+
+			Expect(sqlDB.Release(logger, resource)).To(Succeed())
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.FencingToken).To(BeNumerically(">", tokens[len(tokens)-1]))
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/tracing"
+)
+
+// MaxGroupCommitBatch is the most Lock calls committed together. A batch
+// that fills up is committed without waiting for the rest of its window.
+const MaxGroupCommitBatch = 500
+
+// BatchedLock is one Lock call in a LockBatch.
+type BatchedLock struct {
+	Resource *models.Resource
+	TTL      time.Duration
+}
+
+// BatchedLockResult is what Lock would have returned for one BatchedLock.
+type BatchedLockResult struct {
+	Lock *Lock
+	Err  error
+}
+
+//go:generate counterfeiter . LockBatcher
+type LockBatcher interface {
+	// LockBatch takes or refreshes many independent locks in one
+	// transaction, returning a result for each in the same order.
+	LockBatch(logger lager.Logger, locks []BatchedLock) ([]BatchedLockResult, error)
+}
+
+type lockBatch struct {
+	logger  lager.Logger
+	locks   []BatchedLock
+	results []BatchedLockResult
+	err     error
+	full    chan struct{}
+	done    chan struct{}
+}
+
+type groupCommitDB struct {
+	LockDB
+	batcher LockBatcher
+	clock   clock.Clock
+	window  time.Duration
+
+	mutex   sync.Mutex
+	pending *lockBatch
+}
+
+// NewGroupCommitDB wraps lockDB so that Lock calls arriving within window of
+// each other are committed together by batcher, which is usually the same
+// database. Heartbeats from many cells then cost one commit per window
+// instead of one each, in exchange for adding up to window to every Lock.
+// Other operations, including LockWithGrace, go straight to lockDB. A batch
+// is shared by the requests in it, so it is not rolled back when one of them
+// is canceled, and each waits for the batch to tell its client the outcome.
+func NewGroupCommitDB(lockDB LockDB, batcher LockBatcher, clock clock.Clock, window time.Duration) LockDB {
+	return &groupCommitDB{
+		LockDB:  lockDB,
+		batcher: batcher,
+		clock:   clock,
+		window:  window,
+	}
+}
+
+func (db *groupCommitDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error) {
+	db.mutex.Lock()
+	batch := db.pending
+	if batch == nil {
+		batch = &lockBatch{
+			logger: tracing.WithoutCancel(logger),
+			full:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		db.pending = batch
+		go db.commitAfterWindow(batch)
+	}
+
+	i := len(batch.locks)
+	batch.locks = append(batch.locks, BatchedLock{Resource: resource, TTL: ttl})
+	if len(batch.locks) == MaxGroupCommitBatch {
+		db.pending = nil
+		close(batch.full)
+	}
+	db.mutex.Unlock()
+
+	<-batch.done
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	return batch.results[i].Lock, batch.results[i].Err
+}
+
+func (db *groupCommitDB) commitAfterWindow(batch *lockBatch) {
+	timer := db.clock.NewTimer(db.window)
+	select {
+	case <-timer.C():
+	case <-batch.full:
+		timer.Stop()
+	}
+
+	db.mutex.Lock()
+	if db.pending == batch {
+		db.pending = nil
+	}
+	db.mutex.Unlock()
+
+	logger := batch.logger.Session("group-commit", lager.Data{"size": len(batch.locks)})
+	batch.results, batch.err = db.batcher.LockBatch(logger, batch.locks)
+	close(batch.done)
+}
@@ -0,0 +1,129 @@
+package db_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GroupCommitDB", func() {
+	const window = 10 * time.Millisecond
+
+	type lockResult struct {
+		lock *db.Lock
+		err  error
+	}
+
+	var (
+		fakeLockDB    *dbfakes.FakeLockDB
+		fakeBatcher   *dbfakes.FakeLockBatcher
+		groupClock    *fakeclock.FakeClock
+		groupLogger   *lagertest.TestLogger
+		groupCommitDB db.LockDB
+	)
+
+	BeforeEach(func() {
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		fakeBatcher = &dbfakes.FakeLockBatcher{}
+		fakeBatcher.LockBatchStub = func(logger lager.Logger, locks []db.BatchedLock) ([]db.BatchedLockResult, error) {
+			results := make([]db.BatchedLockResult, len(locks))
+			for i, lock := range locks {
+				if lock.Resource.Owner == "someone-else" {
+					results[i] = db.BatchedLockResult{Lock: &db.Lock{Resource: &models.Resource{Key: lock.Resource.Key, Owner: "jim"}}, Err: models.ErrLockCollision}
+					continue
+				}
+				results[i] = db.BatchedLockResult{Lock: &db.Lock{Resource: lock.Resource}}
+			}
+			return results, nil
+		}
+		groupClock = fakeclock.NewFakeClock(time.Now())
+		groupLogger = lagertest.NewTestLogger("group-commit")
+		groupCommitDB = db.NewGroupCommitDB(fakeLockDB, fakeBatcher, groupClock, window)
+	})
+
+	lockAsync := func(resource *models.Resource) <-chan lockResult {
+		results := make(chan lockResult, 1)
+		go func() {
+			lock, err := groupCommitDB.Lock(groupLogger, resource, 15*time.Second)
+			results <- lockResult{lock: lock, err: err}
+		}()
+		return results
+	}
+
+	It("commits the locks that arrive within the window together", func() {
+		first := lockAsync(&models.Resource{Key: "cell-1", Owner: "rep-1"})
+		groupClock.WaitForWatcherAndIncrement(0)
+		second := lockAsync(&models.Resource{Key: "cell-2", Owner: "someone-else"})
+
+		Consistently(first).ShouldNot(Receive())
+		Expect(fakeBatcher.LockBatchCallCount()).To(Equal(0))
+
+		groupClock.Increment(window)
+
+		var result lockResult
+		Eventually(first).Should(Receive(&result))
+		Expect(result.err).NotTo(HaveOccurred())
+		Expect(result.lock.Key).To(Equal("cell-1"))
+
+		Eventually(second).Should(Receive(&result))
+		Expect(result.err).To(Equal(models.ErrLockCollision))
+		Expect(result.lock.Owner).To(Equal("jim"))
+
+		Expect(fakeBatcher.LockBatchCallCount()).To(Equal(1))
+		_, locks := fakeBatcher.LockBatchArgsForCall(0)
+		Expect(locks).To(HaveLen(2))
+		Expect(locks[0].TTL).To(Equal(15 * time.Second))
+	})
+
+	It("starts a new batch for locks arriving after a commit", func() {
+		first := lockAsync(&models.Resource{Key: "cell-1", Owner: "rep-1"})
+		groupClock.WaitForWatcherAndIncrement(window)
+		Eventually(first).Should(Receive())
+
+		second := lockAsync(&models.Resource{Key: "cell-1", Owner: "rep-1"})
+		groupClock.WaitForWatcherAndIncrement(window)
+		Eventually(second).Should(Receive())
+
+		Expect(fakeBatcher.LockBatchCallCount()).To(Equal(2))
+	})
+
+	It("commits a full batch without waiting for the window", func() {
+		results := make([]<-chan lockResult, db.MaxGroupCommitBatch)
+		for i := range results {
+			results[i] = lockAsync(&models.Resource{Key: "cell", Owner: "rep"})
+		}
+
+		for _, result := range results {
+			Eventually(result).Should(Receive())
+		}
+		Expect(fakeBatcher.LockBatchCallCount()).To(Equal(1))
+	})
+
+	It("fails every lock in the batch when the commit fails", func() {
+		fakeBatcher.LockBatchStub = nil
+		fakeBatcher.LockBatchReturns(nil, errors.New("boom"))
+
+		first := lockAsync(&models.Resource{Key: "cell-1", Owner: "rep-1"})
+		groupClock.WaitForWatcherAndIncrement(window)
+
+		var result lockResult
+		Eventually(first).Should(Receive(&result))
+		Expect(result.err).To(MatchError("boom"))
+		Expect(result.lock).To(BeNil())
+	})
+
+	It("does not batch other operations", func() {
+		_, err := groupCommitDB.LockWithGrace(groupLogger, &models.Resource{Key: "cell-1"}, 15*time.Second, time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeLockDB.LockWithGraceCallCount()).To(Equal(1))
+		Expect(fakeBatcher.LockBatchCallCount()).To(Equal(0))
+	})
+})
@@ -2,10 +2,15 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
 
 	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/tracing"
 )
 
 func lagerDataFromLock(resource *models.Resource) lager.Data {
@@ -17,100 +22,390 @@ func lagerDataFromLock(resource *models.Resource) lager.Data {
 	}
 }
 
-func (db *SQLDB) Lock(logger lager.Logger, resource *models.Resource, ttl int64) (*Lock, error) {
+func (db *SQLDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error) {
 	logger = logger.Session("lock", lagerDataFromLock(resource))
 	var lock *Lock
 
-	err := db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
-		newLock := false
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		var err error
+		lock, err = db.lockInTx(logger, tx, resource, ttl, 0)
+		return err
+	})
 
-		res, index, id, _, err := db.fetchLock(logger, tx, resource.Key)
-		if err != nil {
-			sqlErr := db.helper.ConvertSQLError(err)
-			if sqlErr != helpers.ErrResourceNotFound {
-				logger.Error("failed-to-fetch-lock", err)
+	return lock, db.helper.ConvertSQLError(err)
+}
+
+func (db *SQLDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*Lock, error) {
+	logger = logger.Session("lock-with-grace", lagerDataFromLock(resource))
+	var lock *Lock
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		var err error
+		lock, err = db.lockInTx(logger, tx, resource, ttl, grace)
+		return err
+	})
+
+	return lock, db.helper.ConvertSQLError(err)
+}
+
+// LockGroup locks every resource in a single transaction, visiting the keys
+// in sorted order so that concurrent groups cannot deadlock. Either every
+// lock is granted or none is. On a collision it returns the holder of the
+// first key that could not be locked.
+func (db *SQLDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*Lock, error) {
+	logger = logger.Session("lock-group", lager.Data{"size": len(resources)})
+	locks := make([]*Lock, len(resources))
+	var holder *Lock
+
+	order := make([]int, len(resources))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return resources[order[i]].Key < resources[order[j]].Key
+	})
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		holder = nil
+		for _, i := range order {
+			lock, err := db.lockInTx(logger.Session("lock", lagerDataFromLock(resources[i])), tx, resources[i], ttl, 0)
+			if err != nil {
+				if err == models.ErrLockCollision {
+					holder = lock
+				}
 				return err
 			}
-			newLock = true
-		} else if res.Owner != resource.Owner && res.Owner != "" {
-			logger.Debug("lock-already-exists")
-			return models.ErrLockCollision
+			locks[i] = lock
 		}
+		return nil
+	})
 
-		index++
+	if err != nil {
+		if holder != nil {
+			return []*Lock{holder}, db.helper.ConvertSQLError(err)
+		}
+		return nil, db.helper.ConvertSQLError(err)
+	}
+	return locks, nil
+}
 
-		modifiedId := id
-		if modifiedId == "" {
-			modifiedId, err = db.guidProvider.NextGUID()
-			if err != nil {
-				logger.Error("failed-to-generate-guid", err)
+// LockBatch takes or refreshes each lock as Lock would, but in a single
+// transaction, visiting the keys in sorted order so that concurrent batches
+// cannot deadlock. Each lock succeeds or collides on its own. The returned
+// error is only set when the transaction itself fails, in which case none of
+// the locks were taken.
+func (db *SQLDB) LockBatch(logger lager.Logger, locks []BatchedLock) ([]BatchedLockResult, error) {
+	logger = logger.Session("lock-batch", lager.Data{"size": len(locks)})
+	results := make([]BatchedLockResult, len(locks))
+
+	order := make([]int, len(locks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return locks[order[i]].Resource.Key < locks[order[j]].Resource.Key
+	})
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		for _, i := range order {
+			resource := locks[i].Resource
+			lock, err := db.lockInTx(logger.Session("lock", lagerDataFromLock(resource)), tx, resource, locks[i].TTL, 0)
+			if err != nil && err != models.ErrLockCollision && err != models.ErrMaxHoldExceeded {
 				return err
 			}
+			results[i] = BatchedLockResult{Lock: lock, Err: err}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, db.helper.ConvertSQLError(err)
+	}
+	return results, nil
+}
+
+// WithMaxHold returns a copy of the SQLDB that refuses to let an owner keep
+// refreshing a lock once it has held it for the maximum hold time of its key.
+// maxHolds overrides defaultMaxHold for individual keys, and a maximum hold
+// time of 0 does not limit the key.
+func (db *SQLDB) WithMaxHold(defaultMaxHold time.Duration, maxHolds map[string]time.Duration) *SQLDB {
+	withMaxHold := *db
+	withMaxHold.defaultMaxHold = defaultMaxHold
+	withMaxHold.maxHolds = maxHolds
+	return &withMaxHold
+}
+
+// checkMaxHold returns ErrMaxHoldExceeded when the owner of resource has held
+// existing for longer than the maximum hold time of its key. It only looks at
+// the row lockInTx already read, so it costs no extra query.
+func (db *SQLDB) checkMaxHold(logger lager.Logger, existing *Lock, resource *models.Resource) error {
+	maxHold, ok := db.maxHolds[resource.Key]
+	if !ok {
+		maxHold = db.defaultMaxHold
+	}
+	if maxHold <= 0 || models.GetType(resource) != models.LockType {
+		return nil
+	}
+
+	if existing.Owner != resource.Owner || existing.AcquiredAt == 0 || db.expired(existing.ExpiresAt) {
+		return nil
+	}
+
+	held := db.clock.Now().Sub(time.Unix(0, existing.AcquiredAt))
+	if held < maxHold {
+		return nil
+	}
 
-		lock = &Lock{
-			Resource:      models.GetResource(resource),
-			ModifiedIndex: index,
-			ModifiedId:    modifiedId,
-			TtlInSeconds:  ttl,
+	logger.Info("max-hold-exceeded", lager.Data{
+		"held":     held.String(),
+		"max-hold": maxHold.String(),
+	})
+	return models.ErrMaxHoldExceeded
+}
+
+// lockInTx acquires or refreshes the lock on resource within tx. A grace
+// greater than 0 reserves the lock for its owner for that long after it
+// expires.
+func (db *SQLDB) lockInTx(logger lager.Logger, tx *sql.Tx, resource *models.Resource, ttl, grace time.Duration) (*Lock, error) {
+	newLock := false
+
+	var index, fencingToken int64
+	var id string
+	acquiredAt := db.clock.Now().UnixNano()
+	// acquired is whether the owner takes the lock, rather than refreshes it
+	acquired := true
+
+	existing, err := db.fetchGuardedLock(logger, tx, resource.Key)
+	if err != nil {
+		sqlErr := db.helper.ConvertSQLError(err)
+		if sqlErr != helpers.ErrResourceNotFound {
+			logger.Error("failed-to-fetch-lock", err)
+			return nil, err
+		}
+		newLock = true
+	} else {
+		err = db.checkMaxHold(logger, existing, resource)
+		if err != nil {
+			return nil, err
 		}
 
-		if newLock {
-			_, err = db.helper.Insert(logger, tx, "locks",
-				helpers.SQLAttributes{
-					"path":           lock.Key,
-					"owner":          lock.Owner,
-					"value":          lock.Value,
-					"type":           lock.Type,
-					"modified_index": lock.ModifiedIndex,
-					"modified_id":    lock.ModifiedId,
-					"ttl":            lock.TtlInSeconds,
-				},
-			)
-		} else {
-			_, err = db.helper.Update(logger, tx, "locks",
-				helpers.SQLAttributes{
-					"owner":          lock.Owner,
-					"value":          lock.Value,
-					"type":           lock.Type,
-					"modified_index": lock.ModifiedIndex,
-					"modified_id":    lock.ModifiedId,
-					"ttl":            lock.TtlInSeconds,
-				},
-				"path = ?", lock.Key,
-			)
+		if grace == 0 && db.refreshable(existing, resource, ttl) {
+			return db.refreshInTx(logger, tx, existing, resource, ttl)
 		}
 
+		index, id, fencingToken = existing.ModifiedIndex, existing.ModifiedId, existing.FencingToken
+		if existing.Owner != resource.Owner && existing.Owner != "" {
+			if !db.expired(existing.ExpiresAt) {
+				logger.Debug("lock-already-exists")
+				return existing, models.ErrLockCollision
+			}
+			if db.reserved(existing.ReservedUntil) {
+				logger.Debug("lock-reserved-for-previous-owner", lager.Data{"previous-owner": existing.Owner})
+				return existing, models.ErrLockCollision
+			}
+			logger.Info("taking-over-expired-lock", lager.Data{"previous-owner": existing.Owner})
+			id = ""
+		}
+		if existing.Owner != resource.Owner {
+			fencingToken = 0
+		} else if existing.AcquiredAt > 0 && !db.expired(existing.ExpiresAt) {
+			acquiredAt = existing.AcquiredAt
+			acquired = false
+		}
+	}
+
+	err = db.deleteExpiredSharedLocks(logger, tx, resource.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	holder, err := db.fetchOtherSharedHolder(logger, tx, resource.Key, resource.Owner)
+	if err != nil {
+		logger.Error("failed-to-fetch-shared-locks", err)
+		return nil, err
+	}
+	if holder != nil {
+		logger.Debug("lock-held-shared")
+		return holder, models.ErrLockCollision
+	}
+
+	index++
+
+	if fencingToken == 0 {
+		fencingToken, err = db.nextFencingToken(logger, tx, resource.Key)
 		if err != nil {
-			logger.Error("failed-updating-lock", err)
-			return err
+			logger.Error("failed-to-increment-fencing-token", err)
+			return nil, err
 		}
+	}
 
-		if newLock {
-			logger.Info("acquired-lock")
+	modifiedId := id
+	if modifiedId == "" {
+		modifiedId, err = db.guidProvider.NextGUID()
+		if err != nil {
+			logger.Error("failed-to-generate-guid", err)
+			return nil, err
 		}
+	}
 
-		return nil
-	})
+	metadata, err := encodeMetadata(resource.Metadata)
+	if err != nil {
+		logger.Error("failed-to-encode-metadata", err)
+		return nil, err
+	}
 
-	return lock, db.helper.ConvertSQLError(err)
+	ttlInSeconds, ttlInMilliseconds := NewTTL(ttl)
+	lock := &Lock{
+		Resource:          models.GetResource(resource),
+		ModifiedIndex:     index,
+		ModifiedId:        modifiedId,
+		TtlInSeconds:      ttlInSeconds,
+		TtlInMilliseconds: ttlInMilliseconds,
+		FencingToken:      fencingToken,
+		ExpiresAt:         db.expiresAt(ttl),
+		AcquiredAt:        acquiredAt,
+	}
+	if grace > 0 {
+		lock.ReservedUntil = lock.ExpiresAt + int64(grace)
+	}
+
+	if newLock {
+		_, err = db.helper.Insert(logger, tx, "locks",
+			helpers.SQLAttributes{
+				"path":                lock.Key,
+				"owner":               lock.Owner,
+				"value":               lock.Value,
+				"type":                lock.Type,
+				"metadata":            metadata,
+				"modified_index":      lock.ModifiedIndex,
+				"modified_id":         lock.ModifiedId,
+				"ttl":                 lock.TtlInSeconds,
+				"ttl_in_milliseconds": lock.TtlInMilliseconds,
+				"fencing_token":       lock.FencingToken,
+				"expires_at":          lock.ExpiresAt,
+				"acquired_at":         lock.AcquiredAt,
+				"reserved_until":      lock.ReservedUntil,
+			},
+		)
+	} else {
+		_, err = db.helper.Update(logger, tx, "locks",
+			helpers.SQLAttributes{
+				"owner":               lock.Owner,
+				"value":               lock.Value,
+				"type":                lock.Type,
+				"metadata":            metadata,
+				"modified_index":      lock.ModifiedIndex,
+				"modified_id":         lock.ModifiedId,
+				"ttl":                 lock.TtlInSeconds,
+				"ttl_in_milliseconds": lock.TtlInMilliseconds,
+				"fencing_token":       lock.FencingToken,
+				"expires_at":          lock.ExpiresAt,
+				"acquired_at":         lock.AcquiredAt,
+				"reserved_until":      lock.ReservedUntil,
+			},
+			"path = ?", lock.Key,
+		)
+	}
+
+	if err != nil {
+		logger.Error("failed-updating-lock", err)
+		return nil, err
+	}
+
+	if newLock {
+		logger.Info("acquired-lock")
+	}
+
+	if acquired {
+		err = db.recordEvent(logger, tx, LockCreatedEvent, lock)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lock, nil
+}
+
+// refreshable reports whether existing is a live lock held by resource's
+// owner with the same value, type, metadata and ttl, so that refreshing it
+// only has to extend its expiry. Locks with a grace window, or written by
+// older versions of locket, go through the full path.
+func (db *SQLDB) refreshable(existing *Lock, resource *models.Resource, ttl time.Duration) bool {
+	if existing.Owner != resource.Owner || existing.Owner == "" {
+		return false
+	}
+	if existing.ExpiresAt == 0 || db.expired(existing.ExpiresAt) || existing.ReservedUntil != 0 {
+		return false
+	}
+	if existing.AcquiredAt == 0 || existing.FencingToken == 0 || existing.ModifiedId == "" {
+		return false
+	}
+
+	ttlInSeconds, ttlInMilliseconds := NewTTL(ttl)
+	if existing.TtlInSeconds != ttlInSeconds || existing.TtlInMilliseconds != ttlInMilliseconds {
+		return false
+	}
+
+	requested := models.GetResource(resource)
+	if existing.Value != requested.Value || existing.Type != requested.Type {
+		return false
+	}
+	if len(existing.Metadata) != 0 || len(requested.Metadata) != 0 {
+		return reflect.DeepEqual(existing.Metadata, requested.Metadata)
+	}
+	return true
+}
+
+// refreshInTx extends the expiry of existing, which refreshable has
+// accepted, updating only its expires_at and modified_index columns.
+func (db *SQLDB) refreshInTx(logger lager.Logger, tx *sql.Tx, existing *Lock, resource *models.Resource, ttl time.Duration) (*Lock, error) {
+	lock := *existing
+	lock.Resource = models.GetResource(resource)
+	lock.ModifiedIndex++
+	lock.ExpiresAt = db.expiresAt(ttl)
+
+	_, err := db.helper.Update(logger, tx, "locks",
+		helpers.SQLAttributes{
+			"modified_index": lock.ModifiedIndex,
+			"expires_at":     lock.ExpiresAt,
+		},
+		"path = ?", lock.Key,
+	)
+	if err != nil {
+		logger.Error("failed-refreshing-lock", err)
+		return nil, err
+	}
+
+	return &lock, nil
 }
 
 func (db *SQLDB) Release(logger lager.Logger, resource *models.Resource) error {
+	return db.ReleaseIf(logger, resource, ReleaseCondition{})
+}
+
+func (db *SQLDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition ReleaseCondition) error {
 	logger = logger.Session("release-lock", lagerDataFromLock(resource))
 
-	err := db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
-		res, _, _, _, err := db.fetchLock(logger, tx, resource.Key)
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		existing, err := db.fetchLock(logger, tx, resource.Key)
 		if err != nil {
 			logger.Error("failed-to-fetch-lock", err)
 			return err
 		}
 
-		if res.Owner != resource.Owner {
+		if existing.Owner != resource.Owner {
 			logger.Error("cannot-release-lock", models.ErrLockCollision)
 			return models.ErrLockCollision
 		}
 
+		if !condition.Matches(existing) {
+			logger.Info("release-condition-failed", lager.Data{
+				"expected-value":         condition.Value,
+				"expected-fencing-token": condition.FencingToken,
+				"fencing-token":          existing.FencingToken,
+			})
+			return models.ErrReleaseConditionFailed
+		}
+
 		_, err = db.helper.Delete(logger, tx, "locks",
 			"path = ?", resource.Key,
 		)
@@ -119,17 +414,24 @@ func (db *SQLDB) Release(logger lager.Logger, resource *models.Resource) error {
 			return err
 		}
 		logger.Info("released-lock")
-		return nil
+		return db.recordEvent(logger, tx, db.releaseEvent, existing)
 	})
 	return db.helper.ConvertSQLError(err)
 }
 
 func (db *SQLDB) Fetch(logger lager.Logger, key string) (*Lock, error) {
-	logger = logger.Session("fetch-lock", lager.Data{"key": key})
+	return db.fetch(logger.Session("fetch-lock", lager.Data{"key": key}), key, false)
+}
+
+func (db *SQLDB) FetchIncludingExpired(logger lager.Logger, key string) (*Lock, error) {
+	return db.fetch(logger.Session("fetch-lock-including-expired", lager.Data{"key": key}), key, true)
+}
+
+func (db *SQLDB) fetch(logger lager.Logger, key string, includeExpired bool) (*Lock, error) {
 	var lock *Lock
 
-	err := db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
-		res, index, id, ttl, err := db.fetchLock(logger, tx, key)
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		existing, err := db.fetchLock(logger, tx, key)
 		if err != nil {
 			logger.Error("failed-to-fetch-lock", err)
 			sqlErr := db.helper.ConvertSQLError(err)
@@ -139,11 +441,11 @@ func (db *SQLDB) Fetch(logger lager.Logger, key string) (*Lock, error) {
 			return sqlErr
 		}
 
-		if res.Owner == "" {
+		if existing.Owner == "" || (!includeExpired && db.expired(existing.ExpiresAt)) {
 			return models.ErrResourceNotFound
 		}
 
-		lock = &Lock{Resource: res, ModifiedIndex: index, ModifiedId: id, TtlInSeconds: ttl}
+		lock = existing
 
 		return nil
 	})
@@ -155,17 +457,18 @@ func (db *SQLDB) FetchAll(logger lager.Logger, lockType string) ([]*Lock, error)
 	logger = logger.Session("fetch-all-locks", lager.Data{"type": lockType})
 	var locks []*Lock
 
-	err := db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
-		var where string
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		where := "(expires_at = 0 OR expires_at > ?)"
 		whereBindings := make([]interface{}, 0)
+		whereBindings = append(whereBindings, db.clock.Now().UnixNano())
 
 		if lockType != "" {
-			where = "type = ?"
+			where += " AND type = ?"
 			whereBindings = append(whereBindings, lockType)
 		}
 
 		rows, err := db.helper.All(logger, tx, "locks",
-			helpers.ColumnList{"path", "owner", "value", "type", "modified_index", "modified_id", "ttl"},
+			helpers.ColumnList{"path", "owner", "value", "type", "metadata", "modified_index", "modified_id", "ttl", "ttl_in_milliseconds", "fencing_token", "expires_at"},
 			helpers.NoLockRow, where, whereBindings...,
 		)
 		if err != nil {
@@ -175,10 +478,10 @@ func (db *SQLDB) FetchAll(logger lager.Logger, lockType string) ([]*Lock, error)
 		defer rows.Close()
 
 		for rows.Next() {
-			var key, owner, value, lockType, id string
-			var index, ttl int64
+			var key, owner, value, lockType, metadata, id string
+			var index, ttl, ttlInMilliseconds, fencingToken, expiresAt int64
 
-			err := rows.Scan(&key, &owner, &value, &lockType, &index, &id, &ttl)
+			err := rows.Scan(&key, &owner, &value, &lockType, &metadata, &index, &id, &ttl, &ttlInMilliseconds, &fencingToken, &expiresAt)
 			if err != nil {
 				logger.Error("failed-to-scan-lock", err)
 				continue
@@ -195,10 +498,14 @@ func (db *SQLDB) FetchAll(logger lager.Logger, lockType string) ([]*Lock, error)
 					Value:    value,
 					Type:     lockType,
 					TypeCode: models.GetTypeCode(lockType),
+					Metadata: decodeMetadata(logger, metadata),
 				},
-				ModifiedIndex: index,
-				ModifiedId:    id,
-				TtlInSeconds:  ttl,
+				ModifiedIndex:     index,
+				ModifiedId:        id,
+				TtlInSeconds:      ttl,
+				TtlInMilliseconds: ttlInMilliseconds,
+				FencingToken:      fencingToken,
+				ExpiresAt:         expiresAt,
 			})
 		}
 
@@ -209,9 +516,14 @@ func (db *SQLDB) FetchAll(logger lager.Logger, lockType string) ([]*Lock, error)
 }
 
 func (db *SQLDB) Count(logger lager.Logger, lockType string) (int, error) {
+	db, err := db.atDatabaseNow(logger, db.db)
+	if err != nil {
+		return 0, err
+	}
+
 	whereBindings := make([]interface{}, 0)
-	wheres := "owner <> ?"
-	whereBindings = append(whereBindings, "")
+	wheres := "owner <> ? AND (expires_at = 0 OR expires_at > ?)"
+	whereBindings = append(whereBindings, "", db.clock.Now().UnixNano())
 
 	if lockType != "" {
 		wheres += " AND type = ?"
@@ -223,25 +535,274 @@ func (db *SQLDB) Count(logger lager.Logger, lockType string) (int, error) {
 	return count, db.helper.ConvertSQLError(err)
 }
 
-func (db *SQLDB) fetchLock(logger lager.Logger, q helpers.Queryable, key string) (*models.Resource, int64, string, int64, error) {
+// ExpireLocks deletes every lock whose expiry timestamp has passed and returns
+// the locks that were removed. The rows are locked for the duration of the
+// transaction so that concurrent locket servers sweeping the same database do
+// not expire the same lock twice.
+func (db *SQLDB) ExpireLocks(logger lager.Logger) ([]*Lock, error) {
+	logger = logger.Session("expire-locks")
+	var expired []*Lock
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		expired = nil
+		now := db.clock.Now().UnixNano()
+
+		rows, err := db.helper.All(logger, tx, "locks",
+			helpers.ColumnList{"path", "owner", "value", "type", "metadata", "modified_index", "modified_id", "ttl", "ttl_in_milliseconds", "fencing_token", "expires_at"},
+			helpers.LockRow, "owner <> ? AND expires_at > 0 AND expires_at <= ? AND reserved_until <= ?", "", now, now,
+		)
+		if err != nil {
+			logger.Error("failed-to-fetch-expired-locks", err)
+			return err
+		}
+
+		for rows.Next() {
+			var key, owner, value, lockType, metadata, id string
+			var index, ttl, ttlInMilliseconds, fencingToken, expiresAt int64
+
+			err := rows.Scan(&key, &owner, &value, &lockType, &metadata, &index, &id, &ttl, &ttlInMilliseconds, &fencingToken, &expiresAt)
+			if err != nil {
+				logger.Error("failed-to-scan-lock", err)
+				continue
+			}
+
+			expired = append(expired, &Lock{
+				Resource: &models.Resource{
+					Key:      key,
+					Owner:    owner,
+					Value:    value,
+					Type:     lockType,
+					TypeCode: models.GetTypeCode(lockType),
+					Metadata: decodeMetadata(logger, metadata),
+				},
+				ModifiedIndex:     index,
+				ModifiedId:        id,
+				TtlInSeconds:      ttl,
+				TtlInMilliseconds: ttlInMilliseconds,
+				FencingToken:      fencingToken,
+				ExpiresAt:         expiresAt,
+			})
+		}
+		rows.Close()
+
+		for _, lock := range expired {
+			_, err = db.helper.Delete(logger, tx, "locks",
+				"path = ? AND modified_index = ? AND modified_id = ?", lock.Key, lock.ModifiedIndex, lock.ModifiedId,
+			)
+			if err != nil {
+				logger.Error("failed-to-expire-lock", err, lagerDataFromLock(lock.Resource))
+				return err
+			}
+			logger.Info("expired-lock", lagerDataFromLock(lock.Resource))
+
+			err = db.recordEvent(logger, tx, LockExpiredEvent, lock)
+			if err != nil {
+				return err
+			}
+		}
+
+		shared, err := db.expireSharedLocks(logger, tx)
+		if err != nil {
+			return err
+		}
+		expired = append(expired, shared...)
+
+		return nil
+	})
+
+	return expired, db.helper.ConvertSQLError(err)
+}
+
+// ExtendExpiries pushes back the expiry of every lock, shared hold and
+// grace reservation by the same duration, leaving locks that never expire
+// alone. Their modified indexes do not change, so it is not a heartbeat.
+func (db *SQLDB) ExtendExpiries(logger lager.Logger, by time.Duration) error {
+	logger = logger.Session("extend-expiries", lager.Data{"by": by.String()})
+
+	err := db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
+		for _, query := range []string{
+			`UPDATE locks SET expires_at = expires_at + ? WHERE expires_at > 0`,
+			`UPDATE locks SET reserved_until = reserved_until + ? WHERE reserved_until > 0`,
+			`UPDATE shared_locks SET expires_at = expires_at + ? WHERE expires_at > 0`,
+		} {
+			_, err := tx.Exec(helpers.RebindForFlavor(query, db.flavor), by.Nanoseconds())
+			if err != nil {
+				logger.Error("failed-to-extend-expiries", err)
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return db.helper.ConvertSQLError(err)
+	}
+
+	logger.Info("extended-expiries")
+	return nil
+}
+
+func (db *SQLDB) expiresAt(ttl time.Duration) int64 {
+	return db.clock.Now().Add(ttl).UnixNano()
+}
+
+func (db *SQLDB) expired(expiresAt int64) bool {
+	return expiresAt > 0 && expiresAt <= db.clock.Now().UnixNano()
+}
+
+func (db *SQLDB) reserved(reservedUntil int64) bool {
+	return reservedUntil > db.clock.Now().UnixNano()
+}
+
+func (db *SQLDB) fetchLock(logger lager.Logger, q helpers.Queryable, key string) (lock *Lock, err error) {
+	_, span := tracing.StartLoggerSpan(logger, "sql.fetch-lock")
+	defer func() {
+		// a key without a lock is an answer rather than a failure
+		spanErr := err
+		if spanErr != nil && db.helper.ConvertSQLError(spanErr) == helpers.ErrResourceNotFound {
+			spanErr = nil
+		}
+		tracing.EndSpan(span, spanErr)
+	}()
+
 	row := db.helper.One(logger, q, "locks",
-		helpers.ColumnList{"owner", "value", "type", "modified_index", "modified_id", "ttl"},
+		helpers.ColumnList{"owner", "value", "type", "metadata", "modified_index", "modified_id", "ttl", "ttl_in_milliseconds", "fencing_token", "expires_at", "acquired_at", "reserved_until"},
+		helpers.LockRow,
+		"path = ?", key,
+	)
+
+	var owner, value, lockType, metadata, id string
+	var index, ttl, ttlInMilliseconds, fencingToken, expiresAt, acquiredAt, reservedUntil int64
+	err = row.Scan(&owner, &value, &lockType, &metadata, &index, &id, &ttl, &ttlInMilliseconds, &fencingToken, &expiresAt, &acquiredAt, &reservedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lock{
+		Resource: &models.Resource{
+			Key:      key,
+			Owner:    owner,
+			Value:    value,
+			Type:     lockType,
+			TypeCode: models.GetTypeCode(lockType),
+			Metadata: decodeMetadata(logger, metadata),
+		},
+		ModifiedIndex:     index,
+		ModifiedId:        id,
+		TtlInSeconds:      ttl,
+		TtlInMilliseconds: ttlInMilliseconds,
+		FencingToken:      fencingToken,
+		ExpiresAt:         expiresAt,
+		AcquiredAt:        acquiredAt,
+		ReservedUntil:     reservedUntil,
+	}, nil
+}
+
+// fetchGuardedLock returns the lock on key like fetchLock, locking its row
+// for the rest of tx. When key has no row there is nothing for SELECT ...
+// FOR UPDATE to lock under read committed, so it locks the key's guard row
+// instead and looks again. Every transaction that takes a key, exclusively
+// or shared, fetches it this way first, so that two of them cannot both see
+// a fresh key as free.
+func (db *SQLDB) fetchGuardedLock(logger lager.Logger, tx *sql.Tx, key string) (*Lock, error) {
+	lock, err := db.fetchLock(logger, tx, key)
+	if err == nil || db.helper.ConvertSQLError(err) != helpers.ErrResourceNotFound {
+		return lock, err
+	}
+
+	err = db.guardKey(logger, tx, key)
+	if err != nil {
+		return nil, err
+	}
+	return db.fetchLock(logger, tx, key)
+}
+
+// guardKey locks the key's row in fencing_tokens for the rest of tx,
+// creating it with no token if it does not exist yet. Its token only
+// changes through nextFencingToken.
+func (db *SQLDB) guardKey(logger lager.Logger, tx *sql.Tx, key string) (err error) {
+	_, span := tracing.StartLoggerSpan(logger, "sql.guard-key")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	upsert := `INSERT INTO fencing_tokens (path, token) VALUES (?, 0) ON DUPLICATE KEY UPDATE token = token`
+	if db.flavor == helpers.Postgres {
+		upsert = `INSERT INTO fencing_tokens (path, token) VALUES (?, 0) ON CONFLICT (path) DO NOTHING`
+	}
+	_, err = tx.Exec(helpers.RebindForFlavor(upsert, db.flavor), key)
+	if err != nil {
+		logger.Error("failed-to-create-key-guard", err)
+		return err
+	}
+
+	row := db.helper.One(logger, tx, "fencing_tokens",
+		helpers.ColumnList{"token"},
 		helpers.LockRow,
 		"path = ?", key,
 	)
+	var token int64
+	err = row.Scan(&token)
+	if err != nil {
+		logger.Error("failed-to-lock-key-guard", err)
+	}
+	return err
+}
+
+// nextFencingToken increments and returns the fencing token for key. Tokens
+// are kept in their own table so that they keep increasing after the lock
+// row has been released or expired.
+func (db *SQLDB) nextFencingToken(logger lager.Logger, tx *sql.Tx, key string) (token int64, err error) {
+	_, span := tracing.StartLoggerSpan(logger, "sql.next-fencing-token")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	row := db.helper.One(logger, tx, "fencing_tokens",
+		helpers.ColumnList{"token"},
+		helpers.LockRow,
+		"path = ?", key,
+	)
+
+	err = row.Scan(&token)
+	if err != nil {
+		if db.helper.ConvertSQLError(err) != helpers.ErrResourceNotFound {
+			return 0, err
+		}
+
+		_, err = db.helper.Insert(logger, tx, "fencing_tokens",
+			helpers.SQLAttributes{"path": key, "token": 1},
+		)
+		return 1, err
+	}
+
+	token++
+	_, err = db.helper.Update(logger, tx, "fencing_tokens",
+		helpers.SQLAttributes{"token": token},
+		"path = ?", key,
+	)
+	return token, err
+}
+
+// encodeMetadata stores metadata as a json object, or an empty string when
+// there is none.
+func encodeMetadata(metadata map[string]string) (string, error) {
+	if len(metadata) == 0 {
+		return "", nil
+	}
 
-	var owner, value, lockType, id string
-	var index, ttl int64
-	err := row.Scan(&owner, &value, &lockType, &index, &id, &ttl)
+	data, err := json.Marshal(metadata)
 	if err != nil {
-		return nil, 0, "", 0, err
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeMetadata(logger lager.Logger, data string) map[string]string {
+	if data == "" {
+		return nil
 	}
 
-	return &models.Resource{
-		Key:      key,
-		Owner:    owner,
-		Value:    value,
-		Type:     lockType,
-		TypeCode: models.GetTypeCode(lockType),
-	}, index, id, ttl, nil
+	var metadata map[string]string
+	err := json.Unmarshal([]byte(data), &metadata)
+	if err != nil {
+		logger.Error("failed-to-decode-metadata", err)
+		return nil
+	}
+	return metadata
 }
@@ -4,12 +4,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
 	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/expiration"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/tracing"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/net/context"
 )
 
 func validateLockInDB(rawDB *sql.DB, res *models.Resource, expectedIndex, expectedTTL int64, expectedModifiedId string) error {
@@ -105,37 +113,88 @@ var _ = Describe("Lock", func() {
 							Value:    "i can do anything",
 							TypeCode: models.LOCK,
 						}
-						lock, err := sqlDB.Lock(logger, typeCodeResource, 10)
+						lock, err := sqlDB.Lock(logger, typeCodeResource, 10*time.Second)
 						Expect(err).NotTo(HaveOccurred())
 						Expect(lock).To(Equal(&db.Lock{
-							Resource:      expectedResource,
-							ModifiedIndex: 1,
-							ModifiedId:    "new-guid",
-							TtlInSeconds:  10,
+							Resource:          expectedResource,
+							ModifiedIndex:     1,
+							ModifiedId:        "new-guid",
+							TtlInSeconds:      10,
+							TtlInMilliseconds: 10000,
+							FencingToken:      1,
+							ExpiresAt:         fakeClock.Now().Add(10 * time.Second).UnixNano(),
+							AcquiredAt:        fakeClock.Now().UnixNano(),
 						}))
 						Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
 					})
 				})
 
 				It("inserts the lock for the owner", func() {
-					lock, err := sqlDB.Lock(logger, resource, 10)
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(lock).To(Equal(&db.Lock{
-						Resource:      expectedResource,
-						ModifiedIndex: 1,
-						ModifiedId:    "new-guid",
-						TtlInSeconds:  10,
+						Resource:          expectedResource,
+						ModifiedIndex:     1,
+						ModifiedId:        "new-guid",
+						TtlInSeconds:      10,
+						TtlInMilliseconds: 10000,
+						FencingToken:      1,
+						ExpiresAt:         fakeClock.Now().Add(10 * time.Second).UnixNano(),
+						AcquiredAt:        fakeClock.Now().UnixNano(),
 					}))
 					Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
 				})
 
+				It("does not insert the lock once the request carried by the logger is canceled", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+
+					_, err := sqlDB.Lock(tracing.WithContext(logger, ctx), resource, 10*time.Second)
+					Expect(err).To(HaveOccurred())
+
+					_, err = sqlDB.Fetch(logger, resource.Key)
+					Expect(err).To(Equal(models.ErrResourceNotFound))
+				})
+
+				It("stores millisecond TTLs and rounds the seconds up", func() {
+					lock, err := sqlDB.Lock(logger, resource, 1500*time.Millisecond)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.TtlInSeconds).To(BeEquivalentTo(2))
+					Expect(lock.TtlInMilliseconds).To(BeEquivalentTo(1500))
+
+					fetchedLock, err := sqlDB.Fetch(logger, resource.Key)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fetchedLock.TTL()).To(Equal(1500 * time.Millisecond))
+
+					fakeClock.Increment(1500 * time.Millisecond)
+					_, err = sqlDB.Fetch(logger, resource.Key)
+					Expect(err).To(Equal(models.ErrResourceNotFound))
+				})
+
+				It("stores the resource metadata", func() {
+					resource.Metadata = map[string]string{"az": "z1", "version": "1.2.3"}
+
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.Metadata).To(Equal(resource.Metadata))
+
+					fetchedLock, err := sqlDB.Fetch(logger, resource.Key)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fetchedLock.Metadata).To(Equal(resource.Metadata))
+
+					locks, err := sqlDB.FetchAll(logger, "")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(locks).To(HaveLen(1))
+					Expect(locks[0].Metadata).To(Equal(resource.Metadata))
+				})
+
 				Context("when generating a random guid fails", func() {
 					BeforeEach(func() {
 						fakeGUIDProvider.NextGUIDReturns("", errors.New("boom!"))
 					})
 
 					It("returns an error", func() {
-						_, err := sqlDB.Lock(logger, resource, 10)
+						_, err := sqlDB.Lock(logger, resource, 10*time.Second)
 						Expect(err).To(HaveOccurred())
 					})
 				})
@@ -153,13 +212,17 @@ var _ = Describe("Lock", func() {
 				})
 
 				It("inserts the lock for the owner", func() {
-					lock, err := sqlDB.Lock(logger, resource, 10)
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(lock).To(Equal(&db.Lock{
-						Resource:      expectedResource,
-						ModifiedIndex: 301,
-						ModifiedId:    "new-guid",
-						TtlInSeconds:  10,
+						Resource:          expectedResource,
+						ModifiedIndex:     301,
+						ModifiedId:        "new-guid",
+						TtlInSeconds:      10,
+						TtlInMilliseconds: 10000,
+						FencingToken:      1,
+						ExpiresAt:         fakeClock.Now().Add(10 * time.Second).UnixNano(),
+						AcquiredAt:        fakeClock.Now().UnixNano(),
 					}))
 					Expect(validateLockInDB(rawDB, resource, 301, 10, "new-guid")).To(Succeed())
 				})
@@ -168,7 +231,7 @@ var _ = Describe("Lock", func() {
 
 		Context("when the lock does exist", func() {
 			BeforeEach(func() {
-				_, err := sqlDB.Lock(logger, resource, 10)
+				_, err := sqlDB.Lock(logger, resource, 10*time.Second)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
 
@@ -183,24 +246,185 @@ var _ = Describe("Lock", func() {
 						Value: "i have never seen the princess bride and never will",
 					}
 
-					_, err := sqlDB.Lock(logger, newResource, 10)
+					_, err := sqlDB.Lock(logger, newResource, 10*time.Second)
 					Expect(err).To(Equal(models.ErrLockCollision))
 					Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
 				})
+
+				It("returns the current holder of the lock", func() {
+					newResource := &models.Resource{Key: "quack", Owner: "jim"}
+
+					holder, err := sqlDB.Lock(logger, newResource, 10*time.Second)
+					Expect(err).To(Equal(models.ErrLockCollision))
+					Expect(holder.Owner).To(Equal(resource.Owner))
+					Expect(holder.FencingToken).To(BeEquivalentTo(1))
+					Expect(holder.ExpiresAt).To(Equal(fakeClock.Now().Add(10 * time.Second).UnixNano()))
+				})
+			})
+
+			Context("and the lock has expired", func() {
+				BeforeEach(func() {
+					fakeClock.Increment(10 * time.Second)
+				})
+
+				It("grants the lock to the new owner with a new modified_id", func() {
+					newResource := &models.Resource{
+						Key:   "quack",
+						Owner: "jim",
+						Value: "i have never seen the princess bride and never will",
+						Type:  "lock",
+					}
+
+					lock, err := sqlDB.Lock(logger, newResource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.ModifiedIndex).To(BeEquivalentTo(2))
+					Expect(lock.ModifiedId).To(Equal("another-new-guid"))
+					Expect(validateLockInDB(rawDB, newResource, 2, 10, "another-new-guid")).To(Succeed())
+				})
+
+				It("increments the fencing token", func() {
+					newResource := &models.Resource{Key: "quack", Owner: "jim"}
+
+					lock, err := sqlDB.Lock(logger, newResource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.FencingToken).To(BeEquivalentTo(2))
+				})
+
+				It("starts a new hold even for the previous owner", func() {
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.AcquiredAt).To(Equal(fakeClock.Now().UnixNano()))
+				})
 			})
 
 			Context("and the desired owner is the same", func() {
 				It("increases the modified_index", func() {
-					lock, err := sqlDB.Lock(logger, resource, 10)
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(lock).To(Equal(&db.Lock{
-						Resource:      expectedResource,
-						ModifiedIndex: 2,
-						ModifiedId:    "new-guid",
-						TtlInSeconds:  10,
+						Resource:          expectedResource,
+						ModifiedIndex:     2,
+						ModifiedId:        "new-guid",
+						TtlInSeconds:      10,
+						TtlInMilliseconds: 10000,
+						FencingToken:      1,
+						ExpiresAt:         fakeClock.Now().Add(10 * time.Second).UnixNano(),
+						AcquiredAt:        fakeClock.Now().UnixNano(),
 					}))
 					Expect(validateLockInDB(rawDB, resource, 2, 10, "new-guid")).To(Succeed())
 				})
+
+				It("extends the expiry", func() {
+					fakeClock.Increment(5 * time.Second)
+
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.ExpiresAt).To(Equal(fakeClock.Now().Add(10 * time.Second).UnixNano()))
+
+					fetched, err := sqlDB.Fetch(logger, resource.Key)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fetched).To(Equal(lock))
+				})
+
+				It("stores a new value", func() {
+					resource.Value = "i can do almost anything"
+
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.Value).To(Equal("i can do almost anything"))
+					Expect(validateLockInDB(rawDB, resource, 2, 10, "new-guid")).To(Succeed())
+				})
+
+				It("stores new metadata", func() {
+					resource.Metadata = map[string]string{"address": "10.0.0.1:8080"}
+
+					_, err := sqlDB.Lock(logger, resource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+
+					fetched, err := sqlDB.Fetch(logger, resource.Key)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fetched.Metadata).To(Equal(resource.Metadata))
+				})
+
+				It("stores a new ttl", func() {
+					lock, err := sqlDB.Lock(logger, resource, 20*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.ExpiresAt).To(Equal(fakeClock.Now().Add(20 * time.Second).UnixNano()))
+					Expect(validateLockInDB(rawDB, resource, 2, 20, "new-guid")).To(Succeed())
+				})
+
+				It("keeps the time the lock was acquired", func() {
+					acquiredAt := fakeClock.Now().UnixNano()
+					fakeClock.Increment(5 * time.Second)
+
+					lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.AcquiredAt).To(Equal(acquiredAt))
+
+					fetched, err := sqlDB.Fetch(logger, resource.Key)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fetched.AcquiredAt).To(Equal(acquiredAt))
+				})
+			})
+
+			Context("and the lock has a maximum hold time", func() {
+				var maxHoldDB *db.SQLDB
+
+				BeforeEach(func() {
+					maxHoldDB = sqlDB.WithMaxHold(time.Minute, map[string]time.Duration{"unlimited": 0})
+
+					_, err := sqlDB.Lock(logger, resource, 2*time.Minute)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("lets the owner refresh the lock until it has held it for the maximum hold time", func() {
+					fakeClock.Increment(59 * time.Second)
+
+					_, err := maxHoldDB.Lock(logger, resource, 2*time.Minute)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("refuses to refresh the lock once the owner has held it for the maximum hold time", func() {
+					fakeClock.Increment(time.Minute)
+
+					_, err := maxHoldDB.Lock(logger, resource, 2*time.Minute)
+					Expect(err).To(Equal(models.ErrMaxHoldExceeded))
+
+					fetched, err := sqlDB.Fetch(logger, resource.Key)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fetched.ExpiresAt).To(Equal(fakeClock.Now().Add(time.Minute).UnixNano()))
+				})
+
+				It("lets the owner take the lock again once it has expired", func() {
+					fakeClock.Increment(2 * time.Minute)
+
+					lock, err := maxHoldDB.Lock(logger, resource, 2*time.Minute)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.AcquiredAt).To(Equal(fakeClock.Now().UnixNano()))
+				})
+
+				It("uses the maximum hold time configured for the key", func() {
+					unlimited := &models.Resource{Key: "unlimited", Owner: resource.Owner, Type: "lock"}
+					_, err := sqlDB.Lock(logger, unlimited, 2*time.Minute)
+					Expect(err).NotTo(HaveOccurred())
+					fakeClock.Increment(time.Minute)
+
+					_, err = maxHoldDB.Lock(logger, unlimited, 2*time.Minute)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("refuses the lock in a batch without failing the others", func() {
+					fakeClock.Increment(time.Minute)
+
+					other := &models.Resource{Key: "other", Owner: resource.Owner, Type: "lock"}
+					results, err := maxHoldDB.LockBatch(logger, []db.BatchedLock{
+						{Resource: resource, TTL: 2 * time.Minute},
+						{Resource: other, TTL: 2 * time.Minute},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(results[0].Err).To(Equal(models.ErrMaxHoldExceeded))
+					Expect(results[1].Err).NotTo(HaveOccurred())
+				})
 			})
 		})
 
@@ -216,12 +440,145 @@ var _ = Describe("Lock", func() {
 			})
 
 			It("returns an unrecoverable error", func() {
-				_, err := sqlDB.Lock(logger, resource, 10)
+				_, err := sqlDB.Lock(logger, resource, 10*time.Second)
 				Expect(err).To(Equal(helpers.ErrUnrecoverableError))
 			})
 		})
 	})
 
+	Context("LockGroup", func() {
+		var other *models.Resource
+
+		BeforeEach(func() {
+			other = &models.Resource{Key: "another", Owner: resource.Owner, Type: "lock"}
+		})
+
+		It("locks every resource and returns them in order", func() {
+			locks, err := sqlDB.LockGroup(logger, []*models.Resource{resource, other}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(2))
+			Expect(locks[0].Key).To(Equal(resource.Key))
+			Expect(locks[1].Key).To(Equal(other.Key))
+
+			Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
+			Expect(validateLockInDB(rawDB, other, 1, 10, "new-guid")).To(Succeed())
+		})
+
+		Context("when one of the keys is held by another owner", func() {
+			BeforeEach(func() {
+				_, err := sqlDB.Lock(logger, &models.Resource{Key: "quack", Owner: "jim", Type: "lock"}, 10*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("locks none of them and returns the holder", func() {
+				locks, err := sqlDB.LockGroup(logger, []*models.Resource{other, resource}, 10*time.Second)
+				Expect(err).To(Equal(models.ErrLockCollision))
+				Expect(locks).To(HaveLen(1))
+				Expect(locks[0].Owner).To(Equal("jim"))
+
+				_, err = sqlDB.Fetch(logger, other.Key)
+				Expect(err).To(Equal(models.ErrResourceNotFound))
+			})
+		})
+	})
+
+	Context("LockBatch", func() {
+		var other *models.Resource
+
+		BeforeEach(func() {
+			other = &models.Resource{Key: "another", Owner: "jim", Type: "lock"}
+			_, err := sqlDB.Lock(logger, &models.Resource{Key: other.Key, Owner: "someone-else", Type: "lock"}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("locks each resource on its own and returns the results in order", func() {
+			results, err := sqlDB.LockBatch(logger, []db.BatchedLock{
+				{Resource: resource, TTL: 10 * time.Second},
+				{Resource: other, TTL: 10 * time.Second},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+
+			Expect(results[0].Err).NotTo(HaveOccurred())
+			Expect(results[0].Lock.Key).To(Equal(resource.Key))
+			Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
+
+			Expect(results[1].Err).To(Equal(models.ErrLockCollision))
+			Expect(results[1].Lock.Owner).To(Equal("someone-else"))
+		})
+	})
+
+	Context("LockWithGrace", func() {
+		var other *models.Resource
+
+		BeforeEach(func() {
+			other = &models.Resource{Key: resource.Key, Owner: "someone-else", Type: "lock"}
+
+			lock, err := sqlDB.LockWithGrace(logger, resource, 10*time.Second, 5*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ReservedUntil).To(Equal(fakeClock.Now().Add(15 * time.Second).UnixNano()))
+		})
+
+		It("reserves the expired lock for its owner until the grace window ends", func() {
+			fakeClock.Increment(10 * time.Second)
+
+			holder, err := sqlDB.Lock(logger, other, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(Equal(resource.Owner))
+
+			_, err = sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("keeps the fencing token when the owner takes the lock back", func() {
+			fakeClock.Increment(12 * time.Second)
+
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.FencingToken).To(BeEquivalentTo(1))
+		})
+
+		It("lets other owners take the lock once the grace window ends", func() {
+			fakeClock.Increment(15 * time.Second)
+
+			lock, err := sqlDB.Lock(logger, other, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Owner).To(Equal("someone-else"))
+		})
+
+		It("is not expired until the grace window ends", func() {
+			fakeClock.Increment(10 * time.Second)
+
+			locks, err := sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(BeEmpty())
+
+			fakeClock.Increment(5 * time.Second)
+
+			locks, err = sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+		})
+
+		It("blocks shared holds until the grace window ends", func() {
+			fakeClock.Increment(10 * time.Second)
+
+			_, err := sqlDB.LockShared(logger, other, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+		})
+
+		It("drops the reservation when the owner refreshes without a grace window", func() {
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ReservedUntil).To(BeZero())
+
+			fakeClock.Increment(10 * time.Second)
+
+			_, err = sqlDB.Lock(logger, other, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	Context("Release", func() {
 		Context("when the lock exists", func() {
 			var currentIndex, currentTTL int64
@@ -244,6 +601,18 @@ var _ = Describe("Lock", func() {
 				Expect(validateLockNotInDB(rawDB, resource)).To(Succeed())
 			})
 
+			It("keeps incrementing the fencing token when the lock is reacquired", func() {
+				lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lock.FencingToken).To(BeEquivalentTo(1))
+
+				Expect(sqlDB.Release(logger, resource)).To(Succeed())
+
+				lock, err = sqlDB.Lock(logger, resource, 10*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lock.FencingToken).To(BeEquivalentTo(2))
+			})
+
 			Context("when the lock is owned by another owner", func() {
 				It("returns an error", func() {
 					err := sqlDB.Release(logger, &models.Resource{
@@ -281,6 +650,36 @@ var _ = Describe("Lock", func() {
 		})
 	})
 
+	Context("ReleaseIf", func() {
+		BeforeEach(func() {
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.FencingToken).To(BeEquivalentTo(1))
+		})
+
+		It("releases the lock when its value and fencing token match", func() {
+			err := sqlDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: resource.Value, FencingToken: 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validateLockNotInDB(rawDB, resource)).To(Succeed())
+		})
+
+		It("does not release the lock when its value does not match", func() {
+			err := sqlDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: "something else"})
+			Expect(err).To(Equal(models.ErrReleaseConditionFailed))
+
+			_, err = sqlDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not release the lock when its fencing token does not match", func() {
+			err := sqlDB.ReleaseIf(logger, resource, db.ReleaseCondition{FencingToken: 2})
+			Expect(err).To(Equal(models.ErrReleaseConditionFailed))
+
+			_, err = sqlDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	Context("Fetch", func() {
 		var lock, expectedLock *models.Resource
 
@@ -462,6 +861,158 @@ var _ = Describe("Lock", func() {
 		})
 	})
 
+	Context("FetchIncludingExpired", func() {
+		BeforeEach(func() {
+			_, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			fakeClock.Increment(10 * time.Second)
+		})
+
+		It("returns the lock after it has expired", func() {
+			_, err := sqlDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+
+			lock, err := sqlDB.FetchIncludingExpired(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Owner).To(Equal(resource.Owner))
+			Expect(lock.ModifiedId).To(Equal("new-guid"))
+		})
+
+		It("lets the lock pick release the expired lock", func() {
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeMetronClient := &mfakes.FakeIngressClient{}
+			lockPick := expiration.NewLockPick(sqlDB, fakeClock, fakeMetronClient, nil)
+			lockPick.RegisterTTL(logger, lock)
+
+			fakeClock.WaitForWatcherAndIncrement(10 * time.Second)
+			Eventually(func() error { return validateLockNotInDB(rawDB, resource) }).Should(Succeed())
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LocksExpired"))
+		})
+	})
+
+	Context("when the logger carries a span", func() {
+		var recorder *tracetest.SpanRecorder
+
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		})
+
+		It("traces the transaction and its queries under the span", func() {
+			ctx, span := tracing.StartSpan(context.Background(), "db.lock")
+			_, err := sqlDB.Lock(tracing.WithContext(logger, ctx), resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			tracing.EndSpan(span, nil)
+
+			names := map[string]int{}
+			for _, ended := range recorder.Ended() {
+				names[ended.Name()]++
+			}
+			Expect(names).To(HaveKeyWithValue("sql.transaction", 1))
+			Expect(names).To(HaveKey("sql.fetch-lock"))
+			Expect(names).To(HaveKey("sql.guard-key"))
+			Expect(names).To(HaveKeyWithValue("sql.next-fencing-token", 1))
+		})
+
+		It("does not trace calls made without one", func() {
+			_, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Ended()).To(BeEmpty())
+		})
+	})
+
+	Context("ExtendExpiries", func() {
+		It("pushes back the expiry of every lock without refreshing it", func() {
+			lock, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			shared := &models.Resource{Key: "shared", Owner: "jim", Type: models.LockType}
+			_, err = sqlDB.LockShared(logger, shared, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sqlDB.ExtendExpiries(logger, 5*time.Second)).To(Succeed())
+
+			fakeClock.Increment(12 * time.Second)
+			fetched, err := sqlDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.ModifiedIndex).To(Equal(lock.ModifiedIndex))
+			Expect(fetched.ExpiresAt).To(Equal(lock.ExpiresAt + int64(5*time.Second)))
+
+			expired, err := sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(BeEmpty())
+
+			fakeClock.Increment(3 * time.Second)
+			expired, err = sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(HaveLen(2))
+		})
+	})
+
+	Context("ExpireLocks", func() {
+		BeforeEach(func() {
+			_, err := sqlDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sqlDB.Lock(logger, &models.Resource{Key: "quack-presence", Owner: "jim", Type: "presence"}, 20*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("does not expire locks before their ttl", func() {
+			locks, err := sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(BeEmpty())
+			Expect(validateLockInDB(rawDB, resource, 1, 10, "new-guid")).To(Succeed())
+		})
+
+		Context("when a lock's ttl has passed", func() {
+			BeforeEach(func() {
+				fakeClock.Increment(10 * time.Second)
+			})
+
+			It("removes the lock and returns it", func() {
+				locks, err := sqlDB.ExpireLocks(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(locks).To(HaveLen(1))
+				Expect(locks[0].Resource).To(Equal(expectedResource))
+				Expect(validateLockNotInDB(rawDB, resource)).To(Succeed())
+			})
+
+			It("is idempotent", func() {
+				_, err := sqlDB.ExpireLocks(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				locks, err := sqlDB.ExpireLocks(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(locks).To(BeEmpty())
+			})
+
+			It("no longer returns the lock from Fetch", func() {
+				_, err := sqlDB.Fetch(logger, resource.Key)
+				Expect(err).To(Equal(models.ErrResourceNotFound))
+			})
+		})
+
+		Context("when the lock table disappear", func() {
+			BeforeEach(func() {
+				_, err := rawDB.Exec("DROP TABLE locks")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				err := sqlDB.CreateLockTable(logger)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an unrecoverable error", func() {
+				_, err := sqlDB.ExpireLocks(logger)
+				Expect(err).To(Equal(helpers.ErrUnrecoverableError))
+			})
+		})
+	})
+
 	Context("FetchAll", func() {
 		var dogLock, humanLock *db.Lock
 
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// The types of the events recorded in the outbox.
+const (
+	LockCreatedEvent  = "created"
+	LockReleasedEvent = "released"
+	LockExpiredEvent  = "expired"
+)
+
+// EventClaimTimeout is how long a server has to dispatch the events it
+// claimed before another server can claim them.
+const EventClaimTimeout = 5 * time.Minute
+
+// OutboxEvent is a change to a lock recorded in the outbox.
+type OutboxEvent struct {
+	ID           int64
+	Type         string
+	Resource     *models.Resource
+	FencingToken int64
+
+	// Timestamp is the unix timestamp in nanoseconds at which the change
+	// was made.
+	Timestamp int64
+}
+
+//go:generate counterfeiter . Outbox
+type Outbox interface {
+	// DispatchEvents passes at most limit of the oldest events in the
+	// outbox to dispatch, which returns how many of them, from the first,
+	// were delivered. Only those are deleted, so the rest are dispatched
+	// again later. It returns how many events were delivered.
+	DispatchEvents(logger lager.Logger, limit int, dispatch func([]OutboxEvent) int) (int, error)
+}
+
+// WithOutbox returns a SQLDB on the same database that records the exclusive
+// locks and presences it creates, releases or expires in the lock_events
+// table, in the same transaction as the change. Its releases are recorded as
+// releaseEvent, so that the lock pick, which expires locks by releasing them,
+// can record them as expirations.
+func (db *SQLDB) WithOutbox(releaseEvent string) *SQLDB {
+	withOutbox := *db
+	withOutbox.outbox = true
+	withOutbox.releaseEvent = releaseEvent
+	return &withOutbox
+}
+
+func (db *SQLDB) recordEvent(logger lager.Logger, tx *sql.Tx, eventType string, lock *Lock) error {
+	if !db.outbox {
+		return nil
+	}
+
+	_, err := db.helper.Insert(logger, tx, "lock_events",
+		helpers.SQLAttributes{
+			"type":          eventType,
+			"path":          lock.Key,
+			"owner":         lock.Owner,
+			"value":         lock.Value,
+			"lock_type":     lock.Type,
+			"fencing_token": lock.FencingToken,
+			"created_at":    db.clock.Now().UnixNano(),
+		},
+	)
+	if err != nil {
+		logger.Error("failed-recording-event", err, lager.Data{"event": eventType})
+	}
+	return err
+}
+
+// DispatchEvents passes the oldest events to dispatch in the order they were
+// recorded, which is the order of the changes to each key. It claims the
+// events in one short transaction, dispatches them outside of any
+// transaction, so that slow deliveries never hold up the changes that record
+// new events, and deletes the delivered ones in another. Servers sharing the
+// database never dispatch the same event at once: while the oldest events
+// are claimed, the others wait, so that each key's events stay in order. A
+// claim lapses after EventClaimTimeout, in case its server dies, so dispatch
+// should give up on an event well before then rather than retry it. Events
+// that were not delivered, or that could not be deleted, are dispatched again
+// later, so each event is delivered at least once.
+func (db *SQLDB) DispatchEvents(logger lager.Logger, limit int, dispatch func([]OutboxEvent) int) (int, error) {
+	logger = logger.Session("dispatch-events")
+
+	events, err := db.claimEvents(logger, limit)
+	if err != nil {
+		return 0, db.helper.ConvertSQLError(err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	delivered := dispatch(events)
+	if delivered < 0 {
+		delivered = 0
+	}
+	if delivered > len(events) {
+		delivered = len(events)
+	}
+
+	err = db.finishEvents(logger, events, delivered)
+	if err != nil {
+		return 0, db.helper.ConvertSQLError(err)
+	}
+	return delivered, nil
+}
+
+// claimEvents claims at most limit of the oldest events, stopping at the
+// first event that another server has claimed.
+func (db *SQLDB) claimEvents(logger lager.Logger, limit int) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+
+	err := db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
+		events = nil
+		now := db.clock.Now().UnixNano()
+
+		rows, err := db.helper.All(logger, tx, "lock_events",
+			helpers.ColumnList{"id", "type", "path", "owner", "value", "lock_type", "fencing_token", "created_at", "claimed_until"},
+			helpers.LockRow, "id > ? ORDER BY id LIMIT ?", 0, limit,
+		)
+		if err != nil {
+			logger.Error("failed-to-fetch-events", err)
+			return err
+		}
+
+		for rows.Next() {
+			var eventType, key, owner, value, lockType string
+			var id, fencingToken, createdAt, claimedUntil int64
+
+			err := rows.Scan(&id, &eventType, &key, &owner, &value, &lockType, &fencingToken, &createdAt, &claimedUntil)
+			if err != nil {
+				rows.Close()
+				logger.Error("failed-to-scan-event", err)
+				return err
+			}
+
+			if claimedUntil > now {
+				break
+			}
+
+			events = append(events, OutboxEvent{
+				ID:   id,
+				Type: eventType,
+				Resource: &models.Resource{
+					Key:      key,
+					Owner:    owner,
+					Value:    value,
+					Type:     lockType,
+					TypeCode: models.GetTypeCode(lockType),
+				},
+				FencingToken: fencingToken,
+				Timestamp:    createdAt,
+			})
+		}
+		rows.Close()
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids, placeholders := eventIDs(events)
+		_, err = db.helper.Update(logger, tx, "lock_events",
+			helpers.SQLAttributes{"claimed_until": now + int64(EventClaimTimeout)},
+			"id IN ("+placeholders+")", ids...,
+		)
+		if err != nil {
+			logger.Error("failed-to-claim-events", err)
+			return err
+		}
+		return nil
+	})
+
+	return events, err
+}
+
+// finishEvents deletes the first delivered events and gives up the claim on
+// the rest, so that they are dispatched again straight away.
+func (db *SQLDB) finishEvents(logger lager.Logger, events []OutboxEvent, delivered int) error {
+	return db.helper.Transact(logger, db.db, func(logger lager.Logger, tx *sql.Tx) error {
+		// events are deleted by id, as events recorded by transactions that
+		// commit while these are dispatched can have lower ids
+		if delivered > 0 {
+			ids, placeholders := eventIDs(events[:delivered])
+			_, err := db.helper.Delete(logger, tx, "lock_events", "id IN ("+placeholders+")", ids...)
+			if err != nil {
+				logger.Error("failed-to-delete-events", err)
+				return err
+			}
+		}
+
+		if delivered < len(events) {
+			ids, placeholders := eventIDs(events[delivered:])
+			_, err := db.helper.Update(logger, tx, "lock_events",
+				helpers.SQLAttributes{"claimed_until": 0},
+				"id IN ("+placeholders+")", ids...,
+			)
+			if err != nil {
+				logger.Error("failed-to-release-events", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func eventIDs(events []OutboxEvent) ([]interface{}, string) {
+	ids := make([]interface{}, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids, strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+}
@@ -0,0 +1,201 @@
+package db_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Outbox", func() {
+	var (
+		outboxDB *db.SQLDB
+		resource *models.Resource
+	)
+
+	dispatchAll := func() []db.OutboxEvent {
+		var dispatched []db.OutboxEvent
+		count, err := sqlDB.DispatchEvents(logger, 100, func(events []db.OutboxEvent) int {
+			dispatched = append(dispatched, events...)
+			return len(events)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(len(dispatched)))
+		return dispatched
+	}
+
+	eventTypes := func(events []db.OutboxEvent) []string {
+		types := []string{}
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+
+	BeforeEach(func() {
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+		outboxDB = sqlDB.WithOutbox(db.LockReleasedEvent)
+		resource = &models.Resource{Key: "cell-1", Owner: "rep-1", Value: "10.0.0.1", Type: models.PresenceType}
+	})
+
+	It("records a lock when it is created, but not when it is refreshed", func() {
+		_, err := outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		events := dispatchAll()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal(db.LockCreatedEvent))
+		Expect(events[0].Resource.Key).To(Equal("cell-1"))
+		Expect(events[0].Resource.Owner).To(Equal("rep-1"))
+		Expect(events[0].Resource.Value).To(Equal("10.0.0.1"))
+		Expect(events[0].Resource.TypeCode).To(Equal(models.PRESENCE))
+		Expect(events[0].FencingToken).To(BeEquivalentTo(1))
+		Expect(events[0].Timestamp).To(Equal(fakeClock.Now().UnixNano()))
+	})
+
+	It("records releases with its release event", func() {
+		_, err := outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outboxDB.Release(logger, resource)).To(Succeed())
+
+		expirerDB := sqlDB.WithOutbox(db.LockExpiredEvent)
+		_, err = outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expirerDB.Release(logger, resource)).To(Succeed())
+
+		Expect(eventTypes(dispatchAll())).To(Equal([]string{
+			db.LockCreatedEvent, db.LockReleasedEvent, db.LockCreatedEvent, db.LockExpiredEvent,
+		}))
+	})
+
+	It("records expired locks", func() {
+		_, err := outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		dispatchAll()
+
+		fakeClock.Increment(11 * time.Second)
+		_, err = outboxDB.ExpireLocks(logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(eventTypes(dispatchAll())).To(Equal([]string{db.LockExpiredEvent}))
+	})
+
+	It("does not record a change that fails", func() {
+		_, err := outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		dispatchAll()
+
+		other := &models.Resource{Key: "cell-1", Owner: "rep-2", Type: models.PresenceType}
+		_, err = outboxDB.Lock(logger, other, 10*time.Second)
+		Expect(err).To(Equal(models.ErrLockCollision))
+		Expect(outboxDB.Release(logger, other)).To(Equal(models.ErrLockCollision))
+
+		Expect(dispatchAll()).To(BeEmpty())
+	})
+
+	It("does not record changes made without the outbox", func() {
+		_, err := sqlDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sqlDB.Release(logger, resource)).To(Succeed())
+
+		Expect(dispatchAll()).To(BeEmpty())
+	})
+
+	It("dispatches at most limit events at a time, oldest first", func() {
+		for _, key := range []string{"a", "b", "c"} {
+			_, err := outboxDB.Lock(logger, &models.Resource{Key: key, Owner: "rep-1", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		var keys []string
+		count, err := sqlDB.DispatchEvents(logger, 2, func(events []db.OutboxEvent) int {
+			for _, event := range events {
+				keys = append(keys, event.Resource.Key)
+			}
+			return len(events)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+		Expect(keys).To(Equal([]string{"a", "b"}))
+
+		events := dispatchAll()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Resource.Key).To(Equal("c"))
+	})
+
+	It("keeps the events that were not delivered", func() {
+		for _, key := range []string{"a", "b", "c"} {
+			_, err := outboxDB.Lock(logger, &models.Resource{Key: key, Owner: "rep-1", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		count, err := sqlDB.DispatchEvents(logger, 100, func(events []db.OutboxEvent) int {
+			Expect(events).To(HaveLen(3))
+			return 1
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+
+		count, err = sqlDB.DispatchEvents(logger, 100, func(events []db.OutboxEvent) int {
+			return 0
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+
+		events := dispatchAll()
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Resource.Key).To(Equal("b"))
+		Expect(events[1].Resource.Key).To(Equal("c"))
+	})
+
+	It("does not hold up the changes that record events while it dispatches", func() {
+		_, err := outboxDB.Lock(logger, &models.Resource{Key: "a", Owner: "rep-1", Type: models.LockType}, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		count, err := sqlDB.DispatchEvents(logger, 100, func(events []db.OutboxEvent) int {
+			_, err := outboxDB.Lock(logger, &models.Resource{Key: "b", Owner: "rep-1", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			return len(events)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+
+		events := dispatchAll()
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Resource.Key).To(Equal("b"))
+	})
+
+	It("does not dispatch events that are being dispatched elsewhere until the claim lapses", func() {
+		for _, key := range []string{"a", "b"} {
+			_, err := outboxDB.Lock(logger, &models.Resource{Key: key, Owner: "rep-1", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		var elsewhere []db.OutboxEvent
+		_, err := sqlDB.DispatchEvents(logger, 100, func(events []db.OutboxEvent) int {
+			elsewhere = dispatchAll()
+			return len(events)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(elsewhere).To(BeEmpty())
+		Expect(dispatchAll()).To(BeEmpty())
+	})
+
+	It("lets another server dispatch the events once a claim lapses", func() {
+		_, err := outboxDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lapsed []db.OutboxEvent
+		_, err = sqlDB.DispatchEvents(logger, 100, func(events []db.OutboxEvent) int {
+			fakeClock.Increment(db.EventClaimTimeout + time.Second)
+			lapsed = dispatchAll()
+			return 0
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventTypes(lapsed)).To(Equal([]string{db.LockCreatedEvent}))
+	})
+})
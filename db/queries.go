@@ -1,6 +1,28 @@
 package db
 
-import "code.cloudfoundry.org/lager"
+import (
+	"strings"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/lager"
+)
+
+// lockTables are the tables created by CreateLockTable.
+var lockTables = []string{"locks", "shared_locks", "fencing_tokens", "lock_events", "sentinels"}
+
+// addedLockColumns are the columns of the locks table that tables created by
+// older versions of locket do not have, in the order they were added.
+var addedLockColumns = []struct {
+	name       string
+	definition string
+}{
+	{"expires_at", "BIGINT DEFAULT 0"},
+	{"ttl_in_milliseconds", "BIGINT DEFAULT 0"},
+	{"fencing_token", "BIGINT DEFAULT 0"},
+	{"metadata", "VARCHAR(4096) DEFAULT ''"},
+	{"acquired_at", "BIGINT DEFAULT 0"},
+	{"reserved_until", "BIGINT DEFAULT 0"},
+}
 
 func (db *SQLDB) CreateLockTable(logger lager.Logger) error {
 	_, err := db.db.Exec(`
@@ -9,14 +31,205 @@ func (db *SQLDB) CreateLockTable(logger lager.Logger) error {
 			owner VARCHAR(255),
 			value VARCHAR(4096),
 			type VARCHAR(255) DEFAULT '',
+			metadata VARCHAR(4096) DEFAULT '',
+			modified_index BIGINT DEFAULT 0,
+			modified_id varchar(255) DEFAULT '',
+			ttl BIGINT DEFAULT 0,
+			ttl_in_milliseconds BIGINT DEFAULT 0,
+			fencing_token BIGINT DEFAULT 0,
+			expires_at BIGINT DEFAULT 0,
+			acquired_at BIGINT DEFAULT 0,
+			reserved_until BIGINT DEFAULT 0
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.db.Exec(`
+		CREATE TABLE IF NOT EXISTS shared_locks (
+			path VARCHAR(255),
+			owner VARCHAR(255),
+			value VARCHAR(4096),
+			type VARCHAR(255) DEFAULT '',
+			metadata VARCHAR(4096) DEFAULT '',
 			modified_index BIGINT DEFAULT 0,
 			modified_id varchar(255) DEFAULT '',
-			ttl BIGINT DEFAULT 0
+			ttl BIGINT DEFAULT 0,
+			ttl_in_milliseconds BIGINT DEFAULT 0,
+			expires_at BIGINT DEFAULT 0,
+			PRIMARY KEY (path, owner)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.db.Exec(`
+		CREATE TABLE IF NOT EXISTS fencing_tokens (
+			path VARCHAR(255) PRIMARY KEY,
+			token BIGINT DEFAULT 0
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	eventID := "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	if db.flavor == helpers.Postgres {
+		eventID = "BIGSERIAL PRIMARY KEY"
+	}
+	_, err = db.db.Exec(`
+		CREATE TABLE IF NOT EXISTS lock_events (
+			id ` + eventID + `,
+			type VARCHAR(255),
+			path VARCHAR(255),
+			owner VARCHAR(255),
+			value VARCHAR(4096),
+			lock_type VARCHAR(255) DEFAULT '',
+			fencing_token BIGINT DEFAULT 0,
+			created_at BIGINT DEFAULT 0,
+			claimed_until BIGINT DEFAULT 0
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.db.Exec(`
+		CREATE TABLE IF NOT EXISTS sentinels (
+			id VARCHAR(255) PRIMARY KEY,
+			written_at BIGINT DEFAULT 0
 		);
 	`)
 	if err != nil {
 		return err
 	}
 
+	for _, column := range addedLockColumns {
+		_, err = db.db.Exec(`SELECT ` + column.name + ` FROM locks LIMIT 1`)
+		if err != nil {
+			logger.Info("adding-" + strings.Replace(column.name, "_", "-", -1) + "-column")
+			_, err = db.db.Exec(`ALTER TABLE locks ADD COLUMN ` + column.name + ` ` + column.definition)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = db.db.Exec(`SELECT claimed_until FROM lock_events LIMIT 1`)
+	if err != nil {
+		logger.Info("adding-claimed-until-column")
+		_, err = db.db.Exec(`ALTER TABLE lock_events ADD COLUMN claimed_until BIGINT DEFAULT 0`)
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.backfillExpiresAt(logger)
+}
+
+// backfillExpiresAt gives the locks written by versions of locket that did
+// not set expires_at an expiry one ttl from now, since an expires_at of 0
+// means the lock never expires. It runs on every start so that it also
+// catches locks written by older servers during a rolling upgrade.
+func (db *SQLDB) backfillExpiresAt(logger lager.Logger) error {
+	result, err := db.db.Exec(helpers.RebindForFlavor(
+		`UPDATE locks SET expires_at = ? + ttl * 1000000000 WHERE expires_at = 0 AND ttl > 0 AND owner <> ''`,
+		db.flavor,
+	), db.clock.Now().UnixNano())
+	if err != nil {
+		logger.Error("failed-to-backfill-expires-at", err)
+		return err
+	}
+
+	backfilled, err := result.RowsAffected()
+	if err == nil && backfilled > 0 {
+		logger.Info("backfilled-expires-at", lager.Data{"locks": backfilled})
+	}
 	return nil
 }
+
+// PendingMigrations returns the changes CreateLockTable would make to the
+// database, without making them.
+func (db *SQLDB) PendingMigrations(logger lager.Logger) []string {
+	pending := []string{}
+	for _, table := range lockTables {
+		_, err := db.db.Exec(`SELECT 1 FROM ` + table + ` LIMIT 1`)
+		if err != nil {
+			pending = append(pending, "create table "+table)
+			continue
+		}
+		if table != "locks" {
+			continue
+		}
+
+		for _, column := range addedLockColumns {
+			_, err := db.db.Exec(`SELECT ` + column.name + ` FROM locks LIMIT 1`)
+			if err != nil {
+				pending = append(pending, "add column locks."+column.name)
+			}
+		}
+	}
+	return pending
+}
+
+// CheckWritable checks that the database user can read and write each of the
+// tables locket uses, by writing a probe row to each in a transaction that is
+// rolled back.
+func (db *SQLDB) CheckWritable(logger lager.Logger) error {
+	const probe = "locket-preflight-probe"
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"locks", "shared_locks"} {
+		_, err = db.helper.Insert(logger, tx, table, helpers.SQLAttributes{"path": probe, "owner": probe, "value": ""})
+		if err == nil {
+			_, err = db.helper.Update(logger, tx, table, helpers.SQLAttributes{"value": probe}, "path = ?", probe)
+		}
+		if err == nil {
+			_, err = db.helper.Delete(logger, tx, table, "path = ?", probe)
+		}
+		if err != nil {
+			logger.Error("failed-probing-table", err, lager.Data{"table": table})
+			return err
+		}
+	}
+
+	_, err = db.helper.Insert(logger, tx, "fencing_tokens", helpers.SQLAttributes{"path": probe, "token": 0})
+	if err == nil {
+		_, err = db.helper.Delete(logger, tx, "fencing_tokens", "path = ?", probe)
+	}
+	if err != nil {
+		logger.Error("failed-probing-table", err, lager.Data{"table": "fencing_tokens"})
+		return err
+	}
+
+	_, err = db.helper.Insert(logger, tx, "lock_events", helpers.SQLAttributes{"type": probe, "path": probe, "owner": probe})
+	if err == nil {
+		_, err = db.helper.Delete(logger, tx, "lock_events", "path = ?", probe)
+	}
+	if err != nil {
+		logger.Error("failed-probing-table", err, lager.Data{"table": "lock_events"})
+		return err
+	}
+
+	_, err = db.helper.Insert(logger, tx, "sentinels", helpers.SQLAttributes{"id": probe, "written_at": 0})
+	if err == nil {
+		_, err = db.helper.Delete(logger, tx, "sentinels", "id = ?", probe)
+	}
+	if err != nil {
+		logger.Error("failed-probing-table", err, lager.Data{"table": "sentinels"})
+		return err
+	}
+	return nil
+}
+
+// Ping checks that the database can be reached.
+func (db *SQLDB) Ping() error {
+	return db.db.Ping()
+}
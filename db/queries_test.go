@@ -0,0 +1,110 @@
+package db_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Queries", func() {
+	Context("PendingMigrations", func() {
+		It("returns nothing when the tables are up to date", func() {
+			Expect(sqlDB.PendingMigrations(logger)).To(BeEmpty())
+		})
+
+		Context("when a table is missing", func() {
+			BeforeEach(func() {
+				_, err := rawDB.Exec("DROP TABLE fencing_tokens")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(sqlDB.CreateLockTable(logger)).To(Succeed())
+			})
+
+			It("returns the table to create", func() {
+				Expect(sqlDB.PendingMigrations(logger)).To(Equal([]string{"create table fencing_tokens"}))
+			})
+		})
+
+		Context("when a column is missing", func() {
+			BeforeEach(func() {
+				_, err := rawDB.Exec("ALTER TABLE locks DROP COLUMN reserved_until")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(sqlDB.CreateLockTable(logger)).To(Succeed())
+			})
+
+			It("returns the column to add", func() {
+				Expect(sqlDB.PendingMigrations(logger)).To(Equal([]string{"add column locks.reserved_until"}))
+			})
+		})
+	})
+
+	Context("CreateLockTable", func() {
+		BeforeEach(func() {
+			_, err := rawDB.Exec(helpers.RebindForFlavor(
+				`INSERT INTO locks (path, owner, value, type, ttl) VALUES (?, ?, ?, ?, ?)`,
+				dbFlavor,
+			), "written-by-older-locket", "jim", "", "lock", 5)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("gives locks written without an expiry one a ttl from now", func() {
+			Expect(sqlDB.CreateLockTable(logger)).To(Succeed())
+
+			lock, err := sqlDB.FetchIncludingExpired(logger, "written-by-older-locket")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ExpiresAt).To(Equal(fakeClock.Now().Add(5 * time.Second).UnixNano()))
+
+			fakeClock.Increment(5 * time.Second)
+			expired, err := sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(expired).To(HaveLen(1))
+		})
+	})
+
+	Context("CheckWritable", func() {
+		It("succeeds without leaving anything behind", func() {
+			Expect(sqlDB.CheckWritable(logger)).To(Succeed())
+
+			count, err := sqlDB.Count(logger, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(0))
+		})
+	})
+
+	Context("WriteSentinel", func() {
+		writtenAt := func(id string) int64 {
+			var writtenAt int64
+			err := rawDB.QueryRow(helpers.RebindForFlavor("SELECT written_at FROM sentinels WHERE id = ?", dbFlavor), id).Scan(&writtenAt)
+			Expect(err).NotTo(HaveOccurred())
+			return writtenAt
+		}
+
+		It("records when each server last wrote its sentinel", func() {
+			Expect(sqlDB.WriteSentinel(logger, "server-1")).To(Succeed())
+			Expect(writtenAt("server-1")).To(Equal(fakeClock.Now().UnixNano()))
+
+			fakeClock.Increment(time.Second)
+			Expect(sqlDB.WriteSentinel(logger, "server-1")).To(Succeed())
+			Expect(sqlDB.WriteSentinel(logger, "server-2")).To(Succeed())
+			Expect(writtenAt("server-1")).To(Equal(fakeClock.Now().UnixNano()))
+			Expect(writtenAt("server-2")).To(Equal(fakeClock.Now().UnixNano()))
+		})
+
+		It("removes the sentinels of servers that stopped writing them a day ago", func() {
+			Expect(sqlDB.WriteSentinel(logger, "gone")).To(Succeed())
+			fakeClock.Increment(25 * time.Hour)
+			Expect(sqlDB.WriteSentinel(logger, "server-1")).To(Succeed())
+
+			var count int
+			Expect(rawDB.QueryRow("SELECT COUNT(*) FROM sentinels").Scan(&count)).To(Succeed())
+			Expect(count).To(Equal(1))
+		})
+	})
+})
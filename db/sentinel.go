@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/lager"
+)
+
+// staleSentinelAge is how long a sentinel may go unwritten before another
+// server removes it, so that those of servers that are gone do not pile up.
+const staleSentinelAge = 24 * time.Hour
+
+// WriteSentinel records that the server identified by id could commit a
+// write to the database just now. Unlike CheckWritable it commits, so it
+// also fails when the database only accepts reads, as a replica cut off
+// from its primary does.
+func (db *SQLDB) WriteSentinel(logger lager.Logger, id string) error {
+	logger = logger.Session("write-sentinel", lager.Data{"id": id})
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		now := db.clock.Now()
+
+		result, err := db.helper.Update(logger, tx, "sentinels",
+			helpers.SQLAttributes{"written_at": now.UnixNano()},
+			"id = ?", id,
+		)
+		if err != nil {
+			logger.Error("failed-updating-sentinel", err)
+			return err
+		}
+
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			_, err = db.helper.Insert(logger, tx, "sentinels",
+				helpers.SQLAttributes{"id": id, "written_at": now.UnixNano()},
+			)
+			if err != nil {
+				logger.Error("failed-inserting-sentinel", err)
+				return err
+			}
+		}
+
+		_, err = db.helper.Delete(logger, tx, "sentinels", "written_at < ?", now.Add(-staleSentinelAge).UnixNano())
+		if err != nil {
+			logger.Error("failed-removing-stale-sentinels", err)
+		}
+		return err
+	})
+
+	return db.helper.ConvertSQLError(err)
+}
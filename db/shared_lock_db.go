@@ -0,0 +1,273 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// Shared holds live in the shared_locks table, one row per owner, alongside
+// the single exclusive row in locks. They carry no fencing token.
+var sharedLockColumns = helpers.ColumnList{"path", "owner", "value", "type", "metadata", "modified_index", "modified_id", "ttl", "ttl_in_milliseconds", "expires_at"}
+
+func (db *SQLDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error) {
+	logger = logger.Session("lock-shared", lagerDataFromLock(resource))
+	return db.lockShared(logger, resource, 0, ttl)
+}
+
+// LockSemaphore takes one of capacity slots on the key. Slots are stored as
+// shared holds, so they also keep exclusive lockers out.
+func (db *SQLDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*Lock, error) {
+	logger = logger.Session("lock-semaphore", lagerDataFromLock(resource))
+	return db.lockShared(logger, resource, capacity, ttl)
+}
+
+// lockShared records a shared hold for the owner of resource. When capacity
+// is greater than 0 the hold is only granted while fewer than capacity other
+// owners hold the key.
+func (db *SQLDB) lockShared(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*Lock, error) {
+	var lock *Lock
+
+	err := db.transact(logger, func(logger lager.Logger, tx *sql.Tx, db *SQLDB) error {
+		exclusive, err := db.fetchGuardedLock(logger, tx, resource.Key)
+		if err != nil {
+			if db.helper.ConvertSQLError(err) != helpers.ErrResourceNotFound {
+				logger.Error("failed-to-fetch-lock", err)
+				return err
+			}
+		} else if exclusive.Owner != resource.Owner && exclusive.Owner != "" && (!db.expired(exclusive.ExpiresAt) || db.reserved(exclusive.ReservedUntil)) {
+			logger.Debug("lock-held-exclusively")
+			lock = exclusive
+			return models.ErrLockCollision
+		}
+
+		err = db.deleteExpiredSharedLocks(logger, tx, resource.Key)
+		if err != nil {
+			return err
+		}
+
+		newHold := false
+
+		var index int64
+		var modifiedId string
+
+		existing, err := db.fetchSharedLock(logger, tx, resource.Key, resource.Owner)
+		if err != nil {
+			if db.helper.ConvertSQLError(err) != helpers.ErrResourceNotFound {
+				logger.Error("failed-to-fetch-shared-lock", err)
+				return err
+			}
+			newHold = true
+		} else {
+			index, modifiedId = existing.ModifiedIndex, existing.ModifiedId
+		}
+
+		if newHold && capacity > 0 {
+			// the count is a plain read, so new holders take turns on the
+			// key's guard row for it not to change before they insert
+			err = db.guardKey(logger, tx, resource.Key)
+			if err != nil {
+				return err
+			}
+
+			holders, err := db.helper.Count(logger, tx, "shared_locks", "path = ?", resource.Key)
+			if err != nil {
+				logger.Error("failed-to-count-shared-locks", err)
+				return err
+			}
+			if holders >= capacity {
+				holder, err := db.fetchOtherSharedHolder(logger, tx, resource.Key, resource.Owner)
+				if err != nil {
+					logger.Error("failed-to-fetch-shared-locks", err)
+					return err
+				}
+				logger.Debug("semaphore-full", lager.Data{"capacity": capacity, "holders": holders})
+				lock = holder
+				return models.ErrLockCollision
+			}
+		}
+
+		if modifiedId == "" {
+			modifiedId, err = db.guidProvider.NextGUID()
+			if err != nil {
+				logger.Error("failed-to-generate-guid", err)
+				return err
+			}
+		}
+
+		metadata, err := encodeMetadata(resource.Metadata)
+		if err != nil {
+			logger.Error("failed-to-encode-metadata", err)
+			return err
+		}
+
+		ttlInSeconds, ttlInMilliseconds := NewTTL(ttl)
+		lock = &Lock{
+			Resource:          models.GetResource(resource),
+			ModifiedIndex:     index + 1,
+			ModifiedId:        modifiedId,
+			TtlInSeconds:      ttlInSeconds,
+			TtlInMilliseconds: ttlInMilliseconds,
+			ExpiresAt:         db.expiresAt(ttl),
+		}
+
+		attributes := helpers.SQLAttributes{
+			"value":               lock.Value,
+			"type":                lock.Type,
+			"metadata":            metadata,
+			"modified_index":      lock.ModifiedIndex,
+			"modified_id":         lock.ModifiedId,
+			"ttl":                 lock.TtlInSeconds,
+			"ttl_in_milliseconds": lock.TtlInMilliseconds,
+			"expires_at":          lock.ExpiresAt,
+		}
+
+		if newHold {
+			attributes["path"] = lock.Key
+			attributes["owner"] = lock.Owner
+			_, err = db.helper.Insert(logger, tx, "shared_locks", attributes)
+		} else {
+			_, err = db.helper.Update(logger, tx, "shared_locks", attributes, "path = ? AND owner = ?", lock.Key, lock.Owner)
+		}
+		if err != nil {
+			logger.Error("failed-updating-shared-lock", err)
+			return err
+		}
+
+		if newHold {
+			logger.Info("acquired-shared-lock")
+		}
+		return nil
+	})
+
+	return lock, db.helper.ConvertSQLError(err)
+}
+
+func (db *SQLDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	logger = logger.Session("release-shared-lock", lagerDataFromLock(resource))
+
+	err := db.tracedTransact(logger, func(logger lager.Logger, tx *sql.Tx) error {
+		result, err := db.helper.Delete(logger, tx, "shared_locks",
+			"path = ? AND owner = ?", resource.Key, resource.Owner,
+		)
+		if err != nil {
+			logger.Error("failed-to-release-shared-lock", err)
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return models.ErrResourceNotFound
+		}
+
+		logger.Info("released-shared-lock")
+		return nil
+	})
+	return db.helper.ConvertSQLError(err)
+}
+
+// fetchOtherSharedHolder returns a live shared holder of key other than
+// owner, or nil if there is none.
+func (db *SQLDB) fetchOtherSharedHolder(logger lager.Logger, q helpers.Queryable, key, owner string) (*Lock, error) {
+	row := db.helper.One(logger, q, "shared_locks", sharedLockColumns, helpers.LockRow,
+		"path = ? AND owner <> ? AND (expires_at = 0 OR expires_at > ?)", key, owner, db.clock.Now().UnixNano(),
+	)
+
+	lock, err := db.scanSharedLock(logger, row)
+	if err != nil {
+		if db.helper.ConvertSQLError(err) == helpers.ErrResourceNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (db *SQLDB) fetchSharedLock(logger lager.Logger, q helpers.Queryable, key, owner string) (*Lock, error) {
+	row := db.helper.One(logger, q, "shared_locks", sharedLockColumns, helpers.LockRow,
+		"path = ? AND owner = ?", key, owner,
+	)
+	return db.scanSharedLock(logger, row)
+}
+
+func (db *SQLDB) deleteExpiredSharedLocks(logger lager.Logger, tx *sql.Tx, key string) error {
+	_, err := db.helper.Delete(logger, tx, "shared_locks",
+		"path = ? AND expires_at > 0 AND expires_at <= ?", key, db.clock.Now().UnixNano(),
+	)
+	if err != nil {
+		logger.Error("failed-to-delete-expired-shared-locks", err)
+	}
+	return err
+}
+
+// expireSharedLocks deletes every shared hold whose expiry timestamp has
+// passed and returns them.
+func (db *SQLDB) expireSharedLocks(logger lager.Logger, tx *sql.Tx) ([]*Lock, error) {
+	rows, err := db.helper.All(logger, tx, "shared_locks", sharedLockColumns, helpers.LockRow,
+		"expires_at > 0 AND expires_at <= ?", db.clock.Now().UnixNano(),
+	)
+	if err != nil {
+		logger.Error("failed-to-fetch-expired-shared-locks", err)
+		return nil, err
+	}
+
+	var expired []*Lock
+	for rows.Next() {
+		lock, err := db.scanSharedLock(logger, rows)
+		if err != nil {
+			logger.Error("failed-to-scan-shared-lock", err)
+			continue
+		}
+		expired = append(expired, lock)
+	}
+	rows.Close()
+
+	for _, lock := range expired {
+		_, err = db.helper.Delete(logger, tx, "shared_locks",
+			"path = ? AND owner = ? AND modified_index = ?", lock.Key, lock.Owner, lock.ModifiedIndex,
+		)
+		if err != nil {
+			logger.Error("failed-to-expire-shared-lock", err, lagerDataFromLock(lock.Resource))
+			return nil, err
+		}
+		logger.Info("expired-shared-lock", lagerDataFromLock(lock.Resource))
+	}
+
+	return expired, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (db *SQLDB) scanSharedLock(logger lager.Logger, row rowScanner) (*Lock, error) {
+	var key, owner, value, lockType, metadata, id string
+	var index, ttl, ttlInMilliseconds, expiresAt int64
+
+	err := row.Scan(&key, &owner, &value, &lockType, &metadata, &index, &id, &ttl, &ttlInMilliseconds, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lock{
+		Resource: &models.Resource{
+			Key:      key,
+			Owner:    owner,
+			Value:    value,
+			Type:     lockType,
+			TypeCode: models.GetTypeCode(lockType),
+			Metadata: decodeMetadata(logger, metadata),
+		},
+		ModifiedIndex:     index,
+		ModifiedId:        id,
+		TtlInSeconds:      ttl,
+		TtlInMilliseconds: ttlInMilliseconds,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package db_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SharedLock", func() {
+	var writer, reader, otherReader *models.Resource
+
+	BeforeEach(func() {
+		writer = &models.Resource{Key: "quack", Owner: "writer", Value: "w", Type: models.LockType}
+		reader = &models.Resource{Key: "quack", Owner: "reader-1", Value: "r", Type: models.LockType}
+		otherReader = &models.Resource{Key: "quack", Owner: "reader-2", Value: "r", Type: models.LockType}
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+	})
+
+	Context("LockShared", func() {
+		It("lets several owners hold the key", func() {
+			lock, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Owner).To(Equal("reader-1"))
+			Expect(lock.ModifiedIndex).To(BeEquivalentTo(1))
+			Expect(lock.ModifiedId).To(Equal("new-guid"))
+			Expect(lock.FencingToken).To(BeZero())
+			Expect(lock.ExpiresAt).To(Equal(fakeClock.Now().Add(10 * time.Second).UnixNano()))
+
+			_, err = sqlDB.LockShared(logger, otherReader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("increases the modified_index when the owner refreshes its hold", func() {
+			_, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			lock, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ModifiedIndex).To(BeEquivalentTo(2))
+		})
+
+		It("is not reported by Fetch", func() {
+			_, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = sqlDB.Fetch(logger, reader.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+
+		Context("when another owner holds the key exclusively", func() {
+			BeforeEach(func() {
+				_, err := sqlDB.Lock(logger, writer, 10*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a collision error and the exclusive holder", func() {
+				holder, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+				Expect(err).To(Equal(models.ErrLockCollision))
+				Expect(holder.Owner).To(Equal("writer"))
+			})
+
+			Context("and the exclusive lock has expired", func() {
+				BeforeEach(func() {
+					fakeClock.Increment(10 * time.Second)
+				})
+
+				It("grants the shared hold", func() {
+					_, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		It("never grants a fresh key exclusively and shared at once", func() {
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprintf("fresh-%d", i)
+
+				var exclusiveErr, sharedErr error
+				var wg sync.WaitGroup
+				wg.Add(2)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, exclusiveErr = sqlDB.Lock(logger, &models.Resource{Key: key, Owner: "writer", Type: models.LockType}, 10*time.Second)
+				}()
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, sharedErr = sqlDB.LockShared(logger, &models.Resource{Key: key, Owner: "reader-1", Type: models.LockType}, 10*time.Second)
+				}()
+				wg.Wait()
+
+				Expect(exclusiveErr == nil && sharedErr == nil).To(BeFalse(), "both took "+key)
+			}
+		})
+	})
+
+	Context("LockSemaphore", func() {
+		It("never grants more slots than capacity to concurrent new holders", func() {
+			var granted int32
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, err := sqlDB.LockSemaphore(logger, &models.Resource{Key: "quack", Owner: fmt.Sprintf("reader-%d", i), Type: models.LockType}, 2, 10*time.Second)
+					if err == nil {
+						atomic.AddInt32(&granted, 1)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			Expect(granted).To(BeNumerically("<=", 2))
+		})
+
+		It("grants up to capacity owners a slot", func() {
+			_, err := sqlDB.LockSemaphore(logger, reader, 2, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sqlDB.LockSemaphore(logger, otherReader, 2, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			holder, err := sqlDB.LockSemaphore(logger, &models.Resource{Key: "quack", Owner: "reader-3", Type: models.LockType}, 2, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(SatisfyAny(Equal("reader-1"), Equal("reader-2")))
+		})
+
+		It("lets a holder refresh its slot when the semaphore is full", func() {
+			_, err := sqlDB.LockSemaphore(logger, reader, 1, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			lock, err := sqlDB.LockSemaphore(logger, reader, 1, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ModifiedIndex).To(BeEquivalentTo(2))
+		})
+
+		It("frees a slot once its holder expires", func() {
+			_, err := sqlDB.LockSemaphore(logger, reader, 1, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.Increment(10 * time.Second)
+
+			_, err = sqlDB.LockSemaphore(logger, otherReader, 1, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Lock", func() {
+		BeforeEach(func() {
+			_, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns a collision error and a shared holder", func() {
+			holder, err := sqlDB.Lock(logger, writer, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(Equal("reader-1"))
+		})
+
+		It("lets the only shared holder upgrade to an exclusive lock", func() {
+			_, err := sqlDB.Lock(logger, &models.Resource{Key: "quack", Owner: "reader-1", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("grants the lock once the shared holds are released", func() {
+			Expect(sqlDB.ReleaseShared(logger, reader)).To(Succeed())
+
+			_, err := sqlDB.Lock(logger, writer, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("grants the lock once the shared holds have expired", func() {
+			fakeClock.Increment(10 * time.Second)
+
+			_, err := sqlDB.Lock(logger, writer, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("ReleaseShared", func() {
+		It("returns not found when the owner has no shared hold", func() {
+			err := sqlDB.ReleaseShared(logger, reader)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+
+		It("only releases the owner's hold", func() {
+			_, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sqlDB.LockShared(logger, otherReader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sqlDB.ReleaseShared(logger, reader)).To(Succeed())
+
+			holder, err := sqlDB.Lock(logger, writer, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(Equal("reader-2"))
+		})
+	})
+
+	Context("ExpireLocks", func() {
+		BeforeEach(func() {
+			_, err := sqlDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("removes expired shared holds and returns them", func() {
+			locks, err := sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(BeEmpty())
+
+			fakeClock.Increment(10 * time.Second)
+
+			locks, err = sqlDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+			Expect(locks[0].Owner).To(Equal("reader-1"))
+
+			err = sqlDB.ReleaseShared(logger, reader)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+)
+
+type slowQueryDB struct {
+	LockDB
+	clock     clock.Clock
+	threshold time.Duration
+}
+
+// NewSlowQueryDB wraps lockDB so that any operation taking longer than
+// threshold logs a "slow-query" entry.
+func NewSlowQueryDB(lockDB LockDB, clock clock.Clock, threshold time.Duration) LockDB {
+	return &slowQueryDB{
+		LockDB:    lockDB,
+		clock:     clock,
+		threshold: threshold,
+	}
+}
+
+func (db *slowQueryDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error) {
+	defer db.time(logger, "lock", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.Lock(logger, resource, ttl)
+}
+
+func (db *slowQueryDB) Release(logger lager.Logger, resource *models.Resource) error {
+	defer db.time(logger, "release", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.Release(logger, resource)
+}
+
+func (db *slowQueryDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition ReleaseCondition) error {
+	defer db.time(logger, "release-if", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (db *slowQueryDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*Lock, error) {
+	defer db.time(logger, "lock-group", lager.Data{"size": len(resources)})()
+	return db.LockDB.LockGroup(logger, resources, ttl)
+}
+
+func (db *slowQueryDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error) {
+	defer db.time(logger, "lock-shared", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.LockShared(logger, resource, ttl)
+}
+
+func (db *slowQueryDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	defer db.time(logger, "release-shared", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.ReleaseShared(logger, resource)
+}
+
+func (db *slowQueryDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*Lock, error) {
+	defer db.time(logger, "lock-semaphore", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.LockSemaphore(logger, resource, capacity, ttl)
+}
+
+func (db *slowQueryDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*Lock, error) {
+	defer db.time(logger, "lock-with-grace", lager.Data{"key": resource.GetKey()})()
+	return db.LockDB.LockWithGrace(logger, resource, ttl, grace)
+}
+
+func (db *slowQueryDB) Fetch(logger lager.Logger, key string) (*Lock, error) {
+	defer db.time(logger, "fetch", lager.Data{"key": key})()
+	return db.LockDB.Fetch(logger, key)
+}
+
+func (db *slowQueryDB) FetchAll(logger lager.Logger, lockType string) ([]*Lock, error) {
+	defer db.time(logger, "fetch-all", lager.Data{"type": lockType})()
+	return db.LockDB.FetchAll(logger, lockType)
+}
+
+func (db *slowQueryDB) Count(logger lager.Logger, lockType string) (int, error) {
+	defer db.time(logger, "count", lager.Data{"type": lockType})()
+	return db.LockDB.Count(logger, lockType)
+}
+
+func (db *slowQueryDB) ExpireLocks(logger lager.Logger) ([]*Lock, error) {
+	defer db.time(logger, "expire-locks", lager.Data{})()
+	return db.LockDB.ExpireLocks(logger)
+}
+
+func (db *slowQueryDB) time(logger lager.Logger, query string, data lager.Data) func() {
+	start := db.clock.Now()
+	return func() {
+		duration := db.clock.Since(start)
+		if duration <= db.threshold {
+			return
+		}
+
+		data["query"] = query
+		data["duration"] = duration.String()
+		data["threshold"] = db.threshold.String()
+		logger.Info("slow-query", data)
+	}
+}
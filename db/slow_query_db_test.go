@@ -0,0 +1,55 @@
+package db_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SlowQueryDB", func() {
+	var (
+		fakeLockDB  *dbfakes.FakeLockDB
+		slowClock   *fakeclock.FakeClock
+		slowLogger  *lagertest.TestLogger
+		slowQueryDB db.LockDB
+	)
+
+	BeforeEach(func() {
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		slowClock = fakeclock.NewFakeClock(time.Now())
+		slowLogger = lagertest.NewTestLogger("slow")
+		slowQueryDB = db.NewSlowQueryDB(fakeLockDB, slowClock, time.Second)
+	})
+
+	It("logs queries that take longer than the threshold", func() {
+		fakeLockDB.FetchStub = func(logger lager.Logger, key string) (*db.Lock, error) {
+			slowClock.Increment(2 * time.Second)
+			return &db.Lock{Resource: &models.Resource{Key: key}}, nil
+		}
+
+		lock, err := slowQueryDB.Fetch(slowLogger, "quack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lock.Key).To(Equal("quack"))
+
+		logs := slowLogger.Logs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0].Message).To(Equal("slow.slow-query"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("query", "fetch"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("key", "quack"))
+		Expect(logs[0].Data).To(HaveKeyWithValue("duration", "2s"))
+	})
+
+	It("does not log fast queries", func() {
+		_, err := slowQueryDB.Count(slowLogger, "lock")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeLockDB.CountCallCount()).To(Equal(1))
+		Expect(slowLogger.Logs()).To(BeEmpty())
+	})
+})
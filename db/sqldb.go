@@ -2,27 +2,111 @@ package db
 
 import (
 	"database/sql"
+	"time"
 
 	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
 	"code.cloudfoundry.org/bbs/guidprovider"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/models"
 )
 
 //go:generate counterfeiter . LockDB
 type LockDB interface {
-	Lock(logger lager.Logger, resource *models.Resource, ttl int64) (*Lock, error)
+	// Lock acquires or refreshes the lock on resource. When the lock is held
+	// by another owner, exclusively or shared, it returns the current holder
+	// along with models.ErrLockCollision.
+	Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error)
 	Release(logger lager.Logger, resource *models.Resource) error
+	// ReleaseIf releases the lock on resource like Release, but only when
+	// the stored lock also matches condition. Otherwise it returns
+	// models.ErrReleaseConditionFailed and leaves the lock in place.
+	ReleaseIf(logger lager.Logger, resource *models.Resource, condition ReleaseCondition) error
+	// LockGroup acquires or refreshes the locks on every resource atomically,
+	// returning them in the same order. If any key is held by another owner
+	// nothing is locked, and it returns that key's holder as the only element
+	// along with models.ErrLockCollision.
+	LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*Lock, error)
+	// LockShared acquires or refreshes a shared hold on resource for its
+	// owner. Any number of owners may hold a key in shared mode, but not
+	// while another owner holds it exclusively.
+	LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*Lock, error)
+	ReleaseShared(logger lager.Logger, resource *models.Resource) error
+	// LockSemaphore acquires or refreshes one of capacity slots on resource
+	// for its owner. Slots are released with ReleaseShared.
+	LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*Lock, error)
+	// LockWithGrace acquires or refreshes the lock on resource like Lock,
+	// and reserves it for its owner for grace after it expires. Until then
+	// only the same owner can take the expired lock again.
+	LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*Lock, error)
 	Fetch(logger lager.Logger, key string) (*Lock, error)
+	// FetchIncludingExpired returns the lock on key like Fetch, but also
+	// once it has expired and has not been removed yet, so that the expirer
+	// can check it is still the lock it is watching before releasing it.
+	FetchIncludingExpired(logger lager.Logger, key string) (*Lock, error)
 	FetchAll(logger lager.Logger, lockType string) ([]*Lock, error)
 	Count(logger lager.Logger, lockType string) (int, error)
+	ExpireLocks(logger lager.Logger) ([]*Lock, error)
 }
 
 type Lock struct {
 	*models.Resource
-	TtlInSeconds  int64
-	ModifiedIndex int64
-	ModifiedId    string
+	TtlInSeconds      int64
+	TtlInMilliseconds int64
+	ModifiedIndex     int64
+	ModifiedId        string
+
+	// FencingToken increases every time ownership of the lock changes hands,
+	// so that downstream systems can reject writes from stale holders.
+	FencingToken int64
+
+	// ExpiresAt is the unix timestamp in nanoseconds at which the lock
+	// expires, or 0 if it does not expire.
+	ExpiresAt int64
+
+	// AcquiredAt is the unix timestamp in nanoseconds at which the current
+	// owner took the lock. Refreshing the lock does not change it.
+	AcquiredAt int64
+
+	// ReservedUntil is the unix timestamp in nanoseconds until which only
+	// the owner can take the lock again after it expires, or 0 if it has no
+	// grace window.
+	ReservedUntil int64
+}
+
+// ReleaseCondition is what a lock has to match to be released by ReleaseIf.
+// Zero fields are not checked.
+type ReleaseCondition struct {
+	Value        string
+	FencingToken int64
+}
+
+// Matches reports whether lock satisfies the condition.
+func (c ReleaseCondition) Matches(lock *Lock) bool {
+	if c.Value != "" && lock.Value != c.Value {
+		return false
+	}
+	if c.FencingToken != 0 && lock.FencingToken != c.FencingToken {
+		return false
+	}
+	return true
+}
+
+// NewTTL returns the TtlInSeconds and TtlInMilliseconds for a lock held for
+// ttl. The seconds are rounded up so that readers that only understand
+// seconds never expire a lock early.
+func NewTTL(ttl time.Duration) (int64, int64) {
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	return seconds, int64(ttl / time.Millisecond)
+}
+
+// TTL returns how long the lock is held for. Locks written before
+// millisecond TTLs existed only have TtlInSeconds.
+func (l *Lock) TTL() time.Duration {
+	if l.TtlInMilliseconds > 0 {
+		return time.Duration(l.TtlInMilliseconds) * time.Millisecond
+	}
+	return time.Duration(l.TtlInSeconds) * time.Second
 }
 
 type SQLDB struct {
@@ -30,12 +114,22 @@ type SQLDB struct {
 	flavor       string
 	helper       helpers.SQLHelper
 	guidProvider guidprovider.GUIDProvider
+	clock        clock.Clock
+
+	outbox       bool
+	releaseEvent string
+
+	databaseNow bool
+
+	defaultMaxHold time.Duration
+	maxHolds       map[string]time.Duration
 }
 
 func NewSQLDB(
 	db *sql.DB,
 	flavor string,
 	guidProvider guidprovider.GUIDProvider,
+	clock clock.Clock,
 ) *SQLDB {
 	helper := helpers.NewSQLHelper(flavor)
 	return &SQLDB{
@@ -43,5 +137,6 @@ func NewSQLDB(
 		flavor:       flavor,
 		helper:       helper,
 		guidProvider: guidProvider,
+		clock:        clock,
 	}
 }
@@ -3,10 +3,12 @@ package db_test
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
 	"code.cloudfoundry.org/bbs/guidprovider/fakes"
 	"code.cloudfoundry.org/bbs/test_helpers"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/lager/lagertest"
 	sqldb "code.cloudfoundry.org/locket/db"
 	. "github.com/onsi/ginkgo"
@@ -22,6 +24,7 @@ var (
 	sqlDB                                *sqldb.SQLDB
 	logger                               *lagertest.TestLogger
 	fakeGUIDProvider                     *fakes.FakeGUIDProvider
+	fakeClock                            *fakeclock.FakeClock
 	dbDriverName, dbBaseConnectionString string
 	dbFlavor                             string
 	sqlHelper                            helpers.SQLHelper
@@ -64,7 +67,8 @@ var _ = BeforeSuite(func() {
 	Expect(rawDB.Ping()).NotTo(HaveOccurred())
 
 	fakeGUIDProvider = &fakes.FakeGUIDProvider{}
-	sqlDB = sqldb.NewSQLDB(rawDB, dbFlavor, fakeGUIDProvider)
+	fakeClock = fakeclock.NewFakeClock(time.Now())
+	sqlDB = sqldb.NewSQLDB(rawDB, dbFlavor, fakeGUIDProvider, fakeClock)
 	err = sqlDB.CreateLockTable(logger)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -106,4 +110,8 @@ func truncateTables(db *sql.DB) {
 
 var truncateTablesSQL = []string{
 	"TRUNCATE TABLE locks",
+	"TRUNCATE TABLE fencing_tokens",
+	"TRUNCATE TABLE shared_locks",
+	"TRUNCATE TABLE lock_events",
+	"TRUNCATE TABLE sentinels",
 }
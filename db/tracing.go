@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/net/context"
+)
+
+// transactAttempts and deadlockRetryInterval match the retries of
+// helper.Transact.
+const (
+	transactAttempts      = 3
+	deadlockRetryInterval = 500 * time.Millisecond
+)
+
+// tracedTransact runs f in a transaction like helper.Transact, retrying
+// transactions that deadlocked, in a span of the request that logger
+// carries, if any. The transaction is bound to the context logger carries,
+// so when a request is canceled or times out its statements fail and the
+// transaction rolls back rather than committing after the client has been
+// told it failed.
+func (db *SQLDB) tracedTransact(logger lager.Logger, f func(logger lager.Logger, tx *sql.Tx) error) error {
+	ctx := tracing.LoggerContext(logger)
+	logger, span := tracing.StartLoggerSpan(logger, "sql.transaction")
+
+	var attempts int
+	var err error
+	for attempts < transactAttempts {
+		attempts++
+		err = db.transactOnce(ctx, logger, f)
+		if db.helper.ConvertSQLError(err) != helpers.ErrDeadlock {
+			break
+		}
+
+		logger.Error("deadlock-transaction", err, lager.Data{"attempts": attempts})
+		select {
+		case <-time.After(deadlockRetryInterval):
+		case <-ctx.Done():
+		}
+	}
+
+	span.SetAttributes(attribute.Int("sql.attempts", attempts))
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (db *SQLDB) transactOnce(ctx context.Context, logger lager.Logger, f func(logger lager.Logger, tx *sql.Tx) error) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = f(logger, tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,198 @@
+package dbtuning
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"golang.org/x/net/context"
+)
+
+// Deployment sizes, from a handful of clients to thousands of cells.
+const (
+	SizeSmall  = "small"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+)
+
+// Isolation levels. IsolationDefault leaves the database's own default in
+// place.
+const (
+	IsolationDefault        = "default"
+	IsolationReadCommitted  = "read-committed"
+	IsolationRepeatableRead = "repeatable-read"
+	IsolationSerializable   = "serializable"
+)
+
+// Config picks the profile for the deployment's Size, small by default, and
+// overrides any of its settings that are set.
+type Config struct {
+	Size                  string                `json:"size,omitempty"`
+	MaxOpenConnections    int                   `json:"max_open_connections,omitempty"`
+	MaxIdleConnections    int                   `json:"max_idle_connections,omitempty"`
+	ConnectionMaxLifetime durationjson.Duration `json:"connection_max_lifetime,omitempty"`
+	ConnectionMaxIdleTime durationjson.Duration `json:"connection_max_idle_time,omitempty"`
+	Isolation             string                `json:"isolation,omitempty"`
+	ConnectAttempts       int                   `json:"connect_attempts,omitempty"`
+	ConnectRetryInterval  durationjson.Duration `json:"connect_retry_interval,omitempty"`
+}
+
+// Profile is how the server uses its connection pool to the database.
+type Profile struct {
+	MaxOpenConnections    int
+	MaxIdleConnections    int
+	ConnectionMaxLifetime time.Duration
+	ConnectionMaxIdleTime time.Duration
+	// Isolation is the isolation level of every transaction.
+	Isolation string
+	// ConnectAttempts is how many times the server tries to reach the
+	// database on start before giving up, ConnectRetryInterval apart.
+	ConnectAttempts      int
+	ConnectRetryInterval time.Duration
+}
+
+// profiles are the built-in profiles by driver and size. They keep the
+// database's default isolation level: the gap locks MySQL takes at repeatable
+// read are what serialize the creation of new keys, and older MySQL and
+// MariaDB servers do not know the session variable newer ones use for it.
+var profiles = map[string]map[string]Profile{
+	"mysql": {
+		SizeSmall:  {MaxOpenConnections: 25, MaxIdleConnections: 10, ConnectionMaxLifetime: time.Hour, ConnectionMaxIdleTime: 5 * time.Minute, Isolation: IsolationDefault, ConnectAttempts: 5, ConnectRetryInterval: 2 * time.Second},
+		SizeMedium: {MaxOpenConnections: 100, MaxIdleConnections: 25, ConnectionMaxLifetime: time.Hour, ConnectionMaxIdleTime: 5 * time.Minute, Isolation: IsolationDefault, ConnectAttempts: 5, ConnectRetryInterval: 2 * time.Second},
+		SizeLarge:  {MaxOpenConnections: 250, MaxIdleConnections: 50, ConnectionMaxLifetime: time.Hour, ConnectionMaxIdleTime: 5 * time.Minute, Isolation: IsolationDefault, ConnectAttempts: 10, ConnectRetryInterval: 2 * time.Second},
+	},
+	"postgres": {
+		SizeSmall:  {MaxOpenConnections: 20, MaxIdleConnections: 10, ConnectionMaxLifetime: time.Hour, ConnectionMaxIdleTime: 5 * time.Minute, Isolation: IsolationDefault, ConnectAttempts: 5, ConnectRetryInterval: 2 * time.Second},
+		SizeMedium: {MaxOpenConnections: 50, MaxIdleConnections: 20, ConnectionMaxLifetime: time.Hour, ConnectionMaxIdleTime: 5 * time.Minute, Isolation: IsolationDefault, ConnectAttempts: 5, ConnectRetryInterval: 2 * time.Second},
+		SizeLarge:  {MaxOpenConnections: 100, MaxIdleConnections: 25, ConnectionMaxLifetime: time.Hour, ConnectionMaxIdleTime: 5 * time.Minute, Isolation: IsolationDefault, ConnectAttempts: 10, ConnectRetryInterval: 2 * time.Second},
+	},
+}
+
+var mysqlIsolation = map[string]string{
+	IsolationReadCommitted:  "READ COMMITTED",
+	IsolationRepeatableRead: "REPEATABLE READ",
+	IsolationSerializable:   "SERIALIZABLE",
+}
+
+var postgresIsolation = map[string]string{
+	IsolationReadCommitted:  `read\\ committed`,
+	IsolationRepeatableRead: `repeatable\\ read`,
+	IsolationSerializable:   `serializable`,
+}
+
+// NewProfile returns the built-in profile for driver and config.Size, with
+// the settings in config applied over it.
+func NewProfile(driver string, config Config) (Profile, error) {
+	sizes, ok := profiles[driver]
+	if !ok {
+		return Profile{}, fmt.Errorf("no tuning profiles for database driver %q", driver)
+	}
+
+	size := config.Size
+	if size == "" {
+		size = SizeSmall
+	}
+	profile, ok := sizes[size]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown deployment size %q, must be one of small, medium or large", config.Size)
+	}
+
+	if config.MaxOpenConnections > 0 {
+		profile.MaxOpenConnections = config.MaxOpenConnections
+	}
+	if config.MaxIdleConnections > 0 {
+		profile.MaxIdleConnections = config.MaxIdleConnections
+	}
+	if config.ConnectionMaxLifetime > 0 {
+		profile.ConnectionMaxLifetime = time.Duration(config.ConnectionMaxLifetime)
+	}
+	if config.ConnectionMaxIdleTime > 0 {
+		profile.ConnectionMaxIdleTime = time.Duration(config.ConnectionMaxIdleTime)
+	}
+	if config.Isolation != "" {
+		if _, ok := mysqlIsolation[config.Isolation]; !ok && config.Isolation != IsolationDefault {
+			return Profile{}, fmt.Errorf("unknown isolation level %q", config.Isolation)
+		}
+		profile.Isolation = config.Isolation
+	}
+	if config.ConnectAttempts > 0 {
+		profile.ConnectAttempts = config.ConnectAttempts
+	}
+	if config.ConnectRetryInterval > 0 {
+		profile.ConnectRetryInterval = time.Duration(config.ConnectRetryInterval)
+	}
+
+	if profile.MaxIdleConnections > profile.MaxOpenConnections {
+		profile.MaxIdleConnections = profile.MaxOpenConnections
+	}
+	return profile, nil
+}
+
+// Apply sets the limits of the profile on the connection pool.
+func (p Profile) Apply(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpenConnections)
+	db.SetMaxIdleConns(p.MaxIdleConnections)
+	db.SetConnMaxLifetime(p.ConnectionMaxLifetime)
+	db.SetConnMaxIdleTime(p.ConnectionMaxIdleTime)
+}
+
+// ConnectionString returns connectionString with the parameters that make
+// every session of postgres use the profile's isolation level. It expects
+// the key=value format. MySQL sessions get theirs from Connector instead.
+func (p Profile) ConnectionString(driver, connectionString string) (string, error) {
+	if p.Isolation == "" || p.Isolation == IsolationDefault {
+		return connectionString, nil
+	}
+
+	switch driver {
+	case "mysql":
+		return connectionString, nil
+	case "postgres":
+		option := fmt.Sprintf("options='-c default_transaction_isolation=%s'", postgresIsolation[p.Isolation])
+		return strings.TrimSpace(connectionString + " " + option), nil
+	default:
+		return "", fmt.Errorf("no tuning profiles for database driver %q", driver)
+	}
+}
+
+// Connector returns a connector that sets the profile's isolation level on
+// every mysql session connector opens. It uses SET SESSION TRANSACTION, which
+// every version of MySQL and MariaDB understands, unlike the
+// transaction_isolation and tx_isolation variables. For other drivers, or
+// the default isolation level, it returns connector.
+func (p Profile) Connector(driverName string, connector driver.Connector) driver.Connector {
+	level, ok := mysqlIsolation[p.Isolation]
+	if driverName != "mysql" || !ok {
+		return connector
+	}
+	return &isolatedConnector{Connector: connector, statement: "SET SESSION TRANSACTION ISOLATION LEVEL " + level}
+}
+
+type isolatedConnector struct {
+	driver.Connector
+	statement string
+}
+
+func (c *isolatedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("database connection cannot execute statements")
+	}
+
+	_, err = execer.ExecContext(ctx, c.statement, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,13 @@
+package dbtuning_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDbtuning(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dbtuning Suite")
+}
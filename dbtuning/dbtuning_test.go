@@ -0,0 +1,142 @@
+package dbtuning_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/locket/dbtuning"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Tuning profiles", func() {
+	It("picks the small profile of the driver by default", func() {
+		mysqlProfile, err := dbtuning.NewProfile("mysql", dbtuning.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mysqlProfile.MaxOpenConnections).To(Equal(25))
+		Expect(mysqlProfile.Isolation).To(Equal(dbtuning.IsolationDefault))
+
+		postgresProfile, err := dbtuning.NewProfile("postgres", dbtuning.Config{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(postgresProfile.MaxOpenConnections).To(Equal(20))
+		Expect(postgresProfile.Isolation).To(Equal(dbtuning.IsolationDefault))
+	})
+
+	It("sizes the pool for the deployment", func() {
+		profile, err := dbtuning.NewProfile("mysql", dbtuning.Config{Size: dbtuning.SizeLarge})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profile.MaxOpenConnections).To(Equal(250))
+		Expect(profile.MaxIdleConnections).To(Equal(50))
+	})
+
+	It("applies the overrides over the profile", func() {
+		profile, err := dbtuning.NewProfile("postgres", dbtuning.Config{
+			Size:                 dbtuning.SizeMedium,
+			MaxOpenConnections:   10,
+			Isolation:            dbtuning.IsolationSerializable,
+			ConnectRetryInterval: durationjson.Duration(time.Second),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(profile).To(Equal(dbtuning.Profile{
+			MaxOpenConnections:    10,
+			MaxIdleConnections:    10,
+			ConnectionMaxLifetime: time.Hour,
+			ConnectionMaxIdleTime: 5 * time.Minute,
+			Isolation:             dbtuning.IsolationSerializable,
+			ConnectAttempts:       5,
+			ConnectRetryInterval:  time.Second,
+		}))
+	})
+
+	It("rejects unknown drivers, sizes and isolation levels", func() {
+		_, err := dbtuning.NewProfile("sqlite", dbtuning.Config{})
+		Expect(err).To(HaveOccurred())
+
+		_, err = dbtuning.NewProfile("mysql", dbtuning.Config{Size: "huge"})
+		Expect(err).To(HaveOccurred())
+
+		_, err = dbtuning.NewProfile("mysql", dbtuning.Config{Isolation: "read-uncommitted"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("ConnectionString", func() {
+		It("leaves mysql connection strings to the connector", func() {
+			profile := dbtuning.Profile{Isolation: dbtuning.IsolationReadCommitted}
+			Expect(profile.ConnectionString("mysql", "locket:secret@tcp(127.0.0.1:3306)/locket")).To(Equal("locket:secret@tcp(127.0.0.1:3306)/locket"))
+		})
+
+		It("sets the isolation level of postgres sessions", func() {
+			profile := dbtuning.Profile{Isolation: dbtuning.IsolationRepeatableRead}
+			connectionString, err := profile.ConnectionString("postgres", "dbname=locket host=127.0.0.1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(connectionString).To(Equal(`dbname=locket host=127.0.0.1 options='-c default_transaction_isolation=repeatable\\ read'`))
+		})
+
+		It("leaves the connection string alone for the default isolation level", func() {
+			profile := dbtuning.Profile{Isolation: dbtuning.IsolationDefault}
+			Expect(profile.ConnectionString("postgres", "dbname=locket")).To(Equal("dbname=locket"))
+		})
+	})
+
+	Describe("Connector", func() {
+		var connector *fakeConnector
+
+		BeforeEach(func() {
+			connector = &fakeConnector{}
+		})
+
+		It("sets the isolation level of every new mysql session", func() {
+			profile := dbtuning.Profile{Isolation: dbtuning.IsolationReadCommitted}
+			conn, err := profile.Connector("mysql", connector).Connect(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.(*fakeConn).statements).To(Equal([]string{"SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED"}))
+		})
+
+		It("closes the session when the isolation level cannot be set", func() {
+			connector.execErr = errors.New("boom")
+			profile := dbtuning.Profile{Isolation: dbtuning.IsolationSerializable}
+			_, err := profile.Connector("mysql", connector).Connect(context.Background())
+			Expect(err).To(MatchError("boom"))
+			Expect(connector.conn.closed).To(BeTrue())
+		})
+
+		It("leaves the default isolation level and postgres alone", func() {
+			Expect(dbtuning.Profile{Isolation: dbtuning.IsolationDefault}.Connector("mysql", connector)).To(BeIdenticalTo(connector))
+			Expect(dbtuning.Profile{Isolation: dbtuning.IsolationReadCommitted}.Connector("postgres", connector)).To(BeIdenticalTo(connector))
+		})
+	})
+})
+
+type fakeConnector struct {
+	execErr error
+	conn    *fakeConn
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	c.conn = &fakeConn{execErr: c.execErr}
+	return c.conn, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	driver.Conn
+	execErr    error
+	statements []string
+	closed     bool
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.statements = append(c.statements, query)
+	return driver.ResultNoRows, c.execErr
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
@@ -0,0 +1 @@
+package dbtuning // import "code.cloudfoundry.org/locket/dbtuning"
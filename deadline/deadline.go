@@ -0,0 +1,75 @@
+package deadline
+
+import (
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// waiting are the RPCs that wait for other clients rather than the
+// database, so they only get a timeout when it is set for them in Methods.
+var waiting = map[string]bool{
+	"Acquire":         true,
+	"ArriveAtBarrier": true,
+}
+
+// Config sets the timeout of requests that arrive without a deadline.
+// Methods overrides Default for individual RPCs, by name such as "Lock" or
+// "FetchAll", and a timeout of 0 disables it.
+type Config struct {
+	Default durationjson.Duration            `json:"default,omitempty"`
+	Methods map[string]durationjson.Duration `json:"methods,omitempty"`
+}
+
+func (c Config) Enabled() bool {
+	return c.Default > 0 || len(c.Methods) > 0
+}
+
+// Timeout returns the timeout of a request to method without a deadline.
+func (c Config) Timeout(method string) time.Duration {
+	if timeout, ok := c.Methods[method]; ok {
+		return time.Duration(timeout)
+	}
+	if waiting[method] {
+		return 0
+	}
+	return time.Duration(c.Default)
+}
+
+// NewInterceptor returns a unary interceptor that gives requests without a
+// deadline the timeout of their method. Requests that come with a deadline
+// of their own keep it. The handler's context is canceled at the timeout,
+// which rolls back the database transaction under way, and a request that
+// fails once its timeout is over fails with models.ErrRequestTimeout. The
+// interceptor waits for the handler, so that a client is never told a
+// request failed when its change went through.
+func NewInterceptor(logger lager.Logger, config Config) grpc.UnaryServerInterceptor {
+	logger = logger.Session("request-deadline")
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		timeout := config.Timeout(method)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			logger.Info("request-timed-out", lager.Data{"method": info.FullMethod, "timeout": timeout.String()})
+			return nil, models.ErrRequestTimeout
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,142 @@
+package deadline_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/deadline"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Deadline", func() {
+	var (
+		logger      *lagertest.TestLogger
+		config      deadline.Config
+		interceptor grpc.UnaryServerInterceptor
+
+		release     chan struct{}
+		handlerCtxs chan context.Context
+		handlerErr  error
+		slowHandler grpc.UnaryHandler
+	)
+
+	call := func(ctx context.Context, method string) (interface{}, error) {
+		return interceptor(ctx, "request", &grpc.UnaryServerInfo{FullMethod: "/models.Locket/" + method}, slowHandler)
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("deadline")
+		config = deadline.Config{
+			Default: durationjson.Duration(50 * time.Millisecond),
+			Methods: map[string]durationjson.Duration{
+				"FetchAll": durationjson.Duration(time.Hour),
+			},
+		}
+
+		release = make(chan struct{}, 1)
+		handlerCtxs = make(chan context.Context, 1)
+		handlerErr = nil
+		slowHandler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			handlerCtxs <- ctx
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return "response", handlerErr
+		}
+	})
+
+	JustBeforeEach(func() {
+		interceptor = deadline.NewInterceptor(logger, config)
+	})
+
+	It("fails a request without a deadline once its timeout is over", func() {
+		_, err := call(context.Background(), "Lock")
+		Expect(err).To(Equal(models.ErrRequestTimeout))
+		Expect(logger).To(gbytes.Say("request-timed-out"))
+	})
+
+	It("cancels the handler's context at the timeout", func() {
+		_, err := call(context.Background(), "Fetch")
+		Expect(err).To(HaveOccurred())
+
+		var ctx context.Context
+		Eventually(handlerCtxs).Should(Receive(&ctx))
+		Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("waits for the handler and returns its response when it succeeds after the timeout", func() {
+		done := make(chan interface{}, 1)
+		go func() {
+			defer GinkgoRecover()
+			resp, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					<-release
+					return "response", nil
+				})
+			Expect(err).NotTo(HaveOccurred())
+			done <- resp
+		}()
+		Consistently(done, 200*time.Millisecond).ShouldNot(Receive())
+
+		release <- struct{}{}
+		Eventually(done).Should(Receive(Equal("response")))
+		Expect(logger).NotTo(gbytes.Say("request-timed-out"))
+	})
+
+	It("returns the response of a request that finishes in time", func() {
+		handlerErr = errors.New("boom")
+		release <- struct{}{}
+
+		resp, err := call(context.Background(), "Lock")
+		Expect(resp).To(Equal("response"))
+		Expect(err).To(MatchError("boom"))
+	})
+
+	It("uses the timeout of the method when it has one", func() {
+		done := make(chan error, 1)
+		go func() {
+			_, err := call(context.Background(), "FetchAll")
+			done <- err
+		}()
+		Consistently(done, 200*time.Millisecond).ShouldNot(Receive())
+
+		release <- struct{}{}
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("keeps the deadline of a request that has one", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := call(ctx, "Lock")
+			done <- err
+		}()
+		Consistently(done, 200*time.Millisecond).ShouldNot(Receive())
+
+		release <- struct{}{}
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("does not time out requests that wait for other clients", func() {
+		done := make(chan error, 1)
+		go func() {
+			_, err := call(context.Background(), "Acquire")
+			done <- err
+		}()
+		Consistently(done, 200*time.Millisecond).ShouldNot(Receive())
+
+		release <- struct{}{}
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
@@ -0,0 +1 @@
+package deadline // import "code.cloudfoundry.org/locket/deadline"
@@ -0,0 +1,13 @@
+package deadlock_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDeadlock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Deadlock Suite")
+}
@@ -0,0 +1,188 @@
+// This file was generated by counterfeiter
+package deadlockfakes
+
+import (
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/locket/deadlock"
+)
+
+type FakeDetector struct {
+	RunStub        func(signals <-chan os.Signal, ready chan<- struct{}) error
+	runMutex       sync.RWMutex
+	runArgsForCall []struct {
+		signals <-chan os.Signal
+		ready   chan<- struct{}
+	}
+	runReturns struct {
+		result1 error
+	}
+	WaitStub        func(key string, owner string) *deadlock.Waiter
+	waitMutex       sync.RWMutex
+	waitArgsForCall []struct {
+		key   string
+		owner string
+	}
+	waitReturns struct {
+		result1 *deadlock.Waiter
+	}
+	BlockedStub        func(w *deadlock.Waiter, holder string)
+	blockedMutex       sync.RWMutex
+	blockedArgsForCall []struct {
+		w      *deadlock.Waiter
+		holder string
+	}
+	DoneStub        func(w *deadlock.Waiter)
+	doneMutex       sync.RWMutex
+	doneArgsForCall []struct {
+		w *deadlock.Waiter
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDetector) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	fake.runMutex.Lock()
+	fake.runArgsForCall = append(fake.runArgsForCall, struct {
+		signals <-chan os.Signal
+		ready   chan<- struct{}
+	}{signals, ready})
+	fake.recordInvocation("Run", []interface{}{signals, ready})
+	fake.runMutex.Unlock()
+	if fake.RunStub != nil {
+		return fake.RunStub(signals, ready)
+	} else {
+		return fake.runReturns.result1
+	}
+}
+
+func (fake *FakeDetector) RunCallCount() int {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return len(fake.runArgsForCall)
+}
+
+func (fake *FakeDetector) RunArgsForCall(i int) (<-chan os.Signal, chan<- struct{}) {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return fake.runArgsForCall[i].signals, fake.runArgsForCall[i].ready
+}
+
+func (fake *FakeDetector) RunReturns(result1 error) {
+	fake.RunStub = nil
+	fake.runReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDetector) Wait(key string, owner string) *deadlock.Waiter {
+	fake.waitMutex.Lock()
+	fake.waitArgsForCall = append(fake.waitArgsForCall, struct {
+		key   string
+		owner string
+	}{key, owner})
+	fake.recordInvocation("Wait", []interface{}{key, owner})
+	fake.waitMutex.Unlock()
+	if fake.WaitStub != nil {
+		return fake.WaitStub(key, owner)
+	} else {
+		return fake.waitReturns.result1
+	}
+}
+
+func (fake *FakeDetector) WaitCallCount() int {
+	fake.waitMutex.RLock()
+	defer fake.waitMutex.RUnlock()
+	return len(fake.waitArgsForCall)
+}
+
+func (fake *FakeDetector) WaitArgsForCall(i int) (string, string) {
+	fake.waitMutex.RLock()
+	defer fake.waitMutex.RUnlock()
+	return fake.waitArgsForCall[i].key, fake.waitArgsForCall[i].owner
+}
+
+func (fake *FakeDetector) WaitReturns(result1 *deadlock.Waiter) {
+	fake.WaitStub = nil
+	fake.waitReturns = struct {
+		result1 *deadlock.Waiter
+	}{result1}
+}
+
+func (fake *FakeDetector) Blocked(w *deadlock.Waiter, holder string) {
+	fake.blockedMutex.Lock()
+	fake.blockedArgsForCall = append(fake.blockedArgsForCall, struct {
+		w      *deadlock.Waiter
+		holder string
+	}{w, holder})
+	fake.recordInvocation("Blocked", []interface{}{w, holder})
+	fake.blockedMutex.Unlock()
+	if fake.BlockedStub != nil {
+		fake.BlockedStub(w, holder)
+	}
+}
+
+func (fake *FakeDetector) BlockedCallCount() int {
+	fake.blockedMutex.RLock()
+	defer fake.blockedMutex.RUnlock()
+	return len(fake.blockedArgsForCall)
+}
+
+func (fake *FakeDetector) BlockedArgsForCall(i int) (*deadlock.Waiter, string) {
+	fake.blockedMutex.RLock()
+	defer fake.blockedMutex.RUnlock()
+	return fake.blockedArgsForCall[i].w, fake.blockedArgsForCall[i].holder
+}
+
+func (fake *FakeDetector) Done(w *deadlock.Waiter) {
+	fake.doneMutex.Lock()
+	fake.doneArgsForCall = append(fake.doneArgsForCall, struct {
+		w *deadlock.Waiter
+	}{w})
+	fake.recordInvocation("Done", []interface{}{w})
+	fake.doneMutex.Unlock()
+	if fake.DoneStub != nil {
+		fake.DoneStub(w)
+	}
+}
+
+func (fake *FakeDetector) DoneCallCount() int {
+	fake.doneMutex.RLock()
+	defer fake.doneMutex.RUnlock()
+	return len(fake.doneArgsForCall)
+}
+
+func (fake *FakeDetector) DoneArgsForCall(i int) *deadlock.Waiter {
+	fake.doneMutex.RLock()
+	defer fake.doneMutex.RUnlock()
+	return fake.doneArgsForCall[i].w
+}
+
+func (fake *FakeDetector) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	fake.waitMutex.RLock()
+	defer fake.waitMutex.RUnlock()
+	fake.blockedMutex.RLock()
+	defer fake.blockedMutex.RUnlock()
+	fake.doneMutex.RLock()
+	defer fake.doneMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDetector) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ deadlock.Detector = new(FakeDetector)
@@ -0,0 +1,263 @@
+package deadlock
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+//go:generate counterfeiter . Detector
+
+// Detector keeps a waits-for graph of the clients blocked on a lock and,
+// while running, periodically aborts one waiter of every cycle in it.
+type Detector interface {
+	ifrit.Runner
+
+	// Wait records that owner is waiting on key.
+	Wait(key, owner string) *Waiter
+	// Blocked records the owner currently holding the key w is waiting on,
+	// or clears it when holder is empty.
+	Blocked(w *Waiter, holder string)
+	// Done removes w from the graph.
+	Done(w *Waiter)
+}
+
+// Waiter is a client waiting on a key. Its Aborted channel is closed when the
+// detector breaks a deadlock by aborting it.
+type Waiter struct {
+	key    string
+	owner  string
+	holder string
+	since  time.Time
+
+	abortOnce *sync.Once
+	aborted   chan struct{}
+}
+
+func NewWaiter(key, owner string, since time.Time) *Waiter {
+	return &Waiter{
+		key:       key,
+		owner:     owner,
+		since:     since,
+		abortOnce: &sync.Once{},
+		aborted:   make(chan struct{}),
+	}
+}
+
+// Aborted returns a channel that is closed when the waiter is aborted. A nil
+// waiter is never aborted.
+func (w *Waiter) Aborted() <-chan struct{} {
+	if w == nil {
+		return nil
+	}
+	return w.aborted
+}
+
+func (w *Waiter) Abort() {
+	w.abortOnce.Do(func() {
+		close(w.aborted)
+	})
+}
+
+type detector struct {
+	logger   lager.Logger
+	clock    clock.Clock
+	interval time.Duration
+
+	mutex    *sync.Mutex
+	waiters  map[*Waiter]struct{}
+	suspects map[*Waiter]string
+}
+
+// NewDetector returns a Detector that looks for cycles every interval. In a
+// cycle the waiter that started waiting last is aborted, once the same cycle
+// has been seen on two checks in a row. A holder recorded with Blocked can
+// be stale until its waiter tries the lock again, so a cycle seen only once
+// may already be gone.
+func NewDetector(logger lager.Logger, clock clock.Clock, interval time.Duration) Detector {
+	return &detector{
+		logger:   logger,
+		clock:    clock,
+		interval: interval,
+		mutex:    &sync.Mutex{},
+		waiters:  make(map[*Waiter]struct{}),
+		suspects: make(map[*Waiter]string),
+	}
+}
+
+func (d *detector) Wait(key, owner string) *Waiter {
+	w := NewWaiter(key, owner, d.clock.Now())
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.waiters[w] = struct{}{}
+	return w
+}
+
+func (d *detector) Blocked(w *Waiter, holder string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	w.holder = holder
+}
+
+func (d *detector) Done(w *Waiter) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.waiters, w)
+}
+
+func (d *detector) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := d.logger.Session("deadlock-detector")
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	tick := d.clock.NewTicker(d.interval)
+	defer tick.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-tick.C():
+			d.detect(logger)
+		}
+	}
+}
+
+func (d *detector) detect(logger lager.Logger) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	suspects := make(map[*Waiter]string)
+	skip := make(map[*Waiter]bool)
+	for {
+		cycle := d.findCycle(skip)
+		if cycle == nil {
+			break
+		}
+
+		youngest := cycle[0]
+		owners := make([]string, 0, len(cycle))
+		for _, w := range cycle {
+			owners = append(owners, w.owner)
+			if w.since.After(youngest.since) {
+				youngest = w
+			}
+		}
+		skip[youngest] = true
+
+		id := cycleID(cycle)
+		if d.suspects[youngest] != id {
+			logger.Info("suspected-deadlock", lager.Data{
+				"key":   youngest.key,
+				"owner": youngest.owner,
+				"cycle": owners,
+			})
+			suspects[youngest] = id
+			continue
+		}
+
+		logger.Info("aborting-deadlocked-waiter", lager.Data{
+			"key":   youngest.key,
+			"owner": youngest.owner,
+			"cycle": owners,
+		})
+		delete(d.waiters, youngest)
+		youngest.Abort()
+	}
+
+	d.suspects = suspects
+}
+
+// cycleID identifies a cycle by its edges, wherever it was entered. Each
+// waiter in cycle waits on the owner of the one after it.
+func cycleID(cycle []*Waiter) string {
+	edges := make([]string, len(cycle))
+	for i, w := range cycle {
+		next := cycle[(i+1)%len(cycle)]
+		edges[i] = w.key + "\x00" + w.owner + "\x00" + next.owner
+	}
+	sort.Strings(edges)
+	return strings.Join(edges, "\x01")
+}
+
+// findCycle returns the waiters along a cycle of the waits-for graph, or nil
+// if there is none, leaving out the waiters in skip. The graph has an edge from each waiting owner to the
+// owner holding the key it waits on. Waiters that have not yet seen the
+// holder, such as those queued behind another waiter, wait on whoever holds
+// the key for the other waiters.
+func (d *detector) findCycle(skip map[*Waiter]bool) []*Waiter {
+	keyHolders := make(map[string]string)
+	for w := range d.waiters {
+		if w.holder != "" {
+			keyHolders[w.key] = w.holder
+		}
+	}
+
+	edges := make(map[string][]*Waiter)
+	holders := make(map[*Waiter]string)
+	for w := range d.waiters {
+		if skip[w] {
+			continue
+		}
+		holder := w.holder
+		if holder == "" {
+			holder = keyHolders[w.key]
+		}
+		if holder == "" || holder == w.owner {
+			continue
+		}
+		holders[w] = holder
+		edges[w.owner] = append(edges[w.owner], w)
+	}
+
+	owners := make([]string, 0, len(edges))
+	for owner := range edges {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	visited := make(map[string]bool)
+	onPath := make(map[string]int)
+	var path []*Waiter
+
+	var visit func(owner string) []*Waiter
+	visit = func(owner string) []*Waiter {
+		if i, ok := onPath[owner]; ok {
+			return path[i:]
+		}
+		if visited[owner] {
+			return nil
+		}
+		visited[owner] = true
+
+		onPath[owner] = len(path)
+		for _, w := range edges[owner] {
+			path = append(path, w)
+			if cycle := visit(holders[w]); cycle != nil {
+				return cycle
+			}
+			path = path[:len(path)-1]
+		}
+		delete(onPath, owner)
+		return nil
+	}
+
+	for _, owner := range owners {
+		if cycle := visit(owner); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
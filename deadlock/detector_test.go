@@ -0,0 +1,130 @@
+package deadlock_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/deadlock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Detector", func() {
+	var (
+		process   ifrit.Process
+		detector  deadlock.Detector
+		fakeClock *fakeclock.FakeClock
+		logger    *lagertest.TestLogger
+		interval  time.Duration
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("deadlock")
+		interval = time.Second
+
+		detector = deadlock.NewDetector(logger, fakeClock, interval)
+		process = ginkgomon.Invoke(detector)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	wait := func(key, owner, holder string) *deadlock.Waiter {
+		w := detector.Wait(key, owner)
+		detector.Blocked(w, holder)
+		fakeClock.Increment(time.Millisecond)
+		return w
+	}
+
+	// detectTwice runs two checks, so that a cycle present in both is
+	// confirmed.
+	detectTwice := func() {
+		fakeClock.Increment(interval)
+		Eventually(logger).Should(gbytes.Say("suspected-deadlock"))
+		fakeClock.Increment(interval)
+	}
+
+	Context("when two owners wait on each other", func() {
+		var older, younger *deadlock.Waiter
+
+		BeforeEach(func() {
+			older = wait("key-2", "a", "b")
+			younger = wait("key-1", "b", "a")
+		})
+
+		It("aborts the waiter that started waiting last once the cycle is seen twice", func() {
+			Consistently(younger.Aborted()).ShouldNot(BeClosed())
+
+			fakeClock.Increment(interval)
+			Eventually(logger).Should(gbytes.Say("suspected-deadlock"))
+			Consistently(younger.Aborted()).ShouldNot(BeClosed())
+
+			fakeClock.Increment(interval)
+
+			Eventually(younger.Aborted()).Should(BeClosed())
+			Consistently(older.Aborted()).ShouldNot(BeClosed())
+			Eventually(logger).Should(gbytes.Say("aborting-deadlocked-waiter"))
+		})
+
+		It("does not abort a waiter whose holder changed since the cycle was seen", func() {
+			fakeClock.Increment(interval)
+			Eventually(logger).Should(gbytes.Say("suspected-deadlock"))
+
+			detector.Blocked(younger, "")
+			fakeClock.Increment(interval)
+
+			Consistently(younger.Aborted()).ShouldNot(BeClosed())
+			Consistently(older.Aborted()).ShouldNot(BeClosed())
+			Expect(logger).NotTo(gbytes.Say("aborting-deadlocked-waiter"))
+		})
+
+		It("does not abort waiters that are done", func() {
+			detector.Done(younger)
+			fakeClock.Increment(interval)
+
+			Consistently(younger.Aborted()).ShouldNot(BeClosed())
+			Consistently(older.Aborted()).ShouldNot(BeClosed())
+		})
+	})
+
+	It("detects cycles through several owners", func() {
+		first := wait("key-2", "a", "b")
+		second := wait("key-3", "b", "c")
+		third := wait("key-1", "c", "a")
+
+		detectTwice()
+
+		Eventually(third.Aborted()).Should(BeClosed())
+		Consistently(first.Aborted()).ShouldNot(BeClosed())
+		Consistently(second.Aborted()).ShouldNot(BeClosed())
+	})
+
+	It("follows waiters that have not seen the holder of their key", func() {
+		head := wait("key-1", "c", "a")
+		queued := detector.Wait("key-1", "b")
+		fakeClock.Increment(time.Millisecond)
+		cycle := wait("key-2", "a", "b")
+
+		detectTwice()
+
+		Eventually(cycle.Aborted()).Should(BeClosed())
+		Consistently(head.Aborted()).ShouldNot(BeClosed())
+		Consistently(queued.Aborted()).ShouldNot(BeClosed())
+	})
+
+	It("leaves waiters alone when there is no cycle", func() {
+		first := wait("key-2", "a", "b")
+		second := wait("key-3", "b", "c")
+
+		fakeClock.Increment(interval)
+
+		Consistently(first.Aborted()).ShouldNot(BeClosed())
+		Consistently(second.Aborted()).ShouldNot(BeClosed())
+	})
+})
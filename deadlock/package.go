@@ -0,0 +1 @@
+package deadlock // import "code.cloudfoundry.org/locket/deadlock"
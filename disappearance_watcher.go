@@ -42,7 +42,7 @@ func (d DisappearanceWatcher) Run(signals <-chan os.Signal, ready chan<- struct{
 	defer logger.Info("done")
 
 	stop := make(chan struct{})
-	WatchForDisappearancesUnder(logger, d.consulClient, d.disappearChan, stop, d.keyPrefix)
+	watchForDisappearancesUnder(logger, d.consulClient, d.clock, d.disappearChan, stop, d.keyPrefix)
 	close(ready)
 
 	select {
@@ -60,6 +60,10 @@ const defaultWatchBlockDuration = 10 * time.Second
 var emptyBytes = []byte{}
 
 func WatchForDisappearancesUnder(logger lager.Logger, client consuladapter.Client, disappearanceChan chan []string, stop <-chan struct{}, prefix string) {
+	watchForDisappearancesUnder(logger, client, clock.NewClock(), disappearanceChan, stop, prefix)
+}
+
+func watchForDisappearancesUnder(logger lager.Logger, client consuladapter.Client, clock clock.Clock, disappearanceChan chan []string, stop <-chan struct{}, prefix string) {
 	logger = logger.Session("watch-for-disappearances")
 
 	go func() {
@@ -78,10 +82,12 @@ func WatchForDisappearancesUnder(logger lager.Logger, client consuladapter.Clien
 
 			if err != nil {
 				logger.Error("list-failed", err)
+				timer := clock.NewTimer(1 * time.Second)
 				select {
 				case <-stop:
+					timer.Stop()
 					return
-				case <-time.After(1 * time.Second):
+				case <-timer.C():
 				}
 				queryOpts.WaitIndex = 0
 				continue
@@ -1,10 +1,13 @@
 package locket_test
 
 import (
+	"errors"
 	"time"
 
 	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/consuladapter"
+	"code.cloudfoundry.org/consuladapter/fakes"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/locket"
 	"github.com/tedsuo/ifrit"
@@ -110,5 +113,31 @@ var _ = Describe("Disappearance Watcher", func() {
 })
 
 var _ = Describe("Disappearance Watcher Unit Tests", func() {
+	var (
+		fakeClock      *fakeclock.FakeClock
+		fakeComponents *fakes.FakeClientComponents
+		watcherProcess ifrit.Process
+	)
+
+	BeforeEach(func() {
+		var consulClient *fakes.FakeClient
+		consulClient, fakeComponents = fakes.NewFakeClient()
+		fakeComponents.KV.ListReturns(nil, nil, errors.New("boom"))
+
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		watcherRunner, _ := locket.NewDisappearanceWatcher(lagertest.NewTestLogger("test"), consulClient, "under", fakeClock)
+		watcherProcess = ifrit.Invoke(watcherRunner)
+	})
 
+	AfterEach(func() {
+		ginkgomon.Kill(watcherProcess)
+	})
+
+	It("waits on its clock before listing again after a failure", func() {
+		Eventually(fakeComponents.KV.ListCallCount).Should(Equal(1))
+		Consistently(fakeComponents.KV.ListCallCount).Should(Equal(1))
+
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(fakeComponents.KV.ListCallCount).Should(Equal(2))
+	})
 })
@@ -0,0 +1,67 @@
+// This file was generated by counterfeiter
+package electionfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/locket/election"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type FakeHealthStatusSetter struct {
+	SetServingStatusStub        func(service string, status healthpb.HealthCheckResponse_ServingStatus)
+	setServingStatusMutex       sync.RWMutex
+	setServingStatusArgsForCall []struct {
+		service string
+		status  healthpb.HealthCheckResponse_ServingStatus
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHealthStatusSetter) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	fake.setServingStatusMutex.Lock()
+	fake.setServingStatusArgsForCall = append(fake.setServingStatusArgsForCall, struct {
+		service string
+		status  healthpb.HealthCheckResponse_ServingStatus
+	}{service, status})
+	fake.recordInvocation("SetServingStatus", []interface{}{service, status})
+	fake.setServingStatusMutex.Unlock()
+	if fake.SetServingStatusStub != nil {
+		fake.SetServingStatusStub(service, status)
+	}
+}
+
+func (fake *FakeHealthStatusSetter) SetServingStatusCallCount() int {
+	fake.setServingStatusMutex.RLock()
+	defer fake.setServingStatusMutex.RUnlock()
+	return len(fake.setServingStatusArgsForCall)
+}
+
+func (fake *FakeHealthStatusSetter) SetServingStatusArgsForCall(i int) (string, healthpb.HealthCheckResponse_ServingStatus) {
+	fake.setServingStatusMutex.RLock()
+	defer fake.setServingStatusMutex.RUnlock()
+	return fake.setServingStatusArgsForCall[i].service, fake.setServingStatusArgsForCall[i].status
+}
+
+func (fake *FakeHealthStatusSetter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.setServingStatusMutex.RLock()
+	defer fake.setServingStatusMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeHealthStatusSetter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ election.HealthStatusSetter = new(FakeHealthStatusSetter)
@@ -0,0 +1 @@
+package electionfakes // import "code.cloudfoundry.org/locket/election/electionfakes"
@@ -0,0 +1,189 @@
+package election
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ActiveLockKey is the key of the lock held by the locket server that is
+// currently serving traffic.
+const ActiveLockKey = "locket-active"
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// failuresBeforeStandby is how many attempts in a row must fail before the
+// active server goes to standby, so that a single failed database call does
+// not take it out of service. Two retry intervals are still well within the
+// ttl of the active lock, so no other server can have taken it over.
+const failuresBeforeStandby = 2
+
+var ErrNotActive = grpc.Errorf(codes.Unavailable, "locket server is not active")
+
+//go:generate counterfeiter . HealthStatusSetter
+type HealthStatusSetter interface {
+	SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus)
+}
+
+type elector struct {
+	logger        lager.Logger
+	lockDB        db.LockDB
+	lockPick      expiration.LockPick
+	health        HealthStatusSetter
+	resource      *models.Resource
+	ttlInSeconds  int64
+	clock         clock.Clock
+	retryInterval time.Duration
+	active        *int32
+	failures      int
+}
+
+func NewElector(
+	logger lager.Logger,
+	lockDB db.LockDB,
+	lockPick expiration.LockPick,
+	health HealthStatusSetter,
+	owner string,
+	ttlInSeconds int64,
+	clock clock.Clock,
+	retryInterval time.Duration,
+) *elector {
+	return &elector{
+		logger:   logger,
+		lockDB:   lockDB,
+		lockPick: lockPick,
+		health:   health,
+		resource: &models.Resource{
+			Key:      ActiveLockKey,
+			Owner:    owner,
+			Type:     models.LockType,
+			TypeCode: models.LOCK,
+		},
+		ttlInSeconds:  ttlInSeconds,
+		clock:         clock,
+		retryInterval: retryInterval,
+		active:        new(int32),
+	}
+}
+
+// IsActive reports whether this server currently holds the active lock.
+func (e *elector) IsActive() bool {
+	return atomic.LoadInt32(e.active) == 1
+}
+
+func (e *elector) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := e.logger.Session("elector", lager.Data{"owner": e.resource.Owner})
+
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	e.setActive(logger, false)
+	e.attempt(logger)
+
+	retry := e.clock.NewTicker(e.retryInterval)
+	defer retry.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+
+			if e.IsActive() {
+				e.setActive(logger, false)
+				err := e.lockDB.Release(logger, e.resource)
+				if err != nil {
+					logger.Error("failed-to-release-active-lock", err)
+				}
+			}
+
+			return nil
+
+		case <-retry.C():
+			e.attempt(logger)
+		}
+	}
+}
+
+// attempt acquires or renews the active lock. A collision means another
+// server holds it, so the server goes to standby straight away, while other
+// errors only do once failuresBeforeStandby attempts in a row have failed.
+func (e *elector) attempt(logger lager.Logger) {
+	lock, err := e.lockDB.Lock(logger, e.resource, time.Duration(e.ttlInSeconds)*time.Second)
+	if err == models.ErrLockCollision {
+		e.failures = 0
+		e.setActive(logger, false)
+		return
+	}
+	if err != nil {
+		e.failures++
+		logger.Error("failed-to-acquire-active-lock", err, lager.Data{"failures": e.failures})
+		if e.IsActive() && e.failures < failuresBeforeStandby {
+			return
+		}
+		e.setActive(logger, false)
+		return
+	}
+
+	e.failures = 0
+	e.lockPick.RegisterTTL(logger, lock)
+	e.setActive(logger, true)
+}
+
+func (e *elector) setActive(logger lager.Logger, active bool) {
+	var value int32
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if active {
+		value = 1
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	old := atomic.SwapInt32(e.active, value)
+	if old != value {
+		if active {
+			logger.Info("became-active")
+		} else {
+			logger.Info("became-standby")
+		}
+	}
+
+	e.health.SetServingStatus("", status)
+}
+
+type activeChecker interface {
+	IsActive() bool
+}
+
+// NewInterceptor returns a unary interceptor that rejects requests with
+// ErrNotActive while the elector is on standby.
+func NewInterceptor(checker activeChecker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !checker.IsActive() && !strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return nil, ErrNotActive
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamInterceptor rejects new streams with ErrNotActive while the
+// elector is on standby.
+func NewStreamInterceptor(checker activeChecker) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !checker.IsActive() && !strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return ErrNotActive
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,274 @@
+package election_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/election"
+	"code.cloudfoundry.org/locket/election/electionfakes"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var _ = Describe("Elector", func() {
+	var (
+		elector interface {
+			ifrit.Runner
+			IsActive() bool
+		}
+		process ifrit.Process
+
+		logger       *lagertest.TestLogger
+		fakeLockDB   *dbfakes.FakeLockDB
+		fakeLockPick *expirationfakes.FakeLockPick
+		fakeHealth   *electionfakes.FakeHealthStatusSetter
+		fakeClock    *fakeclock.FakeClock
+
+		retryInterval time.Duration
+		activeLock    *db.Lock
+	)
+
+	lastStatus := func() healthpb.HealthCheckResponse_ServingStatus {
+		count := fakeHealth.SetServingStatusCallCount()
+		if count == 0 {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		_, status := fakeHealth.SetServingStatusArgsForCall(count - 1)
+		return status
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("elector")
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		fakeLockPick = &expirationfakes.FakeLockPick{}
+		fakeHealth = &electionfakes.FakeHealthStatusSetter{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		retryInterval = 5 * time.Second
+
+		activeLock = &db.Lock{
+			Resource:      &models.Resource{Key: election.ActiveLockKey, Owner: "server-1"},
+			ModifiedIndex: 1,
+			TtlInSeconds:  15,
+		}
+		fakeLockDB.LockReturns(activeLock, nil)
+	})
+
+	JustBeforeEach(func() {
+		elector = election.NewElector(logger, fakeLockDB, fakeLockPick, fakeHealth, "server-1", 15, fakeClock, retryInterval)
+		process = ginkgomon.Invoke(elector)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("acquires the active lock", func() {
+		Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		_, resource, ttl := fakeLockDB.LockArgsForCall(0)
+		Expect(resource.Key).To(Equal(election.ActiveLockKey))
+		Expect(resource.Owner).To(Equal("server-1"))
+		Expect(ttl).To(Equal(15 * time.Second))
+	})
+
+	It("registers the lock ttl with the lock pick", func() {
+		Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(1))
+		_, lock := fakeLockPick.RegisterTTLArgsForCall(0)
+		Expect(lock).To(Equal(activeLock))
+	})
+
+	It("becomes active and reports serving", func() {
+		Expect(elector.IsActive()).To(BeTrue())
+		Expect(lastStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+	})
+
+	It("renews the lock on the retry interval", func() {
+		fakeClock.Increment(retryInterval)
+		Eventually(fakeLockDB.LockCallCount).Should(Equal(2))
+	})
+
+	It("releases the lock when signalled", func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+		Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+		Expect(elector.IsActive()).To(BeFalse())
+		Expect(lastStatus()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+	})
+
+	Context("when another server holds the active lock", func() {
+		BeforeEach(func() {
+			fakeLockDB.LockReturns(nil, models.ErrLockCollision)
+		})
+
+		It("stays on standby and reports not serving", func() {
+			Expect(elector.IsActive()).To(BeFalse())
+			Expect(lastStatus()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+		})
+
+		It("does not release the lock when signalled", func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive())
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
+		})
+
+		Context("and the lock becomes available", func() {
+			It("becomes active", func() {
+				fakeLockDB.LockReturns(activeLock, nil)
+				fakeClock.Increment(retryInterval)
+				Eventually(elector.IsActive).Should(BeTrue())
+				Eventually(lastStatus).Should(Equal(healthpb.HealthCheckResponse_SERVING))
+			})
+		})
+	})
+
+	Context("when the active server loses the lock", func() {
+		It("goes back to standby", func() {
+			Expect(elector.IsActive()).To(BeTrue())
+
+			fakeLockDB.LockReturns(nil, models.ErrLockCollision)
+			fakeClock.Increment(retryInterval)
+			Eventually(elector.IsActive).Should(BeFalse())
+			Eventually(lastStatus).Should(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+		})
+	})
+
+	Context("when the active server fails to renew the lock", func() {
+		var results chan error
+
+		BeforeEach(func() {
+			results = make(chan error, 10)
+			fakeLockDB.LockStub = func(lager.Logger, *models.Resource, time.Duration) (*db.Lock, error) {
+				select {
+				case err := <-results:
+					if err != nil {
+						return nil, err
+					}
+				default:
+				}
+				return activeLock, nil
+			}
+		})
+
+		renew := func(err error) {
+			calls := fakeLockDB.LockCallCount()
+			results <- err
+			fakeClock.WaitForWatcherAndIncrement(retryInterval)
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(calls + 1))
+		}
+
+		It("stays active after a single failure", func() {
+			renew(errors.New("boom"))
+			Consistently(elector.IsActive).Should(BeTrue())
+		})
+
+		It("goes back to standby once it fails again in a row", func() {
+			renew(errors.New("boom"))
+			renew(errors.New("boom"))
+			Eventually(elector.IsActive).Should(BeFalse())
+			Eventually(lastStatus).Should(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+		})
+
+		It("counts only failures in a row", func() {
+			renew(errors.New("boom"))
+			renew(nil)
+			renew(errors.New("boom"))
+			Consistently(elector.IsActive).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Interceptor", func() {
+	var (
+		active  bool
+		called  bool
+		handler grpc.UnaryHandler
+	)
+
+	BeforeEach(func() {
+		called = false
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		}
+	})
+
+	intercept := func(method string) error {
+		interceptor := election.NewInterceptor(fakeChecker(func() bool { return active }))
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	Context("when the server is active", func() {
+		BeforeEach(func() {
+			active = true
+		})
+
+		It("calls the handler", func() {
+			Expect(intercept("/models.Locket/Lock")).To(Succeed())
+			Expect(called).To(BeTrue())
+		})
+	})
+
+	Context("when the server is on standby", func() {
+		BeforeEach(func() {
+			active = false
+		})
+
+		It("rejects the request", func() {
+			Expect(intercept("/models.Locket/Lock")).To(Equal(election.ErrNotActive))
+			Expect(called).To(BeFalse())
+		})
+
+		It("still serves health checks", func() {
+			Expect(intercept("/grpc.health.v1.Health/Check")).To(Succeed())
+			Expect(called).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("StreamInterceptor", func() {
+	var (
+		active bool
+		called bool
+	)
+
+	BeforeEach(func() {
+		called = false
+	})
+
+	intercept := func(method string) error {
+		interceptor := election.NewStreamInterceptor(fakeChecker(func() bool { return active }))
+		return interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+			called = true
+			return nil
+		})
+	}
+
+	It("opens streams while the server is active", func() {
+		active = true
+		Expect(intercept("/models.Locket/KeepAlive")).To(Succeed())
+		Expect(called).To(BeTrue())
+	})
+
+	It("rejects streams while the server is on standby", func() {
+		active = false
+		Expect(intercept("/models.Locket/KeepAlive")).To(Equal(election.ErrNotActive))
+		Expect(called).To(BeFalse())
+	})
+})
+
+type fakeChecker func() bool
+
+func (f fakeChecker) IsActive() bool { return f() }
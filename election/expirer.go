@@ -0,0 +1,126 @@
+package election
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/tedsuo/ifrit"
+)
+
+// ActiveFunc reports whether this server is active. It lets the expirer be
+// created before the elector, which needs the expirer's lock pick.
+type ActiveFunc func() bool
+
+func (f ActiveFunc) IsActive() bool {
+	return f()
+}
+
+type expirerLockDB struct {
+	db.LockDB
+	checker activeChecker
+}
+
+// NewExpirerLockDB wraps the expirer's lockDB so that its releases and
+// expirations fail with ErrNotActive while the server is on standby. The
+// lock pick keeps the locks whose release failed and tries them again, so
+// that it takes over with them scheduled, but only the active server acts on
+// them. The locks that expired while no server was active are expired by the
+// burglar when it starts on the newly active server.
+func NewExpirerLockDB(lockDB db.LockDB, checker activeChecker) db.LockDB {
+	return &expirerLockDB{LockDB: lockDB, checker: checker}
+}
+
+func (l *expirerLockDB) Release(logger lager.Logger, resource *models.Resource) error {
+	if !l.checker.IsActive() {
+		return ErrNotActive
+	}
+	return l.LockDB.Release(logger, resource)
+}
+
+func (l *expirerLockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	if !l.checker.IsActive() {
+		return ErrNotActive
+	}
+	return l.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (l *expirerLockDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	if !l.checker.IsActive() {
+		return nil, ErrNotActive
+	}
+	return l.LockDB.ExpireLocks(logger)
+}
+
+type whileActive struct {
+	logger        lager.Logger
+	checker       activeChecker
+	runner        ifrit.Runner
+	clock         clock.Clock
+	checkInterval time.Duration
+}
+
+// WhileActive returns a runner that runs runner only while checker reports
+// that this server is active, checking every checkInterval. It stops runner
+// when the server goes to standby, and starts it again once it is active.
+func WhileActive(logger lager.Logger, checker activeChecker, runner ifrit.Runner, clock clock.Clock, checkInterval time.Duration) ifrit.Runner {
+	return &whileActive{
+		logger:        logger,
+		checker:       checker,
+		runner:        runner,
+		clock:         clock,
+		checkInterval: checkInterval,
+	}
+}
+
+func (w *whileActive) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := w.logger.Session("while-active")
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	check := w.clock.NewTicker(w.checkInterval)
+	defer check.Stop()
+
+	var process ifrit.Process
+	var exited <-chan error
+	stop := func() {
+		if process != nil {
+			process.Signal(os.Interrupt)
+			<-exited
+			process, exited = nil, nil
+		}
+	}
+	defer stop()
+
+	close(ready)
+
+	for {
+		active := w.checker.IsActive()
+		if active && process == nil {
+			logger.Info("starting")
+			process = ifrit.Background(w.runner)
+			exited = process.Wait()
+		} else if !active && process != nil {
+			logger.Info("stopping-on-standby")
+			stop()
+		}
+
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+
+		case err := <-exited:
+			process, exited = nil, nil
+			if err != nil {
+				logger.Error("exited", err)
+			}
+			return err
+
+		case <-check.C():
+		}
+	}
+}
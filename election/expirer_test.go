@@ -0,0 +1,213 @@
+package election_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/election"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("ExpirerLockDB", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeLockDB *dbfakes.FakeLockDB
+		active     bool
+		lockDB     db.LockDB
+		resource   *models.Resource
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("expirer")
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		lockDB = election.NewExpirerLockDB(fakeLockDB, election.ActiveFunc(func() bool { return active }))
+		resource = &models.Resource{Key: "key", Owner: "owner"}
+	})
+
+	Context("when the server is active", func() {
+		BeforeEach(func() {
+			active = true
+		})
+
+		It("expires locks", func() {
+			Expect(lockDB.Release(logger, resource)).To(Succeed())
+			_, err := lockDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the server is on standby", func() {
+		BeforeEach(func() {
+			active = false
+		})
+
+		It("does not expire locks", func() {
+			Expect(lockDB.Release(logger, resource)).To(Equal(election.ErrNotActive))
+			Expect(lockDB.ReleaseIf(logger, resource, db.ReleaseCondition{})).To(Equal(election.ErrNotActive))
+			_, err := lockDB.ExpireLocks(logger)
+			Expect(err).To(Equal(election.ErrNotActive))
+
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
+			Expect(fakeLockDB.ReleaseIfCallCount()).To(Equal(0))
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(0))
+		})
+
+		It("still reads locks", func() {
+			_, err := lockDB.FetchIncludingExpired(logger, "key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.FetchIncludingExpiredCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the server goes from standby to active", func() {
+		var (
+			fakeClock   *fakeclock.FakeClock
+			isActive    int32
+			expirerDB   db.LockDB
+			lockPick    expiration.LockPick
+			expiring    *db.Lock
+			burglar     ifrit.Runner
+			whileActive ifrit.Process
+		)
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+			atomic.StoreInt32(&isActive, 0)
+			checker := election.ActiveFunc(func() bool { return atomic.LoadInt32(&isActive) == 1 })
+			expirerDB = election.NewExpirerLockDB(fakeLockDB, checker)
+
+			expiring = &db.Lock{Resource: resource, ModifiedIndex: 1, ModifiedId: "id", TtlInSeconds: 5}
+			fakeLockDB.FetchIncludingExpiredReturns(expiring, nil)
+
+			lockPick = expiration.NewLockPick(expirerDB, fakeClock, new(mfakes.FakeIngressClient), nil)
+			burglar = expiration.NewBurglar(logger, expirerDB, lockPick, fakeClock, new(mfakes.FakeIngressClient), nil, time.Minute)
+			whileActive = ginkgomon.Invoke(election.WhileActive(logger, checker, burglar, fakeClock, time.Second))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(whileActive)
+		})
+
+		It("expires the locks that came due on standby once it is active", func() {
+			lockPick.RegisterTTL(logger, expiring)
+			Eventually(fakeClock.WatcherCount).Should(Equal(2))
+
+			fakeClock.Increment(5 * time.Second)
+			Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+			Consistently(fakeLockDB.ReleaseCallCount).Should(Equal(0))
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(0))
+
+			atomic.StoreInt32(&isActive, 1)
+			Eventually(func() int {
+				fakeClock.Increment(time.Second)
+				return fakeLockDB.ReleaseCallCount()
+			}).Should(Equal(1))
+			Eventually(lockPick.RegisteredTTLs).Should(BeEmpty())
+
+			// the burglar expires the locks that expired before it started,
+			// which FetchAll leaves out
+			Eventually(fakeLockDB.ExpireLocksCallCount).Should(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("WhileActive", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		active    int32
+		runs      int32
+		stops     chan struct{}
+		exitErr   chan error
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("while-active")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		atomic.StoreInt32(&active, 1)
+		atomic.StoreInt32(&runs, 0)
+		stops = make(chan struct{}, 10)
+		exitErr = make(chan error, 1)
+	})
+
+	JustBeforeEach(func() {
+		runner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			atomic.AddInt32(&runs, 1)
+			close(ready)
+			select {
+			case <-signals:
+				stops <- struct{}{}
+				return nil
+			case err := <-exitErr:
+				return err
+			}
+		})
+		checker := election.ActiveFunc(func() bool { return atomic.LoadInt32(&active) == 1 })
+		process = ginkgomon.Invoke(election.WhileActive(logger, checker, runner, fakeClock, time.Second))
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	runCount := func() int32 {
+		return atomic.LoadInt32(&runs)
+	}
+
+	It("runs the runner while the server is active", func() {
+		Eventually(runCount).Should(BeEquivalentTo(1))
+	})
+
+	It("stops the runner on standby and starts it again once active", func() {
+		Eventually(runCount).Should(BeEquivalentTo(1))
+
+		atomic.StoreInt32(&active, 0)
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(stops).Should(Receive())
+
+		atomic.StoreInt32(&active, 1)
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(runCount).Should(BeEquivalentTo(2))
+	})
+
+	It("stops the runner when signalled", func() {
+		Eventually(runCount).Should(BeEquivalentTo(1))
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(stops).To(Receive())
+	})
+
+	Context("when the server starts on standby", func() {
+		BeforeEach(func() {
+			atomic.StoreInt32(&active, 0)
+		})
+
+		It("does not run the runner", func() {
+			Consistently(runCount).Should(BeEquivalentTo(0))
+		})
+	})
+
+	Context("when the runner exits", func() {
+		It("exits with its error", func() {
+			Eventually(runCount).Should(BeEquivalentTo(1))
+			exitErr <- errors.New("boom")
+			Eventually(process.Wait()).Should(Receive(MatchError("boom")))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+)
+
+const (
+	CreatedEvent  = db.LockCreatedEvent
+	ReleasedEvent = db.LockReleasedEvent
+	ExpiredEvent  = db.LockExpiredEvent
+
+	UnknownLockType = "unknown"
+)
+
+// Event describes a lock or presence that was created, released or expired.
+type Event struct {
+	Type         string `json:"type"`
+	Key          string `json:"key"`
+	Owner        string `json:"owner"`
+	Value        string `json:"value,omitempty"`
+	LockType     string `json:"lock_type"`
+	FencingToken int64  `json:"fencing_token,omitempty"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+//go:generate counterfeiter . Sink
+
+// Sink receives events. Emit is called while the lock operation that caused
+// the event is being handled, so it must not block, and it cannot fail the
+// operation.
+type Sink interface {
+	Emit(event Event)
+}
+
+//go:generate counterfeiter . Deliverer
+
+// Deliverer is a Sink that can also deliver an event synchronously, and
+// report whether it was delivered. The outbox dispatcher delivers through it
+// so that events that were not delivered stay in the outbox.
+type Deliverer interface {
+	Sink
+	Deliver(event Event) error
+}
+
+// Sinks emits every event to each of its sinks.
+type Sinks []Sink
+
+var _ Deliverer = Sinks{}
+
+func (s Sinks) Emit(event Event) {
+	for _, sink := range s {
+		sink.Emit(event)
+	}
+}
+
+// Deliver delivers event to each of its sinks that is a Deliverer, and emits
+// it to the others. It returns the first error, after trying every sink, so
+// a sink that did receive the event receives it again when it is retried.
+func (s Sinks) Deliver(event Event) error {
+	var firstErr error
+	for _, sink := range s {
+		deliverer, ok := sink.(Deliverer)
+		if !ok {
+			sink.Emit(event)
+			continue
+		}
+
+		err := deliverer.Deliver(event)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// NewLockDB wraps lockDB so that the exclusive locks and presences it
+// creates, releases or expires are emitted to sink. Shared holds and
+// semaphore slots are not emitted.
+func NewLockDB(lockDB db.LockDB, clock clock.Clock, sink Sink) db.LockDB {
+	return &lockDB{LockDB: lockDB, clock: clock, sink: sink, releaseEvent: ReleasedEvent}
+}
+
+// NewExpirerLockDB is like NewLockDB, but emits its releases as
+// expirations. It is for the lock pick, which expires locks by releasing
+// them.
+func NewExpirerLockDB(lockDB db.LockDB, clock clock.Clock, sink Sink) db.LockDB {
+	return &lockDB{LockDB: lockDB, clock: clock, sink: sink, releaseEvent: ExpiredEvent}
+}
+
+func newEvent(eventType string, lock *db.Lock, timestamp int64) Event {
+	lockType := models.GetType(lock.Resource)
+	if lockType == "" {
+		lockType = UnknownLockType
+	}
+
+	return Event{
+		Type:         eventType,
+		Key:          lock.GetKey(),
+		Owner:        lock.GetOwner(),
+		Value:        lock.GetValue(),
+		LockType:     lockType,
+		FencingToken: lock.FencingToken,
+		Timestamp:    timestamp,
+	}
+}
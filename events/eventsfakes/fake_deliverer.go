@@ -0,0 +1,107 @@
+// This file was generated by counterfeiter
+package eventsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/locket/events"
+)
+
+type FakeDeliverer struct {
+	EmitStub        func(event events.Event)
+	emitMutex       sync.RWMutex
+	emitArgsForCall []struct {
+		event events.Event
+	}
+	DeliverStub        func(event events.Event) error
+	deliverMutex       sync.RWMutex
+	deliverArgsForCall []struct {
+		event events.Event
+	}
+	deliverReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDeliverer) Emit(event events.Event) {
+	fake.emitMutex.Lock()
+	fake.emitArgsForCall = append(fake.emitArgsForCall, struct {
+		event events.Event
+	}{event})
+	fake.recordInvocation("Emit", []interface{}{event})
+	fake.emitMutex.Unlock()
+	if fake.EmitStub != nil {
+		fake.EmitStub(event)
+	}
+}
+
+func (fake *FakeDeliverer) EmitCallCount() int {
+	fake.emitMutex.RLock()
+	defer fake.emitMutex.RUnlock()
+	return len(fake.emitArgsForCall)
+}
+
+func (fake *FakeDeliverer) EmitArgsForCall(i int) events.Event {
+	fake.emitMutex.RLock()
+	defer fake.emitMutex.RUnlock()
+	return fake.emitArgsForCall[i].event
+}
+
+func (fake *FakeDeliverer) Deliver(event events.Event) error {
+	fake.deliverMutex.Lock()
+	fake.deliverArgsForCall = append(fake.deliverArgsForCall, struct {
+		event events.Event
+	}{event})
+	fake.recordInvocation("Deliver", []interface{}{event})
+	fake.deliverMutex.Unlock()
+	if fake.DeliverStub != nil {
+		return fake.DeliverStub(event)
+	} else {
+		return fake.deliverReturns.result1
+	}
+}
+
+func (fake *FakeDeliverer) DeliverCallCount() int {
+	fake.deliverMutex.RLock()
+	defer fake.deliverMutex.RUnlock()
+	return len(fake.deliverArgsForCall)
+}
+
+func (fake *FakeDeliverer) DeliverArgsForCall(i int) events.Event {
+	fake.deliverMutex.RLock()
+	defer fake.deliverMutex.RUnlock()
+	return fake.deliverArgsForCall[i].event
+}
+
+func (fake *FakeDeliverer) DeliverReturns(result1 error) {
+	fake.DeliverStub = nil
+	fake.deliverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDeliverer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.emitMutex.RLock()
+	defer fake.emitMutex.RUnlock()
+	fake.deliverMutex.RLock()
+	defer fake.deliverMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDeliverer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ events.Deliverer = new(FakeDeliverer)
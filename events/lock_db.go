@@ -0,0 +1,92 @@
+package events
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+)
+
+type lockDB struct {
+	db.LockDB
+	clock        clock.Clock
+	sink         Sink
+	releaseEvent string
+}
+
+func (l *lockDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	start := l.clock.Now()
+	lock, err := l.LockDB.Lock(logger, resource, ttl)
+	l.emitIfCreated(start, lock, err)
+	return lock, err
+}
+
+func (l *lockDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	start := l.clock.Now()
+	lock, err := l.LockDB.LockWithGrace(logger, resource, ttl, grace)
+	l.emitIfCreated(start, lock, err)
+	return lock, err
+}
+
+func (l *lockDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error) {
+	start := l.clock.Now()
+	locks, err := l.LockDB.LockGroup(logger, resources, ttl)
+	if err == nil {
+		for _, lock := range locks {
+			l.emitIfCreated(start, lock, nil)
+		}
+	}
+	return locks, err
+}
+
+func (l *lockDB) Release(logger lager.Logger, resource *models.Resource) error {
+	held := l.held(logger, resource)
+	err := l.LockDB.Release(logger, resource)
+	if err == nil && held != nil {
+		l.emit(l.releaseEvent, held)
+	}
+	return err
+}
+
+func (l *lockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	held := l.held(logger, resource)
+	err := l.LockDB.ReleaseIf(logger, resource, condition)
+	if err == nil && held != nil {
+		l.emit(l.releaseEvent, held)
+	}
+	return err
+}
+
+func (l *lockDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	locks, err := l.LockDB.ExpireLocks(logger)
+	for _, lock := range locks {
+		l.emit(ExpiredEvent, lock)
+	}
+	return locks, err
+}
+
+func (l *lockDB) emit(eventType string, lock *db.Lock) {
+	l.sink.Emit(newEvent(eventType, lock, l.clock.Now().UnixNano()))
+}
+
+// emitIfCreated emits lock if it was acquired, rather than refreshed, since
+// start. A refreshed lock keeps the time its owner acquired it.
+func (l *lockDB) emitIfCreated(start time.Time, lock *db.Lock, err error) {
+	if err != nil || lock == nil || lock.AcquiredAt < start.UnixNano() {
+		return
+	}
+	l.emit(CreatedEvent, lock)
+}
+
+// held returns the lock on resource if resource's owner holds it, so that a
+// release can be emitted with what was stored, which a release request does
+// not carry.
+func (l *lockDB) held(logger lager.Logger, resource *models.Resource) *db.Lock {
+	lock, err := l.LockDB.Fetch(logger, resource.GetKey())
+	if err != nil || lock.Owner != resource.GetOwner() {
+		return nil
+	}
+	return lock
+}
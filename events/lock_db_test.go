@@ -0,0 +1,178 @@
+package events_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/events"
+	"code.cloudfoundry.org/locket/events/eventsfakes"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LockDB", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		fakeLockDB *dbfakes.FakeLockDB
+		fakeSink   *eventsfakes.FakeSink
+		lockDB     db.LockDB
+		resource   *models.Resource
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("events")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		fakeSink = &eventsfakes.FakeSink{}
+		lockDB = events.NewLockDB(fakeLockDB, fakeClock, fakeSink)
+		resource = &models.Resource{Key: "cell-1", Owner: "rep-1", Value: "10.0.0.1", Type: models.PresenceType, TypeCode: models.PRESENCE}
+	})
+
+	Describe("Lock", func() {
+		It("emits a lock acquired by the call", func() {
+			fakeLockDB.LockReturns(&db.Lock{Resource: resource, FencingToken: 3, AcquiredAt: fakeClock.Now().UnixNano()}, nil)
+
+			_, err := lockDB.Lock(logger, resource, 15*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSink.EmitCallCount()).To(Equal(1))
+			Expect(fakeSink.EmitArgsForCall(0)).To(Equal(events.Event{
+				Type:         events.CreatedEvent,
+				Key:          "cell-1",
+				Owner:        "rep-1",
+				Value:        "10.0.0.1",
+				LockType:     models.PresenceType,
+				FencingToken: 3,
+				Timestamp:    fakeClock.Now().UnixNano(),
+			}))
+		})
+
+		It("does not emit a refresh", func() {
+			fakeLockDB.LockReturns(&db.Lock{Resource: resource, AcquiredAt: fakeClock.Now().Add(-time.Second).UnixNano()}, nil)
+
+			_, err := lockDB.Lock(logger, resource, 15*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSink.EmitCallCount()).To(Equal(0))
+		})
+
+		It("does not emit a collision", func() {
+			fakeLockDB.LockReturns(&db.Lock{Resource: resource, AcquiredAt: fakeClock.Now().UnixNano()}, models.ErrLockCollision)
+
+			_, err := lockDB.Lock(logger, resource, 15*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(fakeSink.EmitCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("LockGroup", func() {
+		It("emits each lock acquired by the call", func() {
+			other := &models.Resource{Key: "cell-2", Owner: "rep-1", TypeCode: models.LOCK}
+			fakeLockDB.LockGroupReturns([]*db.Lock{
+				{Resource: resource, AcquiredAt: fakeClock.Now().Add(-time.Second).UnixNano()},
+				{Resource: other, AcquiredAt: fakeClock.Now().UnixNano()},
+			}, nil)
+
+			_, err := lockDB.LockGroup(logger, []*models.Resource{resource, other}, 15*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSink.EmitCallCount()).To(Equal(1))
+			event := fakeSink.EmitArgsForCall(0)
+			Expect(event.Key).To(Equal("cell-2"))
+			Expect(event.LockType).To(Equal(models.LockType))
+		})
+	})
+
+	Describe("Release", func() {
+		It("emits the lock that was held", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: resource, FencingToken: 3}, nil)
+
+			err := lockDB.Release(logger, &models.Resource{Key: "cell-1", Owner: "rep-1"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSink.EmitCallCount()).To(Equal(1))
+			event := fakeSink.EmitArgsForCall(0)
+			Expect(event.Type).To(Equal(events.ReleasedEvent))
+			Expect(event.LockType).To(Equal(models.PresenceType))
+			Expect(event.Value).To(Equal("10.0.0.1"))
+			Expect(event.FencingToken).To(Equal(int64(3)))
+		})
+
+		It("does not emit a failed release", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: resource}, nil)
+			fakeLockDB.ReleaseIfReturns(models.ErrReleaseConditionFailed)
+
+			err := lockDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: "10.0.0.2"})
+			Expect(err).To(Equal(models.ErrReleaseConditionFailed))
+			Expect(fakeSink.EmitCallCount()).To(Equal(0))
+		})
+
+		It("does not emit when the owner did not hold the lock", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: resource}, nil)
+
+			err := lockDB.Release(logger, &models.Resource{Key: "cell-1", Owner: "rep-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeSink.EmitCallCount()).To(Equal(0))
+		})
+
+		It("emits expirations when releasing for the lock pick", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: resource}, nil)
+
+			err := events.NewExpirerLockDB(fakeLockDB, fakeClock, fakeSink).Release(logger, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSink.EmitArgsForCall(0).Type).To(Equal(events.ExpiredEvent))
+		})
+	})
+
+	Describe("ExpireLocks", func() {
+		It("emits every expired lock", func() {
+			fakeLockDB.ExpireLocksReturns([]*db.Lock{
+				{Resource: resource},
+				{Resource: &models.Resource{Key: "auctioneer", Owner: "auctioneer-0"}},
+			}, nil)
+
+			_, err := lockDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSink.EmitCallCount()).To(Equal(2))
+			Expect(fakeSink.EmitArgsForCall(0).Type).To(Equal(events.ExpiredEvent))
+			event := fakeSink.EmitArgsForCall(1)
+			Expect(event.Key).To(Equal("auctioneer"))
+			Expect(event.LockType).To(Equal(events.UnknownLockType))
+		})
+	})
+})
+
+var _ = Describe("Sinks", func() {
+	It("emits to every sink", func() {
+		first, second := &eventsfakes.FakeSink{}, &eventsfakes.FakeSink{}
+		event := events.Event{Type: events.CreatedEvent, Key: "key"}
+
+		events.Sinks{first, second}.Emit(event)
+
+		Expect(first.EmitArgsForCall(0)).To(Equal(event))
+		Expect(second.EmitArgsForCall(0)).To(Equal(event))
+	})
+
+	It("delivers to every deliverer and emits to the other sinks", func() {
+		emitter := &eventsfakes.FakeSink{}
+		failing, deliverer := &eventsfakes.FakeDeliverer{}, &eventsfakes.FakeDeliverer{}
+		failing.DeliverReturns(errors.New("boom"))
+		event := events.Event{Type: events.CreatedEvent, Key: "key"}
+
+		err := events.Sinks{emitter, failing, deliverer}.Deliver(event)
+		Expect(err).To(MatchError("boom"))
+
+		Expect(emitter.EmitArgsForCall(0)).To(Equal(event))
+		Expect(failing.DeliverArgsForCall(0)).To(Equal(event))
+		Expect(deliverer.DeliverArgsForCall(0)).To(Equal(event))
+		Expect(failing.EmitCallCount()).To(Equal(0))
+		Expect(deliverer.EmitCallCount()).To(Equal(0))
+	})
+})
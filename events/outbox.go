@@ -0,0 +1,156 @@
+package events
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"github.com/tedsuo/ifrit"
+)
+
+// outboxBatchSize is the most events taken from the outbox at once.
+const outboxBatchSize = 100
+
+type outboxDispatcher struct {
+	logger   lager.Logger
+	clock    clock.Clock
+	outbox   db.Outbox
+	sinks    []Sink
+	interval time.Duration
+
+	// delivered holds, for each sink, the ids of the events still in the
+	// outbox that it has received
+	delivered []map[int64]bool
+}
+
+// NewOutboxDispatcher returns a runner that delivers the events recorded in
+// outbox to sink every interval. Events recorded while no server was running
+// a dispatcher are delivered once one starts, and events sink fails to
+// deliver are delivered at a later interval. When sink is Sinks, each of its
+// sinks is delivered to on its own, so that one that fails does not hold up
+// the others.
+func NewOutboxDispatcher(logger lager.Logger, clock clock.Clock, outbox db.Outbox, sink Deliverer, interval time.Duration) ifrit.Runner {
+	sinks, ok := sink.(Sinks)
+	if !ok {
+		sinks = Sinks{sink}
+	}
+
+	delivered := make([]map[int64]bool, len(sinks))
+	for i := range delivered {
+		delivered[i] = make(map[int64]bool)
+	}
+
+	return &outboxDispatcher{
+		logger:    logger.Session("outbox-dispatcher"),
+		clock:     clock,
+		outbox:    outbox,
+		sinks:     sinks,
+		interval:  interval,
+		delivered: delivered,
+	}
+}
+
+func (d *outboxDispatcher) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	d.logger.Info("started")
+	defer d.logger.Info("complete")
+	close(ready)
+
+	ticker := d.clock.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch()
+
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+		}
+	}
+}
+
+// dispatch delivers every event in the outbox, a batch at a time, until an
+// event cannot be delivered.
+func (d *outboxDispatcher) dispatch() {
+	for {
+		count, err := d.outbox.DispatchEvents(d.logger, outboxBatchSize, d.deliver)
+		if err != nil {
+			d.logger.Error("failed-to-dispatch-events", err)
+			return
+		}
+		if count < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// deliver delivers events in order to each sink that has not received them
+// yet, and returns how many, from the first, every sink has received. A sink
+// is given none of the events after the first it fails to receive, so that
+// each key's events still reach it in order, but the other sinks carry on.
+func (d *outboxDispatcher) deliver(events []db.OutboxEvent) int {
+	if len(events) == 0 {
+		return 0
+	}
+
+	for i, sink := range d.sinks {
+		delivered := d.delivered[i]
+		for id := range delivered {
+			// events older than the oldest in the outbox have been deleted
+			if id < events[0].ID {
+				delete(delivered, id)
+			}
+		}
+
+		for _, event := range events {
+			if delivered[event.ID] {
+				continue
+			}
+
+			lock := &db.Lock{Resource: event.Resource, FencingToken: event.FencingToken}
+			err := deliverTo(sink, newEvent(event.Type, lock, event.Timestamp))
+			if err != nil {
+				d.logger.Error("failed-to-deliver-event", err, lager.Data{"type": event.Type, "key": event.Resource.GetKey()})
+				break
+			}
+			delivered[event.ID] = true
+		}
+	}
+
+	count := 0
+	for ; count < len(events); count++ {
+		if !d.deliveredToAll(events[count].ID) {
+			break
+		}
+	}
+
+	// the events every sink received are deleted from the outbox
+	for _, event := range events[:count] {
+		for _, delivered := range d.delivered {
+			delete(delivered, event.ID)
+		}
+	}
+	return count
+}
+
+func (d *outboxDispatcher) deliveredToAll(id int64) bool {
+	for _, delivered := range d.delivered {
+		if !delivered[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// deliverTo delivers event to sink if it is a Deliverer, and otherwise
+// emits it, which cannot fail.
+func deliverTo(sink Sink, event Event) error {
+	deliverer, ok := sink.(Deliverer)
+	if !ok {
+		sink.Emit(event)
+		return nil
+	}
+	return deliverer.Deliver(event)
+}
@@ -0,0 +1,193 @@
+package events_test
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/events"
+	"code.cloudfoundry.org/locket/events/eventsfakes"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("OutboxDispatcher", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		fakeOutbox *dbfakes.FakeOutbox
+		fakeSink   *eventsfakes.FakeDeliverer
+		sink       events.Deliverer
+		batches    chan []db.OutboxEvent
+		process    ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("events")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeOutbox = &dbfakes.FakeOutbox{}
+		fakeSink = &eventsfakes.FakeDeliverer{}
+		sink = fakeSink
+
+		batches = make(chan []db.OutboxEvent, 10)
+		fakeOutbox.DispatchEventsStub = func(logger lager.Logger, limit int, dispatch func([]db.OutboxEvent) int) (int, error) {
+			select {
+			case batch := <-batches:
+				return dispatch(batch), nil
+			default:
+				return 0, nil
+			}
+		}
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Invoke(events.NewOutboxDispatcher(logger, fakeClock, fakeOutbox, sink, time.Second))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	Context("when events were recorded before it started", func() {
+		BeforeEach(func() {
+			batches <- []db.OutboxEvent{{
+				ID:           1,
+				Type:         db.LockExpiredEvent,
+				Resource:     &models.Resource{Key: "cell-1", Owner: "rep-1", Value: "10.0.0.1", Type: models.PresenceType},
+				FencingToken: 3,
+				Timestamp:    1234,
+			}}
+		})
+
+		It("delivers them when it starts", func() {
+			Eventually(fakeSink.DeliverCallCount).Should(Equal(1))
+			Expect(fakeSink.DeliverArgsForCall(0)).To(Equal(events.Event{
+				Type:         events.ExpiredEvent,
+				Key:          "cell-1",
+				Owner:        "rep-1",
+				Value:        "10.0.0.1",
+				LockType:     models.PresenceType,
+				FencingToken: 3,
+				Timestamp:    1234,
+			}))
+		})
+	})
+
+	It("delivers events recorded later every interval", func() {
+		Eventually(fakeOutbox.DispatchEventsCallCount).Should(Equal(1))
+
+		batches <- []db.OutboxEvent{{ID: 2, Type: db.LockCreatedEvent, Resource: &models.Resource{Key: "leader", Owner: "bbs-1"}}}
+		Consistently(fakeSink.DeliverCallCount).Should(Equal(0))
+
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(fakeSink.DeliverCallCount).Should(Equal(1))
+		Expect(fakeSink.DeliverArgsForCall(0).LockType).To(Equal(events.UnknownLockType))
+	})
+
+	Context("when a batch is full", func() {
+		BeforeEach(func() {
+			full := make([]db.OutboxEvent, 100)
+			for i := range full {
+				full[i] = db.OutboxEvent{ID: int64(i + 1), Type: db.LockCreatedEvent, Resource: &models.Resource{Key: "cell"}}
+			}
+			batches <- full
+			batches <- full[:1]
+		})
+
+		It("takes the next batch straight away", func() {
+			Eventually(fakeSink.DeliverCallCount).Should(Equal(101))
+			Expect(fakeOutbox.DispatchEventsCallCount()).To(Equal(2))
+			_, limit, _ := fakeOutbox.DispatchEventsArgsForCall(0)
+			Expect(limit).To(Equal(100))
+		})
+	})
+
+	Context("when an event cannot be delivered", func() {
+		BeforeEach(func() {
+			fakeSink.DeliverStub = func(event events.Event) error {
+				if event.Key == "b" {
+					return errors.New("unreachable")
+				}
+				return nil
+			}
+
+			batch := []db.OutboxEvent{}
+			for i, key := range []string{"a", "b", "c"} {
+				batch = append(batch, db.OutboxEvent{ID: int64(i + 1), Type: db.LockCreatedEvent, Resource: &models.Resource{Key: key}})
+			}
+			batches <- batch
+		})
+
+		It("reports only the events before it as delivered and waits for the next interval", func() {
+			Eventually(logger).Should(gbytes.Say("failed-to-deliver-event"))
+			Expect(fakeSink.DeliverCallCount()).To(Equal(2))
+			Expect(fakeSink.DeliverArgsForCall(1).Key).To(Equal("b"))
+			Consistently(fakeOutbox.DispatchEventsCallCount).Should(Equal(1))
+		})
+	})
+
+	Context("when one of several sinks cannot receive an event", func() {
+		var healthySink *eventsfakes.FakeDeliverer
+		var batch []db.OutboxEvent
+		var unreachable int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&unreachable, 1)
+			batch = nil
+			fakeSink.DeliverStub = func(event events.Event) error {
+				if event.Key == "b" && atomic.LoadInt32(&unreachable) == 1 {
+					return errors.New("unreachable")
+				}
+				return nil
+			}
+			healthySink = &eventsfakes.FakeDeliverer{}
+			sink = events.Sinks{fakeSink, healthySink}
+
+			for i, key := range []string{"a", "b", "c"} {
+				batch = append(batch, db.OutboxEvent{ID: int64(i + 1), Type: db.LockCreatedEvent, Resource: &models.Resource{Key: key}})
+			}
+			batches <- batch
+		})
+
+		It("still delivers every event to the other sinks", func() {
+			Eventually(healthySink.DeliverCallCount).Should(Equal(3))
+			Expect(fakeSink.DeliverCallCount()).To(Equal(2))
+		})
+
+		It("delivers only the events they have not received when the events are dispatched again", func() {
+			Eventually(healthySink.DeliverCallCount).Should(Equal(3))
+
+			atomic.StoreInt32(&unreachable, 0)
+			batches <- batch[1:]
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+
+			Eventually(fakeSink.DeliverCallCount).Should(Equal(4))
+			Expect(fakeSink.DeliverArgsForCall(2).Key).To(Equal("b"))
+			Expect(fakeSink.DeliverArgsForCall(3).Key).To(Equal("c"))
+			Consistently(healthySink.DeliverCallCount).Should(Equal(3))
+		})
+	})
+
+	Context("when the outbox cannot be read", func() {
+		BeforeEach(func() {
+			fakeOutbox.DispatchEventsReturns(0, errors.New("boom"))
+		})
+
+		It("logs the error and tries again at the next interval", func() {
+			Eventually(logger).Should(gbytes.Say("failed-to-dispatch-events"))
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(fakeOutbox.DispatchEventsCallCount).Should(Equal(2))
+		})
+	})
+})
@@ -0,0 +1 @@
+package events // import "code.cloudfoundry.org/locket/events"
@@ -0,0 +1,133 @@
+package expiration_test
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// benchmarkKeys is how many locks are being refreshed, as by a deployment
+// of this many cells.
+const benchmarkKeys = 10000
+
+// BenchmarkLockPickRefresh measures registering the refreshed locks of
+// benchmarkKeys holders with the lock pick, which replaces the goroutine
+// watching the previous registration of the key.
+func BenchmarkLockPickRefresh(b *testing.B) {
+	lockPick := expiration.NewLockPick(&dbfakes.FakeLockDB{}, clock.NewClock(), &mfakes.FakeIngressClient{}, nil)
+	benchmarkRefresh(b, func(lock *db.Lock) {
+		lockPick.RegisterTTL(lager.NewLogger("benchmark"), lock)
+	})
+}
+
+// BenchmarkHeapSchedulerRefresh runs the same workload against heapScheduler,
+// a prototype of the proposed redesign of the lock pick that keeps every
+// expiry in one heap served by a single timer, rather than a goroutine and
+// timer per lock.
+func BenchmarkHeapSchedulerRefresh(b *testing.B) {
+	scheduler := newHeapScheduler(clock.NewClock())
+	defer scheduler.timer.Stop()
+	benchmarkRefresh(b, scheduler.register)
+}
+
+func benchmarkRefresh(b *testing.B, register func(*db.Lock)) {
+	locks := make([]*db.Lock, benchmarkKeys)
+	for i := range locks {
+		locks[i] = &db.Lock{
+			Resource:     &models.Resource{Key: fmt.Sprintf("cell-%05d", i), Owner: "owner", Type: models.PresenceType},
+			TtlInSeconds: 3600,
+			ModifiedId:   "guid",
+		}
+		register(locks[i])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lock := locks[i%benchmarkKeys]
+		lock.ModifiedIndex++
+		register(lock)
+	}
+}
+
+type heapScheduler struct {
+	clock   clock.Clock
+	mutex   sync.Mutex
+	timer   clock.Timer
+	entries map[string]*expiry
+	expiries
+}
+
+type expiry struct {
+	key       string
+	index     int64
+	expiresAt time.Time
+	position  int
+}
+
+func newHeapScheduler(clock clock.Clock) *heapScheduler {
+	return &heapScheduler{
+		clock:   clock,
+		timer:   clock.NewTimer(time.Hour),
+		entries: make(map[string]*expiry),
+	}
+}
+
+func (s *heapScheduler) register(lock *db.Lock) {
+	now := s.clock.Now()
+	expiresAt := now.Add(lock.TTL())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.entries[lock.Key]
+	switch {
+	case !ok:
+		e = &expiry{key: lock.Key, index: lock.ModifiedIndex, expiresAt: expiresAt}
+		s.entries[lock.Key] = e
+		heap.Push(&s.expiries, e)
+	case e.index >= lock.ModifiedIndex:
+		return
+	default:
+		e.index = lock.ModifiedIndex
+		e.expiresAt = expiresAt
+		heap.Fix(&s.expiries, e.position)
+	}
+
+	if s.expiries[0] == e {
+		s.timer.Reset(expiresAt.Sub(now))
+	}
+}
+
+type expiries []*expiry
+
+func (e expiries) Len() int           { return len(e) }
+func (e expiries) Less(i, j int) bool { return e[i].expiresAt.Before(e[j].expiresAt) }
+func (e expiries) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+	e[i].position = i
+	e[j].position = j
+}
+
+func (e *expiries) Push(x interface{}) {
+	entry := x.(*expiry)
+	entry.position = len(*e)
+	*e = append(*e, entry)
+}
+
+func (e *expiries) Pop() interface{} {
+	old := *e
+	entry := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return entry
+}
@@ -7,22 +7,34 @@ import (
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/tracing"
+	"golang.org/x/net/context"
 )
 
+// burglar registers every lock in the database with the lock pick, so that
+// locks this server did not see refreshed are still expired. FetchAll leaves
+// out the locks that have already expired, such as those that expired while
+// no server was expiring locks, so the burglar first expires those itself.
 type burglar struct {
 	logger        lager.Logger
 	lockDB        db.LockDB
 	lockPick      LockPick
 	clock         clock.Clock
+	metronClient  emitter.Emitter
+	tagger        *metrics.KeyTagger
 	checkInterval time.Duration
 }
 
-func NewBurglar(logger lager.Logger, lockDB db.LockDB, lockPick LockPick, clock clock.Clock, checkInterval time.Duration) burglar {
+func NewBurglar(logger lager.Logger, lockDB db.LockDB, lockPick LockPick, clock clock.Clock, metronClient emitter.Emitter, tagger *metrics.KeyTagger, checkInterval time.Duration) burglar {
 	return burglar{
 		logger:        logger,
 		lockDB:        lockDB,
 		lockPick:      lockPick,
 		clock:         clock,
+		metronClient:  metronClient,
+		tagger:        tagger,
 		checkInterval: checkInterval,
 	}
 }
@@ -33,16 +45,11 @@ func (b burglar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	logger.Info("started")
 	defer logger.Info("complete")
 
-	locks, err := b.lockDB.FetchAll(logger, "")
-	if err != nil {
-		logger.Error("failed-fetching-locks", err)
-	}
-
-	for _, lock := range locks {
-		b.lockPick.RegisterTTL(logger, lock)
-	}
+	expired := b.expireLocks(logger)
+	b.registerLocks(logger)
 
 	check := b.clock.NewTicker(b.checkInterval)
+	defer check.Stop()
 
 	close(ready)
 
@@ -52,17 +59,39 @@ func (b burglar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 			logger.Info("signalled", lager.Data{"signal": sig})
 			return nil
 		case <-check.C():
-			locks, err := b.lockDB.FetchAll(logger, "")
-			if err != nil {
-				logger.Error("failed-fetching-locks", err)
-				continue
-			}
-
-			for _, lock := range locks {
-				b.lockPick.RegisterTTL(logger, lock)
+			if !expired {
+				expired = b.expireLocks(logger)
 			}
+			b.registerLocks(logger)
 		}
 	}
+}
+
+// expireLocks expires the locks that had already expired when the burglar
+// started, and returns false if it has to try again.
+func (b burglar) expireLocks(logger lager.Logger) bool {
+	_, span := tracing.StartSpan(context.Background(), "expiration.expire-stale-locks")
+	locks, err := b.lockDB.ExpireLocks(logger)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		logger.Error("failed-expiring-locks", err)
+		return false
+	}
 
-	return nil
+	for _, lock := range locks {
+		incrementExpiredCounter(logger, b.metronClient, b.tagger, lock)
+	}
+	return true
+}
+
+func (b burglar) registerLocks(logger lager.Logger) {
+	locks, err := b.lockDB.FetchAll(logger, "")
+	if err != nil {
+		logger.Error("failed-fetching-locks", err)
+		return
+	}
+
+	for _, lock := range locks {
+		b.lockPick.RegisterTTL(logger, lock)
+	}
 }
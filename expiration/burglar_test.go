@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/locket/db"
 	"code.cloudfoundry.org/locket/db/dbfakes"
@@ -23,10 +24,11 @@ var _ = Describe("Burglar", func() {
 		runner  ifrit.Runner
 		process ifrit.Process
 
-		fakeLockDB   *dbfakes.FakeLockDB
-		fakeLockPick *expirationfakes.FakeLockPick
-		fakeClock    *fakeclock.FakeClock
-		logger       *lagertest.TestLogger
+		fakeLockDB       *dbfakes.FakeLockDB
+		fakeLockPick     *expirationfakes.FakeLockPick
+		fakeClock        *fakeclock.FakeClock
+		logger           *lagertest.TestLogger
+		fakeMetronClient *mfakes.FakeIngressClient
 
 		expectedLock1, expectedLock2 *db.Lock
 		checkInterval                time.Duration
@@ -37,6 +39,7 @@ var _ = Describe("Burglar", func() {
 		fakeLockPick = &expirationfakes.FakeLockPick{}
 		fakeClock = fakeclock.NewFakeClock(time.Now())
 		logger = lagertest.NewTestLogger("expiration")
+		fakeMetronClient = new(mfakes.FakeIngressClient)
 
 		expectedLock1 = &db.Lock{
 			Resource: &models.Resource{
@@ -64,7 +67,7 @@ var _ = Describe("Burglar", func() {
 	})
 
 	JustBeforeEach(func() {
-		runner = expiration.NewBurglar(logger, fakeLockDB, fakeLockPick, fakeClock, checkInterval)
+		runner = expiration.NewBurglar(logger, fakeLockDB, fakeLockPick, fakeClock, fakeMetronClient, nil, checkInterval)
 		process = ifrit.Background(runner)
 	})
 
@@ -125,4 +128,49 @@ var _ = Describe("Burglar", func() {
 			Eventually(logger).Should(gbytes.Say("failed-fetching-locks"))
 		})
 	})
+
+	Context("when locks expired before the burglar started", func() {
+		BeforeEach(func() {
+			fakeLockDB.ExpireLocksReturns([]*db.Lock{
+				{Resource: &models.Resource{Key: "dead-cell", Owner: "cell-0", Type: models.PresenceType}},
+			}, nil)
+		})
+
+		It("expires them before registering the others, since FetchAll leaves them out", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(1))
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(1))
+
+			Eventually(fakeMetronClient.IncrementCounterCallCount).Should(Equal(1))
+			Expect(incrementedCounters(fakeMetronClient)).To(ConsistOf("PresenceExpired"))
+		})
+
+		It("only expires them once", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			fakeClock.Increment(checkInterval)
+			Eventually(fakeLockDB.FetchAllCallCount).Should(Equal(2))
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when expiring the locks that already expired fails", func() {
+		BeforeEach(func() {
+			fakeLockDB.ExpireLocksReturns(nil, errors.New("not active"))
+		})
+
+		It("tries again on the next check", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+			Eventually(logger).Should(gbytes.Say("failed-expiring-locks"))
+			Eventually(fakeLockPick.RegisterTTLCallCount).Should(Equal(2))
+
+			fakeLockDB.ExpireLocksReturns(nil, nil)
+			fakeClock.Increment(checkInterval)
+			Eventually(fakeLockDB.ExpireLocksCallCount).Should(Equal(2))
+
+			fakeClock.Increment(checkInterval)
+			Eventually(fakeLockDB.FetchAllCallCount).Should(Equal(3))
+			Expect(fakeLockDB.ExpireLocksCallCount()).To(Equal(2))
+		})
+	})
 })
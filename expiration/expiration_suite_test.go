@@ -1,6 +1,7 @@
 package expiration_test
 
 import (
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -11,3 +12,11 @@ func TestExpiration(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Expiration Suite")
 }
+
+func incrementedCounters(metronClient *mfakes.FakeIngressClient) []string {
+	names := []string{}
+	for i := 0; i < metronClient.IncrementCounterCallCount(); i++ {
+		names = append(names, metronClient.IncrementCounterArgsForCall(i))
+	}
+	return names
+}
@@ -16,6 +16,19 @@ type FakeLockPick struct {
 		logger lager.Logger
 		lock   *db.Lock
 	}
+	RestoreTTLStub        func(logger lager.Logger, ttl expiration.RegisteredTTL)
+	restoreTTLMutex       sync.RWMutex
+	restoreTTLArgsForCall []struct {
+		logger lager.Logger
+		ttl    expiration.RegisteredTTL
+	}
+	RegisteredTTLsStub        func() []expiration.RegisteredTTL
+	registeredTTLsMutex       sync.RWMutex
+	registeredTTLsArgsForCall []struct {
+	}
+	registeredTTLsReturns struct {
+		result1 []expiration.RegisteredTTL
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -45,11 +58,66 @@ func (fake *FakeLockPick) RegisterTTLArgsForCall(i int) (lager.Logger, *db.Lock)
 	return fake.registerTTLArgsForCall[i].logger, fake.registerTTLArgsForCall[i].lock
 }
 
+func (fake *FakeLockPick) RestoreTTL(logger lager.Logger, ttl expiration.RegisteredTTL) {
+	fake.restoreTTLMutex.Lock()
+	fake.restoreTTLArgsForCall = append(fake.restoreTTLArgsForCall, struct {
+		logger lager.Logger
+		ttl    expiration.RegisteredTTL
+	}{logger, ttl})
+	fake.recordInvocation("RestoreTTL", []interface{}{logger, ttl})
+	fake.restoreTTLMutex.Unlock()
+	if fake.RestoreTTLStub != nil {
+		fake.RestoreTTLStub(logger, ttl)
+	}
+}
+
+func (fake *FakeLockPick) RestoreTTLCallCount() int {
+	fake.restoreTTLMutex.RLock()
+	defer fake.restoreTTLMutex.RUnlock()
+	return len(fake.restoreTTLArgsForCall)
+}
+
+func (fake *FakeLockPick) RestoreTTLArgsForCall(i int) (lager.Logger, expiration.RegisteredTTL) {
+	fake.restoreTTLMutex.RLock()
+	defer fake.restoreTTLMutex.RUnlock()
+	return fake.restoreTTLArgsForCall[i].logger, fake.restoreTTLArgsForCall[i].ttl
+}
+
+func (fake *FakeLockPick) RegisteredTTLs() []expiration.RegisteredTTL {
+	fake.registeredTTLsMutex.Lock()
+	fake.registeredTTLsArgsForCall = append(fake.registeredTTLsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("RegisteredTTLs", []interface{}{})
+	fake.registeredTTLsMutex.Unlock()
+	if fake.RegisteredTTLsStub != nil {
+		return fake.RegisteredTTLsStub()
+	} else {
+		return fake.registeredTTLsReturns.result1
+	}
+}
+
+func (fake *FakeLockPick) RegisteredTTLsCallCount() int {
+	fake.registeredTTLsMutex.RLock()
+	defer fake.registeredTTLsMutex.RUnlock()
+	return len(fake.registeredTTLsArgsForCall)
+}
+
+func (fake *FakeLockPick) RegisteredTTLsReturns(result1 []expiration.RegisteredTTL) {
+	fake.RegisteredTTLsStub = nil
+	fake.registeredTTLsReturns = struct {
+		result1 []expiration.RegisteredTTL
+	}{result1}
+}
+
 func (fake *FakeLockPick) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.registerTTLMutex.RLock()
 	defer fake.registerTTLMutex.RUnlock()
+	fake.restoreTTLMutex.RLock()
+	defer fake.restoreTTLMutex.RUnlock()
+	fake.registeredTTLsMutex.RLock()
+	defer fake.registeredTTLsMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -1,36 +1,67 @@
 package expiration
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/emitter"
 	"code.cloudfoundry.org/locket/models"
-	"code.cloudfoundry.org/runtimeschema/metric"
+	"code.cloudfoundry.org/locket/tracing"
+	"golang.org/x/net/context"
 )
 
 const (
-	locksExpired    = metric.Counter("LocksExpired")
-	presenceExpired = metric.Counter("PresenceExpired")
+	locksExpired    = "LocksExpired"
+	presenceExpired = "PresenceExpired"
+
+	// releaseRetryInterval is how long the lock pick waits to try again to
+	// expire a lock that it could not release, such as while the server is
+	// on standby or a backup pauses expiration
+	releaseRetryInterval = 5 * time.Second
 )
 
 //go:generate counterfeiter . LockPick
 type LockPick interface {
 	RegisterTTL(logger lager.Logger, lock *db.Lock)
+	// RestoreTTL watches a lock registered with another server, such as the
+	// process this one replaced, until the time it was due to expire there.
+	// It replaces a registration of the same lock that is due to expire
+	// later, since the other server saw it refreshed more recently.
+	RestoreTTL(logger lager.Logger, ttl RegisteredTTL)
+	RegisteredTTLs() []RegisteredTTL
+}
+
+// RegisteredTTL describes a lock whose expiration is currently being
+// watched in memory.
+type RegisteredTTL struct {
+	Key               string    `json:"key"`
+	Owner             string    `json:"owner"`
+	Type              string    `json:"type"`
+	ModifiedId        string    `json:"modified_id"`
+	ModifiedIndex     int64     `json:"modified_index"`
+	TtlInSeconds      int64     `json:"ttl_in_seconds"`
+	TtlInMilliseconds int64     `json:"ttl_in_milliseconds"`
+	ExpiresAt         time.Time `json:"expires_at"`
 }
 
 type lockPick struct {
-	lockDB    db.LockDB
-	clock     clock.Clock
-	lockTTLs  map[checkKey]chanAndIndex
-	lockMutex *sync.Mutex
+	lockDB       db.LockDB
+	clock        clock.Clock
+	metronClient emitter.Emitter
+	tagger       *metrics.KeyTagger
+	lockTTLs     map[checkKey]chanAndIndex
+	lockMutex    *sync.Mutex
 }
 
 type chanAndIndex struct {
 	channel chan struct{}
 	index   int64
+	ttl     RegisteredTTL
 }
 
 type checkKey struct {
@@ -38,12 +69,14 @@ type checkKey struct {
 	id  string
 }
 
-func NewLockPick(lockDB db.LockDB, clock clock.Clock) lockPick {
+func NewLockPick(lockDB db.LockDB, clock clock.Clock, metronClient emitter.Emitter, tagger *metrics.KeyTagger) lockPick {
 	return lockPick{
-		lockDB:    lockDB,
-		clock:     clock,
-		lockTTLs:  make(map[checkKey]chanAndIndex),
-		lockMutex: &sync.Mutex{},
+		lockDB:       lockDB,
+		clock:        clock,
+		metronClient: metronClient,
+		tagger:       tagger,
+		lockTTLs:     make(map[checkKey]chanAndIndex),
+		lockMutex:    &sync.Mutex{},
 	}
 }
 
@@ -52,29 +85,84 @@ func (l lockPick) RegisterTTL(logger lager.Logger, lock *db.Lock) {
 	logger.Debug("starting")
 	logger.Debug("completed")
 
+	l.register(logger, lock, RegisteredTTL{
+		Key:               lock.Key,
+		Owner:             lock.Owner,
+		Type:              lock.Type,
+		ModifiedId:        lock.ModifiedId,
+		ModifiedIndex:     lock.ModifiedIndex,
+		TtlInSeconds:      lock.TtlInSeconds,
+		TtlInMilliseconds: lock.TtlInMilliseconds,
+		ExpiresAt:         l.clock.Now().Add(lock.TTL()),
+	}, lock.TTL(), false)
+}
+
+func (l lockPick) RestoreTTL(logger lager.Logger, ttl RegisteredTTL) {
+	logger = logger.Session("restore-ttl", lager.Data{"key": ttl.Key, "modified-index": ttl.ModifiedIndex, "type": ttl.Type})
+	logger.Debug("starting")
+	logger.Debug("completed")
+
+	lock := &db.Lock{
+		Resource:          &models.Resource{Key: ttl.Key, Owner: ttl.Owner, Type: ttl.Type},
+		ModifiedId:        ttl.ModifiedId,
+		ModifiedIndex:     ttl.ModifiedIndex,
+		TtlInSeconds:      ttl.TtlInSeconds,
+		TtlInMilliseconds: ttl.TtlInMilliseconds,
+	}
+	l.register(logger, lock, ttl, ttl.ExpiresAt.Sub(l.clock.Now()), true)
+}
+
+// register watches lock until it expires after remaining. The timer only
+// measures remaining, on the monotonic clock, so that ExpiresAt is only
+// used to report and hand over the registration, and a step of the wall
+// clock does not change when the lock expires.
+func (l lockPick) register(logger lager.Logger, lock *db.Lock, ttl RegisteredTTL, remaining time.Duration, restore bool) {
 	newChanIndex := chanAndIndex{
 		channel: make(chan struct{}),
 		index:   lock.ModifiedIndex,
+		ttl:     ttl,
 	}
 	l.lockMutex.Lock()
 	defer l.lockMutex.Unlock()
 
 	channelIndex, ok := l.lockTTLs[checkKeyFromLock(lock)]
-	if ok && channelIndex.index >= newChanIndex.index {
+	sooner := restore && channelIndex.index == newChanIndex.index && ttl.ExpiresAt.Before(channelIndex.ttl.ExpiresAt)
+	if ok && channelIndex.index >= newChanIndex.index && !sooner {
 		logger.Debug("found-expiration-goroutine-for-index", lager.Data{"index": channelIndex.index})
 		return
 	}
 
-	if ok && channelIndex.index < newChanIndex.index {
+	if ok {
 		close(channelIndex.channel)
 	}
 
 	l.lockTTLs[checkKeyFromLock(lock)] = newChanIndex
-	go l.checkExpiration(logger, lock, newChanIndex.channel)
+	go l.checkExpiration(logger, lock, remaining, newChanIndex.channel)
 }
 
-func (l lockPick) checkExpiration(logger lager.Logger, lock *db.Lock, closeChan chan struct{}) {
-	lockTimer := l.clock.NewTimer(time.Duration(lock.TtlInSeconds) * time.Second)
+// RegisteredTTLs returns the locks currently waiting to expire, ordered by
+// key.
+func (l lockPick) RegisteredTTLs() []RegisteredTTL {
+	l.lockMutex.Lock()
+	defer l.lockMutex.Unlock()
+
+	ttls := make([]RegisteredTTL, 0, len(l.lockTTLs))
+	for _, chanIndex := range l.lockTTLs {
+		ttls = append(ttls, chanIndex.ttl)
+	}
+
+	sort.Slice(ttls, func(i, j int) bool {
+		if ttls[i].Key != ttls[j].Key {
+			return ttls[i].Key < ttls[j].Key
+		}
+		return ttls[i].ModifiedId < ttls[j].ModifiedId
+	})
+	return ttls
+}
+
+func (l lockPick) checkExpiration(logger lager.Logger, lock *db.Lock, ttl time.Duration, closeChan chan struct{}) {
+	lockTimer := l.clock.NewTimer(ttl)
+	defer lockTimer.Stop()
 
 	for {
 		select {
@@ -82,41 +170,63 @@ func (l lockPick) checkExpiration(logger lager.Logger, lock *db.Lock, closeChan
 			logger.Debug("cancelling-old-check-goroutine")
 			return
 		case <-lockTimer.C():
-			defer func() {
-				l.lockMutex.Lock()
-				chanIndex := l.lockTTLs[checkKeyFromLock(lock)]
-				if chanIndex.index == lock.ModifiedIndex {
-					delete(l.lockTTLs, checkKeyFromLock(lock))
-				}
-				l.lockMutex.Unlock()
-			}()
-
-			fetchedLock, err := l.lockDB.Fetch(logger, lock.Key)
-			if err != nil {
-				return
-			}
-
-			if fetchedLock.ModifiedIndex == lock.ModifiedIndex && fetchedLock.ModifiedId == lock.ModifiedId {
-				logger.Info("lock-expired")
-
-				switch lock.Type {
-				case models.LockType:
-					locksExpired.Increment()
-				case models.PresenceType:
-					presenceExpired.Increment()
-				default:
-					logger.Debug("unknown-logger-type")
-				}
-
-				err = l.lockDB.Release(logger, lock.Resource)
-				if err != nil {
-					logger.Error("failed-to-release-lock", err)
-					return
-				}
-			}
-			return
 		}
+
+		if !l.expire(logger, lock) {
+			// keep the registration, so that the lock is still expired once
+			// the database, the elector or a backup lets it through
+			lockTimer.Reset(releaseRetryInterval)
+			continue
+		}
+
+		l.lockMutex.Lock()
+		chanIndex := l.lockTTLs[checkKeyFromLock(lock)]
+		if chanIndex.index == lock.ModifiedIndex {
+			delete(l.lockTTLs, checkKeyFromLock(lock))
+		}
+		l.lockMutex.Unlock()
+		return
+	}
+}
+
+// expire releases lock if it has not changed since it was registered. It
+// returns false if it could not tell or could not release the lock, and
+// should try again.
+func (l lockPick) expire(logger lager.Logger, lock *db.Lock) bool {
+	_, span := tracing.StartSpan(context.Background(), "expiration.check-lock")
+	var err error
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
+	fetchedLock, err := l.lockDB.FetchIncludingExpired(logger, lock.Key)
+	if err == models.ErrResourceNotFound {
+		err = nil
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	if fetchedLock.ModifiedIndex != lock.ModifiedIndex || fetchedLock.ModifiedId != lock.ModifiedId {
+		return true
 	}
+
+	err = l.lockDB.Release(logger, lock.Resource)
+	if err == models.ErrResourceNotFound || err == models.ErrLockCollision {
+		// released or taken over since it was fetched
+		err = nil
+		return true
+	}
+	if err != nil {
+		logger.Error("failed-to-release-lock", err)
+		return false
+	}
+
+	logger.Info("lock-expired")
+
+	incrementExpiredCounter(logger, l.metronClient, l.tagger, lock)
+	return true
 }
 
 func checkKeyFromLock(lock *db.Lock) checkKey {
@@ -125,3 +235,21 @@ func checkKeyFromLock(lock *db.Lock) checkKey {
 		id:  lock.ModifiedId,
 	}
 }
+
+func incrementExpiredCounter(logger lager.Logger, metronClient emitter.Emitter, tagger *metrics.KeyTagger, lock *db.Lock) {
+	var err error
+	switch lock.Type {
+	case models.LockType:
+		err = metronClient.IncrementCounter(locksExpired)
+		tagger.IncrementCounter(logger, metronClient, locksExpired, lock.Key)
+	case models.PresenceType:
+		err = metronClient.IncrementCounter(presenceExpired)
+		tagger.IncrementCounter(logger, metronClient, presenceExpired, lock.Key)
+	default:
+		logger.Debug("unknown-lock-type", lager.Data{"type": lock.Type})
+	}
+
+	if err != nil {
+		logger.Error("failed-to-increment-expired-counter", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/locket/db"
@@ -13,8 +14,6 @@ import (
 	"code.cloudfoundry.org/locket/expiration"
 	"code.cloudfoundry.org/locket/models"
 
-	"github.com/cloudfoundry/dropsonde/metric_sender/fake"
-	"github.com/cloudfoundry/dropsonde/metrics"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
@@ -30,8 +29,8 @@ var _ = Describe("LockPick", func() {
 
 		ttl time.Duration
 
-		sender         *fake.FakeMetricSender
-		lock, presence *db.Lock
+		fakeMetronClient *mfakes.FakeIngressClient
+		lock, presence   *db.Lock
 	)
 
 	BeforeEach(func() {
@@ -65,15 +64,14 @@ var _ = Describe("LockPick", func() {
 		logger = lagertest.NewTestLogger("lock-pick")
 		fakeLockDB = &dbfakes.FakeLockDB{}
 
-		sender = fake.NewFakeMetricSender()
-		metrics.Initialize(sender, nil)
+		fakeMetronClient = new(mfakes.FakeIngressClient)
 
-		lockPick = expiration.NewLockPick(fakeLockDB, fakeClock)
+		lockPick = expiration.NewLockPick(fakeLockDB, fakeClock, fakeMetronClient, nil)
 	})
 
 	Context("RegisterTTL", func() {
 		BeforeEach(func() {
-			fakeLockDB.FetchReturns(lock, nil)
+			fakeLockDB.FetchIncludingExpiredReturns(lock, nil)
 		})
 
 		It("checks that the lock expires after the ttl", func() {
@@ -81,8 +79,8 @@ var _ = Describe("LockPick", func() {
 
 			fakeClock.WaitForWatcherAndIncrement(ttl)
 
-			Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
-			_, key := fakeLockDB.FetchArgsForCall(0)
+			Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+			_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 			Expect(key).To(Equal(lock.Key))
 
 			Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
@@ -96,9 +94,8 @@ var _ = Describe("LockPick", func() {
 
 			fakeClock.WaitForNWatchersAndIncrement(ttl, 2)
 
-			Eventually(func() uint64 {
-				return sender.GetCounter("LocksExpired")
-			}).Should(BeEquivalentTo(1))
+			Eventually(fakeMetronClient.IncrementCounterCallCount).Should(Equal(2))
+			Expect(incrementedCounters(fakeMetronClient)).To(ConsistOf("LocksExpired", "PresenceExpired"))
 		})
 
 		It("emits a counter metric for presence expiration", func() {
@@ -107,9 +104,8 @@ var _ = Describe("LockPick", func() {
 
 			fakeClock.WaitForNWatchersAndIncrement(ttl, 2)
 
-			Eventually(func() uint64 {
-				return sender.GetCounter("PresenceExpired")
-			}).Should(BeEquivalentTo(1))
+			Eventually(fakeMetronClient.IncrementCounterCallCount).Should(Equal(2))
+			Expect(incrementedCounters(fakeMetronClient)).To(ContainElement("PresenceExpired"))
 		})
 
 		It("logs the type of the lock", func() {
@@ -135,7 +131,7 @@ var _ = Describe("LockPick", func() {
 					ModifiedIndex: 7,
 				}
 
-				fakeLockDB.FetchReturns(returnedLock, nil)
+				fakeLockDB.FetchIncludingExpiredReturns(returnedLock, nil)
 			})
 
 			It("does not release the lock", func() {
@@ -143,8 +139,8 @@ var _ = Describe("LockPick", func() {
 
 				fakeClock.WaitForWatcherAndIncrement(ttl)
 
-				Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
-				_, key := fakeLockDB.FetchArgsForCall(0)
+				Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+				_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 				Expect(key).To(Equal(lock.Key))
 
 				Consistently(fakeLockDB.ReleaseCallCount).Should(Equal(0))
@@ -165,7 +161,7 @@ var _ = Describe("LockPick", func() {
 					ModifiedId:    "new-guid",
 				}
 
-				fakeLockDB.FetchReturns(returnedLock, nil)
+				fakeLockDB.FetchIncludingExpiredReturns(returnedLock, nil)
 			})
 
 			It("does not release the lock", func() {
@@ -173,8 +169,8 @@ var _ = Describe("LockPick", func() {
 
 				fakeClock.WaitForWatcherAndIncrement(ttl)
 
-				Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
-				_, key := fakeLockDB.FetchArgsForCall(0)
+				Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+				_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 				Expect(key).To(Equal(lock.Key))
 
 				Consistently(fakeLockDB.ReleaseCallCount).Should(Equal(0))
@@ -183,7 +179,7 @@ var _ = Describe("LockPick", func() {
 
 		Context("when fetching the lock fails", func() {
 			BeforeEach(func() {
-				fakeLockDB.FetchReturns(nil, errors.New("failed-to-fetch-lock"))
+				fakeLockDB.FetchIncludingExpiredReturns(nil, errors.New("failed-to-fetch-lock"))
 			})
 
 			It("does not release the lock", func() {
@@ -191,9 +187,22 @@ var _ = Describe("LockPick", func() {
 
 				fakeClock.WaitForWatcherAndIncrement(ttl)
 
-				Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
+				Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
 				Consistently(fakeLockDB.ReleaseCallCount).Should(Equal(0))
 			})
+
+			It("tries again later", func() {
+				lockPick.RegisterTTL(logger, lock)
+
+				fakeClock.WaitForWatcherAndIncrement(ttl)
+				Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+
+				fakeLockDB.FetchIncludingExpiredReturns(lock, nil)
+				fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+
+				Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
+				Eventually(lockPick.RegisteredTTLs).Should(BeEmpty())
+			})
 		})
 
 		Context("when releasing the lock fails", func() {
@@ -208,6 +217,46 @@ var _ = Describe("LockPick", func() {
 
 				Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
 			})
+
+			It("does not count the lock as expired", func() {
+				lockPick.RegisterTTL(logger, lock)
+
+				fakeClock.WaitForWatcherAndIncrement(ttl)
+
+				Eventually(logger).Should(gbytes.Say("failed-to-release-lock"))
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+			})
+
+			It("keeps the lock registered and releases it on a later try", func() {
+				lockPick.RegisterTTL(logger, lock)
+
+				fakeClock.WaitForWatcherAndIncrement(ttl)
+				Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
+				Consistently(lockPick.RegisteredTTLs).Should(HaveLen(1))
+
+				fakeLockDB.ReleaseReturns(nil)
+				fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+
+				Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(2))
+				Eventually(lockPick.RegisteredTTLs).Should(BeEmpty())
+				Expect(incrementedCounters(fakeMetronClient)).To(ConsistOf("LocksExpired"))
+			})
+		})
+
+		Context("when the lock was released since it was fetched", func() {
+			BeforeEach(func() {
+				fakeLockDB.ReleaseReturns(models.ErrResourceNotFound)
+			})
+
+			It("stops watching it without counting it as expired", func() {
+				lockPick.RegisterTTL(logger, lock)
+
+				fakeClock.WaitForWatcherAndIncrement(ttl)
+
+				Eventually(lockPick.RegisteredTTLs).Should(BeEmpty())
+				Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+			})
 		})
 
 		Context("when there is already a check process running", func() {
@@ -231,7 +280,7 @@ var _ = Describe("LockPick", func() {
 							ModifiedId:    "guid",
 						}
 
-						fakeLockDB.FetchReturns(returnedLock, nil)
+						fakeLockDB.FetchIncludingExpiredReturns(returnedLock, nil)
 					})
 
 					It("cancels the existing check and adds a new one", func() {
@@ -243,8 +292,8 @@ var _ = Describe("LockPick", func() {
 
 						Eventually(logger).Should(gbytes.Say("cancelling-old-check"))
 
-						Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
-						_, key := fakeLockDB.FetchArgsForCall(0)
+						Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+						_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 						Expect(key).To(Equal(returnedLock.Key))
 
 						Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
@@ -264,7 +313,7 @@ var _ = Describe("LockPick", func() {
 						thirdLock.ModifiedIndex += 1
 
 						trigger = 1
-						fakeLockDB.FetchStub = func(logger lager.Logger, key string) (*db.Lock, error) {
+						fakeLockDB.FetchIncludingExpiredStub = func(logger lager.Logger, key string) (*db.Lock, error) {
 							if atomic.LoadUint32(&trigger) != 0 {
 								// second expiry goroutine
 								lockPick.RegisterTTL(logger, &newLock)
@@ -278,7 +327,7 @@ var _ = Describe("LockPick", func() {
 					It("checks the expiration of the lock", func() {
 						// first expiry goroutine proceeds into timer case statement
 						fakeClock.WaitForWatcherAndIncrement(ttl)
-						Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
+						Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
 						Eventually(func() uint32 {
 							return atomic.LoadUint32(&trigger)
 						}).Should(BeEquivalentTo(0))
@@ -289,8 +338,8 @@ var _ = Describe("LockPick", func() {
 						Eventually(fakeClock.WatcherCount).Should(Equal(2))
 						fakeClock.WaitForWatcherAndIncrement(ttl)
 
-						Eventually(fakeLockDB.FetchCallCount).Should(Equal(2))
-						Consistently(fakeLockDB.FetchCallCount).Should(Equal(2))
+						Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(2))
+						Consistently(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(2))
 
 						Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
 						_, resource := fakeLockDB.ReleaseArgsForCall(0)
@@ -331,8 +380,8 @@ var _ = Describe("LockPick", func() {
 							Eventually(fakeClock.WatcherCount).Should(Equal(1))
 							fakeClock.WaitForWatcherAndIncrement(ttl)
 
-							Eventually(fakeLockDB.FetchCallCount).Should(Equal(2))
-							_, key := fakeLockDB.FetchArgsForCall(0)
+							Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(2))
+							_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 							Expect(key).To(Equal(l.Key))
 						})
 					})
@@ -345,7 +394,7 @@ var _ = Describe("LockPick", func() {
 				BeforeEach(func() {
 					newLock = *lock
 					newLock.ModifiedId = "new-guid"
-					fakeLockDB.FetchReturns(&newLock, nil)
+					fakeLockDB.FetchIncludingExpiredReturns(&newLock, nil)
 				})
 
 				It("does not effect the other check goroutines", func() {
@@ -356,9 +405,9 @@ var _ = Describe("LockPick", func() {
 
 					fakeClock.WaitForWatcherAndIncrement(ttl)
 
-					Eventually(fakeLockDB.FetchCallCount).Should(Equal(2))
-					_, key1 := fakeLockDB.FetchArgsForCall(0)
-					_, key2 := fakeLockDB.FetchArgsForCall(1)
+					Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(2))
+					_, key1 := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
+					_, key2 := fakeLockDB.FetchIncludingExpiredArgsForCall(1)
 					Expect(key1).To(Equal(newLock.Key))
 					Expect(key2).To(Equal(newLock.Key))
 
@@ -383,7 +432,7 @@ var _ = Describe("LockPick", func() {
 					newLock = *lock
 					newLock.ModifiedIndex += 1
 
-					fakeLockDB.FetchStub = func(logger lager.Logger, key string) (*db.Lock, error) {
+					fakeLockDB.FetchIncludingExpiredStub = func(logger lager.Logger, key string) (*db.Lock, error) {
 						switch {
 						case key == newLock.Key:
 							return &newLock, nil
@@ -406,9 +455,9 @@ var _ = Describe("LockPick", func() {
 					Eventually(fakeClock.WatcherCount).Should(Equal(3))
 					fakeClock.WaitForWatcherAndIncrement(ttl)
 
-					Eventually(fakeLockDB.FetchCallCount).Should(Equal(2))
-					_, key1 := fakeLockDB.FetchArgsForCall(0)
-					_, key2 := fakeLockDB.FetchArgsForCall(1)
+					Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(2))
+					_, key1 := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
+					_, key2 := fakeLockDB.FetchIncludingExpiredArgsForCall(1)
 					Expect([]string{key1, key2}).To(ContainElement(newLock.Key))
 					Expect([]string{key1, key2}).To(ContainElement(anotherLock.Key))
 
@@ -421,8 +470,8 @@ var _ = Describe("LockPick", func() {
 				BeforeEach(func() {
 					fakeClock.WaitForWatcherAndIncrement(ttl)
 
-					Eventually(fakeLockDB.FetchCallCount).Should(Equal(1))
-					_, key := fakeLockDB.FetchArgsForCall(0)
+					Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(1))
+					_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 					Expect(key).To(Equal(lock.Key))
 
 					Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
@@ -435,8 +484,8 @@ var _ = Describe("LockPick", func() {
 					Eventually(fakeClock.WatcherCount).Should(Equal(1))
 					fakeClock.WaitForWatcherAndIncrement(ttl)
 
-					Eventually(fakeLockDB.FetchCallCount).Should(Equal(2))
-					_, key := fakeLockDB.FetchArgsForCall(0)
+					Eventually(fakeLockDB.FetchIncludingExpiredCallCount).Should(Equal(2))
+					_, key := fakeLockDB.FetchIncludingExpiredArgsForCall(0)
 					Expect(key).To(Equal(lock.Key))
 
 					Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(2))
@@ -445,4 +494,91 @@ var _ = Describe("LockPick", func() {
 			})
 		})
 	})
+
+	Context("RestoreTTL", func() {
+		var registered expiration.RegisteredTTL
+
+		BeforeEach(func() {
+			fakeLockDB.FetchIncludingExpiredReturns(lock, nil)
+			registered = expiration.RegisteredTTL{
+				Key:           lock.Key,
+				Owner:         lock.Owner,
+				Type:          lock.Type,
+				ModifiedId:    lock.ModifiedId,
+				ModifiedIndex: lock.ModifiedIndex,
+				TtlInSeconds:  lock.TtlInSeconds,
+				ExpiresAt:     fakeClock.Now().Add(5 * time.Second),
+			}
+		})
+
+		It("checks that the lock expires when it was due to", func() {
+			lockPick.RestoreTTL(logger, registered)
+
+			fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+
+			Eventually(fakeLockDB.ReleaseCallCount).Should(Equal(1))
+			_, resource := fakeLockDB.ReleaseArgsForCall(0)
+			Expect(resource.Key).To(Equal(lock.Key))
+			Expect(resource.Owner).To(Equal(lock.Owner))
+		})
+
+		It("keeps the restored expiry when the same lock is registered again", func() {
+			lockPick.RestoreTTL(logger, registered)
+			lockPick.RegisterTTL(logger, lock)
+
+			Expect(lockPick.RegisteredTTLs()).To(Equal([]expiration.RegisteredTTL{registered}))
+		})
+
+		It("replaces a registration of the same lock that expires later", func() {
+			lockPick.RegisterTTL(logger, lock)
+			lockPick.RestoreTTL(logger, registered)
+			Expect(lockPick.RegisteredTTLs()).To(Equal([]expiration.RegisteredTTL{registered}))
+
+			later := registered
+			later.ExpiresAt = registered.ExpiresAt.Add(time.Second)
+			lockPick.RestoreTTL(logger, later)
+			Expect(lockPick.RegisteredTTLs()).To(Equal([]expiration.RegisteredTTL{registered}))
+		})
+	})
+
+	Context("RegisteredTTLs", func() {
+		It("returns the locks waiting to expire", func() {
+			lockPick.RegisterTTL(logger, lock)
+			lockPick.RegisterTTL(logger, presence)
+
+			Expect(lockPick.RegisteredTTLs()).To(Equal([]expiration.RegisteredTTL{
+				{
+					Key:           "funky",
+					Owner:         "town",
+					Type:          models.LockType,
+					ModifiedId:    "guid",
+					ModifiedIndex: 6,
+					TtlInSeconds:  25,
+					ExpiresAt:     fakeClock.Now().Add(ttl),
+				},
+				{
+					Key:           "funky-presence",
+					Owner:         "town-presence",
+					Type:          models.PresenceType,
+					ModifiedId:    "guid",
+					ModifiedIndex: 6,
+					TtlInSeconds:  25,
+					ExpiresAt:     fakeClock.Now().Add(ttl),
+				},
+			}))
+		})
+
+		Context("when the lock has expired", func() {
+			BeforeEach(func() {
+				fakeLockDB.FetchIncludingExpiredReturns(lock, nil)
+			})
+
+			It("is no longer returned", func() {
+				lockPick.RegisterTTL(logger, lock)
+				fakeClock.WaitForWatcherAndIncrement(ttl)
+
+				Eventually(lockPick.RegisteredTTLs).Should(BeEmpty())
+			})
+		})
+	})
 })
@@ -0,0 +1,89 @@
+package expiration
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/tracing"
+	"golang.org/x/net/context"
+)
+
+// sweeper expires locks using the expiry timestamps stored in the database
+// rather than in-memory timers, so any number of locket servers can run one
+// against the same database.
+type sweeper struct {
+	logger        lager.Logger
+	lockDB        db.LockDB
+	clock         clock.Clock
+	metronClient  emitter.Emitter
+	tagger        *metrics.KeyTagger
+	sweepInterval time.Duration
+}
+
+func NewSweeper(logger lager.Logger, lockDB db.LockDB, clock clock.Clock, metronClient emitter.Emitter, tagger *metrics.KeyTagger, sweepInterval time.Duration) sweeper {
+	return sweeper{
+		logger:        logger,
+		lockDB:        lockDB,
+		clock:         clock,
+		metronClient:  metronClient,
+		tagger:        tagger,
+		sweepInterval: sweepInterval,
+	}
+}
+
+func (s sweeper) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := s.logger.Session("sweeper")
+
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	check := s.clock.NewTicker(s.sweepInterval)
+	defer check.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+		case <-check.C():
+			s.sweep(logger)
+		}
+	}
+}
+
+func (s sweeper) sweep(logger lager.Logger) {
+	_, span := tracing.StartSpan(context.Background(), "expiration.sweep")
+	locks, err := s.lockDB.ExpireLocks(logger)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		logger.Error("failed-expiring-locks", err)
+		return
+	}
+
+	for _, lock := range locks {
+		incrementExpiredCounter(logger, s.metronClient, s.tagger, lock)
+	}
+}
+
+type noopLockPick struct{}
+
+// NewNoopLockPick returns a LockPick that does not track any TTLs. It is used
+// when expiration is handled by a sweeper.
+func NewNoopLockPick() LockPick {
+	return noopLockPick{}
+}
+
+func (noopLockPick) RegisterTTL(logger lager.Logger, lock *db.Lock) {}
+
+func (noopLockPick) RestoreTTL(logger lager.Logger, ttl RegisteredTTL) {}
+
+func (noopLockPick) RegisteredTTLs() []RegisteredTTL {
+	return nil
+}
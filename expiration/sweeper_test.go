@@ -0,0 +1,85 @@
+package expiration_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Sweeper", func() {
+	var (
+		runner  ifrit.Runner
+		process ifrit.Process
+
+		fakeLockDB       *dbfakes.FakeLockDB
+		fakeClock        *fakeclock.FakeClock
+		logger           *lagertest.TestLogger
+		fakeMetronClient *mfakes.FakeIngressClient
+		sweepInterval    time.Duration
+	)
+
+	BeforeEach(func() {
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("expiration")
+		sweepInterval = 5 * time.Second
+
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+
+		fakeLockDB.ExpireLocksReturns([]*db.Lock{
+			{Resource: &models.Resource{Key: "funky", Owner: "town", Type: models.LockType}},
+			{Resource: &models.Resource{Key: "clif", Owner: "bar", Type: models.PresenceType}},
+		}, nil)
+	})
+
+	JustBeforeEach(func() {
+		runner = expiration.NewSweeper(logger, fakeLockDB, fakeClock, fakeMetronClient, nil, sweepInterval)
+		process = ginkgomon.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("expires locks on an interval", func() {
+		Consistently(fakeLockDB.ExpireLocksCallCount).Should(Equal(0))
+
+		fakeClock.Increment(sweepInterval)
+		Eventually(fakeLockDB.ExpireLocksCallCount).Should(Equal(1))
+
+		fakeClock.Increment(sweepInterval)
+		Eventually(fakeLockDB.ExpireLocksCallCount).Should(Equal(2))
+	})
+
+	It("increments the expiration metrics", func() {
+		fakeClock.Increment(sweepInterval)
+		Eventually(fakeMetronClient.IncrementCounterCallCount).Should(Equal(2))
+		Expect(incrementedCounters(fakeMetronClient)).To(ConsistOf("LocksExpired", "PresenceExpired"))
+	})
+
+	Context("when expiring the locks fails", func() {
+		BeforeEach(func() {
+			fakeLockDB.ExpireLocksReturns(nil, errors.New("we got the funk"))
+		})
+
+		It("logs the error and continues", func() {
+			fakeClock.Increment(sweepInterval)
+			Eventually(logger).Should(gbytes.Say("failed-expiring-locks"))
+
+			fakeClock.Increment(sweepInterval)
+			Eventually(fakeLockDB.ExpireLocksCallCount).Should(Equal(2))
+		})
+	})
+})
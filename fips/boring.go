@@ -0,0 +1,10 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+package fips
+
+import _ "crypto/tls/fipsonly"
+
+// BoringCrypto reports whether the binary was built against a BoringCrypto
+// toolchain with the FIPS-only TLS restrictions enabled.
+const BoringCrypto = true
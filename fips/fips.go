@@ -0,0 +1,80 @@
+package fips
+
+import (
+	"crypto/tls"
+	"errors"
+)
+
+var (
+	ErrTLSVersionNotApproved = errors.New("tls version is not fips approved")
+	ErrCipherNotApproved     = errors.New("cipher suite is not fips approved")
+	ErrCurveNotApproved      = errors.New("curve is not fips approved")
+)
+
+var approvedCipherSuites = []uint16{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+}
+
+var approvedCurves = []tls.CurveID{
+	tls.CurveP256,
+	tls.CurveP384,
+}
+
+// Restrict limits the given config to TLS 1.2 with FIPS-approved cipher
+// suites and curves.
+func Restrict(config *tls.Config) {
+	config.MinVersion = tls.VersionTLS12
+	config.MaxVersion = tls.VersionTLS12
+	config.CipherSuites = append([]uint16{}, approvedCipherSuites...)
+	config.CurvePreferences = append([]tls.CurveID{}, approvedCurves...)
+	config.PreferServerCipherSuites = true
+}
+
+// Verify returns an error if the config could negotiate anything that is not
+// FIPS-approved.
+func Verify(config *tls.Config) error {
+	if config.MinVersion != tls.VersionTLS12 || config.MaxVersion != tls.VersionTLS12 {
+		return ErrTLSVersionNotApproved
+	}
+
+	if len(config.CipherSuites) == 0 {
+		return ErrCipherNotApproved
+	}
+	for _, suite := range config.CipherSuites {
+		if !containsSuite(approvedCipherSuites, suite) {
+			return ErrCipherNotApproved
+		}
+	}
+
+	if len(config.CurvePreferences) == 0 {
+		return ErrCurveNotApproved
+	}
+	for _, curve := range config.CurvePreferences {
+		if !containsCurve(approvedCurves, curve) {
+			return ErrCurveNotApproved
+		}
+	}
+
+	return nil
+}
+
+func containsSuite(suites []uint16, suite uint16) bool {
+	for _, s := range suites {
+		if s == suite {
+			return true
+		}
+	}
+	return false
+}
+
+func containsCurve(curves []tls.CurveID, curve tls.CurveID) bool {
+	for _, c := range curves {
+		if c == curve {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package fips_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFips(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fips Suite")
+}
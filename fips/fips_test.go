@@ -0,0 +1,65 @@
+package fips_test
+
+import (
+	"crypto/tls"
+
+	"code.cloudfoundry.org/locket/fips"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FIPS", func() {
+	var config *tls.Config
+
+	BeforeEach(func() {
+		config = &tls.Config{}
+	})
+
+	It("rejects an unrestricted config", func() {
+		Expect(fips.Verify(config)).To(Equal(fips.ErrTLSVersionNotApproved))
+	})
+
+	Context("when the config has been restricted", func() {
+		BeforeEach(func() {
+			fips.Restrict(config)
+		})
+
+		It("only allows tls 1.2", func() {
+			Expect(config.MinVersion).To(BeEquivalentTo(tls.VersionTLS12))
+			Expect(config.MaxVersion).To(BeEquivalentTo(tls.VersionTLS12))
+		})
+
+		It("only allows approved cipher suites", func() {
+			Expect(config.CipherSuites).To(ConsistOf(
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			))
+		})
+
+		It("passes verification", func() {
+			Expect(fips.Verify(config)).To(Succeed())
+		})
+
+		Context("when a non approved cipher suite is added", func() {
+			BeforeEach(func() {
+				config.CipherSuites = append(config.CipherSuites, tls.TLS_RSA_WITH_RC4_128_SHA)
+			})
+
+			It("fails verification", func() {
+				Expect(fips.Verify(config)).To(Equal(fips.ErrCipherNotApproved))
+			})
+		})
+
+		Context("when a non approved curve is added", func() {
+			BeforeEach(func() {
+				config.CurvePreferences = append(config.CurvePreferences, tls.X25519)
+			})
+
+			It("fails verification", func() {
+				Expect(fips.Verify(config)).To(Equal(fips.ErrCurveNotApproved))
+			})
+		})
+	})
+})
@@ -0,0 +1,8 @@
+//go:build !boringcrypto
+// +build !boringcrypto
+
+package fips
+
+// BoringCrypto reports whether the binary was built against a BoringCrypto
+// toolchain with the FIPS-only TLS restrictions enabled.
+const BoringCrypto = false
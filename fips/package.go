@@ -0,0 +1 @@
+package fips // import "code.cloudfoundry.org/locket/fips"
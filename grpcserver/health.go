@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthServer is a grpc health server that applies the status of the whole
+// server, the "" service, to each of its named services as well, so that a
+// load balancer checking a service such as "models.Locket" sees the same
+// status as one checking the server.
+type HealthServer struct {
+	*health.Server
+	services []string
+}
+
+func NewHealthServer(services ...string) *HealthServer {
+	return &HealthServer{
+		Server:   health.NewServer(),
+		services: services,
+	}
+}
+
+func (h *HealthServer) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	h.Server.SetServingStatus(service, status)
+	if service != "" {
+		return
+	}
+
+	for _, name := range h.services {
+		h.Server.SetServingStatus(name, status)
+	}
+}
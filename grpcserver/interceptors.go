@@ -0,0 +1,43 @@
+package grpcserver
+
+import (
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// ChainUnaryInterceptors combines the interceptors into one, since grpc only
+// allows a single unary interceptor per server. The first interceptor is the
+// outermost.
+func ChainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chained = wrap(interceptors[i], info, chained)
+		}
+		return chained(ctx, req)
+	}
+}
+
+func wrap(interceptor grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+// ChainStreamInterceptors combines stream interceptors the same way
+// ChainUnaryInterceptors does for unary ones.
+func ChainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chained = wrapStream(interceptors[i], info, chained)
+		}
+		return chained(srv, stream)
+	}
+}
+
+func wrapStream(interceptor grpc.StreamServerInterceptor, info *grpc.StreamServerInfo, handler grpc.StreamHandler) grpc.StreamHandler {
+	return func(srv interface{}, stream grpc.ServerStream) error {
+		return interceptor(srv, stream, info, handler)
+	}
+}
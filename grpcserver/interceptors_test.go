@@ -0,0 +1,73 @@
+package grpcserver_test
+
+import (
+	"code.cloudfoundry.org/locket/grpcserver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("ChainUnaryInterceptors", func() {
+	var calls []string
+
+	recorder := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+"-before")
+			resp, err := handler(ctx, req)
+			calls = append(calls, name+"-after")
+			return resp, err
+		}
+	}
+
+	BeforeEach(func() {
+		calls = nil
+	})
+
+	It("calls the interceptors in order around the handler", func() {
+		interceptor := grpcserver.ChainUnaryInterceptors(recorder("first"), recorder("second"))
+		resp, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return "response", nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal("response"))
+		Expect(calls).To(Equal([]string{"first-before", "second-before", "handler", "second-after", "first-after"}))
+	})
+
+	It("calls the handler directly when there are no interceptors", func() {
+		interceptor := grpcserver.ChainUnaryInterceptors()
+		resp, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "response", nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp).To(Equal("response"))
+	})
+})
+
+var _ = Describe("ChainStreamInterceptors", func() {
+	var calls []string
+
+	recorder := func(name string) grpc.StreamServerInterceptor {
+		return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, name+"-before")
+			err := handler(srv, stream)
+			calls = append(calls, name+"-after")
+			return err
+		}
+	}
+
+	BeforeEach(func() {
+		calls = nil
+	})
+
+	It("calls the interceptors in order around the handler", func() {
+		interceptor := grpcserver.ChainStreamInterceptors(recorder("first"), recorder("second"))
+		err := interceptor("server", nil, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+			calls = append(calls, "handler")
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal([]string{"first-before", "second-before", "handler", "second-after", "first-after"}))
+	})
+})
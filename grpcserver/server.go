@@ -3,61 +3,243 @@ package grpcserver
 import (
 	"crypto/tls"
 	"net"
+	"net/http"
 	"os"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/models/v2"
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/orca"
 )
 
+// MinClientKeepaliveTime is the shortest keepalive time clients may use.
+// Clients that ping more often are disconnected.
+const MinClientKeepaliveTime = 10 * time.Second
+
 type grpcServerRunner struct {
 	listenAddress string
+	listener      net.Listener
 	handler       models.LocketServer
 	logger        lager.Logger
 	tlsConfig     *tls.Config
+	serverOptions []grpc.ServerOption
+	healthServer  healthpb.HealthServer
+	drainDelay    time.Duration
+	loadReports   orca.ServerMetricsProvider
+	v2Handler     v2.LocketServer
+	chaosHandler  admin.ChaosServer
+	sessions      admin.SessionsServer
+	replication   admin.ReplicationServer
+	web           *grpcWeb
+}
+
+type grpcWeb struct {
+	listener       net.Listener
+	allowedOrigins []string
+	wrap           func(http.Handler) http.Handler
 }
 
-func NewGRPCServer(logger lager.Logger, listenAddress string, tlsConfig *tls.Config, handler models.LocketServer) grpcServerRunner {
+func NewGRPCServer(logger lager.Logger, listenAddress string, tlsConfig *tls.Config, handler models.LocketServer, opts ...grpc.ServerOption) grpcServerRunner {
 	return grpcServerRunner{
 		listenAddress: listenAddress,
 		handler:       handler,
 		logger:        logger,
 		tlsConfig:     tlsConfig,
+		serverOptions: opts,
 	}
 }
 
+func NewGRPCServerWithListener(logger lager.Logger, listener net.Listener, tlsConfig *tls.Config, handler models.LocketServer, opts ...grpc.ServerOption) grpcServerRunner {
+	return grpcServerRunner{
+		listenAddress: listener.Addr().String(),
+		listener:      listener,
+		handler:       handler,
+		logger:        logger,
+		tlsConfig:     tlsConfig,
+		serverOptions: opts,
+	}
+}
+
+// WithHealthServer returns a copy of the runner that also registers the
+// standard grpc health service.
+func (s grpcServerRunner) WithHealthServer(healthServer healthpb.HealthServer) grpcServerRunner {
+	s.healthServer = healthServer
+	return s
+}
+
+// WithDrainDelay returns a copy of the runner that, when signalled, reports
+// NOT_SERVING from its health server and waits for delay before it stops
+// accepting requests, so that load balancers stop sending it new ones first.
+func (s grpcServerRunner) WithDrainDelay(delay time.Duration) grpcServerRunner {
+	s.drainDelay = delay
+	return s
+}
+
+// WithLoadReporting returns a copy of the runner that also serves the ORCA
+// out-of-band load reporting service with the load from provider.
+func (s grpcServerRunner) WithLoadReporting(provider orca.ServerMetricsProvider) grpcServerRunner {
+	s.loadReports = provider
+	return s
+}
+
+// WithV2Server returns a copy of the runner that also serves the locket.v2
+// api using handler.
+func (s grpcServerRunner) WithV2Server(handler v2.LocketServer) grpcServerRunner {
+	s.v2Handler = handler
+	return s
+}
+
+// WithChaosServer returns a copy of the runner that also serves the Chaos
+// admin api using handler.
+func (s grpcServerRunner) WithChaosServer(handler admin.ChaosServer) grpcServerRunner {
+	s.chaosHandler = handler
+	return s
+}
+
+// WithSessionsServer returns a copy of the runner that also serves the
+// Sessions admin api using handler.
+func (s grpcServerRunner) WithSessionsServer(handler admin.SessionsServer) grpcServerRunner {
+	s.sessions = handler
+	return s
+}
+
+// WithReplicationServer returns a copy of the runner that also serves the
+// Replication admin api using handler.
+func (s grpcServerRunner) WithReplicationServer(handler admin.ReplicationServer) grpcServerRunner {
+	s.replication = handler
+	return s
+}
+
+// WithGRPCWeb returns a copy of the runner that also serves the api to
+// browsers over grpc-web on listener, using the same interceptors and tls
+// config as the grpc server. Cross-origin requests are only allowed from
+// allowedOrigins, where "*" allows any origin. If wrap is not nil it wraps
+// the grpc-web http handler.
+func (s grpcServerRunner) WithGRPCWeb(listener net.Listener, allowedOrigins []string, wrap func(http.Handler) http.Handler) grpcServerRunner {
+	s.web = &grpcWeb{listener: listener, allowedOrigins: allowedOrigins, wrap: wrap}
+	return s
+}
+
 func (s grpcServerRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	logger := s.logger.Session("grpc-server")
 
 	logger.Info("started")
 	defer logger.Info("complete")
 
-	lis, err := net.Listen("tcp", s.listenAddress)
-	if err != nil {
-		logger.Error("failed-to-listen", err)
-		return err
+	var err error
+	lis := s.listener
+	if lis == nil {
+		lis, err = net.Listen("tcp", s.listenAddress)
+		if err != nil {
+			logger.Error("failed-to-listen", err)
+			return err
+		}
 	}
 
-	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	opts := append([]grpc.ServerOption{
+		grpc.Creds(credentials.NewTLS(s.tlsConfig)),
+		// clients with locket_keepalive_time ping idle connections too
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: MinClientKeepaliveTime, PermitWithoutStream: true}),
+	}, s.serverOptions...)
+	server := grpc.NewServer(opts...)
 	models.RegisterLocketServer(server, s.handler)
+	if s.v2Handler != nil {
+		v2.RegisterLocketServer(server, s.v2Handler)
+	}
+	if s.chaosHandler != nil {
+		admin.RegisterChaosServer(server, s.chaosHandler)
+	}
+	if s.sessions != nil {
+		admin.RegisterSessionsServer(server, s.sessions)
+	}
+	if s.replication != nil {
+		admin.RegisterReplicationServer(server, s.replication)
+	}
+	if s.healthServer != nil {
+		healthpb.RegisterHealthServer(server, s.healthServer)
+	}
+	if s.loadReports != nil {
+		err = orca.Register(server, orca.ServiceOptions{ServerMetricsProvider: s.loadReports})
+		if err != nil {
+			logger.Error("failed-to-register-load-reporting", err)
+			return err
+		}
+	}
 
-	errCh := make(chan error)
+	errCh := make(chan error, 2)
 	go func() {
 		errCh <- server.Serve(lis)
 	}()
 
+	var webServer *http.Server
+	if s.web != nil {
+		var handler http.Handler = grpcweb.WrapServer(server, grpcweb.WithOriginFunc(s.web.allowOrigin))
+		if s.web.wrap != nil {
+			handler = s.web.wrap(handler)
+		}
+
+		webServer = &http.Server{Handler: handler}
+		go func() {
+			errCh <- webServer.Serve(tls.NewListener(s.web.listener, s.tlsConfig))
+		}()
+		logger.Info("serving-grpc-web", lager.Data{"address": s.web.listener.Addr().String()})
+	}
+
 	close(ready)
 
 	select {
 	case sig := <-signals:
 		logger.Info("signalled", lager.Data{"signal": sig})
+		s.drain(logger, errCh)
 		break
 	case err = <-errCh:
 		logger.Error("failed-to-serve", err)
 		break
 	}
 
+	if webServer != nil {
+		webServer.Close()
+	}
 	server.GracefulStop()
 	return err
 }
+
+type healthShutdowner interface {
+	Shutdown()
+}
+
+func (s grpcServerRunner) drain(logger lager.Logger, errCh <-chan error) {
+	if shutdowner, ok := s.healthServer.(healthShutdowner); ok {
+		shutdowner.Shutdown()
+	}
+
+	if s.drainDelay <= 0 {
+		return
+	}
+
+	logger.Info("draining", lager.Data{"delay": s.drainDelay.String()})
+	timer := time.NewTimer(s.drainDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case err := <-errCh:
+		logger.Error("failed-to-serve-while-draining", err)
+	}
+}
+
+func (w *grpcWeb) allowOrigin(origin string) bool {
+	for _, allowed := range w.allowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
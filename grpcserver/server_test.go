@@ -1,16 +1,36 @@
 package grpcserver_test
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/binary"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"time"
 
+	orcaservicepb "github.com/cncf/xds/go/xds/service/orca/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/orca"
 
 	"code.cloudfoundry.org/cfhttp"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/chaos"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
 	"code.cloudfoundry.org/locket/grpcserver"
+	"code.cloudfoundry.org/locket/handlers"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/models/v2"
+	"code.cloudfoundry.org/locket/replication"
+	"code.cloudfoundry.org/locket/sessions"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/tedsuo/ifrit"
@@ -71,6 +91,230 @@ var _ = Describe("GRPCServer", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	Context("when the server is given a listener", func() {
+		var listener net.Listener
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", listenAddress)
+			Expect(err).NotTo(HaveOccurred())
+
+			runner = grpcserver.NewGRPCServerWithListener(logger, listener, tlsConfig, &testHandler{})
+		})
+
+		It("serves on the provided listener", func() {
+			conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			locketClient := models.NewLocketClient(conn)
+			_, err = locketClient.Lock(context.Background(), &models.LockRequest{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the server is given a health server", func() {
+		BeforeEach(func() {
+			healthServer := health.NewServer()
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).WithHealthServer(healthServer)
+		})
+
+		It("serves the health service", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Status).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+		})
+	})
+
+	Context("when the server is given a health server and a drain delay", func() {
+		var healthServer *grpcserver.HealthServer
+
+		BeforeEach(func() {
+			healthServer = grpcserver.NewHealthServer("models.Locket")
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).
+				WithHealthServer(healthServer).
+				WithDrainDelay(time.Second)
+		})
+
+		It("reports the server's status for each named service", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "models.Locket"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Status).To(Equal(healthpb.HealthCheckResponse_SERVING))
+		})
+
+		It("reports NOT_SERVING and keeps serving until the delay has passed when signalled", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+			healthClient := healthpb.NewHealthClient(conn)
+
+			serverProcess.Signal(os.Interrupt)
+
+			Eventually(func() healthpb.HealthCheckResponse_ServingStatus {
+				resp, err := healthClient.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "models.Locket"})
+				Expect(err).NotTo(HaveOccurred())
+				return resp.Status
+			}).Should(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+
+			_, err = models.NewLocketClient(conn).Fetch(context.Background(), &models.FetchRequest{Key: "key"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(serverProcess.Wait(), 2*time.Second).Should(Receive())
+		})
+	})
+
+	Context("when the server is given a load report provider", func() {
+		BeforeEach(func() {
+			recorder := orca.NewServerMetricsRecorder()
+			recorder.SetCPUUtilization(0.25)
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).WithLoadReporting(recorder)
+		})
+
+		It("serves the orca load reporting service", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			stream, err := orcaservicepb.NewOpenRcaServiceClient(conn).StreamCoreMetrics(context.Background(), &orcaservicepb.OrcaLoadReportRequest{})
+			Expect(err).NotTo(HaveOccurred())
+
+			report, err := stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.CpuUtilization).To(Equal(0.25))
+		})
+	})
+
+	Context("when the server is given a v2 handler", func() {
+		BeforeEach(func() {
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).WithV2Server(handlers.NewV2Handler(&testHandler{}))
+		})
+
+		It("serves both versions of the api", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = models.NewLocketClient(conn).Fetch(context.Background(), &models.FetchRequest{Key: "key"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = v2.NewLocketClient(conn).Fetch(context.Background(), &v2.FetchRequest{Key: "key"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the server is given a chaos handler", func() {
+		BeforeEach(func() {
+			injector := chaos.NewInjector(logger, &dbfakes.FakeLockDB{}, fakeclock.NewFakeClock(time.Now()), 1)
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).WithChaosServer(chaos.NewHandler(logger, injector))
+		})
+
+		It("serves the chaos admin api", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := admin.NewChaosClient(conn).GetFaults(context.Background(), &admin.GetFaultsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Faults).To(Equal(&admin.Faults{}))
+		})
+	})
+
+	Context("when the server is given a sessions handler", func() {
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", listenAddress)
+			Expect(err).NotTo(HaveOccurred())
+
+			tracker := sessions.NewTracker(logger, fakeclock.NewFakeClock(time.Now()))
+			runner = grpcserver.NewGRPCServerWithListener(logger, tracker.Listener(listener), tlsConfig, &testHandler{}).
+				WithSessionsServer(sessions.NewHandler(logger, tracker, &testHandler{}))
+		})
+
+		It("serves the sessions admin api", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := admin.NewSessionsClient(conn).ListSessions(context.Background(), &admin.ListSessionsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Sessions).To(HaveLen(1))
+		})
+	})
+
+	Context("when the server is given a replication handler", func() {
+		BeforeEach(func() {
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).
+				WithReplicationServer(replication.NewServer(logger, &expirationfakes.FakeLockPick{}, fakeclock.NewFakeClock(time.Now()), time.Second))
+		})
+
+		It("serves the replication admin api", func() {
+			conn, err := grpc.Dial(listenAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			Expect(err).NotTo(HaveOccurred())
+
+			stream, err := admin.NewReplicationClient(conn).WatchTTLs(context.Background(), &admin.WatchTTLsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			snapshot, err := stream.Recv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.Ttls).To(BeEmpty())
+		})
+	})
+
+	Context("when the server is given a grpc-web listener", func() {
+		var webAddress string
+
+		BeforeEach(func() {
+			webListener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			webAddress = webListener.Addr().String()
+
+			runner = grpcserver.NewGRPCServer(logger, listenAddress, tlsConfig, &testHandler{}).
+				WithGRPCWeb(webListener, []string{"https://dashboard.example.com"}, nil)
+		})
+
+		webRequest := func(method, origin string, body []byte) *http.Response {
+			req, err := http.NewRequest(method, "https://"+webAddress+"/models.Locket/Fetch", bytes.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Content-Type", "application/grpc-web+proto")
+			req.Header.Set("X-Grpc-Web", "1")
+			req.Header.Set("Origin", origin)
+			if method == "OPTIONS" {
+				req.Header.Set("Access-Control-Request-Method", "POST")
+				req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		It("serves the api over grpc-web", func() {
+			msg, err := (&models.FetchRequest{Key: "key"}).Marshal()
+			Expect(err).NotTo(HaveOccurred())
+
+			frame := make([]byte, 5, 5+len(msg))
+			binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+			frame = append(frame, msg...)
+
+			resp := webRequest("POST", "https://dashboard.example.com", frame)
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("Access-Control-Allow-Origin")).To(Equal("https://dashboard.example.com"))
+
+			body, err := ioutil.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(body)).To(BeNumerically(">=", 5))
+			Expect(body[0]).To(BeEquivalentTo(0))
+		})
+
+		It("does not allow other origins", func() {
+			resp := webRequest("OPTIONS", "https://evil.example.com", nil)
+			defer resp.Body.Close()
+			Expect(resp.Header.Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+	})
+
 	Context("when the server fails to listen", func() {
 		var alternateRunner ifrit.Runner
 
@@ -101,3 +345,21 @@ func (h *testHandler) Fetch(ctx context.Context, req *models.FetchRequest) (*mod
 func (h *testHandler) FetchAll(ctx context.Context, req *models.FetchAllRequest) (*models.FetchAllResponse, error) {
 	return &models.FetchAllResponse{}, nil
 }
+func (h *testHandler) Acquire(ctx context.Context, req *models.LockRequest) (*models.LockResponse, error) {
+	return &models.LockResponse{}, nil
+}
+func (h *testHandler) LockGroup(ctx context.Context, req *models.LockGroupRequest) (*models.LockGroupResponse, error) {
+	return &models.LockGroupResponse{}, nil
+}
+func (h *testHandler) Waiters(ctx context.Context, req *models.WaitersRequest) (*models.WaitersResponse, error) {
+	return &models.WaitersResponse{}, nil
+}
+func (h *testHandler) KeepAlive(stream models.Locket_KeepAliveServer) error {
+	return nil
+}
+func (h *testHandler) ArriveAtBarrier(ctx context.Context, req *models.BarrierRequest) (*models.BarrierResponse, error) {
+	return &models.BarrierResponse{}, nil
+}
+func (h *testHandler) Stats(ctx context.Context, req *models.StatsRequest) (*models.StatsResponse, error) {
+	return &models.StatsResponse{}, nil
+}
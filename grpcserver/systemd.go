@@ -0,0 +1,40 @@
+package grpcserver
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd, see
+// sd_listen_fds(3)
+const listenFdsStart = 3
+
+var ErrNoSystemdListener = errors.New("no listener passed by systemd")
+
+// SystemdListener returns the first socket passed to this process using the
+// systemd socket activation protocol (LISTEN_PID/LISTEN_FDS). The environment
+// variables are cleared so that child processes do not inherit them.
+func SystemdListener() (net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, ErrNoSystemdListener
+	}
+
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, ErrNoSystemdListener
+	}
+
+	file := os.NewFile(uintptr(listenFdsStart), "LISTEN_FD_3")
+	defer file.Close()
+
+	return net.FileListener(file)
+}
@@ -0,0 +1,42 @@
+package grpcserver_test
+
+import (
+	"fmt"
+	"os"
+
+	"code.cloudfoundry.org/locket/grpcserver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SystemdListener", func() {
+	AfterEach(func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+	})
+
+	Context("when no sockets were passed", func() {
+		It("returns an error", func() {
+			_, err := grpcserver.SystemdListener()
+			Expect(err).To(Equal(grpcserver.ErrNoSystemdListener))
+		})
+	})
+
+	Context("when the sockets were passed to a different process", func() {
+		BeforeEach(func() {
+			os.Setenv("LISTEN_PID", fmt.Sprintf("%d", os.Getpid()+1))
+			os.Setenv("LISTEN_FDS", "1")
+		})
+
+		It("returns an error", func() {
+			_, err := grpcserver.SystemdListener()
+			Expect(err).To(Equal(grpcserver.ErrNoSystemdListener))
+		})
+
+		It("clears the environment", func() {
+			grpcserver.SystemdListener()
+			Expect(os.Getenv("LISTEN_PID")).To(BeEmpty())
+			Expect(os.Getenv("LISTEN_FDS")).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/slowlog"
+	"golang.org/x/net/context"
+)
+
+// barrierOwner owns the lock that records that a barrier has tripped, so that
+// every participant can take it without colliding.
+const barrierOwner = "barrier"
+
+// ArriveAtBarrier records that the participant in req has arrived at the
+// barrier and blocks until all of its participants have arrived, or returns
+// ErrBarrierTimeout when ctx is done first.
+//
+// Barriers are kept in the lock table. Each arrival is a lock of type
+// barrier, keyed by the barrier and participant, whose value is the barrier
+// key. Once enough arrivals are counted the barrier trips: a lock is taken on
+// the barrier key itself, so that participants that have not seen the
+// arrivals yet also return. Every row lives for the ttl of the request.
+func (h *locketHandler) ArriveAtBarrier(ctx context.Context, req *models.BarrierRequest) (*models.BarrierResponse, error) {
+	logger := h.logger.Session("arrive-at-barrier", lager.Data{
+		"key":          req.Key,
+		"participant":  req.Participant,
+		"participants": req.Participants,
+	})
+	logger.Debug("started")
+	defer logger.Debug("complete")
+
+	err := validate(req)
+	if err != nil {
+		logger.Error("invalid-request", err)
+		return nil, err
+	}
+	ttl := time.Duration(req.TtlInMilliseconds) * time.Millisecond
+
+	arrival := &models.Resource{
+		Key:   req.Key + "/" + req.Participant,
+		Owner: req.Participant,
+		Value: req.Key,
+		Type:  models.BarrierType,
+	}
+
+	for {
+		arrived, err := h.checkBarrier(ctx, logger, req, arrival, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if arrived > 0 {
+			logger.Info("barrier-tripped", lager.Data{"arrived": arrived})
+			return &models.BarrierResponse{Arrived: arrived}, nil
+		}
+
+		stopTimer := slowlog.Track(ctx, "wait")
+		retry := h.clock.NewTimer(acquireRetryInterval)
+		select {
+		case <-ctx.Done():
+			retry.Stop()
+			stopTimer()
+			logger.Info("gave-up-waiting", lager.Data{"error": ctx.Err().Error()})
+
+			err := h.db.Release(logger, arrival)
+			if err != nil && err != models.ErrResourceNotFound {
+				logger.Error("failed-to-release-arrival", err)
+			}
+			return nil, models.ErrBarrierTimeout
+		case <-retry.C():
+		}
+		retry.Stop()
+		stopTimer()
+	}
+}
+
+// checkBarrier refreshes the participant's arrival and returns the number of
+// participants that have arrived once the barrier has tripped, or 0 while it
+// is still waiting for them.
+func (h *locketHandler) checkBarrier(ctx context.Context, logger lager.Logger, req *models.BarrierRequest, arrival *models.Resource, ttl time.Duration) (int32, error) {
+	call := startDBCall(ctx, logger, "db.lock")
+	lock, err := h.db.Lock(call.logger, arrival, ttl)
+	call.finish(err)
+	if err != nil {
+		h.exitIfUnrecoverable(err)
+		logger.Error("failed-to-record-arrival", err)
+		return 0, err
+	}
+	h.lockPick.RegisterTTL(logger, lock)
+
+	call = startDBCall(ctx, logger, "db.fetch")
+	tripped, err := h.db.Fetch(call.logger, req.Key)
+	call.finish(err)
+	if err == nil && tripped.Type == models.BarrierType {
+		return req.Participants, nil
+	}
+	if err != nil && err != models.ErrResourceNotFound {
+		h.exitIfUnrecoverable(err)
+		logger.Error("failed-to-fetch-barrier", err)
+		return 0, err
+	}
+
+	call = startDBCall(ctx, logger, "db.fetch-all")
+	locks, err := h.db.FetchAll(call.logger, models.BarrierType)
+	call.finish(err)
+	if err != nil {
+		h.exitIfUnrecoverable(err)
+		logger.Error("failed-to-fetch-arrivals", err)
+		return 0, err
+	}
+
+	var arrived int32
+	for _, lock := range locks {
+		if lock.Value == req.Key && lock.Key != req.Key {
+			arrived++
+		}
+	}
+	if arrived < req.Participants {
+		logger.Debug("waiting-for-participants", lager.Data{"arrived": arrived})
+		return 0, nil
+	}
+
+	return arrived, h.tripBarrier(ctx, logger, req.Key, ttl)
+}
+
+func (h *locketHandler) tripBarrier(ctx context.Context, logger lager.Logger, key string, ttl time.Duration) error {
+	call := startDBCall(ctx, logger, "db.lock")
+	lock, err := h.db.Lock(call.logger, &models.Resource{Key: key, Owner: barrierOwner, Type: models.BarrierType}, ttl)
+	call.finish(err)
+	if err != nil {
+		h.exitIfUnrecoverable(err)
+		logger.Error("failed-to-trip-barrier", err)
+		return err
+	}
+	h.lockPick.RegisterTTL(logger, lock)
+	return nil
+}
@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/deadlock"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/handlers"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// The benchmarks use a database that answers from memory, so that they
+// measure the handler itself. The database and expiration scheduler have
+// benchmarks of their own.
+
+func BenchmarkLock(b *testing.B) {
+	handler := newBenchmarkHandler(&benchmarkDB{})
+	req := &models.LockRequest{
+		Resource:     &models.Resource{Key: "key", Owner: "owner", Value: "value", TypeCode: models.LOCK},
+		TtlInSeconds: 15,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := handler.Lock(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFetch(b *testing.B) {
+	handler := newBenchmarkHandler(&benchmarkDB{})
+	req := &models.FetchRequest{Key: "key"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := handler.Fetch(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkKeepAlive100 refreshes 100 locks per message on a KeepAlive
+// stream, for comparison with refreshing them one Lock call at a time. Its
+// ns/lock is comparable with BenchmarkLock's ns/op.
+func BenchmarkKeepAlive100(b *testing.B) {
+	const locks = 100
+
+	handler := newBenchmarkHandler(&benchmarkDB{})
+	req := &models.KeepAliveRequest{}
+	for i := 0; i < locks; i++ {
+		req.Locks = append(req.Locks, &models.LockRequest{
+			Resource:     &models.Resource{Key: fmt.Sprintf("key-%d", i), Owner: "owner", TypeCode: models.LOCK},
+			TtlInSeconds: 15,
+		})
+	}
+
+	stream := newKeepAliveStream()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- handler.KeepAlive(stream)
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		stream.requests <- req
+		<-stream.responses
+	}
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	b.ReportMetric(float64(elapsed.Nanoseconds())/float64(b.N*locks), "ns/lock")
+	close(stream.requests)
+	if err := <-errCh; err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkFetchAll10k(b *testing.B) {
+	benchmarkDB := &benchmarkDB{}
+	for i := 0; i < 10000; i++ {
+		benchmarkDB.locks = append(benchmarkDB.locks, &db.Lock{
+			Resource:  &models.Resource{Key: fmt.Sprintf("cell-%05d", i), Owner: fmt.Sprintf("owner-%05d", i), Type: models.PresenceType},
+			ExpiresAt: time.Now().Add(time.Hour).UnixNano(),
+		})
+	}
+	handler := newBenchmarkHandler(benchmarkDB)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resp, err := handler.FetchAll(context.Background(), &models.FetchAllRequest{TypeCode: models.PRESENCE})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(resp.Resources) != 10000 {
+			b.Fatalf("fetched %d locks", len(resp.Resources))
+		}
+	}
+}
+
+func newBenchmarkHandler(lockDB db.LockDB) models.LocketServer {
+	logger := lager.NewLogger("benchmark")
+	clock := clock.NewClock()
+	return handlers.NewLocketHandler(
+		logger,
+		lockDB,
+		expiration.NewNoopLockPick(),
+		contention.NewTracker(logger, clock, &mfakes.FakeIngressClient{}, nil, 5*time.Minute),
+		deadlock.NewDetector(logger, clock, time.Second),
+		clock,
+		make(chan struct{}, 1),
+	)
+}
+
+type benchmarkDB struct {
+	db.LockDB
+	locks []*db.Lock
+}
+
+func (d *benchmarkDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	return &db.Lock{Resource: resource, ModifiedIndex: 1, FencingToken: 1, ExpiresAt: time.Now().Add(ttl).UnixNano()}, nil
+}
+
+func (d *benchmarkDB) Fetch(logger lager.Logger, key string) (*db.Lock, error) {
+	return &db.Lock{
+		Resource:  &models.Resource{Key: key, Owner: "owner", Value: "value", TypeCode: models.LOCK},
+		ExpiresAt: time.Now().Add(time.Hour).UnixNano(),
+	}, nil
+}
+
+func (d *benchmarkDB) FetchAll(logger lager.Logger, lockType string) ([]*db.Lock, error) {
+	return d.locks, nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+)
+
+// connectionSweepInterval is how often expired entries are dropped from the
+// connections, as they are added.
+const connectionSweepInterval = time.Minute
+
+// connections remembers which connection last took or refreshed each
+// exclusive lock, so that a Lock by the same owner from another connection
+// can be told apart from a refresh. It only knows about locks taken through
+// this server since it started.
+type connections struct {
+	mutex     *sync.Mutex
+	holders   map[string]connection
+	lastSweep time.Time
+}
+
+type connection struct {
+	owner     string
+	address   string
+	expiresAt time.Time
+}
+
+func newConnections() *connections {
+	return &connections{
+		mutex:   &sync.Mutex{},
+		holders: make(map[string]connection),
+	}
+}
+
+func connectionAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+// other returns the address of the connection that owner last held key
+// from, when that is not address and the lock has not expired since.
+func (c *connections) other(key, owner, address string, now time.Time) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	holder, ok := c.holders[key]
+	if !ok || holder.owner != owner || holder.address == address || !now.Before(holder.expiresAt) {
+		return ""
+	}
+	return holder.address
+}
+
+func (c *connections) put(key, owner, address string, expiresAt, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if now.Sub(c.lastSweep) >= connectionSweepInterval {
+		for key, holder := range c.holders {
+			if !now.Before(holder.expiresAt) {
+				delete(c.holders, key)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.holders[key] = connection{owner: owner, address: address, expiresAt: expiresAt}
+}
+
+func (c *connections) remove(key, owner string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.holders[key].owner == owner {
+		delete(c.holders, key)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+)
+
+// fetchAllGroup coalesces concurrent FetchAll requests for the same type, so
+// that they share one database query and one response. A request only joins
+// a query that starts after it arrived, so it never sees a result older than
+// writes that finished before it was made. Requests that arrive while a query
+// is running wait for it to finish and then share the next one.
+type fetchAllGroup struct {
+	mutex   *sync.Mutex
+	running map[string]*fetchAllCall
+	next    map[string]*fetchAllCall
+}
+
+type fetchAllCall struct {
+	ctx      context.Context
+	fetch    func(ctx context.Context) (*models.FetchAllResponse, error)
+	done     chan struct{}
+	response *models.FetchAllResponse
+	err      error
+}
+
+func newFetchAllGroup() *fetchAllGroup {
+	return &fetchAllGroup{
+		mutex:   &sync.Mutex{},
+		running: make(map[string]*fetchAllCall),
+		next:    make(map[string]*fetchAllCall),
+	}
+}
+
+// do returns the result of fetch for lockType, calling it only if no other
+// request for lockType is waiting to. The bool reports whether the result
+// came from another request's call. The call is shared, so it runs on a
+// context that keeps the values of ctx but is never canceled, and every
+// request waiting on it, including the one that made it, gives up when its
+// own ctx is done.
+func (g *fetchAllGroup) do(ctx context.Context, lockType string, fetch func(ctx context.Context) (*models.FetchAllResponse, error)) (*models.FetchAllResponse, error, bool) {
+	g.mutex.Lock()
+
+	var call *fetchAllCall
+	shared := false
+	if _, ok := g.running[lockType]; !ok {
+		call = newFetchAllCall(ctx, fetch)
+		g.running[lockType] = call
+		go g.run(lockType, call)
+	} else if next, ok := g.next[lockType]; ok {
+		call = next
+		shared = true
+	} else {
+		// run starts call once the running query finishes
+		call = newFetchAllCall(ctx, fetch)
+		g.next[lockType] = call
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.response, call.err, shared
+	case <-ctx.Done():
+		return nil, ctx.Err(), shared
+	}
+}
+
+func newFetchAllCall(ctx context.Context, fetch func(ctx context.Context) (*models.FetchAllResponse, error)) *fetchAllCall {
+	return &fetchAllCall{
+		ctx:   detachedContext{ctx},
+		fetch: fetch,
+		done:  make(chan struct{}),
+	}
+}
+
+// run makes call, and then the calls queued behind it, until none is left.
+func (g *fetchAllGroup) run(lockType string, call *fetchAllCall) {
+	for call != nil {
+		call.response, call.err = call.fetch(call.ctx)
+
+		g.mutex.Lock()
+		next, ok := g.next[lockType]
+		if ok {
+			g.running[lockType] = next
+			delete(g.next, lockType)
+		} else {
+			delete(g.running, lockType)
+		}
+		g.mutex.Unlock()
+
+		close(call.done)
+		call = next
+	}
+}
+
+// detachedContext keeps the values of a context, such as its span, but not
+// its deadline or cancelation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
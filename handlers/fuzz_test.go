@@ -0,0 +1,203 @@
+package handlers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/contention/contentionfakes"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/deadlock/deadlockfakes"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
+	"code.cloudfoundry.org/locket/handlers"
+	"code.cloudfoundry.org/locket/models"
+)
+
+// The fuzz targets decode arbitrary bytes as requests, so that the proto
+// decoding is fuzzed along with the handlers, and check that whatever
+// reaches the database fits in the lock tables. The corpus is kept in
+// testdata/fuzz; run a target with, for example:
+//
+//	go test ./handlers -run '^$' -fuzz FuzzLockRequest
+
+func FuzzLockRequest(f *testing.F) {
+	for _, req := range []*models.LockRequest{
+		{Resource: &models.Resource{Key: "key", Owner: "owner", Value: "value", Type: models.LockType}, TtlInSeconds: 10},
+		{Resource: &models.Resource{Key: "key", Owner: "owner", TypeCode: models.PRESENCE}, TtlInMilliseconds: 250},
+		{Resource: &models.Resource{Key: "key", Owner: "owner", TypeCode: models.LOCK}, TtlInSeconds: 10, Mode: models.SEMAPHORE, Capacity: 3},
+		{Resource: &models.Resource{Key: "key", Owner: "owner", TypeCode: models.LOCK}, TtlInSeconds: 10, GraceInMilliseconds: 1000},
+	} {
+		f.Add(marshal(f, req))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		req := &models.LockRequest{}
+		if req.Unmarshal(data) != nil {
+			return
+		}
+
+		handler, fakeLockDB := newFuzzHandler()
+		_, err := handler.Lock(context.Background(), req)
+		if err != nil {
+			return
+		}
+
+		calls := fakeLockDB.LockCallCount() + fakeLockDB.LockSharedCallCount() + fakeLockDB.LockSemaphoreCallCount() + fakeLockDB.LockWithGraceCallCount()
+		if calls != 1 {
+			t.Fatalf("expected one call to the database, got %d", calls)
+		}
+
+		var resource *models.Resource
+		var ttl time.Duration
+		switch {
+		case fakeLockDB.LockCallCount() == 1:
+			_, resource, ttl = fakeLockDB.LockArgsForCall(0)
+		case fakeLockDB.LockSharedCallCount() == 1:
+			_, resource, ttl = fakeLockDB.LockSharedArgsForCall(0)
+		case fakeLockDB.LockSemaphoreCallCount() == 1:
+			_, resource, _, ttl = fakeLockDB.LockSemaphoreArgsForCall(0)
+		default:
+			var grace time.Duration
+			_, resource, ttl, grace = fakeLockDB.LockWithGraceArgsForCall(0)
+			checkTTL(t, grace)
+		}
+
+		checkResource(t, resource)
+		if resource.Owner == "" {
+			t.Fatal("locked without an owner")
+		}
+		checkTTL(t, ttl)
+	})
+}
+
+func FuzzReleaseRequest(f *testing.F) {
+	for _, req := range []*models.ReleaseRequest{
+		{Resource: &models.Resource{Key: "key", Owner: "owner"}},
+		{Resource: &models.Resource{Key: "key", Owner: "owner"}, Mode: models.SHARED},
+		{Resource: &models.Resource{Key: "key", Owner: "owner"}, ExpectedValue: "value", ExpectedFencingToken: 7},
+	} {
+		f.Add(marshal(f, req))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		req := &models.ReleaseRequest{}
+		if req.Unmarshal(data) != nil {
+			return
+		}
+
+		handler, fakeLockDB := newFuzzHandler()
+		_, err := handler.Release(context.Background(), req)
+		if err != nil {
+			return
+		}
+
+		switch {
+		case fakeLockDB.ReleaseCallCount() == 1:
+			_, resource := fakeLockDB.ReleaseArgsForCall(0)
+			checkResource(t, resource)
+		case fakeLockDB.ReleaseSharedCallCount() == 1:
+			_, resource := fakeLockDB.ReleaseSharedArgsForCall(0)
+			checkResource(t, resource)
+		case fakeLockDB.ReleaseIfCallCount() == 1:
+			_, resource, _ := fakeLockDB.ReleaseIfArgsForCall(0)
+			checkResource(t, resource)
+		default:
+			t.Fatal("released without calling the database")
+		}
+	})
+}
+
+func FuzzFetchRequest(f *testing.F) {
+	for _, req := range []*models.FetchRequest{
+		{Key: "key"},
+		{Key: "key", TypeCode: models.PRESENCE},
+	} {
+		f.Add(marshal(f, req))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		req := &models.FetchRequest{}
+		if req.Unmarshal(data) != nil {
+			return
+		}
+
+		handler, fakeLockDB := newFuzzHandler()
+		_, err := handler.Fetch(context.Background(), req)
+		if err != nil || fakeLockDB.FetchCallCount() == 0 {
+			return
+		}
+
+		_, key := fakeLockDB.FetchArgsForCall(0)
+		checkResource(t, &models.Resource{Key: key})
+	})
+}
+
+func newFuzzHandler() (models.LocketServer, *dbfakes.FakeLockDB) {
+	fakeLockDB := &dbfakes.FakeLockDB{}
+	fakeLockDB.LockStub = func(_ lager.Logger, resource *models.Resource, _ time.Duration) (*db.Lock, error) {
+		return &db.Lock{Resource: resource}, nil
+	}
+	fakeLockDB.LockSharedStub = fakeLockDB.LockStub
+	fakeLockDB.LockSemaphoreStub = func(logger lager.Logger, resource *models.Resource, _ int, ttl time.Duration) (*db.Lock, error) {
+		return fakeLockDB.LockStub(logger, resource, ttl)
+	}
+	fakeLockDB.LockWithGraceStub = func(logger lager.Logger, resource *models.Resource, ttl, _ time.Duration) (*db.Lock, error) {
+		return fakeLockDB.LockStub(logger, resource, ttl)
+	}
+	fakeLockDB.FetchStub = func(_ lager.Logger, key string) (*db.Lock, error) {
+		return &db.Lock{Resource: &models.Resource{Key: key, Owner: "owner", Type: models.LockType}}, nil
+	}
+
+	handler := handlers.NewLocketHandler(
+		lagertest.NewTestLogger("fuzz"),
+		fakeLockDB,
+		&expirationfakes.FakeLockPick{},
+		&contentionfakes.FakeTracker{},
+		&deadlockfakes.FakeDetector{},
+		fakeclock.NewFakeClock(time.Now()),
+		make(chan struct{}, 1),
+	)
+	return handler, fakeLockDB
+}
+
+func checkResource(t *testing.T, resource *models.Resource) {
+	if resource == nil {
+		t.Fatal("called the database without a resource")
+	}
+	checkString(t, "key", resource.Key, models.MaxKeyLength)
+	checkString(t, "owner", resource.Owner, models.MaxOwnerLength)
+	checkString(t, "value", resource.Value, models.MaxValueLength)
+}
+
+func checkString(t *testing.T, name, s string, maxLength int) {
+	if !utf8.ValidString(s) {
+		t.Fatalf("called the database with a %s that is not valid utf-8: %q", name, s)
+	}
+	if utf8.RuneCountInString(s) > maxLength {
+		t.Fatalf("called the database with a %s of %d characters", name, utf8.RuneCountInString(s))
+	}
+}
+
+func checkTTL(t *testing.T, ttl time.Duration) {
+	now := time.Now()
+	if ttl < 0 || now.Add(ttl).Before(now) {
+		t.Fatalf("called the database with a ttl of %d", ttl)
+	}
+}
+
+type marshaler interface {
+	Marshal() ([]byte, error)
+}
+
+func marshal(f *testing.F, req marshaler) []byte {
+	data, err := req.Marshal()
+	if err != nil {
+		f.Fatal(err)
+	}
+	return data
+}
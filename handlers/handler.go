@@ -1,31 +1,109 @@
 package handlers
 
 import (
+	"io"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+
 	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/contention"
 	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/deadlock"
 	"code.cloudfoundry.org/locket/expiration"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/slowlog"
+	"code.cloudfoundry.org/locket/tokenauth"
+	"code.cloudfoundry.org/locket/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultStatsTopN = 10
+
+	// acquireRetryInterval is how often a blocked Acquire retries the lock
+	// when it is not woken by a release on this server.
+	acquireRetryInterval = 500 * time.Millisecond
+
+	// maxTTL is longer than any lock needs, and short enough that adding it
+	// to the current time cannot overflow.
+	maxTTL = time.Duration(math.MaxInt64 / 2)
 )
 
 type locketHandler struct {
 	logger lager.Logger
 
-	db       db.LockDB
-	exitCh   chan<- struct{}
-	lockPick expiration.LockPick
+	db         db.LockDB
+	exitCh     chan<- struct{}
+	lockPick   expiration.LockPick
+	contention contention.Tracker
+	deadlocks  deadlock.Detector
+	clock      clock.Clock
+	waiters    *waitQueue
+	fetchAlls  *fetchAllGroup
+
+	defaultMaxHold time.Duration
+	maxHolds       map[string]time.Duration
+
+	requestIDs      *requestIDs
+	requestIDWindow time.Duration
+
+	connections *connections
+
+	hotKeys *hotKeys
 }
 
-func NewLocketHandler(logger lager.Logger, db db.LockDB, lockPick expiration.LockPick, exitCh chan<- struct{}) *locketHandler {
+func NewLocketHandler(logger lager.Logger, db db.LockDB, lockPick expiration.LockPick, contention contention.Tracker, deadlocks deadlock.Detector, clock clock.Clock, exitCh chan<- struct{}) *locketHandler {
 	return &locketHandler{
-		logger:   logger,
-		db:       db,
-		lockPick: lockPick,
-		exitCh:   exitCh,
+		logger:      logger,
+		db:          db,
+		lockPick:    lockPick,
+		contention:  contention,
+		deadlocks:   deadlocks,
+		clock:       clock,
+		exitCh:      exitCh,
+		waiters:     newWaitQueue(),
+		fetchAlls:   newFetchAllGroup(),
+		requestIDs:  newRequestIDs(),
+		connections: newConnections(),
 	}
 }
 
+// WithMaxHold limits how long an owner can keep refreshing a lock before it
+// has to let it go. maxHolds overrides defaultMaxHold for individual keys,
+// and a limit of 0 disables it. It fetches the lock before each refresh, so
+// it is only for databases that cannot enforce the limit themselves, the way
+// SQLDB.WithMaxHold does within the lock's transaction.
+func (h *locketHandler) WithMaxHold(defaultMaxHold time.Duration, maxHolds map[string]time.Duration) *locketHandler {
+	h.defaultMaxHold = defaultMaxHold
+	h.maxHolds = maxHolds
+	return h
+}
+
+// WithRequestIDWindow makes Lock and Acquire return the original response to
+// a request whose request ID was granted within window, rather than taking
+// the lock again. A window of 0 disables this.
+func (h *locketHandler) WithRequestIDWindow(window time.Duration) *locketHandler {
+	h.requestIDWindow = window
+	return h
+}
+
+// WithHotKeyWindow makes Lock turn owners away from a contended key with the
+// holder it last saw there, for up to window, rather than asking the
+// database each time. A window of 0 disables this.
+func (h *locketHandler) WithHotKeyWindow(window time.Duration) *locketHandler {
+	h.hotKeys = nil
+	if window > 0 {
+		h.hotKeys = newHotKeys(window)
+	}
+	return h
+}
+
 func (h *locketHandler) exitIfUnrecoverable(err error) {
 	if err != helpers.ErrUnrecoverableError {
 		return
@@ -44,18 +122,191 @@ func (h *locketHandler) Lock(ctx context.Context, req *models.LockRequest) (*mod
 	logger.Debug("started")
 	defer logger.Debug("complete")
 
+	if response := h.duplicateLock(logger, req); response != nil {
+		return response, nil
+	}
+
+	start := h.clock.Now()
+	address := connectionAddress(ctx)
+	newConnection := false
+	if address != "" && req.Mode == models.EXCLUSIVE {
+		other := h.connections.other(req.Resource.GetKey(), req.Resource.GetOwner(), address, start)
+		if other != "" {
+			data := lager.Data{
+				"key":                req.Resource.GetKey(),
+				"owner":              req.Resource.GetOwner(),
+				"connection":         address,
+				"holding-connection": other,
+			}
+			if req.OnDuplicate == models.REJECT_DUPLICATE {
+				logger.Info("rejected-lock-held-by-other-connection", data)
+				return nil, models.ErrHeldByOtherConnection
+			}
+			logger.Info("refreshing-lock-from-new-connection", data)
+			newConnection = true
+		}
+	}
+
+	lock, err := h.lock(ctx, logger, req)
+	if err != nil {
+		if err == models.ErrLockCollision {
+			h.sendLockHolder(ctx, logger, lock)
+		}
+		return nil, err
+	}
+
+	response := &models.LockResponse{
+		Resource:     lock.Resource,
+		FencingToken: lock.FencingToken,
+		ExpiresAt:    lock.ExpiresAt,
+		Outcome:      lockOutcome(lock, start, newConnection),
+	}
+	if address != "" && req.Mode == models.EXCLUSIVE {
+		h.connections.put(req.Resource.GetKey(), req.Resource.GetOwner(), address, time.Unix(0, lock.ExpiresAt), h.clock.Now())
+	}
+	h.rememberLock(req, response)
+	return response, nil
+}
+
+// lockOutcome tells whether lock was taken or refreshed by a Lock that
+// started at start. A lock acquired before start was already held by its
+// owner, since acquiring keeps the time the owner first took it.
+func lockOutcome(lock *db.Lock, start time.Time, newConnection bool) models.LockOutcome {
+	switch {
+	case lock.AcquiredAt == 0 || lock.AcquiredAt >= start.UnixNano():
+		return models.ACQUIRED
+	case newConnection:
+		return models.REFRESHED_FROM_NEW_CONNECTION
+	default:
+		return models.REFRESHED
+	}
+}
+
+// Acquire blocks until the lock in req is granted or ctx is done, in which
+// case it returns ErrLockCollision and the last holder it saw. Fair and
+// prioritized requests wait in a per-key queue on this server and are granted
+// by priority, then in arrival order. A waiter that is part of a deadlock
+// may be aborted with ErrDeadlock.
+func (h *locketHandler) Acquire(ctx context.Context, req *models.LockRequest) (*models.LockResponse, error) {
+	logger := h.logger.Session("acquire", lager.Data{
+		"key":      req.Resource.GetKey(),
+		"owner":    req.Resource.GetOwner(),
+		"fair":     req.Fair,
+		"priority": req.Priority,
+	})
+	logger.Debug("started")
+	defer logger.Debug("complete")
+
+	if response := h.duplicateLock(logger, req); response != nil {
+		return response, nil
+	}
+
+	var wake, preempted <-chan struct{}
+	var w *waiter
+	if req.Fair || req.Priority != 0 || req.Preempt {
+		w = h.waiters.enqueue(req.Resource.GetKey(), req.Resource.GetOwner(), tokenauth.Identity(ctx), req.Priority, req.Preempt, h.clock.Now())
+		defer h.waiters.remove(req.Resource.GetKey(), w)
+		wake, preempted = w.wake, w.preempted
+	}
+
+	blocked := h.deadlocks.Wait(req.Resource.GetKey(), req.Resource.GetOwner())
+	defer h.deadlocks.Done(blocked)
+
+	var holder *db.Lock
+	for {
+		if w == nil || h.waiters.isHead(req.Resource.GetKey(), w) {
+			lock, err := h.lock(ctx, logger, req)
+			if err == nil {
+				response := &models.LockResponse{
+					Resource:     lock.Resource,
+					FencingToken: lock.FencingToken,
+					ExpiresAt:    lock.ExpiresAt,
+				}
+				h.rememberLock(req, response)
+				return response, nil
+			}
+			if err != models.ErrLockCollision {
+				return nil, err
+			}
+			if lock != nil {
+				holder = lock
+				h.deadlocks.Blocked(blocked, lock.Owner)
+			}
+		}
+
+		stopTimer := slowlog.Track(ctx, "wait")
+		retry := h.clock.NewTimer(acquireRetryInterval)
+		select {
+		case <-ctx.Done():
+			retry.Stop()
+			stopTimer()
+			logger.Info("gave-up-waiting", lager.Data{"error": ctx.Err().Error()})
+			h.sendLockHolder(ctx, logger, holder)
+			return nil, models.ErrLockCollision
+		case <-preempted:
+			retry.Stop()
+			stopTimer()
+			logger.Info("preempted")
+			return nil, models.ErrLockWaitPreempted
+		case <-blocked.Aborted():
+			retry.Stop()
+			stopTimer()
+			logger.Info("aborted-deadlock")
+			h.sendLockHolder(ctx, logger, holder)
+			return nil, models.ErrDeadlock
+		case <-wake:
+		case <-retry.C():
+		}
+		retry.Stop()
+		stopTimer()
+
+		// the holder may have released the lock since, so it is recorded
+		// again only if the next attempt collides with it
+		h.deadlocks.Blocked(blocked, "")
+	}
+}
+
+// duplicateLock returns the response already granted to req's request ID, if
+// it is still within the request ID window.
+func (h *locketHandler) duplicateLock(logger lager.Logger, req *models.LockRequest) *models.LockResponse {
+	if req.RequestId == "" || h.requestIDWindow <= 0 {
+		return nil
+	}
+
+	response := h.requestIDs.get(req, h.clock.Now())
+	if response != nil {
+		logger.Info("duplicate-request", lager.Data{
+			"request_id": req.RequestId,
+			"key":        req.Resource.GetKey(),
+			"owner":      req.Resource.GetOwner(),
+		})
+	}
+	return response
+}
+
+func (h *locketHandler) rememberLock(req *models.LockRequest, response *models.LockResponse) {
+	if req.RequestId == "" || h.requestIDWindow <= 0 {
+		return
+	}
+	h.requestIDs.put(req, response, h.clock.Now(), h.requestIDWindow)
+}
+
+// lock validates req and takes the lock it describes. On a collision it
+// returns the current holder along with models.ErrLockCollision.
+func (h *locketHandler) lock(ctx context.Context, logger lager.Logger, req *models.LockRequest) (*db.Lock, error) {
 	err := validate(req)
 	if err != nil {
 		logger.Error("invalid-request", err, lager.Data{"type": req.Resource.GetType(), "typeCode": req.Resource.GetTypeCode()})
 		return nil, err
 	}
 
-	if req.TtlInSeconds <= 0 {
-		logger.Error("failed-locking-lock", models.ErrInvalidTTL, lager.Data{
+	ttl, err := lockTTL(req)
+	if err != nil {
+		logger.Error("failed-locking-lock", err, lager.Data{
 			"key":   req.Resource.Key,
 			"owner": req.Resource.Owner,
 		})
-		return nil, models.ErrInvalidTTL
+		return nil, err
 	}
 
 	if req.Resource.Owner == "" {
@@ -66,21 +317,200 @@ func (h *locketHandler) Lock(ctx context.Context, req *models.LockRequest) (*mod
 		return nil, models.ErrInvalidOwner
 	}
 
-	lock, err := h.db.Lock(logger, req.Resource, req.TtlInSeconds)
+	if req.Mode == models.EXCLUSIVE && h.hotKeys != nil {
+		if holder := h.rememberedHolder(logger, req); holder != nil {
+			return holder, models.ErrLockCollision
+		}
+
+		end, err := h.hotKeys.begin(ctx, req.Resource.Key)
+		if err != nil {
+			return nil, err
+		}
+		defer end()
+
+		// the call this one waited for may have found the holder
+		if holder := h.rememberedHolder(logger, req); holder != nil {
+			return holder, models.ErrLockCollision
+		}
+	}
+
+	if req.Mode == models.EXCLUSIVE {
+		err = h.checkMaxHold(ctx, logger, req.Resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lock *db.Lock
+	switch req.Mode {
+	case models.SHARED:
+		call := startDBCall(ctx, logger, "db.lock-shared")
+		lock, err = h.db.LockShared(call.logger, req.Resource, ttl)
+		call.finish(err)
+	case models.SEMAPHORE:
+		call := startDBCall(ctx, logger, "db.lock-semaphore")
+		lock, err = h.db.LockSemaphore(call.logger, req.Resource, int(req.Capacity), ttl)
+		call.finish(err)
+	default:
+		if req.GraceInMilliseconds > 0 {
+			call := startDBCall(ctx, logger, "db.lock-with-grace")
+			lock, err = h.db.LockWithGrace(call.logger, req.Resource, ttl, time.Duration(req.GraceInMilliseconds)*time.Millisecond)
+			call.finish(err)
+		} else {
+			call := startDBCall(ctx, logger, "db.lock")
+			lock, err = h.db.Lock(call.logger, req.Resource, ttl)
+			call.finish(err)
+		}
+	}
+	if err != nil {
+		h.exitIfUnrecoverable(err)
+		if err == models.ErrLockCollision {
+			h.contention.RecordCollision(req.Resource.Key, req.Resource.Owner)
+			if h.hotKeys != nil && req.Mode == models.EXCLUSIVE && lock != nil {
+				h.hotKeys.collided(lock, h.clock.Now())
+			}
+			return lock, err
+		}
+		if err == models.ErrMaxHoldExceeded {
+			return nil, err
+		}
+		logger.Error("failed-locking-lock", err, lager.Data{
+			"key":   req.Resource.Key,
+			"owner": req.Resource.Owner,
+		})
+		return nil, err
+	}
+
+	// Shared holds and semaphore slots are expired from their stored expiry
+	// rather than by the lock pick, which only tracks the single exclusive
+	// holder of a key.
+	if req.Mode == models.EXCLUSIVE {
+		h.contention.RecordAcquired(req.Resource.Key, req.Resource.Owner, ttl)
+		h.lockPick.RegisterTTL(logger, lock)
+		if h.hotKeys != nil {
+			h.hotKeys.locked(lock, h.clock.Now())
+		}
+	}
+
+	return lock, nil
+}
+
+// rememberedHolder returns the holder the hot key cache has for the key of
+// req, if it is another owner, and records the collision.
+func (h *locketHandler) rememberedHolder(logger lager.Logger, req *models.LockRequest) *db.Lock {
+	holder := h.hotKeys.holder(req.Resource.Key, req.Resource.Owner, h.clock.Now())
+	if holder == nil {
+		return nil
+	}
+
+	logger.Debug("collided-with-remembered-holder", lager.Data{
+		"key":    req.Resource.Key,
+		"owner":  req.Resource.Owner,
+		"holder": holder.Owner,
+	})
+	h.contention.RecordCollision(req.Resource.Key, req.Resource.Owner)
+	return holder
+}
+
+// LockGroup locks every resource in req atomically: either all of the locks
+// are granted or none are.
+func (h *locketHandler) LockGroup(ctx context.Context, req *models.LockGroupRequest) (*models.LockGroupResponse, error) {
+	logger := h.logger.Session("lock-group", lager.Data{"size": len(req.Resources)})
+	logger.Debug("started")
+	defer logger.Debug("complete")
+
+	err := validate(req)
+	if err != nil {
+		logger.Error("invalid-request", err)
+		return nil, err
+	}
+
+	ttl, err := lockTTL(&models.LockRequest{TtlInSeconds: req.TtlInSeconds, TtlInMilliseconds: req.TtlInMilliseconds})
+	if err != nil {
+		logger.Error("failed-locking-lock-group", err)
+		return nil, err
+	}
+
+	call := startDBCall(ctx, logger, "db.lock-group")
+	locks, err := h.db.LockGroup(call.logger, req.Resources, ttl)
+	call.finish(err)
 	if err != nil {
 		h.exitIfUnrecoverable(err)
-		if err != models.ErrLockCollision {
-			logger.Error("failed-locking-lock", err, lager.Data{
-				"key":   req.Resource.Key,
-				"owner": req.Resource.Owner,
-			})
+		if err == models.ErrLockCollision && len(locks) > 0 {
+			for _, resource := range req.Resources {
+				if resource.Key == locks[0].Key {
+					h.contention.RecordCollision(resource.Key, resource.Owner)
+				}
+			}
+			h.sendLockHolder(ctx, logger, locks[0])
+			return nil, err
 		}
+		logger.Error("failed-locking-lock-group", err)
 		return nil, err
 	}
 
-	h.lockPick.RegisterTTL(logger, lock)
+	response := &models.LockGroupResponse{}
+	for _, lock := range locks {
+		h.contention.RecordAcquired(lock.Key, lock.Owner, ttl)
+		h.lockPick.RegisterTTL(logger, lock)
+		response.Locks = append(response.Locks, &models.LockResponse{
+			Resource:     lock.Resource,
+			FencingToken: lock.FencingToken,
+			ExpiresAt:    lock.ExpiresAt,
+		})
+	}
+	return response, nil
+}
+
+// sendLockHolder tells a client whose lock request collided who currently
+// holds the lock and for how much longer, so that it can back off sensibly.
+func (h *locketHandler) sendLockHolder(ctx context.Context, logger lager.Logger, holder *db.Lock) {
+	if holder == nil {
+		return
+	}
 
-	return &models.LockResponse{}, nil
+	md, err := models.LockHolderTrailer(h.lockHolder(holder))
+	if err == nil {
+		err = grpc.SetTrailer(ctx, md)
+	}
+	if err != nil {
+		logger.Debug("failed-to-send-lock-holder", lager.Data{"error": err.Error()})
+	}
+}
+
+// lockHolder returns the holder of lock, how long until it is free and how
+// long its owner has held it.
+func (h *locketHandler) lockHolder(lock *db.Lock) *models.LockHolder {
+	holder := lockHolderAt(lock, h.clock.Now())
+	return &holder
+}
+
+func lockHolderAt(lock *db.Lock, now time.Time) models.LockHolder {
+	// a lock reserved for its previous owner is not free until the
+	// reservation ends
+	expiresAt := lock.ExpiresAt
+	if lock.ReservedUntil > expiresAt {
+		expiresAt = lock.ReservedUntil
+	}
+
+	remaining := time.Duration(expiresAt - now.UnixNano())
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	// locks stored before AcquiredAt was recorded have no known age
+	var heldFor time.Duration
+	if lock.AcquiredAt > 0 && now.UnixNano() > lock.AcquiredAt {
+		heldFor = time.Duration(now.UnixNano() - lock.AcquiredAt)
+	}
+
+	return models.LockHolder{
+		Key:                        lock.Key,
+		Owner:                      lock.Owner,
+		RemainingTtlInMilliseconds: int64(remaining / time.Millisecond),
+		ExpiresAt:                  expiresAt,
+		HeldForInMilliseconds:      int64(heldFor / time.Millisecond),
+	}
 }
 
 func (h *locketHandler) Release(ctx context.Context, req *models.ReleaseRequest) (*models.ReleaseResponse, error) {
@@ -88,24 +518,152 @@ func (h *locketHandler) Release(ctx context.Context, req *models.ReleaseRequest)
 	logger.Debug("started")
 	defer logger.Debug("complete")
 
-	err := h.db.Release(logger, req.Resource)
+	err := validate(req)
+	if err != nil {
+		logger.Error("invalid-request", err, lager.Data{"mode": req.GetMode()})
+		return nil, err
+	}
+
+	if req.Mode == models.SHARED || req.Mode == models.SEMAPHORE {
+		call := startDBCall(ctx, logger, "db.release-shared")
+		err = h.db.ReleaseShared(call.logger, req.Resource)
+		call.finish(err)
+		if err != nil {
+			h.exitIfUnrecoverable(err)
+			return nil, err
+		}
+		h.waiters.notify(req.Resource.Key)
+		return &models.ReleaseResponse{}, nil
+	}
+
+	if req.ExpectedValue != "" || req.ExpectedFencingToken != 0 {
+		call := startDBCall(ctx, logger, "db.release-if")
+		err = h.db.ReleaseIf(call.logger, req.Resource, db.ReleaseCondition{
+			Value:        req.ExpectedValue,
+			FencingToken: req.ExpectedFencingToken,
+		})
+		call.finish(err)
+	} else {
+		call := startDBCall(ctx, logger, "db.release")
+		err = h.db.Release(call.logger, req.Resource)
+		call.finish(err)
+	}
 	if err != nil {
 		h.exitIfUnrecoverable(err)
 		return nil, err
 	}
+
+	h.contention.RecordReleased(req.Resource.Key, req.Resource.Owner)
+	h.connections.remove(req.Resource.Key, req.Resource.Owner)
+	if h.hotKeys != nil {
+		h.hotKeys.released(req.Resource.Key)
+	}
+	h.waiters.notify(req.Resource.Key)
 	return &models.ReleaseResponse{}, nil
 }
 
+// KeepAlive renews every lock added to the stream each time the client sends
+// a message, so that a client holding many locks keeps a single stream open
+// instead of calling Lock for each of them. A lock that cannot be renewed is
+// dropped from the stream and reported back in a Revocation. Locks are not
+// released when the stream ends, they expire at the end of their ttl.
+func (h *locketHandler) KeepAlive(stream models.Locket_KeepAliveServer) error {
+	logger := h.logger.Session("keep-alive")
+	logger.Debug("started")
+	defer logger.Debug("complete")
+
+	ctx := stream.Context()
+	locks := make(map[string]*models.LockRequest)
+	// reused for every message, as a stream refreshes the same locks for
+	// as long as it is open
+	var keys []string
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			logger.Info("stream-closed", lager.Data{"error": err.Error()})
+			return err
+		}
+
+		for _, key := range req.Releases {
+			lock, ok := locks[key]
+			if !ok {
+				continue
+			}
+			delete(locks, key)
+
+			_, err := h.Release(ctx, &models.ReleaseRequest{Resource: lock.Resource, Mode: lock.Mode})
+			if err != nil && err != models.ErrResourceNotFound {
+				logger.Error("failed-releasing-lock", err, lager.Data{"key": key})
+			}
+		}
+
+		for _, lock := range req.Locks {
+			if lock == nil {
+				continue
+			}
+			locks[lock.Resource.GetKey()] = lock
+		}
+
+		keys = keys[:0]
+		for key := range locks {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		response := &models.KeepAliveResponse{}
+		for _, key := range keys {
+			lock := locks[key]
+			_, err := h.lock(ctx, logger, lock)
+			if err != nil {
+				logger.Info("revoked-lock", lager.Data{"key": key, "owner": lock.Resource.GetOwner(), "reason": err.Error()})
+				delete(locks, key)
+				response.Revocations = append(response.Revocations, &models.Revocation{
+					Key:    key,
+					Owner:  lock.Resource.GetOwner(),
+					Reason: grpc.ErrorDesc(err),
+				})
+			}
+		}
+
+		err = stream.Send(response)
+		if err != nil {
+			logger.Info("stream-closed", lager.Data{"error": err.Error()})
+			return err
+		}
+	}
+}
+
 func (h *locketHandler) Fetch(ctx context.Context, req *models.FetchRequest) (*models.FetchResponse, error) {
 	logger := h.logger.Session("fetch")
 	logger.Debug("started")
 	defer logger.Debug("complete")
 
-	lock, err := h.db.Fetch(logger, req.Key)
+	err := validate(req)
+	if err != nil {
+		logger.Error("invalid-request", err, lager.Data{"typeCode": req.GetTypeCode()})
+		return nil, err
+	}
+
+	call := startDBCall(ctx, logger, "db.fetch")
+	lock, err := h.db.Fetch(call.logger, req.Key)
+	call.finish(err)
 	if err != nil {
 		h.exitIfUnrecoverable(err)
 		return nil, err
 	}
+
+	lockTypeCode := lock.TypeCode
+	if lockTypeCode == models.UNKNOWN {
+		lockTypeCode = models.GetTypeCode(lock.Type)
+	}
+	if req.TypeCode != models.UNKNOWN && lockTypeCode != req.TypeCode {
+		logger.Debug("type-mismatch", lager.Data{"key": req.Key, "type-code": req.TypeCode, "lock-type-code": lockTypeCode})
+		return nil, models.ErrResourceNotFound
+	}
 	return &models.FetchResponse{
 		Resource: lock.Resource,
 	}, nil
@@ -122,30 +680,293 @@ func (h *locketHandler) FetchAll(ctx context.Context, req *models.FetchAllReques
 		return nil, err
 	}
 
-	locks, err := h.db.FetchAll(logger, models.GetType(&models.Resource{Type: req.Type, TypeCode: req.TypeCode}))
+	lockType := models.GetType(&models.Resource{Type: req.Type, TypeCode: req.TypeCode})
+	response, err, shared := h.fetchAlls.do(ctx, lockType, func(ctx context.Context) (*models.FetchAllResponse, error) {
+		return h.fetchAll(ctx, logger, lockType)
+	})
+	if shared {
+		logger.Debug("shared-fetch-all", lager.Data{"type": lockType})
+	}
+	return response, err
+}
+
+func (h *locketHandler) fetchAll(ctx context.Context, logger lager.Logger, lockType string) (*models.FetchAllResponse, error) {
+	call := startDBCall(ctx, logger, "db.fetch-all")
+	locks, err := h.db.FetchAll(call.logger, lockType)
+	call.finish(err)
 	if err != nil {
 		h.exitIfUnrecoverable(err)
 		return nil, err
 	}
 
-	var responses []*models.Resource
-	for _, lock := range locks {
-		responses = append(responses, lock.Resource)
+	if len(locks) == 0 {
+		return &models.FetchAllResponse{}, nil
+	}
+
+	// the holders share one allocation, as presence listings can hold
+	// thousands of them
+	responses := make([]*models.Resource, len(locks))
+	holders := make([]*models.LockHolder, len(locks))
+	holderValues := make([]models.LockHolder, len(locks))
+	now := h.clock.Now()
+	for i, lock := range locks {
+		responses[i] = lock.Resource
+		holderValues[i] = lockHolderAt(lock, now)
+		holders[i] = &holderValues[i]
 	}
 
 	return &models.FetchAllResponse{
 		Resources: responses,
+		Holders:   holders,
 	}, nil
 }
 
+func (h *locketHandler) Stats(ctx context.Context, req *models.StatsRequest) (*models.StatsResponse, error) {
+	logger := h.logger.Session("stats")
+	logger.Debug("started")
+	defer logger.Debug("complete")
+
+	topN := int(req.TopN)
+	if topN <= 0 {
+		topN = defaultStatsTopN
+	}
+
+	return &models.StatsResponse{
+		ContendedKeys: h.contention.Top(topN),
+	}, nil
+}
+
+// Waiters lists the clients waiting on a key: the queued Acquire calls on this
+// server in the order they will be granted the lock, followed by any other
+// owners that have recently failed to take it.
+func (h *locketHandler) Waiters(ctx context.Context, req *models.WaitersRequest) (*models.WaitersResponse, error) {
+	logger := h.logger.Session("waiters", lager.Data{"key": req.Key})
+	logger.Debug("started")
+	defer logger.Debug("complete")
+
+	now := h.clock.Now()
+	queued := make(map[string]bool)
+
+	waiters := []*models.Waiter{}
+	for _, w := range h.waiters.list(req.Key) {
+		queued[w.owner] = true
+		waiters = append(waiters, &models.Waiter{
+			Owner:                 w.owner,
+			Identity:              w.identity,
+			Priority:              w.priority,
+			WaitingInMilliseconds: int64(now.Sub(w.since) / time.Millisecond),
+			Queued:                true,
+		})
+	}
+
+	for _, w := range h.contention.WaitQueues()[req.Key] {
+		if queued[w.Owner] {
+			continue
+		}
+		waiters = append(waiters, &models.Waiter{
+			Owner:                 w.Owner,
+			WaitingInMilliseconds: int64(now.Sub(w.WaitingSince) / time.Millisecond),
+		})
+	}
+
+	return &models.WaitersResponse{Waiters: waiters}, nil
+}
+
+// checkMaxHold returns ErrMaxHoldExceeded when the owner of resource has held
+// the lock for longer than the maximum hold time of its key.
+func (h *locketHandler) checkMaxHold(ctx context.Context, logger lager.Logger, resource *models.Resource) error {
+	maxHold := h.maxHold(resource.Key)
+	if maxHold <= 0 || models.GetType(resource) != models.LockType {
+		return nil
+	}
+
+	call := startDBCall(ctx, logger, "db.fetch")
+	existing, err := h.db.Fetch(call.logger, resource.Key)
+	call.finish(err)
+	if err == models.ErrResourceNotFound {
+		return nil
+	}
+	if err != nil {
+		h.exitIfUnrecoverable(err)
+		logger.Error("failed-fetching-lock", err)
+		return err
+	}
+
+	if existing.Owner != resource.Owner || existing.AcquiredAt == 0 {
+		return nil
+	}
+
+	held := h.clock.Now().Sub(time.Unix(0, existing.AcquiredAt))
+	if held < maxHold {
+		return nil
+	}
+
+	logger.Info("max-hold-exceeded", lager.Data{
+		"key":      resource.Key,
+		"owner":    resource.Owner,
+		"held":     held.String(),
+		"max-hold": maxHold.String(),
+	})
+	return models.ErrMaxHoldExceeded
+}
+
+func (h *locketHandler) maxHold(key string) time.Duration {
+	if maxHold, ok := h.maxHolds[key]; ok {
+		return maxHold
+	}
+	return h.defaultMaxHold
+}
+
+// dbCall is a call to the database that is being traced and timed. It is a
+// value rather than a closure so that the calls made for every request do
+// not allocate. Its logger carries its span, so that the database can trace
+// what the call is made of.
+type dbCall struct {
+	logger    lager.Logger
+	span      trace.Span
+	stopTimer func()
+}
+
+// startDBCall traces and times a call to the database made while handling
+// the request in ctx. The call must be made with its logger, and finish
+// must be called with the result.
+func startDBCall(ctx context.Context, logger lager.Logger, name string) dbCall {
+	ctx, span := tracing.StartSpan(ctx, name)
+	return dbCall{logger: tracing.WithContext(logger, ctx), span: span, stopTimer: slowlog.Track(ctx, "db")}
+}
+
+func (c dbCall) finish(err error) {
+	c.stopTimer()
+	tracing.EndSpan(c.span, err)
+}
+
+// lockTTL returns the ttl requested by req. TtlInMilliseconds takes
+// precedence over TtlInSeconds when both are set.
+func lockTTL(req *models.LockRequest) (time.Duration, error) {
+	if req.TtlInMilliseconds < 0 {
+		return 0, models.ErrInvalidTTL
+	}
+
+	if req.TtlInMilliseconds > 0 {
+		return duration(req.TtlInMilliseconds, time.Millisecond)
+	}
+
+	if req.TtlInSeconds <= 0 {
+		return 0, models.ErrInvalidTTL
+	}
+	return duration(req.TtlInSeconds, time.Second)
+}
+
+// duration returns n units as a duration, or ErrInvalidTTL if it is longer
+// than maxTTL, checking before multiplying so that it cannot overflow.
+func duration(n int64, unit time.Duration) (time.Duration, error) {
+	if n > int64(maxTTL/unit) {
+		return 0, models.ErrInvalidTTL
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// validateResource checks that resource fits in the lock tables, so that
+// it is not truncated or rejected by the database.
+func validateResource(resource *models.Resource) error {
+	if resource == nil || !validString(resource.Key, models.MaxKeyLength) {
+		return models.ErrInvalidKey
+	}
+	if !validString(resource.Owner, models.MaxOwnerLength) {
+		return models.ErrInvalidOwner
+	}
+	if !validString(resource.Value, models.MaxValueLength) {
+		return models.ErrInvalidValue
+	}
+	return nil
+}
+
+func validString(s string, maxLength int) bool {
+	// checked in bytes first, as a string has at least as many bytes as
+	// characters, so that huge strings are not counted
+	if len(s) > maxLength && (len(s) > utf8.UTFMax*maxLength || utf8.RuneCountInString(s) > maxLength) {
+		return false
+	}
+	return utf8.ValidString(s)
+}
+
 func validate(req interface{}) error {
 	var reqType string
 	var reqTypeCode models.TypeCode
 
 	switch incomingReq := req.(type) {
 	case *models.LockRequest:
+		if err := validateResource(incomingReq.Resource); err != nil {
+			return err
+		}
+		if _, found := models.LockMode_name[int32(incomingReq.GetMode())]; !found {
+			return models.ErrInvalidLockMode
+		}
+		if incomingReq.GetMode() == models.SEMAPHORE && incomingReq.GetCapacity() <= 0 {
+			return models.ErrInvalidCapacity
+		}
+		if incomingReq.GraceInMilliseconds < 0 || incomingReq.GraceInMilliseconds > int64(maxTTL/time.Millisecond) {
+			return models.ErrInvalidTTL
+		}
+		// only an exclusive lock has a single previous owner to reserve it for
+		if incomingReq.GraceInMilliseconds > 0 && incomingReq.GetMode() != models.EXCLUSIVE {
+			return models.ErrInvalidLockMode
+		}
 		reqType = incomingReq.Resource.GetType()
 		reqTypeCode = incomingReq.Resource.GetTypeCode()
+	case *models.LockGroupRequest:
+		if len(incomingReq.Resources) == 0 {
+			return models.ErrInvalidLockGroup
+		}
+		keys := make(map[string]bool, len(incomingReq.Resources))
+		for _, resource := range incomingReq.Resources {
+			if resource == nil || keys[resource.Key] {
+				return models.ErrInvalidLockGroup
+			}
+			keys[resource.Key] = true
+			if resource.Owner == "" {
+				return models.ErrInvalidOwner
+			}
+			err := validate(&models.LockRequest{Resource: resource})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case *models.BarrierRequest:
+		if incomingReq.Key == "" || incomingReq.Participant == "" || incomingReq.Participants <= 0 {
+			return models.ErrInvalidBarrier
+		}
+		// arrivals are keyed by the barrier and participant together
+		if !validString(incomingReq.Key+"/"+incomingReq.Participant, models.MaxKeyLength) {
+			return models.ErrInvalidBarrier
+		}
+		if incomingReq.TtlInMilliseconds <= 0 || incomingReq.TtlInMilliseconds > int64(maxTTL/time.Millisecond) {
+			return models.ErrInvalidTTL
+		}
+		return nil
+	case *models.ReleaseRequest:
+		if err := validateResource(incomingReq.Resource); err != nil {
+			return err
+		}
+		if _, found := models.LockMode_name[int32(incomingReq.GetMode())]; !found {
+			return models.ErrInvalidLockMode
+		}
+		// shared holds have no fencing token, so only exclusive locks can be
+		// released conditionally
+		conditional := incomingReq.ExpectedValue != "" || incomingReq.ExpectedFencingToken != 0
+		if conditional && incomingReq.GetMode() != models.EXCLUSIVE {
+			return models.ErrInvalidLockMode
+		}
+		return nil
+	case *models.FetchRequest:
+		if !validString(incomingReq.Key, models.MaxKeyLength) {
+			return models.ErrInvalidKey
+		}
+		if _, found := models.TypeCode_name[int32(incomingReq.GetTypeCode())]; !found {
+			return models.ErrInvalidType
+		}
+		return nil
 	case *models.FetchAllRequest:
 		reqType = incomingReq.GetType()
 		reqTypeCode = incomingReq.GetTypeCode()
@@ -3,23 +3,40 @@ package handlers_test
 import (
 	"context"
 	"errors"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/bbs/db/sqldb/helpers"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/contention/contentionfakes"
 	"code.cloudfoundry.org/locket/db"
 	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/deadlock"
+	"code.cloudfoundry.org/locket/deadlock/deadlockfakes"
 	"code.cloudfoundry.org/locket/expiration/expirationfakes"
 	"code.cloudfoundry.org/locket/handlers"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/tracing"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"google.golang.org/grpc/peer"
 )
 
 var _ = Describe("Lock", func() {
 	var (
 		fakeLockDB    *dbfakes.FakeLockDB
 		fakeLockPick  *expirationfakes.FakeLockPick
+		fakeTracker   *contentionfakes.FakeTracker
+		fakeDetector  *deadlockfakes.FakeDetector
+		fakeClock     *fakeclock.FakeClock
 		logger        *lagertest.TestLogger
 		locketHandler models.LocketServer
 		resource      *models.Resource
@@ -29,6 +46,9 @@ var _ = Describe("Lock", func() {
 	BeforeEach(func() {
 		fakeLockDB = &dbfakes.FakeLockDB{}
 		fakeLockPick = &expirationfakes.FakeLockPick{}
+		fakeTracker = &contentionfakes.FakeTracker{}
+		fakeDetector = &deadlockfakes.FakeDetector{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
 		logger = lagertest.NewTestLogger("locket-handler")
 		exitCh = make(chan struct{}, 1)
 
@@ -39,7 +59,7 @@ var _ = Describe("Lock", func() {
 			Type:  "lock",
 		}
 
-		locketHandler = handlers.NewLocketHandler(logger, fakeLockDB, fakeLockPick, exitCh)
+		locketHandler = handlers.NewLocketHandler(logger, fakeLockDB, fakeLockPick, fakeTracker, fakeDetector, fakeClock, exitCh)
 	})
 
 	Context("Lock", func() {
@@ -58,6 +78,8 @@ var _ = Describe("Lock", func() {
 				Resource:      resource,
 				TtlInSeconds:  10,
 				ModifiedIndex: 2,
+				FencingToken:  7,
+				ExpiresAt:     fakeClock.Now().Add(10 * time.Second).UnixNano(),
 			}
 
 			fakeLockDB.LockReturns(expectedLock, nil)
@@ -70,174 +92,1405 @@ var _ = Describe("Lock", func() {
 			Expect(fakeLockDB.LockCallCount()).Should(Equal(1))
 			_, actualResource, ttl := fakeLockDB.LockArgsForCall(0)
 			Expect(actualResource).To(Equal(resource))
-			Expect(ttl).To(BeEquivalentTo(10))
+			Expect(ttl).To(Equal(10 * time.Second))
+		})
+
+		It("returns the stored resource with its fencing token and expiry", func() {
+			response, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response).To(Equal(&models.LockResponse{
+				Resource:     resource,
+				FencingToken: 7,
+				ExpiresAt:    expectedLock.ExpiresAt,
+			}))
+		})
+
+		It("records the acquisition with the contention tracker", func() {
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeTracker.RecordAcquiredCallCount()).To(Equal(1))
+			key, owner, ttl := fakeTracker.RecordAcquiredArgsForCall(0)
+			Expect(key).To(Equal(resource.Key))
+			Expect(owner).To(Equal(resource.Owner))
+			Expect(ttl).To(Equal(10 * time.Second))
 		})
 
 		It("registers the lock and ttl with the lock pick", func() {
 			_, err := locketHandler.Lock(context.Background(), request)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(1))
-			_, lock := fakeLockPick.RegisterTTLArgsForCall(0)
-			Expect(lock).To(Equal(expectedLock))
+			Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(1))
+			_, lock := fakeLockPick.RegisterTTLArgsForCall(0)
+			Expect(lock).To(Equal(expectedLock))
+		})
+
+		Context("when the request is in shared mode", func() {
+			BeforeEach(func() {
+				request.Mode = models.SHARED
+				fakeLockDB.LockSharedReturns(expectedLock, nil)
+			})
+
+			It("takes a shared hold in the database", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+				Expect(fakeLockDB.LockSharedCallCount()).To(Equal(1))
+				_, actualResource, ttl := fakeLockDB.LockSharedArgsForCall(0)
+				Expect(actualResource).To(Equal(resource))
+				Expect(ttl).To(Equal(10 * time.Second))
+			})
+
+			It("does not register the hold with the lock pick", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(0))
+			})
+
+			Context("when the key is held exclusively", func() {
+				BeforeEach(func() {
+					fakeLockDB.LockSharedReturns(nil, models.ErrLockCollision)
+				})
+
+				It("returns the collision error", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(Equal(models.ErrLockCollision))
+				})
+			})
+		})
+
+		Context("when the request is for a semaphore slot", func() {
+			BeforeEach(func() {
+				request.Mode = models.SEMAPHORE
+				request.Capacity = 3
+				fakeLockDB.LockSemaphoreReturns(expectedLock, nil)
+			})
+
+			It("takes a slot with the requested capacity", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeLockDB.LockSemaphoreCallCount()).To(Equal(1))
+				_, actualResource, capacity, ttl := fakeLockDB.LockSemaphoreArgsForCall(0)
+				Expect(actualResource).To(Equal(resource))
+				Expect(capacity).To(Equal(3))
+				Expect(ttl).To(Equal(10 * time.Second))
+				Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(0))
+			})
+
+			Context("and no capacity", func() {
+				BeforeEach(func() {
+					request.Capacity = 0
+				})
+
+				It("returns a validation error", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(Equal(models.ErrInvalidCapacity))
+					Expect(fakeLockDB.LockSemaphoreCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the request has an unknown mode", func() {
+			BeforeEach(func() {
+				request.Mode = models.LockMode(7)
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidLockMode))
+				Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("validate lock type", func() {
+			Context("when type string is set", func() {
+				It("should be invalid with type not set to presence/lock", func() {
+					request.Resource.Type = "random"
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(HaveOccurred())
+				})
+
+				It("should be valid with type set to presence", func() {
+					request.Resource.Type = "presence"
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should be valid with type set to lock", func() {
+					request.Resource.Type = "lock"
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when type_code is set", func() {
+				It("should ba invalid when mismatching non-empty type", func() {
+					request.Resource.Type = "lock"
+					request.Resource.TypeCode = models.PRESENCE
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(HaveOccurred())
+
+					request.Resource.Type = "presence"
+					request.Resource.TypeCode = models.LOCK
+					_, err = locketHandler.Lock(context.Background(), request)
+					Expect(err).To(HaveOccurred())
+				})
+
+				It("should be valid when type and type code match", func() {
+					request.Resource.Type = "lock"
+					request.Resource.TypeCode = models.LOCK
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).NotTo(HaveOccurred())
+
+					request.Resource.Type = "presence"
+					request.Resource.TypeCode = models.PRESENCE
+					_, err = locketHandler.Lock(context.Background(), request)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should be valid on a valid type code and empty type", func() {
+					request.Resource.Type = ""
+					request.Resource.TypeCode = models.LOCK
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should be invalid on an UNKNOWN type code and empty type", func() {
+					request.Resource.Type = ""
+					request.Resource.TypeCode = models.UNKNOWN
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(HaveOccurred())
+				})
+
+				It("should be invalid on an non-existent type code", func() {
+					request.Resource.Type = ""
+					request.Resource.TypeCode = 4
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when request does not have TTL", func() {
+			BeforeEach(func() {
+				request = &models.LockRequest{
+					Resource: resource,
+				}
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(models.ErrInvalidTTL))
+				Expect(logger).To(gbytes.Say(models.ErrInvalidTTL.Error()))
+				Expect(logger).To(gbytes.Say("\"key\":"))
+				Expect(logger).To(gbytes.Say("\"owner\":"))
+			})
+		})
+
+		Context("when the request has a millisecond TTL", func() {
+			BeforeEach(func() {
+				request.TtlInMilliseconds = 250
+			})
+
+			It("takes precedence over the TTL in seconds", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, ttl := fakeLockDB.LockArgsForCall(0)
+				Expect(ttl).To(Equal(250 * time.Millisecond))
+			})
+
+			Context("and no TTL in seconds", func() {
+				BeforeEach(func() {
+					request.TtlInSeconds = 0
+				})
+
+				It("locks with the millisecond TTL", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, ttl := fakeLockDB.LockArgsForCall(0)
+					Expect(ttl).To(Equal(250 * time.Millisecond))
+				})
+			})
+
+			Context("and it is negative", func() {
+				BeforeEach(func() {
+					request.TtlInMilliseconds = -1
+				})
+
+				It("returns a validation error", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(Equal(models.ErrInvalidTTL))
+					Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("and it would overflow", func() {
+				BeforeEach(func() {
+					request.TtlInMilliseconds = math.MaxInt64
+				})
+
+				It("returns a validation error", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(Equal(models.ErrInvalidTTL))
+					Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the TTL in seconds would overflow", func() {
+			BeforeEach(func() {
+				request.TtlInSeconds = math.MaxInt64 / 1000
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidTTL))
+				Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the request has no resource", func() {
+			It("returns a validation error", func() {
+				_, err := locketHandler.Lock(context.Background(), &models.LockRequest{TtlInSeconds: 10})
+				Expect(err).To(Equal(models.ErrInvalidKey))
+			})
+		})
+
+		Context("when the resource does not fit in the lock tables", func() {
+			It("rejects keys that are too long", func() {
+				resource.Key = strings.Repeat("k", models.MaxKeyLength+1)
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidKey))
+				Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+			})
+
+			It("counts characters rather than bytes", func() {
+				resource.Key = strings.Repeat("é", models.MaxKeyLength)
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("rejects keys that are not valid utf-8", func() {
+				resource.Key = "bad\xff"
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidKey))
+			})
+
+			It("rejects owners that are too long", func() {
+				resource.Owner = strings.Repeat("o", models.MaxOwnerLength+1)
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidOwner))
+			})
+
+			It("rejects values that are too long", func() {
+				resource.Value = strings.Repeat("v", models.MaxValueLength+1)
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidValue))
+			})
+		})
+
+		Context("when the request has a grace window", func() {
+			BeforeEach(func() {
+				request.GraceInMilliseconds = 3000
+				fakeLockDB.LockWithGraceReturns(expectedLock, nil)
+			})
+
+			It("reserves the lock for the owner after it expires", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+				Expect(fakeLockDB.LockWithGraceCallCount()).To(Equal(1))
+				_, actualResource, ttl, grace := fakeLockDB.LockWithGraceArgsForCall(0)
+				Expect(actualResource).To(Equal(resource))
+				Expect(ttl).To(Equal(10 * time.Second))
+				Expect(grace).To(Equal(3 * time.Second))
+			})
+
+			Context("and it is negative", func() {
+				BeforeEach(func() {
+					request.GraceInMilliseconds = -1
+				})
+
+				It("returns a validation error", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(Equal(models.ErrInvalidTTL))
+					Expect(fakeLockDB.LockWithGraceCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("and the request is in shared mode", func() {
+				BeforeEach(func() {
+					request.Mode = models.SHARED
+				})
+
+				It("returns a validation error", func() {
+					_, err := locketHandler.Lock(context.Background(), request)
+					Expect(err).To(Equal(models.ErrInvalidLockMode))
+					Expect(fakeLockDB.LockSharedCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the request does not have an owner", func() {
+			BeforeEach(func() {
+				resource.Owner = ""
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(Equal(models.ErrInvalidOwner))
+				Expect(logger).To(gbytes.Say(models.ErrInvalidOwner.Error()))
+				Expect(logger).To(gbytes.Say("\"key\":"))
+				Expect(logger).To(gbytes.Say("\"owner\":"))
+			})
+		})
+
+		Context("when locking errors", func() {
+			var (
+				err error
+			)
+
+			BeforeEach(func() {
+				fakeLockDB.LockReturns(nil, errors.New("Boom."))
+			})
+
+			JustBeforeEach(func() {
+				_, err = locketHandler.Lock(context.Background(), request)
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("logs the error with identifying information", func() {
+				Expect(logger).To(gbytes.Say("Boom."))
+				Expect(logger).To(gbytes.Say("\"key\":"))
+				Expect(logger).To(gbytes.Say("\"owner\":"))
+			})
+
+			Context("when lock collision error occurs", func() {
+				BeforeEach(func() {
+					fakeLockDB.LockReturns(nil, models.ErrLockCollision)
+				})
+
+				It("does not log the error", func() {
+					Expect(logger).NotTo(gbytes.Say("lock-collision"))
+				})
+
+				It("records the collision", func() {
+					Expect(fakeTracker.RecordCollisionCallCount()).To(Equal(1))
+					key, owner := fakeTracker.RecordCollisionArgsForCall(0)
+					Expect(key).To(Equal(resource.Key))
+					Expect(owner).To(Equal(resource.Owner))
+				})
+
+				Context("and the current holder is returned", func() {
+					BeforeEach(func() {
+						fakeLockDB.LockReturns(&db.Lock{
+							Resource:  &models.Resource{Key: resource.Key, Owner: "someone-else"},
+							ExpiresAt: fakeClock.Now().Add(time.Second).UnixNano(),
+						}, models.ErrLockCollision)
+					})
+
+					It("still returns the collision error", func() {
+						Expect(err).To(Equal(models.ErrLockCollision))
+					})
+				})
+			})
+		})
+
+		Context("when an unrecoverable error is returned", func() {
+			BeforeEach(func() {
+				fakeLockDB.LockReturns(nil, helpers.ErrUnrecoverableError)
+			})
+
+			It("logs and writes to the exit channel", func() {
+				locketHandler.Lock(context.Background(), request)
+				Expect(logger).To(gbytes.Say("unrecoverable-error"))
+				Expect(exitCh).To(Receive())
+			})
+		})
+	})
+
+	Context("LockGroup", func() {
+		var (
+			request *models.LockGroupRequest
+			other   *models.Resource
+			locks   []*db.Lock
+		)
+
+		BeforeEach(func() {
+			other = &models.Resource{Key: "other", Owner: "myself", Type: "lock"}
+			request = &models.LockGroupRequest{
+				Resources:    []*models.Resource{resource, other},
+				TtlInSeconds: 10,
+			}
+
+			locks = []*db.Lock{
+				{Resource: resource, TtlInSeconds: 10, FencingToken: 3},
+				{Resource: other, TtlInSeconds: 10, FencingToken: 4},
+			}
+			fakeLockDB.LockGroupReturns(locks, nil)
+		})
+
+		It("locks every resource in the database", func() {
+			response, err := locketHandler.LockGroup(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLockDB.LockGroupCallCount()).To(Equal(1))
+			_, resources, ttl := fakeLockDB.LockGroupArgsForCall(0)
+			Expect(resources).To(Equal([]*models.Resource{resource, other}))
+			Expect(ttl).To(Equal(10 * time.Second))
+
+			Expect(response.Locks).To(Equal([]*models.LockResponse{
+				{Resource: resource, FencingToken: 3},
+				{Resource: other, FencingToken: 4},
+			}))
+		})
+
+		It("registers every lock with the lock pick", func() {
+			_, err := locketHandler.LockGroup(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(2))
+			Expect(fakeTracker.RecordAcquiredCallCount()).To(Equal(2))
+		})
+
+		Context("when a key is held by another owner", func() {
+			BeforeEach(func() {
+				fakeLockDB.LockGroupReturns([]*db.Lock{{Resource: &models.Resource{Key: "other", Owner: "someone-else"}}}, models.ErrLockCollision)
+			})
+
+			It("returns the collision and records it against that key", func() {
+				_, err := locketHandler.LockGroup(context.Background(), request)
+				Expect(err).To(Equal(models.ErrLockCollision))
+
+				Expect(fakeTracker.RecordCollisionCallCount()).To(Equal(1))
+				key, owner := fakeTracker.RecordCollisionArgsForCall(0)
+				Expect(key).To(Equal("other"))
+				Expect(owner).To(Equal("myself"))
+				Expect(fakeLockPick.RegisterTTLCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the group is empty", func() {
+			BeforeEach(func() {
+				request.Resources = nil
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.LockGroup(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidLockGroup))
+			})
+		})
+
+		Context("when a key appears twice", func() {
+			BeforeEach(func() {
+				request.Resources = append(request.Resources, resource)
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.LockGroup(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidLockGroup))
+				Expect(fakeLockDB.LockGroupCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a resource has no owner", func() {
+			BeforeEach(func() {
+				other.Owner = ""
+			})
+
+			It("returns a validation error", func() {
+				_, err := locketHandler.LockGroup(context.Background(), request)
+				Expect(err).To(Equal(models.ErrInvalidOwner))
+			})
+		})
+	})
+
+	Context("when locks have a maximum hold time", func() {
+		var request *models.LockRequest
+
+		BeforeEach(func() {
+			locketHandler = handlers.NewLocketHandler(logger, fakeLockDB, fakeLockPick, fakeTracker, fakeDetector, fakeClock, exitCh).
+				WithMaxHold(time.Minute, map[string]time.Duration{"unlimited": 0})
+
+			request = &models.LockRequest{Resource: resource, TtlInSeconds: 10}
+			fakeLockDB.LockReturns(&db.Lock{Resource: resource}, nil)
+		})
+
+		It("lets the owner refresh the lock until it has held it for the maximum hold time", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: resource, AcquiredAt: fakeClock.Now().Add(-59 * time.Second).UnixNano()}, nil)
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		})
+
+		It("refuses to refresh the lock once the owner has held it for the maximum hold time", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: resource, AcquiredAt: fakeClock.Now().Add(-time.Minute).UnixNano()}, nil)
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).To(Equal(models.ErrMaxHoldExceeded))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+		})
+
+		It("lets other owners try to take the lock", func() {
+			fakeLockDB.FetchReturns(&db.Lock{
+				Resource:   &models.Resource{Key: resource.Key, Owner: "someone-else", Type: "lock"},
+				AcquiredAt: fakeClock.Now().Add(-time.Hour).UnixNano(),
+			}, nil)
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		})
+
+		It("grants locks that are not held", func() {
+			fakeLockDB.FetchReturns(nil, models.ErrResourceNotFound)
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uses the maximum hold time configured for the key", func() {
+			request.Resource = &models.Resource{Key: "unlimited", Owner: "myself", Type: "lock"}
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(0))
+		})
+
+		It("does not limit presences", func() {
+			request.Resource = &models.Resource{Key: "test", Owner: "myself", Type: "presence"}
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the database refuses a lock held for longer than its maximum hold time", func() {
+		BeforeEach(func() {
+			fakeLockDB.LockReturns(nil, models.ErrMaxHoldExceeded)
+		})
+
+		It("returns the error without fetching the lock first", func() {
+			_, err := locketHandler.Lock(context.Background(), &models.LockRequest{Resource: resource, TtlInSeconds: 10})
+			Expect(err).To(Equal(models.ErrMaxHoldExceeded))
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(0))
+			Expect(logger).NotTo(gbytes.Say("failed-locking-lock"))
+		})
+	})
+
+	Context("when lock requests carry a request ID", func() {
+		var request *models.LockRequest
+
+		BeforeEach(func() {
+			locketHandler = handlers.NewLocketHandler(logger, fakeLockDB, fakeLockPick, fakeTracker, fakeDetector, fakeClock, exitCh).
+				WithRequestIDWindow(time.Minute)
+
+			request = &models.LockRequest{Resource: resource, TtlInSeconds: 10, RequestId: "request-1"}
+			fakeLockDB.LockReturns(&db.Lock{
+				Resource:     resource,
+				FencingToken: 3,
+				ExpiresAt:    fakeClock.Now().Add(10 * time.Second).UnixNano(),
+			}, nil)
+		})
+
+		It("returns the original response to a retried request without locking again", func() {
+			first, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(Equal(first))
+
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+			Expect(fakeTracker.RecordAcquiredCallCount()).To(Equal(1))
+			Expect(logger).To(gbytes.Say("duplicate-request"))
+		})
+
+		It("deduplicates retried Acquire requests", func() {
+			_, err := locketHandler.Acquire(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = locketHandler.Acquire(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		})
+
+		It("locks again for a different request ID", func() {
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = locketHandler.Lock(context.Background(), &models.LockRequest{Resource: resource, TtlInSeconds: 10, RequestId: "request-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("does not deduplicate requests for another owner", func() {
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			other := &models.Resource{Key: resource.Key, Owner: "someone-else", Type: "lock"}
+			_, err = locketHandler.Lock(context.Background(), &models.LockRequest{Resource: other, TtlInSeconds: 10, RequestId: "request-1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("does not remember failed requests", func() {
+			fakeLockDB.LockReturnsOnCall(0, nil, models.ErrLockCollision)
+
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).To(Equal(models.ErrLockCollision))
+
+			_, err = locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("forgets a request once the lock it granted has expired", func() {
+			_, err := locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.Increment(10 * time.Second)
+
+			_, err = locketHandler.Lock(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		Context("when the window is disabled", func() {
+			BeforeEach(func() {
+				locketHandler = handlers.NewLocketHandler(logger, fakeLockDB, fakeLockPick, fakeTracker, fakeDetector, fakeClock, exitCh)
+			})
+
+			It("locks every time", func() {
+				_, err := locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = locketHandler.Lock(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+			})
+		})
+	})
+
+	Context("when hot keys are remembered", func() {
+		var (
+			holder     *db.Lock
+			contenders []*models.LockRequest
+		)
+
+		contend := func(i int) error {
+			_, err := locketHandler.Lock(context.Background(), contenders[i])
+			return err
+		}
+
+		BeforeEach(func() {
+			locketHandler = handlers.NewLocketHandler(logger, fakeLockDB, fakeLockPick, fakeTracker, fakeDetector, fakeClock, exitCh).
+				WithHotKeyWindow(5 * time.Second)
+
+			holder = &db.Lock{
+				Resource:  &models.Resource{Key: "test", Owner: "holder", Type: "lock"},
+				ExpiresAt: fakeClock.Now().Add(10 * time.Second).UnixNano(),
+			}
+			contenders = nil
+			for _, owner := range []string{"contender-1", "contender-2"} {
+				contenders = append(contenders, &models.LockRequest{
+					Resource:     &models.Resource{Key: "test", Owner: owner, Type: "lock"},
+					TtlInSeconds: 10,
+				})
+			}
+			fakeLockDB.LockReturns(holder, models.ErrLockCollision)
+		})
+
+		It("turns other owners away with the holder it saw without asking the database", func() {
+			Expect(contend(0)).To(Equal(models.ErrLockCollision))
+			Expect(contend(1)).To(Equal(models.ErrLockCollision))
+			Expect(contend(0)).To(Equal(models.ErrLockCollision))
+
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+			Expect(fakeTracker.RecordCollisionCallCount()).To(Equal(3))
+			Expect(logger).To(gbytes.Say("collided-with-remembered-holder"))
+		})
+
+		It("still refreshes the holder's lock in the database", func() {
+			Expect(contend(0)).To(Equal(models.ErrLockCollision))
+
+			fakeLockDB.LockReturns(holder, nil)
+			_, err := locketHandler.Lock(context.Background(), &models.LockRequest{Resource: holder.Resource, TtlInSeconds: 10})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+
+			Expect(contend(1)).To(Equal(models.ErrLockCollision))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("asks the database again once the window has passed", func() {
+			Expect(contend(0)).To(Equal(models.ErrLockCollision))
+
+			fakeClock.Increment(5 * time.Second)
+			Expect(contend(1)).To(Equal(models.ErrLockCollision))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("asks the database again once the holder's lock has expired", func() {
+			holder.ExpiresAt = fakeClock.Now().Add(time.Second).UnixNano()
+			Expect(contend(0)).To(Equal(models.ErrLockCollision))
+
+			fakeClock.Increment(time.Second)
+			Expect(contend(1)).To(Equal(models.ErrLockCollision))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("forgets the holder once it releases the lock on this server", func() {
+			Expect(contend(0)).To(Equal(models.ErrLockCollision))
+
+			_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: holder.Resource})
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeLockDB.LockReturns(&db.Lock{Resource: contenders[1].Resource}, nil)
+			Expect(contend(1)).To(Succeed())
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("does not remember the holders of keys that saw no collision", func() {
+			fakeLockDB.LockReturns(&db.Lock{Resource: contenders[0].Resource}, nil)
+			Expect(contend(0)).To(Succeed())
+
+			fakeLockDB.LockReturns(&db.Lock{Resource: contenders[0].Resource}, models.ErrLockCollision)
+			Expect(contend(1)).To(Equal(models.ErrLockCollision))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("lets contenders that arrive together wait for the first to ask the database", func() {
+			blockLock := make(chan struct{})
+			fakeLockDB.LockStub = func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+				<-blockLock
+				return holder, models.ErrLockCollision
+			}
+
+			errs := make(chan error, 2)
+			go func() { errs <- contend(0) }()
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+			go func() { errs <- contend(1) }()
+			Consistently(fakeLockDB.LockCallCount).Should(Equal(1))
+
+			close(blockLock)
+			Eventually(errs).Should(Receive(Equal(models.ErrLockCollision)))
+			Eventually(errs).Should(Receive(Equal(models.ErrLockCollision)))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		})
+
+		It("gives up waiting when the request is cancelled", func() {
+			blockLock := make(chan struct{})
+			defer close(blockLock)
+			fakeLockDB.LockStub = func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+				<-blockLock
+				return holder, models.ErrLockCollision
+			}
+
+			go contend(0)
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := locketHandler.Lock(ctx, contenders[1])
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
+	Context("when the same owner locks from different connections", func() {
+		var (
+			request        *models.LockRequest
+			firstConn      context.Context
+			secondConn     context.Context
+			lockAcquiredAt int64
+		)
+
+		connectionFrom := func(port int) context.Context {
+			return peer.NewContext(context.Background(), &peer.Peer{
+				Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: port},
+			})
+		}
+
+		BeforeEach(func() {
+			request = &models.LockRequest{Resource: resource, TtlInSeconds: 10}
+			firstConn = connectionFrom(50001)
+			secondConn = connectionFrom(50002)
+
+			lockAcquiredAt = fakeClock.Now().UnixNano()
+			fakeLockDB.LockStub = func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+				return &db.Lock{
+					Resource:   resource,
+					ExpiresAt:  fakeClock.Now().Add(ttl).UnixNano(),
+					AcquiredAt: lockAcquiredAt,
+				}, nil
+			}
+		})
+
+		It("tells whether the lock was acquired or refreshed", func() {
+			response, err := locketHandler.Lock(firstConn, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Outcome).To(Equal(models.ACQUIRED))
+
+			fakeClock.Increment(time.Second)
+			response, err = locketHandler.Lock(firstConn, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Outcome).To(Equal(models.REFRESHED))
+		})
+
+		It("refreshes the lock from the new connection by default", func() {
+			_, err := locketHandler.Lock(firstConn, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.Increment(time.Second)
+			response, err := locketHandler.Lock(secondConn, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Outcome).To(Equal(models.REFRESHED_FROM_NEW_CONNECTION))
+			Expect(logger).To(gbytes.Say("refreshing-lock-from-new-connection"))
+
+			fakeClock.Increment(time.Second)
+			response, err = locketHandler.Lock(secondConn, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Outcome).To(Equal(models.REFRESHED))
+		})
+
+		Context("when the request rejects duplicates", func() {
+			BeforeEach(func() {
+				request.OnDuplicate = models.REJECT_DUPLICATE
+			})
+
+			It("rejects the lock from the new connection", func() {
+				_, err := locketHandler.Lock(firstConn, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = locketHandler.Lock(secondConn, request)
+				Expect(err).To(Equal(models.ErrHeldByOtherConnection))
+				Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+			})
+
+			It("still lets the same connection refresh the lock", func() {
+				_, err := locketHandler.Lock(firstConn, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = locketHandler.Lock(firstConn, request)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("lets the new connection take the lock once it has expired", func() {
+				_, err := locketHandler.Lock(firstConn, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeClock.Increment(10 * time.Second)
+				lockAcquiredAt = fakeClock.Now().UnixNano()
+				response, err := locketHandler.Lock(secondConn, request)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(response.Outcome).To(Equal(models.ACQUIRED))
+			})
+
+			It("lets the new connection take the lock once it has been released", func() {
+				_, err := locketHandler.Lock(firstConn, request)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = locketHandler.Release(firstConn, &models.ReleaseRequest{Resource: resource})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = locketHandler.Lock(secondConn, request)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Context("Acquire", func() {
+		var (
+			request      *models.LockRequest
+			expectedLock *db.Lock
+			available    int32
+		)
+
+		BeforeEach(func() {
+			request = &models.LockRequest{Resource: resource, TtlInSeconds: 10}
+			expectedLock = &db.Lock{Resource: resource, TtlInSeconds: 10, FencingToken: 7}
+
+			atomic.StoreInt32(&available, 0)
+			fakeLockDB.LockStub = func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+				if atomic.LoadInt32(&available) == 0 {
+					return &db.Lock{Resource: &models.Resource{Key: resource.Key, Owner: "someone-else"}}, models.ErrLockCollision
+				}
+				return &db.Lock{Resource: resource, FencingToken: 7}, nil
+			}
+		})
+
+		acquire := func(ctx context.Context, req *models.LockRequest) <-chan error {
+			errCh := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := locketHandler.Acquire(ctx, req)
+				errCh <- err
+			}()
+			return errCh
+		}
+
+		It("returns as soon as the lock is available", func() {
+			atomic.StoreInt32(&available, 1)
+
+			response, err := locketHandler.Acquire(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Resource).To(Equal(expectedLock.Resource))
+			Expect(response.FencingToken).To(BeEquivalentTo(7))
+		})
+
+		It("retries until the lock becomes available", func() {
+			errCh := acquire(context.Background(), request)
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+			Consistently(errCh).ShouldNot(Receive())
+
+			atomic.StoreInt32(&available, 1)
+			fakeClock.WaitForWatcherAndIncrement(500 * time.Millisecond)
+
+			Eventually(errCh).Should(Receive(BeNil()))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+		})
+
+		It("gives up with a collision error when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			errCh := acquire(ctx, request)
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+			cancel()
+			Eventually(errCh).Should(Receive(Equal(models.ErrLockCollision)))
+		})
+
+		It("returns validation errors without waiting", func() {
+			request.TtlInSeconds = 0
+
+			_, err := locketHandler.Acquire(context.Background(), request)
+			Expect(err).To(Equal(models.ErrInvalidTTL))
+		})
+
+		It("records the holder it is blocked on with the deadlock detector", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			waiter := deadlock.NewWaiter(resource.Key, resource.Owner, fakeClock.Now())
+			fakeDetector.WaitReturns(waiter)
+
+			errCh := acquire(ctx, request)
+			Eventually(fakeDetector.BlockedCallCount).Should(Equal(1))
+
+			Expect(fakeDetector.WaitCallCount()).To(Equal(1))
+			key, owner := fakeDetector.WaitArgsForCall(0)
+			Expect(key).To(Equal(resource.Key))
+			Expect(owner).To(Equal(resource.Owner))
+
+			blocked, holder := fakeDetector.BlockedArgsForCall(0)
+			Expect(blocked).To(Equal(waiter))
+			Expect(holder).To(Equal("someone-else"))
+
+			cancel()
+			Eventually(errCh).Should(Receive())
+			Expect(fakeDetector.DoneCallCount()).To(Equal(1))
+			Expect(fakeDetector.DoneArgsForCall(0)).To(Equal(waiter))
+		})
+
+		It("clears the holder it recorded before trying the lock again", func() {
+			waiter := deadlock.NewWaiter(resource.Key, resource.Owner, fakeClock.Now())
+			fakeDetector.WaitReturns(waiter)
+
+			errCh := acquire(context.Background(), request)
+			Eventually(fakeDetector.BlockedCallCount).Should(Equal(1))
+
+			atomic.StoreInt32(&available, 1)
+			fakeClock.WaitForWatcherAndIncrement(500 * time.Millisecond)
+			Eventually(errCh).Should(Receive(BeNil()))
+
+			Expect(fakeDetector.BlockedCallCount()).To(Equal(2))
+			blocked, holder := fakeDetector.BlockedArgsForCall(1)
+			Expect(blocked).To(Equal(waiter))
+			Expect(holder).To(BeEmpty())
+		})
+
+		It("returns a deadlock error when the detector aborts the wait", func() {
+			waiter := deadlock.NewWaiter(resource.Key, resource.Owner, fakeClock.Now())
+			fakeDetector.WaitReturns(waiter)
+
+			errCh := acquire(context.Background(), request)
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+			waiter.Abort()
+			Eventually(errCh).Should(Receive(Equal(models.ErrDeadlock)))
+		})
+
+		Context("when the requests are fair", func() {
+			It("grants the lock to the longest waiting request first", func() {
+				first := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "first", Type: "lock"}, TtlInSeconds: 10, Fair: true}
+				second := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "second", Type: "lock"}, TtlInSeconds: 10, Fair: true}
+
+				firstErr := acquire(context.Background(), first)
+				Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+				secondErr := acquire(context.Background(), second)
+				Consistently(fakeLockDB.LockCallCount).Should(Equal(1))
+
+				atomic.StoreInt32(&available, 1)
+				_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource})
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(firstErr).Should(Receive(BeNil()))
+				Eventually(secondErr).Should(Receive(BeNil()))
+
+				_, firstResource, _ := fakeLockDB.LockArgsForCall(1)
+				Expect(firstResource.Owner).To(Equal("first"))
+				_, secondResource, _ := fakeLockDB.LockArgsForCall(2)
+				Expect(secondResource.Owner).To(Equal("second"))
+			})
+
+			It("moves on to the next waiter when the head gives up", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				first := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "first", Type: "lock"}, TtlInSeconds: 10, Fair: true}
+				second := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "second", Type: "lock"}, TtlInSeconds: 10, Fair: true}
+
+				firstErr := acquire(ctx, first)
+				Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+				secondErr := acquire(context.Background(), second)
+				Consistently(fakeLockDB.LockCallCount).Should(Equal(1))
+
+				atomic.StoreInt32(&available, 1)
+				cancel()
+
+				Eventually(firstErr).Should(Receive(Equal(models.ErrLockCollision)))
+				Eventually(secondErr).Should(Receive(BeNil()))
+			})
+		})
+
+		Context("when the requests have priorities", func() {
+			var low, high *models.LockRequest
+
+			BeforeEach(func() {
+				low = &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "low", Type: "lock"}, TtlInSeconds: 10, Priority: 1}
+				high = &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "high", Type: "lock"}, TtlInSeconds: 10, Priority: 5}
+			})
+
+			It("grants the lock to the highest priority waiter first", func() {
+				waiting := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "waiting", Type: "lock"}, TtlInSeconds: 10, Fair: true}
+				waitingErr := acquire(context.Background(), waiting)
+				Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+				lowErr := acquire(context.Background(), low)
+				Eventually(fakeLockDB.LockCallCount).Should(Equal(2))
+				highErr := acquire(context.Background(), high)
+				Eventually(fakeLockDB.LockCallCount).Should(Equal(3))
+
+				atomic.StoreInt32(&available, 1)
+				_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource})
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(highErr).Should(Receive(BeNil()))
+				Eventually(lowErr).Should(Receive(BeNil()))
+				Eventually(waitingErr).Should(Receive(BeNil()))
+
+				_, resource, _ := fakeLockDB.LockArgsForCall(3)
+				Expect(resource.Owner).To(Equal("high"))
+				_, resource, _ = fakeLockDB.LockArgsForCall(4)
+				Expect(resource.Owner).To(Equal("low"))
+			})
+
+			Context("and a higher priority request preempts", func() {
+				BeforeEach(func() {
+					high.Preempt = true
+				})
+
+				It("aborts the lower priority waiters", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					lowErr := acquire(ctx, low)
+					Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+					highErr := acquire(ctx, high)
+					Eventually(lowErr).Should(Receive(Equal(models.ErrLockWaitPreempted)))
+					Consistently(highErr).ShouldNot(Receive())
+				})
+
+				It("does not preempt waiters with the same priority", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					low.Priority = 5
+					lowErr := acquire(ctx, low)
+					Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+					acquire(ctx, high)
+					Consistently(lowErr).ShouldNot(Receive())
+				})
+			})
+		})
+	})
+
+	Context("ArriveAtBarrier", func() {
+		var request *models.BarrierRequest
+
+		arrival := func(participant string) *db.Lock {
+			return &db.Lock{Resource: &models.Resource{Key: "upgrade/" + participant, Owner: participant, Value: "upgrade", Type: models.BarrierType}}
+		}
+
+		BeforeEach(func() {
+			request = &models.BarrierRequest{Key: "upgrade", Participant: "cell-1", Participants: 2, TtlInMilliseconds: 60000}
+			fakeLockDB.LockStub = func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+				return &db.Lock{Resource: resource}, nil
+			}
+			fakeLockDB.FetchReturns(nil, models.ErrResourceNotFound)
+		})
+
+		It("validates the request", func() {
+			request.Participants = 0
+			_, err := locketHandler.ArriveAtBarrier(context.Background(), request)
+			Expect(err).To(Equal(models.ErrInvalidBarrier))
+
+			request.Participants = 2
+			request.TtlInMilliseconds = 0
+			_, err = locketHandler.ArriveAtBarrier(context.Background(), request)
+			Expect(err).To(Equal(models.ErrInvalidTTL))
+		})
+
+		It("records the arrival and trips the barrier once every participant has arrived", func() {
+			fakeLockDB.FetchAllReturns([]*db.Lock{arrival("cell-1"), arrival("cell-2"), {Resource: &models.Resource{Key: "other/cell-3", Value: "other"}}}, nil)
+
+			response, err := locketHandler.ArriveAtBarrier(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Arrived).To(BeEquivalentTo(2))
+
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+			_, resource, ttl := fakeLockDB.LockArgsForCall(0)
+			Expect(resource).To(Equal(arrival("cell-1").Resource))
+			Expect(ttl).To(Equal(time.Minute))
+
+			_, resource, _ = fakeLockDB.LockArgsForCall(1)
+			Expect(resource.Key).To(Equal("upgrade"))
+			Expect(resource.Type).To(Equal(models.BarrierType))
+
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(1))
+			_, lockType := fakeLockDB.FetchAllArgsForCall(0)
+			Expect(lockType).To(Equal(models.BarrierType))
 		})
 
-		Context("validate lock type", func() {
-			Context("when type string is set", func() {
-				It("should be invalid with type not set to presence/lock", func() {
-					request.Resource.Type = "random"
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).To(HaveOccurred())
-				})
+		It("returns straight away when the barrier has already tripped", func() {
+			fakeLockDB.FetchReturns(&db.Lock{Resource: &models.Resource{Key: "upgrade", Type: models.BarrierType}}, nil)
 
-				It("should be valid with type set to presence", func() {
-					request.Resource.Type = "presence"
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).NotTo(HaveOccurred())
-				})
+			response, err := locketHandler.ArriveAtBarrier(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Arrived).To(BeEquivalentTo(2))
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(0))
+		})
 
-				It("should be valid with type set to lock", func() {
-					request.Resource.Type = "lock"
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).NotTo(HaveOccurred())
-				})
-			})
+		It("waits until the other participants arrive", func() {
+			fakeLockDB.FetchAllReturns([]*db.Lock{arrival("cell-1")}, nil)
 
-			Context("when type_code is set", func() {
-				It("should ba invalid when mismatching non-empty type", func() {
-					request.Resource.Type = "lock"
-					request.Resource.TypeCode = models.PRESENCE
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).To(HaveOccurred())
+			errCh := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := locketHandler.ArriveAtBarrier(context.Background(), request)
+				errCh <- err
+			}()
 
-					request.Resource.Type = "presence"
-					request.Resource.TypeCode = models.LOCK
-					_, err = locketHandler.Lock(context.Background(), request)
-					Expect(err).To(HaveOccurred())
-				})
+			Eventually(fakeLockDB.FetchAllCallCount).Should(Equal(1))
+			Consistently(errCh).ShouldNot(Receive())
 
-				It("should be valid when type and type code match", func() {
-					request.Resource.Type = "lock"
-					request.Resource.TypeCode = models.LOCK
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).NotTo(HaveOccurred())
+			fakeLockDB.FetchAllReturns([]*db.Lock{arrival("cell-1"), arrival("cell-2")}, nil)
+			fakeClock.WaitForWatcherAndIncrement(500 * time.Millisecond)
 
-					request.Resource.Type = "presence"
-					request.Resource.TypeCode = models.PRESENCE
-					_, err = locketHandler.Lock(context.Background(), request)
-					Expect(err).NotTo(HaveOccurred())
-				})
+			Eventually(errCh).Should(Receive(BeNil()))
+		})
 
-				It("should be valid on a valid type code and empty type", func() {
-					request.Resource.Type = ""
-					request.Resource.TypeCode = models.LOCK
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).NotTo(HaveOccurred())
-				})
+		It("gives up and withdraws the arrival when the context is done", func() {
+			fakeLockDB.FetchAllReturns([]*db.Lock{arrival("cell-1")}, nil)
 
-				It("should be invalid on an UNKNOWN type code and empty type", func() {
-					request.Resource.Type = ""
-					request.Resource.TypeCode = models.UNKNOWN
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).To(HaveOccurred())
-				})
+			ctx, cancel := context.WithCancel(context.Background())
+			errCh := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				_, err := locketHandler.ArriveAtBarrier(ctx, request)
+				errCh <- err
+			}()
 
-				It("should be invalid on an non-existent type code", func() {
-					request.Resource.Type = ""
-					request.Resource.TypeCode = 4
-					_, err := locketHandler.Lock(context.Background(), request)
-					Expect(err).To(HaveOccurred())
-				})
-			})
+			Eventually(fakeLockDB.FetchAllCallCount).Should(Equal(1))
+			cancel()
+
+			Eventually(errCh).Should(Receive(Equal(models.ErrBarrierTimeout)))
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+			_, resource := fakeLockDB.ReleaseArgsForCall(0)
+			Expect(resource).To(Equal(arrival("cell-1").Resource))
 		})
+	})
 
-		Context("when request does not have TTL", func() {
-			BeforeEach(func() {
-				request = &models.LockRequest{
-					Resource: resource,
+	Context("KeepAlive", func() {
+		var (
+			stream *keepAliveStream
+			errCh  chan error
+		)
+
+		lockRequest := func(key string) *models.LockRequest {
+			return &models.LockRequest{Resource: &models.Resource{Key: key, Owner: "myself", Type: "lock"}, TtlInSeconds: 10}
+		}
+
+		BeforeEach(func() {
+			stream = newKeepAliveStream()
+			errCh = make(chan error, 1)
+
+			fakeLockDB.LockStub = func(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+				if resource.Key == "lost" {
+					return &db.Lock{Resource: &models.Resource{Key: resource.Key, Owner: "someone-else"}}, models.ErrLockCollision
 				}
-			})
+				return &db.Lock{Resource: resource}, nil
+			}
 
-			It("returns a validation error", func() {
-				_, err := locketHandler.Lock(context.Background(), request)
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(models.ErrInvalidTTL))
-				Expect(logger).To(gbytes.Say(models.ErrInvalidTTL.Error()))
-				Expect(logger).To(gbytes.Say("\"key\":"))
-				Expect(logger).To(gbytes.Say("\"owner\":"))
-			})
+			go func() {
+				defer GinkgoRecover()
+				errCh <- locketHandler.KeepAlive(stream)
+			}()
 		})
 
-		Context("when the request does not have an owner", func() {
-			BeforeEach(func() {
-				resource.Owner = ""
-			})
+		AfterEach(func() {
+			close(stream.requests)
+			Eventually(errCh).Should(Receive(BeNil()))
+		})
 
-			It("returns a validation error", func() {
-				_, err := locketHandler.Lock(context.Background(), request)
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(models.ErrInvalidOwner))
-				Expect(logger).To(gbytes.Say(models.ErrInvalidOwner.Error()))
-				Expect(logger).To(gbytes.Say("\"key\":"))
-				Expect(logger).To(gbytes.Say("\"owner\":"))
-			})
+		It("renews every lock on the stream each time the client sends a message", func() {
+			stream.requests <- &models.KeepAliveRequest{Locks: []*models.LockRequest{lockRequest("a"), lockRequest("b")}}
+			Eventually(stream.responses).Should(Receive(Equal(&models.KeepAliveResponse{})))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(2))
+
+			stream.requests <- &models.KeepAliveRequest{}
+			Eventually(stream.responses).Should(Receive(Equal(&models.KeepAliveResponse{})))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(4))
+
+			_, resource, ttl := fakeLockDB.LockArgsForCall(3)
+			Expect(resource.Key).To(Equal("b"))
+			Expect(ttl).To(Equal(10 * time.Second))
 		})
 
-		Context("when locking errors", func() {
-			var (
-				err error
-			)
+		It("revokes locks that cannot be renewed and stops renewing them", func() {
+			stream.requests <- &models.KeepAliveRequest{Locks: []*models.LockRequest{lockRequest("a"), lockRequest("lost")}}
+			Eventually(stream.responses).Should(Receive(Equal(&models.KeepAliveResponse{
+				Revocations: []*models.Revocation{{Key: "lost", Owner: "myself", Reason: "lock-collision"}},
+			})))
 
-			BeforeEach(func() {
-				fakeLockDB.LockReturns(nil, errors.New("Boom."))
-			})
+			stream.requests <- &models.KeepAliveRequest{}
+			Eventually(stream.responses).Should(Receive(Equal(&models.KeepAliveResponse{})))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(3))
+		})
 
-			JustBeforeEach(func() {
-				_, err = locketHandler.Lock(context.Background(), request)
+		It("releases locks the client removes from the stream", func() {
+			stream.requests <- &models.KeepAliveRequest{Locks: []*models.LockRequest{lockRequest("a"), lockRequest("b")}}
+			Eventually(stream.responses).Should(Receive())
+
+			stream.requests <- &models.KeepAliveRequest{Releases: []string{"a", "unknown"}}
+			Eventually(stream.responses).Should(Receive())
+
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+			_, resource := fakeLockDB.ReleaseArgsForCall(0)
+			Expect(resource.Key).To(Equal("a"))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(3))
+		})
+	})
+
+	Context("Release", func() {
+		It("releases the lock in the database", func() {
+			_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLockDB.ReleaseCallCount()).Should(Equal(1))
+			_, actualResource := fakeLockDB.ReleaseArgsForCall(0)
+			Expect(actualResource).To(Equal(resource))
+		})
+
+		It("records the release with the contention tracker", func() {
+			_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeTracker.RecordReleasedCallCount()).To(Equal(1))
+			key, owner := fakeTracker.RecordReleasedArgsForCall(0)
+			Expect(key).To(Equal(resource.Key))
+			Expect(owner).To(Equal(resource.Owner))
+		})
+
+		Context("when the request is in shared mode", func() {
+			It("releases the owner's shared hold", func() {
+				_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource, Mode: models.SHARED})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
+				Expect(fakeLockDB.ReleaseSharedCallCount()).To(Equal(1))
+				_, actualResource := fakeLockDB.ReleaseSharedArgsForCall(0)
+				Expect(actualResource).To(Equal(resource))
+				Expect(fakeTracker.RecordReleasedCallCount()).To(Equal(0))
 			})
+		})
 
-			It("returns the error", func() {
-				Expect(err).To(HaveOccurred())
+		Context("when the request has a condition", func() {
+			var request *models.ReleaseRequest
+
+			BeforeEach(func() {
+				request = &models.ReleaseRequest{Resource: resource, ExpectedValue: "test-value", ExpectedFencingToken: 7}
 			})
 
-			It("logs the error with identifying information", func() {
-				Expect(logger).To(gbytes.Say("Boom."))
-				Expect(logger).To(gbytes.Say("\"key\":"))
-				Expect(logger).To(gbytes.Say("\"owner\":"))
+			It("releases the lock only if it matches the condition", func() {
+				_, err := locketHandler.Release(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
+				Expect(fakeLockDB.ReleaseIfCallCount()).To(Equal(1))
+				_, actualResource, condition := fakeLockDB.ReleaseIfArgsForCall(0)
+				Expect(actualResource).To(Equal(resource))
+				Expect(condition).To(Equal(db.ReleaseCondition{Value: "test-value", FencingToken: 7}))
+				Expect(fakeTracker.RecordReleasedCallCount()).To(Equal(1))
 			})
 
-			Context("when lock collision error occurs", func() {
+			Context("and the lock does not match", func() {
 				BeforeEach(func() {
-					fakeLockDB.LockReturns(nil, models.ErrLockCollision)
+					fakeLockDB.ReleaseIfReturns(models.ErrReleaseConditionFailed)
 				})
 
-				It("does not log the error", func() {
-					Expect(logger).NotTo(gbytes.Say("lock-collision"))
+				It("returns the error without recording a release", func() {
+					_, err := locketHandler.Release(context.Background(), request)
+					Expect(err).To(Equal(models.ErrReleaseConditionFailed))
+					Expect(fakeTracker.RecordReleasedCallCount()).To(Equal(0))
 				})
 			})
-		})
 
-		Context("when an unrecoverable error is returned", func() {
-			BeforeEach(func() {
-				fakeLockDB.LockReturns(nil, helpers.ErrUnrecoverableError)
+			Context("and the request is in shared mode", func() {
+				It("returns a validation error", func() {
+					request.Mode = models.SHARED
+					_, err := locketHandler.Release(context.Background(), request)
+					Expect(err).To(Equal(models.ErrInvalidLockMode))
+					Expect(fakeLockDB.ReleaseSharedCallCount()).To(Equal(0))
+				})
 			})
+		})
 
-			It("logs and writes to the exit channel", func() {
-				locketHandler.Lock(context.Background(), request)
-				Expect(logger).To(gbytes.Say("unrecoverable-error"))
-				Expect(exitCh).To(Receive())
+		Context("when the request has an unknown mode", func() {
+			It("returns a validation error", func() {
+				_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource, Mode: models.LockMode(7)})
+				Expect(err).To(Equal(models.ErrInvalidLockMode))
+				Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
 			})
 		})
-	})
-
-	Context("Release", func() {
-		It("releases the lock in the database", func() {
-			_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource})
-			Expect(err).NotTo(HaveOccurred())
 
-			Expect(fakeLockDB.ReleaseCallCount()).Should(Equal(1))
-			_, actualResource := fakeLockDB.ReleaseArgsForCall(0)
-			Expect(actualResource).To(Equal(resource))
+		Context("when the request has no resource", func() {
+			It("returns a validation error", func() {
+				_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{})
+				Expect(err).To(Equal(models.ErrInvalidKey))
+				Expect(fakeLockDB.ReleaseCallCount()).To(Equal(0))
+			})
 		})
 
 		Context("when releasing errors", func() {
@@ -248,6 +1501,7 @@ var _ = Describe("Lock", func() {
 			It("returns the error", func() {
 				_, err := locketHandler.Release(context.Background(), &models.ReleaseRequest{Resource: resource})
 				Expect(err).To(HaveOccurred())
+				Expect(fakeTracker.RecordReleasedCallCount()).To(Equal(0))
 			})
 		})
 
@@ -279,6 +1533,33 @@ var _ = Describe("Lock", func() {
 			Expect(key).To(Equal("test-fetch"))
 		})
 
+		Context("when a type code is given", func() {
+			It("returns the lock when the type matches", func() {
+				fetchResp, err := locketHandler.Fetch(context.Background(), &models.FetchRequest{Key: "test-fetch", TypeCode: models.LOCK})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fetchResp.Resource).To(Equal(resource))
+			})
+
+			It("returns not found when the type does not match", func() {
+				_, err := locketHandler.Fetch(context.Background(), &models.FetchRequest{Key: "test-fetch", TypeCode: models.PRESENCE})
+				Expect(err).To(Equal(models.ErrResourceNotFound))
+			})
+
+			It("rejects unknown type codes", func() {
+				_, err := locketHandler.Fetch(context.Background(), &models.FetchRequest{Key: "test-fetch", TypeCode: 42})
+				Expect(err).To(Equal(models.ErrInvalidType))
+				Expect(fakeLockDB.FetchCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the key does not fit in the lock tables", func() {
+			It("returns a validation error without querying the database", func() {
+				_, err := locketHandler.Fetch(context.Background(), &models.FetchRequest{Key: strings.Repeat("k", 1<<20)})
+				Expect(err).To(Equal(models.ErrInvalidKey))
+				Expect(fakeLockDB.FetchCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when fetching errors", func() {
 			BeforeEach(func() {
 				fakeLockDB.FetchReturns(nil, errors.New("boom"))
@@ -404,6 +1685,137 @@ var _ = Describe("Lock", func() {
 				_, lockType := fakeLockDB.FetchAllArgsForCall(0)
 				Expect(lockType).To(Equal("lock"))
 			})
+
+			It("returns how long each lock has left", func() {
+				now := fakeClock.Now().UnixNano()
+				fakeLockDB.FetchAllReturns([]*db.Lock{
+					{Resource: expectedResources[0], ExpiresAt: now + int64(5*time.Second)},
+					{Resource: expectedResources[1], ExpiresAt: now - int64(time.Second), ReservedUntil: now + int64(2*time.Second)},
+				}, nil)
+
+				fetchResp, err := locketHandler.FetchAll(context.Background(), &models.FetchAllRequest{TypeCode: models.LOCK})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fetchResp.Holders).To(Equal([]*models.LockHolder{
+					{Key: expectedResources[0].Key, Owner: expectedResources[0].Owner, RemainingTtlInMilliseconds: 5000, ExpiresAt: now + int64(5*time.Second)},
+					{Key: "cell", Owner: "cell-1", RemainingTtlInMilliseconds: 2000, ExpiresAt: now + int64(2*time.Second)},
+				}))
+			})
+
+			It("returns how long each owner has held its lock", func() {
+				now := fakeClock.Now().UnixNano()
+				fakeLockDB.FetchAllReturns([]*db.Lock{
+					{Resource: expectedResources[0], ExpiresAt: now + int64(5*time.Second), AcquiredAt: now - int64(90*time.Second)},
+					{Resource: expectedResources[1], ExpiresAt: now + int64(5*time.Second)},
+				}, nil)
+
+				fetchResp, err := locketHandler.FetchAll(context.Background(), &models.FetchAllRequest{TypeCode: models.LOCK})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fetchResp.Holders).To(HaveLen(2))
+				Expect(fetchResp.Holders[0].HeldForInMilliseconds).To(BeEquivalentTo(90000))
+				Expect(fetchResp.Holders[1].HeldForInMilliseconds).To(BeZero())
+			})
+		})
+
+		Context("when requests for the same type arrive while a query is running", func() {
+			var (
+				started chan string
+				unblock chan struct{}
+			)
+
+			BeforeEach(func() {
+				started = make(chan string, 10)
+				unblock = make(chan struct{})
+				fakeLockDB.FetchAllStub = func(logger lager.Logger, lockType string) ([]*db.Lock, error) {
+					started <- lockType
+					<-unblock
+					return []*db.Lock{{Resource: resource}}, nil
+				}
+			})
+
+			fetchAll := func(req *models.FetchAllRequest) <-chan *models.FetchAllResponse {
+				responses := make(chan *models.FetchAllResponse, 1)
+				go func() {
+					defer GinkgoRecover()
+					resp, err := locketHandler.FetchAll(context.Background(), req)
+					Expect(err).NotTo(HaveOccurred())
+					responses <- resp
+				}()
+				return responses
+			}
+
+			It("runs one more query after it, shared by all of them", func() {
+				first := fetchAll(&models.FetchAllRequest{TypeCode: models.LOCK})
+				Eventually(started).Should(Receive(Equal("lock")))
+
+				second := fetchAll(&models.FetchAllRequest{TypeCode: models.LOCK})
+				third := fetchAll(&models.FetchAllRequest{Type: models.LockType})
+				Consistently(started).ShouldNot(Receive())
+
+				unblock <- struct{}{}
+				Eventually(first).Should(Receive())
+				Eventually(started).Should(Receive(Equal("lock")))
+
+				close(unblock)
+				var secondResp, thirdResp *models.FetchAllResponse
+				Eventually(second).Should(Receive(&secondResp))
+				Eventually(third).Should(Receive(&thirdResp))
+				Expect(secondResp).To(BeIdenticalTo(thirdResp))
+				Expect(fakeLockDB.FetchAllCallCount()).To(Equal(2))
+			})
+
+			It("lets a request waiting for the next query give up when its context is done", func() {
+				first := fetchAll(&models.FetchAllRequest{TypeCode: models.LOCK})
+				Eventually(started).Should(Receive(Equal("lock")))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				errs := make(chan error, 1)
+				go func() {
+					_, err := locketHandler.FetchAll(ctx, &models.FetchAllRequest{TypeCode: models.LOCK})
+					errs <- err
+				}()
+				Consistently(errs).ShouldNot(Receive())
+
+				cancel()
+				Eventually(errs).Should(Receive(Equal(context.Canceled)))
+				Consistently(first).ShouldNot(Receive())
+
+				close(unblock)
+				Eventually(first).Should(Receive())
+			})
+
+			It("does not cancel a shared query when the request that made it gives up", func() {
+				queryErrs := make(chan error, 10)
+				fakeLockDB.FetchAllStub = func(logger lager.Logger, lockType string) ([]*db.Lock, error) {
+					started <- lockType
+					<-unblock
+					queryErrs <- tracing.LoggerContext(logger).Err()
+					return []*db.Lock{{Resource: resource}}, nil
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				errs := make(chan error, 1)
+				go func() {
+					_, err := locketHandler.FetchAll(ctx, &models.FetchAllRequest{TypeCode: models.LOCK})
+					errs <- err
+				}()
+				Eventually(started).Should(Receive(Equal("lock")))
+
+				cancel()
+				Eventually(errs).Should(Receive(Equal(context.Canceled)))
+
+				close(unblock)
+				Eventually(queryErrs).Should(Receive(BeNil()))
+				Eventually(fetchAll(&models.FetchAllRequest{TypeCode: models.LOCK})).Should(Receive())
+			})
+
+			It("does not share queries between types", func() {
+				fetchAll(&models.FetchAllRequest{TypeCode: models.LOCK})
+				fetchAll(&models.FetchAllRequest{TypeCode: models.PRESENCE})
+
+				Eventually(started).Should(Receive())
+				Eventually(started).Should(Receive())
+				close(unblock)
+			})
 		})
 
 		Context("when the type is invalid", func() {
@@ -443,4 +1855,109 @@ var _ = Describe("Lock", func() {
 			})
 		})
 	})
+
+	Context("Waiters", func() {
+		It("lists the queued Acquire calls in the order they will be granted", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeLockDB.LockReturns(&db.Lock{Resource: &models.Resource{Key: "test", Owner: "someone-else"}}, models.ErrLockCollision)
+
+			low := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "low", Type: "lock"}, TtlInSeconds: 10, Priority: 1}
+			go locketHandler.Acquire(ctx, low)
+			Eventually(fakeLockDB.LockCallCount).Should(Equal(1))
+
+			fakeClock.Increment(2 * time.Second)
+
+			high := &models.LockRequest{Resource: &models.Resource{Key: "test", Owner: "high", Type: "lock"}, TtlInSeconds: 10, Priority: 5}
+			go locketHandler.Acquire(ctx, high)
+			Eventually(func() int {
+				resp, err := locketHandler.Waiters(context.Background(), &models.WaitersRequest{Key: "test"})
+				Expect(err).NotTo(HaveOccurred())
+				return len(resp.Waiters)
+			}).Should(Equal(2))
+
+			fakeClock.Increment(time.Second)
+
+			resp, err := locketHandler.Waiters(context.Background(), &models.WaitersRequest{Key: "test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Waiters).To(Equal([]*models.Waiter{
+				{Owner: "high", Priority: 5, WaitingInMilliseconds: 1000, Queued: true},
+				{Owner: "low", Priority: 1, WaitingInMilliseconds: 3000, Queued: true},
+			}))
+		})
+
+		It("includes owners that are retrying the lock without queueing", func() {
+			fakeTracker.WaitQueuesReturns(map[string][]contention.Waiter{
+				"test":  {{Owner: "poller", WaitingSince: fakeClock.Now().Add(-5 * time.Second)}},
+				"other": {{Owner: "elsewhere", WaitingSince: fakeClock.Now()}},
+			})
+
+			resp, err := locketHandler.Waiters(context.Background(), &models.WaitersRequest{Key: "test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Waiters).To(Equal([]*models.Waiter{
+				{Owner: "poller", WaitingInMilliseconds: 5000},
+			}))
+		})
+
+		It("returns no waiters for a key nobody is waiting on", func() {
+			resp, err := locketHandler.Waiters(context.Background(), &models.WaitersRequest{Key: "test"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Waiters).To(BeEmpty())
+		})
+	})
+
+	Context("Stats", func() {
+		var contended []*models.ContendedKey
+
+		BeforeEach(func() {
+			contended = []*models.ContendedKey{{Key: "test", FailedAttempts: 4, Waiters: 2}}
+			fakeTracker.TopReturns(contended)
+		})
+
+		It("returns the most contended keys", func() {
+			resp, err := locketHandler.Stats(context.Background(), &models.StatsRequest{TopN: 3})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.ContendedKeys).To(Equal(contended))
+			Expect(fakeTracker.TopArgsForCall(0)).To(Equal(3))
+		})
+
+		Context("when top n is not set", func() {
+			It("returns the default number of keys", func() {
+				_, err := locketHandler.Stats(context.Background(), &models.StatsRequest{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeTracker.TopArgsForCall(0)).To(Equal(10))
+			})
+		})
+	})
 })
+
+type keepAliveStream struct {
+	models.Locket_KeepAliveServer
+	requests  chan *models.KeepAliveRequest
+	responses chan *models.KeepAliveResponse
+}
+
+func newKeepAliveStream() *keepAliveStream {
+	return &keepAliveStream{
+		requests:  make(chan *models.KeepAliveRequest),
+		responses: make(chan *models.KeepAliveResponse, 1),
+	}
+}
+
+func (s *keepAliveStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *keepAliveStream) Recv() (*models.KeepAliveRequest, error) {
+	req, ok := <-s.requests
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (s *keepAliveStream) Send(resp *models.KeepAliveResponse) error {
+	s.responses <- resp
+	return nil
+}
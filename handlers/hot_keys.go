@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/locket/db"
+	"golang.org/x/net/context"
+)
+
+// maxIdleHotKeys is how many keys may keep a holder that is no longer
+// trusted before they are swept.
+const maxIdleHotKeys = 10000
+
+// hotKeys cuts the database load of keys that many owners contend on. It
+// remembers the holder of each key that saw a collision for up to window,
+// or until the lock expires if that is sooner, so that the other owners
+// are turned away without a transaction. Exclusive locks on a key are
+// taken one at a time, so that contenders that arrive together wait for
+// the first to find out who holds the key instead of all asking the
+// database. Locks released on this server are forgotten at once, but one
+// released on another server is only noticed once window has passed.
+type hotKeys struct {
+	window time.Duration
+
+	mutex *sync.Mutex
+	keys  map[string]*hotKey
+}
+
+type hotKey struct {
+	// holder is the last holder seen, or nil for a key that has not seen a
+	// collision
+	holder *db.Lock
+	seenAt time.Time
+	// busy is closed when the lock call in flight on the key returns
+	busy chan struct{}
+}
+
+func newHotKeys(window time.Duration) *hotKeys {
+	return &hotKeys{
+		window: window,
+		mutex:  &sync.Mutex{},
+		keys:   make(map[string]*hotKey),
+	}
+}
+
+// holder returns the holder of key if it is another owner than owner and
+// can still be trusted at now.
+func (k *hotKeys) holder(key, owner string, now time.Time) *db.Lock {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	entry, ok := k.keys[key]
+	if !ok || !k.trusted(entry, now) || entry.holder.Owner == owner {
+		return nil
+	}
+	return entry.holder
+}
+
+// begin waits for the lock call in flight on key to return, and returns the
+// function that ends the caller's own. It gives up when ctx is done.
+func (k *hotKeys) begin(ctx context.Context, key string) (func(), error) {
+	for {
+		k.mutex.Lock()
+		entry, ok := k.keys[key]
+		if !ok {
+			entry = &hotKey{}
+			k.keys[key] = entry
+		}
+		if entry.busy == nil {
+			busy := make(chan struct{})
+			entry.busy = busy
+			k.mutex.Unlock()
+			return func() { k.end(key, busy) }, nil
+		}
+		busy := entry.busy
+		k.mutex.Unlock()
+
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (k *hotKeys) end(key string, busy chan struct{}) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	close(busy)
+	entry := k.keys[key]
+	entry.busy = nil
+	if entry.holder == nil {
+		delete(k.keys, key)
+	}
+}
+
+// collided records that holder was found holding its key at now.
+func (k *hotKeys) collided(holder *db.Lock, now time.Time) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	entry, ok := k.keys[holder.Key]
+	if !ok {
+		entry = &hotKey{}
+		k.keys[holder.Key] = entry
+	}
+	entry.holder = holder
+	entry.seenAt = now
+
+	if len(k.keys) > maxIdleHotKeys {
+		k.sweep(now)
+	}
+}
+
+// locked records that lock was granted at now. Only keys that have seen a
+// collision keep track of their holder.
+func (k *hotKeys) locked(lock *db.Lock, now time.Time) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	entry, ok := k.keys[lock.Key]
+	if ok && entry.holder != nil {
+		entry.holder = lock
+		entry.seenAt = now
+	}
+}
+
+// released forgets the holder of key.
+func (k *hotKeys) released(key string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	entry, ok := k.keys[key]
+	if !ok {
+		return
+	}
+	entry.holder = nil
+	if entry.busy == nil {
+		delete(k.keys, key)
+	}
+}
+
+func (k *hotKeys) trusted(entry *hotKey, now time.Time) bool {
+	if entry.holder == nil || now.Sub(entry.seenAt) >= k.window {
+		return false
+	}
+	return entry.holder.ExpiresAt == 0 || now.UnixNano() < entry.holder.ExpiresAt
+}
+
+func (k *hotKeys) sweep(now time.Time) {
+	for key, entry := range k.keys {
+		if entry.busy == nil && !k.trusted(entry, now) {
+			delete(k.keys, key)
+		}
+	}
+}
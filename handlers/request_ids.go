@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+)
+
+// requestIDs remembers the responses to recently granted lock requests that
+// carried a client request ID, so that a client retrying after an ambiguous
+// failure gets the original grant back instead of a second acquisition.
+type requestIDs struct {
+	mutex     *sync.Mutex
+	responses map[requestID]requestIDEntry
+}
+
+type requestID struct {
+	id    string
+	key   string
+	owner string
+}
+
+type requestIDEntry struct {
+	response  *models.LockResponse
+	expiresAt time.Time
+}
+
+func newRequestIDs() *requestIDs {
+	return &requestIDs{
+		mutex:     &sync.Mutex{},
+		responses: make(map[requestID]requestIDEntry),
+	}
+}
+
+func requestIDFor(req *models.LockRequest) requestID {
+	return requestID{
+		id:    req.RequestId,
+		key:   req.Resource.GetKey(),
+		owner: req.Resource.GetOwner(),
+	}
+}
+
+// get returns the response remembered for req, or nil if there is none or
+// it has expired.
+func (r *requestIDs) get(req *models.LockRequest, now time.Time) *models.LockResponse {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry, ok := r.responses[requestIDFor(req)]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil
+	}
+	return entry.response
+}
+
+// put remembers response for req until the window has passed or the lock it
+// granted expires, whichever is sooner. Expired entries are dropped as new
+// ones are added.
+func (r *requestIDs) put(req *models.LockRequest, response *models.LockResponse, now time.Time, window time.Duration) {
+	expiresAt := now.Add(window)
+	if response.ExpiresAt > 0 && response.ExpiresAt < expiresAt.UnixNano() {
+		expiresAt = time.Unix(0, response.ExpiresAt)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, entry := range r.responses {
+		if !now.Before(entry.expiresAt) {
+			delete(r.responses, id)
+		}
+	}
+
+	r.responses[requestIDFor(req)] = requestIDEntry{response: response, expiresAt: expiresAt}
+}
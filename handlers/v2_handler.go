@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/v2"
+	"golang.org/x/net/context"
+)
+
+// v2Handler serves the locket.v2 api by translating requests onto the v1
+// handler, so that both versions share validation, storage and metrics.
+type v2Handler struct {
+	handler models.LocketServer
+}
+
+func NewV2Handler(handler models.LocketServer) v2.LocketServer {
+	return &v2Handler{handler: handler}
+}
+
+func (h *v2Handler) Lock(ctx context.Context, req *v2.LockRequest) (*v2.LockResponse, error) {
+	resp, err := h.handler.Lock(ctx, toV1LockRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV2LockResponse(resp), nil
+}
+
+func (h *v2Handler) Acquire(ctx context.Context, req *v2.LockRequest) (*v2.LockResponse, error) {
+	resp, err := h.handler.Acquire(ctx, toV1LockRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return toV2LockResponse(resp), nil
+}
+
+func (h *v2Handler) LockGroup(ctx context.Context, req *v2.LockGroupRequest) (*v2.LockGroupResponse, error) {
+	var resources []*models.Resource
+	for _, resource := range req.Resources {
+		resources = append(resources, toV1Resource(resource))
+	}
+
+	resp, err := h.handler.LockGroup(ctx, &models.LockGroupRequest{
+		Resources:         resources,
+		TtlInMilliseconds: req.TtlInMilliseconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var locks []*v2.LockResponse
+	for _, lock := range resp.Locks {
+		locks = append(locks, toV2LockResponse(lock))
+	}
+	return &v2.LockGroupResponse{Locks: locks}, nil
+}
+
+func (h *v2Handler) Release(ctx context.Context, req *v2.ReleaseRequest) (*v2.ReleaseResponse, error) {
+	_, err := h.handler.Release(ctx, &models.ReleaseRequest{
+		Resource: toV1Resource(req.Resource),
+		Mode:     models.LockMode(req.Mode),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v2.ReleaseResponse{}, nil
+}
+
+func (h *v2Handler) Fetch(ctx context.Context, req *v2.FetchRequest) (*v2.FetchResponse, error) {
+	resp, err := h.handler.Fetch(ctx, &models.FetchRequest{
+		Key:      req.Key,
+		TypeCode: models.TypeCode(req.TypeCode),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v2.FetchResponse{Resource: toV2Resource(resp.Resource)}, nil
+}
+
+func (h *v2Handler) FetchAll(ctx context.Context, req *v2.FetchAllRequest) (*v2.FetchAllResponse, error) {
+	resp, err := h.handler.FetchAll(ctx, &models.FetchAllRequest{TypeCode: models.TypeCode(req.TypeCode)})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*v2.Resource
+	for _, resource := range resp.Resources {
+		resources = append(resources, toV2Resource(resource))
+	}
+	return &v2.FetchAllResponse{Resources: resources}, nil
+}
+
+func toV1LockRequest(req *v2.LockRequest) *models.LockRequest {
+	return &models.LockRequest{
+		Resource:          toV1Resource(req.Resource),
+		TtlInMilliseconds: req.TtlInMilliseconds,
+		Mode:              models.LockMode(req.Mode),
+		Capacity:          req.Capacity,
+		Fair:              req.Fair,
+		Priority:          req.Priority,
+		Preempt:           req.Preempt,
+	}
+}
+
+func toV2LockResponse(resp *models.LockResponse) *v2.LockResponse {
+	return &v2.LockResponse{
+		Resource:     toV2Resource(resp.Resource),
+		FencingToken: resp.FencingToken,
+		ExpiresAt:    resp.ExpiresAt,
+	}
+}
+
+func toV1Resource(resource *v2.Resource) *models.Resource {
+	if resource == nil {
+		return nil
+	}
+	return models.GetResource(&models.Resource{
+		Key:      resource.Key,
+		Owner:    resource.Owner,
+		Value:    resource.Value,
+		TypeCode: models.TypeCode(resource.TypeCode),
+		Metadata: resource.Metadata,
+	})
+}
+
+func toV2Resource(resource *models.Resource) *v2.Resource {
+	if resource == nil {
+		return nil
+	}
+	return &v2.Resource{
+		Key:      resource.Key,
+		Owner:    resource.Owner,
+		Value:    resource.Value,
+		TypeCode: v2.TypeCode(models.GetResource(resource).TypeCode),
+		Metadata: resource.Metadata,
+	}
+}
@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/contention/contentionfakes"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/deadlock/deadlockfakes"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
+	"code.cloudfoundry.org/locket/handlers"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("V2Handler", func() {
+	var (
+		fakeLockDB *dbfakes.FakeLockDB
+		fakeClock  *fakeclock.FakeClock
+		v2Handler  v2.LocketServer
+		resource   *v2.Resource
+		stored     *db.Lock
+	)
+
+	BeforeEach(func() {
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger := lagertest.NewTestLogger("v2-handler")
+
+		handler := handlers.NewLocketHandler(
+			logger,
+			fakeLockDB,
+			&expirationfakes.FakeLockPick{},
+			&contentionfakes.FakeTracker{},
+			&deadlockfakes.FakeDetector{},
+			fakeClock,
+			make(chan struct{}, 1),
+		)
+		v2Handler = handlers.NewV2Handler(handler)
+
+		resource = &v2.Resource{Key: "test", Owner: "myself", Value: "test-value", TypeCode: v2.LOCK}
+		stored = &db.Lock{
+			Resource: &models.Resource{
+				Key:      "test",
+				Owner:    "myself",
+				Value:    "test-value",
+				Type:     models.LockType,
+				TypeCode: models.LOCK,
+			},
+			TtlInMilliseconds: 1500,
+			FencingToken:      3,
+			ExpiresAt:         fakeClock.Now().Add(1500 * time.Millisecond).UnixNano(),
+		}
+	})
+
+	Context("Lock", func() {
+		BeforeEach(func() {
+			fakeLockDB.LockReturns(stored, nil)
+		})
+
+		It("locks the resource through the v1 handler", func() {
+			resp, err := v2Handler.Lock(context.Background(), &v2.LockRequest{Resource: resource, TtlInMilliseconds: 1500})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(Equal(&v2.LockResponse{
+				Resource:     resource,
+				FencingToken: 3,
+				ExpiresAt:    stored.ExpiresAt,
+			}))
+
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+			_, actualResource, ttl := fakeLockDB.LockArgsForCall(0)
+			Expect(actualResource).To(Equal(stored.Resource))
+			Expect(ttl).To(Equal(1500 * time.Millisecond))
+		})
+
+		It("passes the lock mode through", func() {
+			fakeLockDB.LockSharedReturns(stored, nil)
+
+			_, err := v2Handler.Lock(context.Background(), &v2.LockRequest{Resource: resource, TtlInMilliseconds: 1500, Mode: v2.SHARED})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.LockSharedCallCount()).To(Equal(1))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(0))
+		})
+
+		It("returns v1 errors unchanged", func() {
+			_, err := v2Handler.Lock(context.Background(), &v2.LockRequest{Resource: resource})
+			Expect(err).To(Equal(models.ErrInvalidTTL))
+		})
+	})
+
+	Context("Acquire", func() {
+		BeforeEach(func() {
+			fakeLockDB.LockReturns(stored, nil)
+		})
+
+		It("acquires the resource through the v1 handler", func() {
+			resp, err := v2Handler.Acquire(context.Background(), &v2.LockRequest{Resource: resource, TtlInMilliseconds: 1500, Fair: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.FencingToken).To(BeEquivalentTo(3))
+			Expect(fakeLockDB.LockCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("Release", func() {
+		It("releases the resource through the v1 handler", func() {
+			_, err := v2Handler.Release(context.Background(), &v2.ReleaseRequest{Resource: resource})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLockDB.ReleaseCallCount()).To(Equal(1))
+			_, actualResource := fakeLockDB.ReleaseArgsForCall(0)
+			Expect(actualResource).To(Equal(stored.Resource))
+		})
+
+		It("passes the lock mode through", func() {
+			_, err := v2Handler.Release(context.Background(), &v2.ReleaseRequest{Resource: resource, Mode: v2.SHARED})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.ReleaseSharedCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("Fetch", func() {
+		BeforeEach(func() {
+			fakeLockDB.FetchReturns(stored, nil)
+		})
+
+		It("fetches the resource through the v1 handler", func() {
+			resp, err := v2Handler.Fetch(context.Background(), &v2.FetchRequest{Key: "test", TypeCode: v2.LOCK})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Resource).To(Equal(resource))
+		})
+
+		It("filters by type code", func() {
+			_, err := v2Handler.Fetch(context.Background(), &v2.FetchRequest{Key: "test", TypeCode: v2.PRESENCE})
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+	})
+
+	Context("FetchAll", func() {
+		BeforeEach(func() {
+			fakeLockDB.FetchAllReturns([]*db.Lock{stored}, nil)
+		})
+
+		It("fetches the resources of the requested type", func() {
+			resp, err := v2Handler.FetchAll(context.Background(), &v2.FetchAllRequest{TypeCode: v2.LOCK})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Resources).To(ConsistOf(resource))
+
+			_, lockType := fakeLockDB.FetchAllArgsForCall(0)
+			Expect(lockType).To(Equal(models.LockType))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// waitQueue orders the Acquire calls waiting on each key. Waiters are kept
+// by descending priority and then by arrival, and only the waiter at the
+// head of a key's queue attempts to take the lock.
+type waitQueue struct {
+	mutex   *sync.Mutex
+	waiters map[string][]*waiter
+}
+
+type waiter struct {
+	owner    string
+	identity string
+	priority int32
+	since    time.Time
+
+	wake      chan struct{}
+	preempted chan struct{}
+}
+
+func newWaitQueue() *waitQueue {
+	return &waitQueue{
+		mutex:   &sync.Mutex{},
+		waiters: make(map[string][]*waiter),
+	}
+}
+
+// enqueue adds a waiter for key behind every waiter with the same or a
+// higher priority. When preempt is set, waiters with a lower priority are
+// removed from the queue and told they were preempted.
+func (q *waitQueue) enqueue(key, owner, identity string, priority int32, preempt bool, since time.Time) *waiter {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	w := &waiter{
+		owner:     owner,
+		identity:  identity,
+		priority:  priority,
+		since:     since,
+		wake:      make(chan struct{}, 1),
+		preempted: make(chan struct{}),
+	}
+
+	waiters := q.waiters[key]
+	i := 0
+	for i < len(waiters) && waiters[i].priority >= priority {
+		i++
+	}
+
+	if preempt {
+		for _, lower := range waiters[i:] {
+			close(lower.preempted)
+		}
+		waiters = waiters[:i]
+	}
+
+	waiters = append(waiters, nil)
+	copy(waiters[i+1:], waiters[i:])
+	waiters[i] = w
+	q.waiters[key] = waiters
+	return w
+}
+
+func (q *waitQueue) isHead(key string, w *waiter) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	waiters := q.waiters[key]
+	return len(waiters) > 0 && waiters[0] == w
+}
+
+// remove takes w out of the queue and, if it was at the head, wakes the next
+// waiter so that it can try the lock straight away.
+func (q *waitQueue) remove(key string, w *waiter) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	waiters := q.waiters[key]
+	for i := range waiters {
+		if waiters[i] != w {
+			continue
+		}
+
+		waiters = append(waiters[:i], waiters[i+1:]...)
+		if len(waiters) == 0 {
+			delete(q.waiters, key)
+			return
+		}
+
+		q.waiters[key] = waiters
+		if i == 0 {
+			waiters[0].notify()
+		}
+		return
+	}
+}
+
+// list returns a copy of key's queue, head first.
+func (q *waitQueue) list(key string) []waiter {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	waiters := make([]waiter, 0, len(q.waiters[key]))
+	for _, w := range q.waiters[key] {
+		waiters = append(waiters, *w)
+	}
+	return waiters
+}
+
+// notify wakes the waiter at the head of key's queue, if there is one.
+func (q *waitQueue) notify(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	waiters := q.waiters[key]
+	if len(waiters) > 0 {
+		waiters[0].notify()
+	}
+}
+
+func (w *waiter) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
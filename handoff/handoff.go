@@ -0,0 +1,219 @@
+package handoff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/expiration"
+	"github.com/tedsuo/ifrit"
+)
+
+// protocolVersion is sent along with the listening socket, so that a
+// successor can refuse a handoff it does not understand.
+const protocolVersion = 1
+
+// timeout bounds each step of a handoff, so that a stuck peer cannot hold up
+// a restart.
+const timeout = 30 * time.Second
+
+var ErrNoPredecessor = errors.New("no locket process to take over from")
+
+type Config struct {
+	SocketPath string `json:"socket_path,omitempty"`
+}
+
+// state is the in-memory state handed to the successor.
+type state struct {
+	RegisteredTTLs []expiration.RegisteredTTL `json:"registered_ttls"`
+}
+
+type server struct {
+	logger     lager.Logger
+	socketPath string
+	listener   net.Listener
+	lockPick   expiration.LockPick
+}
+
+// NewServer returns a runner that waits on a unix socket at socketPath for
+// a new locket process started during an upgrade. It hands that process
+// listener, which must be a *net.TCPListener, and the TTLs registered with
+// lockPick, and exits once the successor has them, so that the rest of this
+// process shuts down while the successor accepts connections on the same
+// socket and keeps expiring locks on schedule.
+func NewServer(logger lager.Logger, socketPath string, listener net.Listener, lockPick expiration.LockPick) ifrit.Runner {
+	return &server{
+		logger:     logger.Session("handoff"),
+		socketPath: socketPath,
+		listener:   listener,
+		lockPick:   lockPick,
+	}
+}
+
+func (s *server) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	// the socket left behind by the process this one took over from
+	err := os.Remove(s.socketPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	unixListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: s.socketPath, Net: "unix"})
+	if err != nil {
+		return err
+	}
+	// once handed off, the path belongs to the successor's socket
+	unixListener.SetUnlinkOnClose(false)
+	defer unixListener.Close()
+
+	conns := make(chan *net.UnixConn)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			conn, err := unixListener.AcceptUnix()
+			if err != nil {
+				return
+			}
+			select {
+			case conns <- conn:
+			case <-done:
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	s.logger.Info("listening", lager.Data{"socket-path": s.socketPath})
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			s.logger.Info("signalled", lager.Data{"signal": sig})
+			os.Remove(s.socketPath)
+			return nil
+		case conn := <-conns:
+			err := s.handOff(conn)
+			conn.Close()
+			if err != nil {
+				s.logger.Error("failed-to-hand-off", err)
+				continue
+			}
+			s.logger.Info("handed-off")
+			return nil
+		}
+	}
+}
+
+func (s *server) handOff(conn *net.UnixConn) error {
+	filer, ok := s.listener.(interface{ File() (*os.File, error) })
+	if !ok {
+		return fmt.Errorf("cannot hand off a %T", s.listener)
+	}
+	file, err := filer.File()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return err
+	}
+
+	_, _, err = conn.WriteMsgUnix([]byte{protocolVersion}, syscall.UnixRights(int(file.Fd())), nil)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(conn).Encode(state{RegisteredTTLs: s.lockPick.RegisteredTTLs()})
+	if err != nil {
+		return err
+	}
+
+	// the successor acknowledges once it has the socket and the TTLs, before
+	// which this process has to keep serving in case it fails
+	ack := make([]byte, 1)
+	_, err = conn.Read(ack)
+	return err
+}
+
+// Receive takes over the listening socket and registered TTLs of the locket
+// process serving handoffs at socketPath. It returns ErrNoPredecessor when
+// there is none.
+func Receive(logger lager.Logger, socketPath string) (net.Listener, []expiration.RegisteredTTL, error) {
+	logger = logger.Session("receive-handoff", lager.Data{"socket-path": socketPath})
+
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, nil, ErrNoPredecessor
+		}
+		return nil, nil, err
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version := make([]byte, 1)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_, oobn, _, _, err := conn.ReadMsgUnix(version, oob)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version[0] != protocolVersion {
+		return nil, nil, fmt.Errorf("unsupported handoff protocol version: %d", version[0])
+	}
+
+	listener, err := listenerFromRights(oob[:oobn])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var received state
+	err = json.NewDecoder(conn).Decode(&received)
+	if err != nil {
+		listener.Close()
+		return nil, nil, err
+	}
+
+	_, err = conn.Write([]byte{protocolVersion})
+	if err != nil {
+		listener.Close()
+		return nil, nil, err
+	}
+
+	logger.Info("received", lager.Data{"address": listener.Addr().String(), "registered-ttls": len(received.RegisteredTTLs)})
+	return listener, received.RegisteredTTLs, nil
+}
+
+func listenerFromRights(oob []byte) (net.Listener, error) {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) != 1 {
+		return nil, errors.New("no listening socket in handoff")
+	}
+
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(fds) != 1 {
+		return nil, errors.New("no listening socket in handoff")
+	}
+
+	file := os.NewFile(uintptr(fds[0]), "handoff-listener")
+	defer file.Close()
+
+	return net.FileListener(file)
+}
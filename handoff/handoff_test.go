@@ -0,0 +1,108 @@
+package handoff_test
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
+	"code.cloudfoundry.org/locket/handoff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Handoff", func() {
+	var (
+		logger       *lagertest.TestLogger
+		tmpDir       string
+		socketPath   string
+		listener     net.Listener
+		fakeLockPick *expirationfakes.FakeLockPick
+		ttls         []expiration.RegisteredTTL
+		process      ifrit.Process
+	)
+
+	BeforeEach(func() {
+		var err error
+		logger = lagertest.NewTestLogger("handoff")
+		tmpDir, err = ioutil.TempDir("", "handoff")
+		Expect(err).NotTo(HaveOccurred())
+		socketPath = filepath.Join(tmpDir, "locket.sock")
+
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		ttls = []expiration.RegisteredTTL{{
+			Key:           "cell-1",
+			Owner:         "rep-1",
+			Type:          "presence",
+			ModifiedId:    "guid",
+			ModifiedIndex: 3,
+			TtlInSeconds:  15,
+			ExpiresAt:     time.Unix(1700000000, 0).UTC(),
+		}}
+		fakeLockPick = &expirationfakes.FakeLockPick{}
+		fakeLockPick.RegisteredTTLsReturns(ttls)
+
+		process = ginkgomon.Invoke(handoff.NewServer(logger, socketPath, listener, fakeLockPick))
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+		listener.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("hands the listening socket and registered ttls to the successor", func() {
+		inherited, received, err := handoff.Receive(logger, socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		defer inherited.Close()
+
+		Expect(inherited.Addr().String()).To(Equal(listener.Addr().String()))
+		Expect(received).To(Equal(ttls))
+
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(logger).To(gbytes.Say("handed-off"))
+	})
+
+	It("lets the successor accept connections once the server is closed", func() {
+		inherited, _, err := handoff.Receive(logger, socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		defer inherited.Close()
+		Eventually(process.Wait()).Should(Receive())
+		listener.Close()
+
+		accepted := make(chan error, 1)
+		go func() {
+			conn, err := inherited.Accept()
+			if err == nil {
+				conn.Close()
+			}
+			accepted <- err
+		}()
+
+		conn, err := net.Dial("tcp", inherited.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		conn.Close()
+		Eventually(accepted).Should(Receive(BeNil()))
+	})
+
+	It("removes the socket when signalled", func() {
+		ginkgomon.Interrupt(process)
+		Expect(socketPath).NotTo(BeAnExistingFile())
+	})
+
+	Context("when no process is serving handoffs", func() {
+		It("returns ErrNoPredecessor", func() {
+			_, _, err := handoff.Receive(logger, filepath.Join(tmpDir, "missing.sock"))
+			Expect(err).To(Equal(handoff.ErrNoPredecessor))
+		})
+	})
+})
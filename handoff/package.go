@@ -0,0 +1 @@
+package handoff // import "code.cloudfoundry.org/locket/handoff"
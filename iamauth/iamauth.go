@@ -0,0 +1,145 @@
+package iamauth
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/locket/secrets"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	AWSProvider = "aws"
+	GCPProvider = "gcp"
+
+	// CloudSQLLoginScope is the OAuth2 scope of tokens used to log in to
+	// Cloud SQL as an IAM user.
+	CloudSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+)
+
+var (
+	ErrUnknownProvider = errors.New("database iam provider must be aws or gcp")
+	ErrMissingUsername = errors.New("database iam username is required")
+	ErrMissingRegion   = errors.New("database iam region is required for aws")
+	ErrMissingEndpoint = errors.New("database iam endpoint is required for aws")
+)
+
+// Config makes the server log in to its database as an IAM user, with a
+// short-lived token in place of a password. For AWS RDS, Endpoint is the
+// host:port of the database instance and Region its region, and the token
+// is signed with the instance's AWS credentials. For GCP Cloud SQL, the
+// token is an OAuth2 access token for the instance's default service
+// account. Username is the database user in both cases.
+type Config struct {
+	Provider string `json:"provider,omitempty"`
+	Username string `json:"username,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// Enabled returns true if IAM authentication is configured.
+func (c Config) Enabled() bool {
+	return c.Provider != ""
+}
+
+func (c Config) Validate() error {
+	if c.Username == "" {
+		return ErrMissingUsername
+	}
+
+	switch c.Provider {
+	case AWSProvider:
+		if c.Region == "" {
+			return ErrMissingRegion
+		}
+		if c.Endpoint == "" {
+			return ErrMissingEndpoint
+		}
+	case GCPProvider:
+	default:
+		return ErrUnknownProvider
+	}
+	return nil
+}
+
+//go:generate counterfeiter . TokenSource
+
+// TokenSource returns a token that is valid for logging in now. It is
+// called for every new database connection.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// NewTokenSource returns the TokenSource for the configured provider.
+func NewTokenSource(config Config) (TokenSource, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Provider == AWSProvider {
+		sess, err := session.NewSession(&aws.Config{Region: aws.String(config.Region)})
+		if err != nil {
+			return nil, err
+		}
+		return &rdsTokenSource{config: config, session: sess}, nil
+	}
+
+	tokenSource, err := google.DefaultTokenSource(context.Background(), CloudSQLLoginScope)
+	if err != nil {
+		return nil, err
+	}
+	return &cloudSQLTokenSource{tokenSource: tokenSource}, nil
+}
+
+// rdsTokenSource signs a new token for every connection. Signing is local
+// and each token is valid for 15 minutes, which covers the login.
+type rdsTokenSource struct {
+	config  Config
+	session *session.Session
+}
+
+func (s *rdsTokenSource) Token() (string, error) {
+	return rdsutils.BuildAuthToken(s.config.Endpoint, s.config.Region, s.config.Username, s.session.Config.Credentials)
+}
+
+// cloudSQLTokenSource reuses an access token until it is about to expire,
+// and then fetches a new one.
+type cloudSQLTokenSource struct {
+	tokenSource oauth2.TokenSource
+}
+
+func (s *cloudSQLTokenSource) Token() (string, error) {
+	token, err := s.tokenSource.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// WithToken returns the connection string dsn, as prepared for driverName,
+// logging in as username with token for a password. MySQL only accepts
+// IAM tokens as cleartext passwords, so they are allowed; the connection
+// string should require TLS.
+func WithToken(driverName, dsn, username, token string) (string, error) {
+	dsn, err := secrets.WithDatabaseCredentials(driverName, dsn, username, token)
+	if err != nil {
+		return "", err
+	}
+
+	if driverName != "mysql" {
+		return dsn, nil
+	}
+
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	cfg.AllowCleartextPasswords = true
+	return cfg.FormatDSN(), nil
+}
@@ -0,0 +1,13 @@
+package iamauth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestIAMAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "IAMAuth Suite")
+}
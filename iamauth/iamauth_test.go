@@ -0,0 +1,111 @@
+package iamauth_test
+
+import (
+	"os"
+	"strings"
+
+	"code.cloudfoundry.org/locket/iamauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IAM authentication", func() {
+	Context("Config", func() {
+		var config iamauth.Config
+
+		BeforeEach(func() {
+			config = iamauth.Config{
+				Provider: iamauth.AWSProvider,
+				Username: "locket",
+				Region:   "us-east-1",
+				Endpoint: "locket.abc123.us-east-1.rds.amazonaws.com:3306",
+			}
+		})
+
+		It("is enabled when a provider is configured", func() {
+			Expect(config.Enabled()).To(BeTrue())
+			Expect(iamauth.Config{}.Enabled()).To(BeFalse())
+		})
+
+		It("is valid", func() {
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("requires a username", func() {
+			config.Username = ""
+			Expect(config.Validate()).To(Equal(iamauth.ErrMissingUsername))
+		})
+
+		It("requires a region and an endpoint for aws", func() {
+			config.Region = ""
+			Expect(config.Validate()).To(Equal(iamauth.ErrMissingRegion))
+
+			config.Region = "us-east-1"
+			config.Endpoint = ""
+			Expect(config.Validate()).To(Equal(iamauth.ErrMissingEndpoint))
+		})
+
+		It("only requires a username for gcp", func() {
+			Expect(iamauth.Config{Provider: iamauth.GCPProvider, Username: "locket@project.iam"}.Validate()).To(Succeed())
+		})
+
+		It("rejects other providers", func() {
+			config.Provider = "azure"
+			Expect(config.Validate()).To(Equal(iamauth.ErrUnknownProvider))
+		})
+	})
+
+	Context("NewTokenSource", func() {
+		BeforeEach(func() {
+			os.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+			os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("AWS_ACCESS_KEY_ID")
+			os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+		})
+
+		It("signs rds tokens for the endpoint and user", func() {
+			tokenSource, err := iamauth.NewTokenSource(iamauth.Config{
+				Provider: iamauth.AWSProvider,
+				Username: "locket",
+				Region:   "us-east-1",
+				Endpoint: "locket.abc123.us-east-1.rds.amazonaws.com:3306",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			token, err := tokenSource.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.HasPrefix(token, "locket.abc123.us-east-1.rds.amazonaws.com:3306/?")).To(BeTrue())
+			Expect(token).To(ContainSubstring("DBUser=locket"))
+			Expect(token).To(ContainSubstring("X-Amz-Credential=AKIDEXAMPLE"))
+		})
+
+		It("returns an error for an invalid config", func() {
+			_, err := iamauth.NewTokenSource(iamauth.Config{Provider: iamauth.AWSProvider})
+			Expect(err).To(Equal(iamauth.ErrMissingUsername))
+		})
+	})
+
+	Context("WithToken", func() {
+		It("logs in to mysql with the token as a cleartext password", func() {
+			dsn, err := iamauth.WithToken("mysql", "tcp(10.0.0.1:3306)/locket?tls=true", "locket", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dsn).To(HavePrefix("locket:some-token@tcp(10.0.0.1:3306)/locket?"))
+			Expect(dsn).To(ContainSubstring("allowCleartextPasswords=true"))
+			Expect(dsn).To(ContainSubstring("tls=true"))
+		})
+
+		It("logs in to postgres with the token as the password", func() {
+			dsn, err := iamauth.WithToken("postgres", "host=10.0.0.1 dbname=locket sslmode=verify-full", "locket", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dsn).To(Equal("host=10.0.0.1 dbname=locket sslmode=verify-full user='locket' password='some-token'"))
+		})
+
+		It("returns an error for other drivers", func() {
+			_, err := iamauth.WithToken("sqlite3", "locket.db", "locket", "some-token")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
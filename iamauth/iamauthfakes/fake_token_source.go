@@ -0,0 +1,70 @@
+// This file was generated by counterfeiter
+package iamauthfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/locket/iamauth"
+)
+
+type FakeTokenSource struct {
+	TokenStub        func() (string, error)
+	tokenMutex       sync.RWMutex
+	tokenArgsForCall []struct {
+	}
+	tokenReturns struct {
+		result1 string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTokenSource) Token() (string, error) {
+	fake.tokenMutex.Lock()
+	fake.tokenArgsForCall = append(fake.tokenArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Token", []interface{}{})
+	fake.tokenMutex.Unlock()
+	if fake.TokenStub != nil {
+		return fake.TokenStub()
+	} else {
+		return fake.tokenReturns.result1, fake.tokenReturns.result2
+	}
+}
+
+func (fake *FakeTokenSource) TokenCallCount() int {
+	fake.tokenMutex.RLock()
+	defer fake.tokenMutex.RUnlock()
+	return len(fake.tokenArgsForCall)
+}
+
+func (fake *FakeTokenSource) TokenReturns(result1 string, result2 error) {
+	fake.TokenStub = nil
+	fake.tokenReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTokenSource) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.tokenMutex.RLock()
+	defer fake.tokenMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeTokenSource) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ iamauth.TokenSource = new(FakeTokenSource)
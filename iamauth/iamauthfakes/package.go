@@ -0,0 +1 @@
+package iamauthfakes // import "code.cloudfoundry.org/locket/iamauth/iamauthfakes"
@@ -0,0 +1 @@
+package iamauth // import "code.cloudfoundry.org/locket/iamauth"
@@ -0,0 +1,269 @@
+package loadgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	UniformDistribution = "uniform"
+	ZipfDistribution    = "zipf"
+)
+
+var ErrInvalidConfig = errors.New("invalid load generator config")
+
+// Config describes the load to generate. Each of Clients picks a key from
+// Keys keys using Distribution and tries to lock it. When it gets the lock
+// it heartbeats it Heartbeats times, every Interval, and releases it. When
+// it does not, it waits Interval and picks another key.
+type Config struct {
+	Clients      int
+	Keys         int
+	Distribution string
+	TtlInSeconds int64
+	Heartbeats   int
+	Interval     time.Duration
+	Seed         int64
+}
+
+// Validate returns ErrInvalidConfig if c cannot generate any load.
+func (c Config) Validate() error {
+	if c.Clients <= 0 || c.Keys <= 0 || c.TtlInSeconds <= 0 || c.Heartbeats < 0 || c.Interval < 0 {
+		return ErrInvalidConfig
+	}
+	if c.Distribution != UniformDistribution && c.Distribution != ZipfDistribution {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// Latency holds latency percentiles in milliseconds.
+type Latency struct {
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+// Report summarizes a load generator run. Requests counts every request
+// made, and ErrorRate is the fraction of them that failed with anything
+// other than a lock collision.
+type Report struct {
+	Clients            int     `json:"clients"`
+	DurationInSeconds  float64 `json:"duration_in_seconds"`
+	Requests           int     `json:"requests"`
+	RequestsPerSecond  float64 `json:"requests_per_second"`
+	Acquisitions       int     `json:"acquisitions"`
+	Collisions         int     `json:"collisions"`
+	Errors             int     `json:"errors"`
+	ErrorRate          float64 `json:"error_rate"`
+	AcquisitionLatency Latency `json:"acquisition_latency"`
+	HeartbeatLatency   Latency `json:"heartbeat_latency"`
+}
+
+// results are what a single simulated client saw.
+type results struct {
+	requests, acquisitions, collisions, errors int
+	acquisitionLatency, heartbeatLatency       []time.Duration
+}
+
+// Run generates load against client until ctx is done, and reports what
+// happened.
+func Run(logger lager.Logger, ctx context.Context, client models.LocketClient, cfg Config, clock clock.Clock) (*Report, error) {
+	logger = logger.Session("loadgen", lager.Data{"clients": cfg.Clients, "keys": cfg.Keys, "distribution": cfg.Distribution})
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("starting")
+	start := clock.Now()
+
+	allResults := make([]*results, cfg.Clients)
+	wg := sync.WaitGroup{}
+	for i := 0; i < cfg.Clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := &worker{
+				client:  client,
+				cfg:     cfg,
+				clock:   clock,
+				owner:   fmt.Sprintf("loadgen-%d", i),
+				keys:    newKeyPicker(cfg, rand.New(rand.NewSource(cfg.Seed+int64(i)))),
+				results: &results{},
+			}
+			w.run(ctx)
+			allResults[i] = w.results
+		}(i)
+	}
+	wg.Wait()
+
+	report := merge(allResults, clock.Since(start))
+	report.Clients = cfg.Clients
+	logger.Info("complete", lager.Data{"requests": report.Requests, "errors": report.Errors})
+	return report, nil
+}
+
+type worker struct {
+	client  models.LocketClient
+	cfg     Config
+	clock   clock.Clock
+	owner   string
+	keys    func() string
+	results *results
+}
+
+func (w *worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		key := w.keys()
+		resource := &models.Resource{Key: key, Owner: w.owner, Type: models.LockType, TypeCode: models.LOCK}
+
+		latency, err := w.lock(ctx, resource)
+		switch {
+		case err == nil:
+			w.results.acquisitions++
+			w.results.acquisitionLatency = append(w.results.acquisitionLatency, latency)
+			w.hold(ctx, resource)
+		case grpc.Code(err) == codes.AlreadyExists:
+			w.results.collisions++
+		case ctx.Err() != nil:
+			return
+		default:
+			w.results.errors++
+		}
+
+		if !w.wait(ctx) {
+			return
+		}
+	}
+}
+
+// hold heartbeats the lock on resource and then releases it.
+func (w *worker) hold(ctx context.Context, resource *models.Resource) {
+	for i := 0; i < w.cfg.Heartbeats; i++ {
+		if !w.wait(ctx) {
+			break
+		}
+
+		latency, err := w.lock(ctx, resource)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.results.errors++
+			}
+			break
+		}
+		w.results.heartbeatLatency = append(w.results.heartbeatLatency, latency)
+	}
+
+	// release even after ctx is done, so that runs do not leave locks behind
+	releaseCtx, cancel := context.WithTimeout(context.Background(), time.Duration(w.cfg.TtlInSeconds)*time.Second)
+	defer cancel()
+	w.results.requests++
+	_, err := w.client.Release(releaseCtx, &models.ReleaseRequest{Resource: resource})
+	if err != nil {
+		w.results.errors++
+	}
+}
+
+func (w *worker) lock(ctx context.Context, resource *models.Resource) (time.Duration, error) {
+	start := w.clock.Now()
+	_, err := w.client.Lock(ctx, &models.LockRequest{Resource: resource, TtlInSeconds: w.cfg.TtlInSeconds})
+	latency := w.clock.Since(start)
+	if err == nil || ctx.Err() == nil {
+		w.results.requests++
+	}
+	return latency, err
+}
+
+// wait waits for the interval, returning false if ctx is done first.
+func (w *worker) wait(ctx context.Context) bool {
+	if w.cfg.Interval == 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := w.clock.NewTimer(w.cfg.Interval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+func newKeyPicker(cfg Config, r *rand.Rand) func() string {
+	if cfg.Distribution == ZipfDistribution && cfg.Keys > 1 {
+		zipf := rand.NewZipf(r, 1.1, 1, uint64(cfg.Keys-1))
+		return func() string {
+			return fmt.Sprintf("loadgen-key-%d", zipf.Uint64())
+		}
+	}
+	return func() string {
+		return fmt.Sprintf("loadgen-key-%d", r.Intn(cfg.Keys))
+	}
+}
+
+func merge(allResults []*results, duration time.Duration) *Report {
+	report := &Report{DurationInSeconds: duration.Seconds()}
+	var acquisitionLatency, heartbeatLatency []time.Duration
+	for _, r := range allResults {
+		report.Requests += r.requests
+		report.Acquisitions += r.acquisitions
+		report.Collisions += r.collisions
+		report.Errors += r.errors
+		acquisitionLatency = append(acquisitionLatency, r.acquisitionLatency...)
+		heartbeatLatency = append(heartbeatLatency, r.heartbeatLatency...)
+	}
+
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+	if duration > 0 {
+		report.RequestsPerSecond = float64(report.Requests) / duration.Seconds()
+	}
+	report.AcquisitionLatency = Percentiles(acquisitionLatency)
+	report.HeartbeatLatency = Percentiles(heartbeatLatency)
+	return report
+}
+
+// Percentiles sorts latencies and returns their percentiles.
+func Percentiles(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(latencies)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return milliseconds(latencies[i])
+	}
+
+	return Latency{
+		P50: percentile(0.5),
+		P90: percentile(0.9),
+		P99: percentile(0.99),
+		Max: milliseconds(latencies[len(latencies)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
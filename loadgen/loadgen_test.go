@@ -0,0 +1,116 @@
+package loadgen_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/loadgen"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Loadgen", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClient *modelsfakes.FakeLocketClient
+		cfg        loadgen.Config
+		ctx        context.Context
+		cancel     context.CancelFunc
+
+		lock sync.Mutex
+		held map[string]string
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("loadgen")
+		held = map[string]string{}
+
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.LockStub = func(ctx context.Context, req *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			if owner, ok := held[req.Resource.Key]; ok && owner != req.Resource.Owner {
+				return nil, models.ErrLockCollision
+			}
+			held[req.Resource.Key] = req.Resource.Owner
+			return &models.LockResponse{}, nil
+		}
+		fakeClient.ReleaseStub = func(ctx context.Context, req *models.ReleaseRequest, opts ...grpc.CallOption) (*models.ReleaseResponse, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			delete(held, req.Resource.Key)
+			return &models.ReleaseResponse{}, nil
+		}
+
+		cfg = loadgen.Config{
+			Clients:      4,
+			Keys:         1,
+			Distribution: loadgen.UniformDistribution,
+			TtlInSeconds: 15,
+			Heartbeats:   2,
+			Interval:     time.Millisecond,
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("acquires, heartbeats, contends and releases locks", func() {
+		report, err := loadgen.Run(logger, ctx, fakeClient, cfg, clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Clients).To(Equal(4))
+		Expect(report.Acquisitions).To(BeNumerically(">", 0))
+		Expect(report.Collisions).To(BeNumerically(">", 0))
+		Expect(report.Errors).To(BeZero())
+		Expect(report.ErrorRate).To(BeZero())
+		Expect(report.Requests).To(BeNumerically(">=", report.Acquisitions+report.Collisions))
+		Expect(report.AcquisitionLatency.Max).To(BeNumerically(">=", report.AcquisitionLatency.P50))
+		Expect(report.HeartbeatLatency.Max).To(BeNumerically(">=", report.HeartbeatLatency.P50))
+
+		Expect(fakeClient.ReleaseCallCount()).To(Equal(report.Acquisitions))
+		Expect(held).To(BeEmpty())
+	})
+
+	It("spreads clients over the keys", func() {
+		cfg.Keys = 100
+		cfg.Distribution = loadgen.ZipfDistribution
+
+		_, err := loadgen.Run(logger, ctx, fakeClient, cfg, clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+
+		keys := map[string]bool{}
+		for i := 0; i < fakeClient.LockCallCount(); i++ {
+			_, req, _ := fakeClient.LockArgsForCall(i)
+			keys[req.Resource.Key] = true
+		}
+		Expect(len(keys)).To(BeNumerically(">", 1))
+	})
+
+	It("reports the error rate", func() {
+		fakeClient.LockStub = nil
+		fakeClient.LockReturns(nil, errors.New("boom"))
+
+		report, err := loadgen.Run(logger, ctx, fakeClient, cfg, clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Errors).To(BeNumerically(">", 0))
+		Expect(report.ErrorRate).To(BeNumerically("~", 1.0, 0.01))
+		Expect(report.Acquisitions).To(BeZero())
+	})
+
+	It("rejects invalid configs", func() {
+		cfg.Distribution = "gaussian"
+		_, err := loadgen.Run(logger, ctx, fakeClient, cfg, clock.NewClock())
+		Expect(err).To(Equal(loadgen.ErrInvalidConfig))
+	})
+})
@@ -0,0 +1 @@
+package loadgen // import "code.cloudfoundry.org/locket/loadgen"
@@ -0,0 +1,157 @@
+package loadreport
+
+import (
+	"os"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/orca"
+)
+
+// DefaultInterval is how often the server's utilization is sampled when no
+// interval is configured.
+const DefaultInterval = 5 * time.Second
+
+// RPCTimeCost is the name of the per-request cost, in milliseconds, that is
+// reported in the trailers of every RPC.
+const RPCTimeCost = "rpc_time_ms"
+
+type Config struct {
+	Enabled  bool                  `json:"enabled,omitempty"`
+	Interval durationjson.Duration `json:"interval,omitempty"`
+}
+
+// CPUTimeFunc returns the total cpu time used by the process so far.
+type CPUTimeFunc func() (time.Duration, error)
+
+// ProcessCPUTime returns the user and system cpu time used by this process.
+func ProcessCPUTime() (time.Duration, error) {
+	var usage syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), nil
+}
+
+// Reporter samples the server's cpu utilization, request rate and error rate
+// and makes them available as ORCA load reports, both in the trailers of each
+// RPC and on the out-of-band OpenRcaService, so that Envoy or grpc clients
+// balancing across several active servers can weigh them by load.
+type Reporter struct {
+	logger   lager.Logger
+	clock    clock.Clock
+	interval time.Duration
+	cpuTime  CPUTimeFunc
+	recorder orca.ServerMetricsRecorder
+	numCPU   int
+
+	requests int64
+	errors   int64
+}
+
+func NewReporter(logger lager.Logger, clock clock.Clock, interval time.Duration, cpuTime CPUTimeFunc) *Reporter {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Reporter{
+		logger:   logger.Session("load-reporter"),
+		clock:    clock,
+		interval: interval,
+		cpuTime:  cpuTime,
+		recorder: orca.NewServerMetricsRecorder(),
+		numCPU:   runtime.NumCPU(),
+	}
+}
+
+// Provider returns the latest sampled load, for registering the out-of-band
+// OpenRcaService.
+func (r *Reporter) Provider() orca.ServerMetricsProvider {
+	return r.recorder
+}
+
+// ServerOptions returns the options that attach the latest sampled load and
+// each request's cost to the trailers of every unary RPC, and that count
+// requests and errors towards the request and error rates.
+func (r *Reporter) ServerOptions() []grpc.ServerOption {
+	// the call metrics recorder is added to the context by the orca
+	// interceptor, so the cost interceptor has to be chained inside it
+	return []grpc.ServerOption{
+		orca.CallMetricsServerOption(r.recorder),
+		grpc.ChainUnaryInterceptor(r.NewInterceptor()),
+	}
+}
+
+// NewInterceptor returns a unary interceptor that counts each request, and
+// its error, and records how long it took as its RPCTimeCost.
+func (r *Reporter) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := r.clock.Now()
+		resp, err := handler(ctx, req)
+		elapsed := r.clock.Since(start)
+
+		atomic.AddInt64(&r.requests, 1)
+		if err != nil {
+			atomic.AddInt64(&r.errors, 1)
+		}
+
+		if recorder := orca.CallMetricsRecorderFromContext(ctx); recorder != nil {
+			recorder.SetRequestCost(RPCTimeCost, float64(elapsed)/float64(time.Millisecond))
+		}
+
+		return resp, err
+	}
+}
+
+func (r *Reporter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger
+	logger.Info("started", lager.Data{"interval": r.interval.String()})
+	defer logger.Info("complete")
+
+	lastSample := r.clock.Now()
+	lastCPU, err := r.cpuTime()
+	if err != nil {
+		logger.Error("failed-to-read-cpu-time", err)
+	}
+
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+
+		case now := <-ticker.C():
+			elapsed := now.Sub(lastSample)
+			if elapsed <= 0 {
+				continue
+			}
+			lastSample = now
+
+			requests := atomic.SwapInt64(&r.requests, 0)
+			errors := atomic.SwapInt64(&r.errors, 0)
+			r.recorder.SetQPS(float64(requests) / elapsed.Seconds())
+			r.recorder.SetEPS(float64(errors) / elapsed.Seconds())
+
+			cpu, err := r.cpuTime()
+			if err != nil {
+				logger.Error("failed-to-read-cpu-time", err)
+				continue
+			}
+			r.recorder.SetCPUUtilization(float64(cpu-lastCPU) / (float64(elapsed) * float64(r.numCPU)))
+			lastCPU = cpu
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package loadreport_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLoadreport(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Loadreport Suite")
+}
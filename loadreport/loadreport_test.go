@@ -0,0 +1,119 @@
+package loadreport_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/loadreport"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Reporter", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		interval  time.Duration
+		reporter  *loadreport.Reporter
+		process   ifrit.Process
+
+		cpuMutex sync.Mutex
+		cpuTime  time.Duration
+		cpuErr   error
+	)
+
+	setCPUTime := func(t time.Duration, err error) {
+		cpuMutex.Lock()
+		defer cpuMutex.Unlock()
+		cpuTime, cpuErr = t, err
+	}
+
+	call := func(err error) {
+		interceptor := reporter.NewInterceptor()
+		_, callErr := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Fetch"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+		Expect(callErr).To(Equal(err))
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("load-report")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		interval = 10 * time.Second
+		setCPUTime(time.Second, nil)
+
+		reporter = loadreport.NewReporter(logger, fakeClock, interval, func() (time.Duration, error) {
+			cpuMutex.Lock()
+			defer cpuMutex.Unlock()
+			return cpuTime, cpuErr
+		})
+	})
+
+	JustBeforeEach(func() {
+		process = ginkgomon.Invoke(reporter)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("does not report any load before the first interval", func() {
+		metrics := reporter.Provider().ServerMetrics()
+		Expect(metrics.CPUUtilization).To(BeNumerically("<", 0))
+		Expect(metrics.QPS).To(BeNumerically("<", 0))
+	})
+
+	It("reports the cpu used over each interval as a fraction of all cpus", func() {
+		setCPUTime(time.Second+time.Duration(runtime.NumCPU())*5*time.Second, nil)
+		fakeClock.WaitForWatcherAndIncrement(interval)
+
+		Eventually(func() float64 {
+			return reporter.Provider().ServerMetrics().CPUUtilization
+		}).Should(BeNumerically("~", 0.5, 0.001))
+	})
+
+	It("reports the request and error rates over each interval", func() {
+		for i := 0; i < 20; i++ {
+			call(nil)
+		}
+		for i := 0; i < 5; i++ {
+			call(errors.New("boom"))
+		}
+		fakeClock.WaitForWatcherAndIncrement(interval)
+
+		Eventually(func() float64 {
+			return reporter.Provider().ServerMetrics().QPS
+		}).Should(BeNumerically("~", 2.5, 0.001))
+		Expect(reporter.Provider().ServerMetrics().EPS).To(BeNumerically("~", 0.5, 0.001))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(func() float64 {
+			return reporter.Provider().ServerMetrics().QPS
+		}).Should(BeZero())
+	})
+
+	Context("when the cpu time cannot be read", func() {
+		BeforeEach(func() {
+			setCPUTime(0, errors.New("no rusage"))
+		})
+
+		It("logs the error and keeps reporting the request rate", func() {
+			call(nil)
+			fakeClock.WaitForWatcherAndIncrement(interval)
+
+			Eventually(logger).Should(gbytes.Say("failed-to-read-cpu-time"))
+			Eventually(func() float64 {
+				return reporter.Provider().ServerMetrics().QPS
+			}).Should(BeNumerically("~", 0.1, 0.001))
+		})
+	})
+})
@@ -0,0 +1 @@
+package loadreport // import "code.cloudfoundry.org/locket/loadreport"
@@ -0,0 +1,128 @@
+package election
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/lock"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/tedsuo/ifrit"
+)
+
+// AddressMetadataKey is the resource metadata key under which a candidate
+// advertises its address to observers.
+const AddressMetadataKey = "address"
+
+// Candidate campaigns for leadership of a key by holding it with a lock
+// runner. Unlike the lock runner it is ready as soon as it starts
+// campaigning, and it campaigns again whenever leadership is lost instead of
+// exiting.
+type Candidate struct {
+	logger lager.Logger
+
+	locker        models.LocketClient
+	resource      *models.Resource
+	ttlInSeconds  int64
+	clock         clock.Clock
+	retryInterval time.Duration
+
+	leaderLock sync.Mutex
+	leader     bool
+	changes    chan bool
+}
+
+func NewCandidate(
+	logger lager.Logger,
+	locker models.LocketClient,
+	key, owner, address string,
+	ttlInSeconds int64,
+	clock clock.Clock,
+	retryInterval time.Duration,
+) *Candidate {
+	return &Candidate{
+		logger: logger,
+		locker: locker,
+		resource: &models.Resource{
+			Key:      key,
+			Owner:    owner,
+			Metadata: map[string]string{AddressMetadataKey: address},
+		},
+		ttlInSeconds:  ttlInSeconds,
+		clock:         clock,
+		retryInterval: retryInterval,
+		changes:       make(chan bool, 1),
+	}
+}
+
+// IsLeader reports whether the candidate currently holds the key.
+func (c *Candidate) IsLeader() bool {
+	c.leaderLock.Lock()
+	defer c.leaderLock.Unlock()
+	return c.leader
+}
+
+// Changes receives the candidate's leadership whenever it changes. Only the
+// latest change is buffered, so a slow reader sees the current state rather
+// than every transition.
+func (c *Candidate) Changes() <-chan bool {
+	return c.changes
+}
+
+func (c *Candidate) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := c.logger.Session("election", lager.Data{"key": c.resource.Key, "owner": c.resource.Owner})
+
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	process := c.campaign(logger)
+	elected, exited := process.Ready(), process.Wait()
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			process.Signal(sig)
+			<-exited
+			c.setLeader(logger, false)
+			return nil
+
+		case <-elected:
+			elected = nil
+			c.setLeader(logger, true)
+
+		case err := <-exited:
+			logger.Error("lost-leadership", err)
+			c.setLeader(logger, false)
+
+			process = c.campaign(logger)
+			elected, exited = process.Ready(), process.Wait()
+		}
+	}
+}
+
+func (c *Candidate) campaign(logger lager.Logger) ifrit.Process {
+	return ifrit.Background(lock.NewLockRunner(logger, c.locker, c.resource, c.ttlInSeconds, c.clock, c.retryInterval))
+}
+
+func (c *Candidate) setLeader(logger lager.Logger, leader bool) {
+	c.leaderLock.Lock()
+	changed := c.leader != leader
+	c.leader = leader
+	c.leaderLock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	logger.Info("leadership-changed", lager.Data{"leader": leader})
+
+	select {
+	case <-c.changes:
+	default:
+	}
+	c.changes <- leader
+}
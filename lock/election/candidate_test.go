@@ -0,0 +1,117 @@
+package election_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/lock/election"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Candidate", func() {
+	var (
+		logger *lagertest.TestLogger
+
+		fakeLocker    *modelsfakes.FakeLocketClient
+		fakeClock     *fakeclock.FakeClock
+		retryInterval time.Duration
+
+		candidate *election.Candidate
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("election")
+
+		fakeLocker = &modelsfakes.FakeLocketClient{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		retryInterval = time.Second
+
+		candidate = election.NewCandidate(logger, fakeLocker, "leader", "jim", "10.0.0.1:8080", 5, fakeClock, retryInterval)
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Background(candidate)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+	})
+
+	It("is ready before it is elected", func() {
+		fakeLocker.LockReturns(nil, errors.New("no-lock-for-you"))
+		Eventually(process.Ready()).Should(BeClosed())
+		Consistently(candidate.IsLeader).Should(BeFalse())
+	})
+
+	It("campaigns with its advertised address", func() {
+		Eventually(fakeLocker.LockCallCount).Should(BeNumerically(">=", 1))
+		_, lockReq, _ := fakeLocker.LockArgsForCall(0)
+		Expect(lockReq.Resource.Key).To(Equal("leader"))
+		Expect(lockReq.Resource.Owner).To(Equal("jim"))
+		Expect(lockReq.Resource.Metadata).To(HaveKeyWithValue(election.AddressMetadataKey, "10.0.0.1:8080"))
+		Expect(lockReq.TtlInSeconds).To(BeEquivalentTo(5))
+	})
+
+	It("becomes the leader once the key is acquired", func() {
+		Eventually(candidate.IsLeader).Should(BeTrue())
+		Eventually(candidate.Changes()).Should(Receive(BeTrue()))
+	})
+
+	Context("when the key is acquired on a retry", func() {
+		BeforeEach(func() {
+			fakeLocker.LockReturnsOnCall(0, nil, errors.New("no-lock-for-you"))
+		})
+
+		It("becomes the leader", func() {
+			Eventually(fakeLocker.LockCallCount).Should(Equal(1))
+			Expect(candidate.IsLeader()).To(BeFalse())
+
+			fakeClock.WaitForWatcherAndIncrement(retryInterval)
+			Eventually(candidate.IsLeader).Should(BeTrue())
+		})
+	})
+
+	Context("when leadership is lost", func() {
+		BeforeEach(func() {
+			fakeLocker.LockReturnsOnCall(1, nil, errors.New("lost-it"))
+			fakeLocker.LockReturnsOnCall(2, nil, errors.New("still-lost"))
+		})
+
+		It("reports the change and campaigns again", func() {
+			Eventually(candidate.Changes()).Should(Receive(BeTrue()))
+
+			fakeClock.WaitForWatcherAndIncrement(retryInterval)
+			Eventually(candidate.Changes()).Should(Receive(BeFalse()))
+			Expect(candidate.IsLeader()).To(BeFalse())
+			Eventually(fakeLocker.LockCallCount).Should(Equal(3))
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			fakeClock.WaitForWatcherAndIncrement(retryInterval)
+			Eventually(candidate.Changes()).Should(Receive(BeTrue()))
+		})
+	})
+
+	Context("when signalled", func() {
+		It("releases the key and steps down", func() {
+			Eventually(candidate.IsLeader).Should(BeTrue())
+
+			ginkgomon.Interrupt(process)
+			Expect(fakeLocker.ReleaseCallCount()).To(Equal(1))
+			_, releaseReq, _ := fakeLocker.ReleaseArgsForCall(0)
+			Expect(releaseReq.Resource).To(Equal(&models.Resource{
+				Key:      "leader",
+				Owner:    "jim",
+				Metadata: map[string]string{election.AddressMetadataKey: "10.0.0.1:8080"},
+			}))
+			Expect(candidate.IsLeader()).To(BeFalse())
+		})
+	})
+})
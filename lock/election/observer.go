@@ -0,0 +1,133 @@
+package election
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Leader identifies the holder of an election key. The zero value means
+// there is no leader.
+type Leader struct {
+	Owner   string
+	Address string
+}
+
+// Observer tracks the leader of a key by polling Fetch, without campaigning
+// for it.
+type Observer struct {
+	logger lager.Logger
+
+	locker       models.LocketClient
+	key          string
+	clock        clock.Clock
+	pollInterval time.Duration
+
+	leaderLock sync.Mutex
+	leader     Leader
+	changes    chan Leader
+}
+
+func NewObserver(
+	logger lager.Logger,
+	locker models.LocketClient,
+	key string,
+	clock clock.Clock,
+	pollInterval time.Duration,
+) *Observer {
+	return &Observer{
+		logger:       logger,
+		locker:       locker,
+		key:          key,
+		clock:        clock,
+		pollInterval: pollInterval,
+		changes:      make(chan Leader, 1),
+	}
+}
+
+// Leader returns the last observed leader.
+func (o *Observer) Leader() Leader {
+	o.leaderLock.Lock()
+	defer o.leaderLock.Unlock()
+	return o.leader
+}
+
+// Changes receives the leader whenever it changes. Only the latest change is
+// buffered.
+func (o *Observer) Changes() <-chan Leader {
+	return o.changes
+}
+
+// Run is ready once the first poll has completed, so Leader is meaningful
+// from then on.
+func (o *Observer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := o.logger.Session("election-observer", lager.Data{"key": o.key})
+
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	o.poll(logger)
+	close(ready)
+
+	poll := o.clock.NewTimer(o.pollInterval)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+
+		case <-poll.C():
+			o.poll(logger)
+			poll.Reset(o.pollInterval)
+		}
+	}
+}
+
+func (o *Observer) poll(logger lager.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), o.pollInterval)
+	resp, err := o.locker.Fetch(ctx, &models.FetchRequest{Key: o.key}, grpc.FailFast(false))
+	cancel()
+
+	var leader Leader
+	if err != nil {
+		if grpc.Code(err) != codes.NotFound {
+			// keep the last known leader rather than flapping on a transient error
+			logger.Error("failed-to-fetch-leader", err)
+			return
+		}
+	} else {
+		leader = Leader{
+			Owner:   resp.Resource.Owner,
+			Address: resp.Resource.Metadata[AddressMetadataKey],
+		}
+	}
+
+	o.setLeader(logger, leader)
+}
+
+func (o *Observer) setLeader(logger lager.Logger, leader Leader) {
+	o.leaderLock.Lock()
+	changed := o.leader != leader
+	o.leader = leader
+	o.leaderLock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	logger.Info("leader-changed", lager.Data{"owner": leader.Owner, "address": leader.Address})
+
+	select {
+	case <-o.changes:
+	default:
+	}
+	o.changes <- leader
+}
@@ -0,0 +1,99 @@
+package election_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/lock/election"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Observer", func() {
+	var (
+		logger *lagertest.TestLogger
+
+		fakeLocker   *modelsfakes.FakeLocketClient
+		fakeClock    *fakeclock.FakeClock
+		pollInterval time.Duration
+
+		observer *election.Observer
+		process  ifrit.Process
+	)
+
+	leaderResponse := func(owner, address string) *models.FetchResponse {
+		return &models.FetchResponse{Resource: &models.Resource{
+			Key:      "leader",
+			Owner:    owner,
+			Metadata: map[string]string{election.AddressMetadataKey: address},
+		}}
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("election")
+
+		fakeLocker = &modelsfakes.FakeLocketClient{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		pollInterval = time.Second
+
+		fakeLocker.FetchReturns(leaderResponse("jim", "10.0.0.1:8080"), nil)
+
+		observer = election.NewObserver(logger, fakeLocker, "leader", fakeClock, pollInterval)
+	})
+
+	JustBeforeEach(func() {
+		process = ginkgomon.Invoke(observer)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+	})
+
+	It("knows the leader once ready, without campaigning", func() {
+		Expect(observer.Leader()).To(Equal(election.Leader{Owner: "jim", Address: "10.0.0.1:8080"}))
+		Expect(observer.Changes()).To(Receive(Equal(election.Leader{Owner: "jim", Address: "10.0.0.1:8080"})))
+
+		_, fetchReq, _ := fakeLocker.FetchArgsForCall(0)
+		Expect(fetchReq.Key).To(Equal("leader"))
+		Expect(fakeLocker.LockCallCount()).To(Equal(0))
+	})
+
+	It("follows leadership changes", func() {
+		Expect(observer.Changes()).To(Receive())
+
+		fakeLocker.FetchReturns(leaderResponse("bob", "10.0.0.2:8080"), nil)
+		fakeClock.WaitForWatcherAndIncrement(pollInterval)
+
+		Eventually(observer.Changes()).Should(Receive(Equal(election.Leader{Owner: "bob", Address: "10.0.0.2:8080"})))
+		Expect(observer.Leader().Owner).To(Equal("bob"))
+	})
+
+	Context("when there is no leader", func() {
+		BeforeEach(func() {
+			fakeLocker.FetchReturns(nil, models.ErrResourceNotFound)
+		})
+
+		It("reports the zero leader", func() {
+			Expect(observer.Leader()).To(Equal(election.Leader{}))
+		})
+	})
+
+	Context("when fetching the leader fails", func() {
+		It("keeps the last known leader", func() {
+			Expect(observer.Changes()).To(Receive())
+
+			fakeLocker.FetchReturns(nil, errors.New("boom"))
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+
+			Eventually(fakeLocker.FetchCallCount).Should(Equal(2))
+			Consistently(observer.Changes()).ShouldNot(Receive())
+			Expect(observer.Leader().Owner).To(Equal("jim"))
+		})
+	})
+})
@@ -0,0 +1,146 @@
+package lease
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+var locksResource = schema.GroupResource{Group: "locket", Resource: "locks"}
+
+// Lock implements client-go's resourcelock.Interface with a locket lock, so
+// that code using client-go's leader election can be pointed at locket
+// instead of a Kubernetes Lease.
+//
+// The lock is held by the identity of the leader, with the lease duration as
+// its ttl, and its value is the JSON encoded leader election record. Locket
+// expires the lock once the leader stops renewing it, so a candidate that
+// finds the lock gone creates it rather than taking it over.
+type Lock struct {
+	logger   lager.Logger
+	locker   models.LocketClient
+	key      string
+	identity string
+}
+
+var _ resourcelock.Interface = &Lock{}
+
+func New(logger lager.Logger, locker models.LocketClient, key, identity string) *Lock {
+	return &Lock{
+		logger:   logger.Session("lease", lager.Data{"key": key, "identity": identity}),
+		locker:   locker,
+		key:      key,
+		identity: identity,
+	}
+}
+
+// Get returns the leader election record stored in the lock, and its raw
+// value. It returns a Kubernetes NotFound error when no one holds the lock.
+func (l *Lock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	resp, err := l.locker.Fetch(ctx, &models.FetchRequest{Key: l.key})
+	if err != nil {
+		if grpc.Code(err) == codes.NotFound {
+			return nil, nil, apierrors.NewNotFound(locksResource, l.key)
+		}
+		return nil, nil, err
+	}
+
+	raw := []byte(resp.Resource.Value)
+	record := &resourcelock.LeaderElectionRecord{}
+	err = json.Unmarshal(raw, record)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the lock's owner is the authority on who holds it
+	record.HolderIdentity = resp.Resource.Owner
+	return record, raw, nil
+}
+
+// Create takes the lock for ler's holder. It returns a Kubernetes
+// AlreadyExists error when someone else holds the lock.
+func (l *Lock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	err := l.lock(ctx, ler)
+	if grpc.Code(err) == codes.AlreadyExists {
+		return apierrors.NewAlreadyExists(locksResource, l.key)
+	}
+	return err
+}
+
+// Update renews the lock, or takes it over once it has expired. A record
+// without a holder, which client-go writes when it gives up leadership,
+// releases the lock instead. It returns a Kubernetes Conflict error when
+// someone else holds the lock.
+func (l *Lock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	if ler.HolderIdentity == "" {
+		return l.release(ctx)
+	}
+
+	err := l.lock(ctx, ler)
+	if grpc.Code(err) == codes.AlreadyExists {
+		return apierrors.NewConflict(locksResource, l.key, err)
+	}
+	return err
+}
+
+// RecordEvent logs leadership events, as there is no Kubernetes event
+// recorder to send them to.
+func (l *Lock) RecordEvent(event string) {
+	l.logger.Info("event", lager.Data{"event": event})
+}
+
+// Identity returns the identity the lock is held with.
+func (l *Lock) Identity() string {
+	return l.identity
+}
+
+// Describe names the lock in client-go's logs.
+func (l *Lock) Describe() string {
+	return fmt.Sprintf("locket/%s", l.key)
+}
+
+func (l *Lock) lock(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	if ler.LeaseDurationSeconds <= 0 {
+		return models.ErrInvalidTTL
+	}
+
+	value, err := json.Marshal(ler)
+	if err != nil {
+		return err
+	}
+
+	_, err = l.locker.Lock(ctx, &models.LockRequest{
+		Resource: &models.Resource{
+			Key:      l.key,
+			Owner:    ler.HolderIdentity,
+			Value:    string(value),
+			Type:     models.LockType,
+			TypeCode: models.LOCK,
+		},
+		TtlInSeconds: int64(ler.LeaseDurationSeconds),
+	})
+	return err
+}
+
+func (l *Lock) release(ctx context.Context) error {
+	_, err := l.locker.Release(ctx, &models.ReleaseRequest{
+		Resource: &models.Resource{
+			Key:      l.key,
+			Owner:    l.identity,
+			Type:     models.LockType,
+			TypeCode: models.LOCK,
+		},
+	})
+	if grpc.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package lease_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/lock/lease"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+var _ = Describe("Lock", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClient *modelsfakes.FakeLocketClient
+		record     resourcelock.LeaderElectionRecord
+		l          *lease.Lock
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("lease")
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		now := metav1.NewTime(time.Now().Truncate(time.Second))
+		record = resourcelock.LeaderElectionRecord{
+			HolderIdentity:       "controller-0",
+			LeaseDurationSeconds: 15,
+			AcquireTime:          now,
+			RenewTime:            now,
+			LeaderTransitions:    2,
+		}
+		l = lease.New(logger, fakeClient, "controller", "controller-0")
+	})
+
+	It("identifies itself", func() {
+		Expect(l.Identity()).To(Equal("controller-0"))
+		Expect(l.Describe()).To(Equal("locket/controller"))
+	})
+
+	Describe("Get", func() {
+		It("returns the record stored in the lock", func() {
+			value, err := json.Marshal(record)
+			Expect(err).NotTo(HaveOccurred())
+			fakeClient.FetchReturns(&models.FetchResponse{
+				Resource: &models.Resource{Key: "controller", Owner: "controller-0", Value: string(value)},
+			}, nil)
+
+			got, raw, err := l.Get(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw).To(Equal(value))
+			Expect(got.HolderIdentity).To(Equal("controller-0"))
+			Expect(got.LeaseDurationSeconds).To(Equal(15))
+			Expect(got.LeaderTransitions).To(Equal(2))
+			Expect(got.RenewTime.Equal(&record.RenewTime)).To(BeTrue())
+
+			_, req, _ := fakeClient.FetchArgsForCall(0)
+			Expect(req.Key).To(Equal("controller"))
+		})
+
+		It("returns a Kubernetes NotFound error when the lock is not held", func() {
+			fakeClient.FetchReturns(nil, models.ErrResourceNotFound)
+
+			_, _, err := l.Get(context.Background())
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("returns other errors", func() {
+			fakeClient.FetchReturns(nil, errors.New("boom"))
+
+			_, _, err := l.Get(context.Background())
+			Expect(err).To(MatchError("boom"))
+			Expect(apierrors.IsNotFound(err)).To(BeFalse())
+		})
+
+		It("fails when the lock was not written by a lease", func() {
+			fakeClient.FetchReturns(&models.FetchResponse{
+				Resource: &models.Resource{Key: "controller", Owner: "controller-0", Value: "10.0.0.1"},
+			}, nil)
+
+			_, _, err := l.Get(context.Background())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Create", func() {
+		It("locks the key for the holder with the lease duration as ttl", func() {
+			fakeClient.LockReturns(&models.LockResponse{}, nil)
+
+			Expect(l.Create(context.Background(), record)).To(Succeed())
+
+			Expect(fakeClient.LockCallCount()).To(Equal(1))
+			_, req, _ := fakeClient.LockArgsForCall(0)
+			Expect(req.Resource.Key).To(Equal("controller"))
+			Expect(req.Resource.Owner).To(Equal("controller-0"))
+			Expect(req.Resource.TypeCode).To(Equal(models.LOCK))
+			Expect(req.TtlInSeconds).To(Equal(int64(15)))
+
+			var stored resourcelock.LeaderElectionRecord
+			Expect(json.Unmarshal([]byte(req.Resource.Value), &stored)).To(Succeed())
+			Expect(stored.LeaderTransitions).To(Equal(2))
+		})
+
+		It("returns a Kubernetes AlreadyExists error when someone else holds the lock", func() {
+			fakeClient.LockReturns(nil, models.ErrLockCollision)
+
+			err := l.Create(context.Background(), record)
+			Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+		})
+
+		It("rejects a record without a lease duration", func() {
+			record.LeaseDurationSeconds = 0
+
+			Expect(l.Create(context.Background(), record)).To(Equal(models.ErrInvalidTTL))
+			Expect(fakeClient.LockCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("Update", func() {
+		It("renews the lock", func() {
+			fakeClient.LockReturns(&models.LockResponse{}, nil)
+
+			Expect(l.Update(context.Background(), record)).To(Succeed())
+
+			_, req, _ := fakeClient.LockArgsForCall(0)
+			Expect(req.Resource.Owner).To(Equal("controller-0"))
+			Expect(req.TtlInSeconds).To(Equal(int64(15)))
+		})
+
+		It("returns a Kubernetes Conflict error when someone else holds the lock", func() {
+			fakeClient.LockReturns(nil, models.ErrLockCollision)
+
+			err := l.Update(context.Background(), record)
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+		})
+
+		Context("when the record has no holder", func() {
+			BeforeEach(func() {
+				record.HolderIdentity = ""
+				record.LeaseDurationSeconds = 1
+			})
+
+			It("releases the lock", func() {
+				fakeClient.ReleaseReturns(&models.ReleaseResponse{}, nil)
+
+				Expect(l.Update(context.Background(), record)).To(Succeed())
+
+				Expect(fakeClient.LockCallCount()).To(Equal(0))
+				Expect(fakeClient.ReleaseCallCount()).To(Equal(1))
+				_, req, _ := fakeClient.ReleaseArgsForCall(0)
+				Expect(req.Resource.Key).To(Equal("controller"))
+				Expect(req.Resource.Owner).To(Equal("controller-0"))
+			})
+
+			It("succeeds when the lock is already gone", func() {
+				fakeClient.ReleaseReturns(nil, models.ErrResourceNotFound)
+
+				Expect(l.Update(context.Background(), record)).To(Succeed())
+			})
+		})
+	})
+})
@@ -0,0 +1 @@
+package lease // import "code.cloudfoundry.org/locket/lock/lease"
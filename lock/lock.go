@@ -2,15 +2,62 @@ package lock
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics/emitter"
 	"code.cloudfoundry.org/locket/models"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
+const (
+	lockWaitDuration = "LockWaitDuration"
+	lockCollisions   = "LockCollisions"
+
+	lockHeartbeatLatency = "LockHeartbeatLatency"
+	lockClockSkew        = "LockClockSkew"
+	lockTimingWarnings   = "LockTimingWarnings"
+
+	lockLost = "LockLost"
+
+	// DefaultTimingWarningFraction is how much of the ttl the heartbeat round
+	// trip and the skew between the server's clock and the runner's may use
+	// together before the runner warns that the lock is about to flap.
+	DefaultTimingWarningFraction = 0.25
+)
+
+// Why a runner lost a lock it held, as tagged on the LockLost metric and
+// logged with lost-lock.
+const (
+	// LossCollision is a heartbeat refused because someone else holds the
+	// lock, usually after it expired on the server.
+	LossCollision = "collision"
+	// LossTimeout is a heartbeat that got no answer within the ttl.
+	LossTimeout = "timeout"
+	// LossRPCError is a heartbeat that failed for any other reason.
+	LossRPCError = "rpc_error"
+	// LossShutdown is the runner being signalled to release the lock.
+	LossShutdown = "shutdown"
+)
+
+// WaitStats describes how long a runner has been waiting for its lock and
+// what it has been waiting behind. Collisions counts the attempts refused
+// because someone else held the lock since the runner started waiting, and
+// Holder is that someone else as of HolderFetchedAt.
+type WaitStats struct {
+	Acquired        bool          `json:"acquired"`
+	WaitingSince    time.Time     `json:"waiting_since,omitempty"`
+	Waited          time.Duration `json:"waited"`
+	Collisions      int           `json:"collisions"`
+	Holder          string        `json:"holder,omitempty"`
+	HolderFetchedAt time.Time     `json:"holder_fetched_at,omitempty"`
+}
+
 type lockRunner struct {
 	logger lager.Logger
 
@@ -20,6 +67,14 @@ type lockRunner struct {
 	clock          clock.Clock
 	retryInterval  time.Duration
 	exitOnLostLock bool
+	mode           models.LockMode
+	capacity       int32
+	metronClient   emitter.Emitter
+
+	timingWarningFraction float64
+
+	statsMutex *sync.Mutex
+	stats      WaitStats
 }
 
 func NewLockRunner(
@@ -38,6 +93,9 @@ func NewLockRunner(
 		clock:          clock,
 		retryInterval:  retryInterval,
 		exitOnLostLock: true,
+		statsMutex:     &sync.Mutex{},
+
+		timingWarningFraction: DefaultTimingWarningFraction,
 	}
 }
 
@@ -57,6 +115,76 @@ func NewPresenceRunner(
 		clock:          clock,
 		retryInterval:  retryInterval,
 		exitOnLostLock: false,
+		statsMutex:     &sync.Mutex{},
+
+		timingWarningFraction: DefaultTimingWarningFraction,
+	}
+}
+
+// NewSemaphoreRunner holds one of capacity slots on the lock's key, so that
+// at most capacity runners sharing the key are ready at any time. Every
+// runner must use a distinct owner and should agree on the capacity.
+func NewSemaphoreRunner(
+	logger lager.Logger,
+	locker models.LocketClient,
+	lock *models.Resource,
+	capacity int32,
+	ttlInSeconds int64,
+	clock clock.Clock,
+	retryInterval time.Duration,
+) *lockRunner {
+	return &lockRunner{
+		logger:         logger,
+		locker:         locker,
+		lock:           lock,
+		ttlInSeconds:   ttlInSeconds,
+		clock:          clock,
+		retryInterval:  retryInterval,
+		exitOnLostLock: true,
+		mode:           models.SEMAPHORE,
+		capacity:       capacity,
+		statsMutex:     &sync.Mutex{},
+
+		timingWarningFraction: DefaultTimingWarningFraction,
+	}
+}
+
+// WithMetrics makes the runner emit LockWaitDuration.<key> after every
+// failed attempt while it waits for the lock, increment
+// LockCollisions.<key> whenever the lock is held by someone else, and
+// increment LockLost.<cause>.<key> whenever it loses the lock it held.
+func (l *lockRunner) WithMetrics(metronClient emitter.Emitter) *lockRunner {
+	l.metronClient = metronClient
+	return l
+}
+
+// WithTimingWarningFraction sets how much of the ttl the heartbeat round trip
+// and clock skew may use before the runner warns. A fraction of 0 turns the
+// warnings off.
+func (l *lockRunner) WithTimingWarningFraction(fraction float64) *lockRunner {
+	l.timingWarningFraction = fraction
+	return l
+}
+
+// WaitStats returns how long the runner has been waiting for its lock, and
+// behind whom. It can be called while the runner runs.
+func (l *lockRunner) WaitStats() WaitStats {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	stats := l.stats
+	if !stats.Acquired && !stats.WaitingSince.IsZero() {
+		stats.Waited = l.clock.Since(stats.WaitingSince)
+	}
+	return stats
+}
+
+func (l *lockRunner) lockRequest() *models.LockRequest {
+	return &models.LockRequest{
+		Resource:     l.lock,
+		TtlInSeconds: l.ttlInSeconds,
+		Mode:         l.mode,
+		Capacity:     l.capacity,
 	}
 }
 
@@ -66,25 +194,32 @@ func (l *lockRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 	logger.Info("started")
 	defer logger.Info("completed")
 
+	l.startWaiting()
+
 	var acquired, isReady bool
-	_, err := l.locker.Lock(context.Background(), &models.LockRequest{Resource: l.lock, TtlInSeconds: l.ttlInSeconds})
+	hint, holder, err := l.heartbeat(context.Background(), logger)
 	if err != nil {
 		logger.Error("failed-to-acquire-lock", err)
+		l.recordFailure(logger, err, holder)
 	} else {
+		l.recordAcquired()
 		logger.Info("acquired-lock")
 		close(ready)
 		acquired = true
 		isReady = true
 	}
 
-	retry := l.clock.NewTimer(l.retryInterval)
+	retry := l.clock.NewTimer(l.nextAttempt(logger, hint, acquired))
 
 	for {
 		select {
 		case sig := <-signals:
 			logger.Info("signalled", lager.Data{"signal": sig})
+			if acquired {
+				l.recordLoss(logger, LossShutdown)
+			}
 
-			_, err := l.locker.Release(context.Background(), &models.ReleaseRequest{Resource: l.lock})
+			_, err := l.locker.Release(context.Background(), &models.ReleaseRequest{Resource: l.lock, Mode: l.mode})
 			if err != nil {
 				logger.Error("failed-to-release-lock", err)
 			} else {
@@ -95,19 +230,24 @@ func (l *lockRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 
 		case <-retry.C():
 			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(l.ttlInSeconds)*time.Second)
-			_, err := l.locker.Lock(ctx, &models.LockRequest{Resource: l.lock, TtlInSeconds: l.ttlInSeconds}, grpc.FailFast(false))
+			hint, holder, err := l.heartbeat(ctx, logger, grpc.FailFast(false))
 			cancel()
 			if err != nil {
 				if acquired {
-					logger.Error("lost-lock", err)
+					cause := lossCause(err)
+					logger.Error("lost-lock", err, lager.Data{"cause": cause})
+					l.recordLoss(logger, cause)
 					if l.exitOnLostLock {
 						return err
 					}
 
 					acquired = false
+					l.startWaiting()
 				}
+				l.recordFailure(logger, err, holder)
 			} else if !acquired {
 				logger.Info("acquired-lock")
+				l.recordAcquired()
 				if !isReady {
 					close(ready)
 					isReady = true
@@ -115,9 +255,170 @@ func (l *lockRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error
 				acquired = true
 			}
 
-			retry.Reset(l.retryInterval)
+			retry.Reset(l.nextAttempt(logger, hint, acquired))
 		}
 	}
 
 	return nil
 }
+
+// heartbeat takes or refreshes the lock, and checks how much of the ttl is
+// used up by the round trip and by the skew between the server's clock and
+// the runner's. It also returns how long an overloaded server asked the
+// runner to wait before the next attempt, or 0 if it did not, and on a
+// collision the holder the server sent in the trailer, if it did.
+func (l *lockRunner) heartbeat(ctx context.Context, logger lager.Logger, opts ...grpc.CallOption) (time.Duration, *models.LockHolder, error) {
+	var trailer metadata.MD
+	sent := l.clock.Now()
+	resp, err := l.locker.Lock(ctx, l.lockRequest(), append(opts, grpc.Trailer(&trailer))...)
+	if err != nil {
+		holder, holderErr := models.LockHolderFromTrailer(trailer)
+		if holderErr != nil {
+			logger.Debug("failed-to-read-holder", lager.Data{"error": holderErr.Error()})
+		}
+		return models.RetryAfterFromTrailer(trailer), holder, err
+	}
+
+	l.checkTiming(logger, sent, l.clock.Since(sent), resp.GetExpiresAt())
+	return time.Duration(resp.GetHeartbeatIntervalInMilliseconds()) * time.Millisecond, nil, nil
+}
+
+// nextAttempt returns how long to wait before the next attempt: the retry
+// interval, or the server's hint when that is longer. A held lock is still
+// refreshed at least three times per ttl, so that one failed heartbeat does
+// not lose it.
+func (l *lockRunner) nextAttempt(logger lager.Logger, hint time.Duration, acquired bool) time.Duration {
+	if hint <= l.retryInterval {
+		return l.retryInterval
+	}
+
+	wait := hint
+	if maxWait := time.Duration(l.ttlInSeconds) * time.Second / 3; acquired && wait > maxWait {
+		wait = maxWait
+	}
+	if wait <= l.retryInterval {
+		return l.retryInterval
+	}
+
+	logger.Debug("slowing-down-for-server", lager.Data{"wait": wait.String()})
+	return wait
+}
+
+// checkTiming compares the expiry the server reported with the one expected
+// from the runner's clock, assuming the server handled the request halfway
+// through the round trip. Servers that do not report an expiry are only
+// checked for latency.
+func (l *lockRunner) checkTiming(logger lager.Logger, sent time.Time, latency time.Duration, expiresAt int64) {
+	ttl := time.Duration(l.ttlInSeconds) * time.Second
+
+	var skew time.Duration
+	if expiresAt > 0 {
+		expected := sent.Add(latency / 2).Add(ttl)
+		skew = time.Unix(0, expiresAt).Sub(expected)
+		if skew < 0 {
+			skew = -skew
+		}
+	}
+
+	if l.metronClient != nil {
+		sendErr := l.metronClient.SendDuration(lockHeartbeatLatency+"."+l.lock.Key, latency)
+		if sendErr != nil {
+			logger.Error("failed-sending-heartbeat-latency", sendErr)
+		}
+		if expiresAt > 0 {
+			sendErr = l.metronClient.SendDuration(lockClockSkew+"."+l.lock.Key, skew)
+			if sendErr != nil {
+				logger.Error("failed-sending-clock-skew", sendErr)
+			}
+		}
+	}
+
+	if l.timingWarningFraction <= 0 || float64(skew+latency) <= l.timingWarningFraction*float64(ttl) {
+		return
+	}
+
+	logger.Info("clock-skew-or-latency-too-high", lager.Data{
+		"latency":  latency.String(),
+		"skew":     skew.String(),
+		"ttl":      ttl.String(),
+		"fraction": l.timingWarningFraction,
+	})
+
+	if l.metronClient != nil {
+		sendErr := l.metronClient.IncrementCounter(lockTimingWarnings + "." + l.lock.Key)
+		if sendErr != nil {
+			logger.Error("failed-sending-timing-warnings", sendErr)
+		}
+	}
+}
+
+func (l *lockRunner) startWaiting() {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	l.stats = WaitStats{WaitingSince: l.clock.Now()}
+}
+
+func (l *lockRunner) recordAcquired() {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	l.stats = WaitStats{Acquired: true, Holder: l.lock.Owner, HolderFetchedAt: l.clock.Now()}
+}
+
+// recordFailure counts a failed attempt to take the lock, and records who
+// holds it when the server sent that with a collision. Servers that do not
+// send the holder leave the last one known.
+func (l *lockRunner) recordFailure(logger lager.Logger, err error, holder *models.LockHolder) {
+	collision := grpc.Code(err) == codes.AlreadyExists
+
+	l.statsMutex.Lock()
+	now := l.clock.Now()
+	if collision {
+		l.stats.Collisions++
+		if holder != nil {
+			l.stats.Holder = holder.Owner
+			l.stats.HolderFetchedAt = now
+		}
+	}
+	waited := now.Sub(l.stats.WaitingSince)
+	l.statsMutex.Unlock()
+
+	if l.metronClient == nil {
+		return
+	}
+
+	sendErr := l.metronClient.SendDuration(lockWaitDuration+"."+l.lock.Key, waited)
+	if sendErr != nil {
+		logger.Error("failed-sending-wait-duration", sendErr)
+	}
+	if collision {
+		sendErr = l.metronClient.IncrementCounter(lockCollisions + "." + l.lock.Key)
+		if sendErr != nil {
+			logger.Error("failed-sending-collisions", sendErr)
+		}
+	}
+}
+
+// lossCause classifies the error of the heartbeat that lost a held lock.
+func lossCause(err error) string {
+	switch {
+	case grpc.Code(err) == codes.AlreadyExists:
+		return LossCollision
+	case err == context.DeadlineExceeded, grpc.Code(err) == codes.DeadlineExceeded:
+		return LossTimeout
+	default:
+		return LossRPCError
+	}
+}
+
+func (l *lockRunner) recordLoss(logger lager.Logger, cause string) {
+	if l.metronClient == nil {
+		return
+	}
+
+	sendErr := l.metronClient.IncrementCounter(lockLost + "." + cause + "." + l.lock.Key)
+	if sendErr != nil {
+		logger.Error("failed-sending-lock-lost", sendErr)
+	}
+}
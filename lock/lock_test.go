@@ -2,11 +2,14 @@ package lock_test
 
 import (
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/locket"
 	"code.cloudfoundry.org/locket/lock"
@@ -14,6 +17,7 @@ import (
 	"code.cloudfoundry.org/locket/models/modelsfakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/ginkgomon"
 	"golang.org/x/net/context"
@@ -92,11 +96,34 @@ var _ = Describe("Lock", func() {
 				Expect(lockReq.TtlInSeconds).To(Equal(expectedTTL))
 				_, ok := ctx.Deadline()
 				Expect(ok).To(BeTrue(), "no deadline set")
-				Expect(options).To(HaveLen(1))
+				Expect(options).To(HaveLen(2))
 
 				Consistently(lockProcess.Ready()).ShouldNot(BeClosed())
 			})
 
+			Context("because the server is overloaded", func() {
+				BeforeEach(func() {
+					fakeLocker.LockStub = func(ctx context.Context, res *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+						for _, opt := range opts {
+							if trailer, ok := opt.(grpc.TrailerCallOption); ok {
+								*trailer.TrailerAddr = models.RetryAfterTrailer(3 * lockRetryInterval)
+							}
+						}
+						return nil, models.ErrOverloaded
+					}
+				})
+
+				It("waits as long as the server asks before retrying", func() {
+					Eventually(fakeLocker.LockCallCount).Should(Equal(1))
+
+					fakeClock.WaitForWatcherAndIncrement(lockRetryInterval)
+					Consistently(fakeLocker.LockCallCount).Should(Equal(1))
+
+					fakeClock.Increment(2 * lockRetryInterval)
+					Eventually(fakeLocker.LockCallCount).Should(Equal(2))
+				})
+			})
+
 			Context("and the lock becomes available", func() {
 				var done chan struct{}
 
@@ -157,6 +184,31 @@ var _ = Describe("Lock", func() {
 				Expect(lockReq.TtlInSeconds).To(Equal(expectedTTL))
 			})
 
+			Context("and the server asks for fewer heartbeats", func() {
+				BeforeEach(func() {
+					lockRunner = lock.NewLockRunner(
+						logger,
+						fakeLocker,
+						expectedLock,
+						60,
+						fakeClock,
+						lockRetryInterval,
+					)
+					fakeLocker.LockReturns(&models.LockResponse{HeartbeatIntervalInMilliseconds: int64(time.Hour / time.Millisecond)}, nil)
+				})
+
+				It("still heartbeats three times per ttl", func() {
+					Eventually(lockProcess.Ready()).Should(BeClosed())
+					Eventually(fakeLocker.LockCallCount).Should(Equal(1))
+
+					fakeClock.WaitForWatcherAndIncrement(lockRetryInterval)
+					Consistently(fakeLocker.LockCallCount).Should(Equal(1))
+
+					fakeClock.Increment(20*time.Second - lockRetryInterval)
+					Eventually(fakeLocker.LockCallCount).Should(Equal(2))
+				})
+			})
+
 			Context("and then the lock becomes unavailable", func() {
 				var done chan struct{}
 
@@ -285,4 +337,294 @@ var _ = Describe("Lock", func() {
 			})
 		})
 	})
+
+	Context("NewSemaphoreRunner", func() {
+		BeforeEach(func() {
+			lockRunner = lock.NewSemaphoreRunner(
+				logger,
+				fakeLocker,
+				expectedLock,
+				3,
+				expectedTTL,
+				fakeClock,
+				lockRetryInterval,
+			)
+		})
+
+		JustBeforeEach(func() {
+			lockProcess = ifrit.Background(lockRunner)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Kill(lockProcess)
+		})
+
+		It("takes a slot with the configured capacity", func() {
+			Eventually(lockProcess.Ready()).Should(BeClosed())
+			Eventually(fakeLocker.LockCallCount).Should(Equal(1))
+			_, lockReq, _ := fakeLocker.LockArgsForCall(0)
+			Expect(lockReq.Resource).To(Equal(expectedLock))
+			Expect(lockReq.Mode).To(Equal(models.SEMAPHORE))
+			Expect(lockReq.Capacity).To(BeEquivalentTo(3))
+		})
+
+		It("releases the slot when signalled", func() {
+			Eventually(lockProcess.Ready()).Should(BeClosed())
+			ginkgomon.Interrupt(lockProcess)
+			Eventually(fakeLocker.ReleaseCallCount).Should(Equal(1))
+			_, releaseReq, _ := fakeLocker.ReleaseArgsForCall(0)
+			Expect(releaseReq.Mode).To(Equal(models.SEMAPHORE))
+		})
+	})
+	Context("WaitStats", func() {
+		var (
+			fakeMetronClient *mfakes.FakeIngressClient
+			statsRunner      interface {
+				ifrit.Runner
+				WaitStats() lock.WaitStats
+			}
+			holderGone int32
+		)
+
+		BeforeEach(func() {
+			holderGone = 0
+			fakeMetronClient = &mfakes.FakeIngressClient{}
+			statsRunner = lock.NewLockRunner(
+				logger,
+				fakeLocker,
+				expectedLock,
+				expectedTTL,
+				fakeClock,
+				lockRetryInterval,
+			).WithMetrics(fakeMetronClient)
+
+			fakeLocker.LockStub = func(ctx context.Context, res *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+				if atomic.LoadInt32(&holderGone) == 1 {
+					return &models.LockResponse{}, nil
+				}
+				for _, opt := range opts {
+					if trailer, ok := opt.(grpc.TrailerCallOption); ok {
+						*trailer.TrailerAddr, _ = models.LockHolderTrailer(&models.LockHolder{Key: "test", Owner: "bob"})
+					}
+				}
+				return nil, models.ErrLockCollision
+			}
+		})
+
+		JustBeforeEach(func() {
+			lockProcess = ifrit.Background(statsRunner)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Kill(lockProcess)
+		})
+
+		It("tracks how long it has waited, the collisions and the holder", func() {
+			Eventually(fakeLocker.LockCallCount).Should(Equal(1))
+			fakeClock.WaitForWatcherAndIncrement(lockRetryInterval)
+			Eventually(func() int { return statsRunner.WaitStats().Collisions }).Should(Equal(2))
+
+			stats := statsRunner.WaitStats()
+			Expect(stats.Acquired).To(BeFalse())
+			Expect(stats.Waited).To(Equal(lockRetryInterval))
+			Expect(stats.Holder).To(Equal("bob"))
+		})
+
+		It("reads the holder from the collision rather than fetching it", func() {
+			Eventually(func() string { return statsRunner.WaitStats().Holder }).Should(Equal("bob"))
+			Expect(fakeLocker.FetchCallCount()).To(Equal(0))
+		})
+
+		It("emits the wait duration and collisions", func() {
+			Eventually(fakeMetronClient.IncrementCounterCallCount).Should(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockCollisions.test"))
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+			name, _ := fakeMetronClient.SendDurationArgsForCall(0)
+			Expect(name).To(Equal("LockWaitDuration.test"))
+		})
+
+		It("resets once the lock is acquired", func() {
+			Eventually(func() int { return statsRunner.WaitStats().Collisions }).Should(Equal(1))
+			atomic.StoreInt32(&holderGone, 1)
+			fakeClock.WaitForWatcherAndIncrement(lockRetryInterval)
+
+			Eventually(lockProcess.Ready()).Should(BeClosed())
+			stats := statsRunner.WaitStats()
+			Expect(stats.Acquired).To(BeTrue())
+			Expect(stats.Collisions).To(Equal(0))
+			Expect(stats.Holder).To(Equal("jim"))
+		})
+
+		Context("when the lock cannot be taken for another reason", func() {
+			BeforeEach(func() {
+				fakeLocker.LockStub = nil
+				fakeLocker.LockReturns(nil, errors.New("no-lock-for-you"))
+			})
+
+			It("does not count a collision", func() {
+				Eventually(fakeMetronClient.SendDurationCallCount).Should(Equal(1))
+				Expect(statsRunner.WaitStats().Collisions).To(Equal(0))
+				Expect(statsRunner.WaitStats().Holder).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("timing warnings", func() {
+		var (
+			fakeMetronClient *mfakes.FakeIngressClient
+			skew             time.Duration
+		)
+
+		BeforeEach(func() {
+			fakeMetronClient = &mfakes.FakeIngressClient{}
+			lockRunner = lock.NewLockRunner(
+				logger,
+				fakeLocker,
+				expectedLock,
+				expectedTTL,
+				fakeClock,
+				lockRetryInterval,
+			).WithMetrics(fakeMetronClient)
+
+			skew = 0
+			fakeLocker.LockStub = func(ctx context.Context, req *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+				expiresAt := fakeClock.Now().Add(time.Duration(expectedTTL) * time.Second).Add(skew)
+				return &models.LockResponse{Resource: req.Resource, ExpiresAt: expiresAt.UnixNano()}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			lockProcess = ifrit.Background(lockRunner)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Kill(lockProcess)
+		})
+
+		It("emits the heartbeat latency and clock skew", func() {
+			Eventually(lockProcess.Ready()).Should(BeClosed())
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(2))
+			name, latency := fakeMetronClient.SendDurationArgsForCall(0)
+			Expect(name).To(Equal("LockHeartbeatLatency.test"))
+			Expect(latency).To(BeZero())
+			name, reportedSkew := fakeMetronClient.SendDurationArgsForCall(1)
+			Expect(name).To(Equal("LockClockSkew.test"))
+			Expect(reportedSkew).To(BeZero())
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+			Expect(logger).NotTo(gbytes.Say("clock-skew-or-latency-too-high"))
+		})
+
+		Context("when the server's clock is far enough behind", func() {
+			BeforeEach(func() {
+				skew = -2 * time.Second
+			})
+
+			It("warns that the lock may flap", func() {
+				Eventually(lockProcess.Ready()).Should(BeClosed())
+				Expect(logger).To(gbytes.Say("clock-skew-or-latency-too-high"))
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockTimingWarnings.test"))
+
+				_, reportedSkew := fakeMetronClient.SendDurationArgsForCall(1)
+				Expect(reportedSkew).To(Equal(2 * time.Second))
+			})
+		})
+
+		Context("when the server does not report an expiry", func() {
+			BeforeEach(func() {
+				fakeLocker.LockStub = nil
+				fakeLocker.LockReturns(&models.LockResponse{}, nil)
+			})
+
+			It("only emits the latency", func() {
+				Eventually(lockProcess.Ready()).Should(BeClosed())
+				Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+				name, _ := fakeMetronClient.SendDurationArgsForCall(0)
+				Expect(name).To(Equal("LockHeartbeatLatency.test"))
+			})
+		})
+	})
+
+	Context("lock loss", func() {
+		var (
+			fakeMetronClient *mfakes.FakeIngressClient
+			heartbeatErr     error
+		)
+
+		BeforeEach(func() {
+			fakeMetronClient = &mfakes.FakeIngressClient{}
+			lockRunner = lock.NewLockRunner(
+				logger,
+				fakeLocker,
+				expectedLock,
+				expectedTTL,
+				fakeClock,
+				lockRetryInterval,
+			).WithMetrics(fakeMetronClient)
+
+			fakeLocker.LockReturns(&models.LockResponse{}, nil)
+		})
+
+		JustBeforeEach(func() {
+			lockProcess = ifrit.Background(lockRunner)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Kill(lockProcess)
+		})
+
+		loseLock := func() {
+			Eventually(lockProcess.Ready()).Should(BeClosed())
+			fakeLocker.LockReturns(nil, heartbeatErr)
+			fakeClock.WaitForWatcherAndIncrement(lockRetryInterval)
+			Eventually(lockProcess.Wait()).Should(Receive(Equal(heartbeatErr)))
+		}
+
+		It("counts a refused heartbeat as a collision", func() {
+			heartbeatErr = models.ErrLockCollision
+			loseLock()
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockLost.collision.test"))
+			Expect(logger).To(gbytes.Say(`lost-lock.*"cause":"collision"`))
+		})
+
+		It("counts a heartbeat that ran out of time as a timeout", func() {
+			heartbeatErr = grpc.Errorf(codes.DeadlineExceeded, "context deadline exceeded")
+			loseLock()
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockLost.timeout.test"))
+		})
+
+		It("counts any other failed heartbeat as an rpc error", func() {
+			heartbeatErr = errors.New("no-lock-for-you")
+			loseLock()
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockLost.rpc_error.test"))
+		})
+
+		It("counts releasing the lock on a signal as a shutdown", func() {
+			Eventually(lockProcess.Ready()).Should(BeClosed())
+			ginkgomon.Interrupt(lockProcess)
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("LockLost.shutdown.test"))
+		})
+
+		Context("when the lock was never acquired", func() {
+			BeforeEach(func() {
+				fakeLocker.LockReturns(nil, errors.New("no-lock-for-you"))
+			})
+
+			It("counts no loss", func() {
+				Eventually(fakeMetronClient.SendDurationCallCount).Should(Equal(1))
+				ginkgomon.Interrupt(lockProcess)
+
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+			})
+		})
+	})
 })
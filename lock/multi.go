@@ -0,0 +1,208 @@
+package lock
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Member is one of the resources held by a multi-lock runner. Losing a
+// lock makes the runner exit, like a lock runner, while a Presence is
+// retried in the background, like a presence runner.
+type Member struct {
+	Resource *models.Resource
+	Presence bool
+}
+
+// ResourceStatus describes whether a multi-lock runner holds one of its
+// resources. LastError is the error of the latest failed attempt to take
+// it, and is cleared once the resource is held.
+type ResourceStatus struct {
+	Key       string    `json:"key"`
+	Owner     string    `json:"owner"`
+	Presence  bool      `json:"presence,omitempty"`
+	Held      bool      `json:"held"`
+	HeldSince time.Time `json:"held_since,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+type multiLockRunner struct {
+	logger        lager.Logger
+	locker        models.LocketClient
+	members       []Member
+	ttlInSeconds  int64
+	clock         clock.Clock
+	retryInterval time.Duration
+
+	statusMutex *sync.Mutex
+	statuses    []ResourceStatus
+}
+
+// NewMultiLockRunner takes and heartbeats every one of members through
+// locker, so over a single connection, in place of a lock or presence
+// runner for each. It is ready once it holds all of them at the same time,
+// and exits when it loses a member that is not a Presence. Every member
+// shares ttlInSeconds and retryInterval.
+func NewMultiLockRunner(
+	logger lager.Logger,
+	locker models.LocketClient,
+	members []Member,
+	ttlInSeconds int64,
+	clock clock.Clock,
+	retryInterval time.Duration,
+) *multiLockRunner {
+	statuses := make([]ResourceStatus, len(members))
+	for i, member := range members {
+		statuses[i] = ResourceStatus{
+			Key:      member.Resource.Key,
+			Owner:    member.Resource.Owner,
+			Presence: member.Presence,
+		}
+	}
+
+	return &multiLockRunner{
+		logger:        logger,
+		locker:        locker,
+		members:       members,
+		ttlInSeconds:  ttlInSeconds,
+		clock:         clock,
+		retryInterval: retryInterval,
+		statusMutex:   &sync.Mutex{},
+		statuses:      statuses,
+	}
+}
+
+// Status returns whether each member is held, in the order they were
+// given. It can be called while the runner runs.
+func (m *multiLockRunner) Status() []ResourceStatus {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+
+	statuses := make([]ResourceStatus, len(m.statuses))
+	copy(statuses, m.statuses)
+	return statuses
+}
+
+func (m *multiLockRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := m.logger.Session("locket-multi-lock", lager.Data{"resources": len(m.members), "ttl_in_seconds": m.ttlInSeconds})
+
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	isReady := false
+	heartbeat := func() error {
+		err := m.heartbeat(logger)
+		if err != nil {
+			m.releaseAll(logger)
+			return err
+		}
+
+		if !isReady && m.allHeld() {
+			logger.Info("acquired-all")
+			close(ready)
+			isReady = true
+		}
+		return nil
+	}
+
+	err := heartbeat()
+	if err != nil {
+		return err
+	}
+
+	retry := m.clock.NewTimer(m.retryInterval)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			m.releaseAll(logger)
+			return nil
+
+		case <-retry.C():
+			err := heartbeat()
+			if err != nil {
+				return err
+			}
+
+			retry.Reset(m.retryInterval)
+		}
+	}
+}
+
+// heartbeat tries to take or refresh every member, and returns the error
+// of a lock that was held and has been lost.
+func (m *multiLockRunner) heartbeat(logger lager.Logger) error {
+	for i, member := range m.members {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(m.ttlInSeconds)*time.Second)
+		_, err := m.locker.Lock(ctx, &models.LockRequest{Resource: member.Resource, TtlInSeconds: m.ttlInSeconds}, grpc.FailFast(false))
+		cancel()
+
+		m.statusMutex.Lock()
+		status := &m.statuses[i]
+		wasHeld := status.Held
+		if err != nil {
+			status.Held = false
+			status.HeldSince = time.Time{}
+			status.LastError = err.Error()
+		} else {
+			if !wasHeld {
+				status.HeldSince = m.clock.Now()
+			}
+			status.Held = true
+			status.LastError = ""
+		}
+		m.statusMutex.Unlock()
+
+		data := lager.Data{"key": member.Resource.Key}
+		switch {
+		case err != nil && wasHeld:
+			logger.Error("lost-lock", err, data)
+			if !member.Presence {
+				return err
+			}
+		case err != nil:
+			logger.Debug("failed-to-acquire-lock", lager.Data{"key": member.Resource.Key, "error": err.Error()})
+		case !wasHeld:
+			logger.Info("acquired-lock", data)
+		}
+	}
+	return nil
+}
+
+func (m *multiLockRunner) allHeld() bool {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+
+	for _, status := range m.statuses {
+		if !status.Held {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *multiLockRunner) releaseAll(logger lager.Logger) {
+	for i, member := range m.members {
+		m.statusMutex.Lock()
+		held := m.statuses[i].Held
+		m.statuses[i].Held = false
+		m.statusMutex.Unlock()
+		if !held {
+			continue
+		}
+
+		_, err := m.locker.Release(context.Background(), &models.ReleaseRequest{Resource: member.Resource})
+		if err != nil {
+			logger.Error("failed-to-release-lock", err, lager.Data{"key": member.Resource.Key})
+		} else {
+			logger.Info("released-lock", lager.Data{"key": member.Resource.Key})
+		}
+	}
+}
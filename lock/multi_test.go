@@ -0,0 +1,163 @@
+package lock_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/lock"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("MultiLockRunner", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeLocker *modelsfakes.FakeLocketClient
+		fakeClock  *fakeclock.FakeClock
+
+		failingMutex *sync.Mutex
+		failing      map[string]error
+
+		runner interface {
+			ifrit.Runner
+			Status() []lock.ResourceStatus
+		}
+		process ifrit.Process
+	)
+
+	setFailing := func(key string, err error) {
+		failingMutex.Lock()
+		defer failingMutex.Unlock()
+		if err == nil {
+			delete(failing, key)
+		} else {
+			failing[key] = err
+		}
+	}
+
+	held := func() []bool {
+		var held []bool
+		for _, status := range runner.Status() {
+			held = append(held, status.Held)
+		}
+		return held
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("multi-lock")
+		fakeLocker = &modelsfakes.FakeLocketClient{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		failingMutex = &sync.Mutex{}
+		failing = map[string]error{}
+		fakeLocker.LockStub = func(ctx context.Context, req *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+			failingMutex.Lock()
+			defer failingMutex.Unlock()
+			return &models.LockResponse{}, failing[req.Resource.Key]
+		}
+
+		runner = lock.NewMultiLockRunner(
+			logger,
+			fakeLocker,
+			[]lock.Member{
+				{Resource: &models.Resource{Key: "cell-1", Owner: "rep-1", Type: models.PresenceType}, Presence: true},
+				{Resource: &models.Resource{Key: "maintenance", Owner: "rep-1", Type: models.LockType}},
+			},
+			15,
+			fakeClock,
+			locket.RetryInterval,
+		)
+	})
+
+	JustBeforeEach(func() {
+		process = ifrit.Background(runner)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+	})
+
+	It("becomes ready once every resource is held", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+		Expect(held()).To(Equal([]bool{true, true}))
+
+		_, req, _ := fakeLocker.LockArgsForCall(0)
+		Expect(req.Resource.Key).To(Equal("cell-1"))
+		Expect(req.TtlInSeconds).To(BeEquivalentTo(15))
+	})
+
+	It("heartbeats every resource", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+		fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+		Eventually(fakeLocker.LockCallCount).Should(Equal(4))
+	})
+
+	It("releases every resource when signalled", func() {
+		Eventually(process.Ready()).Should(BeClosed())
+		ginkgomon.Interrupt(process)
+
+		Expect(fakeLocker.ReleaseCallCount()).To(Equal(2))
+		Expect(held()).To(Equal([]bool{false, false}))
+	})
+
+	Context("when a resource is held by someone else", func() {
+		BeforeEach(func() {
+			setFailing("maintenance", models.ErrLockCollision)
+		})
+
+		It("is not ready until it is acquired", func() {
+			Eventually(fakeLocker.LockCallCount).Should(Equal(2))
+			Consistently(process.Ready()).ShouldNot(BeClosed())
+
+			status := runner.Status()
+			Expect(status[0].Held).To(BeTrue())
+			Expect(status[1].Held).To(BeFalse())
+			Expect(status[1].LastError).To(Equal(models.ErrLockCollision.Error()))
+
+			setFailing("maintenance", nil)
+			fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+			Eventually(process.Ready()).Should(BeClosed())
+			Expect(runner.Status()[1].LastError).To(BeEmpty())
+		})
+	})
+
+	Context("when a lock is lost", func() {
+		It("releases the rest and exits", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			lostErr := errors.New("lost")
+			setFailing("maintenance", lostErr)
+			fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+
+			Eventually(process.Wait()).Should(Receive(Equal(lostErr)))
+			Expect(fakeLocker.ReleaseCallCount()).To(Equal(1))
+			_, releaseReq, _ := fakeLocker.ReleaseArgsForCall(0)
+			Expect(releaseReq.Resource.Key).To(Equal("cell-1"))
+		})
+	})
+
+	Context("when a presence is lost", func() {
+		It("keeps running and retries it", func() {
+			Eventually(process.Ready()).Should(BeClosed())
+
+			setFailing("cell-1", errors.New("lost"))
+			fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+			Eventually(held).Should(Equal([]bool{false, true}))
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			setFailing("cell-1", nil)
+			fakeClock.WaitForWatcherAndIncrement(locket.RetryInterval)
+			Eventually(held).Should(Equal([]bool{true, true}))
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package lock
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/grouper"
+)
+
+type whileHeldRunner struct {
+	logger     lager.Logger
+	lockRunner ifrit.Runner
+	members    grouper.Members
+	grace      time.Duration
+	clock      clock.Clock
+}
+
+// NewWhileHeldRunner runs members, in order like grouper.NewOrdered, only
+// while lockRunner holds its lock, such as the work a component does only as
+// leader. It is ready once the lock is held and every member is ready.
+//
+// When the lock is lost the members are interrupted straight away, and
+// killed if they have not exited within grace, so that they stop before
+// anyone else can take the lock. The runner then exits with the error the
+// lock runner exited with. When a member exits, or the runner is signalled,
+// the members are stopped before the lock is released.
+func NewWhileHeldRunner(logger lager.Logger, lockRunner ifrit.Runner, members grouper.Members, grace time.Duration, clock clock.Clock) ifrit.Runner {
+	return &whileHeldRunner{
+		logger:     logger,
+		lockRunner: lockRunner,
+		members:    members,
+		grace:      grace,
+		clock:      clock,
+	}
+}
+
+func (r *whileHeldRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("while-held")
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	lockProcess := ifrit.Background(r.lockRunner)
+
+	select {
+	case sig := <-signals:
+		logger.Info("signalled", lager.Data{"signal": sig})
+		lockProcess.Signal(sig)
+		return <-lockProcess.Wait()
+	case err := <-lockProcess.Wait():
+		return err
+	case <-lockProcess.Ready():
+	}
+
+	logger.Info("acquired-lock")
+	group := ifrit.Background(grouper.NewOrdered(os.Interrupt, r.members))
+	groupReady := group.Ready()
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			group.Signal(sig)
+			groupErr := <-group.Wait()
+			lockProcess.Signal(sig)
+			lockErr := <-lockProcess.Wait()
+			if groupErr != nil {
+				return groupErr
+			}
+			return lockErr
+
+		case <-groupReady:
+			groupReady = nil
+			logger.Info("members-ready")
+			close(ready)
+
+		case err := <-group.Wait():
+			logger.Error("member-exited", err)
+			lockProcess.Signal(os.Interrupt)
+			<-lockProcess.Wait()
+			return err
+
+		case err := <-lockProcess.Wait():
+			logger.Error("lost-lock", err)
+			r.stopWithinGrace(logger, group)
+			return err
+		}
+	}
+}
+
+func (r *whileHeldRunner) stopWithinGrace(logger lager.Logger, group ifrit.Process) {
+	group.Signal(os.Interrupt)
+
+	timer := r.clock.NewTimer(r.grace)
+	defer timer.Stop()
+
+	select {
+	case <-group.Wait():
+	case <-timer.C():
+		logger.Info("grace-period-expired", lager.Data{"grace": r.grace.String()})
+		group.Signal(os.Kill)
+	}
+}
@@ -0,0 +1,152 @@
+package lock_test
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/lock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"github.com/tedsuo/ifrit/grouper"
+)
+
+var _ = Describe("WhileHeldRunner", func() {
+	const grace = 10 * time.Second
+
+	var (
+		fakeClock *fakeclock.FakeClock
+
+		acquire  chan struct{}
+		lose     chan error
+		released chan struct{}
+
+		memberStarted   chan struct{}
+		memberStopped   chan struct{}
+		memberExit      chan error
+		ignoreInterrupt bool
+
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		acquire = make(chan struct{})
+		lose = make(chan error, 1)
+		released = make(chan struct{})
+		memberStarted = make(chan struct{})
+		memberStopped = make(chan struct{})
+		memberExit = make(chan error, 1)
+		ignoreInterrupt = false
+	})
+
+	JustBeforeEach(func() {
+		lockRunner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			select {
+			case <-acquire:
+			case <-signals:
+				return nil
+			}
+			close(ready)
+
+			select {
+			case err := <-lose:
+				return err
+			case <-signals:
+				close(released)
+				return nil
+			}
+		})
+
+		member := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			close(memberStarted)
+			close(ready)
+			defer close(memberStopped)
+			for {
+				select {
+				case err := <-memberExit:
+					return err
+				case sig := <-signals:
+					if sig == os.Interrupt && ignoreInterrupt {
+						continue
+					}
+					return nil
+				}
+			}
+		})
+
+		process = ifrit.Background(lock.NewWhileHeldRunner(
+			lagertest.NewTestLogger("while-held"),
+			lockRunner,
+			grouper.Members{{"member", member}},
+			grace,
+			fakeClock,
+		))
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+	})
+
+	It("runs the members only once the lock is held", func() {
+		Consistently(memberStarted).ShouldNot(BeClosed())
+		Consistently(process.Ready()).ShouldNot(BeClosed())
+
+		close(acquire)
+		Eventually(memberStarted).Should(BeClosed())
+		Eventually(process.Ready()).Should(BeClosed())
+	})
+
+	It("stops the members and exits when the lock is lost", func() {
+		close(acquire)
+		Eventually(process.Ready()).Should(BeClosed())
+
+		lostErr := errors.New("lost")
+		lose <- lostErr
+		Eventually(process.Wait()).Should(Receive(Equal(lostErr)))
+		Expect(memberStopped).To(BeClosed())
+	})
+
+	Context("when a member does not stop when interrupted", func() {
+		BeforeEach(func() {
+			ignoreInterrupt = true
+		})
+
+		It("kills it once the grace period is over", func() {
+			close(acquire)
+			Eventually(process.Ready()).Should(BeClosed())
+
+			lose <- errors.New("lost")
+			Consistently(memberStopped).ShouldNot(BeClosed())
+
+			fakeClock.WaitForWatcherAndIncrement(grace)
+			Eventually(memberStopped).Should(BeClosed())
+			Eventually(process.Wait()).Should(Receive())
+		})
+	})
+
+	It("stops the members before releasing the lock when signalled", func() {
+		close(acquire)
+		Eventually(process.Ready()).Should(BeClosed())
+
+		process.Signal(os.Interrupt)
+		Eventually(released).Should(BeClosed())
+		Expect(memberStopped).To(BeClosed())
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	It("releases the lock and exits when a member exits", func() {
+		close(acquire)
+		Eventually(process.Ready()).Should(BeClosed())
+
+		memberErr := errors.New("member failed")
+		memberExit <- memberErr
+		Eventually(process.Wait()).Should(Receive(HaveOccurred()))
+		Expect(released).To(BeClosed())
+	})
+})
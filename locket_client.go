@@ -1,22 +1,25 @@
 package locket
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
 	"time"
 
 	"code.cloudfoundry.org/cfhttp"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/compression"
 	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/vaultpki"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 )
 
-type ClientLocketConfig struct {
-	LocketAddress        string `json:"locket_address,omitempty" yaml:"locket_address,omitempty"`
-	LocketCACertFile     string `json:"locket_ca_cert_file,omitempty" yaml:"locket_ca_cert_file,omitempty"`
-	LocketClientCertFile string `json:"locket_client_cert_file,omitempty" yaml:"locket_client_cert_file,omitempty"`
-	LocketClientKeyFile  string `json:"locket_client_key_file,omitempty" yaml:"locket_client_key_file,omitempty"`
-}
-
 func NewClientSkipCertVerify(logger lager.Logger, config ClientLocketConfig) (models.LocketClient, error) {
 	return newClientInternal(logger, config, true)
 }
@@ -26,21 +29,108 @@ func NewClient(logger lager.Logger, config ClientLocketConfig) (models.LocketCli
 }
 
 func newClientInternal(logger lager.Logger, config ClientLocketConfig, skipCertVerify bool) (models.LocketClient, error) {
-	locketTLSConfig, err := cfhttp.NewTLSConfig(config.LocketClientCertFile, config.LocketClientKeyFile, config.LocketCACertFile)
+	err := config.Validate()
+	// without verification there is no need for the server's ca
+	if err == ErrMissingLocketCACert && skipCertVerify {
+		err = nil
+	}
+	if err != nil {
+		logger.Error("invalid-config", err)
+		return nil, err
+	}
+
+	var locketTLSConfig *tls.Config
+	if config.LocketVault.Enabled() {
+		locketTLSConfig, err = vaultTLSConfig(logger, config)
+	} else if config.LocketClientCertFile == "" && config.LocketAuthToken != "" {
+		locketTLSConfig, err = serverOnlyTLSConfig(config.LocketCACertFile)
+	} else {
+		locketTLSConfig, err = cfhttp.NewTLSConfig(config.LocketClientCertFile, config.LocketClientKeyFile, config.LocketCACertFile)
+	}
 	if err != nil {
 		logger.Error("failed-to-open-tls-config", err, lager.Data{"keypath": config.LocketClientKeyFile, "certpath": config.LocketClientCertFile, "capath": config.LocketCACertFile})
 		return nil, err
 	}
 	locketTLSConfig.InsecureSkipVerify = skipCertVerify
+	if len(config.LocketServerPins) > 0 {
+		locketTLSConfig.VerifyPeerCertificate = VerifyPins(config.LocketServerPins)
+	}
 
-	conn, err := grpc.Dial(
-		config.LocketAddress,
+	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(credentials.NewTLS(locketTLSConfig)),
 		grpc.WithBlock(),
-		grpc.WithTimeout(1*time.Second),
-	)
+		grpc.WithTimeout(config.DialTimeout()),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	}
+	if config.LocketKeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(config.LocketKeepaliveTime),
+			Timeout:             time.Duration(config.LocketKeepaliveTimeout),
+			PermitWithoutStream: true,
+		}))
+	}
+	if config.LocketAuthToken != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(config.LocketAuthToken)))
+	}
+	if config.LocketCompression != "" {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(compression.NewUnaryClientInterceptor(config.LocketCompression)))
+	}
+
+	conn, err := grpc.Dial(config.LocketAddress, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return models.NewLocketClient(conn), nil
 }
+
+// bearerToken sends a token in the authorization metadata of every request,
+// for servers that accept token authentication instead of client
+// certificates.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// vaultTLSConfig presents a certificate issued by Vault, and renews it during
+// a handshake once it is due.
+func vaultTLSConfig(logger lager.Logger, config ClientLocketConfig) (*tls.Config, error) {
+	tlsConfig, err := serverOnlyTLSConfig(config.LocketCACertFile)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := vaultpki.NewIssuer(config.LocketVault)
+	if err != nil {
+		return nil, err
+	}
+
+	rotator, err := vaultpki.NewRotator(logger, issuer, clock.NewClock())
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig.GetClientCertificate = rotator.GetClientCertificate
+	return tlsConfig, nil
+}
+
+func serverOnlyTLSConfig(caFile string) (*tls.Config, error) {
+	caBytes, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caBytes) {
+		return nil, errors.New("unable to load ca certificate")
+	}
+
+	return &tls.Config{
+		RootCAs:    caPool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
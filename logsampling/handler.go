@@ -0,0 +1,52 @@
+package logsampling
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// RulesPath is the debug server path that serves and replaces the sampling
+// rules.
+const RulesPath = "/debug/log-sampling"
+
+type handler struct {
+	logger  lager.Logger
+	sampler *Sampler
+}
+
+// NewHandler returns an http.Handler that writes the sampler's rules as JSON
+// on GET, and replaces them with the JSON body of a PUT.
+func NewHandler(logger lager.Logger, sampler *Sampler) http.Handler {
+	return &handler{
+		logger:  logger.Session("log-sampling-handler"),
+		sampler: sampler,
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var rules Rules
+		err := json.NewDecoder(r.Body).Decode(&rules)
+		if err == nil {
+			err = h.sampler.SetRules(rules)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Info("set-rules", lager.Data{"rules": rules})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(h.sampler.Rules())
+	if err != nil {
+		h.logger.Error("failed-to-encode-rules", err)
+	}
+}
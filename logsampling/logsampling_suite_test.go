@@ -0,0 +1,13 @@
+package logsampling_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLogsampling(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logsampling Suite")
+}
@@ -0,0 +1,127 @@
+package logsampling_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/logsampling"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log sampling", func() {
+	var (
+		sink    *lagertest.TestSink
+		sampler *logsampling.Sampler
+		logger  lager.Logger
+	)
+
+	BeforeEach(func() {
+		sink = lagertest.NewTestSink()
+
+		var err error
+		sampler, err = logsampling.NewSampler(sink, logsampling.Rules{
+			"locket.lock": {OneIn: 100},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		logger = lager.NewLogger("locket")
+		logger.RegisterSink(sampler)
+	})
+
+	heartbeat := func(task string, fail bool) {
+		session := logger.Session(task)
+		session.Debug("started")
+		if fail {
+			session.Error("failed-to-lock", errors.New("boom"))
+		}
+		session.Debug("complete")
+	}
+
+	count := func(message string) int {
+		n := 0
+		for _, log := range sink.Logs() {
+			if log.Message == message {
+				n++
+			}
+		}
+		return n
+	}
+
+	It("keeps about one in OneIn sessions, with all of their messages", func() {
+		for i := 0; i < 1000; i++ {
+			heartbeat("lock", false)
+		}
+
+		Expect(count("locket.lock.started")).To(BeNumerically("~", 10, 8))
+		Expect(count("locket.lock.complete")).To(Equal(count("locket.lock.started")))
+	})
+
+	It("always keeps failures", func() {
+		for i := 0; i < 100; i++ {
+			heartbeat("lock", true)
+		}
+
+		Expect(count("locket.lock.failed-to-lock")).To(Equal(100))
+	})
+
+	It("keeps every message without a rule", func() {
+		for i := 0; i < 100; i++ {
+			heartbeat("fetch", false)
+			heartbeat("lock-group", false)
+		}
+
+		Expect(count("locket.fetch.started")).To(Equal(100))
+		Expect(count("locket.lock-group.started")).To(Equal(100))
+	})
+
+	It("rejects invalid rules", func() {
+		_, err := logsampling.NewSampler(sink, logsampling.Rules{
+			"locket.lock": {OneIn: 100, AlwaysLogLevel: "loud"},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("the handler", func() {
+		var (
+			handler  http.Handler
+			recorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			handler = logsampling.NewHandler(lagertest.NewTestLogger("log-sampling"), sampler)
+			recorder = httptest.NewRecorder()
+		})
+
+		It("serves the rules", func() {
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", logsampling.RulesPath, nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"locket.lock": {"one_in": 100}}`))
+		})
+
+		It("replaces the rules", func() {
+			body := strings.NewReader(`{"locket.fetch": {"one_in": 10, "always_log_level": "info"}}`)
+			handler.ServeHTTP(recorder, httptest.NewRequest("PUT", logsampling.RulesPath, body))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(sampler.Rules()).To(Equal(logsampling.Rules{
+				"locket.fetch": {OneIn: 10, AlwaysLogLevel: "info"},
+			}))
+
+			for i := 0; i < 100; i++ {
+				heartbeat("lock", false)
+			}
+			Expect(count("locket.lock.started")).To(Equal(100))
+		})
+
+		It("keeps the rules when the new ones are invalid", func() {
+			body := strings.NewReader(`{"locket.fetch": {"one_in": -1}}`)
+			handler.ServeHTTP(recorder, httptest.NewRequest("PUT", logsampling.RulesPath, body))
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(sampler.Rules()).To(HaveKey("locket.lock"))
+		})
+	})
+})
@@ -0,0 +1 @@
+package logsampling // import "code.cloudfoundry.org/locket/logsampling"
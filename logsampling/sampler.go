@@ -0,0 +1,144 @@
+package logsampling
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// Rule samples the logs of the sessions whose messages start with a prefix,
+// such as "locket.lock" for the Lock handler.
+type Rule struct {
+	// OneIn keeps every message of 1 in OneIn sessions. 0 and 1 keep them
+	// all.
+	OneIn int `json:"one_in"`
+	// AlwaysLogLevel is the level, "debug", "info", "error" or "fatal", from
+	// which messages are kept whether or not their session is sampled. It
+	// defaults to "error", so that failures are always logged.
+	AlwaysLogLevel string `json:"always_log_level,omitempty"`
+}
+
+// Rules are keyed by message prefix.
+type Rules map[string]Rule
+
+type rule struct {
+	oneIn       uint32
+	alwaysLevel lager.LogLevel
+}
+
+// Sampler is a sink that drops the messages of sessions that are not sampled
+// by its rules, and passes every other message to the sink it wraps. All the
+// messages of a session are kept or dropped together, so that "started" is
+// never logged without its "complete".
+type Sampler struct {
+	sink lager.Sink
+
+	mutex  sync.RWMutex
+	rules  map[string]Rule
+	parsed map[string]rule
+}
+
+func NewSampler(sink lager.Sink, rules Rules) (*Sampler, error) {
+	s := &Sampler{sink: sink}
+	err := s.SetRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Rules returns the rules in use.
+func (s *Sampler) Rules() Rules {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rules := make(Rules, len(s.rules))
+	for prefix, r := range s.rules {
+		rules[prefix] = r
+	}
+	return rules
+}
+
+// SetRules replaces the rules in use. It leaves them unchanged if any of the
+// new rules is invalid.
+func (s *Sampler) SetRules(rules Rules) error {
+	parsed := make(map[string]rule, len(rules))
+	copied := make(Rules, len(rules))
+	for prefix, r := range rules {
+		if r.OneIn < 0 {
+			return fmt.Errorf("one_in for %q must not be negative", prefix)
+		}
+
+		level, err := parseLevel(r.AlwaysLogLevel)
+		if err != nil {
+			return fmt.Errorf("always_log_level for %q: %s", prefix, err)
+		}
+
+		parsed[prefix] = rule{oneIn: uint32(r.OneIn), alwaysLevel: level}
+		copied[prefix] = r
+	}
+
+	s.mutex.Lock()
+	s.rules = copied
+	s.parsed = parsed
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *Sampler) Log(log lager.LogFormat) {
+	if s.keep(log) {
+		s.sink.Log(log)
+	}
+}
+
+func (s *Sampler) keep(log lager.LogFormat) bool {
+	s.mutex.RLock()
+	r, ok := s.match(log.Message)
+	s.mutex.RUnlock()
+
+	if !ok || r.oneIn <= 1 || log.LogLevel >= r.alwaysLevel {
+		return true
+	}
+
+	session, ok := log.Data["session"].(string)
+	if !ok {
+		return true
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(session))
+	return hash.Sum32()%r.oneIn == 0
+}
+
+// match returns the rule with the longest prefix of message.
+func (s *Sampler) match(message string) (rule, bool) {
+	var (
+		matched rule
+		longest = -1
+	)
+	for prefix, r := range s.parsed {
+		if len(prefix) > longest && strings.HasPrefix(message, prefix+".") {
+			matched = r
+			longest = len(prefix)
+		}
+	}
+	return matched, longest >= 0
+}
+
+func parseLevel(level string) (lager.LogLevel, error) {
+	switch level {
+	case "debug":
+		return lager.DEBUG, nil
+	case "info":
+		return lager.INFO, nil
+	case "", "error":
+		return lager.ERROR, nil
+	case "fatal":
+		return lager.FATAL, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", level)
+	}
+}
@@ -0,0 +1,57 @@
+package emitter
+
+import "time"
+
+// Emitter is what metrics are emitted through. The loggregator
+// IngressClient satisfies it, and so do the server's other metrics sinks.
+// This package has no dependencies, so that clients such as the lock runner
+// can take an Emitter without pulling in the sinks.
+type Emitter interface {
+	IncrementCounter(name string) error
+	SendDuration(name string, value time.Duration) error
+	SendMetric(name string, value int) error
+}
+
+// Noop discards every metric.
+type Noop struct{}
+
+func (Noop) IncrementCounter(name string) error                  { return nil }
+func (Noop) SendDuration(name string, value time.Duration) error { return nil }
+func (Noop) SendMetric(name string, value int) error             { return nil }
+
+// Multi returns an Emitter that emits every metric to all of emitters. Each
+// is tried even when another fails, and the first error is returned.
+func Multi(emitters ...Emitter) Emitter {
+	switch len(emitters) {
+	case 0:
+		return Noop{}
+	case 1:
+		return emitters[0]
+	}
+	return multi(emitters)
+}
+
+type multi []Emitter
+
+func (m multi) IncrementCounter(name string) error {
+	return m.each(func(e Emitter) error { return e.IncrementCounter(name) })
+}
+
+func (m multi) SendDuration(name string, value time.Duration) error {
+	return m.each(func(e Emitter) error { return e.SendDuration(name, value) })
+}
+
+func (m multi) SendMetric(name string, value int) error {
+	return m.each(func(e Emitter) error { return e.SendMetric(name, value) })
+}
+
+func (m multi) each(emit func(Emitter) error) error {
+	var firstErr error
+	for _, e := range m {
+		err := emit(e)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
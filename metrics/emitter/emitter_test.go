@@ -0,0 +1,51 @@
+package emitter_test
+
+import (
+	"errors"
+	"time"
+
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multi", func() {
+	var (
+		first, second *mfakes.FakeIngressClient
+		multi         emitter.Emitter
+	)
+
+	BeforeEach(func() {
+		first = new(mfakes.FakeIngressClient)
+		second = new(mfakes.FakeIngressClient)
+		multi = emitter.Multi(first, second)
+	})
+
+	It("emits to every sink", func() {
+		Expect(multi.IncrementCounter("LocksExpired")).To(Succeed())
+		Expect(multi.SendDuration("LockHeartbeatLatency", time.Second)).To(Succeed())
+		Expect(multi.SendMetric("ActiveLocks", 3)).To(Succeed())
+
+		for _, fake := range []*mfakes.FakeIngressClient{first, second} {
+			Expect(fake.IncrementCounterArgsForCall(0)).To(Equal("LocksExpired"))
+			name, duration := fake.SendDurationArgsForCall(0)
+			Expect(name).To(Equal("LockHeartbeatLatency"))
+			Expect(duration).To(Equal(time.Second))
+			name, value := fake.SendMetricArgsForCall(0)
+			Expect(name).To(Equal("ActiveLocks"))
+			Expect(value).To(Equal(3))
+		}
+	})
+
+	It("keeps emitting after a sink fails, and returns the error", func() {
+		first.SendMetricReturns(errors.New("boom"))
+
+		Expect(multi.SendMetric("ActiveLocks", 3)).To(MatchError("boom"))
+		Expect(second.SendMetricCallCount()).To(Equal(1))
+	})
+
+	It("discards metrics without any sinks", func() {
+		Expect(emitter.Multi()).To(Equal(emitter.Noop{}))
+	})
+})
@@ -0,0 +1 @@
+package emitter // import "code.cloudfoundry.org/locket/metrics/emitter"
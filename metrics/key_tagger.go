@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/v2"
+)
+
+const (
+	// otherTag is the tag of keys that match none of the configured prefixes.
+	otherTag = "other"
+	// noKeyTag is the tag of requests that are not for a single key, such as
+	// FetchAll.
+	noKeyTag = "none"
+)
+
+// KeyPrefix names the keys starting with Prefix, for example "cells" for
+// keys starting with "cell-".
+type KeyPrefix struct {
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// KeyTagger tags metrics with the name of the configured prefix their key
+// starts with. Keys that match no prefix share a single tag, so the number
+// of metrics emitted is bounded by the number of prefixes however many keys
+// there are. A nil KeyTagger tags nothing.
+type KeyTagger struct {
+	prefixes []KeyPrefix
+}
+
+func NewKeyTagger(prefixes []KeyPrefix) *KeyTagger {
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	sorted := make([]KeyPrefix, len(prefixes))
+	copy(sorted, prefixes)
+	// the longest matching prefix wins
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+	return &KeyTagger{prefixes: sorted}
+}
+
+// Tag returns the name of the longest prefix of key.
+func (t *KeyTagger) Tag(key string) string {
+	if key == "" {
+		return noKeyTag
+	}
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(key, prefix.Prefix) {
+			return prefix.Name
+		}
+	}
+	return otherTag
+}
+
+// Tagged returns the name of metric tagged for key, or "" if t is nil.
+// Tagged metrics are emitted alongside the untagged ones, as loggregator v1
+// metrics cannot carry tags of their own.
+func (t *KeyTagger) Tagged(metric, key string) string {
+	if t == nil {
+		return ""
+	}
+	return metric + "." + t.Tag(key)
+}
+
+// IncrementCounter increments the counter metric tagged for key.
+func (t *KeyTagger) IncrementCounter(logger lager.Logger, metronClient emitter.Emitter, metric, key string) {
+	name := t.Tagged(metric, key)
+	if name == "" {
+		return
+	}
+	err := metronClient.IncrementCounter(name)
+	if err != nil {
+		logger.Error("failed-sending-tagged-counter", err, lager.Data{"metric": name})
+	}
+}
+
+// SendDuration sends the duration metric tagged for key.
+func (t *KeyTagger) SendDuration(logger lager.Logger, metronClient emitter.Emitter, metric, key string, value time.Duration) {
+	name := t.Tagged(metric, key)
+	if name == "" {
+		return
+	}
+	err := metronClient.SendDuration(name, value)
+	if err != nil {
+		logger.Error("failed-sending-tagged-duration", err, lager.Data{"metric": name})
+	}
+}
+
+// requestKey returns the key a request is for. Group requests are tagged
+// with their first key.
+func requestKey(req interface{}) string {
+	switch r := req.(type) {
+	case *models.LockGroupRequest:
+		if len(r.GetResources()) > 0 {
+			return r.GetResources()[0].GetKey()
+		}
+	case *v2.LockGroupRequest:
+		if len(r.GetResources()) > 0 {
+			return r.GetResources()[0].GetKey()
+		}
+	case *models.LockRequest:
+		return r.GetResource().GetKey()
+	case *models.ReleaseRequest:
+		return r.GetResource().GetKey()
+	case *models.FetchRequest:
+		return r.GetKey()
+	case *models.WaitersRequest:
+		return r.GetKey()
+	case *models.BarrierRequest:
+		return r.GetKey()
+	case *v2.LockRequest:
+		return r.GetResource().GetKey()
+	case *v2.ReleaseRequest:
+		return r.GetResource().GetKey()
+	case *v2.FetchRequest:
+		return r.GetKey()
+	}
+	return ""
+}
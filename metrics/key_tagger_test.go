@@ -0,0 +1,40 @@
+package metrics_test
+
+import (
+	"code.cloudfoundry.org/locket/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyTagger", func() {
+	var tagger *metrics.KeyTagger
+
+	BeforeEach(func() {
+		tagger = metrics.NewKeyTagger([]metrics.KeyPrefix{
+			{Name: "cells", Prefix: "cell-"},
+			{Name: "canary-cells", Prefix: "cell-canary-"},
+			{Name: "bbs", Prefix: "bbs"},
+		})
+	})
+
+	It("tags keys with the name of their longest prefix", func() {
+		Expect(tagger.Tag("bbs")).To(Equal("bbs"))
+		Expect(tagger.Tag("cell-z1-0")).To(Equal("cells"))
+		Expect(tagger.Tag("cell-canary-0")).To(Equal("canary-cells"))
+	})
+
+	It("tags keys that match no prefix as other", func() {
+		Expect(tagger.Tag("auctioneer")).To(Equal("other"))
+		Expect(tagger.Tagged("RequestCount", "auctioneer")).To(Equal("RequestCount.other"))
+	})
+
+	It("tags requests without a key as none", func() {
+		Expect(tagger.Tagged("RequestCount", "")).To(Equal("RequestCount.none"))
+	})
+
+	Context("when no prefixes are configured", func() {
+		It("tags nothing", func() {
+			Expect(metrics.NewKeyTagger(nil).Tagged("RequestCount", "bbs")).To(BeEmpty())
+		})
+	})
+})
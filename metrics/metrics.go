@@ -5,9 +5,9 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/clock"
-	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/metrics/emitter"
 	"code.cloudfoundry.org/locket/models"
 	"github.com/tedsuo/ifrit"
 )
@@ -22,10 +22,10 @@ type metricsNotifier struct {
 	ticker          clock.Clock
 	metricsInterval time.Duration
 	lockDB          db.LockDB
-	metronClient    loggregator_v2.IngressClient
+	metronClient    emitter.Emitter
 }
 
-func NewMetricsNotifier(logger lager.Logger, ticker clock.Clock, metronClient loggregator_v2.IngressClient, metricsInterval time.Duration, lockDB db.LockDB) ifrit.Runner {
+func NewMetricsNotifier(logger lager.Logger, ticker clock.Clock, metronClient emitter.Emitter, metricsInterval time.Duration, lockDB db.LockDB) ifrit.Runner {
 	return &metricsNotifier{
 		logger:          logger,
 		ticker:          ticker,
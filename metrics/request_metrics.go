@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const (
+	requestCount   = "RequestCount"
+	requestLatency = "RequestLatency"
+	requestsFailed = "RequestsFailed"
+
+	methodRequestCount   = "MethodRequestCount"
+	methodRequestLatency = "MethodRequestLatency"
+	methodRequestsFailed = "MethodRequestsFailed"
+)
+
+// NewRequestMetricsInterceptor returns a unary interceptor that emits a
+// request count, latency and failure count for every RPC, the same metrics
+// tagged by tagger with the key of the request, and the same metrics per
+// method, such as MethodRequestCount.Lock.
+func NewRequestMetricsInterceptor(logger lager.Logger, clock clock.Clock, metronClient emitter.Emitter, tagger *KeyTagger) grpc.UnaryServerInterceptor {
+	logger = logger.Session("request-metrics")
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := clock.Now()
+		resp, err := handler(ctx, req)
+		latency := clock.Since(start)
+		key := requestKey(req)
+		method := methodName(info.FullMethod)
+
+		sendErr := metronClient.IncrementCounter(requestCount)
+		if sendErr != nil {
+			logger.Error("failed-sending-request-count", sendErr)
+		}
+		tagger.IncrementCounter(logger, metronClient, requestCount, key)
+		sendErr = metronClient.IncrementCounter(methodRequestCount + "." + method)
+		if sendErr != nil {
+			logger.Error("failed-sending-method-request-count", sendErr)
+		}
+
+		sendErr = metronClient.SendDuration(requestLatency, latency)
+		if sendErr != nil {
+			logger.Error("failed-sending-request-latency", sendErr)
+		}
+		tagger.SendDuration(logger, metronClient, requestLatency, key, latency)
+		sendErr = metronClient.SendDuration(methodRequestLatency+"."+method, latency)
+		if sendErr != nil {
+			logger.Error("failed-sending-method-request-latency", sendErr)
+		}
+
+		if err != nil {
+			sendErr = metronClient.IncrementCounter(requestsFailed)
+			if sendErr != nil {
+				logger.Error("failed-sending-requests-failed", sendErr)
+			}
+			tagger.IncrementCounter(logger, metronClient, requestsFailed, key)
+			sendErr = metronClient.IncrementCounter(methodRequestsFailed + "." + method)
+			if sendErr != nil {
+				logger.Error("failed-sending-method-requests-failed", sendErr)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// methodName returns the method of a full gRPC method name, such as Lock for
+// /models.Locket/Lock. Versions of the API share the metrics of a method.
+func methodName(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
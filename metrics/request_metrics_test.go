@@ -0,0 +1,92 @@
+package metrics_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/metrics"
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("RequestMetricsInterceptor", func() {
+	var (
+		fakeMetronClient *mfakes.FakeIngressClient
+		fakeClock        *fakeclock.FakeClock
+		logger           *lagertest.TestLogger
+		handlerErr       error
+		tagger           *metrics.KeyTagger
+		req              interface{}
+	)
+
+	BeforeEach(func() {
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("metrics")
+		handlerErr = nil
+		tagger = nil
+		req = nil
+	})
+
+	JustBeforeEach(func() {
+		interceptor := metrics.NewRequestMetricsInterceptor(logger, fakeClock, fakeMetronClient, tagger)
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			fakeClock.Increment(time.Second)
+			return nil, handlerErr
+		})
+		Expect(err).To(Equal(handlerErr))
+	})
+
+	It("emits the request count, in total and for the method", func() {
+		Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
+		Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RequestCount"))
+		Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal("MethodRequestCount.Lock"))
+	})
+
+	It("emits the request latency, in total and for the method", func() {
+		Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(2))
+		name, value := fakeMetronClient.SendDurationArgsForCall(0)
+		Expect(name).To(Equal("RequestLatency"))
+		Expect(value).To(Equal(time.Second))
+		name, value = fakeMetronClient.SendDurationArgsForCall(1)
+		Expect(name).To(Equal("MethodRequestLatency.Lock"))
+		Expect(value).To(Equal(time.Second))
+	})
+
+	Context("when the request fails", func() {
+		BeforeEach(func() {
+			handlerErr = errors.New("boom")
+		})
+
+		It("emits the failed request count, in total and for the method", func() {
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(4))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(2)).To(Equal("RequestsFailed"))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(3)).To(Equal("MethodRequestsFailed.Lock"))
+		})
+	})
+
+	Context("when key prefixes are configured", func() {
+		BeforeEach(func() {
+			tagger = metrics.NewKeyTagger([]metrics.KeyPrefix{{Name: "bbs", Prefix: "bbs"}})
+			req = &models.LockRequest{Resource: &models.Resource{Key: "bbs"}}
+			handlerErr = errors.New("boom")
+		})
+
+		It("also emits the metrics tagged with the key prefix", func() {
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(6))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal("RequestCount.bbs"))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(4)).To(Equal("RequestsFailed.bbs"))
+
+			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(3))
+			name, value := fakeMetronClient.SendDurationArgsForCall(1)
+			Expect(name).To(Equal("RequestLatency.bbs"))
+			Expect(value).To(Equal(time.Second))
+		})
+	})
+})
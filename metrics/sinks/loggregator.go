@@ -0,0 +1,66 @@
+package sinks
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
+)
+
+var ErrTagsNeedV2API = errors.New("metrics tags need loggregator_use_v2_api")
+
+// TaggedLoggregator emits metrics as loggregator v2 envelopes carrying the
+// job tags of the loggregator config and the static tags, which the
+// IngressClient of the compatibility package cannot add. Durations are
+// gauges in nanoseconds, as the compatibility client sends them.
+type TaggedLoggregator struct {
+	*loggregator.IngressClient
+}
+
+// NewTaggedLoggregator returns a TaggedLoggregator, or ErrTagsNeedV2API if
+// config does not use the v2 API, as v1 envelopes cannot carry tags.
+func NewTaggedLoggregator(config loggregator_v2.Config, tags map[string]string) (*TaggedLoggregator, error) {
+	if !config.UseV2API {
+		return nil, ErrTagsNeedV2API
+	}
+
+	tlsConfig, err := loggregator.NewIngressTLSConfig(config.CACertPath, config.CertPath, config.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []loggregator.IngressOption{
+		loggregator.WithAddr(fmt.Sprintf("localhost:%d", config.APIPort)),
+		loggregator.WithTag("deployment", config.JobDeployment),
+		loggregator.WithTag("job", config.JobName),
+		loggregator.WithTag("index", config.JobIndex),
+		loggregator.WithTag("ip", config.JobIP),
+		loggregator.WithTag("origin", config.JobOrigin),
+	}
+	for _, name := range sortedTagNames(tags) {
+		opts = append(opts, loggregator.WithTag(name, tags[name]))
+	}
+
+	client, err := loggregator.NewIngressClient(tlsConfig, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TaggedLoggregator{IngressClient: client}, nil
+}
+
+func (l *TaggedLoggregator) IncrementCounter(name string) error {
+	l.EmitCounter(name)
+	return nil
+}
+
+func (l *TaggedLoggregator) SendDuration(name string, value time.Duration) error {
+	l.EmitGauge(loggregator.WithGaugeValue(name, float64(value), "nanos"))
+	return nil
+}
+
+func (l *TaggedLoggregator) SendMetric(name string, value int) error {
+	l.EmitGauge(loggregator.WithGaugeValue(name, float64(value), "Metric"))
+	return nil
+}
@@ -0,0 +1 @@
+package sinks // import "code.cloudfoundry.org/locket/metrics/sinks"
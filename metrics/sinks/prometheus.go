@@ -0,0 +1,179 @@
+package sinks
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PrometheusPath is where the debug server serves the Prometheus metrics
+// when they have no listen address of their own.
+const PrometheusPath = "/metrics"
+
+const defaultNamespace = "locket"
+
+// maxPrometheusTags is how many tags each metric keeps its own series for.
+// The names of keyed metrics carry a key or a key prefix after a dot, and
+// keys are unbounded, so later tags share the otherPrometheusTag series.
+const (
+	maxPrometheusTags  = 100
+	otherPrometheusTag = "other"
+	tagLabel           = "tag"
+)
+
+type PrometheusConfig struct {
+	ListenAddress string `json:"listen_address,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+}
+
+// Prometheus keeps the metrics emitted to it in a registry, and serves them
+// to Prometheus scrapes. Metric names are prefixed with the namespace, and
+// characters Prometheus does not allow are replaced by underscores.
+// Counters get a _total suffix, and durations are gauges in seconds with a
+// _seconds suffix. A name with a dot, such as LocksExpired.cells, is tagged:
+// the part after the first dot becomes the tag label of a metric named after
+// the part before it with a _by_tag suffix, such as
+// locket_LocksExpired_by_tag_total{tag="cells"}, so that every tag shares one
+// collector and the untagged totals are not counted twice when summed.
+// Static tags are constant labels of every metric.
+type Prometheus struct {
+	namespace string
+	labels    prometheus.Labels
+	registry  *prometheus.Registry
+	handler   http.Handler
+
+	mutex    sync.Mutex
+	counters map[string]*prometheus.CounterVec
+	gauges   map[string]*prometheus.GaugeVec
+	tags     map[string]map[string]bool
+}
+
+func NewPrometheus(config PrometheusConfig, tags map[string]string) *Prometheus {
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	registry := prometheus.NewRegistry()
+	return &Prometheus{
+		namespace: namespace,
+		labels:    prometheus.Labels(tags),
+		registry:  registry,
+		handler:   promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		counters:  make(map[string]*prometheus.CounterVec),
+		gauges:    make(map[string]*prometheus.GaugeVec),
+		tags:      make(map[string]map[string]bool),
+	}
+}
+
+func (p *Prometheus) IncrementCounter(name string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	fullName, labels := p.metricName(name, "_total")
+	counter, ok := p.counters[fullName]
+	if !ok {
+		counter = prometheus.NewCounterVec(prometheus.CounterOpts{Name: fullName, Help: help(name), ConstLabels: p.labels}, labelNames(labels))
+		err := p.registry.Register(counter)
+		if err != nil {
+			return err
+		}
+		p.counters[fullName] = counter
+	}
+
+	counter.WithLabelValues(p.labelValues(fullName, labels)...).Inc()
+	return nil
+}
+
+func (p *Prometheus) SendDuration(name string, value time.Duration) error {
+	return p.setGauge(name, "_seconds", value.Seconds())
+}
+
+func (p *Prometheus) SendMetric(name string, value int) error {
+	return p.setGauge(name, "", float64(value))
+}
+
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.handler.ServeHTTP(w, r)
+}
+
+func (p *Prometheus) setGauge(name, suffix string, value float64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	fullName, labels := p.metricName(name, suffix)
+	gauge, ok := p.gauges[fullName]
+	if !ok {
+		gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: fullName, Help: help(name), ConstLabels: p.labels}, labelNames(labels))
+		err := p.registry.Register(gauge)
+		if err != nil {
+			return err
+		}
+		p.gauges[fullName] = gauge
+	}
+
+	gauge.WithLabelValues(p.labelValues(fullName, labels)...).Set(value)
+	return nil
+}
+
+// metricName returns the Prometheus name of the metric name, and its tag if
+// it has one.
+func (p *Prometheus) metricName(name, suffix string) (string, []string) {
+	var labels []string
+	if i := strings.Index(name, "."); i >= 0 {
+		labels = []string{name[i+1:]}
+		name = name[:i] + "_by_tag"
+	}
+
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return p.namespace + "_" + sanitized + suffix, labels
+}
+
+// labelValues bounds the tags of fullName to maxPrometheusTags.
+func (p *Prometheus) labelValues(fullName string, labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	tags, ok := p.tags[fullName]
+	if !ok {
+		tags = make(map[string]bool)
+		p.tags[fullName] = tags
+	}
+
+	tag := labels[0]
+	if !tags[tag] {
+		if len(tags) >= maxPrometheusTags {
+			return []string{otherPrometheusTag}
+		}
+		tags[tag] = true
+	}
+	return []string{tag}
+}
+
+func labelNames(labels []string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+	return []string{tagLabel}
+}
+
+// help is the name the metric is emitted with to the other sinks, without
+// its tag.
+func help(name string) string {
+	if i := strings.Index(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
@@ -0,0 +1,95 @@
+package sinks_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/locket/metrics/sinks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prometheus", func() {
+	var prometheus *sinks.Prometheus
+
+	BeforeEach(func() {
+		prometheus = sinks.NewPrometheus(sinks.PrometheusConfig{}, nil)
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		prometheus.ServeHTTP(recorder, httptest.NewRequest("GET", sinks.PrometheusPath, nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		return recorder.Body.String()
+	}
+
+	It("serves counters", func() {
+		Expect(prometheus.IncrementCounter("LocksExpired")).To(Succeed())
+		Expect(prometheus.IncrementCounter("LocksExpired")).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("# TYPE locket_LocksExpired_total counter\nlocket_LocksExpired_total 2\n"))
+	})
+
+	It("serves durations as gauges in seconds", func() {
+		Expect(prometheus.SendDuration("LockHeartbeatLatency", 1500*time.Millisecond)).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("# TYPE locket_LockHeartbeatLatency_seconds gauge\nlocket_LockHeartbeatLatency_seconds 1.5\n"))
+	})
+
+	It("serves the last value sent", func() {
+		Expect(prometheus.SendMetric("ActiveLocks", 3)).To(Succeed())
+		Expect(prometheus.SendMetric("ActiveLocks", 2)).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("locket_ActiveLocks 2\n"))
+	})
+
+	It("replaces characters Prometheus does not allow in names", func() {
+		Expect(prometheus.SendMetric("Active-Locks", 1)).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("locket_Active_Locks 1\n"))
+	})
+
+	It("serves the tag of a keyed name as a label of a single metric", func() {
+		Expect(prometheus.IncrementCounter("LocksExpired")).To(Succeed())
+		Expect(prometheus.IncrementCounter("LocksExpired.cells-east")).To(Succeed())
+		Expect(prometheus.IncrementCounter("LocksExpired.cells-west")).To(Succeed())
+		Expect(prometheus.SendDuration("RequestLatency.cells-east", time.Second)).To(Succeed())
+
+		metrics := scrape()
+		Expect(metrics).To(ContainSubstring("locket_LocksExpired_total 1\n"))
+		Expect(metrics).To(ContainSubstring(`locket_LocksExpired_by_tag_total{tag="cells-east"} 1`))
+		Expect(metrics).To(ContainSubstring(`locket_LocksExpired_by_tag_total{tag="cells-west"} 1`))
+		Expect(metrics).To(ContainSubstring(`locket_RequestLatency_by_tag_seconds{tag="cells-east"} 1`))
+	})
+
+	It("shares one series between the tags past the limit", func() {
+		for i := 0; i < 150; i++ {
+			Expect(prometheus.IncrementCounter(fmt.Sprintf("LockCollisions.key-%d", i))).To(Succeed())
+		}
+
+		metrics := scrape()
+		Expect(strings.Count(metrics, "locket_LockCollisions_by_tag_total{")).To(Equal(101))
+		Expect(metrics).To(ContainSubstring(`locket_LockCollisions_by_tag_total{tag="key-99"} 1`))
+		Expect(metrics).To(ContainSubstring(`locket_LockCollisions_by_tag_total{tag="other"} 50`))
+	})
+
+	It("uses the configured namespace", func() {
+		prometheus = sinks.NewPrometheus(sinks.PrometheusConfig{Namespace: "lockserver"}, nil)
+		Expect(prometheus.SendMetric("ActiveLocks", 1)).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("lockserver_ActiveLocks 1\n"))
+	})
+
+	It("labels every metric with the static tags", func() {
+		prometheus = sinks.NewPrometheus(sinks.PrometheusConfig{}, map[string]string{"az": "z1", "instance_id": "locket-0"})
+		Expect(prometheus.SendMetric("ActiveLocks", 1)).To(Succeed())
+		Expect(prometheus.IncrementCounter("LocksExpired.cells")).To(Succeed())
+
+		metrics := scrape()
+		Expect(metrics).To(ContainSubstring(`locket_ActiveLocks{az="z1",instance_id="locket-0"} 1`))
+		Expect(metrics).To(ContainSubstring(`locket_LocksExpired_by_tag_total{az="z1",instance_id="locket-0",tag="cells"} 1`))
+	})
+})
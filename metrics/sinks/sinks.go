@@ -0,0 +1,72 @@
+package sinks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	loggregator_v2 "code.cloudfoundry.org/go-loggregator/compatibility"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+)
+
+const (
+	LoggregatorSink = "loggregator"
+	PrometheusSink  = "prometheus"
+	StatsDSink      = "statsd"
+	NoneSink        = "none"
+)
+
+// The loggregator IngressClient is one of the sinks.
+var _ emitter.Emitter = loggregator_v2.IngressClient(nil)
+
+// tagNamePattern is what a static tag may be named, so that it is a valid
+// Prometheus label name.
+var tagNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Config selects the sinks metrics are emitted to. Every metric goes to all
+// of them. Without any sinks, metrics go to loggregator alone. Tags, such as
+// the instance id or availability zone, are added to every metric by every
+// sink.
+type Config struct {
+	Sinks      []string          `json:"sinks,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Prometheus PrometheusConfig  `json:"prometheus"`
+	StatsD     StatsDConfig      `json:"statsd"`
+}
+
+// SinkNames returns the configured sinks, defaulting to loggregator. It
+// returns an error for sinks it does not know.
+func (c Config) SinkNames() ([]string, error) {
+	if len(c.Sinks) == 0 {
+		return []string{LoggregatorSink}, nil
+	}
+
+	for _, name := range c.Sinks {
+		switch name {
+		case LoggregatorSink, PrometheusSink, StatsDSink, NoneSink:
+		default:
+			return nil, fmt.Errorf("unknown metrics sink %q", name)
+		}
+	}
+	return c.Sinks, nil
+}
+
+// ValidateTags returns an error for tags that are not valid Prometheus label
+// names, or that clash with the label of keyed metrics.
+func (c Config) ValidateTags() error {
+	for _, name := range sortedTagNames(c.Tags) {
+		if !tagNamePattern.MatchString(name) || name == tagLabel {
+			return fmt.Errorf("invalid metrics tag %q", name)
+		}
+	}
+	return nil
+}
+
+func sortedTagNames(tags map[string]string) []string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
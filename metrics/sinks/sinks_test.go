@@ -0,0 +1,40 @@
+package sinks_test
+
+import (
+	"code.cloudfoundry.org/locket/metrics/sinks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sinks", func() {
+	Describe("Config", func() {
+		It("defaults to loggregator", func() {
+			names, err := sinks.Config{}.SinkNames()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{sinks.LoggregatorSink}))
+		})
+
+		It("returns the configured sinks", func() {
+			names, err := sinks.Config{Sinks: []string{"prometheus", "statsd"}}.SinkNames()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{sinks.PrometheusSink, sinks.StatsDSink}))
+		})
+
+		It("rejects unknown sinks", func() {
+			_, err := sinks.Config{Sinks: []string{"graphite"}}.SinkNames()
+			Expect(err).To(MatchError(`unknown metrics sink "graphite"`))
+		})
+
+		It("accepts tags that are valid label names", func() {
+			Expect(sinks.Config{Tags: map[string]string{"az": "z1", "instance_id": "locket-0"}}.ValidateTags()).To(Succeed())
+		})
+
+		It("rejects tags that are not valid label names", func() {
+			Expect(sinks.Config{Tags: map[string]string{"instance-id": "locket-0"}}.ValidateTags()).To(MatchError(`invalid metrics tag "instance-id"`))
+		})
+
+		It("rejects the tag label of keyed metrics", func() {
+			Expect(sinks.Config{Tags: map[string]string{"tag": "x"}}.ValidateTags()).To(MatchError(`invalid metrics tag "tag"`))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package sinks
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StatsDConfig struct {
+	Address string `json:"address,omitempty"`
+	Prefix  string `json:"prefix,omitempty"`
+}
+
+// StatsD emits metrics to a StatsD server over UDP: counters as counts,
+// durations as timings in milliseconds, and values as gauges. Static tags
+// are sent in the DogStatsD format, which plain StatsD servers ignore.
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+	tags   string
+}
+
+func NewStatsD(config StatsDConfig, tags map[string]string) (*StatsD, error) {
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsD{conn: conn, prefix: config.Prefix, tags: statsDTags(tags)}, nil
+}
+
+func (s *StatsD) IncrementCounter(name string) error {
+	return s.send(name, "1", "c")
+}
+
+func (s *StatsD) SendDuration(name string, value time.Duration) error {
+	ms := float64(value) / float64(time.Millisecond)
+	return s.send(name, strconv.FormatFloat(ms, 'f', -1, 64), "ms")
+}
+
+func (s *StatsD) SendMetric(name string, value int) error {
+	if value < 0 {
+		// a signed gauge is a change to the current value, so it is zeroed
+		// first to set it to a negative value
+		err := s.send(name, "0", "g")
+		if err != nil {
+			return err
+		}
+	}
+	return s.send(name, strconv.Itoa(value), "g")
+}
+
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsD) send(name, value, kind string) error {
+	_, err := s.conn.Write([]byte(s.prefix + name + ":" + value + "|" + kind + s.tags))
+	return err
+}
+
+func statsDTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(tags))
+	for _, name := range sortedTagNames(tags) {
+		pairs = append(pairs, name+":"+tags[name])
+	}
+	return "|#" + strings.Join(pairs, ",")
+}
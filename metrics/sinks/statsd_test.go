@@ -0,0 +1,69 @@
+package sinks_test
+
+import (
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/locket/metrics/sinks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StatsD", func() {
+	var (
+		server *net.UDPConn
+		statsD *sinks.StatsD
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).NotTo(HaveOccurred())
+
+		statsD, err = sinks.NewStatsD(sinks.StatsDConfig{Address: server.LocalAddr().String(), Prefix: "locket."}, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		statsD.Close()
+		server.Close()
+	})
+
+	receive := func() string {
+		buf := make([]byte, 512)
+		Expect(server.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+		n, err := server.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+		return string(buf[:n])
+	}
+
+	It("sends counters", func() {
+		Expect(statsD.IncrementCounter("LocksExpired")).To(Succeed())
+		Expect(receive()).To(Equal("locket.LocksExpired:1|c"))
+	})
+
+	It("sends durations as timings in milliseconds", func() {
+		Expect(statsD.SendDuration("LockHeartbeatLatency", 1500*time.Microsecond)).To(Succeed())
+		Expect(receive()).To(Equal("locket.LockHeartbeatLatency:1.5|ms"))
+	})
+
+	It("sends values as gauges", func() {
+		Expect(statsD.SendMetric("ActiveLocks", 3)).To(Succeed())
+		Expect(receive()).To(Equal("locket.ActiveLocks:3|g"))
+	})
+
+	It("zeroes gauges before setting them to negative values", func() {
+		Expect(statsD.SendMetric("DatabaseClockSkew", -2)).To(Succeed())
+		Expect(receive()).To(Equal("locket.DatabaseClockSkew:0|g"))
+		Expect(receive()).To(Equal("locket.DatabaseClockSkew:-2|g"))
+	})
+
+	It("sends the static tags in the DogStatsD format", func() {
+		tagged, err := sinks.NewStatsD(sinks.StatsDConfig{Address: server.LocalAddr().String()}, map[string]string{"instance_id": "locket-0", "az": "z1"})
+		Expect(err).NotTo(HaveOccurred())
+		defer tagged.Close()
+
+		Expect(tagged.IncrementCounter("LocksExpired")).To(Succeed())
+		Expect(receive()).To(Equal("LocksExpired:1|c|#az:z1,instance_id:locket-0"))
+	})
+})
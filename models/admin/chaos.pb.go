@@ -0,0 +1,1767 @@
+// Code generated by protoc-gen-gogo.
+// source: chaos.proto
+// DO NOT EDIT!
+
+/*
+	Package admin is a generated protocol buffer package.
+
+	It is generated from these files:
+		chaos.proto
+		sessions.proto
+		replication.proto
+
+	It has these top-level messages:
+		Faults
+		SetFaultsRequest
+		SetFaultsResponse
+		GetFaultsRequest
+		GetFaultsResponse
+		ExpireLockRequest
+		ExpireLockResponse
+		Session
+		ListSessionsRequest
+		ListSessionsResponse
+		DisconnectSessionRequest
+		DisconnectSessionResponse
+		ReplicatedTTL
+		WatchTTLsRequest
+		TTLSnapshot
+*/
+package admin
+
+import proto "github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import strings "strings"
+import reflect "reflect"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
+
+type Faults struct {
+	DelayProbability       float64 `protobuf:"fixed64,1,opt,name=delay_probability,json=delayProbability,proto3" json:"delay_probability,omitempty"`
+	MaxDelayInMilliseconds int64   `protobuf:"varint,2,opt,name=max_delay_in_milliseconds,json=maxDelayInMilliseconds,proto3" json:"max_delay_in_milliseconds,omitempty"`
+	DropProbability        float64 `protobuf:"fixed64,3,opt,name=drop_probability,json=dropProbability,proto3" json:"drop_probability,omitempty"`
+	DbErrorProbability     float64 `protobuf:"fixed64,4,opt,name=db_error_probability,json=dbErrorProbability,proto3" json:"db_error_probability,omitempty"`
+}
+
+func (m *Faults) Reset()                    { *m = Faults{} }
+func (*Faults) ProtoMessage()               {}
+func (*Faults) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{0} }
+
+func (m *Faults) GetDelayProbability() float64 {
+	if m != nil {
+		return m.DelayProbability
+	}
+	return 0
+}
+
+func (m *Faults) GetMaxDelayInMilliseconds() int64 {
+	if m != nil {
+		return m.MaxDelayInMilliseconds
+	}
+	return 0
+}
+
+func (m *Faults) GetDropProbability() float64 {
+	if m != nil {
+		return m.DropProbability
+	}
+	return 0
+}
+
+func (m *Faults) GetDbErrorProbability() float64 {
+	if m != nil {
+		return m.DbErrorProbability
+	}
+	return 0
+}
+
+type SetFaultsRequest struct {
+	Faults *Faults `protobuf:"bytes,1,opt,name=faults" json:"faults,omitempty"`
+}
+
+func (m *SetFaultsRequest) Reset()                    { *m = SetFaultsRequest{} }
+func (*SetFaultsRequest) ProtoMessage()               {}
+func (*SetFaultsRequest) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{1} }
+
+func (m *SetFaultsRequest) GetFaults() *Faults {
+	if m != nil {
+		return m.Faults
+	}
+	return nil
+}
+
+type SetFaultsResponse struct {
+	Previous *Faults `protobuf:"bytes,1,opt,name=previous" json:"previous,omitempty"`
+}
+
+func (m *SetFaultsResponse) Reset()                    { *m = SetFaultsResponse{} }
+func (*SetFaultsResponse) ProtoMessage()               {}
+func (*SetFaultsResponse) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{2} }
+
+func (m *SetFaultsResponse) GetPrevious() *Faults {
+	if m != nil {
+		return m.Previous
+	}
+	return nil
+}
+
+type GetFaultsRequest struct {
+}
+
+func (m *GetFaultsRequest) Reset()                    { *m = GetFaultsRequest{} }
+func (*GetFaultsRequest) ProtoMessage()               {}
+func (*GetFaultsRequest) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{3} }
+
+type GetFaultsResponse struct {
+	Faults *Faults `protobuf:"bytes,1,opt,name=faults" json:"faults,omitempty"`
+}
+
+func (m *GetFaultsResponse) Reset()                    { *m = GetFaultsResponse{} }
+func (*GetFaultsResponse) ProtoMessage()               {}
+func (*GetFaultsResponse) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{4} }
+
+func (m *GetFaultsResponse) GetFaults() *Faults {
+	if m != nil {
+		return m.Faults
+	}
+	return nil
+}
+
+type ExpireLockRequest struct {
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *ExpireLockRequest) Reset()                    { *m = ExpireLockRequest{} }
+func (*ExpireLockRequest) ProtoMessage()               {}
+func (*ExpireLockRequest) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{5} }
+
+func (m *ExpireLockRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+type ExpireLockResponse struct {
+	Owner        string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	FencingToken int64  `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+}
+
+func (m *ExpireLockResponse) Reset()                    { *m = ExpireLockResponse{} }
+func (*ExpireLockResponse) ProtoMessage()               {}
+func (*ExpireLockResponse) Descriptor() ([]byte, []int) { return fileDescriptorChaos, []int{6} }
+
+func (m *ExpireLockResponse) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ExpireLockResponse) GetFencingToken() int64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Faults)(nil), "locket.admin.Faults")
+	proto.RegisterType((*SetFaultsRequest)(nil), "locket.admin.SetFaultsRequest")
+	proto.RegisterType((*SetFaultsResponse)(nil), "locket.admin.SetFaultsResponse")
+	proto.RegisterType((*GetFaultsRequest)(nil), "locket.admin.GetFaultsRequest")
+	proto.RegisterType((*GetFaultsResponse)(nil), "locket.admin.GetFaultsResponse")
+	proto.RegisterType((*ExpireLockRequest)(nil), "locket.admin.ExpireLockRequest")
+	proto.RegisterType((*ExpireLockResponse)(nil), "locket.admin.ExpireLockResponse")
+}
+func (this *Faults) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Faults)
+	if !ok {
+		that2, ok := that.(Faults)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.DelayProbability != that1.DelayProbability {
+		return false
+	}
+	if this.MaxDelayInMilliseconds != that1.MaxDelayInMilliseconds {
+		return false
+	}
+	if this.DropProbability != that1.DropProbability {
+		return false
+	}
+	if this.DbErrorProbability != that1.DbErrorProbability {
+		return false
+	}
+	return true
+}
+func (this *SetFaultsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*SetFaultsRequest)
+	if !ok {
+		that2, ok := that.(SetFaultsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Faults.Equal(that1.Faults) {
+		return false
+	}
+	return true
+}
+func (this *SetFaultsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*SetFaultsResponse)
+	if !ok {
+		that2, ok := that.(SetFaultsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Previous.Equal(that1.Previous) {
+		return false
+	}
+	return true
+}
+func (this *GetFaultsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*GetFaultsRequest)
+	if !ok {
+		that2, ok := that.(GetFaultsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *GetFaultsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*GetFaultsResponse)
+	if !ok {
+		that2, ok := that.(GetFaultsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Faults.Equal(that1.Faults) {
+		return false
+	}
+	return true
+}
+func (this *ExpireLockRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ExpireLockRequest)
+	if !ok {
+		that2, ok := that.(ExpireLockRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	return true
+}
+func (this *ExpireLockResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ExpireLockResponse)
+	if !ok {
+		that2, ok := that.(ExpireLockResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Owner != that1.Owner {
+		return false
+	}
+	if this.FencingToken != that1.FencingToken {
+		return false
+	}
+	return true
+}
+func (this *Faults) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&admin.Faults{")
+	s = append(s, "DelayProbability: "+fmt.Sprintf("%#v", this.DelayProbability)+",\n")
+	s = append(s, "MaxDelayInMilliseconds: "+fmt.Sprintf("%#v", this.MaxDelayInMilliseconds)+",\n")
+	s = append(s, "DropProbability: "+fmt.Sprintf("%#v", this.DropProbability)+",\n")
+	s = append(s, "DbErrorProbability: "+fmt.Sprintf("%#v", this.DbErrorProbability)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *SetFaultsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.SetFaultsRequest{")
+	if this.Faults != nil {
+		s = append(s, "Faults: "+fmt.Sprintf("%#v", this.Faults)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *SetFaultsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.SetFaultsResponse{")
+	if this.Previous != nil {
+		s = append(s, "Previous: "+fmt.Sprintf("%#v", this.Previous)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *GetFaultsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&admin.GetFaultsRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *GetFaultsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.GetFaultsResponse{")
+	if this.Faults != nil {
+		s = append(s, "Faults: "+fmt.Sprintf("%#v", this.Faults)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ExpireLockRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.ExpireLockRequest{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ExpireLockResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&admin.ExpireLockResponse{")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "FencingToken: "+fmt.Sprintf("%#v", this.FencingToken)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringChaos(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Chaos service
+
+type ChaosClient interface {
+	SetFaults(ctx context.Context, in *SetFaultsRequest, opts ...grpc.CallOption) (*SetFaultsResponse, error)
+	GetFaults(ctx context.Context, in *GetFaultsRequest, opts ...grpc.CallOption) (*GetFaultsResponse, error)
+	ExpireLock(ctx context.Context, in *ExpireLockRequest, opts ...grpc.CallOption) (*ExpireLockResponse, error)
+}
+
+type chaosClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewChaosClient(cc *grpc.ClientConn) ChaosClient {
+	return &chaosClient{cc}
+}
+
+func (c *chaosClient) SetFaults(ctx context.Context, in *SetFaultsRequest, opts ...grpc.CallOption) (*SetFaultsResponse, error) {
+	out := new(SetFaultsResponse)
+	err := grpc.Invoke(ctx, "/locket.admin.Chaos/SetFaults", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chaosClient) GetFaults(ctx context.Context, in *GetFaultsRequest, opts ...grpc.CallOption) (*GetFaultsResponse, error) {
+	out := new(GetFaultsResponse)
+	err := grpc.Invoke(ctx, "/locket.admin.Chaos/GetFaults", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chaosClient) ExpireLock(ctx context.Context, in *ExpireLockRequest, opts ...grpc.CallOption) (*ExpireLockResponse, error) {
+	out := new(ExpireLockResponse)
+	err := grpc.Invoke(ctx, "/locket.admin.Chaos/ExpireLock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Chaos service
+
+type ChaosServer interface {
+	SetFaults(context.Context, *SetFaultsRequest) (*SetFaultsResponse, error)
+	GetFaults(context.Context, *GetFaultsRequest) (*GetFaultsResponse, error)
+	ExpireLock(context.Context, *ExpireLockRequest) (*ExpireLockResponse, error)
+}
+
+func RegisterChaosServer(s *grpc.Server, srv ChaosServer) {
+	s.RegisterService(&_Chaos_serviceDesc, srv)
+}
+
+func _Chaos_SetFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFaultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChaosServer).SetFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.admin.Chaos/SetFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChaosServer).SetFaults(ctx, req.(*SetFaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chaos_GetFaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFaultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChaosServer).GetFaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.admin.Chaos/GetFaults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChaosServer).GetFaults(ctx, req.(*GetFaultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chaos_ExpireLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpireLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChaosServer).ExpireLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.admin.Chaos/ExpireLock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChaosServer).ExpireLock(ctx, req.(*ExpireLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Chaos_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "locket.admin.Chaos",
+	HandlerType: (*ChaosServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetFaults",
+			Handler:    _Chaos_SetFaults_Handler,
+		},
+		{
+			MethodName: "GetFaults",
+			Handler:    _Chaos_GetFaults_Handler,
+		},
+		{
+			MethodName: "ExpireLock",
+			Handler:    _Chaos_ExpireLock_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "chaos.proto",
+}
+
+func (m *Faults) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Faults) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.DelayProbability != 0 {
+		dAtA[i] = 0x9
+		i++
+		i = encodeFixed64Chaos(dAtA, i, uint64(math.Float64bits(float64(m.DelayProbability))))
+	}
+	if m.MaxDelayInMilliseconds != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(m.MaxDelayInMilliseconds))
+	}
+	if m.DropProbability != 0 {
+		dAtA[i] = 0x19
+		i++
+		i = encodeFixed64Chaos(dAtA, i, uint64(math.Float64bits(float64(m.DropProbability))))
+	}
+	if m.DbErrorProbability != 0 {
+		dAtA[i] = 0x21
+		i++
+		i = encodeFixed64Chaos(dAtA, i, uint64(math.Float64bits(float64(m.DbErrorProbability))))
+	}
+	return i, nil
+}
+
+func (m *SetFaultsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SetFaultsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Faults != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(m.Faults.Size()))
+		n1, err := m.Faults.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	return i, nil
+}
+
+func (m *SetFaultsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SetFaultsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Previous != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(m.Previous.Size()))
+		n2, err := m.Previous.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	return i, nil
+}
+
+func (m *GetFaultsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetFaultsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *GetFaultsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetFaultsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Faults != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(m.Faults.Size()))
+		n3, err := m.Faults.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	return i, nil
+}
+
+func (m *ExpireLockRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExpireLockRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	return i, nil
+}
+
+func (m *ExpireLockResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExpireLockResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if m.FencingToken != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintChaos(dAtA, i, uint64(m.FencingToken))
+	}
+	return i, nil
+}
+
+func encodeFixed64Chaos(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Chaos(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintChaos(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *Faults) Size() (n int) {
+	var l int
+	_ = l
+	if m.DelayProbability != 0 {
+		n += 9
+	}
+	if m.MaxDelayInMilliseconds != 0 {
+		n += 1 + sovChaos(uint64(m.MaxDelayInMilliseconds))
+	}
+	if m.DropProbability != 0 {
+		n += 9
+	}
+	if m.DbErrorProbability != 0 {
+		n += 9
+	}
+	return n
+}
+
+func (m *SetFaultsRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Faults != nil {
+		l = m.Faults.Size()
+		n += 1 + l + sovChaos(uint64(l))
+	}
+	return n
+}
+
+func (m *SetFaultsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Previous != nil {
+		l = m.Previous.Size()
+		n += 1 + l + sovChaos(uint64(l))
+	}
+	return n
+}
+
+func (m *GetFaultsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *GetFaultsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Faults != nil {
+		l = m.Faults.Size()
+		n += 1 + l + sovChaos(uint64(l))
+	}
+	return n
+}
+
+func (m *ExpireLockRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovChaos(uint64(l))
+	}
+	return n
+}
+
+func (m *ExpireLockResponse) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovChaos(uint64(l))
+	}
+	if m.FencingToken != 0 {
+		n += 1 + sovChaos(uint64(m.FencingToken))
+	}
+	return n
+}
+
+func sovChaos(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozChaos(x uint64) (n int) {
+	return sovChaos(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Faults) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Faults{`,
+		`DelayProbability:` + fmt.Sprintf("%v", this.DelayProbability) + `,`,
+		`MaxDelayInMilliseconds:` + fmt.Sprintf("%v", this.MaxDelayInMilliseconds) + `,`,
+		`DropProbability:` + fmt.Sprintf("%v", this.DropProbability) + `,`,
+		`DbErrorProbability:` + fmt.Sprintf("%v", this.DbErrorProbability) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *SetFaultsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SetFaultsRequest{`,
+		`Faults:` + strings.Replace(fmt.Sprintf("%v", this.Faults), "Faults", "Faults", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *SetFaultsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&SetFaultsResponse{`,
+		`Previous:` + strings.Replace(fmt.Sprintf("%v", this.Previous), "Faults", "Faults", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GetFaultsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&GetFaultsRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GetFaultsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&GetFaultsResponse{`,
+		`Faults:` + strings.Replace(fmt.Sprintf("%v", this.Faults), "Faults", "Faults", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ExpireLockRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ExpireLockRequest{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ExpireLockResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ExpireLockResponse{`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`FencingToken:` + fmt.Sprintf("%v", this.FencingToken) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringChaos(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Faults) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Faults: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Faults: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DelayProbability", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += 8
+			v = uint64(dAtA[iNdEx-8])
+			v |= uint64(dAtA[iNdEx-7]) << 8
+			v |= uint64(dAtA[iNdEx-6]) << 16
+			v |= uint64(dAtA[iNdEx-5]) << 24
+			v |= uint64(dAtA[iNdEx-4]) << 32
+			v |= uint64(dAtA[iNdEx-3]) << 40
+			v |= uint64(dAtA[iNdEx-2]) << 48
+			v |= uint64(dAtA[iNdEx-1]) << 56
+			m.DelayProbability = float64(math.Float64frombits(v))
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxDelayInMilliseconds", wireType)
+			}
+			m.MaxDelayInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxDelayInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DropProbability", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += 8
+			v = uint64(dAtA[iNdEx-8])
+			v |= uint64(dAtA[iNdEx-7]) << 8
+			v |= uint64(dAtA[iNdEx-6]) << 16
+			v |= uint64(dAtA[iNdEx-5]) << 24
+			v |= uint64(dAtA[iNdEx-4]) << 32
+			v |= uint64(dAtA[iNdEx-3]) << 40
+			v |= uint64(dAtA[iNdEx-2]) << 48
+			v |= uint64(dAtA[iNdEx-1]) << 56
+			m.DropProbability = float64(math.Float64frombits(v))
+		case 4:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DbErrorProbability", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += 8
+			v = uint64(dAtA[iNdEx-8])
+			v |= uint64(dAtA[iNdEx-7]) << 8
+			v |= uint64(dAtA[iNdEx-6]) << 16
+			v |= uint64(dAtA[iNdEx-5]) << 24
+			v |= uint64(dAtA[iNdEx-4]) << 32
+			v |= uint64(dAtA[iNdEx-3]) << 40
+			v |= uint64(dAtA[iNdEx-2]) << 48
+			v |= uint64(dAtA[iNdEx-1]) << 56
+			m.DbErrorProbability = float64(math.Float64frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SetFaultsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SetFaultsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SetFaultsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Faults", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChaos
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Faults == nil {
+				m.Faults = &Faults{}
+			}
+			if err := m.Faults.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SetFaultsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SetFaultsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SetFaultsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Previous", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChaos
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Previous == nil {
+				m.Previous = &Faults{}
+			}
+			if err := m.Previous.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetFaultsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetFaultsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetFaultsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetFaultsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetFaultsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetFaultsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Faults", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChaos
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Faults == nil {
+				m.Faults = &Faults{}
+			}
+			if err := m.Faults.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExpireLockRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExpireLockRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExpireLockRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChaos
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExpireLockResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExpireLockResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExpireLockResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChaos
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FencingToken", wireType)
+			}
+			m.FencingToken = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FencingToken |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChaos(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthChaos
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipChaos(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowChaos
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowChaos
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthChaos
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowChaos
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipChaos(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthChaos = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowChaos   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("chaos.proto", fileDescriptorChaos) }
+
+var fileDescriptorChaos = []byte{
+	// 434 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0x4b, 0xcf, 0xd2, 0x40,
+	0x14, 0xed, 0x88, 0x1f, 0xf1, 0xbb, 0x1f, 0xc6, 0x76, 0x42, 0x0c, 0xb2, 0x18, 0x49, 0x8d, 0x09,
+	0x46, 0xd2, 0x10, 0x5c, 0xb9, 0xf3, 0x85, 0x8d, 0x89, 0xcf, 0xea, 0xbe, 0xe9, 0x63, 0xd0, 0x49,
+	0xdb, 0x99, 0x3a, 0x2d, 0x0a, 0x3b, 0x7f, 0x82, 0x3f, 0xc3, 0x9f, 0xe2, 0xc6, 0x84, 0xa5, 0x4b,
+	0xa9, 0x1b, 0x97, 0xac, 0x5d, 0x99, 0x3e, 0x80, 0x02, 0xa2, 0xf9, 0x76, 0xed, 0x39, 0xe7, 0x9e,
+	0x7b, 0xef, 0xb9, 0x2d, 0x9c, 0x79, 0xef, 0x1c, 0x91, 0x18, 0xb1, 0x14, 0xa9, 0xc0, 0xad, 0x50,
+	0x78, 0x01, 0x4d, 0x0d, 0xc7, 0x8f, 0x18, 0xd7, 0xbf, 0x21, 0x68, 0x3e, 0x76, 0xa6, 0x61, 0x9a,
+	0xe0, 0xdb, 0xa0, 0xf9, 0x34, 0x74, 0xe6, 0x76, 0x2c, 0x85, 0xeb, 0xb8, 0x2c, 0x64, 0xe9, 0xbc,
+	0x83, 0x7a, 0xa8, 0x8f, 0x2c, 0xb5, 0x20, 0x5e, 0x6e, 0x71, 0x7c, 0x17, 0xae, 0x45, 0xce, 0xcc,
+	0x2e, 0x0b, 0x18, 0xb7, 0x23, 0x16, 0x86, 0x2c, 0xa1, 0x9e, 0xe0, 0x7e, 0xd2, 0xb9, 0xd0, 0x43,
+	0xfd, 0x86, 0x75, 0x35, 0x72, 0x66, 0x8f, 0x72, 0xfe, 0x09, 0x7f, 0x56, 0x63, 0xf1, 0x2d, 0x50,
+	0x7d, 0x29, 0xe2, 0x9d, 0x36, 0x8d, 0xa2, 0xcd, 0x95, 0x1c, 0xaf, 0x77, 0x19, 0x42, 0xdb, 0x77,
+	0x6d, 0x2a, 0xa5, 0x90, 0x3b, 0xf2, 0x8b, 0x85, 0x1c, 0xfb, 0xee, 0x38, 0xa7, 0x6a, 0x15, 0xfa,
+	0x3d, 0x50, 0x5f, 0xd3, 0xb4, 0xdc, 0xc8, 0xa2, 0xef, 0xa7, 0x34, 0x49, 0xf1, 0x00, 0x9a, 0x93,
+	0x02, 0x28, 0xb6, 0x39, 0x1b, 0xb5, 0x8d, 0x7a, 0x04, 0x46, 0x25, 0xae, 0x34, 0xfa, 0x18, 0xb4,
+	0x9a, 0x43, 0x12, 0x0b, 0x9e, 0x50, 0x3c, 0x84, 0x4b, 0xb1, 0xa4, 0x1f, 0x98, 0x98, 0xfe, 0xdb,
+	0x64, 0xa3, 0xd2, 0x31, 0xa8, 0xe6, 0xde, 0x20, 0xfa, 0x7d, 0xd0, 0xcc, 0x03, 0xeb, 0xf3, 0x4d,
+	0x77, 0x13, 0xb4, 0xf1, 0x2c, 0x66, 0x92, 0x3e, 0x15, 0x5e, 0xb0, 0x5e, 0x50, 0x85, 0x46, 0x40,
+	0xcb, 0x5b, 0x9d, 0x5a, 0xf9, 0xa3, 0xfe, 0x02, 0x70, 0x5d, 0x56, 0xb5, 0x6a, 0xc3, 0x89, 0xf8,
+	0xc8, 0xa9, 0xac, 0x94, 0xe5, 0x0b, 0xbe, 0x01, 0x97, 0x27, 0x94, 0x7b, 0x8c, 0xbf, 0xb5, 0x53,
+	0x11, 0x50, 0x5e, 0x9d, 0xaf, 0x55, 0x81, 0x6f, 0x72, 0x6c, 0xf4, 0x1b, 0xc1, 0xc9, 0xc3, 0xfc,
+	0x2b, 0xc2, 0xcf, 0xe1, 0x74, 0x93, 0x0f, 0x26, 0xbb, 0xc3, 0xee, 0x47, 0xdf, 0xbd, 0x7e, 0x94,
+	0x2f, 0x47, 0xd2, 0x95, 0xdc, 0xcf, 0x3c, 0xe6, 0x67, 0xfe, 0xc7, 0xcf, 0xfc, 0x8b, 0xdf, 0x2b,
+	0x80, 0xed, 0xea, 0x78, 0xaf, 0xe0, 0x20, 0xbb, 0x6e, 0xef, 0xb8, 0x60, 0x6d, 0xf9, 0x60, 0xb0,
+	0x58, 0x12, 0xe5, 0xfb, 0x92, 0x28, 0xab, 0x25, 0x41, 0x9f, 0x32, 0x82, 0xbe, 0x64, 0x04, 0x7d,
+	0xcd, 0x08, 0x5a, 0x64, 0x04, 0xfd, 0xc8, 0x08, 0xfa, 0x95, 0x11, 0x65, 0x95, 0x11, 0xf4, 0xf9,
+	0x27, 0x51, 0xdc, 0x66, 0xf1, 0x9f, 0xdd, 0xf9, 0x33, 0x00, 0x21, 0x4b, 0xe4, 0xd2, 0x76, 0x03,
+	0x00, 0x00,
+}
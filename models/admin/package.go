@@ -0,0 +1,3 @@
+package admin // import "code.cloudfoundry.org/locket/models/admin"
+
+//go:generate bash ../../scripts/generate_protos.sh
@@ -0,0 +1,1134 @@
+// Code generated by protoc-gen-gogo.
+// source: replication.proto
+// DO NOT EDIT!
+
+package admin
+
+import proto "github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import strings "strings"
+import reflect "reflect"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type ReplicatedTTL struct {
+	Key                     string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Owner                   string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Type                    string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	ModifiedId              string `protobuf:"bytes,4,opt,name=modified_id,json=modifiedId,proto3" json:"modified_id,omitempty"`
+	ModifiedIndex           int64  `protobuf:"varint,5,opt,name=modified_index,json=modifiedIndex,proto3" json:"modified_index,omitempty"`
+	TtlInSeconds            int64  `protobuf:"varint,6,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	TtlInMilliseconds       int64  `protobuf:"varint,7,opt,name=ttl_in_milliseconds,json=ttlInMilliseconds,proto3" json:"ttl_in_milliseconds,omitempty"`
+	RemainingInMilliseconds int64  `protobuf:"varint,8,opt,name=remaining_in_milliseconds,json=remainingInMilliseconds,proto3" json:"remaining_in_milliseconds,omitempty"`
+}
+
+func (m *ReplicatedTTL) Reset()                    { *m = ReplicatedTTL{} }
+func (*ReplicatedTTL) ProtoMessage()               {}
+func (*ReplicatedTTL) Descriptor() ([]byte, []int) { return fileDescriptorReplication, []int{0} }
+
+func (m *ReplicatedTTL) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *ReplicatedTTL) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ReplicatedTTL) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ReplicatedTTL) GetModifiedId() string {
+	if m != nil {
+		return m.ModifiedId
+	}
+	return ""
+}
+
+func (m *ReplicatedTTL) GetModifiedIndex() int64 {
+	if m != nil {
+		return m.ModifiedIndex
+	}
+	return 0
+}
+
+func (m *ReplicatedTTL) GetTtlInSeconds() int64 {
+	if m != nil {
+		return m.TtlInSeconds
+	}
+	return 0
+}
+
+func (m *ReplicatedTTL) GetTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.TtlInMilliseconds
+	}
+	return 0
+}
+
+func (m *ReplicatedTTL) GetRemainingInMilliseconds() int64 {
+	if m != nil {
+		return m.RemainingInMilliseconds
+	}
+	return 0
+}
+
+type WatchTTLsRequest struct {
+}
+
+func (m *WatchTTLsRequest) Reset()                    { *m = WatchTTLsRequest{} }
+func (*WatchTTLsRequest) ProtoMessage()               {}
+func (*WatchTTLsRequest) Descriptor() ([]byte, []int) { return fileDescriptorReplication, []int{1} }
+
+type TTLSnapshot struct {
+	Ttls []*ReplicatedTTL `protobuf:"bytes,1,rep,name=ttls" json:"ttls,omitempty"`
+}
+
+func (m *TTLSnapshot) Reset()                    { *m = TTLSnapshot{} }
+func (*TTLSnapshot) ProtoMessage()               {}
+func (*TTLSnapshot) Descriptor() ([]byte, []int) { return fileDescriptorReplication, []int{2} }
+
+func (m *TTLSnapshot) GetTtls() []*ReplicatedTTL {
+	if m != nil {
+		return m.Ttls
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ReplicatedTTL)(nil), "locket.admin.ReplicatedTTL")
+	proto.RegisterType((*WatchTTLsRequest)(nil), "locket.admin.WatchTTLsRequest")
+	proto.RegisterType((*TTLSnapshot)(nil), "locket.admin.TTLSnapshot")
+}
+func (this *ReplicatedTTL) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ReplicatedTTL)
+	if !ok {
+		that2, ok := that.(ReplicatedTTL)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if this.Owner != that1.Owner {
+		return false
+	}
+	if this.Type != that1.Type {
+		return false
+	}
+	if this.ModifiedId != that1.ModifiedId {
+		return false
+	}
+	if this.ModifiedIndex != that1.ModifiedIndex {
+		return false
+	}
+	if this.TtlInSeconds != that1.TtlInSeconds {
+		return false
+	}
+	if this.TtlInMilliseconds != that1.TtlInMilliseconds {
+		return false
+	}
+	if this.RemainingInMilliseconds != that1.RemainingInMilliseconds {
+		return false
+	}
+	return true
+}
+func (this *WatchTTLsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*WatchTTLsRequest)
+	if !ok {
+		that2, ok := that.(WatchTTLsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *TTLSnapshot) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*TTLSnapshot)
+	if !ok {
+		that2, ok := that.(TTLSnapshot)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.Ttls) != len(that1.Ttls) {
+		return false
+	}
+	for i := range this.Ttls {
+		if !this.Ttls[i].Equal(that1.Ttls[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReplicatedTTL) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&admin.ReplicatedTTL{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
+	s = append(s, "ModifiedId: "+fmt.Sprintf("%#v", this.ModifiedId)+",\n")
+	s = append(s, "ModifiedIndex: "+fmt.Sprintf("%#v", this.ModifiedIndex)+",\n")
+	s = append(s, "TtlInSeconds: "+fmt.Sprintf("%#v", this.TtlInSeconds)+",\n")
+	s = append(s, "TtlInMilliseconds: "+fmt.Sprintf("%#v", this.TtlInMilliseconds)+",\n")
+	s = append(s, "RemainingInMilliseconds: "+fmt.Sprintf("%#v", this.RemainingInMilliseconds)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *WatchTTLsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&admin.WatchTTLsRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TTLSnapshot) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.TTLSnapshot{")
+	if this.Ttls != nil {
+		s = append(s, "Ttls: "+fmt.Sprintf("%#v", this.Ttls)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringReplication(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Replication service
+
+type ReplicationClient interface {
+	WatchTTLs(ctx context.Context, in *WatchTTLsRequest, opts ...grpc.CallOption) (Replication_WatchTTLsClient, error)
+}
+
+type replicationClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewReplicationClient(cc *grpc.ClientConn) ReplicationClient {
+	return &replicationClient{cc}
+}
+
+func (c *replicationClient) WatchTTLs(ctx context.Context, in *WatchTTLsRequest, opts ...grpc.CallOption) (Replication_WatchTTLsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Replication_serviceDesc.Streams[0], c.cc, "/locket.admin.Replication/WatchTTLs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &replicationWatchTTLsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Replication_WatchTTLsClient interface {
+	Recv() (*TTLSnapshot, error)
+	grpc.ClientStream
+}
+
+type replicationWatchTTLsClient struct {
+	grpc.ClientStream
+}
+
+func (x *replicationWatchTTLsClient) Recv() (*TTLSnapshot, error) {
+	m := new(TTLSnapshot)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Replication service
+
+type ReplicationServer interface {
+	WatchTTLs(*WatchTTLsRequest, Replication_WatchTTLsServer) error
+}
+
+func RegisterReplicationServer(s *grpc.Server, srv ReplicationServer) {
+	s.RegisterService(&_Replication_serviceDesc, srv)
+}
+
+func _Replication_WatchTTLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTTLsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReplicationServer).WatchTTLs(m, &replicationWatchTTLsServer{stream})
+}
+
+type Replication_WatchTTLsServer interface {
+	Send(*TTLSnapshot) error
+	grpc.ServerStream
+}
+
+type replicationWatchTTLsServer struct {
+	grpc.ServerStream
+}
+
+func (x *replicationWatchTTLsServer) Send(m *TTLSnapshot) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Replication_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "locket.admin.Replication",
+	HandlerType: (*ReplicationServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTTLs",
+			Handler:       _Replication_WatchTTLs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "replication.proto",
+}
+
+func (m *ReplicatedTTL) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReplicatedTTL) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if len(m.Type) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(len(m.Type)))
+		i += copy(dAtA[i:], m.Type)
+	}
+	if len(m.ModifiedId) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(len(m.ModifiedId)))
+		i += copy(dAtA[i:], m.ModifiedId)
+	}
+	if m.ModifiedIndex != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(m.ModifiedIndex))
+	}
+	if m.TtlInSeconds != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(m.TtlInSeconds))
+	}
+	if m.TtlInMilliseconds != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(m.TtlInMilliseconds))
+	}
+	if m.RemainingInMilliseconds != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintReplication(dAtA, i, uint64(m.RemainingInMilliseconds))
+	}
+	return i, nil
+}
+
+func (m *WatchTTLsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WatchTTLsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *TTLSnapshot) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TTLSnapshot) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Ttls) > 0 {
+		for _, msg := range m.Ttls {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintReplication(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func encodeFixed64Replication(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Replication(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintReplication(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *ReplicatedTTL) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovReplication(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovReplication(uint64(l))
+	}
+	l = len(m.Type)
+	if l > 0 {
+		n += 1 + l + sovReplication(uint64(l))
+	}
+	l = len(m.ModifiedId)
+	if l > 0 {
+		n += 1 + l + sovReplication(uint64(l))
+	}
+	if m.ModifiedIndex != 0 {
+		n += 1 + sovReplication(uint64(m.ModifiedIndex))
+	}
+	if m.TtlInSeconds != 0 {
+		n += 1 + sovReplication(uint64(m.TtlInSeconds))
+	}
+	if m.TtlInMilliseconds != 0 {
+		n += 1 + sovReplication(uint64(m.TtlInMilliseconds))
+	}
+	if m.RemainingInMilliseconds != 0 {
+		n += 1 + sovReplication(uint64(m.RemainingInMilliseconds))
+	}
+	return n
+}
+
+func (m *WatchTTLsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *TTLSnapshot) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Ttls) > 0 {
+		for _, e := range m.Ttls {
+			l = e.Size()
+			n += 1 + l + sovReplication(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovReplication(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozReplication(x uint64) (n int) {
+	return sovReplication(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *ReplicatedTTL) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReplicatedTTL{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
+		`ModifiedId:` + fmt.Sprintf("%v", this.ModifiedId) + `,`,
+		`ModifiedIndex:` + fmt.Sprintf("%v", this.ModifiedIndex) + `,`,
+		`TtlInSeconds:` + fmt.Sprintf("%v", this.TtlInSeconds) + `,`,
+		`TtlInMilliseconds:` + fmt.Sprintf("%v", this.TtlInMilliseconds) + `,`,
+		`RemainingInMilliseconds:` + fmt.Sprintf("%v", this.RemainingInMilliseconds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *WatchTTLsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&WatchTTLsRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TTLSnapshot) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TTLSnapshot{`,
+		`Ttls:` + strings.Replace(fmt.Sprintf("%v", this.Ttls), "ReplicatedTTL", "ReplicatedTTL", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringReplication(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *ReplicatedTTL) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowReplication
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReplicatedTTL: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReplicatedTTL: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReplication
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReplication
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReplication
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Type = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ModifiedId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthReplication
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ModifiedId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ModifiedIndex", wireType)
+			}
+			m.ModifiedIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ModifiedIndex |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInSeconds", wireType)
+			}
+			m.TtlInSeconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInSeconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInMilliseconds", wireType)
+			}
+			m.TtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RemainingInMilliseconds", wireType)
+			}
+			m.RemainingInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RemainingInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipReplication(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthReplication
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WatchTTLsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowReplication
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WatchTTLsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WatchTTLsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipReplication(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthReplication
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TTLSnapshot) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowReplication
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TTLSnapshot: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TTLSnapshot: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttls", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthReplication
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Ttls = append(m.Ttls, &ReplicatedTTL{})
+			if err := m.Ttls[len(m.Ttls)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipReplication(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthReplication
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipReplication(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowReplication
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowReplication
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthReplication
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowReplication
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipReplication(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthReplication = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowReplication   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("replication.proto", fileDescriptorReplication) }
+
+var fileDescriptorReplication = []byte{
+	// 355 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x65, 0x92, 0xbd, 0x4e, 0xc3, 0x30,
+	0x14, 0x85, 0x1b, 0xd2, 0x16, 0x7a, 0xd3, 0x56, 0xad, 0x41, 0x22, 0x05, 0x29, 0xa0, 0x08, 0xa4,
+	0x0e, 0x28, 0xa0, 0xb2, 0x31, 0x30, 0xb0, 0x15, 0xc1, 0x92, 0x56, 0x42, 0x4c, 0x55, 0x88, 0x0d,
+	0xb5, 0x9a, 0xd8, 0x21, 0x31, 0x82, 0x6e, 0x3c, 0x02, 0x2f, 0x81, 0xc4, 0xa3, 0x30, 0x76, 0x64,
+	0xa4, 0x65, 0x61, 0xe4, 0x11, 0x70, 0xdd, 0xdf, 0xc0, 0x70, 0x64, 0xfb, 0xdc, 0xef, 0xea, 0x5a,
+	0xc7, 0x86, 0x6a, 0x4c, 0xa2, 0x80, 0xfa, 0x9e, 0xa0, 0x9c, 0x39, 0x51, 0xcc, 0x05, 0x47, 0xc5,
+	0x80, 0xfb, 0x3d, 0x22, 0x1c, 0x0f, 0x87, 0x94, 0xd9, 0xaf, 0x2b, 0x50, 0x72, 0xa7, 0x0c, 0xc1,
+	0xed, 0xf6, 0x05, 0xaa, 0x80, 0xde, 0x23, 0x7d, 0x53, 0xdb, 0xd5, 0xea, 0x05, 0x77, 0xbc, 0x45,
+	0x1b, 0x90, 0xe3, 0x8f, 0x8c, 0xc4, 0xe6, 0x8a, 0xf2, 0x26, 0x07, 0x84, 0x20, 0x2b, 0xfa, 0x11,
+	0x31, 0x75, 0x65, 0xaa, 0x3d, 0xda, 0x01, 0x23, 0xe4, 0x98, 0xde, 0x52, 0x82, 0x3b, 0x14, 0x9b,
+	0x59, 0x55, 0x82, 0x99, 0xd5, 0xc4, 0x68, 0x1f, 0xca, 0x0b, 0x80, 0x61, 0xf2, 0x64, 0xe6, 0x24,
+	0xa3, 0xbb, 0xa5, 0x39, 0x33, 0x36, 0xd1, 0x1e, 0x94, 0x85, 0x08, 0x24, 0xd1, 0x49, 0x88, 0xcf,
+	0x19, 0x4e, 0xcc, 0xbc, 0xc2, 0x8a, 0xd2, 0x6d, 0xb2, 0xd6, 0xc4, 0x43, 0x0e, 0xac, 0x4f, 0xa9,
+	0x90, 0x06, 0x01, 0x9d, 0xa1, 0xab, 0x0a, 0xad, 0x2a, 0xf4, 0x72, 0xa9, 0x80, 0x4e, 0xa0, 0x16,
+	0x93, 0xd0, 0xa3, 0x8c, 0xb2, 0xbb, 0x7f, 0x5d, 0x6b, 0xaa, 0x6b, 0x73, 0x0e, 0xa4, 0x7b, 0x6d,
+	0x04, 0x95, 0x2b, 0x4f, 0xf8, 0x5d, 0x99, 0x50, 0xe2, 0x92, 0xfb, 0x07, 0x92, 0x08, 0xfb, 0x14,
+	0x0c, 0x79, 0x6c, 0x31, 0x2f, 0x4a, 0xba, 0x5c, 0xa0, 0x43, 0x19, 0x88, 0x08, 0x12, 0x99, 0x9c,
+	0x5e, 0x37, 0x1a, 0xdb, 0xce, 0x72, 0xce, 0x4e, 0x2a, 0x63, 0x57, 0x81, 0x8d, 0x6b, 0x30, 0xdc,
+	0xc5, 0xf3, 0xa0, 0x73, 0x28, 0xcc, 0x47, 0x20, 0x2b, 0xdd, 0xfe, 0x77, 0xf6, 0x56, 0x2d, 0x5d,
+	0x5f, 0xba, 0x87, 0x9d, 0x39, 0xd2, 0xce, 0x0e, 0x06, 0x43, 0x2b, 0xf3, 0x21, 0xf5, 0x33, 0xb4,
+	0xb4, 0xe7, 0x91, 0xa5, 0xbd, 0x49, 0xbd, 0x4b, 0x0d, 0xa4, 0x3e, 0xa5, 0xbe, 0x47, 0xb2, 0x26,
+	0xd7, 0x97, 0x2f, 0x2b, 0x73, 0x93, 0x57, 0x3f, 0xe3, 0xf8, 0x17, 0xc4, 0xd8, 0xbe, 0x0b, 0x2e,
+	0x02, 0x00, 0x00,
+}
@@ -0,0 +1,1453 @@
+// Code generated by protoc-gen-gogo.
+// source: sessions.proto
+// DO NOT EDIT!
+
+package admin
+
+import proto "github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import strings "strings"
+import reflect "reflect"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type Session struct {
+	Address         string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Identity        string   `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	ConnectedAt     int64    `protobuf:"varint,3,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"`
+	LastHeartbeatAt int64    `protobuf:"varint,4,opt,name=last_heartbeat_at,json=lastHeartbeatAt,proto3" json:"last_heartbeat_at,omitempty"`
+	Keys            []string `protobuf:"bytes,5,rep,name=keys" json:"keys,omitempty"`
+}
+
+func (m *Session) Reset()                    { *m = Session{} }
+func (*Session) ProtoMessage()               {}
+func (*Session) Descriptor() ([]byte, []int) { return fileDescriptorSessions, []int{0} }
+
+func (m *Session) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Session) GetIdentity() string {
+	if m != nil {
+		return m.Identity
+	}
+	return ""
+}
+
+func (m *Session) GetConnectedAt() int64 {
+	if m != nil {
+		return m.ConnectedAt
+	}
+	return 0
+}
+
+func (m *Session) GetLastHeartbeatAt() int64 {
+	if m != nil {
+		return m.LastHeartbeatAt
+	}
+	return 0
+}
+
+func (m *Session) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type ListSessionsRequest struct {
+}
+
+func (m *ListSessionsRequest) Reset()                    { *m = ListSessionsRequest{} }
+func (*ListSessionsRequest) ProtoMessage()               {}
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) { return fileDescriptorSessions, []int{1} }
+
+type ListSessionsResponse struct {
+	Sessions []*Session `protobuf:"bytes,1,rep,name=sessions" json:"sessions,omitempty"`
+}
+
+func (m *ListSessionsResponse) Reset()                    { *m = ListSessionsResponse{} }
+func (*ListSessionsResponse) ProtoMessage()               {}
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) { return fileDescriptorSessions, []int{2} }
+
+func (m *ListSessionsResponse) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type DisconnectSessionRequest struct {
+	Address     string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	ExpireLocks bool   `protobuf:"varint,2,opt,name=expire_locks,json=expireLocks,proto3" json:"expire_locks,omitempty"`
+}
+
+func (m *DisconnectSessionRequest) Reset()      { *m = DisconnectSessionRequest{} }
+func (*DisconnectSessionRequest) ProtoMessage() {}
+func (*DisconnectSessionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptorSessions, []int{3}
+}
+
+func (m *DisconnectSessionRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *DisconnectSessionRequest) GetExpireLocks() bool {
+	if m != nil {
+		return m.ExpireLocks
+	}
+	return false
+}
+
+type DisconnectSessionResponse struct {
+	ExpiredKeys []string `protobuf:"bytes,1,rep,name=expired_keys,json=expiredKeys" json:"expired_keys,omitempty"`
+}
+
+func (m *DisconnectSessionResponse) Reset()      { *m = DisconnectSessionResponse{} }
+func (*DisconnectSessionResponse) ProtoMessage() {}
+func (*DisconnectSessionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptorSessions, []int{4}
+}
+
+func (m *DisconnectSessionResponse) GetExpiredKeys() []string {
+	if m != nil {
+		return m.ExpiredKeys
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Session)(nil), "locket.admin.Session")
+	proto.RegisterType((*ListSessionsRequest)(nil), "locket.admin.ListSessionsRequest")
+	proto.RegisterType((*ListSessionsResponse)(nil), "locket.admin.ListSessionsResponse")
+	proto.RegisterType((*DisconnectSessionRequest)(nil), "locket.admin.DisconnectSessionRequest")
+	proto.RegisterType((*DisconnectSessionResponse)(nil), "locket.admin.DisconnectSessionResponse")
+}
+func (this *Session) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Session)
+	if !ok {
+		that2, ok := that.(Session)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Address != that1.Address {
+		return false
+	}
+	if this.Identity != that1.Identity {
+		return false
+	}
+	if this.ConnectedAt != that1.ConnectedAt {
+		return false
+	}
+	if this.LastHeartbeatAt != that1.LastHeartbeatAt {
+		return false
+	}
+	if len(this.Keys) != len(that1.Keys) {
+		return false
+	}
+	for i := range this.Keys {
+		if this.Keys[i] != that1.Keys[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *ListSessionsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ListSessionsRequest)
+	if !ok {
+		that2, ok := that.(ListSessionsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *ListSessionsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ListSessionsResponse)
+	if !ok {
+		that2, ok := that.(ListSessionsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.Sessions) != len(that1.Sessions) {
+		return false
+	}
+	for i := range this.Sessions {
+		if !this.Sessions[i].Equal(that1.Sessions[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *DisconnectSessionRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*DisconnectSessionRequest)
+	if !ok {
+		that2, ok := that.(DisconnectSessionRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Address != that1.Address {
+		return false
+	}
+	if this.ExpireLocks != that1.ExpireLocks {
+		return false
+	}
+	return true
+}
+func (this *DisconnectSessionResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*DisconnectSessionResponse)
+	if !ok {
+		that2, ok := that.(DisconnectSessionResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.ExpiredKeys) != len(that1.ExpiredKeys) {
+		return false
+	}
+	for i := range this.ExpiredKeys {
+		if this.ExpiredKeys[i] != that1.ExpiredKeys[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *Session) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&admin.Session{")
+	s = append(s, "Address: "+fmt.Sprintf("%#v", this.Address)+",\n")
+	s = append(s, "Identity: "+fmt.Sprintf("%#v", this.Identity)+",\n")
+	s = append(s, "ConnectedAt: "+fmt.Sprintf("%#v", this.ConnectedAt)+",\n")
+	s = append(s, "LastHeartbeatAt: "+fmt.Sprintf("%#v", this.LastHeartbeatAt)+",\n")
+	if this.Keys != nil {
+		s = append(s, "Keys: "+fmt.Sprintf("%#v", this.Keys)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ListSessionsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&admin.ListSessionsRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ListSessionsResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.ListSessionsResponse{")
+	if this.Sessions != nil {
+		s = append(s, "Sessions: "+fmt.Sprintf("%#v", this.Sessions)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DisconnectSessionRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&admin.DisconnectSessionRequest{")
+	s = append(s, "Address: "+fmt.Sprintf("%#v", this.Address)+",\n")
+	s = append(s, "ExpireLocks: "+fmt.Sprintf("%#v", this.ExpireLocks)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DisconnectSessionResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&admin.DisconnectSessionResponse{")
+	if this.ExpiredKeys != nil {
+		s = append(s, "ExpiredKeys: "+fmt.Sprintf("%#v", this.ExpiredKeys)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringSessions(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Sessions service
+
+type SessionsClient interface {
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	DisconnectSession(ctx context.Context, in *DisconnectSessionRequest, opts ...grpc.CallOption) (*DisconnectSessionResponse, error)
+}
+
+type sessionsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSessionsClient(cc *grpc.ClientConn) SessionsClient {
+	return &sessionsClient{cc}
+}
+
+func (c *sessionsClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := grpc.Invoke(ctx, "/locket.admin.Sessions/ListSessions", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionsClient) DisconnectSession(ctx context.Context, in *DisconnectSessionRequest, opts ...grpc.CallOption) (*DisconnectSessionResponse, error) {
+	out := new(DisconnectSessionResponse)
+	err := grpc.Invoke(ctx, "/locket.admin.Sessions/DisconnectSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Sessions service
+
+type SessionsServer interface {
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	DisconnectSession(context.Context, *DisconnectSessionRequest) (*DisconnectSessionResponse, error)
+}
+
+func RegisterSessionsServer(s *grpc.Server, srv SessionsServer) {
+	s.RegisterService(&_Sessions_serviceDesc, srv)
+}
+
+func _Sessions_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionsServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.admin.Sessions/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionsServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sessions_DisconnectSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionsServer).DisconnectSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.admin.Sessions/DisconnectSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionsServer).DisconnectSession(ctx, req.(*DisconnectSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Sessions_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "locket.admin.Sessions",
+	HandlerType: (*SessionsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSessions",
+			Handler:    _Sessions_ListSessions_Handler,
+		},
+		{
+			MethodName: "DisconnectSession",
+			Handler:    _Sessions_DisconnectSession_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "sessions.proto",
+}
+
+func (m *Session) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Session) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Address) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintSessions(dAtA, i, uint64(len(m.Address)))
+		i += copy(dAtA[i:], m.Address)
+	}
+	if len(m.Identity) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintSessions(dAtA, i, uint64(len(m.Identity)))
+		i += copy(dAtA[i:], m.Identity)
+	}
+	if m.ConnectedAt != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintSessions(dAtA, i, uint64(m.ConnectedAt))
+	}
+	if m.LastHeartbeatAt != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintSessions(dAtA, i, uint64(m.LastHeartbeatAt))
+	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			dAtA[i] = 0x2a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *ListSessionsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListSessionsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *ListSessionsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListSessionsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Sessions) > 0 {
+		for _, msg := range m.Sessions {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintSessions(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *DisconnectSessionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DisconnectSessionRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Address) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintSessions(dAtA, i, uint64(len(m.Address)))
+		i += copy(dAtA[i:], m.Address)
+	}
+	if m.ExpireLocks {
+		dAtA[i] = 0x10
+		i++
+		if m.ExpireLocks {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *DisconnectSessionResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DisconnectSessionResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ExpiredKeys) > 0 {
+		for _, s := range m.ExpiredKeys {
+			dAtA[i] = 0xa
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func encodeFixed64Sessions(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Sessions(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintSessions(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *Session) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovSessions(uint64(l))
+	}
+	l = len(m.Identity)
+	if l > 0 {
+		n += 1 + l + sovSessions(uint64(l))
+	}
+	if m.ConnectedAt != 0 {
+		n += 1 + sovSessions(uint64(m.ConnectedAt))
+	}
+	if m.LastHeartbeatAt != 0 {
+		n += 1 + sovSessions(uint64(m.LastHeartbeatAt))
+	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			l = len(s)
+			n += 1 + l + sovSessions(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ListSessionsRequest) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *ListSessionsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Sessions) > 0 {
+		for _, e := range m.Sessions {
+			l = e.Size()
+			n += 1 + l + sovSessions(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DisconnectSessionRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovSessions(uint64(l))
+	}
+	if m.ExpireLocks {
+		n += 2
+	}
+	return n
+}
+
+func (m *DisconnectSessionResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.ExpiredKeys) > 0 {
+		for _, s := range m.ExpiredKeys {
+			l = len(s)
+			n += 1 + l + sovSessions(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovSessions(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozSessions(x uint64) (n int) {
+	return sovSessions(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Session) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Session{`,
+		`Address:` + fmt.Sprintf("%v", this.Address) + `,`,
+		`Identity:` + fmt.Sprintf("%v", this.Identity) + `,`,
+		`ConnectedAt:` + fmt.Sprintf("%v", this.ConnectedAt) + `,`,
+		`LastHeartbeatAt:` + fmt.Sprintf("%v", this.LastHeartbeatAt) + `,`,
+		`Keys:` + fmt.Sprintf("%v", this.Keys) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ListSessionsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ListSessionsRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ListSessionsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ListSessionsResponse{`,
+		`Sessions:` + strings.Replace(fmt.Sprintf("%v", this.Sessions), "Session", "Session", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DisconnectSessionRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DisconnectSessionRequest{`,
+		`Address:` + fmt.Sprintf("%v", this.Address) + `,`,
+		`ExpireLocks:` + fmt.Sprintf("%v", this.ExpireLocks) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DisconnectSessionResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DisconnectSessionResponse{`,
+		`ExpiredKeys:` + fmt.Sprintf("%v", this.ExpiredKeys) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringSessions(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Session) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSessions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Session: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Session: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSessions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Identity", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSessions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Identity = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectedAt", wireType)
+			}
+			m.ConnectedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ConnectedAt |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastHeartbeatAt", wireType)
+			}
+			m.LastHeartbeatAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastHeartbeatAt |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSessions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSessions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSessions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListSessionsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSessions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListSessionsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListSessionsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSessions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSessions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListSessionsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSessions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListSessionsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListSessionsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sessions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSessions
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sessions = append(m.Sessions, &Session{})
+			if err := m.Sessions[len(m.Sessions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSessions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSessions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DisconnectSessionRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSessions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DisconnectSessionRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DisconnectSessionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSessions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpireLocks", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ExpireLocks = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSessions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSessions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DisconnectSessionResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSessions
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DisconnectSessionResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DisconnectSessionResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiredKeys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSessions
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpiredKeys = append(m.ExpiredKeys, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSessions(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSessions
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipSessions(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowSessions
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowSessions
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthSessions
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowSessions
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipSessions(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthSessions = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowSessions   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("sessions.proto", fileDescriptorSessions) }
+
+var fileDescriptorSessions = []byte{
+	// 370 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x85, 0x52, 0x3d, 0x4f, 0x02, 0x41,
+	0x10, 0xe5, 0x3c, 0x94, 0x73, 0x20, 0x1a, 0x56, 0x49, 0x4e, 0x8a, 0x0b, 0x6c, 0xa1, 0xc4, 0x98,
+	0x4b, 0xc4, 0xde, 0x44, 0x63, 0xa1, 0x91, 0xea, 0x2c, 0x28, 0x2f, 0x07, 0xbb, 0xc6, 0x0b, 0x78,
+	0x87, 0xb7, 0x6b, 0x22, 0x9d, 0x3f, 0xc1, 0xdf, 0x60, 0xe5, 0x4f, 0x31, 0xb1, 0xa1, 0xb4, 0x14,
+	0x6c, 0x2c, 0xfd, 0x09, 0xce, 0x7d, 0x86, 0x8b, 0x88, 0xc5, 0x64, 0x77, 0xde, 0x7b, 0xb3, 0x33,
+	0x6f, 0xb2, 0xb0, 0x21, 0xb8, 0x10, 0xae, 0xef, 0x09, 0x73, 0x14, 0xf8, 0xd2, 0x27, 0x95, 0xa1,
+	0xdf, 0x1f, 0x70, 0x69, 0x3a, 0xec, 0xd6, 0xf5, 0xe8, 0xb3, 0x02, 0xa5, 0xab, 0x58, 0x40, 0x74,
+	0x28, 0x39, 0x8c, 0x05, 0x98, 0xe9, 0x4a, 0x43, 0x69, 0xad, 0x5b, 0x69, 0x4a, 0xea, 0xa0, 0xb9,
+	0x8c, 0x7b, 0xd2, 0x95, 0x63, 0x7d, 0x25, 0xa2, 0xb2, 0x9c, 0x34, 0xa1, 0xd2, 0xf7, 0x3d, 0x8f,
+	0xf7, 0x25, 0x67, 0xb6, 0x23, 0x75, 0x15, 0x79, 0xd5, 0x2a, 0x67, 0xd8, 0x89, 0x24, 0xfb, 0x50,
+	0x1d, 0x3a, 0x42, 0xda, 0x37, 0xdc, 0x09, 0x64, 0x8f, 0x3b, 0x32, 0xd4, 0x15, 0x23, 0xdd, 0x66,
+	0x48, 0x9c, 0xa7, 0x38, 0x6a, 0x09, 0x14, 0x07, 0x7c, 0x2c, 0xf4, 0xd5, 0x86, 0x8a, 0x6d, 0xa2,
+	0x3b, 0xad, 0xc1, 0x56, 0xc7, 0x15, 0x32, 0x99, 0x53, 0x58, 0xfc, 0xee, 0x9e, 0x0b, 0x49, 0x2f,
+	0x60, 0x3b, 0x0f, 0x8b, 0x11, 0x1e, 0x9c, 0x1c, 0x82, 0x96, 0x7a, 0x46, 0x23, 0x6a, 0xab, 0xdc,
+	0xae, 0x99, 0xf3, 0xa6, 0xcd, 0xa4, 0xc2, 0xca, 0x64, 0xb4, 0x0b, 0xfa, 0x99, 0x2b, 0x92, 0x99,
+	0x53, 0x3a, 0x6e, 0xb3, 0x64, 0x2d, 0x68, 0x9d, 0x3f, 0x8c, 0xdc, 0x80, 0xdb, 0xe1, 0xf3, 0x22,
+	0x5a, 0x8d, 0x66, 0x95, 0x63, 0xac, 0x13, 0x42, 0xf4, 0x18, 0x76, 0x16, 0x3c, 0x9c, 0x0c, 0x9a,
+	0xd5, 0x33, 0x3b, 0xf2, 0xac, 0x44, 0x9e, 0x93, 0x7a, 0x76, 0x89, 0x50, 0xfb, 0x4d, 0x01, 0x2d,
+	0x35, 0x48, 0xba, 0x50, 0x99, 0x37, 0x4c, 0x9a, 0x79, 0x5b, 0x0b, 0x76, 0x54, 0xa7, 0xcb, 0x24,
+	0xf1, 0x18, 0xb4, 0x40, 0xae, 0xa1, 0xfa, 0x6b, 0x4a, 0xb2, 0x9b, 0x2f, 0xfd, 0x6b, 0x3f, 0xf5,
+	0xbd, 0x7f, 0x75, 0x69, 0x9f, 0xd3, 0x83, 0xc9, 0xd4, 0x28, 0xbc, 0x63, 0x7c, 0x4f, 0x0d, 0xe5,
+	0x71, 0x66, 0x28, 0x2f, 0x18, 0xaf, 0x18, 0x13, 0x8c, 0x0f, 0x8c, 0xaf, 0x19, 0x72, 0x78, 0x3e,
+	0x7d, 0x1a, 0x85, 0xde, 0x5a, 0xf4, 0x61, 0x8f, 0x7e, 0x00, 0x72, 0x0a, 0x16, 0x24, 0xc2, 0x02,
+	0x00, 0x00,
+}
@@ -1,7 +1,7 @@
 package models
 
 func GetResource(resource *Resource) *Resource {
-	r := &Resource{Key: resource.Key, Owner: resource.Owner, Value: resource.Value}
+	r := &Resource{Key: resource.Key, Owner: resource.Owner, Value: resource.Value, Metadata: resource.Metadata}
 	if resource.TypeCode == UNKNOWN {
 		r.TypeCode = GetTypeCode(resource.Type)
 		r.Type = resource.Type
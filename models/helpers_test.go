@@ -71,5 +71,15 @@ var _ = Describe("helpers", func() {
 			Expect(models.GetResource(resource2).TypeCode).To(Equal(models.PRESENCE))
 			Expect(models.GetResource(resource2).Type).To(Equal("presence"))
 		})
+
+		It("keeps the metadata", func() {
+			resource := &models.Resource{
+				Key:      "sandwich",
+				TypeCode: models.LOCK,
+				Metadata: map[string]string{"az": "z1"},
+			}
+
+			Expect(models.GetResource(resource).Metadata).To(Equal(map[string]string{"az": "z1"}))
+		})
 	})
 })
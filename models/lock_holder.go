@@ -0,0 +1,32 @@
+package models
+
+import "google.golang.org/grpc/metadata"
+
+// LockHolderTrailerKey is the response trailer in which Lock returns the
+// current holder of a lock when the request collides with it. Clients can
+// read it with the grpc.Trailer call option and LockHolderFromTrailer.
+const LockHolderTrailerKey = "locket-lock-holder-bin"
+
+func LockHolderTrailer(holder *LockHolder) (metadata.MD, error) {
+	data, err := holder.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Pairs(LockHolderTrailerKey, string(data)), nil
+}
+
+// LockHolderFromTrailer returns the lock holder sent in md, or nil if there
+// is none.
+func LockHolderFromTrailer(md metadata.MD) (*LockHolder, error) {
+	values := md[LockHolderTrailerKey]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	holder := &LockHolder{}
+	err := holder.Unmarshal([]byte(values[0]))
+	if err != nil {
+		return nil, err
+	}
+	return holder, nil
+}
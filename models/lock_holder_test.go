@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ = Describe("LockHolder", func() {
+	It("round trips the holder through a trailer", func() {
+		holder := &models.LockHolder{Key: "key", Owner: "jim", RemainingTtlInMilliseconds: 1500}
+
+		md, err := models.LockHolderTrailer(holder)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded, err := models.LockHolderFromTrailer(md)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(Equal(holder))
+	})
+
+	It("returns nil when the trailer has no holder", func() {
+		holder, err := models.LockHolderFromTrailer(metadata.MD{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(holder).To(BeNil())
+	})
+})
@@ -12,12 +12,26 @@
 		Resource
 		LockRequest
 		LockResponse
+		LockHolder
+		LockGroupRequest
+		LockGroupResponse
 		ReleaseRequest
 		ReleaseResponse
 		FetchRequest
 		FetchResponse
 		FetchAllRequest
 		FetchAllResponse
+		ContendedKey
+		StatsRequest
+		StatsResponse
+		Waiter
+		WaitersRequest
+		WaitersResponse
+		KeepAliveRequest
+		Revocation
+		KeepAliveResponse
+		BarrierRequest
+		BarrierResponse
 */
 package models
 
@@ -29,6 +43,7 @@ import strconv "strconv"
 
 import strings "strings"
 import reflect "reflect"
+import github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
 
 import (
 	context "golang.org/x/net/context"
@@ -69,12 +84,73 @@ var TypeCode_value = map[string]int32{
 
 func (TypeCode) EnumDescriptor() ([]byte, []int) { return fileDescriptorLocket, []int{0} }
 
+type LockMode int32
+
+const (
+	EXCLUSIVE LockMode = 0
+	SHARED    LockMode = 1
+	SEMAPHORE LockMode = 2
+)
+
+var LockMode_name = map[int32]string{
+	0: "EXCLUSIVE",
+	1: "SHARED",
+	2: "SEMAPHORE",
+}
+var LockMode_value = map[string]int32{
+	"EXCLUSIVE": 0,
+	"SHARED":    1,
+	"SEMAPHORE": 2,
+}
+
+func (LockMode) EnumDescriptor() ([]byte, []int) { return fileDescriptorLocket, []int{1} }
+
+type DuplicatePolicy int32
+
+const (
+	ALLOW_DUPLICATE  DuplicatePolicy = 0
+	REJECT_DUPLICATE DuplicatePolicy = 1
+)
+
+var DuplicatePolicy_name = map[int32]string{
+	0: "ALLOW_DUPLICATE",
+	1: "REJECT_DUPLICATE",
+}
+var DuplicatePolicy_value = map[string]int32{
+	"ALLOW_DUPLICATE":  0,
+	"REJECT_DUPLICATE": 1,
+}
+
+func (DuplicatePolicy) EnumDescriptor() ([]byte, []int) { return fileDescriptorLocket, []int{2} }
+
+type LockOutcome int32
+
+const (
+	ACQUIRED                      LockOutcome = 0
+	REFRESHED                     LockOutcome = 1
+	REFRESHED_FROM_NEW_CONNECTION LockOutcome = 2
+)
+
+var LockOutcome_name = map[int32]string{
+	0: "ACQUIRED",
+	1: "REFRESHED",
+	2: "REFRESHED_FROM_NEW_CONNECTION",
+}
+var LockOutcome_value = map[string]int32{
+	"ACQUIRED":                      0,
+	"REFRESHED":                     1,
+	"REFRESHED_FROM_NEW_CONNECTION": 2,
+}
+
+func (LockOutcome) EnumDescriptor() ([]byte, []int) { return fileDescriptorLocket, []int{3} }
+
 type Resource struct {
-	Key      string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Owner    string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
-	Value    string   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	Type     string   `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
-	TypeCode TypeCode `protobuf:"varint,5,opt,name=type_code,json=typeCode,proto3,enum=models.TypeCode" json:"type_code,omitempty"`
+	Key      string            `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Owner    string            `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Value    string            `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Type     string            `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	TypeCode TypeCode          `protobuf:"varint,5,opt,name=type_code,json=typeCode,proto3,enum=models.TypeCode" json:"type_code,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,6,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (m *Resource) Reset()                    { *m = Resource{} }
@@ -116,9 +192,25 @@ func (m *Resource) GetTypeCode() TypeCode {
 	return UNKNOWN
 }
 
+func (m *Resource) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 type LockRequest struct {
-	Resource     *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
-	TtlInSeconds int64     `protobuf:"varint,2,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	Resource            *Resource       `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	TtlInSeconds        int64           `protobuf:"varint,2,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	TtlInMilliseconds   int64           `protobuf:"varint,3,opt,name=ttl_in_milliseconds,json=ttlInMilliseconds,proto3" json:"ttl_in_milliseconds,omitempty"`
+	Mode                LockMode        `protobuf:"varint,4,opt,name=mode,proto3,enum=models.LockMode" json:"mode,omitempty"`
+	Capacity            int32           `protobuf:"varint,5,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Fair                bool            `protobuf:"varint,6,opt,name=fair,proto3" json:"fair,omitempty"`
+	Priority            int32           `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	Preempt             bool            `protobuf:"varint,8,opt,name=preempt,proto3" json:"preempt,omitempty"`
+	RequestId           string          `protobuf:"bytes,9,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	GraceInMilliseconds int64           `protobuf:"varint,10,opt,name=grace_in_milliseconds,json=graceInMilliseconds,proto3" json:"grace_in_milliseconds,omitempty"`
+	OnDuplicate         DuplicatePolicy `protobuf:"varint,11,opt,name=on_duplicate,json=onDuplicate,proto3,enum=models.DuplicatePolicy" json:"on_duplicate,omitempty"`
 }
 
 func (m *LockRequest) Reset()                    { *m = LockRequest{} }
@@ -139,20 +231,219 @@ func (m *LockRequest) GetTtlInSeconds() int64 {
 	return 0
 }
 
+func (m *LockRequest) GetTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.TtlInMilliseconds
+	}
+	return 0
+}
+
+func (m *LockRequest) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return EXCLUSIVE
+}
+
+func (m *LockRequest) GetCapacity() int32 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *LockRequest) GetFair() bool {
+	if m != nil {
+		return m.Fair
+	}
+	return false
+}
+
+func (m *LockRequest) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *LockRequest) GetPreempt() bool {
+	if m != nil {
+		return m.Preempt
+	}
+	return false
+}
+
+func (m *LockRequest) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *LockRequest) GetGraceInMilliseconds() int64 {
+	if m != nil {
+		return m.GraceInMilliseconds
+	}
+	return 0
+}
+
+func (m *LockRequest) GetOnDuplicate() DuplicatePolicy {
+	if m != nil {
+		return m.OnDuplicate
+	}
+	return 0
+}
+
 type LockResponse struct {
+	Resource                        *Resource   `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	FencingToken                    int64       `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	ExpiresAt                       int64       `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Outcome                         LockOutcome `protobuf:"varint,4,opt,name=outcome,proto3,enum=models.LockOutcome" json:"outcome,omitempty"`
+	HeartbeatIntervalInMilliseconds int64       `protobuf:"varint,5,opt,name=heartbeat_interval_in_milliseconds,json=heartbeatIntervalInMilliseconds,proto3" json:"heartbeat_interval_in_milliseconds,omitempty"`
 }
 
 func (m *LockResponse) Reset()                    { *m = LockResponse{} }
 func (*LockResponse) ProtoMessage()               {}
 func (*LockResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{2} }
 
+func (m *LockResponse) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *LockResponse) GetFencingToken() int64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *LockResponse) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *LockResponse) GetOutcome() LockOutcome {
+	if m != nil {
+		return m.Outcome
+	}
+	return 0
+}
+
+func (m *LockResponse) GetHeartbeatIntervalInMilliseconds() int64 {
+	if m != nil {
+		return m.HeartbeatIntervalInMilliseconds
+	}
+	return 0
+}
+
+type LockHolder struct {
+	Key                        string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Owner                      string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	RemainingTtlInMilliseconds int64  `protobuf:"varint,3,opt,name=remaining_ttl_in_milliseconds,json=remainingTtlInMilliseconds,proto3" json:"remaining_ttl_in_milliseconds,omitempty"`
+	ExpiresAt                  int64  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	HeldForInMilliseconds      int64  `protobuf:"varint,5,opt,name=held_for_in_milliseconds,json=heldForInMilliseconds,proto3" json:"held_for_in_milliseconds,omitempty"`
+}
+
+func (m *LockHolder) Reset()                    { *m = LockHolder{} }
+func (*LockHolder) ProtoMessage()               {}
+func (*LockHolder) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{3} }
+
+func (m *LockHolder) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *LockHolder) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *LockHolder) GetRemainingTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.RemainingTtlInMilliseconds
+	}
+	return 0
+}
+
+func (m *LockHolder) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *LockHolder) GetHeldForInMilliseconds() int64 {
+	if m != nil {
+		return m.HeldForInMilliseconds
+	}
+	return 0
+}
+
+type LockGroupRequest struct {
+	Resources         []*Resource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	TtlInSeconds      int64       `protobuf:"varint,2,opt,name=ttl_in_seconds,json=ttlInSeconds,proto3" json:"ttl_in_seconds,omitempty"`
+	TtlInMilliseconds int64       `protobuf:"varint,3,opt,name=ttl_in_milliseconds,json=ttlInMilliseconds,proto3" json:"ttl_in_milliseconds,omitempty"`
+}
+
+func (m *LockGroupRequest) Reset()                    { *m = LockGroupRequest{} }
+func (*LockGroupRequest) ProtoMessage()               {}
+func (*LockGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{4} }
+
+func (m *LockGroupRequest) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *LockGroupRequest) GetTtlInSeconds() int64 {
+	if m != nil {
+		return m.TtlInSeconds
+	}
+	return 0
+}
+
+func (m *LockGroupRequest) GetTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.TtlInMilliseconds
+	}
+	return 0
+}
+
+type LockGroupResponse struct {
+	Locks []*LockResponse `protobuf:"bytes,1,rep,name=locks" json:"locks,omitempty"`
+}
+
+func (m *LockGroupResponse) Reset()                    { *m = LockGroupResponse{} }
+func (*LockGroupResponse) ProtoMessage()               {}
+func (*LockGroupResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{5} }
+
+func (m *LockGroupResponse) GetLocks() []*LockResponse {
+	if m != nil {
+		return m.Locks
+	}
+	return nil
+}
+
 type ReleaseRequest struct {
-	Resource *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	Resource             *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	Mode                 LockMode  `protobuf:"varint,2,opt,name=mode,proto3,enum=models.LockMode" json:"mode,omitempty"`
+	ExpectedValue        string    `protobuf:"bytes,3,opt,name=expected_value,json=expectedValue,proto3" json:"expected_value,omitempty"`
+	ExpectedFencingToken int64     `protobuf:"varint,4,opt,name=expected_fencing_token,json=expectedFencingToken,proto3" json:"expected_fencing_token,omitempty"`
 }
 
 func (m *ReleaseRequest) Reset()                    { *m = ReleaseRequest{} }
 func (*ReleaseRequest) ProtoMessage()               {}
-func (*ReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{3} }
+func (*ReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{6} }
 
 func (m *ReleaseRequest) GetResource() *Resource {
 	if m != nil {
@@ -161,20 +452,42 @@ func (m *ReleaseRequest) GetResource() *Resource {
 	return nil
 }
 
+func (m *ReleaseRequest) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return EXCLUSIVE
+}
+
+func (m *ReleaseRequest) GetExpectedValue() string {
+	if m != nil {
+		return m.ExpectedValue
+	}
+	return ""
+}
+
+func (m *ReleaseRequest) GetExpectedFencingToken() int64 {
+	if m != nil {
+		return m.ExpectedFencingToken
+	}
+	return 0
+}
+
 type ReleaseResponse struct {
 }
 
 func (m *ReleaseResponse) Reset()                    { *m = ReleaseResponse{} }
 func (*ReleaseResponse) ProtoMessage()               {}
-func (*ReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{4} }
+func (*ReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{7} }
 
 type FetchRequest struct {
-	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Key      string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	TypeCode TypeCode `protobuf:"varint,2,opt,name=type_code,json=typeCode,proto3,enum=models.TypeCode" json:"type_code,omitempty"`
 }
 
 func (m *FetchRequest) Reset()                    { *m = FetchRequest{} }
 func (*FetchRequest) ProtoMessage()               {}
-func (*FetchRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{5} }
+func (*FetchRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{8} }
 
 func (m *FetchRequest) GetKey() string {
 	if m != nil {
@@ -183,13 +496,20 @@ func (m *FetchRequest) GetKey() string {
 	return ""
 }
 
+func (m *FetchRequest) GetTypeCode() TypeCode {
+	if m != nil {
+		return m.TypeCode
+	}
+	return UNKNOWN
+}
+
 type FetchResponse struct {
 	Resource *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
 }
 
 func (m *FetchResponse) Reset()                    { *m = FetchResponse{} }
 func (*FetchResponse) ProtoMessage()               {}
-func (*FetchResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{6} }
+func (*FetchResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{9} }
 
 func (m *FetchResponse) GetResource() *Resource {
 	if m != nil {
@@ -205,7 +525,7 @@ type FetchAllRequest struct {
 
 func (m *FetchAllRequest) Reset()                    { *m = FetchAllRequest{} }
 func (*FetchAllRequest) ProtoMessage()               {}
-func (*FetchAllRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{7} }
+func (*FetchAllRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{10} }
 
 func (m *FetchAllRequest) GetType() string {
 	if m != nil {
@@ -222,12 +542,13 @@ func (m *FetchAllRequest) GetTypeCode() TypeCode {
 }
 
 type FetchAllResponse struct {
-	Resources []*Resource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	Resources []*Resource   `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	Holders   []*LockHolder `protobuf:"bytes,2,rep,name=holders" json:"holders,omitempty"`
 }
 
 func (m *FetchAllResponse) Reset()                    { *m = FetchAllResponse{} }
 func (*FetchAllResponse) ProtoMessage()               {}
-func (*FetchAllResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{8} }
+func (*FetchAllResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{11} }
 
 func (m *FetchAllResponse) GetResources() []*Resource {
 	if m != nil {
@@ -236,58 +557,372 @@ func (m *FetchAllResponse) GetResources() []*Resource {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Resource)(nil), "models.Resource")
-	proto.RegisterType((*LockRequest)(nil), "models.LockRequest")
-	proto.RegisterType((*LockResponse)(nil), "models.LockResponse")
-	proto.RegisterType((*ReleaseRequest)(nil), "models.ReleaseRequest")
-	proto.RegisterType((*ReleaseResponse)(nil), "models.ReleaseResponse")
-	proto.RegisterType((*FetchRequest)(nil), "models.FetchRequest")
-	proto.RegisterType((*FetchResponse)(nil), "models.FetchResponse")
-	proto.RegisterType((*FetchAllRequest)(nil), "models.FetchAllRequest")
-	proto.RegisterType((*FetchAllResponse)(nil), "models.FetchAllResponse")
-	proto.RegisterEnum("models.TypeCode", TypeCode_name, TypeCode_value)
+func (m *FetchAllResponse) GetHolders() []*LockHolder {
+	if m != nil {
+		return m.Holders
+	}
+	return nil
 }
-func (x TypeCode) String() string {
-	s, ok := TypeCode_name[int32(x)]
-	if ok {
-		return s
+
+type ContendedKey struct {
+	Key              string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	FailedAttempts   int64  `protobuf:"varint,2,opt,name=failed_attempts,json=failedAttempts,proto3" json:"failed_attempts,omitempty"`
+	Waiters          int64  `protobuf:"varint,3,opt,name=waiters,proto3" json:"waiters,omitempty"`
+	OwnershipChanges int64  `protobuf:"varint,4,opt,name=ownership_changes,json=ownershipChanges,proto3" json:"ownership_changes,omitempty"`
+}
+
+func (m *ContendedKey) Reset()                    { *m = ContendedKey{} }
+func (*ContendedKey) ProtoMessage()               {}
+func (*ContendedKey) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{12} }
+
+func (m *ContendedKey) GetKey() string {
+	if m != nil {
+		return m.Key
 	}
-	return strconv.Itoa(int(x))
+	return ""
 }
-func (this *Resource) Equal(that interface{}) bool {
-	if that == nil {
-		if this == nil {
-			return true
-		}
-		return false
+
+func (m *ContendedKey) GetFailedAttempts() int64 {
+	if m != nil {
+		return m.FailedAttempts
 	}
+	return 0
+}
 
-	that1, ok := that.(*Resource)
-	if !ok {
-		that2, ok := that.(Resource)
-		if ok {
-			that1 = &that2
-		} else {
-			return false
-		}
+func (m *ContendedKey) GetWaiters() int64 {
+	if m != nil {
+		return m.Waiters
 	}
-	if that1 == nil {
-		if this == nil {
-			return true
-		}
-		return false
-	} else if this == nil {
-		return false
+	return 0
+}
+
+func (m *ContendedKey) GetOwnershipChanges() int64 {
+	if m != nil {
+		return m.OwnershipChanges
 	}
-	if this.Key != that1.Key {
-		return false
+	return 0
+}
+
+type StatsRequest struct {
+	TopN int32 `protobuf:"varint,1,opt,name=top_n,json=topN,proto3" json:"top_n,omitempty"`
+}
+
+func (m *StatsRequest) Reset()                    { *m = StatsRequest{} }
+func (*StatsRequest) ProtoMessage()               {}
+func (*StatsRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{13} }
+
+func (m *StatsRequest) GetTopN() int32 {
+	if m != nil {
+		return m.TopN
 	}
-	if this.Owner != that1.Owner {
-		return false
+	return 0
+}
+
+type StatsResponse struct {
+	ContendedKeys []*ContendedKey `protobuf:"bytes,1,rep,name=contended_keys,json=contendedKeys" json:"contended_keys,omitempty"`
+}
+
+func (m *StatsResponse) Reset()                    { *m = StatsResponse{} }
+func (*StatsResponse) ProtoMessage()               {}
+func (*StatsResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{14} }
+
+func (m *StatsResponse) GetContendedKeys() []*ContendedKey {
+	if m != nil {
+		return m.ContendedKeys
 	}
-	if this.Value != that1.Value {
-		return false
+	return nil
+}
+
+type Waiter struct {
+	Owner                 string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Identity              string `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	Priority              int32  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	WaitingInMilliseconds int64  `protobuf:"varint,4,opt,name=waiting_in_milliseconds,json=waitingInMilliseconds,proto3" json:"waiting_in_milliseconds,omitempty"`
+	Queued                bool   `protobuf:"varint,5,opt,name=queued,proto3" json:"queued,omitempty"`
+}
+
+func (m *Waiter) Reset()                    { *m = Waiter{} }
+func (*Waiter) ProtoMessage()               {}
+func (*Waiter) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{15} }
+
+func (m *Waiter) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Waiter) GetIdentity() string {
+	if m != nil {
+		return m.Identity
+	}
+	return ""
+}
+
+func (m *Waiter) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *Waiter) GetWaitingInMilliseconds() int64 {
+	if m != nil {
+		return m.WaitingInMilliseconds
+	}
+	return 0
+}
+
+func (m *Waiter) GetQueued() bool {
+	if m != nil {
+		return m.Queued
+	}
+	return false
+}
+
+type WaitersRequest struct {
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *WaitersRequest) Reset()                    { *m = WaitersRequest{} }
+func (*WaitersRequest) ProtoMessage()               {}
+func (*WaitersRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{16} }
+
+func (m *WaitersRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+type WaitersResponse struct {
+	Waiters []*Waiter `protobuf:"bytes,1,rep,name=waiters" json:"waiters,omitempty"`
+}
+
+func (m *WaitersResponse) Reset()                    { *m = WaitersResponse{} }
+func (*WaitersResponse) ProtoMessage()               {}
+func (*WaitersResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{17} }
+
+func (m *WaitersResponse) GetWaiters() []*Waiter {
+	if m != nil {
+		return m.Waiters
+	}
+	return nil
+}
+
+type KeepAliveRequest struct {
+	Locks    []*LockRequest `protobuf:"bytes,1,rep,name=locks" json:"locks,omitempty"`
+	Releases []string       `protobuf:"bytes,2,rep,name=releases" json:"releases,omitempty"`
+}
+
+func (m *KeepAliveRequest) Reset()                    { *m = KeepAliveRequest{} }
+func (*KeepAliveRequest) ProtoMessage()               {}
+func (*KeepAliveRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{18} }
+
+func (m *KeepAliveRequest) GetLocks() []*LockRequest {
+	if m != nil {
+		return m.Locks
+	}
+	return nil
+}
+
+func (m *KeepAliveRequest) GetReleases() []string {
+	if m != nil {
+		return m.Releases
+	}
+	return nil
+}
+
+type Revocation struct {
+	Key    string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Owner  string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (m *Revocation) Reset()                    { *m = Revocation{} }
+func (*Revocation) ProtoMessage()               {}
+func (*Revocation) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{19} }
+
+func (m *Revocation) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Revocation) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Revocation) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type KeepAliveResponse struct {
+	Revocations []*Revocation `protobuf:"bytes,1,rep,name=revocations" json:"revocations,omitempty"`
+}
+
+func (m *KeepAliveResponse) Reset()                    { *m = KeepAliveResponse{} }
+func (*KeepAliveResponse) ProtoMessage()               {}
+func (*KeepAliveResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{20} }
+
+func (m *KeepAliveResponse) GetRevocations() []*Revocation {
+	if m != nil {
+		return m.Revocations
+	}
+	return nil
+}
+
+type BarrierRequest struct {
+	Key               string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Participant       string `protobuf:"bytes,2,opt,name=participant,proto3" json:"participant,omitempty"`
+	Participants      int32  `protobuf:"varint,3,opt,name=participants,proto3" json:"participants,omitempty"`
+	TtlInMilliseconds int64  `protobuf:"varint,4,opt,name=ttl_in_milliseconds,json=ttlInMilliseconds,proto3" json:"ttl_in_milliseconds,omitempty"`
+}
+
+func (m *BarrierRequest) Reset()                    { *m = BarrierRequest{} }
+func (*BarrierRequest) ProtoMessage()               {}
+func (*BarrierRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{21} }
+
+func (m *BarrierRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *BarrierRequest) GetParticipant() string {
+	if m != nil {
+		return m.Participant
+	}
+	return ""
+}
+
+func (m *BarrierRequest) GetParticipants() int32 {
+	if m != nil {
+		return m.Participants
+	}
+	return 0
+}
+
+func (m *BarrierRequest) GetTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.TtlInMilliseconds
+	}
+	return 0
+}
+
+type BarrierResponse struct {
+	Arrived int32 `protobuf:"varint,1,opt,name=arrived,proto3" json:"arrived,omitempty"`
+}
+
+func (m *BarrierResponse) Reset()                    { *m = BarrierResponse{} }
+func (*BarrierResponse) ProtoMessage()               {}
+func (*BarrierResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocket, []int{22} }
+
+func (m *BarrierResponse) GetArrived() int32 {
+	if m != nil {
+		return m.Arrived
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Resource)(nil), "models.Resource")
+	proto.RegisterType((*LockRequest)(nil), "models.LockRequest")
+	proto.RegisterType((*LockResponse)(nil), "models.LockResponse")
+	proto.RegisterType((*LockHolder)(nil), "models.LockHolder")
+	proto.RegisterType((*LockGroupRequest)(nil), "models.LockGroupRequest")
+	proto.RegisterType((*LockGroupResponse)(nil), "models.LockGroupResponse")
+	proto.RegisterType((*ReleaseRequest)(nil), "models.ReleaseRequest")
+	proto.RegisterType((*ReleaseResponse)(nil), "models.ReleaseResponse")
+	proto.RegisterType((*FetchRequest)(nil), "models.FetchRequest")
+	proto.RegisterType((*FetchResponse)(nil), "models.FetchResponse")
+	proto.RegisterType((*FetchAllRequest)(nil), "models.FetchAllRequest")
+	proto.RegisterType((*FetchAllResponse)(nil), "models.FetchAllResponse")
+	proto.RegisterType((*ContendedKey)(nil), "models.ContendedKey")
+	proto.RegisterType((*StatsRequest)(nil), "models.StatsRequest")
+	proto.RegisterType((*StatsResponse)(nil), "models.StatsResponse")
+	proto.RegisterType((*Waiter)(nil), "models.Waiter")
+	proto.RegisterType((*WaitersRequest)(nil), "models.WaitersRequest")
+	proto.RegisterType((*WaitersResponse)(nil), "models.WaitersResponse")
+	proto.RegisterType((*KeepAliveRequest)(nil), "models.KeepAliveRequest")
+	proto.RegisterType((*Revocation)(nil), "models.Revocation")
+	proto.RegisterType((*KeepAliveResponse)(nil), "models.KeepAliveResponse")
+	proto.RegisterType((*BarrierRequest)(nil), "models.BarrierRequest")
+	proto.RegisterType((*BarrierResponse)(nil), "models.BarrierResponse")
+	proto.RegisterEnum("models.TypeCode", TypeCode_name, TypeCode_value)
+	proto.RegisterEnum("models.LockMode", LockMode_name, LockMode_value)
+	proto.RegisterEnum("models.DuplicatePolicy", DuplicatePolicy_name, DuplicatePolicy_value)
+	proto.RegisterEnum("models.LockOutcome", LockOutcome_name, LockOutcome_value)
+}
+func (x TypeCode) String() string {
+	s, ok := TypeCode_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (x LockMode) String() string {
+	s, ok := LockMode_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (x DuplicatePolicy) String() string {
+	s, ok := DuplicatePolicy_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (x LockOutcome) String() string {
+	s, ok := LockOutcome_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (this *Resource) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Resource)
+	if !ok {
+		that2, ok := that.(Resource)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if this.Owner != that1.Owner {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
 	}
 	if this.Type != that1.Type {
 		return false
@@ -295,6 +930,14 @@ func (this *Resource) Equal(that interface{}) bool {
 	if this.TypeCode != that1.TypeCode {
 		return false
 	}
+	if len(this.Metadata) != len(that1.Metadata) {
+		return false
+	}
+	for i := range this.Metadata {
+		if this.Metadata[i] != that1.Metadata[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *LockRequest) Equal(that interface{}) bool {
@@ -328,6 +971,33 @@ func (this *LockRequest) Equal(that interface{}) bool {
 	if this.TtlInSeconds != that1.TtlInSeconds {
 		return false
 	}
+	if this.TtlInMilliseconds != that1.TtlInMilliseconds {
+		return false
+	}
+	if this.Mode != that1.Mode {
+		return false
+	}
+	if this.Capacity != that1.Capacity {
+		return false
+	}
+	if this.Fair != that1.Fair {
+		return false
+	}
+	if this.Priority != that1.Priority {
+		return false
+	}
+	if this.Preempt != that1.Preempt {
+		return false
+	}
+	if this.RequestId != that1.RequestId {
+		return false
+	}
+	if this.GraceInMilliseconds != that1.GraceInMilliseconds {
+		return false
+	}
+	if this.OnDuplicate != that1.OnDuplicate {
+		return false
+	}
 	return true
 }
 func (this *LockResponse) Equal(that interface{}) bool {
@@ -355,9 +1025,24 @@ func (this *LockResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
+	if !this.Resource.Equal(that1.Resource) {
+		return false
+	}
+	if this.FencingToken != that1.FencingToken {
+		return false
+	}
+	if this.ExpiresAt != that1.ExpiresAt {
+		return false
+	}
+	if this.Outcome != that1.Outcome {
+		return false
+	}
+	if this.HeartbeatIntervalInMilliseconds != that1.HeartbeatIntervalInMilliseconds {
+		return false
+	}
 	return true
 }
-func (this *ReleaseRequest) Equal(that interface{}) bool {
+func (this *LockHolder) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -365,9 +1050,9 @@ func (this *ReleaseRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*ReleaseRequest)
+	that1, ok := that.(*LockHolder)
 	if !ok {
-		that2, ok := that.(ReleaseRequest)
+		that2, ok := that.(LockHolder)
 		if ok {
 			that1 = &that2
 		} else {
@@ -382,12 +1067,24 @@ func (this *ReleaseRequest) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
-	if !this.Resource.Equal(that1.Resource) {
+	if this.Key != that1.Key {
+		return false
+	}
+	if this.Owner != that1.Owner {
+		return false
+	}
+	if this.RemainingTtlInMilliseconds != that1.RemainingTtlInMilliseconds {
+		return false
+	}
+	if this.ExpiresAt != that1.ExpiresAt {
+		return false
+	}
+	if this.HeldForInMilliseconds != that1.HeldForInMilliseconds {
 		return false
 	}
 	return true
 }
-func (this *ReleaseResponse) Equal(that interface{}) bool {
+func (this *LockGroupRequest) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -395,9 +1092,9 @@ func (this *ReleaseResponse) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*ReleaseResponse)
+	that1, ok := that.(*LockGroupRequest)
 	if !ok {
-		that2, ok := that.(ReleaseResponse)
+		that2, ok := that.(LockGroupRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -412,9 +1109,23 @@ func (this *ReleaseResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
+	if len(this.Resources) != len(that1.Resources) {
+		return false
+	}
+	for i := range this.Resources {
+		if !this.Resources[i].Equal(that1.Resources[i]) {
+			return false
+		}
+	}
+	if this.TtlInSeconds != that1.TtlInSeconds {
+		return false
+	}
+	if this.TtlInMilliseconds != that1.TtlInMilliseconds {
+		return false
+	}
 	return true
 }
-func (this *FetchRequest) Equal(that interface{}) bool {
+func (this *LockGroupResponse) Equal(that interface{}) bool {
 	if that == nil {
 		if this == nil {
 			return true
@@ -422,9 +1133,110 @@ func (this *FetchRequest) Equal(that interface{}) bool {
 		return false
 	}
 
-	that1, ok := that.(*FetchRequest)
+	that1, ok := that.(*LockGroupResponse)
 	if !ok {
-		that2, ok := that.(FetchRequest)
+		that2, ok := that.(LockGroupResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.Locks) != len(that1.Locks) {
+		return false
+	}
+	for i := range this.Locks {
+		if !this.Locks[i].Equal(that1.Locks[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReleaseRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ReleaseRequest)
+	if !ok {
+		that2, ok := that.(ReleaseRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Resource.Equal(that1.Resource) {
+		return false
+	}
+	if this.Mode != that1.Mode {
+		return false
+	}
+	if this.ExpectedValue != that1.ExpectedValue {
+		return false
+	}
+	if this.ExpectedFencingToken != that1.ExpectedFencingToken {
+		return false
+	}
+	return true
+}
+func (this *ReleaseResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ReleaseResponse)
+	if !ok {
+		that2, ok := that.(ReleaseResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *FetchRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*FetchRequest)
+	if !ok {
+		that2, ok := that.(FetchRequest)
 		if ok {
 			that1 = &that2
 		} else {
@@ -442,6 +1254,9 @@ func (this *FetchRequest) Equal(that interface{}) bool {
 	if this.Key != that1.Key {
 		return false
 	}
+	if this.TypeCode != that1.TypeCode {
+		return false
+	}
 	return true
 }
 func (this *FetchResponse) Equal(that interface{}) bool {
@@ -540,793 +1355,4432 @@ func (this *FetchAllResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
-	return true
-}
-func (this *Resource) GoString() string {
-	if this == nil {
-		return "nil"
-	}
-	s := make([]string, 0, 9)
-	s = append(s, "&models.Resource{")
-	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
-	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
-	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
-	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
-	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *LockRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if len(this.Holders) != len(that1.Holders) {
+		return false
 	}
-	s := make([]string, 0, 6)
-	s = append(s, "&models.LockRequest{")
-	if this.Resource != nil {
-		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	for i := range this.Holders {
+		if !this.Holders[i].Equal(that1.Holders[i]) {
+			return false
+		}
 	}
-	s = append(s, "TtlInSeconds: "+fmt.Sprintf("%#v", this.TtlInSeconds)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *LockResponse) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *ContendedKey) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&models.LockResponse{")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *ReleaseRequest) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*ContendedKey)
+	if !ok {
+		that2, ok := that.(ContendedKey)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&models.ReleaseRequest{")
-	if this.Resource != nil {
-		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *ReleaseResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if this.Key != that1.Key {
+		return false
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&models.ReleaseResponse{")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *FetchRequest) GoString() string {
-	if this == nil {
-		return "nil"
+	if this.FailedAttempts != that1.FailedAttempts {
+		return false
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&models.FetchRequest{")
-	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *FetchResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if this.Waiters != that1.Waiters {
+		return false
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&models.FetchResponse{")
-	if this.Resource != nil {
-		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	if this.OwnershipChanges != that1.OwnershipChanges {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *FetchAllRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *StatsRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	s := make([]string, 0, 6)
-	s = append(s, "&models.FetchAllRequest{")
-	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
-	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *FetchAllResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*StatsRequest)
+	if !ok {
+		that2, ok := that.(StatsRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&models.FetchAllResponse{")
-	if this.Resources != nil {
-		s = append(s, "Resources: "+fmt.Sprintf("%#v", this.Resources)+",\n")
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func valueToGoStringLocket(v interface{}, typ string) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+	if this.TopN != that1.TopN {
+		return false
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+	return true
 }
+func (this *StatsResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// Client API for Locket service
-
-type LocketClient interface {
-	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
-	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
-	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
-	FetchAll(ctx context.Context, in *FetchAllRequest, opts ...grpc.CallOption) (*FetchAllResponse, error)
-}
-
-type locketClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewLocketClient(cc *grpc.ClientConn) LocketClient {
-	return &locketClient{cc}
-}
-
-func (c *locketClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
-	out := new(LockResponse)
-	err := grpc.Invoke(ctx, "/models.Locket/Lock", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+	that1, ok := that.(*StatsResponse)
+	if !ok {
+		that2, ok := that.(StatsResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	return out, nil
-}
-
-func (c *locketClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
-	out := new(FetchResponse)
-	err := grpc.Invoke(ctx, "/models.Locket/Fetch", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return out, nil
-}
-
-func (c *locketClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
-	out := new(ReleaseResponse)
-	err := grpc.Invoke(ctx, "/models.Locket/Release", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+	if len(this.ContendedKeys) != len(that1.ContendedKeys) {
+		return false
 	}
-	return out, nil
-}
-
-func (c *locketClient) FetchAll(ctx context.Context, in *FetchAllRequest, opts ...grpc.CallOption) (*FetchAllResponse, error) {
-	out := new(FetchAllResponse)
-	err := grpc.Invoke(ctx, "/models.Locket/FetchAll", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+	for i := range this.ContendedKeys {
+		if !this.ContendedKeys[i].Equal(that1.ContendedKeys[i]) {
+			return false
+		}
 	}
-	return out, nil
-}
-
-// Server API for Locket service
-
-type LocketServer interface {
-	Lock(context.Context, *LockRequest) (*LockResponse, error)
-	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
-	Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error)
-	FetchAll(context.Context, *FetchAllRequest) (*FetchAllResponse, error)
-}
-
-func RegisterLocketServer(s *grpc.Server, srv LocketServer) {
-	s.RegisterService(&_Locket_serviceDesc, srv)
+	return true
 }
+func (this *Waiter) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
 
-func _Locket_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LockRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+	that1, ok := that.(*Waiter)
+	if !ok {
+		that2, ok := that.(Waiter)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if interceptor == nil {
-		return srv.(LocketServer).Lock(ctx, in)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/models.Locket/Lock",
+	if this.Owner != that1.Owner {
+		return false
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocketServer).Lock(ctx, req.(*LockRequest))
+	if this.Identity != that1.Identity {
+		return false
 	}
-	return interceptor(ctx, in, info, handler)
+	if this.Priority != that1.Priority {
+		return false
+	}
+	if this.WaitingInMilliseconds != that1.WaitingInMilliseconds {
+		return false
+	}
+	if this.Queued != that1.Queued {
+		return false
+	}
+	return true
 }
-
-func _Locket_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FetchRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (this *WaitersRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	if interceptor == nil {
-		return srv.(LocketServer).Fetch(ctx, in)
+
+	that1, ok := that.(*WaitersRequest)
+	if !ok {
+		that2, ok := that.(WaitersRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/models.Locket/Fetch",
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocketServer).Fetch(ctx, req.(*FetchRequest))
+	if this.Key != that1.Key {
+		return false
 	}
-	return interceptor(ctx, in, info, handler)
+	return true
 }
+func (this *WaitersResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
 
-func _Locket_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReleaseRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+	that1, ok := that.(*WaitersResponse)
+	if !ok {
+		that2, ok := that.(WaitersResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if interceptor == nil {
-		return srv.(LocketServer).Release(ctx, in)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/models.Locket/Release",
+	if len(this.Waiters) != len(that1.Waiters) {
+		return false
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocketServer).Release(ctx, req.(*ReleaseRequest))
+	for i := range this.Waiters {
+		if !this.Waiters[i].Equal(that1.Waiters[i]) {
+			return false
+		}
 	}
-	return interceptor(ctx, in, info, handler)
+	return true
 }
+func (this *KeepAliveRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
 
-func _Locket_FetchAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FetchAllRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+	that1, ok := that.(*KeepAliveRequest)
+	if !ok {
+		that2, ok := that.(KeepAliveRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if interceptor == nil {
-		return srv.(LocketServer).FetchAll(ctx, in)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/models.Locket/FetchAll",
+	if len(this.Locks) != len(that1.Locks) {
+		return false
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LocketServer).FetchAll(ctx, req.(*FetchAllRequest))
+	for i := range this.Locks {
+		if !this.Locks[i].Equal(that1.Locks[i]) {
+			return false
+		}
 	}
-	return interceptor(ctx, in, info, handler)
+	if len(this.Releases) != len(that1.Releases) {
+		return false
+	}
+	for i := range this.Releases {
+		if this.Releases[i] != that1.Releases[i] {
+			return false
+		}
+	}
+	return true
 }
+func (this *Revocation) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
 
-var _Locket_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "models.Locket",
-	HandlerType: (*LocketServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Lock",
-			Handler:    _Locket_Lock_Handler,
-		},
-		{
-			MethodName: "Fetch",
-			Handler:    _Locket_Fetch_Handler,
-		},
-		{
-			MethodName: "Release",
-			Handler:    _Locket_Release_Handler,
-		},
-		{
-			MethodName: "FetchAll",
-			Handler:    _Locket_FetchAll_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "locket.proto",
-}
-
-func (m *Resource) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
-	}
-	return dAtA[:n], nil
-}
-
-func (m *Resource) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Key) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
-		i += copy(dAtA[i:], m.Key)
+	that1, ok := that.(*Revocation)
+	if !ok {
+		that2, ok := that.(Revocation)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if len(m.Owner) > 0 {
-		dAtA[i] = 0x12
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(len(m.Owner)))
-		i += copy(dAtA[i:], m.Owner)
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	if len(m.Value) > 0 {
-		dAtA[i] = 0x1a
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(len(m.Value)))
-		i += copy(dAtA[i:], m.Value)
+	if this.Key != that1.Key {
+		return false
 	}
-	if len(m.Type) > 0 {
-		dAtA[i] = 0x22
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(len(m.Type)))
-		i += copy(dAtA[i:], m.Type)
+	if this.Owner != that1.Owner {
+		return false
 	}
-	if m.TypeCode != 0 {
-		dAtA[i] = 0x28
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(m.TypeCode))
+	if this.Reason != that1.Reason {
+		return false
 	}
-	return i, nil
+	return true
 }
-
-func (m *LockRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+func (this *KeepAliveResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
 	}
-	return dAtA[:n], nil
-}
 
-func (m *LockRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Resource != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
-		n1, err := m.Resource.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+	that1, ok := that.(*KeepAliveResponse)
+	if !ok {
+		that2, ok := that.(KeepAliveResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
 		}
-		i += n1
 	}
-	if m.TtlInSeconds != 0 {
-		dAtA[i] = 0x10
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(m.TtlInSeconds))
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return i, nil
-}
-
-func (m *LockResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if len(this.Revocations) != len(that1.Revocations) {
+		return false
 	}
-	return dAtA[:n], nil
-}
-
-func (m *LockResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
-}
-
-func (m *ReleaseRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	for i := range this.Revocations {
+		if !this.Revocations[i].Equal(that1.Revocations[i]) {
+			return false
+		}
 	}
-	return dAtA[:n], nil
+	return true
 }
-
-func (m *ReleaseRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Resource != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
-		n2, err := m.Resource.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+func (this *BarrierRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
 		}
-		i += n2
+		return false
 	}
-	return i, nil
-}
 
-func (m *ReleaseResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	that1, ok := that.(*BarrierRequest)
+	if !ok {
+		that2, ok := that.(BarrierRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	return dAtA[:n], nil
-}
-
-func (m *ReleaseResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	return i, nil
-}
-
-func (m *FetchRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return dAtA[:n], nil
-}
-
-func (m *FetchRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Key) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
-		i += copy(dAtA[i:], m.Key)
+	if this.Key != that1.Key {
+		return false
 	}
-	return i, nil
-}
-
-func (m *FetchResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	if this.Participant != that1.Participant {
+		return false
 	}
-	return dAtA[:n], nil
+	if this.Participants != that1.Participants {
+		return false
+	}
+	if this.TtlInMilliseconds != that1.TtlInMilliseconds {
+		return false
+	}
+	return true
 }
-
-func (m *FetchResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if m.Resource != nil {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
-		n3, err := m.Resource.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+func (this *BarrierResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
 		}
-		i += n3
+		return false
 	}
-	return i, nil
-}
 
-func (m *FetchAllRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
+	that1, ok := that.(*BarrierResponse)
+	if !ok {
+		that2, ok := that.(BarrierResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	return dAtA[:n], nil
-}
-
-func (m *FetchAllRequest) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Type) > 0 {
-		dAtA[i] = 0xa
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(len(m.Type)))
-		i += copy(dAtA[i:], m.Type)
-	}
-	if m.TypeCode != 0 {
-		dAtA[i] = 0x10
-		i++
-		i = encodeVarintLocket(dAtA, i, uint64(m.TypeCode))
-	}
-	return i, nil
-}
-
-func (m *FetchAllResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
-		return nil, err
-	}
-	return dAtA[:n], nil
-}
-
-func (m *FetchAllResponse) MarshalTo(dAtA []byte) (int, error) {
-	var i int
-	_ = i
-	var l int
-	_ = l
-	if len(m.Resources) > 0 {
-		for _, msg := range m.Resources {
-			dAtA[i] = 0xa
-			i++
-			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
-			n, err := msg.MarshalTo(dAtA[i:])
-			if err != nil {
-				return 0, err
-			}
-			i += n
+	if that1 == nil {
+		if this == nil {
+			return true
 		}
+		return false
+	} else if this == nil {
+		return false
 	}
-	return i, nil
-}
-
-func encodeFixed64Locket(dAtA []byte, offset int, v uint64) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	dAtA[offset+4] = uint8(v >> 32)
-	dAtA[offset+5] = uint8(v >> 40)
-	dAtA[offset+6] = uint8(v >> 48)
-	dAtA[offset+7] = uint8(v >> 56)
-	return offset + 8
-}
-func encodeFixed32Locket(dAtA []byte, offset int, v uint32) int {
-	dAtA[offset] = uint8(v)
-	dAtA[offset+1] = uint8(v >> 8)
-	dAtA[offset+2] = uint8(v >> 16)
-	dAtA[offset+3] = uint8(v >> 24)
-	return offset + 4
-}
-func encodeVarintLocket(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+	if this.Arrived != that1.Arrived {
+		return false
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return true
 }
-func (m *Resource) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Key)
-	if l > 0 {
-		n += 1 + l + sovLocket(uint64(l))
-	}
-	l = len(m.Owner)
-	if l > 0 {
-		n += 1 + l + sovLocket(uint64(l))
+func (this *Resource) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	l = len(m.Value)
-	if l > 0 {
-		n += 1 + l + sovLocket(uint64(l))
+	s := make([]string, 0, 10)
+	s = append(s, "&models.Resource{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
+	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
+	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
+	keysForMetadata := make([]string, 0, len(this.Metadata))
+	for k, _ := range this.Metadata {
+		keysForMetadata = append(keysForMetadata, k)
 	}
-	l = len(m.Type)
-	if l > 0 {
-		n += 1 + l + sovLocket(uint64(l))
+	github_com_gogo_protobuf_sortkeys.Strings(keysForMetadata)
+	mapStringForMetadata := "map[string]string{"
+	for _, k := range keysForMetadata {
+		mapStringForMetadata += fmt.Sprintf("%#v: %#v,", k, this.Metadata[k])
 	}
-	if m.TypeCode != 0 {
-		n += 1 + sovLocket(uint64(m.TypeCode))
+	mapStringForMetadata += "}"
+	if this.Metadata != nil {
+		s = append(s, "Metadata: "+mapStringForMetadata+",\n")
 	}
-	return n
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *LockRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.Resource != nil {
-		l = m.Resource.Size()
-		n += 1 + l + sovLocket(uint64(l))
+func (this *LockRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	if m.TtlInSeconds != 0 {
-		n += 1 + sovLocket(uint64(m.TtlInSeconds))
+	s := make([]string, 0, 15)
+	s = append(s, "&models.LockRequest{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
 	}
-	return n
-}
-
-func (m *LockResponse) Size() (n int) {
-	var l int
-	_ = l
-	return n
+	s = append(s, "TtlInSeconds: "+fmt.Sprintf("%#v", this.TtlInSeconds)+",\n")
+	s = append(s, "TtlInMilliseconds: "+fmt.Sprintf("%#v", this.TtlInMilliseconds)+",\n")
+	s = append(s, "Mode: "+fmt.Sprintf("%#v", this.Mode)+",\n")
+	s = append(s, "Capacity: "+fmt.Sprintf("%#v", this.Capacity)+",\n")
+	s = append(s, "Fair: "+fmt.Sprintf("%#v", this.Fair)+",\n")
+	s = append(s, "Priority: "+fmt.Sprintf("%#v", this.Priority)+",\n")
+	s = append(s, "Preempt: "+fmt.Sprintf("%#v", this.Preempt)+",\n")
+	s = append(s, "RequestId: "+fmt.Sprintf("%#v", this.RequestId)+",\n")
+	s = append(s, "GraceInMilliseconds: "+fmt.Sprintf("%#v", this.GraceInMilliseconds)+",\n")
+	s = append(s, "OnDuplicate: "+fmt.Sprintf("%#v", this.OnDuplicate)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *ReleaseRequest) Size() (n int) {
-	var l int
-	_ = l
-	if m.Resource != nil {
-		l = m.Resource.Size()
-		n += 1 + l + sovLocket(uint64(l))
+func (this *LockResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
-}
-
-func (m *ReleaseResponse) Size() (n int) {
-	var l int
-	_ = l
-	return n
-}
-
-func (m *FetchRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Key)
-	if l > 0 {
-		n += 1 + l + sovLocket(uint64(l))
+	s := make([]string, 0, 9)
+	s = append(s, "&models.LockResponse{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
 	}
-	return n
+	s = append(s, "FencingToken: "+fmt.Sprintf("%#v", this.FencingToken)+",\n")
+	s = append(s, "ExpiresAt: "+fmt.Sprintf("%#v", this.ExpiresAt)+",\n")
+	s = append(s, "Outcome: "+fmt.Sprintf("%#v", this.Outcome)+",\n")
+	s = append(s, "HeartbeatIntervalInMilliseconds: "+fmt.Sprintf("%#v", this.HeartbeatIntervalInMilliseconds)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *FetchResponse) Size() (n int) {
-	var l int
-	_ = l
-	if m.Resource != nil {
-		l = m.Resource.Size()
-		n += 1 + l + sovLocket(uint64(l))
+func (this *LockHolder) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
+	s := make([]string, 0, 9)
+	s = append(s, "&models.LockHolder{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "RemainingTtlInMilliseconds: "+fmt.Sprintf("%#v", this.RemainingTtlInMilliseconds)+",\n")
+	s = append(s, "ExpiresAt: "+fmt.Sprintf("%#v", this.ExpiresAt)+",\n")
+	s = append(s, "HeldForInMilliseconds: "+fmt.Sprintf("%#v", this.HeldForInMilliseconds)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *FetchAllRequest) Size() (n int) {
-	var l int
-	_ = l
-	l = len(m.Type)
-	if l > 0 {
-		n += 1 + l + sovLocket(uint64(l))
+func (this *LockGroupRequest) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	if m.TypeCode != 0 {
-		n += 1 + sovLocket(uint64(m.TypeCode))
+	s := make([]string, 0, 7)
+	s = append(s, "&models.LockGroupRequest{")
+	if this.Resources != nil {
+		s = append(s, "Resources: "+fmt.Sprintf("%#v", this.Resources)+",\n")
 	}
-	return n
+	s = append(s, "TtlInSeconds: "+fmt.Sprintf("%#v", this.TtlInSeconds)+",\n")
+	s = append(s, "TtlInMilliseconds: "+fmt.Sprintf("%#v", this.TtlInMilliseconds)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-
-func (m *FetchAllResponse) Size() (n int) {
-	var l int
-	_ = l
-	if len(m.Resources) > 0 {
-		for _, e := range m.Resources {
-			l = e.Size()
-			n += 1 + l + sovLocket(uint64(l))
-		}
+func (this *LockGroupResponse) GoString() string {
+	if this == nil {
+		return "nil"
 	}
-	return n
-}
-
-func sovLocket(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	s := make([]string, 0, 5)
+	s = append(s, "&models.LockGroupResponse{")
+	if this.Locks != nil {
+		s = append(s, "Locks: "+fmt.Sprintf("%#v", this.Locks)+",\n")
 	}
-	return n
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func sozLocket(x uint64) (n int) {
-	return sovLocket(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+func (this *ReleaseRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&models.ReleaseRequest{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	}
+	s = append(s, "Mode: "+fmt.Sprintf("%#v", this.Mode)+",\n")
+	s = append(s, "ExpectedValue: "+fmt.Sprintf("%#v", this.ExpectedValue)+",\n")
+	s = append(s, "ExpectedFencingToken: "+fmt.Sprintf("%#v", this.ExpectedFencingToken)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *Resource) String() string {
+func (this *ReleaseResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&Resource{`,
-		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
-		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
-		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
-		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
-		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 4)
+	s = append(s, "&models.ReleaseResponse{")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *LockRequest) String() string {
+func (this *FetchRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&LockRequest{`,
-		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
-		`TtlInSeconds:` + fmt.Sprintf("%v", this.TtlInSeconds) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 6)
+	s = append(s, "&models.FetchRequest{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *LockResponse) String() string {
+func (this *FetchResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&LockResponse{`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 5)
+	s = append(s, "&models.FetchResponse{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *ReleaseRequest) String() string {
+func (this *FetchAllRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&ReleaseRequest{`,
-		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 6)
+	s = append(s, "&models.FetchAllRequest{")
+	s = append(s, "Type: "+fmt.Sprintf("%#v", this.Type)+",\n")
+	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *ReleaseResponse) String() string {
+func (this *FetchAllResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&ReleaseResponse{`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 6)
+	s = append(s, "&models.FetchAllResponse{")
+	if this.Resources != nil {
+		s = append(s, "Resources: "+fmt.Sprintf("%#v", this.Resources)+",\n")
+	}
+	if this.Holders != nil {
+		s = append(s, "Holders: "+fmt.Sprintf("%#v", this.Holders)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *FetchRequest) String() string {
+func (this *ContendedKey) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&FetchRequest{`,
-		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 8)
+	s = append(s, "&models.ContendedKey{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "FailedAttempts: "+fmt.Sprintf("%#v", this.FailedAttempts)+",\n")
+	s = append(s, "Waiters: "+fmt.Sprintf("%#v", this.Waiters)+",\n")
+	s = append(s, "OwnershipChanges: "+fmt.Sprintf("%#v", this.OwnershipChanges)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *FetchResponse) String() string {
+func (this *StatsRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&FetchResponse{`,
-		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 5)
+	s = append(s, "&models.StatsRequest{")
+	s = append(s, "TopN: "+fmt.Sprintf("%#v", this.TopN)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *FetchAllRequest) String() string {
+func (this *StatsResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&FetchAllRequest{`,
-		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
-		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
-		`}`,
-	}, "")
-	return s
+	s := make([]string, 0, 5)
+	s = append(s, "&models.StatsResponse{")
+	if this.ContendedKeys != nil {
+		s = append(s, "ContendedKeys: "+fmt.Sprintf("%#v", this.ContendedKeys)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
-func (this *FetchAllResponse) String() string {
+func (this *Waiter) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&models.Waiter{")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "Identity: "+fmt.Sprintf("%#v", this.Identity)+",\n")
+	s = append(s, "Priority: "+fmt.Sprintf("%#v", this.Priority)+",\n")
+	s = append(s, "WaitingInMilliseconds: "+fmt.Sprintf("%#v", this.WaitingInMilliseconds)+",\n")
+	s = append(s, "Queued: "+fmt.Sprintf("%#v", this.Queued)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *WaitersRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&models.WaitersRequest{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *WaitersResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&models.WaitersResponse{")
+	if this.Waiters != nil {
+		s = append(s, "Waiters: "+fmt.Sprintf("%#v", this.Waiters)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *KeepAliveRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&models.KeepAliveRequest{")
+	if this.Locks != nil {
+		s = append(s, "Locks: "+fmt.Sprintf("%#v", this.Locks)+",\n")
+	}
+	if this.Releases != nil {
+		s = append(s, "Releases: "+fmt.Sprintf("%#v", this.Releases)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Revocation) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&models.Revocation{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "Reason: "+fmt.Sprintf("%#v", this.Reason)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *KeepAliveResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&models.KeepAliveResponse{")
+	if this.Revocations != nil {
+		s = append(s, "Revocations: "+fmt.Sprintf("%#v", this.Revocations)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *BarrierRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&models.BarrierRequest{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Participant: "+fmt.Sprintf("%#v", this.Participant)+",\n")
+	s = append(s, "Participants: "+fmt.Sprintf("%#v", this.Participants)+",\n")
+	s = append(s, "TtlInMilliseconds: "+fmt.Sprintf("%#v", this.TtlInMilliseconds)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *BarrierResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := strings.Join([]string{`&FetchAllResponse{`,
-		`Resources:` + strings.Replace(fmt.Sprintf("%v", this.Resources), "Resource", "Resource", 1) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func valueToStringLocket(v interface{}) string {
-	rv := reflect.ValueOf(v)
-	if rv.IsNil() {
-		return "nil"
+	s := make([]string, 0, 5)
+	s = append(s, "&models.BarrierResponse{")
+	s = append(s, "Arrived: "+fmt.Sprintf("%#v", this.Arrived)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringLocket(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Locket service
+
+type LocketClient interface {
+	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
+	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
+	FetchAll(ctx context.Context, in *FetchAllRequest, opts ...grpc.CallOption) (*FetchAllResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	Acquire(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	LockGroup(ctx context.Context, in *LockGroupRequest, opts ...grpc.CallOption) (*LockGroupResponse, error)
+	Waiters(ctx context.Context, in *WaitersRequest, opts ...grpc.CallOption) (*WaitersResponse, error)
+	KeepAlive(ctx context.Context, opts ...grpc.CallOption) (Locket_KeepAliveClient, error)
+	ArriveAtBarrier(ctx context.Context, in *BarrierRequest, opts ...grpc.CallOption) (*BarrierResponse, error)
+}
+
+type locketClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLocketClient(cc *grpc.ClientConn) LocketClient {
+	return &locketClient{cc}
+}
+
+func (c *locketClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	out := new(LockResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/Lock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
+	out := new(FetchResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/Fetch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
+	out := new(ReleaseResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/Release", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) FetchAll(ctx context.Context, in *FetchAllRequest, opts ...grpc.CallOption) (*FetchAllResponse, error) {
+	out := new(FetchAllResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/FetchAll", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	out := new(StatsResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/Stats", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Acquire(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	out := new(LockResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/Acquire", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) LockGroup(ctx context.Context, in *LockGroupRequest, opts ...grpc.CallOption) (*LockGroupResponse, error) {
+	out := new(LockGroupResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/LockGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Waiters(ctx context.Context, in *WaitersRequest, opts ...grpc.CallOption) (*WaitersResponse, error) {
+	out := new(WaitersResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/Waiters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) KeepAlive(ctx context.Context, opts ...grpc.CallOption) (Locket_KeepAliveClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Locket_serviceDesc.Streams[0], c.cc, "/models.Locket/KeepAlive", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &locketKeepAliveClient{stream}
+	return x, nil
+}
+
+type Locket_KeepAliveClient interface {
+	Send(*KeepAliveRequest) error
+	Recv() (*KeepAliveResponse, error)
+	grpc.ClientStream
+}
+
+type locketKeepAliveClient struct {
+	grpc.ClientStream
+}
+
+func (x *locketKeepAliveClient) Send(m *KeepAliveRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *locketKeepAliveClient) Recv() (*KeepAliveResponse, error) {
+	m := new(KeepAliveResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *locketClient) ArriveAtBarrier(ctx context.Context, in *BarrierRequest, opts ...grpc.CallOption) (*BarrierResponse, error) {
+	out := new(BarrierResponse)
+	err := grpc.Invoke(ctx, "/models.Locket/ArriveAtBarrier", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Locket service
+
+type LocketServer interface {
+	Lock(context.Context, *LockRequest) (*LockResponse, error)
+	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
+	Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error)
+	FetchAll(context.Context, *FetchAllRequest) (*FetchAllResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	Acquire(context.Context, *LockRequest) (*LockResponse, error)
+	LockGroup(context.Context, *LockGroupRequest) (*LockGroupResponse, error)
+	Waiters(context.Context, *WaitersRequest) (*WaitersResponse, error)
+	KeepAlive(Locket_KeepAliveServer) error
+	ArriveAtBarrier(context.Context, *BarrierRequest) (*BarrierResponse, error)
+}
+
+func RegisterLocketServer(s *grpc.Server, srv LocketServer) {
+	s.RegisterService(&_Locket_serviceDesc, srv)
+}
+
+func _Locket_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Lock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/Lock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Lock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Fetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/Fetch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Fetch(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/Release",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Release(ctx, req.(*ReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_FetchAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).FetchAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/FetchAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).FetchAll(ctx, req.(*FetchAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Acquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Acquire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/Acquire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Acquire(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_LockGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).LockGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/LockGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).LockGroup(ctx, req.(*LockGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Waiters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Waiters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/Waiters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Waiters(ctx, req.(*WaitersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_KeepAlive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LocketServer).KeepAlive(&locketKeepAliveServer{stream})
+}
+
+type Locket_KeepAliveServer interface {
+	Send(*KeepAliveResponse) error
+	Recv() (*KeepAliveRequest, error)
+	grpc.ServerStream
+}
+
+type locketKeepAliveServer struct {
+	grpc.ServerStream
+}
+
+func (x *locketKeepAliveServer) Send(m *KeepAliveResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *locketKeepAliveServer) Recv() (*KeepAliveRequest, error) {
+	m := new(KeepAliveRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Locket_ArriveAtBarrier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BarrierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).ArriveAtBarrier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/models.Locket/ArriveAtBarrier",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).ArriveAtBarrier(ctx, req.(*BarrierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Locket_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "models.Locket",
+	HandlerType: (*LocketServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lock",
+			Handler:    _Locket_Lock_Handler,
+		},
+		{
+			MethodName: "Fetch",
+			Handler:    _Locket_Fetch_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _Locket_Release_Handler,
+		},
+		{
+			MethodName: "FetchAll",
+			Handler:    _Locket_FetchAll_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Locket_Stats_Handler,
+		},
+		{
+			MethodName: "Acquire",
+			Handler:    _Locket_Acquire_Handler,
+		},
+		{
+			MethodName: "LockGroup",
+			Handler:    _Locket_LockGroup_Handler,
+		},
+		{
+			MethodName: "Waiters",
+			Handler:    _Locket_Waiters_Handler,
+		},
+		{
+			MethodName: "ArriveAtBarrier",
+			Handler:    _Locket_ArriveAtBarrier_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "KeepAlive",
+			Handler:       _Locket_KeepAlive_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "locket.proto",
+}
+
+func (m *Resource) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Resource) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	if len(m.Type) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Type)))
+		i += copy(dAtA[i:], m.Type)
+	}
+	if m.TypeCode != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TypeCode))
+	}
+	if len(m.Metadata) > 0 {
+		for k, _ := range m.Metadata {
+			dAtA[i] = 0x32
+			i++
+			v := m.Metadata[k]
+			mapSize := 1 + len(k) + sovLocket(uint64(len(k))) + 1 + len(v) + sovLocket(uint64(len(v)))
+			i = encodeVarintLocket(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(len(v)))
+			i += copy(dAtA[i:], v)
+		}
+	}
+	return i, nil
+}
+
+func (m *LockRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
+		n1, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	if m.TtlInSeconds != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TtlInSeconds))
+	}
+	if m.TtlInMilliseconds != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TtlInMilliseconds))
+	}
+	if m.Mode != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Mode))
+	}
+	if m.Capacity != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Capacity))
+	}
+	if m.Fair {
+		dAtA[i] = 0x30
+		i++
+		if m.Fair {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Priority != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Priority))
+	}
+	if m.Preempt {
+		dAtA[i] = 0x40
+		i++
+		if m.Preempt {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.RequestId) > 0 {
+		dAtA[i] = 0x4a
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.RequestId)))
+		i += copy(dAtA[i:], m.RequestId)
+	}
+	if m.GraceInMilliseconds != 0 {
+		dAtA[i] = 0x50
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.GraceInMilliseconds))
+	}
+	if m.OnDuplicate != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.OnDuplicate))
+	}
+	return i, nil
+}
+
+func (m *LockResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
+		n2, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	if m.FencingToken != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.FencingToken))
+	}
+	if m.ExpiresAt != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.ExpiresAt))
+	}
+	if m.Outcome != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Outcome))
+	}
+	if m.HeartbeatIntervalInMilliseconds != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.HeartbeatIntervalInMilliseconds))
+	}
+	return i, nil
+}
+
+func (m *LockHolder) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockHolder) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if m.RemainingTtlInMilliseconds != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.RemainingTtlInMilliseconds))
+	}
+	if m.ExpiresAt != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.ExpiresAt))
+	}
+	if m.HeldForInMilliseconds != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.HeldForInMilliseconds))
+	}
+	return i, nil
+}
+
+func (m *LockGroupRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockGroupRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, msg := range m.Resources {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.TtlInSeconds != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TtlInSeconds))
+	}
+	if m.TtlInMilliseconds != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TtlInMilliseconds))
+	}
+	return i, nil
+}
+
+func (m *LockGroupResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockGroupResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Locks) > 0 {
+		for _, msg := range m.Locks {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *ReleaseRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
+		n3, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.Mode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Mode))
+	}
+	if len(m.ExpectedValue) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.ExpectedValue)))
+		i += copy(dAtA[i:], m.ExpectedValue)
+	}
+	if m.ExpectedFencingToken != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.ExpectedFencingToken))
+	}
+	return i, nil
+}
+
+func (m *ReleaseResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *FetchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.TypeCode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TypeCode))
+	}
+	return i, nil
+}
+
+func (m *FetchResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Resource.Size()))
+		n4, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	return i, nil
+}
+
+func (m *FetchAllRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchAllRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Type) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Type)))
+		i += copy(dAtA[i:], m.Type)
+	}
+	if m.TypeCode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TypeCode))
+	}
+	return i, nil
+}
+
+func (m *FetchAllResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchAllResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, msg := range m.Resources {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Holders) > 0 {
+		for _, msg := range m.Holders {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *ContendedKey) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ContendedKey) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.FailedAttempts != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.FailedAttempts))
+	}
+	if m.Waiters != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Waiters))
+	}
+	if m.OwnershipChanges != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.OwnershipChanges))
+	}
+	return i, nil
+}
+
+func (m *StatsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StatsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.TopN != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TopN))
+	}
+	return i, nil
+}
+
+func (m *StatsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StatsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.ContendedKeys) > 0 {
+		for _, msg := range m.ContendedKeys {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *Waiter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Waiter) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if len(m.Identity) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Identity)))
+		i += copy(dAtA[i:], m.Identity)
+	}
+	if m.Priority != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Priority))
+	}
+	if m.WaitingInMilliseconds != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.WaitingInMilliseconds))
+	}
+	if m.Queued {
+		dAtA[i] = 0x28
+		i++
+		if m.Queued {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *WaitersRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WaitersRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	return i, nil
+}
+
+func (m *WaitersResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WaitersResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Waiters) > 0 {
+		for _, msg := range m.Waiters {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *KeepAliveRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeepAliveRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Locks) > 0 {
+		for _, msg := range m.Locks {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Releases) > 0 {
+		for _, s := range m.Releases {
+			dAtA[i] = 0x12
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	return i, nil
+}
+
+func (m *Revocation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Revocation) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if len(m.Reason) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Reason)))
+		i += copy(dAtA[i:], m.Reason)
+	}
+	return i, nil
+}
+
+func (m *KeepAliveResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *KeepAliveResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Revocations) > 0 {
+		for _, msg := range m.Revocations {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocket(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *BarrierRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BarrierRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Participant) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(len(m.Participant)))
+		i += copy(dAtA[i:], m.Participant)
+	}
+	if m.Participants != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Participants))
+	}
+	if m.TtlInMilliseconds != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.TtlInMilliseconds))
+	}
+	return i, nil
+}
+
+func (m *BarrierResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BarrierResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Arrived != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintLocket(dAtA, i, uint64(m.Arrived))
+	}
+	return i, nil
+}
+
+func encodeFixed64Locket(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32Locket(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintLocket(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *Resource) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Type)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.TypeCode != 0 {
+		n += 1 + sovLocket(uint64(m.TypeCode))
+	}
+	if len(m.Metadata) > 0 {
+		for k, v := range m.Metadata {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovLocket(uint64(len(k))) + 1 + len(v) + sovLocket(uint64(len(v)))
+			n += mapEntrySize + 1 + sovLocket(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *LockRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.TtlInSeconds != 0 {
+		n += 1 + sovLocket(uint64(m.TtlInSeconds))
+	}
+	if m.TtlInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.TtlInMilliseconds))
+	}
+	if m.Mode != 0 {
+		n += 1 + sovLocket(uint64(m.Mode))
+	}
+	if m.Capacity != 0 {
+		n += 1 + sovLocket(uint64(m.Capacity))
+	}
+	if m.Fair {
+		n += 2
+	}
+	if m.Priority != 0 {
+		n += 1 + sovLocket(uint64(m.Priority))
+	}
+	if m.Preempt {
+		n += 2
+	}
+	l = len(m.RequestId)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.GraceInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.GraceInMilliseconds))
+	}
+	if m.OnDuplicate != 0 {
+		n += 1 + sovLocket(uint64(m.OnDuplicate))
+	}
+	return n
+}
+
+func (m *LockResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.FencingToken != 0 {
+		n += 1 + sovLocket(uint64(m.FencingToken))
+	}
+	if m.ExpiresAt != 0 {
+		n += 1 + sovLocket(uint64(m.ExpiresAt))
+	}
+	if m.Outcome != 0 {
+		n += 1 + sovLocket(uint64(m.Outcome))
+	}
+	if m.HeartbeatIntervalInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.HeartbeatIntervalInMilliseconds))
+	}
+	return n
+}
+
+func (m *LockHolder) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.RemainingTtlInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.RemainingTtlInMilliseconds))
+	}
+	if m.ExpiresAt != 0 {
+		n += 1 + sovLocket(uint64(m.ExpiresAt))
+	}
+	if m.HeldForInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.HeldForInMilliseconds))
+	}
+	return n
+}
+
+func (m *LockGroupRequest) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, e := range m.Resources {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	if m.TtlInSeconds != 0 {
+		n += 1 + sovLocket(uint64(m.TtlInSeconds))
+	}
+	if m.TtlInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.TtlInMilliseconds))
+	}
+	return n
+}
+
+func (m *LockGroupResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Locks) > 0 {
+		for _, e := range m.Locks {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ReleaseRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.Mode != 0 {
+		n += 1 + sovLocket(uint64(m.Mode))
+	}
+	l = len(m.ExpectedValue)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.ExpectedFencingToken != 0 {
+		n += 1 + sovLocket(uint64(m.ExpectedFencingToken))
+	}
+	return n
+}
+
+func (m *ReleaseResponse) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *FetchRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.TypeCode != 0 {
+		n += 1 + sovLocket(uint64(m.TypeCode))
+	}
+	return n
+}
+
+func (m *FetchResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	return n
+}
+
+func (m *FetchAllRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Type)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.TypeCode != 0 {
+		n += 1 + sovLocket(uint64(m.TypeCode))
+	}
+	return n
+}
+
+func (m *FetchAllResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, e := range m.Resources {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	if len(m.Holders) > 0 {
+		for _, e := range m.Holders {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ContendedKey) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.FailedAttempts != 0 {
+		n += 1 + sovLocket(uint64(m.FailedAttempts))
+	}
+	if m.Waiters != 0 {
+		n += 1 + sovLocket(uint64(m.Waiters))
+	}
+	if m.OwnershipChanges != 0 {
+		n += 1 + sovLocket(uint64(m.OwnershipChanges))
+	}
+	return n
+}
+
+func (m *StatsRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.TopN != 0 {
+		n += 1 + sovLocket(uint64(m.TopN))
+	}
+	return n
+}
+
+func (m *StatsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.ContendedKeys) > 0 {
+		for _, e := range m.ContendedKeys {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Waiter) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Identity)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.Priority != 0 {
+		n += 1 + sovLocket(uint64(m.Priority))
+	}
+	if m.WaitingInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.WaitingInMilliseconds))
+	}
+	if m.Queued {
+		n += 2
+	}
+	return n
+}
+
+func (m *WaitersRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	return n
+}
+
+func (m *WaitersResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Waiters) > 0 {
+		for _, e := range m.Waiters {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *KeepAliveRequest) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Locks) > 0 {
+		for _, e := range m.Locks {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	if len(m.Releases) > 0 {
+		for _, s := range m.Releases {
+			l = len(s)
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *Revocation) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	return n
+}
+
+func (m *KeepAliveResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Revocations) > 0 {
+		for _, e := range m.Revocations {
+			l = e.Size()
+			n += 1 + l + sovLocket(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *BarrierRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	l = len(m.Participant)
+	if l > 0 {
+		n += 1 + l + sovLocket(uint64(l))
+	}
+	if m.Participants != 0 {
+		n += 1 + sovLocket(uint64(m.Participants))
+	}
+	if m.TtlInMilliseconds != 0 {
+		n += 1 + sovLocket(uint64(m.TtlInMilliseconds))
+	}
+	return n
+}
+
+func (m *BarrierResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Arrived != 0 {
+		n += 1 + sovLocket(uint64(m.Arrived))
+	}
+	return n
+}
+
+func sovLocket(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozLocket(x uint64) (n int) {
+	return sovLocket(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Resource) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForMetadata := make([]string, 0, len(this.Metadata))
+	for k, _ := range this.Metadata {
+		keysForMetadata = append(keysForMetadata, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForMetadata)
+	mapStringForMetadata := "map[string]string{"
+	for _, k := range keysForMetadata {
+		mapStringForMetadata += fmt.Sprintf("%v: %v,", k, this.Metadata[k])
+	}
+	mapStringForMetadata += "}"
+	s := strings.Join([]string{`&Resource{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
+		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
+		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
+		`Metadata:` + mapStringForMetadata + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockRequest{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`TtlInSeconds:` + fmt.Sprintf("%v", this.TtlInSeconds) + `,`,
+		`TtlInMilliseconds:` + fmt.Sprintf("%v", this.TtlInMilliseconds) + `,`,
+		`Mode:` + fmt.Sprintf("%v", this.Mode) + `,`,
+		`Capacity:` + fmt.Sprintf("%v", this.Capacity) + `,`,
+		`Fair:` + fmt.Sprintf("%v", this.Fair) + `,`,
+		`Priority:` + fmt.Sprintf("%v", this.Priority) + `,`,
+		`Preempt:` + fmt.Sprintf("%v", this.Preempt) + `,`,
+		`RequestId:` + fmt.Sprintf("%v", this.RequestId) + `,`,
+		`GraceInMilliseconds:` + fmt.Sprintf("%v", this.GraceInMilliseconds) + `,`,
+		`OnDuplicate:` + fmt.Sprintf("%v", this.OnDuplicate) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockResponse{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`FencingToken:` + fmt.Sprintf("%v", this.FencingToken) + `,`,
+		`ExpiresAt:` + fmt.Sprintf("%v", this.ExpiresAt) + `,`,
+		`Outcome:` + fmt.Sprintf("%v", this.Outcome) + `,`,
+		`HeartbeatIntervalInMilliseconds:` + fmt.Sprintf("%v", this.HeartbeatIntervalInMilliseconds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockHolder) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockHolder{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`RemainingTtlInMilliseconds:` + fmt.Sprintf("%v", this.RemainingTtlInMilliseconds) + `,`,
+		`ExpiresAt:` + fmt.Sprintf("%v", this.ExpiresAt) + `,`,
+		`HeldForInMilliseconds:` + fmt.Sprintf("%v", this.HeldForInMilliseconds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockGroupRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockGroupRequest{`,
+		`Resources:` + strings.Replace(fmt.Sprintf("%v", this.Resources), "Resource", "Resource", 1) + `,`,
+		`TtlInSeconds:` + fmt.Sprintf("%v", this.TtlInSeconds) + `,`,
+		`TtlInMilliseconds:` + fmt.Sprintf("%v", this.TtlInMilliseconds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockGroupResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockGroupResponse{`,
+		`Locks:` + strings.Replace(fmt.Sprintf("%v", this.Locks), "LockResponse", "LockResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReleaseRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReleaseRequest{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`Mode:` + fmt.Sprintf("%v", this.Mode) + `,`,
+		`ExpectedValue:` + fmt.Sprintf("%v", this.ExpectedValue) + `,`,
+		`ExpectedFencingToken:` + fmt.Sprintf("%v", this.ExpectedFencingToken) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReleaseResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReleaseResponse{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchRequest{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchResponse{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchAllRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchAllRequest{`,
+		`Type:` + fmt.Sprintf("%v", this.Type) + `,`,
+		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchAllResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchAllResponse{`,
+		`Resources:` + strings.Replace(fmt.Sprintf("%v", this.Resources), "Resource", "Resource", 1) + `,`,
+		`Holders:` + strings.Replace(fmt.Sprintf("%v", this.Holders), "LockHolder", "LockHolder", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ContendedKey) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ContendedKey{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`FailedAttempts:` + fmt.Sprintf("%v", this.FailedAttempts) + `,`,
+		`Waiters:` + fmt.Sprintf("%v", this.Waiters) + `,`,
+		`OwnershipChanges:` + fmt.Sprintf("%v", this.OwnershipChanges) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *StatsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&StatsRequest{`,
+		`TopN:` + fmt.Sprintf("%v", this.TopN) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *StatsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&StatsResponse{`,
+		`ContendedKeys:` + strings.Replace(fmt.Sprintf("%v", this.ContendedKeys), "ContendedKey", "ContendedKey", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Waiter) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Waiter{`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`Identity:` + fmt.Sprintf("%v", this.Identity) + `,`,
+		`Priority:` + fmt.Sprintf("%v", this.Priority) + `,`,
+		`WaitingInMilliseconds:` + fmt.Sprintf("%v", this.WaitingInMilliseconds) + `,`,
+		`Queued:` + fmt.Sprintf("%v", this.Queued) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *WaitersRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&WaitersRequest{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *WaitersResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&WaitersResponse{`,
+		`Waiters:` + strings.Replace(fmt.Sprintf("%v", this.Waiters), "Waiter", "Waiter", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *KeepAliveRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&KeepAliveRequest{`,
+		`Locks:` + strings.Replace(fmt.Sprintf("%v", this.Locks), "LockRequest", "LockRequest", 1) + `,`,
+		`Releases:` + fmt.Sprintf("%v", this.Releases) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Revocation) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Revocation{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`Reason:` + fmt.Sprintf("%v", this.Reason) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *KeepAliveResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&KeepAliveResponse{`,
+		`Revocations:` + strings.Replace(fmt.Sprintf("%v", this.Revocations), "Revocation", "Revocation", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *BarrierRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&BarrierRequest{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Participant:` + fmt.Sprintf("%v", this.Participant) + `,`,
+		`Participants:` + fmt.Sprintf("%v", this.Participants) + `,`,
+		`TtlInMilliseconds:` + fmt.Sprintf("%v", this.TtlInMilliseconds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *BarrierResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&BarrierResponse{`,
+		`Arrived:` + fmt.Sprintf("%v", this.Arrived) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringLocket(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Resource) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Resource: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Resource: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Type = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+			}
+			m.TypeCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var keykey uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				keykey |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			var stringLenmapkey uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLenmapkey |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLenmapkey := int(stringLenmapkey)
+			if intStringLenmapkey < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postStringIndexmapkey := iNdEx + intStringLenmapkey
+			if postStringIndexmapkey > l {
+				return io.ErrUnexpectedEOF
+			}
+			mapkey := string(dAtA[iNdEx:postStringIndexmapkey])
+			iNdEx = postStringIndexmapkey
+			if m.Metadata == nil {
+				m.Metadata = make(map[string]string)
+			}
+			if iNdEx < postIndex {
+				var valuekey uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowLocket
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					valuekey |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				var stringLenmapvalue uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowLocket
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				intStringLenmapvalue := int(stringLenmapvalue)
+				if intStringLenmapvalue < 0 {
+					return ErrInvalidLengthLocket
+				}
+				postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+				if postStringIndexmapvalue > l {
+					return io.ErrUnexpectedEOF
+				}
+				mapvalue := string(dAtA[iNdEx:postStringIndexmapvalue])
+				iNdEx = postStringIndexmapvalue
+				m.Metadata[mapkey] = mapvalue
+			} else {
+				var mapvalue string
+				m.Metadata[mapkey] = mapvalue
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInSeconds", wireType)
+			}
+			m.TtlInSeconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInSeconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInMilliseconds", wireType)
+			}
+			m.TtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mode", wireType)
+			}
+			m.Mode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Mode |= (LockMode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+			}
+			m.Capacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Capacity |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fair", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Fair = bool(v != 0)
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Preempt", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Preempt = bool(v != 0)
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RequestId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GraceInMilliseconds", wireType)
+			}
+			m.GraceInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GraceInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OnDuplicate", wireType)
+			}
+			m.OnDuplicate = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OnDuplicate |= (DuplicatePolicy(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FencingToken", wireType)
+			}
+			m.FencingToken = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FencingToken |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAt", wireType)
+			}
+			m.ExpiresAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpiresAt |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Outcome", wireType)
+			}
+			m.Outcome = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Outcome |= (LockOutcome(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeartbeatIntervalInMilliseconds", wireType)
+			}
+			m.HeartbeatIntervalInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.HeartbeatIntervalInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockHolder) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockHolder: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockHolder: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RemainingTtlInMilliseconds", wireType)
+			}
+			m.RemainingTtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RemainingTtlInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAt", wireType)
+			}
+			m.ExpiresAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpiresAt |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeldForInMilliseconds", wireType)
+			}
+			m.HeldForInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.HeldForInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockGroupRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockGroupRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockGroupRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resources", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Resources = append(m.Resources, &Resource{})
+			if err := m.Resources[len(m.Resources)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInSeconds", wireType)
+			}
+			m.TtlInSeconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInSeconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInMilliseconds", wireType)
+			}
+			m.TtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockGroupResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockGroupResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockGroupResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Locks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Locks = append(m.Locks, &LockResponse{})
+			if err := m.Locks[len(m.Locks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReleaseRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReleaseRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReleaseRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mode", wireType)
+			}
+			m.Mode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Mode |= (LockMode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectedValue", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectedValue = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectedFencingToken", wireType)
+			}
+			m.ExpectedFencingToken = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpectedFencingToken |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReleaseResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReleaseResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReleaseResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+			}
+			m.TypeCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchAllRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchAllRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Type = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+			}
+			m.TypeCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchAllResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchAllResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchAllResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resources", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Resources = append(m.Resources, &Resource{})
+			if err := m.Resources[len(m.Resources)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Holders", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Holders = append(m.Holders, &LockHolder{})
+			if err := m.Holders[len(m.Holders)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	pv := reflect.Indirect(rv).Interface()
-	return fmt.Sprintf("*%v", pv)
+	return nil
 }
-func (m *Resource) Unmarshal(dAtA []byte) error {
+func (m *ContendedKey) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1349,10 +5803,10 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Resource: wiretype end group for non-group")
+			return fmt.Errorf("proto: ContendedKey: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Resource: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ContendedKey: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1385,10 +5839,10 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 			m.Key = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FailedAttempts", wireType)
 			}
-			var stringLen uint64
+			m.FailedAttempts = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -1398,26 +5852,16 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.FailedAttempts |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthLocket
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Owner = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Waiters", wireType)
 			}
-			var stringLen uint64
+			m.Waiters = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -1427,26 +5871,16 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.Waiters |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthLocket
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Value = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OwnershipChanges", wireType)
 			}
-			var stringLen uint64
+			m.OwnershipChanges = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -1456,26 +5890,66 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= (uint64(b) & 0x7F) << shift
+				m.OwnershipChanges |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthLocket
 			}
-			postIndex := iNdEx + intStringLen
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Type = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 5:
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StatsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StatsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StatsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TopN", wireType)
 			}
-			m.TypeCode = 0
+			m.TopN = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -1485,7 +5959,7 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				m.TopN |= (int32(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -1511,7 +5985,7 @@ func (m *Resource) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LockRequest) Unmarshal(dAtA []byte) error {
+func (m *StatsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1534,15 +6008,15 @@ func (m *LockRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LockRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: StatsResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LockRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: StatsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ContendedKeys", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1566,18 +6040,162 @@ func (m *LockRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Resource == nil {
-				m.Resource = &Resource{}
+			m.ContendedKeys = append(m.ContendedKeys, &ContendedKey{})
+			if err := m.ContendedKeys[len(m.ContendedKeys)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
 			}
-			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocket(dAtA[iNdEx:])
+			if err != nil {
 				return err
 			}
-			iNdEx = postIndex
-		case 2:
+			if skippy < 0 {
+				return ErrInvalidLengthLocket
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Waiter) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocket
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Waiter: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Waiter: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Identity", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Identity = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WaitingInMilliseconds", wireType)
+			}
+			m.WaitingInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.WaitingInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TtlInSeconds", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Queued", wireType)
 			}
-			m.TtlInSeconds = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -1587,11 +6205,12 @@ func (m *LockRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.TtlInSeconds |= (int64(b) & 0x7F) << shift
+				v |= (int(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			m.Queued = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLocket(dAtA[iNdEx:])
@@ -1613,7 +6232,7 @@ func (m *LockRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LockResponse) Unmarshal(dAtA []byte) error {
+func (m *WaitersRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1636,12 +6255,41 @@ func (m *LockResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LockResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: WaitersRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LockResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: WaitersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLocket(dAtA[iNdEx:])
@@ -1663,7 +6311,7 @@ func (m *LockResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ReleaseRequest) Unmarshal(dAtA []byte) error {
+func (m *WaitersResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1686,15 +6334,15 @@ func (m *ReleaseRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ReleaseRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: WaitersResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ReleaseRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: WaitersResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Waiters", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1718,10 +6366,8 @@ func (m *ReleaseRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Resource == nil {
-				m.Resource = &Resource{}
-			}
-			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Waiters = append(m.Waiters, &Waiter{})
+			if err := m.Waiters[len(m.Waiters)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1746,7 +6392,7 @@ func (m *ReleaseRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *ReleaseResponse) Unmarshal(dAtA []byte) error {
+func (m *KeepAliveRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1769,12 +6415,72 @@ func (m *ReleaseResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: ReleaseResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: KeepAliveRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ReleaseResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: KeepAliveRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Locks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Locks = append(m.Locks, &LockRequest{})
+			if err := m.Locks[len(m.Locks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Releases", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Releases = append(m.Releases, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLocket(dAtA[iNdEx:])
@@ -1796,7 +6502,7 @@ func (m *ReleaseResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchRequest) Unmarshal(dAtA []byte) error {
+func (m *Revocation) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1819,10 +6525,10 @@ func (m *FetchRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: Revocation: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Revocation: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -1854,6 +6560,64 @@ func (m *FetchRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Key = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLocket(dAtA[iNdEx:])
@@ -1875,7 +6639,7 @@ func (m *FetchRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchResponse) Unmarshal(dAtA []byte) error {
+func (m *KeepAliveResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1898,15 +6662,15 @@ func (m *FetchResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: KeepAliveResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: KeepAliveResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Revocations", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1930,10 +6694,8 @@ func (m *FetchResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Resource == nil {
-				m.Resource = &Resource{}
-			}
-			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			m.Revocations = append(m.Revocations, &Revocation{})
+			if err := m.Revocations[len(m.Revocations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1958,7 +6720,7 @@ func (m *FetchResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
+func (m *BarrierRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1981,15 +6743,15 @@ func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchAllRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: BarrierRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchAllRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BarrierRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -2014,13 +6776,42 @@ func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Type = string(dAtA[iNdEx:postIndex])
+			m.Key = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Participant", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocket
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Participant = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Participants", wireType)
 			}
-			m.TypeCode = 0
+			m.Participants = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -2030,7 +6821,26 @@ func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				m.Participants |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInMilliseconds", wireType)
+			}
+			m.TtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocket
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInMilliseconds |= (int64(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -2056,7 +6866,7 @@ func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *FetchAllResponse) Unmarshal(dAtA []byte) error {
+func (m *BarrierResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2079,17 +6889,17 @@ func (m *FetchAllResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: FetchAllResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: BarrierResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: FetchAllResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: BarrierResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Resources", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Arrived", wireType)
 			}
-			var msglen int
+			m.Arrived = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowLocket
@@ -2099,23 +6909,11 @@ func (m *FetchAllResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= (int(b) & 0x7F) << shift
+				m.Arrived |= (int32(b) & 0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthLocket
-			}
-			postIndex := iNdEx + msglen
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Resources = append(m.Resources, &Resource{})
-			if err := m.Resources[len(m.Resources)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLocket(dAtA[iNdEx:])
@@ -2245,36 +7043,98 @@ var (
 func init() { proto.RegisterFile("locket.proto", fileDescriptorLocket) }
 
 var fileDescriptorLocket = []byte{
-	// 483 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x53, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xf6, 0xe6, 0xaf, 0xce, 0xc4, 0xa4, 0x66, 0x29, 0xad, 0x95, 0xc3, 0x2a, 0xb2, 0x38, 0x54,
-	0xa8, 0x04, 0xa9, 0x95, 0x38, 0xf1, 0x23, 0x12, 0x05, 0x09, 0x35, 0x4a, 0xd1, 0x16, 0x04, 0xb7,
-	0x28, 0x38, 0x23, 0x81, 0x62, 0xbc, 0x21, 0xbb, 0x01, 0xe5, 0xc6, 0x1b, 0xc0, 0x63, 0xf0, 0x28,
-	0x1c, 0x7b, 0xe4, 0x48, 0xcc, 0x85, 0x63, 0x0f, 0x3c, 0x00, 0xf2, 0xda, 0x6b, 0xa7, 0x04, 0x81,
-	0xe8, 0xc9, 0x33, 0x9f, 0xe7, 0x9b, 0xef, 0xf3, 0xcc, 0x18, 0x9c, 0x50, 0x04, 0x53, 0x54, 0x9d,
-	0xd9, 0x5c, 0x28, 0x41, 0x6b, 0x6f, 0xc4, 0x04, 0x43, 0xe9, 0x7f, 0x24, 0x60, 0x73, 0x94, 0x62,
-	0x31, 0x0f, 0x90, 0xba, 0x50, 0x9e, 0xe2, 0xd2, 0x23, 0x6d, 0xb2, 0x5f, 0xe7, 0x49, 0x48, 0x77,
-	0xa0, 0x2a, 0xde, 0x47, 0x38, 0xf7, 0x4a, 0x1a, 0x4b, 0x93, 0x04, 0x7d, 0x37, 0x0e, 0x17, 0xe8,
-	0x95, 0x53, 0x54, 0x27, 0x74, 0x17, 0x2a, 0x6a, 0x39, 0x43, 0xaf, 0x92, 0x80, 0xdd, 0x92, 0x47,
-	0xb8, 0xce, 0xe9, 0x2d, 0xa8, 0x27, 0xcf, 0x51, 0x20, 0x26, 0xe8, 0x55, 0xdb, 0x64, 0xbf, 0x79,
-	0xe8, 0x76, 0x52, 0xf9, 0xce, 0xd3, 0xe5, 0x0c, 0x7b, 0x62, 0x82, 0xdc, 0x56, 0x59, 0xe4, 0x8f,
-	0xa1, 0x31, 0x10, 0xc1, 0x94, 0xe3, 0xdb, 0x05, 0x4a, 0x45, 0x0f, 0xc0, 0x9e, 0x67, 0xfe, 0xb4,
-	0xb1, 0x46, 0x41, 0x36, 0xbe, 0x79, 0x5e, 0x41, 0x6f, 0x40, 0x53, 0xa9, 0x70, 0xf4, 0x3a, 0x1a,
-	0x49, 0x0c, 0x44, 0x34, 0x91, 0xda, 0x78, 0x99, 0x3b, 0x4a, 0x85, 0x8f, 0xa3, 0xd3, 0x14, 0xf3,
-	0x9b, 0xe0, 0xa4, 0x12, 0x72, 0x26, 0x22, 0x89, 0xfe, 0x7d, 0x68, 0x72, 0x0c, 0x71, 0x2c, 0xf1,
-	0x52, 0xaa, 0xfe, 0x55, 0xd8, 0xce, 0xf9, 0x59, 0xcb, 0x36, 0x38, 0x8f, 0x50, 0x05, 0xaf, 0x4c,
-	0xc3, 0x8d, 0xd1, 0xfa, 0xf7, 0xe0, 0x4a, 0x56, 0x91, 0x52, 0xfe, 0x53, 0xf3, 0x05, 0x6c, 0x6b,
-	0xfa, 0xc3, 0x30, 0x34, 0x1a, 0x66, 0x01, 0xe4, 0x6f, 0x0b, 0x28, 0xfd, 0x73, 0x01, 0x5d, 0x70,
-	0x8b, 0xce, 0x99, 0xb7, 0x0e, 0xd4, 0x8d, 0xb2, 0xf4, 0x48, 0xbb, 0xfc, 0x47, 0x73, 0x45, 0xc9,
-	0xcd, 0xdb, 0x60, 0x9b, 0xce, 0xb4, 0x01, 0x5b, 0xcf, 0x86, 0xc7, 0xc3, 0x93, 0xe7, 0x43, 0xd7,
-	0xa2, 0x36, 0x54, 0x06, 0x27, 0xbd, 0x63, 0x97, 0x50, 0x07, 0xec, 0x27, 0xbc, 0x7f, 0xda, 0x1f,
-	0xf6, 0xfa, 0x6e, 0xe9, 0xf0, 0x27, 0x81, 0xda, 0x40, 0x1f, 0x28, 0x3d, 0x82, 0x4a, 0x12, 0xd1,
-	0x6b, 0x46, 0x60, 0xed, 0x1c, 0x5a, 0x3b, 0x17, 0xc1, 0x6c, 0xda, 0x16, 0xbd, 0x03, 0x55, 0x6d,
-	0x9a, 0xe6, 0x05, 0xeb, 0xe3, 0x6f, 0x5d, 0xff, 0x0d, 0xcd, 0x79, 0x77, 0x61, 0x2b, 0x5b, 0x1d,
-	0xdd, 0x2d, 0x3e, 0x68, 0xfd, 0x16, 0x5a, 0x7b, 0x1b, 0x78, 0xce, 0x7e, 0x00, 0xb6, 0x19, 0x15,
-	0xdd, 0xbb, 0x20, 0x51, 0xac, 0xa5, 0xe5, 0x6d, 0xbe, 0x30, 0x0d, 0xba, 0x07, 0x67, 0x2b, 0x66,
-	0x7d, 0x5d, 0x31, 0xeb, 0x7c, 0xc5, 0xc8, 0x87, 0x98, 0x91, 0xcf, 0x31, 0x23, 0x5f, 0x62, 0x46,
-	0xce, 0x62, 0x46, 0xbe, 0xc5, 0x8c, 0xfc, 0x88, 0x99, 0x75, 0x1e, 0x33, 0xf2, 0xe9, 0x3b, 0xb3,
-	0x5e, 0xd6, 0xf4, 0xbf, 0x7b, 0xf4, 0x2b, 0x00, 0x00, 0xff, 0xff, 0x6c, 0x9c, 0x36, 0x84, 0xcb,
-	0x03, 0x00, 0x00,
+	// 1484 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb5, 0x57, 0xcb, 0x73, 0xdb, 0x44,
+	0x18, 0xb7, 0xfc, 0x88, 0xed, 0xcf, 0xcf, 0x6c, 0x5e, 0xc2, 0x33, 0x0d, 0x45, 0x85, 0x21, 0xa4,
+	0x25, 0x30, 0x69, 0x07, 0x98, 0xb6, 0x4c, 0xc7, 0x71, 0x1c, 0x62, 0xe2, 0xd8, 0x41, 0x49, 0x9a,
+	0x72, 0xd2, 0xa8, 0xf2, 0xb6, 0xd1, 0x54, 0x91, 0x54, 0x79, 0x9d, 0x92, 0x1b, 0x47, 0x8e, 0x3d,
+	0xf2, 0x17, 0x30, 0x1c, 0xf8, 0x27, 0xb8, 0x71, 0xa3, 0x47, 0x8e, 0x14, 0x2e, 0x1c, 0x39, 0x70,
+	0xe4, 0xc0, 0xee, 0x6a, 0x57, 0x92, 0xed, 0xa4, 0xaf, 0x19, 0x0e, 0x1a, 0xeb, 0x7b, 0xac, 0xbe,
+	0xf7, 0xef, 0x5b, 0x43, 0xd9, 0xf1, 0xac, 0x47, 0x98, 0xac, 0xf9, 0x81, 0x47, 0x3c, 0x34, 0x73,
+	0xe2, 0x0d, 0xb0, 0x33, 0xd4, 0xbe, 0x4b, 0x43, 0x41, 0xc7, 0x43, 0x6f, 0x14, 0x58, 0x18, 0xd5,
+	0x21, 0xf3, 0x08, 0x9f, 0xa9, 0xca, 0x65, 0x65, 0xa5, 0xa8, 0xb3, 0x57, 0x34, 0x0f, 0x39, 0xef,
+	0x89, 0x8b, 0x03, 0x35, 0xcd, 0x79, 0x21, 0xc1, 0xb8, 0xa7, 0xa6, 0x33, 0xc2, 0x6a, 0x26, 0xe4,
+	0x72, 0x02, 0x2d, 0x42, 0x96, 0x9c, 0xf9, 0x58, 0xcd, 0x32, 0xe6, 0x46, 0x5a, 0x55, 0x74, 0x4e,
+	0xa3, 0x0f, 0xa1, 0xc8, 0x7e, 0x0d, 0x8b, 0x5a, 0x54, 0x73, 0x54, 0x58, 0x5d, 0xaf, 0xaf, 0x85,
+	0xe6, 0xd7, 0x0e, 0xa8, 0xa0, 0x45, 0x5f, 0xf5, 0x02, 0x11, 0x6f, 0xe8, 0x26, 0x14, 0x4e, 0x30,
+	0x31, 0x07, 0x26, 0x31, 0xd5, 0x99, 0xcb, 0x99, 0x95, 0xd2, 0xfa, 0xb2, 0xd4, 0x96, 0x8e, 0xae,
+	0xed, 0x0a, 0x85, 0xb6, 0x4b, 0x82, 0x33, 0x3d, 0xd2, 0x6f, 0xdc, 0x82, 0xca, 0x98, 0xe8, 0xfc,
+	0x88, 0x42, 0xdf, 0xd3, 0x09, 0xdf, 0x6f, 0xa6, 0x3f, 0x53, 0xb4, 0x9f, 0x32, 0x50, 0xea, 0xd2,
+	0x1c, 0xe9, 0xf8, 0xf1, 0x08, 0x0f, 0x09, 0xba, 0x06, 0x85, 0x40, 0x18, 0xe4, 0x1f, 0x28, 0xc5,
+	0x6e, 0x4b, 0x47, 0xf4, 0x48, 0x03, 0xbd, 0x0b, 0x55, 0x42, 0x1c, 0xc3, 0x76, 0x8d, 0x21, 0xb6,
+	0x3c, 0x77, 0x30, 0xe4, 0x06, 0x32, 0x7a, 0x99, 0x72, 0x3b, 0xee, 0x7e, 0xc8, 0x43, 0x6b, 0x30,
+	0x27, 0xb4, 0x4e, 0x6c, 0xc7, 0xb1, 0xa5, 0x6a, 0x86, 0xab, 0xce, 0x72, 0xd5, 0xdd, 0x84, 0x80,
+	0x7e, 0x35, 0xcb, 0x4c, 0xf2, 0x9c, 0x26, 0xd2, 0xc6, 0xdc, 0xdc, 0x65, 0x69, 0xe3, 0x52, 0xd4,
+	0x80, 0x82, 0x65, 0xfa, 0xa6, 0x65, 0x93, 0x33, 0x9e, 0xe0, 0x9c, 0x1e, 0xd1, 0x08, 0x41, 0xf6,
+	0x81, 0x69, 0x07, 0x34, 0x95, 0xca, 0x4a, 0x41, 0xe7, 0xef, 0x4c, 0xdf, 0x0f, 0x6c, 0x2f, 0x60,
+	0xfa, 0xf9, 0x50, 0x5f, 0xd2, 0x48, 0x85, 0xbc, 0x1f, 0x60, 0x7c, 0xe2, 0x13, 0xb5, 0xc0, 0x8f,
+	0x48, 0x12, 0x5d, 0x02, 0x08, 0xc2, 0xd4, 0x18, 0xf6, 0x40, 0x2d, 0xf2, 0xf4, 0x15, 0x05, 0xa7,
+	0x33, 0x40, 0xeb, 0xb0, 0xf0, 0x30, 0x30, 0x2d, 0x3c, 0x15, 0x1c, 0xf0, 0xe0, 0xe6, 0xb8, 0x70,
+	0x22, 0xbc, 0x9b, 0x50, 0xf6, 0x5c, 0x63, 0x30, 0xf2, 0x1d, 0xdb, 0x32, 0x09, 0x56, 0x4b, 0x3c,
+	0xcc, 0x25, 0x19, 0xe6, 0xa6, 0x14, 0xec, 0x79, 0xf4, 0xf7, 0x4c, 0x2f, 0x79, 0x6e, 0xc4, 0xd2,
+	0xfe, 0x55, 0xa0, 0x1c, 0x96, 0x6b, 0xe8, 0x7b, 0xee, 0x10, 0xbf, 0x66, 0xbd, 0xae, 0x40, 0xe5,
+	0x01, 0x76, 0x2d, 0xdb, 0x7d, 0x68, 0x10, 0xef, 0x11, 0x76, 0x65, 0xb9, 0x04, 0xf3, 0x80, 0xf1,
+	0x58, 0xc8, 0xf8, 0x1b, 0xdf, 0xa6, 0x87, 0x0c, 0x93, 0x88, 0x2a, 0x15, 0x05, 0xa7, 0x49, 0x68,
+	0x67, 0xe7, 0xbd, 0x11, 0xb1, 0xbc, 0x13, 0x59, 0xa0, 0xb9, 0x64, 0x81, 0xfa, 0xa1, 0x48, 0x97,
+	0x3a, 0x68, 0x07, 0xb4, 0x63, 0x6c, 0x06, 0xe4, 0x3e, 0x36, 0x69, 0x0a, 0x5d, 0x82, 0x03, 0xda,
+	0x7c, 0x53, 0xe9, 0xca, 0x71, 0x2b, 0x6f, 0x47, 0x9a, 0x1d, 0xa1, 0x38, 0x9e, 0x3a, 0xed, 0x57,
+	0x05, 0x80, 0x59, 0xd9, 0xf6, 0x9c, 0x01, 0x1d, 0xc9, 0x57, 0x1d, 0xdd, 0x26, 0x5c, 0x0a, 0xf0,
+	0x89, 0x69, 0xbb, 0x3c, 0xf0, 0x0b, 0x5b, 0xb1, 0x11, 0x29, 0x1d, 0x4c, 0xf5, 0xe4, 0x78, 0x52,
+	0xb2, 0x93, 0x49, 0xf9, 0x14, 0xd4, 0x63, 0xec, 0x0c, 0x8c, 0x07, 0x5e, 0x70, 0x41, 0x6c, 0x0b,
+	0x4c, 0xbe, 0xe5, 0x05, 0x13, 0x11, 0x7d, 0xaf, 0x40, 0x9d, 0x45, 0xf4, 0x45, 0xe0, 0x8d, 0x7c,
+	0x39, 0x84, 0x6b, 0x50, 0x94, 0x25, 0x1b, 0xd2, 0xe8, 0x32, 0xe7, 0x56, 0x35, 0x56, 0xf9, 0x7f,
+	0xc6, 0x50, 0xbb, 0x03, 0xb3, 0x09, 0xcf, 0x44, 0xbf, 0xad, 0x42, 0x8e, 0x41, 0xaa, 0x74, 0x6b,
+	0x3e, 0x59, 0x7b, 0xa9, 0xa4, 0x87, 0x2a, 0xda, 0xcf, 0x0a, 0x54, 0x75, 0xec, 0x60, 0x93, 0xb2,
+	0xde, 0x10, 0x5e, 0x42, 0x20, 0x48, 0xbf, 0x10, 0x08, 0xde, 0x83, 0x2a, 0x2d, 0x04, 0xb6, 0x08,
+	0x1e, 0x18, 0x49, 0x84, 0xae, 0x48, 0xee, 0x5d, 0x8e, 0xd4, 0x37, 0x60, 0x31, 0x52, 0x1b, 0x1f,
+	0x82, 0xb0, 0x9a, 0xf3, 0x52, 0xba, 0x95, 0x18, 0x06, 0x6d, 0x16, 0x6a, 0x51, 0x08, 0x61, 0x74,
+	0x5a, 0x1f, 0xca, 0x5b, 0x98, 0x58, 0xc7, 0x32, 0xa6, 0xe9, 0x2e, 0x1c, 0x03, 0xff, 0xf4, 0xcb,
+	0xc0, 0x5f, 0xfb, 0x1c, 0x2a, 0xe2, 0x83, 0x6f, 0x32, 0xd4, 0xda, 0x3d, 0xa8, 0xf1, 0xe3, 0x4d,
+	0xc7, 0x91, 0x2e, 0xc9, 0xad, 0xa4, 0xbc, 0x68, 0x2b, 0xbd, 0xdc, 0x31, 0x1f, 0xea, 0xf1, 0x97,
+	0x85, 0x6f, 0xaf, 0xdb, 0x9b, 0xd7, 0x20, 0x7f, 0xcc, 0xa7, 0x95, 0x35, 0x25, 0xd3, 0x46, 0xc9,
+	0x32, 0x86, 0x83, 0xac, 0x4b, 0x15, 0xed, 0x29, 0xc5, 0xb7, 0x96, 0x47, 0x87, 0xdf, 0x1d, 0xe0,
+	0xc1, 0x0e, 0x3e, 0x6f, 0x97, 0xbd, 0x0f, 0x35, 0x8a, 0xe7, 0x0e, 0xad, 0xa2, 0x49, 0x08, 0xc3,
+	0x68, 0xd9, 0xed, 0xd5, 0x90, 0xdd, 0x14, 0x5c, 0x06, 0xea, 0x4f, 0x4c, 0x9b, 0x30, 0xcb, 0x61,
+	0x8f, 0x4b, 0x12, 0x5d, 0x85, 0x59, 0x0e, 0x0c, 0xc3, 0x63, 0xdb, 0x37, 0xac, 0x63, 0xd3, 0x7d,
+	0x48, 0x63, 0x09, 0xbb, 0xa0, 0x1e, 0x09, 0x5a, 0x21, 0x5f, 0xbb, 0x02, 0xe5, 0x7d, 0x62, 0x92,
+	0xa1, 0xcc, 0xed, 0x1c, 0xe4, 0x88, 0xe7, 0x1b, 0x2e, 0xf7, 0x29, 0x47, 0x13, 0xeb, 0xf9, 0x3d,
+	0xad, 0x0b, 0x15, 0xa1, 0x24, 0xd2, 0x74, 0x0b, 0xaa, 0x96, 0x8c, 0xc3, 0xa0, 0x6e, 0x4f, 0x0d,
+	0x4c, 0x32, 0x4a, 0xbd, 0x62, 0x25, 0xa8, 0xa1, 0xf6, 0x83, 0x02, 0x33, 0x47, 0xdc, 0xd7, 0x18,
+	0xd0, 0x94, 0x24, 0xa0, 0xd1, 0x5d, 0x66, 0x0f, 0xb0, 0x4b, 0xd8, 0x2e, 0x0b, 0x91, 0x2e, 0xa2,
+	0xc7, 0xf6, 0x5c, 0x66, 0x62, 0xcf, 0x7d, 0x02, 0x4b, 0x2c, 0x07, 0xac, 0xf5, 0x27, 0x61, 0x20,
+	0x0c, 0x7f, 0x41, 0x88, 0x27, 0xd0, 0x6f, 0x11, 0x66, 0x68, 0xf0, 0x23, 0x3c, 0xe0, 0x60, 0x56,
+	0xd0, 0x05, 0xa5, 0x69, 0x50, 0x0d, 0xfd, 0x1c, 0x5e, 0x38, 0x0c, 0xda, 0x2d, 0xa8, 0x45, 0x3a,
+	0x22, 0x39, 0x2b, 0x71, 0x65, 0xc2, 0xac, 0x54, 0x65, 0x56, 0x42, 0xcd, 0xa8, 0x52, 0xda, 0xd7,
+	0x50, 0xdf, 0xc1, 0xd8, 0x6f, 0x3a, 0xf6, 0x69, 0x84, 0x21, 0x1f, 0x8c, 0x43, 0xd0, 0xdc, 0x38,
+	0x04, 0x71, 0x1d, 0x81, 0x40, 0x2c, 0x17, 0x41, 0x38, 0xbd, 0x61, 0xf7, 0x15, 0xf5, 0x88, 0xa6,
+	0x25, 0x03, 0x1d, 0x9f, 0x7a, 0x74, 0xad, 0xda, 0x9e, 0xfb, 0xca, 0xab, 0x84, 0x66, 0x22, 0xa0,
+	0xe7, 0x3d, 0x57, 0x80, 0x8c, 0xa0, 0xb4, 0x0e, 0xcc, 0x26, 0x1c, 0x15, 0x71, 0xde, 0x80, 0x52,
+	0x10, 0x99, 0x90, 0xfe, 0xa2, 0x78, 0x5a, 0xa4, 0x48, 0x4f, 0xaa, 0xb1, 0x95, 0x50, 0xdd, 0x30,
+	0x83, 0xc0, 0xa6, 0x89, 0xb8, 0x10, 0x62, 0x2e, 0x43, 0xc9, 0xa7, 0xbb, 0xd2, 0xb6, 0x6c, 0xdf,
+	0x74, 0x89, 0xf0, 0x31, 0xc9, 0x42, 0x1a, 0x94, 0x13, 0xe4, 0x50, 0xf4, 0xc2, 0x18, 0xef, 0xa2,
+	0x95, 0x90, 0xbd, 0x68, 0x25, 0x5c, 0x85, 0x5a, 0xe4, 0x99, 0x88, 0x91, 0x4e, 0x19, 0xe3, 0x9c,
+	0xd2, 0xde, 0x08, 0x07, 0x42, 0x92, 0xab, 0x1f, 0x41, 0x41, 0x62, 0x0a, 0x2a, 0x41, 0xfe, 0xb0,
+	0xb7, 0xd3, 0xeb, 0x1f, 0xf5, 0xea, 0x29, 0x54, 0x80, 0x6c, 0xb7, 0xdf, 0xda, 0xa9, 0x2b, 0xa8,
+	0x0c, 0x85, 0x3d, 0xbd, 0xbd, 0xdf, 0xee, 0xb5, 0xda, 0xf5, 0xf4, 0xea, 0x0d, 0x28, 0x48, 0x68,
+	0x47, 0x15, 0x28, 0xb6, 0xef, 0xb5, 0xba, 0x87, 0xfb, 0x9d, 0xbb, 0x6d, 0x7a, 0x04, 0x60, 0x66,
+	0x7f, 0xbb, 0xa9, 0xb7, 0x37, 0xe9, 0x21, 0x2a, 0xda, 0x6f, 0xef, 0x36, 0xf7, 0xb6, 0xfb, 0x3a,
+	0x3b, 0x75, 0x1b, 0x6a, 0x13, 0x57, 0x26, 0x3a, 0xa2, 0xb5, 0x66, 0xb7, 0xdb, 0x3f, 0x32, 0x36,
+	0x0f, 0xf7, 0xba, 0x9d, 0x56, 0xf3, 0x80, 0x7d, 0x62, 0x1e, 0xea, 0x7a, 0xfb, 0xcb, 0x76, 0xeb,
+	0x20, 0xc1, 0x55, 0x56, 0x77, 0xc3, 0xeb, 0xaf, 0xb8, 0xb6, 0x30, 0x87, 0x9a, 0xad, 0xaf, 0x0e,
+	0x3b, 0xcc, 0x52, 0x8a, 0x59, 0xd2, 0xdb, 0x5b, 0xd4, 0xc1, 0x6d, 0x6e, 0xf8, 0x1d, 0xb8, 0x14,
+	0x91, 0xc6, 0x96, 0xde, 0xdf, 0x35, 0x7a, 0xed, 0x23, 0xa3, 0xd5, 0xef, 0xf5, 0xe8, 0x57, 0x3b,
+	0xfd, 0x5e, 0x3d, 0xbd, 0xfe, 0x4f, 0x16, 0x66, 0xba, 0xfc, 0x2f, 0x07, 0xba, 0x4e, 0xa3, 0xa4,
+	0x6f, 0xe8, 0xbc, 0xfe, 0x6c, 0x9c, 0xbb, 0x37, 0xb5, 0x14, 0x1d, 0xd0, 0x1c, 0x47, 0x5c, 0x14,
+	0x29, 0x24, 0x57, 0x4d, 0x63, 0x61, 0x82, 0x1b, 0x9d, 0xbb, 0x0d, 0x79, 0xb1, 0xa6, 0xd0, 0x62,
+	0xdc, 0x5f, 0xc9, 0xd5, 0xdb, 0x58, 0x9a, 0xe2, 0x47, 0xa7, 0xef, 0x40, 0x41, 0xe2, 0x3c, 0x5a,
+	0x1a, 0x33, 0x11, 0xef, 0x94, 0x86, 0x3a, 0x2d, 0x48, 0xba, 0xcd, 0xe1, 0x2f, 0x76, 0x3b, 0x09,
+	0x99, 0xb1, 0xdb, 0x63, 0x18, 0xc9, 0xcf, 0xe5, 0x9b, 0xd6, 0xe3, 0x11, 0xbd, 0x44, 0xbd, 0x5e,
+	0x9a, 0x36, 0xa0, 0x18, 0x5d, 0x4d, 0x90, 0x9a, 0x54, 0x4a, 0xde, 0xa3, 0x1a, 0x6f, 0x9d, 0x23,
+	0x49, 0xa6, 0x4c, 0xe0, 0x52, 0x9c, 0xb2, 0x71, 0x30, 0x8b, 0x53, 0x36, 0x01, 0x60, 0xf4, 0xf4,
+	0x16, 0x14, 0xa3, 0x79, 0x8f, 0x3d, 0x98, 0xc4, 0xaa, 0xd8, 0x83, 0x29, 0x70, 0xd0, 0x52, 0x2b,
+	0xca, 0xc7, 0x0a, 0xda, 0xa4, 0xad, 0xca, 0xe7, 0xa5, 0x49, 0xc4, 0x64, 0xc5, 0xde, 0x8c, 0x83,
+	0x40, 0xec, 0xcd, 0xc4, 0x08, 0x6a, 0xa9, 0x8d, 0x6b, 0xcf, 0x9e, 0x2f, 0xa7, 0x7e, 0xa3, 0xcf,
+	0xdf, 0xcf, 0x97, 0x95, 0x6f, 0xff, 0x58, 0x56, 0x7e, 0xa4, 0xcf, 0x2f, 0xf4, 0x79, 0x46, 0x9f,
+	0xdf, 0xe9, 0xf3, 0xd7, 0x1f, 0x54, 0x46, 0x7f, 0x9f, 0xfe, 0xb9, 0x9c, 0xba, 0x3f, 0xc3, 0xff,
+	0x0d, 0x5f, 0xff, 0x0f, 0x78, 0x55, 0x7b, 0x6d, 0x1d, 0x0f, 0x00, 0x00,
 }
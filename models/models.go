@@ -10,9 +10,71 @@ import (
 
 const PresenceType = "presence"
 const LockType = "lock"
+const BarrierType = "barrier"
+
+// MaxKeyLength, MaxOwnerLength and MaxValueLength are the most characters
+// the lock tables can store in a key, owner and value.
+const (
+	MaxKeyLength   = 255
+	MaxOwnerLength = 255
+	MaxValueLength = 4096
+)
 
 var ErrLockCollision = grpc.Errorf(codes.AlreadyExists, "lock-collision")
 var ErrInvalidTTL = grpc.Errorf(codes.InvalidArgument, "invalid-ttl")
 var ErrInvalidOwner = grpc.Errorf(codes.InvalidArgument, "invalid-owner")
+var ErrInvalidKey = grpc.Errorf(codes.InvalidArgument, "invalid-key")
+var ErrInvalidValue = grpc.Errorf(codes.InvalidArgument, "invalid-value")
 var ErrResourceNotFound = grpc.Errorf(codes.NotFound, "resource-not-found")
 var ErrInvalidType = grpc.Errorf(codes.NotFound, "invalid-type")
+var ErrInvalidLockMode = grpc.Errorf(codes.InvalidArgument, "invalid-lock-mode")
+var ErrInvalidCapacity = grpc.Errorf(codes.InvalidArgument, "invalid-capacity")
+var ErrLockWaitPreempted = grpc.Errorf(codes.Aborted, "lock-wait-preempted")
+var ErrDeadlock = grpc.Errorf(codes.Aborted, "deadlock-detected")
+var ErrMaxHoldExceeded = grpc.Errorf(codes.FailedPrecondition, "max-hold-exceeded")
+var ErrInvalidBarrier = grpc.Errorf(codes.InvalidArgument, "invalid-barrier")
+var ErrBarrierTimeout = grpc.Errorf(codes.DeadlineExceeded, "barrier-timeout")
+var ErrReleaseConditionFailed = grpc.Errorf(codes.FailedPrecondition, "release-condition-failed")
+var ErrInvalidLockGroup = grpc.Errorf(codes.InvalidArgument, "invalid-lock-group")
+var ErrOverloaded = grpc.Errorf(codes.ResourceExhausted, "overloaded")
+var ErrRequestTimeout = grpc.Errorf(codes.DeadlineExceeded, "request-timeout")
+var ErrHeldByOtherConnection = grpc.Errorf(codes.FailedPrecondition, "held-by-other-connection")
+
+var knownErrors = []error{
+	ErrLockCollision,
+	ErrInvalidTTL,
+	ErrInvalidOwner,
+	ErrInvalidKey,
+	ErrInvalidValue,
+	ErrResourceNotFound,
+	ErrInvalidType,
+	ErrInvalidLockMode,
+	ErrInvalidCapacity,
+	ErrLockWaitPreempted,
+	ErrDeadlock,
+	ErrMaxHoldExceeded,
+	ErrInvalidBarrier,
+	ErrBarrierTimeout,
+	ErrReleaseConditionFailed,
+	ErrInvalidLockGroup,
+	ErrOverloaded,
+	ErrRequestTimeout,
+	ErrHeldByOtherConnection,
+}
+
+// ErrorFrom returns the Err value matching the code and description of an
+// error received from the server, so that callers can compare it with ==.
+// Any other error is returned as it is.
+func ErrorFrom(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code, desc := grpc.Code(err), grpc.ErrorDesc(err)
+	for _, known := range knownErrors {
+		if grpc.Code(known) == code && grpc.ErrorDesc(known) == desc {
+			return known
+		}
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var _ = Describe("ErrorFrom", func() {
+	It("returns the matching error", func() {
+		received := grpc.Errorf(codes.AlreadyExists, "lock-collision")
+		Expect(models.ErrorFrom(received)).To(BeIdenticalTo(models.ErrLockCollision))
+	})
+
+	It("tells apart errors that share a code", func() {
+		received := grpc.Errorf(codes.InvalidArgument, "invalid-owner")
+		Expect(models.ErrorFrom(received)).To(BeIdenticalTo(models.ErrInvalidOwner))
+	})
+
+	It("returns other errors as they are", func() {
+		received := grpc.Errorf(codes.AlreadyExists, "something-else")
+		Expect(models.ErrorFrom(received)).To(BeIdenticalTo(received))
+
+		plain := errors.New("boom")
+		Expect(models.ErrorFrom(plain)).To(BeIdenticalTo(plain))
+		Expect(models.ErrorFrom(nil)).To(BeNil())
+	})
+})
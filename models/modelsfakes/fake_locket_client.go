@@ -54,6 +54,71 @@ type FakeLocketClient struct {
 		result1 *models.FetchAllResponse
 		result2 error
 	}
+	StatsStub        func(ctx context.Context, in *models.StatsRequest, opts ...grpc.CallOption) (*models.StatsResponse, error)
+	statsMutex       sync.RWMutex
+	statsArgsForCall []struct {
+		ctx  context.Context
+		in   *models.StatsRequest
+		opts []grpc.CallOption
+	}
+	statsReturns struct {
+		result1 *models.StatsResponse
+		result2 error
+	}
+	AcquireStub        func(ctx context.Context, in *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error)
+	acquireMutex       sync.RWMutex
+	acquireArgsForCall []struct {
+		ctx  context.Context
+		in   *models.LockRequest
+		opts []grpc.CallOption
+	}
+	acquireReturns struct {
+		result1 *models.LockResponse
+		result2 error
+	}
+	LockGroupStub        func(ctx context.Context, in *models.LockGroupRequest, opts ...grpc.CallOption) (*models.LockGroupResponse, error)
+	lockGroupMutex       sync.RWMutex
+	lockGroupArgsForCall []struct {
+		ctx  context.Context
+		in   *models.LockGroupRequest
+		opts []grpc.CallOption
+	}
+	lockGroupReturns struct {
+		result1 *models.LockGroupResponse
+		result2 error
+	}
+	WaitersStub        func(ctx context.Context, in *models.WaitersRequest, opts ...grpc.CallOption) (*models.WaitersResponse, error)
+	waitersMutex       sync.RWMutex
+	waitersArgsForCall []struct {
+		ctx  context.Context
+		in   *models.WaitersRequest
+		opts []grpc.CallOption
+	}
+	waitersReturns struct {
+		result1 *models.WaitersResponse
+		result2 error
+	}
+	KeepAliveStub        func(ctx context.Context, opts ...grpc.CallOption) (models.Locket_KeepAliveClient, error)
+	keepAliveMutex       sync.RWMutex
+	keepAliveArgsForCall []struct {
+		ctx  context.Context
+		opts []grpc.CallOption
+	}
+	keepAliveReturns struct {
+		result1 models.Locket_KeepAliveClient
+		result2 error
+	}
+	ArriveAtBarrierStub        func(ctx context.Context, in *models.BarrierRequest, opts ...grpc.CallOption) (*models.BarrierResponse, error)
+	arriveAtBarrierMutex       sync.RWMutex
+	arriveAtBarrierArgsForCall []struct {
+		ctx  context.Context
+		in   *models.BarrierRequest
+		opts []grpc.CallOption
+	}
+	arriveAtBarrierReturns struct {
+		result1 *models.BarrierResponse
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -202,6 +267,221 @@ func (fake *FakeLocketClient) FetchAllReturns(result1 *models.FetchAllResponse,
 	}{result1, result2}
 }
 
+func (fake *FakeLocketClient) Stats(ctx context.Context, in *models.StatsRequest, opts ...grpc.CallOption) (*models.StatsResponse, error) {
+	fake.statsMutex.Lock()
+	fake.statsArgsForCall = append(fake.statsArgsForCall, struct {
+		ctx  context.Context
+		in   *models.StatsRequest
+		opts []grpc.CallOption
+	}{ctx, in, opts})
+	fake.recordInvocation("Stats", []interface{}{ctx, in, opts})
+	fake.statsMutex.Unlock()
+	if fake.StatsStub != nil {
+		return fake.StatsStub(ctx, in, opts...)
+	} else {
+		return fake.statsReturns.result1, fake.statsReturns.result2
+	}
+}
+
+func (fake *FakeLocketClient) StatsCallCount() int {
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	return len(fake.statsArgsForCall)
+}
+
+func (fake *FakeLocketClient) StatsArgsForCall(i int) (context.Context, *models.StatsRequest, []grpc.CallOption) {
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	return fake.statsArgsForCall[i].ctx, fake.statsArgsForCall[i].in, fake.statsArgsForCall[i].opts
+}
+
+func (fake *FakeLocketClient) StatsReturns(result1 *models.StatsResponse, result2 error) {
+	fake.StatsStub = nil
+	fake.statsReturns = struct {
+		result1 *models.StatsResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocketClient) Acquire(ctx context.Context, in *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+	fake.acquireMutex.Lock()
+	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct {
+		ctx  context.Context
+		in   *models.LockRequest
+		opts []grpc.CallOption
+	}{ctx, in, opts})
+	fake.recordInvocation("Acquire", []interface{}{ctx, in, opts})
+	fake.acquireMutex.Unlock()
+	if fake.AcquireStub != nil {
+		return fake.AcquireStub(ctx, in, opts...)
+	} else {
+		return fake.acquireReturns.result1, fake.acquireReturns.result2
+	}
+}
+
+func (fake *FakeLocketClient) AcquireCallCount() int {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return len(fake.acquireArgsForCall)
+}
+
+func (fake *FakeLocketClient) AcquireArgsForCall(i int) (context.Context, *models.LockRequest, []grpc.CallOption) {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return fake.acquireArgsForCall[i].ctx, fake.acquireArgsForCall[i].in, fake.acquireArgsForCall[i].opts
+}
+
+func (fake *FakeLocketClient) AcquireReturns(result1 *models.LockResponse, result2 error) {
+	fake.AcquireStub = nil
+	fake.acquireReturns = struct {
+		result1 *models.LockResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocketClient) LockGroup(ctx context.Context, in *models.LockGroupRequest, opts ...grpc.CallOption) (*models.LockGroupResponse, error) {
+	fake.lockGroupMutex.Lock()
+	fake.lockGroupArgsForCall = append(fake.lockGroupArgsForCall, struct {
+		ctx  context.Context
+		in   *models.LockGroupRequest
+		opts []grpc.CallOption
+	}{ctx, in, opts})
+	fake.recordInvocation("LockGroup", []interface{}{ctx, in, opts})
+	fake.lockGroupMutex.Unlock()
+	if fake.LockGroupStub != nil {
+		return fake.LockGroupStub(ctx, in, opts...)
+	} else {
+		return fake.lockGroupReturns.result1, fake.lockGroupReturns.result2
+	}
+}
+
+func (fake *FakeLocketClient) LockGroupCallCount() int {
+	fake.lockGroupMutex.RLock()
+	defer fake.lockGroupMutex.RUnlock()
+	return len(fake.lockGroupArgsForCall)
+}
+
+func (fake *FakeLocketClient) LockGroupArgsForCall(i int) (context.Context, *models.LockGroupRequest, []grpc.CallOption) {
+	fake.lockGroupMutex.RLock()
+	defer fake.lockGroupMutex.RUnlock()
+	return fake.lockGroupArgsForCall[i].ctx, fake.lockGroupArgsForCall[i].in, fake.lockGroupArgsForCall[i].opts
+}
+
+func (fake *FakeLocketClient) LockGroupReturns(result1 *models.LockGroupResponse, result2 error) {
+	fake.LockGroupStub = nil
+	fake.lockGroupReturns = struct {
+		result1 *models.LockGroupResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocketClient) Waiters(ctx context.Context, in *models.WaitersRequest, opts ...grpc.CallOption) (*models.WaitersResponse, error) {
+	fake.waitersMutex.Lock()
+	fake.waitersArgsForCall = append(fake.waitersArgsForCall, struct {
+		ctx  context.Context
+		in   *models.WaitersRequest
+		opts []grpc.CallOption
+	}{ctx, in, opts})
+	fake.recordInvocation("Waiters", []interface{}{ctx, in, opts})
+	fake.waitersMutex.Unlock()
+	if fake.WaitersStub != nil {
+		return fake.WaitersStub(ctx, in, opts...)
+	} else {
+		return fake.waitersReturns.result1, fake.waitersReturns.result2
+	}
+}
+
+func (fake *FakeLocketClient) WaitersCallCount() int {
+	fake.waitersMutex.RLock()
+	defer fake.waitersMutex.RUnlock()
+	return len(fake.waitersArgsForCall)
+}
+
+func (fake *FakeLocketClient) WaitersArgsForCall(i int) (context.Context, *models.WaitersRequest, []grpc.CallOption) {
+	fake.waitersMutex.RLock()
+	defer fake.waitersMutex.RUnlock()
+	return fake.waitersArgsForCall[i].ctx, fake.waitersArgsForCall[i].in, fake.waitersArgsForCall[i].opts
+}
+
+func (fake *FakeLocketClient) WaitersReturns(result1 *models.WaitersResponse, result2 error) {
+	fake.WaitersStub = nil
+	fake.waitersReturns = struct {
+		result1 *models.WaitersResponse
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocketClient) KeepAlive(ctx context.Context, opts ...grpc.CallOption) (models.Locket_KeepAliveClient, error) {
+	fake.keepAliveMutex.Lock()
+	fake.keepAliveArgsForCall = append(fake.keepAliveArgsForCall, struct {
+		ctx  context.Context
+		opts []grpc.CallOption
+	}{ctx, opts})
+	fake.recordInvocation("KeepAlive", []interface{}{ctx, opts})
+	fake.keepAliveMutex.Unlock()
+	if fake.KeepAliveStub != nil {
+		return fake.KeepAliveStub(ctx, opts...)
+	} else {
+		return fake.keepAliveReturns.result1, fake.keepAliveReturns.result2
+	}
+}
+
+func (fake *FakeLocketClient) KeepAliveCallCount() int {
+	fake.keepAliveMutex.RLock()
+	defer fake.keepAliveMutex.RUnlock()
+	return len(fake.keepAliveArgsForCall)
+}
+
+func (fake *FakeLocketClient) KeepAliveArgsForCall(i int) (context.Context, []grpc.CallOption) {
+	fake.keepAliveMutex.RLock()
+	defer fake.keepAliveMutex.RUnlock()
+	return fake.keepAliveArgsForCall[i].ctx, fake.keepAliveArgsForCall[i].opts
+}
+
+func (fake *FakeLocketClient) KeepAliveReturns(result1 models.Locket_KeepAliveClient, result2 error) {
+	fake.KeepAliveStub = nil
+	fake.keepAliveReturns = struct {
+		result1 models.Locket_KeepAliveClient
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocketClient) ArriveAtBarrier(ctx context.Context, in *models.BarrierRequest, opts ...grpc.CallOption) (*models.BarrierResponse, error) {
+	fake.arriveAtBarrierMutex.Lock()
+	fake.arriveAtBarrierArgsForCall = append(fake.arriveAtBarrierArgsForCall, struct {
+		ctx  context.Context
+		in   *models.BarrierRequest
+		opts []grpc.CallOption
+	}{ctx, in, opts})
+	fake.recordInvocation("ArriveAtBarrier", []interface{}{ctx, in, opts})
+	fake.arriveAtBarrierMutex.Unlock()
+	if fake.ArriveAtBarrierStub != nil {
+		return fake.ArriveAtBarrierStub(ctx, in, opts...)
+	} else {
+		return fake.arriveAtBarrierReturns.result1, fake.arriveAtBarrierReturns.result2
+	}
+}
+
+func (fake *FakeLocketClient) ArriveAtBarrierCallCount() int {
+	fake.arriveAtBarrierMutex.RLock()
+	defer fake.arriveAtBarrierMutex.RUnlock()
+	return len(fake.arriveAtBarrierArgsForCall)
+}
+
+func (fake *FakeLocketClient) ArriveAtBarrierArgsForCall(i int) (context.Context, *models.BarrierRequest, []grpc.CallOption) {
+	fake.arriveAtBarrierMutex.RLock()
+	defer fake.arriveAtBarrierMutex.RUnlock()
+	return fake.arriveAtBarrierArgsForCall[i].ctx, fake.arriveAtBarrierArgsForCall[i].in, fake.arriveAtBarrierArgsForCall[i].opts
+}
+
+func (fake *FakeLocketClient) ArriveAtBarrierReturns(result1 *models.BarrierResponse, result2 error) {
+	fake.ArriveAtBarrierStub = nil
+	fake.arriveAtBarrierReturns = struct {
+		result1 *models.BarrierResponse
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLocketClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -213,6 +493,18 @@ func (fake *FakeLocketClient) Invocations() map[string][][]interface{} {
 	defer fake.releaseMutex.RUnlock()
 	fake.fetchAllMutex.RLock()
 	defer fake.fetchAllMutex.RUnlock()
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	fake.lockGroupMutex.RLock()
+	defer fake.lockGroupMutex.RUnlock()
+	fake.waitersMutex.RLock()
+	defer fake.waitersMutex.RUnlock()
+	fake.keepAliveMutex.RLock()
+	defer fake.keepAliveMutex.RUnlock()
+	fake.arriveAtBarrierMutex.RLock()
+	defer fake.arriveAtBarrierMutex.RUnlock()
 	return fake.invocations
 }
 
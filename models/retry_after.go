@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// RetryAfterTrailerKey is the response trailer in which the server suggests
+// how many milliseconds a client should wait before retrying a request it
+// rejected with ErrOverloaded. Clients can read it with the grpc.Trailer call
+// option and RetryAfterFromTrailer.
+const RetryAfterTrailerKey = "locket-retry-after-ms"
+
+func RetryAfterTrailer(retryAfter time.Duration) metadata.MD {
+	return metadata.Pairs(RetryAfterTrailerKey, strconv.FormatInt(int64(retryAfter/time.Millisecond), 10))
+}
+
+// RetryAfterFromTrailer returns the wait suggested in md, or 0 if there is
+// none.
+func RetryAfterFromTrailer(md metadata.MD) time.Duration {
+	values := md[RetryAfterTrailerKey]
+	if len(values) == 0 {
+		return 0
+	}
+
+	milliseconds, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || milliseconds < 0 {
+		return 0
+	}
+	return time.Duration(milliseconds) * time.Millisecond
+}
@@ -0,0 +1,23 @@
+package models_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/locket/models"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ = Describe("RetryAfter", func() {
+	It("round trips the wait through a trailer", func() {
+		md := models.RetryAfterTrailer(1500 * time.Millisecond)
+		Expect(models.RetryAfterFromTrailer(md)).To(Equal(1500 * time.Millisecond))
+	})
+
+	It("returns 0 when the trailer has no valid wait", func() {
+		Expect(models.RetryAfterFromTrailer(metadata.MD{})).To(BeZero())
+		Expect(models.RetryAfterFromTrailer(metadata.Pairs(models.RetryAfterTrailerKey, "soon"))).To(BeZero())
+		Expect(models.RetryAfterFromTrailer(metadata.Pairs(models.RetryAfterTrailerKey, "-1"))).To(BeZero())
+	})
+})
@@ -0,0 +1,3338 @@
+// Code generated by protoc-gen-gogo.
+// source: locket_v2.proto
+// DO NOT EDIT!
+
+/*
+	Package v2 is a generated protocol buffer package.
+
+	It is generated from these files:
+		locket_v2.proto
+
+	It has these top-level messages:
+		Resource
+		LockRequest
+		LockResponse
+		LockGroupRequest
+		LockGroupResponse
+		ReleaseRequest
+		ReleaseResponse
+		FetchRequest
+		FetchResponse
+		FetchAllRequest
+		FetchAllResponse
+*/
+package v2
+
+import proto "github.com/gogo/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import strconv "strconv"
+
+import strings "strings"
+import reflect "reflect"
+import github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
+
+type TypeCode int32
+
+const (
+	UNKNOWN  TypeCode = 0
+	LOCK     TypeCode = 1
+	PRESENCE TypeCode = 2
+)
+
+var TypeCode_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "LOCK",
+	2: "PRESENCE",
+}
+var TypeCode_value = map[string]int32{
+	"UNKNOWN":  0,
+	"LOCK":     1,
+	"PRESENCE": 2,
+}
+
+func (TypeCode) EnumDescriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{0} }
+
+type LockMode int32
+
+const (
+	EXCLUSIVE LockMode = 0
+	SHARED    LockMode = 1
+	SEMAPHORE LockMode = 2
+)
+
+var LockMode_name = map[int32]string{
+	0: "EXCLUSIVE",
+	1: "SHARED",
+	2: "SEMAPHORE",
+}
+var LockMode_value = map[string]int32{
+	"EXCLUSIVE": 0,
+	"SHARED":    1,
+	"SEMAPHORE": 2,
+}
+
+func (LockMode) EnumDescriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{1} }
+
+type Resource struct {
+	Key      string            `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Owner    string            `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Value    string            `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	TypeCode TypeCode          `protobuf:"varint,4,opt,name=type_code,json=typeCode,proto3,enum=locket.v2.TypeCode" json:"type_code,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Resource) Reset()                    { *m = Resource{} }
+func (*Resource) ProtoMessage()               {}
+func (*Resource) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{0} }
+
+func (m *Resource) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Resource) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Resource) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Resource) GetTypeCode() TypeCode {
+	if m != nil {
+		return m.TypeCode
+	}
+	return UNKNOWN
+}
+
+func (m *Resource) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type LockRequest struct {
+	Resource          *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	TtlInMilliseconds int64     `protobuf:"varint,2,opt,name=ttl_in_milliseconds,json=ttlInMilliseconds,proto3" json:"ttl_in_milliseconds,omitempty"`
+	Mode              LockMode  `protobuf:"varint,3,opt,name=mode,proto3,enum=locket.v2.LockMode" json:"mode,omitempty"`
+	Capacity          int32     `protobuf:"varint,4,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Fair              bool      `protobuf:"varint,5,opt,name=fair,proto3" json:"fair,omitempty"`
+	Priority          int32     `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	Preempt           bool      `protobuf:"varint,7,opt,name=preempt,proto3" json:"preempt,omitempty"`
+}
+
+func (m *LockRequest) Reset()                    { *m = LockRequest{} }
+func (*LockRequest) ProtoMessage()               {}
+func (*LockRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{1} }
+
+func (m *LockRequest) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *LockRequest) GetTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.TtlInMilliseconds
+	}
+	return 0
+}
+
+func (m *LockRequest) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return EXCLUSIVE
+}
+
+func (m *LockRequest) GetCapacity() int32 {
+	if m != nil {
+		return m.Capacity
+	}
+	return 0
+}
+
+func (m *LockRequest) GetFair() bool {
+	if m != nil {
+		return m.Fair
+	}
+	return false
+}
+
+func (m *LockRequest) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
+func (m *LockRequest) GetPreempt() bool {
+	if m != nil {
+		return m.Preempt
+	}
+	return false
+}
+
+type LockResponse struct {
+	Resource     *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	FencingToken int64     `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	ExpiresAt    int64     `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (m *LockResponse) Reset()                    { *m = LockResponse{} }
+func (*LockResponse) ProtoMessage()               {}
+func (*LockResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{2} }
+
+func (m *LockResponse) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *LockResponse) GetFencingToken() int64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *LockResponse) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+type LockGroupRequest struct {
+	Resources         []*Resource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+	TtlInMilliseconds int64       `protobuf:"varint,2,opt,name=ttl_in_milliseconds,json=ttlInMilliseconds,proto3" json:"ttl_in_milliseconds,omitempty"`
+}
+
+func (m *LockGroupRequest) Reset()                    { *m = LockGroupRequest{} }
+func (*LockGroupRequest) ProtoMessage()               {}
+func (*LockGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{3} }
+
+func (m *LockGroupRequest) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func (m *LockGroupRequest) GetTtlInMilliseconds() int64 {
+	if m != nil {
+		return m.TtlInMilliseconds
+	}
+	return 0
+}
+
+type LockGroupResponse struct {
+	Locks []*LockResponse `protobuf:"bytes,1,rep,name=locks" json:"locks,omitempty"`
+}
+
+func (m *LockGroupResponse) Reset()                    { *m = LockGroupResponse{} }
+func (*LockGroupResponse) ProtoMessage()               {}
+func (*LockGroupResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{4} }
+
+func (m *LockGroupResponse) GetLocks() []*LockResponse {
+	if m != nil {
+		return m.Locks
+	}
+	return nil
+}
+
+type ReleaseRequest struct {
+	Resource *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+	Mode     LockMode  `protobuf:"varint,2,opt,name=mode,proto3,enum=locket.v2.LockMode" json:"mode,omitempty"`
+}
+
+func (m *ReleaseRequest) Reset()                    { *m = ReleaseRequest{} }
+func (*ReleaseRequest) ProtoMessage()               {}
+func (*ReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{5} }
+
+func (m *ReleaseRequest) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+func (m *ReleaseRequest) GetMode() LockMode {
+	if m != nil {
+		return m.Mode
+	}
+	return EXCLUSIVE
+}
+
+type ReleaseResponse struct {
+}
+
+func (m *ReleaseResponse) Reset()                    { *m = ReleaseResponse{} }
+func (*ReleaseResponse) ProtoMessage()               {}
+func (*ReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{6} }
+
+type FetchRequest struct {
+	Key      string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	TypeCode TypeCode `protobuf:"varint,2,opt,name=type_code,json=typeCode,proto3,enum=locket.v2.TypeCode" json:"type_code,omitempty"`
+}
+
+func (m *FetchRequest) Reset()                    { *m = FetchRequest{} }
+func (*FetchRequest) ProtoMessage()               {}
+func (*FetchRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{7} }
+
+func (m *FetchRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *FetchRequest) GetTypeCode() TypeCode {
+	if m != nil {
+		return m.TypeCode
+	}
+	return UNKNOWN
+}
+
+type FetchResponse struct {
+	Resource *Resource `protobuf:"bytes,1,opt,name=resource" json:"resource,omitempty"`
+}
+
+func (m *FetchResponse) Reset()                    { *m = FetchResponse{} }
+func (*FetchResponse) ProtoMessage()               {}
+func (*FetchResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{8} }
+
+func (m *FetchResponse) GetResource() *Resource {
+	if m != nil {
+		return m.Resource
+	}
+	return nil
+}
+
+type FetchAllRequest struct {
+	TypeCode TypeCode `protobuf:"varint,1,opt,name=type_code,json=typeCode,proto3,enum=locket.v2.TypeCode" json:"type_code,omitempty"`
+}
+
+func (m *FetchAllRequest) Reset()                    { *m = FetchAllRequest{} }
+func (*FetchAllRequest) ProtoMessage()               {}
+func (*FetchAllRequest) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{9} }
+
+func (m *FetchAllRequest) GetTypeCode() TypeCode {
+	if m != nil {
+		return m.TypeCode
+	}
+	return UNKNOWN
+}
+
+type FetchAllResponse struct {
+	Resources []*Resource `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+}
+
+func (m *FetchAllResponse) Reset()                    { *m = FetchAllResponse{} }
+func (*FetchAllResponse) ProtoMessage()               {}
+func (*FetchAllResponse) Descriptor() ([]byte, []int) { return fileDescriptorLocketV2, []int{10} }
+
+func (m *FetchAllResponse) GetResources() []*Resource {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Resource)(nil), "locket.v2.Resource")
+	proto.RegisterType((*LockRequest)(nil), "locket.v2.LockRequest")
+	proto.RegisterType((*LockResponse)(nil), "locket.v2.LockResponse")
+	proto.RegisterType((*LockGroupRequest)(nil), "locket.v2.LockGroupRequest")
+	proto.RegisterType((*LockGroupResponse)(nil), "locket.v2.LockGroupResponse")
+	proto.RegisterType((*ReleaseRequest)(nil), "locket.v2.ReleaseRequest")
+	proto.RegisterType((*ReleaseResponse)(nil), "locket.v2.ReleaseResponse")
+	proto.RegisterType((*FetchRequest)(nil), "locket.v2.FetchRequest")
+	proto.RegisterType((*FetchResponse)(nil), "locket.v2.FetchResponse")
+	proto.RegisterType((*FetchAllRequest)(nil), "locket.v2.FetchAllRequest")
+	proto.RegisterType((*FetchAllResponse)(nil), "locket.v2.FetchAllResponse")
+	proto.RegisterEnum("locket.v2.TypeCode", TypeCode_name, TypeCode_value)
+	proto.RegisterEnum("locket.v2.LockMode", LockMode_name, LockMode_value)
+}
+func (x TypeCode) String() string {
+	s, ok := TypeCode_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (x LockMode) String() string {
+	s, ok := LockMode_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
+}
+func (this *Resource) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*Resource)
+	if !ok {
+		that2, ok := that.(Resource)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if this.Owner != that1.Owner {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
+	}
+	if this.TypeCode != that1.TypeCode {
+		return false
+	}
+	if len(this.Metadata) != len(that1.Metadata) {
+		return false
+	}
+	for i := range this.Metadata {
+		if this.Metadata[i] != that1.Metadata[i] {
+			return false
+		}
+	}
+	return true
+}
+func (this *LockRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*LockRequest)
+	if !ok {
+		that2, ok := that.(LockRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Resource.Equal(that1.Resource) {
+		return false
+	}
+	if this.TtlInMilliseconds != that1.TtlInMilliseconds {
+		return false
+	}
+	if this.Mode != that1.Mode {
+		return false
+	}
+	if this.Capacity != that1.Capacity {
+		return false
+	}
+	if this.Fair != that1.Fair {
+		return false
+	}
+	if this.Priority != that1.Priority {
+		return false
+	}
+	if this.Preempt != that1.Preempt {
+		return false
+	}
+	return true
+}
+func (this *LockResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*LockResponse)
+	if !ok {
+		that2, ok := that.(LockResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Resource.Equal(that1.Resource) {
+		return false
+	}
+	if this.FencingToken != that1.FencingToken {
+		return false
+	}
+	if this.ExpiresAt != that1.ExpiresAt {
+		return false
+	}
+	return true
+}
+func (this *LockGroupRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*LockGroupRequest)
+	if !ok {
+		that2, ok := that.(LockGroupRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.Resources) != len(that1.Resources) {
+		return false
+	}
+	for i := range this.Resources {
+		if !this.Resources[i].Equal(that1.Resources[i]) {
+			return false
+		}
+	}
+	if this.TtlInMilliseconds != that1.TtlInMilliseconds {
+		return false
+	}
+	return true
+}
+func (this *LockGroupResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*LockGroupResponse)
+	if !ok {
+		that2, ok := that.(LockGroupResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.Locks) != len(that1.Locks) {
+		return false
+	}
+	for i := range this.Locks {
+		if !this.Locks[i].Equal(that1.Locks[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReleaseRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ReleaseRequest)
+	if !ok {
+		that2, ok := that.(ReleaseRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Resource.Equal(that1.Resource) {
+		return false
+	}
+	if this.Mode != that1.Mode {
+		return false
+	}
+	return true
+}
+func (this *ReleaseResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*ReleaseResponse)
+	if !ok {
+		that2, ok := that.(ReleaseResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	return true
+}
+func (this *FetchRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*FetchRequest)
+	if !ok {
+		that2, ok := that.(FetchRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.Key != that1.Key {
+		return false
+	}
+	if this.TypeCode != that1.TypeCode {
+		return false
+	}
+	return true
+}
+func (this *FetchResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*FetchResponse)
+	if !ok {
+		that2, ok := that.(FetchResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if !this.Resource.Equal(that1.Resource) {
+		return false
+	}
+	return true
+}
+func (this *FetchAllRequest) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*FetchAllRequest)
+	if !ok {
+		that2, ok := that.(FetchAllRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if this.TypeCode != that1.TypeCode {
+		return false
+	}
+	return true
+}
+func (this *FetchAllResponse) Equal(that interface{}) bool {
+	if that == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	}
+
+	that1, ok := that.(*FetchAllResponse)
+	if !ok {
+		that2, ok := that.(FetchAllResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return true
+		}
+		return false
+	} else if this == nil {
+		return false
+	}
+	if len(this.Resources) != len(that1.Resources) {
+		return false
+	}
+	for i := range this.Resources {
+		if !this.Resources[i].Equal(that1.Resources[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *Resource) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&v2.Resource{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "Owner: "+fmt.Sprintf("%#v", this.Owner)+",\n")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
+	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
+	keysForMetadata := make([]string, 0, len(this.Metadata))
+	for k, _ := range this.Metadata {
+		keysForMetadata = append(keysForMetadata, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForMetadata)
+	mapStringForMetadata := "map[string]string{"
+	for _, k := range keysForMetadata {
+		mapStringForMetadata += fmt.Sprintf("%#v: %#v,", k, this.Metadata[k])
+	}
+	mapStringForMetadata += "}"
+	if this.Metadata != nil {
+		s = append(s, "Metadata: "+mapStringForMetadata+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LockRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 11)
+	s = append(s, "&v2.LockRequest{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	}
+	s = append(s, "TtlInMilliseconds: "+fmt.Sprintf("%#v", this.TtlInMilliseconds)+",\n")
+	s = append(s, "Mode: "+fmt.Sprintf("%#v", this.Mode)+",\n")
+	s = append(s, "Capacity: "+fmt.Sprintf("%#v", this.Capacity)+",\n")
+	s = append(s, "Fair: "+fmt.Sprintf("%#v", this.Fair)+",\n")
+	s = append(s, "Priority: "+fmt.Sprintf("%#v", this.Priority)+",\n")
+	s = append(s, "Preempt: "+fmt.Sprintf("%#v", this.Preempt)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LockResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&v2.LockResponse{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	}
+	s = append(s, "FencingToken: "+fmt.Sprintf("%#v", this.FencingToken)+",\n")
+	s = append(s, "ExpiresAt: "+fmt.Sprintf("%#v", this.ExpiresAt)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LockGroupRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&v2.LockGroupRequest{")
+	if this.Resources != nil {
+		s = append(s, "Resources: "+fmt.Sprintf("%#v", this.Resources)+",\n")
+	}
+	s = append(s, "TtlInMilliseconds: "+fmt.Sprintf("%#v", this.TtlInMilliseconds)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LockGroupResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&v2.LockGroupResponse{")
+	if this.Locks != nil {
+		s = append(s, "Locks: "+fmt.Sprintf("%#v", this.Locks)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ReleaseRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&v2.ReleaseRequest{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	}
+	s = append(s, "Mode: "+fmt.Sprintf("%#v", this.Mode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ReleaseResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&v2.ReleaseResponse{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *FetchRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&v2.FetchRequest{")
+	s = append(s, "Key: "+fmt.Sprintf("%#v", this.Key)+",\n")
+	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *FetchResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&v2.FetchResponse{")
+	if this.Resource != nil {
+		s = append(s, "Resource: "+fmt.Sprintf("%#v", this.Resource)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *FetchAllRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&v2.FetchAllRequest{")
+	s = append(s, "TypeCode: "+fmt.Sprintf("%#v", this.TypeCode)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *FetchAllResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&v2.FetchAllResponse{")
+	if this.Resources != nil {
+		s = append(s, "Resources: "+fmt.Sprintf("%#v", this.Resources)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringLocketV2(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Locket service
+
+type LocketClient interface {
+	Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
+	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error)
+	FetchAll(ctx context.Context, in *FetchAllRequest, opts ...grpc.CallOption) (*FetchAllResponse, error)
+	Acquire(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error)
+	LockGroup(ctx context.Context, in *LockGroupRequest, opts ...grpc.CallOption) (*LockGroupResponse, error)
+}
+
+type locketClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLocketClient(cc *grpc.ClientConn) LocketClient {
+	return &locketClient{cc}
+}
+
+func (c *locketClient) Lock(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	out := new(LockResponse)
+	err := grpc.Invoke(ctx, "/locket.v2.Locket/Lock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
+	out := new(FetchResponse)
+	err := grpc.Invoke(ctx, "/locket.v2.Locket/Fetch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*ReleaseResponse, error) {
+	out := new(ReleaseResponse)
+	err := grpc.Invoke(ctx, "/locket.v2.Locket/Release", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) FetchAll(ctx context.Context, in *FetchAllRequest, opts ...grpc.CallOption) (*FetchAllResponse, error) {
+	out := new(FetchAllResponse)
+	err := grpc.Invoke(ctx, "/locket.v2.Locket/FetchAll", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) Acquire(ctx context.Context, in *LockRequest, opts ...grpc.CallOption) (*LockResponse, error) {
+	out := new(LockResponse)
+	err := grpc.Invoke(ctx, "/locket.v2.Locket/Acquire", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *locketClient) LockGroup(ctx context.Context, in *LockGroupRequest, opts ...grpc.CallOption) (*LockGroupResponse, error) {
+	out := new(LockGroupResponse)
+	err := grpc.Invoke(ctx, "/locket.v2.Locket/LockGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Locket service
+
+type LocketServer interface {
+	Lock(context.Context, *LockRequest) (*LockResponse, error)
+	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
+	Release(context.Context, *ReleaseRequest) (*ReleaseResponse, error)
+	FetchAll(context.Context, *FetchAllRequest) (*FetchAllResponse, error)
+	Acquire(context.Context, *LockRequest) (*LockResponse, error)
+	LockGroup(context.Context, *LockGroupRequest) (*LockGroupResponse, error)
+}
+
+func RegisterLocketServer(s *grpc.Server, srv LocketServer) {
+	s.RegisterService(&_Locket_serviceDesc, srv)
+}
+
+func _Locket_Lock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Lock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.v2.Locket/Lock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Lock(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Fetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Fetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.v2.Locket/Fetch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Fetch(ctx, req.(*FetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.v2.Locket/Release",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Release(ctx, req.(*ReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_FetchAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).FetchAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.v2.Locket/FetchAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).FetchAll(ctx, req.(*FetchAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_Acquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).Acquire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.v2.Locket/Acquire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).Acquire(ctx, req.(*LockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Locket_LockGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LocketServer).LockGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/locket.v2.Locket/LockGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LocketServer).LockGroup(ctx, req.(*LockGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Locket_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "locket.v2.Locket",
+	HandlerType: (*LocketServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lock",
+			Handler:    _Locket_Lock_Handler,
+		},
+		{
+			MethodName: "Fetch",
+			Handler:    _Locket_Fetch_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _Locket_Release_Handler,
+		},
+		{
+			MethodName: "FetchAll",
+			Handler:    _Locket_FetchAll_Handler,
+		},
+		{
+			MethodName: "Acquire",
+			Handler:    _Locket_Acquire_Handler,
+		},
+		{
+			MethodName: "LockGroup",
+			Handler:    _Locket_LockGroup_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "locket_v2.proto",
+}
+
+func (m *Resource) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Resource) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if len(m.Owner) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(len(m.Owner)))
+		i += copy(dAtA[i:], m.Owner)
+	}
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	if m.TypeCode != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.TypeCode))
+	}
+	if len(m.Metadata) > 0 {
+		for k, _ := range m.Metadata {
+			dAtA[i] = 0x2a
+			i++
+			v := m.Metadata[k]
+			mapSize := 1 + len(k) + sovLocketV2(uint64(len(k))) + 1 + len(v) + sovLocketV2(uint64(len(v)))
+			i = encodeVarintLocketV2(dAtA, i, uint64(mapSize))
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocketV2(dAtA, i, uint64(len(k)))
+			i += copy(dAtA[i:], k)
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintLocketV2(dAtA, i, uint64(len(v)))
+			i += copy(dAtA[i:], v)
+		}
+	}
+	return i, nil
+}
+
+func (m *LockRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Resource.Size()))
+		n1, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	if m.TtlInMilliseconds != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.TtlInMilliseconds))
+	}
+	if m.Mode != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Mode))
+	}
+	if m.Capacity != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Capacity))
+	}
+	if m.Fair {
+		dAtA[i] = 0x28
+		i++
+		if m.Fair {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Priority != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Priority))
+	}
+	if m.Preempt {
+		dAtA[i] = 0x38
+		i++
+		if m.Preempt {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	return i, nil
+}
+
+func (m *LockResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Resource.Size()))
+		n2, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	if m.FencingToken != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.FencingToken))
+	}
+	if m.ExpiresAt != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.ExpiresAt))
+	}
+	return i, nil
+}
+
+func (m *LockGroupRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockGroupRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, msg := range m.Resources {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocketV2(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.TtlInMilliseconds != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.TtlInMilliseconds))
+	}
+	return i, nil
+}
+
+func (m *LockGroupResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LockGroupResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Locks) > 0 {
+		for _, msg := range m.Locks {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocketV2(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *ReleaseRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Resource.Size()))
+		n3, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.Mode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Mode))
+	}
+	return i, nil
+}
+
+func (m *ReleaseResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReleaseResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	return i, nil
+}
+
+func (m *FetchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Key) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(len(m.Key)))
+		i += copy(dAtA[i:], m.Key)
+	}
+	if m.TypeCode != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.TypeCode))
+	}
+	return i, nil
+}
+
+func (m *FetchResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Resource != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.Resource.Size()))
+		n4, err := m.Resource.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	return i, nil
+}
+
+func (m *FetchAllRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchAllRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.TypeCode != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintLocketV2(dAtA, i, uint64(m.TypeCode))
+	}
+	return i, nil
+}
+
+func (m *FetchAllResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FetchAllResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, msg := range m.Resources {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintLocketV2(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func encodeFixed64LocketV2(dAtA []byte, offset int, v uint64) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	dAtA[offset+4] = uint8(v >> 32)
+	dAtA[offset+5] = uint8(v >> 40)
+	dAtA[offset+6] = uint8(v >> 48)
+	dAtA[offset+7] = uint8(v >> 56)
+	return offset + 8
+}
+func encodeFixed32LocketV2(dAtA []byte, offset int, v uint32) int {
+	dAtA[offset] = uint8(v)
+	dAtA[offset+1] = uint8(v >> 8)
+	dAtA[offset+2] = uint8(v >> 16)
+	dAtA[offset+3] = uint8(v >> 24)
+	return offset + 4
+}
+func encodeVarintLocketV2(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *Resource) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	l = len(m.Owner)
+	if l > 0 {
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	if m.TypeCode != 0 {
+		n += 1 + sovLocketV2(uint64(m.TypeCode))
+	}
+	if len(m.Metadata) > 0 {
+		for k, v := range m.Metadata {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovLocketV2(uint64(len(k))) + 1 + len(v) + sovLocketV2(uint64(len(v)))
+			n += mapEntrySize + 1 + sovLocketV2(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *LockRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	if m.TtlInMilliseconds != 0 {
+		n += 1 + sovLocketV2(uint64(m.TtlInMilliseconds))
+	}
+	if m.Mode != 0 {
+		n += 1 + sovLocketV2(uint64(m.Mode))
+	}
+	if m.Capacity != 0 {
+		n += 1 + sovLocketV2(uint64(m.Capacity))
+	}
+	if m.Fair {
+		n += 2
+	}
+	if m.Priority != 0 {
+		n += 1 + sovLocketV2(uint64(m.Priority))
+	}
+	if m.Preempt {
+		n += 2
+	}
+	return n
+}
+
+func (m *LockResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	if m.FencingToken != 0 {
+		n += 1 + sovLocketV2(uint64(m.FencingToken))
+	}
+	if m.ExpiresAt != 0 {
+		n += 1 + sovLocketV2(uint64(m.ExpiresAt))
+	}
+	return n
+}
+
+func (m *LockGroupRequest) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, e := range m.Resources {
+			l = e.Size()
+			n += 1 + l + sovLocketV2(uint64(l))
+		}
+	}
+	if m.TtlInMilliseconds != 0 {
+		n += 1 + sovLocketV2(uint64(m.TtlInMilliseconds))
+	}
+	return n
+}
+
+func (m *LockGroupResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Locks) > 0 {
+		for _, e := range m.Locks {
+			l = e.Size()
+			n += 1 + l + sovLocketV2(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ReleaseRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	if m.Mode != 0 {
+		n += 1 + sovLocketV2(uint64(m.Mode))
+	}
+	return n
+}
+
+func (m *ReleaseResponse) Size() (n int) {
+	var l int
+	_ = l
+	return n
+}
+
+func (m *FetchRequest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	if m.TypeCode != 0 {
+		n += 1 + sovLocketV2(uint64(m.TypeCode))
+	}
+	return n
+}
+
+func (m *FetchResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Resource != nil {
+		l = m.Resource.Size()
+		n += 1 + l + sovLocketV2(uint64(l))
+	}
+	return n
+}
+
+func (m *FetchAllRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.TypeCode != 0 {
+		n += 1 + sovLocketV2(uint64(m.TypeCode))
+	}
+	return n
+}
+
+func (m *FetchAllResponse) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Resources) > 0 {
+		for _, e := range m.Resources {
+			l = e.Size()
+			n += 1 + l + sovLocketV2(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovLocketV2(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozLocketV2(x uint64) (n int) {
+	return sovLocketV2(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Resource) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForMetadata := make([]string, 0, len(this.Metadata))
+	for k, _ := range this.Metadata {
+		keysForMetadata = append(keysForMetadata, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForMetadata)
+	mapStringForMetadata := "map[string]string{"
+	for _, k := range keysForMetadata {
+		mapStringForMetadata += fmt.Sprintf("%v: %v,", k, this.Metadata[k])
+	}
+	mapStringForMetadata += "}"
+	s := strings.Join([]string{`&Resource{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`Owner:` + fmt.Sprintf("%v", this.Owner) + `,`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
+		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
+		`Metadata:` + mapStringForMetadata + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockRequest{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`TtlInMilliseconds:` + fmt.Sprintf("%v", this.TtlInMilliseconds) + `,`,
+		`Mode:` + fmt.Sprintf("%v", this.Mode) + `,`,
+		`Capacity:` + fmt.Sprintf("%v", this.Capacity) + `,`,
+		`Fair:` + fmt.Sprintf("%v", this.Fair) + `,`,
+		`Priority:` + fmt.Sprintf("%v", this.Priority) + `,`,
+		`Preempt:` + fmt.Sprintf("%v", this.Preempt) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockResponse{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`FencingToken:` + fmt.Sprintf("%v", this.FencingToken) + `,`,
+		`ExpiresAt:` + fmt.Sprintf("%v", this.ExpiresAt) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockGroupRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockGroupRequest{`,
+		`Resources:` + strings.Replace(fmt.Sprintf("%v", this.Resources), "Resource", "Resource", 1) + `,`,
+		`TtlInMilliseconds:` + fmt.Sprintf("%v", this.TtlInMilliseconds) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LockGroupResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LockGroupResponse{`,
+		`Locks:` + strings.Replace(fmt.Sprintf("%v", this.Locks), "LockResponse", "LockResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReleaseRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReleaseRequest{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`Mode:` + fmt.Sprintf("%v", this.Mode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReleaseResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReleaseResponse{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchRequest{`,
+		`Key:` + fmt.Sprintf("%v", this.Key) + `,`,
+		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchResponse{`,
+		`Resource:` + strings.Replace(fmt.Sprintf("%v", this.Resource), "Resource", "Resource", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchAllRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchAllRequest{`,
+		`TypeCode:` + fmt.Sprintf("%v", this.TypeCode) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *FetchAllResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&FetchAllResponse{`,
+		`Resources:` + strings.Replace(fmt.Sprintf("%v", this.Resources), "Resource", "Resource", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringLocketV2(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Resource) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Resource: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Resource: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Owner", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Owner = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+			}
+			m.TypeCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var keykey uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				keykey |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			var stringLenmapkey uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLenmapkey |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLenmapkey := int(stringLenmapkey)
+			if intStringLenmapkey < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postStringIndexmapkey := iNdEx + intStringLenmapkey
+			if postStringIndexmapkey > l {
+				return io.ErrUnexpectedEOF
+			}
+			mapkey := string(dAtA[iNdEx:postStringIndexmapkey])
+			iNdEx = postStringIndexmapkey
+			if m.Metadata == nil {
+				m.Metadata = make(map[string]string)
+			}
+			if iNdEx < postIndex {
+				var valuekey uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowLocketV2
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					valuekey |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				var stringLenmapvalue uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowLocketV2
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					stringLenmapvalue |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				intStringLenmapvalue := int(stringLenmapvalue)
+				if intStringLenmapvalue < 0 {
+					return ErrInvalidLengthLocketV2
+				}
+				postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+				if postStringIndexmapvalue > l {
+					return io.ErrUnexpectedEOF
+				}
+				mapvalue := string(dAtA[iNdEx:postStringIndexmapvalue])
+				iNdEx = postStringIndexmapvalue
+				m.Metadata[mapkey] = mapvalue
+			} else {
+				var mapvalue string
+				m.Metadata[mapkey] = mapvalue
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInMilliseconds", wireType)
+			}
+			m.TtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mode", wireType)
+			}
+			m.Mode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Mode |= (LockMode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capacity", wireType)
+			}
+			m.Capacity = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Capacity |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fair", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Fair = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Priority", wireType)
+			}
+			m.Priority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Priority |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Preempt", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Preempt = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FencingToken", wireType)
+			}
+			m.FencingToken = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FencingToken |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpiresAt", wireType)
+			}
+			m.ExpiresAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpiresAt |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockGroupRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockGroupRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockGroupRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resources", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Resources = append(m.Resources, &Resource{})
+			if err := m.Resources[len(m.Resources)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TtlInMilliseconds", wireType)
+			}
+			m.TtlInMilliseconds = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TtlInMilliseconds |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LockGroupResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LockGroupResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LockGroupResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Locks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Locks = append(m.Locks, &LockResponse{})
+			if err := m.Locks[len(m.Locks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReleaseRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReleaseRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReleaseRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Mode", wireType)
+			}
+			m.Mode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Mode |= (LockMode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReleaseResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReleaseResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReleaseResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+			}
+			m.TypeCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Resource == nil {
+				m.Resource = &Resource{}
+			}
+			if err := m.Resource.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchAllRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchAllRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchAllRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TypeCode", wireType)
+			}
+			m.TypeCode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TypeCode |= (TypeCode(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FetchAllResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FetchAllResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FetchAllResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resources", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Resources = append(m.Resources, &Resource{})
+			if err := m.Resources[len(m.Resources)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLocketV2(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLocketV2
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipLocketV2(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowLocketV2
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowLocketV2
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthLocketV2
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowLocketV2
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipLocketV2(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthLocketV2 = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowLocketV2   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("locket_v2.proto", fileDescriptorLocketV2) }
+
+var fileDescriptorLocketV2 = []byte{
+	// 757 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcb, 0x6e, 0xdb, 0x46,
+	0x14, 0xd5, 0xe8, 0x49, 0x5d, 0x49, 0x36, 0x3d, 0x2e, 0x5a, 0x96, 0x6e, 0x09, 0x95, 0x5d, 0x54,
+	0x30, 0x5a, 0xb9, 0x55, 0xbb, 0x68, 0x5e, 0x40, 0x64, 0x85, 0x89, 0x0d, 0x4b, 0xb2, 0x31, 0xb2,
+	0x93, 0xec, 0x04, 0x86, 0x1a, 0x27, 0x8c, 0x28, 0x92, 0x26, 0x47, 0x4e, 0xb4, 0x0b, 0x90, 0x1f,
+	0xc8, 0x22, 0x1f, 0x91, 0x4f, 0xc9, 0xd2, 0xcb, 0x2c, 0x63, 0x65, 0x93, 0xa5, 0x57, 0xd9, 0x05,
+	0x08, 0xf8, 0x34, 0x65, 0xcb, 0x88, 0xed, 0xdd, 0xcc, 0xbd, 0x67, 0xee, 0x39, 0x73, 0xe6, 0x50,
+	0x82, 0x45, 0xc3, 0xd2, 0x86, 0x94, 0xf5, 0x0f, 0x1b, 0x75, 0xdb, 0xb1, 0x98, 0x85, 0x8b, 0x41,
+	0xa1, 0x7e, 0xd8, 0x90, 0xbf, 0x20, 0xe0, 0x08, 0x75, 0xad, 0xb1, 0xa3, 0x51, 0xcc, 0x43, 0x66,
+	0x48, 0x27, 0x02, 0xaa, 0xa2, 0x5a, 0x91, 0x78, 0x4b, 0xfc, 0x03, 0xe4, 0xac, 0x17, 0x26, 0x75,
+	0x84, 0xb4, 0x5f, 0x0b, 0x36, 0x5e, 0xf5, 0x50, 0x35, 0xc6, 0x54, 0xc8, 0x04, 0x55, 0x7f, 0x83,
+	0xff, 0x86, 0x22, 0x9b, 0xd8, 0xb4, 0xaf, 0x59, 0x03, 0x2a, 0x64, 0xab, 0xa8, 0xb6, 0xd0, 0x58,
+	0xae, 0xc7, 0x4c, 0xf5, 0xdd, 0x89, 0x4d, 0x5b, 0xd6, 0x80, 0x12, 0x8e, 0x85, 0x2b, 0x7c, 0x07,
+	0xb8, 0x11, 0x65, 0xea, 0x40, 0x65, 0xaa, 0x90, 0xab, 0x66, 0x6a, 0xa5, 0xc6, 0x6f, 0x89, 0x03,
+	0x91, 0xac, 0x7a, 0x27, 0xc4, 0x28, 0x26, 0x73, 0x26, 0x24, 0x3e, 0x22, 0xde, 0x82, 0xca, 0x4c,
+	0x6b, 0xbe, 0xfe, 0x40, 0x69, 0x3a, 0xa1, 0xf4, 0x66, 0xfa, 0x7f, 0x24, 0x7f, 0x45, 0x50, 0x6a,
+	0x5b, 0xda, 0x90, 0xd0, 0x83, 0x31, 0x75, 0x19, 0x5e, 0x03, 0xce, 0x09, 0x09, 0xfd, 0x01, 0xa5,
+	0xc6, 0xf2, 0x1c, 0x2d, 0x24, 0x06, 0xe1, 0x3a, 0x2c, 0x33, 0x66, 0xf4, 0x75, 0xb3, 0x3f, 0xd2,
+	0x0d, 0x43, 0x77, 0xa9, 0x66, 0x99, 0x03, 0xd7, 0x27, 0xca, 0x90, 0x25, 0xc6, 0x8c, 0x4d, 0xb3,
+	0x93, 0x68, 0xe0, 0x3f, 0x20, 0x3b, 0xb2, 0x06, 0x81, 0x67, 0xb3, 0xce, 0x78, 0x32, 0x3a, 0x9e,
+	0x33, 0x3e, 0x00, 0x8b, 0xc0, 0x69, 0xaa, 0xad, 0x6a, 0x3a, 0x9b, 0xf8, 0x36, 0xe6, 0x48, 0xbc,
+	0xc7, 0x18, 0xb2, 0xfb, 0xaa, 0xee, 0x08, 0xb9, 0x2a, 0xaa, 0x71, 0xc4, 0x5f, 0x7b, 0x78, 0xdb,
+	0xd1, 0x2d, 0xc7, 0xc3, 0xe7, 0x03, 0x7c, 0xb4, 0xc7, 0x02, 0x14, 0x6c, 0x87, 0xd2, 0x91, 0xcd,
+	0x84, 0x82, 0x7f, 0x24, 0xda, 0xca, 0xaf, 0x11, 0x94, 0x83, 0xfb, 0xbb, 0xb6, 0x65, 0xba, 0xf4,
+	0xea, 0x06, 0xfc, 0x0e, 0x95, 0x7d, 0x6a, 0x6a, 0xba, 0xf9, 0xb4, 0xcf, 0xac, 0x21, 0x35, 0xc3,
+	0xab, 0x97, 0xc3, 0xe2, 0xae, 0x57, 0xc3, 0xbf, 0x02, 0xd0, 0x97, 0xb6, 0xee, 0x50, 0xb7, 0xaf,
+	0x32, 0xff, 0xee, 0x19, 0x52, 0x0c, 0x2b, 0x4d, 0x26, 0x8f, 0x81, 0xf7, 0x44, 0x3c, 0x70, 0xac,
+	0xb1, 0x1d, 0xbd, 0xc4, 0x3f, 0x50, 0x8c, 0x38, 0x5c, 0x01, 0x55, 0x33, 0x17, 0x29, 0x39, 0x45,
+	0x5d, 0xf5, 0x2d, 0xe4, 0x75, 0x58, 0x4a, 0xd0, 0x86, 0x06, 0xfc, 0x05, 0x39, 0x8f, 0x25, 0xe2,
+	0xfc, 0xe9, 0xcc, 0x0b, 0x45, 0x38, 0x12, 0xa0, 0xe4, 0xe7, 0xb0, 0x40, 0xa8, 0x41, 0x55, 0x97,
+	0x5e, 0x3b, 0x42, 0x51, 0x24, 0xd2, 0xdf, 0x89, 0x84, 0xbc, 0x04, 0x8b, 0x31, 0x57, 0xa0, 0x42,
+	0x26, 0x50, 0xbe, 0x4f, 0x99, 0xf6, 0x2c, 0x22, 0x3f, 0x9f, 0xfd, 0x99, 0xef, 0x31, 0x7d, 0x89,
+	0xef, 0x51, 0xbe, 0x0b, 0x95, 0x70, 0xe6, 0x35, 0x33, 0x21, 0xb7, 0x60, 0xd1, 0x9f, 0xd0, 0x34,
+	0x8c, 0x48, 0xd8, 0x8c, 0x0c, 0x74, 0x19, 0x19, 0x0a, 0xf0, 0xa7, 0x43, 0x42, 0x25, 0x57, 0x0f,
+	0xc5, 0xea, 0x1a, 0x70, 0xd1, 0x70, 0x5c, 0x82, 0xc2, 0x5e, 0x77, 0xab, 0xbb, 0xfd, 0xa8, 0xcb,
+	0xa7, 0x30, 0x07, 0xd9, 0xf6, 0x76, 0x6b, 0x8b, 0x47, 0xb8, 0x0c, 0xdc, 0x0e, 0x51, 0x7a, 0x4a,
+	0xb7, 0xa5, 0xf0, 0xe9, 0xd5, 0xff, 0x80, 0x8b, 0x7c, 0xc7, 0x15, 0x28, 0x2a, 0x8f, 0x5b, 0xed,
+	0xbd, 0xde, 0xe6, 0x43, 0x85, 0x4f, 0x61, 0x80, 0x7c, 0x6f, 0xa3, 0x49, 0x94, 0x7b, 0x3c, 0xf2,
+	0x5a, 0x3d, 0xa5, 0xd3, 0xdc, 0xd9, 0xd8, 0x26, 0x0a, 0x9f, 0x6e, 0xbc, 0xcd, 0x40, 0xbe, 0xed,
+	0x0b, 0xc1, 0x37, 0x20, 0xeb, 0xad, 0xf0, 0x8f, 0xe7, 0xa2, 0xe3, 0x5b, 0x21, 0x5e, 0x14, 0x29,
+	0x39, 0x85, 0x6f, 0x43, 0xce, 0xbf, 0x33, 0x4e, 0x62, 0x92, 0x0f, 0x2c, 0x0a, 0xe7, 0x1b, 0xf1,
+	0xe9, 0x75, 0x28, 0x84, 0xf9, 0xc0, 0x3f, 0xcf, 0xb8, 0x92, 0xcc, 0xa7, 0x28, 0xce, 0x6b, 0xc5,
+	0x33, 0x14, 0xe0, 0x22, 0xd7, 0xb1, 0x78, 0x96, 0xeb, 0xf4, 0x3d, 0xc5, 0x95, 0xb9, 0xbd, 0xc4,
+	0x45, 0x0a, 0x4d, 0xed, 0x60, 0xac, 0x3b, 0xf4, 0x3a, 0x36, 0x6c, 0x40, 0x31, 0xfe, 0x30, 0xf1,
+	0xca, 0x19, 0x5c, 0xf2, 0x57, 0x42, 0xfc, 0x65, 0x7e, 0x33, 0x9a, 0xb4, 0xfe, 0xe7, 0xd1, 0xb1,
+	0x94, 0xfa, 0x70, 0x2c, 0xa5, 0x4e, 0x8e, 0x25, 0xf4, 0x6a, 0x2a, 0xa1, 0x77, 0x53, 0x09, 0xbd,
+	0x9f, 0x4a, 0xe8, 0x68, 0x2a, 0xa1, 0x8f, 0x53, 0x09, 0x7d, 0x9e, 0x4a, 0xa9, 0x93, 0xa9, 0x84,
+	0xde, 0x7c, 0x92, 0x52, 0x4f, 0xf2, 0xfe, 0x1f, 0xe3, 0xbf, 0xdf, 0x06, 0x00, 0x4f, 0xc2, 0xf7,
+	0xad, 0x2b, 0x07, 0x00, 0x00,
+}
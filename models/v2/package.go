@@ -0,0 +1,3 @@
+package v2 // import "code.cloudfoundry.org/locket/models/v2"
+
+//go:generate bash ../../scripts/generate_protos.sh
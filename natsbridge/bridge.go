@@ -0,0 +1,135 @@
+package natsbridge
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/events"
+	"github.com/nats-io/nats.go"
+)
+
+const DefaultSubjectPrefix = "locket"
+
+// deliverTimeout is how long Deliver waits for the server to confirm it
+// received an event.
+const deliverTimeout = 5 * time.Second
+
+type Config struct {
+	Servers       []string `json:"servers,omitempty"`
+	SubjectPrefix string   `json:"subject_prefix,omitempty"`
+	CACertFile    string   `json:"ca_cert_file,omitempty"`
+}
+
+// Enabled returns true if any NATS server is configured.
+func (c Config) Enabled() bool {
+	return len(c.Servers) > 0
+}
+
+//go:generate counterfeiter . Publisher
+type Publisher interface {
+	Publish(subject string, data []byte) error
+	FlushTimeout(timeout time.Duration) error
+}
+
+// Connect connects to the configured NATS servers, reconnecting for as long
+// as locket runs.
+func Connect(logger lager.Logger, config Config) (*nats.Conn, error) {
+	logger = logger.Session("nats-connect", lager.Data{"servers": config.Servers})
+
+	opts := []nats.Option{
+		nats.Name("locket"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			logger.Error("disconnected", err)
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			logger.Info("reconnected")
+		}),
+	}
+	if config.CACertFile != "" {
+		opts = append(opts, nats.RootCAs(config.CACertFile))
+	}
+
+	conn, err := nats.Connect(strings.Join(config.Servers, ","), opts...)
+	if err != nil {
+		logger.Error("failed-to-connect", err)
+		return nil, err
+	}
+
+	logger.Info("connected")
+	return conn, nil
+}
+
+// Bridge is an events.Sink that publishes lock events to NATS. Events go to
+// the subject PREFIX.TYPE.EVENT, such as locket.presence.expired, so that
+// subscribers can pick the events they need with wildcards. The key is in
+// the event rather than the subject, as keys can contain any character.
+//
+// Emit is best effort: a failure to publish is logged and never fails the
+// lock operation that caused it. Deliver, which the outbox dispatcher uses,
+// waits until the server has the event.
+type Bridge struct {
+	logger    lager.Logger
+	publisher Publisher
+	prefix    string
+}
+
+var _ events.Deliverer = &Bridge{}
+
+func NewBridge(logger lager.Logger, publisher Publisher, prefix string) *Bridge {
+	if prefix == "" {
+		prefix = DefaultSubjectPrefix
+	}
+
+	return &Bridge{
+		logger:    logger.Session("nats-bridge"),
+		publisher: publisher,
+		prefix:    prefix,
+	}
+}
+
+// Subject returns the subject events of eventType for locks of lockType are
+// published to.
+func (b *Bridge) Subject(lockType, eventType string) string {
+	return b.prefix + "." + lockType + "." + eventType
+}
+
+// Emit publishes event to its subject.
+func (b *Bridge) Emit(event events.Event) {
+	b.publish(b.logger.Session("publish", lager.Data{"type": event.Type, "key": event.Key, "owner": event.Owner}), event)
+}
+
+// Deliver publishes event to its subject, and flushes the connection so that
+// it only returns once the server has received the event. Publishes are
+// buffered while the connection is reconnecting, so without the flush an
+// event could be lost with the buffer.
+func (b *Bridge) Deliver(event events.Event) error {
+	logger := b.logger.Session("deliver", lager.Data{"type": event.Type, "key": event.Key, "owner": event.Owner})
+
+	err := b.publish(logger, event)
+	if err != nil {
+		return err
+	}
+
+	err = b.publisher.FlushTimeout(deliverTimeout)
+	if err != nil {
+		logger.Error("failed-to-flush", err)
+	}
+	return err
+}
+
+func (b *Bridge) publish(logger lager.Logger, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("failed-to-marshal-event", err)
+		return err
+	}
+
+	err = b.publisher.Publish(b.Subject(event.LockType, event.Type), data)
+	if err != nil {
+		logger.Error("failed-to-publish-event", err)
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package natsbridge_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/events"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/natsbridge"
+	"code.cloudfoundry.org/locket/natsbridge/natsbridgefakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Bridge", func() {
+	var (
+		logger        *lagertest.TestLogger
+		fakePublisher *natsbridgefakes.FakePublisher
+		bridge        *natsbridge.Bridge
+		event         events.Event
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("nats-bridge")
+		fakePublisher = &natsbridgefakes.FakePublisher{}
+		bridge = natsbridge.NewBridge(logger, fakePublisher, "cf.locket")
+		event = events.Event{
+			Type:         events.ExpiredEvent,
+			Key:          "cell-1",
+			Owner:        "rep-1",
+			Value:        "10.0.0.1",
+			LockType:     models.PresenceType,
+			FencingToken: 3,
+			Timestamp:    1234,
+		}
+	})
+
+	It("publishes the event to the subject for its lock type and event type", func() {
+		bridge.Emit(event)
+
+		Expect(fakePublisher.PublishCallCount()).To(Equal(1))
+		subject, data := fakePublisher.PublishArgsForCall(0)
+		Expect(subject).To(Equal("cf.locket.presence.expired"))
+
+		var published events.Event
+		Expect(json.Unmarshal(data, &published)).To(Succeed())
+		Expect(published).To(Equal(event))
+	})
+
+	It("defaults the subject prefix", func() {
+		bridge = natsbridge.NewBridge(logger, fakePublisher, "")
+		Expect(bridge.Subject(models.LockType, events.CreatedEvent)).To(Equal("locket.lock.created"))
+	})
+
+	It("logs failures to publish", func() {
+		fakePublisher.PublishReturns(errors.New("nats down"))
+
+		bridge.Emit(event)
+		Expect(logger).To(gbytes.Say("failed-to-publish-event"))
+	})
+
+	Describe("Deliver", func() {
+		It("publishes the event and waits for the server to receive it", func() {
+			Expect(bridge.Deliver(event)).To(Succeed())
+
+			Expect(fakePublisher.PublishCallCount()).To(Equal(1))
+			subject, _ := fakePublisher.PublishArgsForCall(0)
+			Expect(subject).To(Equal("cf.locket.presence.expired"))
+			Expect(fakePublisher.FlushTimeoutCallCount()).To(Equal(1))
+		})
+
+		It("returns the error when the event cannot be published", func() {
+			fakePublisher.PublishReturns(errors.New("nats down"))
+
+			Expect(bridge.Deliver(event)).To(MatchError("nats down"))
+			Expect(fakePublisher.FlushTimeoutCallCount()).To(Equal(0))
+		})
+
+		It("returns the error when the server does not confirm it", func() {
+			fakePublisher.FlushTimeoutReturns(errors.New("timeout"))
+
+			Expect(bridge.Deliver(event)).To(MatchError("timeout"))
+			Expect(logger).To(gbytes.Say("failed-to-flush"))
+		})
+	})
+})
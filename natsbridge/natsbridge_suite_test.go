@@ -0,0 +1,13 @@
+package natsbridge_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestNatsbridge(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Natsbridge Suite")
+}
@@ -0,0 +1,127 @@
+// This file was generated by counterfeiter
+package natsbridgefakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/locket/natsbridge"
+)
+
+type FakePublisher struct {
+	PublishStub        func(subject string, data []byte) error
+	publishMutex       sync.RWMutex
+	publishArgsForCall []struct {
+		subject string
+		data    []byte
+	}
+	publishReturns struct {
+		result1 error
+	}
+	FlushTimeoutStub        func(timeout time.Duration) error
+	flushTimeoutMutex       sync.RWMutex
+	flushTimeoutArgsForCall []struct {
+		timeout time.Duration
+	}
+	flushTimeoutReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePublisher) Publish(subject string, data []byte) error {
+	var dataCopy []byte
+	if data != nil {
+		dataCopy = make([]byte, len(data))
+		copy(dataCopy, data)
+	}
+	fake.publishMutex.Lock()
+	fake.publishArgsForCall = append(fake.publishArgsForCall, struct {
+		subject string
+		data    []byte
+	}{subject, dataCopy})
+	fake.recordInvocation("Publish", []interface{}{subject, dataCopy})
+	fake.publishMutex.Unlock()
+	if fake.PublishStub != nil {
+		return fake.PublishStub(subject, data)
+	} else {
+		return fake.publishReturns.result1
+	}
+}
+
+func (fake *FakePublisher) PublishCallCount() int {
+	fake.publishMutex.RLock()
+	defer fake.publishMutex.RUnlock()
+	return len(fake.publishArgsForCall)
+}
+
+func (fake *FakePublisher) PublishArgsForCall(i int) (string, []byte) {
+	fake.publishMutex.RLock()
+	defer fake.publishMutex.RUnlock()
+	return fake.publishArgsForCall[i].subject, fake.publishArgsForCall[i].data
+}
+
+func (fake *FakePublisher) PublishReturns(result1 error) {
+	fake.PublishStub = nil
+	fake.publishReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePublisher) FlushTimeout(timeout time.Duration) error {
+	fake.flushTimeoutMutex.Lock()
+	fake.flushTimeoutArgsForCall = append(fake.flushTimeoutArgsForCall, struct {
+		timeout time.Duration
+	}{timeout})
+	fake.recordInvocation("FlushTimeout", []interface{}{timeout})
+	fake.flushTimeoutMutex.Unlock()
+	if fake.FlushTimeoutStub != nil {
+		return fake.FlushTimeoutStub(timeout)
+	} else {
+		return fake.flushTimeoutReturns.result1
+	}
+}
+
+func (fake *FakePublisher) FlushTimeoutCallCount() int {
+	fake.flushTimeoutMutex.RLock()
+	defer fake.flushTimeoutMutex.RUnlock()
+	return len(fake.flushTimeoutArgsForCall)
+}
+
+func (fake *FakePublisher) FlushTimeoutArgsForCall(i int) time.Duration {
+	fake.flushTimeoutMutex.RLock()
+	defer fake.flushTimeoutMutex.RUnlock()
+	return fake.flushTimeoutArgsForCall[i].timeout
+}
+
+func (fake *FakePublisher) FlushTimeoutReturns(result1 error) {
+	fake.FlushTimeoutStub = nil
+	fake.flushTimeoutReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePublisher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.publishMutex.RLock()
+	defer fake.publishMutex.RUnlock()
+	fake.flushTimeoutMutex.RLock()
+	defer fake.flushTimeoutMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakePublisher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ natsbridge.Publisher = new(FakePublisher)
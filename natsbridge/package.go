@@ -0,0 +1 @@
+package natsbridge // import "code.cloudfoundry.org/locket/natsbridge"
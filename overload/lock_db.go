@@ -0,0 +1,69 @@
+package overload
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+)
+
+type observedDB struct {
+	db.LockDB
+	limiter *Limiter
+}
+
+// LockDB wraps lockDB so that the latency of its calls adjusts the limit.
+// Only calls on a single key are observed. FetchAll, Count and ExpireLocks
+// take time in proportion to the number of locks, which says little about
+// how busy the database is.
+func (l *Limiter) LockDB(lockDB db.LockDB) db.LockDB {
+	return &observedDB{LockDB: lockDB, limiter: l}
+}
+
+func (d *observedDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	defer d.observe()()
+	return d.LockDB.Lock(logger, resource, ttl)
+}
+
+func (d *observedDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	defer d.observe()()
+	return d.LockDB.LockWithGrace(logger, resource, ttl, grace)
+}
+
+func (d *observedDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	defer d.observe()()
+	return d.LockDB.LockShared(logger, resource, ttl)
+}
+
+func (d *observedDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	defer d.observe()()
+	return d.LockDB.LockSemaphore(logger, resource, capacity, ttl)
+}
+
+func (d *observedDB) Release(logger lager.Logger, resource *models.Resource) error {
+	defer d.observe()()
+	return d.LockDB.Release(logger, resource)
+}
+
+func (d *observedDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	defer d.observe()()
+	return d.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (d *observedDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	defer d.observe()()
+	return d.LockDB.ReleaseShared(logger, resource)
+}
+
+func (d *observedDB) Fetch(logger lager.Logger, key string) (*db.Lock, error) {
+	defer d.observe()()
+	return d.LockDB.Fetch(logger, key)
+}
+
+func (d *observedDB) observe() func() {
+	start := d.limiter.clock.Now()
+	return func() {
+		d.limiter.Observe(d.limiter.clock.Since(start))
+	}
+}
@@ -0,0 +1,226 @@
+package overload
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const (
+	DefaultMinLimit   = 10
+	DefaultMaxLimit   = 1000
+	DefaultRetryAfter = 10 * time.Second
+
+	requestsShed = "RequestsShed"
+
+	// tolerance is how many times its usual latency the database may take
+	// before the limit starts to come down.
+	tolerance = 2.0
+	// smoothing is how much of each new limit is applied at once.
+	smoothing = 0.2
+	// shortAlpha and longAlpha weigh each latency sample in the recent and
+	// usual latency, which roughly average the last 5 and 500 samples.
+	shortAlpha = 0.2
+	longAlpha  = 0.002
+	// nonCriticalShare is the part of the limit that non-critical requests
+	// may use, so that they are shed while critical requests still have room.
+	nonCriticalShare = 0.5
+	// heartbeatsPerTTL is how many times per ttl clients are asked to
+	// refresh their locks while the limit is down, which still leaves room
+	// for a heartbeat to fail.
+	heartbeatsPerTTL = 3
+)
+
+// nonCritical are the RPCs shed first. They read many locks and can be
+// retried later, while the others hold locks that are lost if they fail.
+var nonCritical = map[string]bool{
+	"FetchAll": true,
+	"Stats":    true,
+	"Waiters":  true,
+}
+
+// unlimited are the RPCs that are never shed, because they spend most of
+// their time waiting for other clients rather than the database.
+var unlimited = map[string]bool{
+	"Acquire":         true,
+	"ArriveAtBarrier": true,
+}
+
+type Config struct {
+	Enabled    bool                  `json:"enabled,omitempty"`
+	MinLimit   int                   `json:"min_limit,omitempty"`
+	MaxLimit   int                   `json:"max_limit,omitempty"`
+	RetryAfter durationjson.Duration `json:"retry_after,omitempty"`
+}
+
+// Limiter limits how many requests are handled at once. The limit is
+// adjusted after every database call by comparing its recent latency with
+// its usual latency, in the manner of Netflix's gradient limiter: it grows
+// while the database keeps up and shrinks when its latency climbs, so that
+// the requests in excess are rejected instead of slowing down every request.
+// Non-critical requests may only use part of the limit, so they are shed
+// before critical ones. While the limit is down, clients are told to slow
+// down their retries and heartbeats.
+type Limiter struct {
+	logger       lager.Logger
+	clock        clock.Clock
+	metronClient emitter.Emitter
+	minLimit     float64
+	maxLimit     float64
+	retryAfter   time.Duration
+
+	mutex    sync.Mutex
+	limit    float64
+	inflight int
+	shortRTT float64
+	longRTT  float64
+}
+
+func NewLimiter(logger lager.Logger, clock clock.Clock, metronClient emitter.Emitter, config Config) *Limiter {
+	minLimit, maxLimit := config.MinLimit, config.MaxLimit
+	if minLimit <= 0 {
+		minLimit = DefaultMinLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxLimit
+	}
+	if maxLimit < minLimit {
+		maxLimit = minLimit
+	}
+	retryAfter := time.Duration(config.RetryAfter)
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+
+	return &Limiter{
+		logger:       logger.Session("overload"),
+		clock:        clock,
+		metronClient: metronClient,
+		minLimit:     float64(minLimit),
+		maxLimit:     float64(maxLimit),
+		retryAfter:   retryAfter,
+		limit:        float64(maxLimit),
+	}
+}
+
+// Limit returns the current limit on concurrent requests.
+func (l *Limiter) Limit() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int(l.limit)
+}
+
+// Observe adjusts the limit for a database call that took latency.
+func (l *Limiter) Observe(latency time.Duration) {
+	sample := float64(latency)
+	if sample <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.longRTT == 0 {
+		l.shortRTT, l.longRTT = sample, sample
+		return
+	}
+	l.shortRTT += (sample - l.shortRTT) * shortAlpha
+	l.longRTT += (sample - l.longRTT) * longAlpha
+
+	// once the database is much faster than usual again, forget the slow
+	// period quickly so that the limit can recover
+	if l.longRTT > 2*l.shortRTT {
+		l.longRTT = 0.95*l.longRTT + 0.05*l.shortRTT
+	}
+
+	gradient := math.Max(0.5, math.Min(1, tolerance*l.longRTT/l.shortRTT))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = l.limit*(1-smoothing) + newLimit*smoothing
+	l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, l.limit))
+}
+
+// NewInterceptor returns a unary interceptor that rejects requests over the
+// limit with models.ErrOverloaded and emits a RequestsShed counter for each.
+// Rejected requests suggest when to retry in the models.RetryAfterTrailerKey
+// trailer, and while the limit is down Lock responses suggest a heartbeat
+// interval of a third of the lock's ttl.
+func (l *Limiter) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		if unlimited[method] {
+			return handler(ctx, req)
+		}
+
+		if !l.admit(!nonCritical[method]) {
+			l.logger.Debug("shed-request", lager.Data{"method": info.FullMethod})
+			err := l.metronClient.IncrementCounter(requestsShed)
+			if err != nil {
+				l.logger.Error("failed-sending-requests-shed", err)
+			}
+			err = grpc.SetTrailer(ctx, models.RetryAfterTrailer(l.retryAfter))
+			if err != nil {
+				l.logger.Debug("failed-to-send-retry-after", lager.Data{"error": err.Error()})
+			}
+			return nil, models.ErrOverloaded
+		}
+		defer l.done()
+
+		resp, err := handler(ctx, req)
+		if lockResp, ok := resp.(*models.LockResponse); ok && err == nil {
+			l.hintHeartbeatInterval(req, lockResp)
+		}
+		return resp, err
+	}
+}
+
+func (l *Limiter) hintHeartbeatInterval(req interface{}, resp *models.LockResponse) {
+	lockReq, ok := req.(*models.LockRequest)
+	if !ok {
+		return
+	}
+
+	l.mutex.Lock()
+	limited := l.limit < l.maxLimit
+	l.mutex.Unlock()
+
+	if limited {
+		resp.HeartbeatIntervalInMilliseconds = int64(lockTTL(lockReq) / heartbeatsPerTTL / time.Millisecond)
+	}
+}
+
+func lockTTL(req *models.LockRequest) time.Duration {
+	if req.TtlInMilliseconds > 0 {
+		return time.Duration(req.TtlInMilliseconds) * time.Millisecond
+	}
+	return time.Duration(req.TtlInSeconds) * time.Second
+}
+
+func (l *Limiter) admit(critical bool) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limit := l.limit
+	if !critical {
+		limit *= nonCriticalShare
+	}
+	if float64(l.inflight) >= limit {
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+func (l *Limiter) done() {
+	l.mutex.Lock()
+	l.inflight--
+	l.mutex.Unlock()
+}
@@ -0,0 +1,13 @@
+package overload_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestOverload(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Overload Suite")
+}
@@ -0,0 +1,182 @@
+package overload_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/overload"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeTransportStream struct {
+	trailer metadata.MD
+}
+
+func (s *fakeTransportStream) Method() string                  { return "" }
+func (s *fakeTransportStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *fakeTransportStream) SendHeader(md metadata.MD) error { return nil }
+func (s *fakeTransportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+var _ = Describe("Limiter", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeClock    *fakeclock.FakeClock
+		metronClient *mfakes.FakeIngressClient
+		limiter      *overload.Limiter
+		interceptor  grpc.UnaryServerInterceptor
+
+		release chan struct{}
+		blocked grpc.UnaryHandler
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("overload")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		metronClient = &mfakes.FakeIngressClient{}
+		limiter = overload.NewLimiter(logger, fakeClock, metronClient, overload.Config{Enabled: true, MinLimit: 4, MaxLimit: 8})
+		interceptor = limiter.NewInterceptor()
+
+		release = make(chan struct{})
+		blocked = func(ctx context.Context, req interface{}) (interface{}, error) {
+			<-release
+			return "ok", nil
+		}
+	})
+
+	AfterEach(func() {
+		close(release)
+	})
+
+	call := func(method string, handler grpc.UnaryHandler) error {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	fill := func(method string, n int) {
+		for i := 0; i < n; i++ {
+			go call(method, blocked)
+		}
+	}
+
+	slowDown := func() {
+		for i := 0; i < 100; i++ {
+			limiter.Observe(time.Millisecond)
+		}
+		for i := 0; i < 100; i++ {
+			limiter.Observe(10 * time.Millisecond)
+		}
+	}
+
+	It("starts at the maximum limit", func() {
+		Expect(limiter.Limit()).To(Equal(8))
+	})
+
+	It("lowers the limit when the database slows down", func() {
+		slowDown()
+		Expect(limiter.Limit()).To(Equal(4))
+	})
+
+	It("raises the limit again once the database recovers", func() {
+		slowDown()
+		for i := 0; i < 100; i++ {
+			limiter.Observe(time.Millisecond)
+		}
+		Expect(limiter.Limit()).To(Equal(8))
+	})
+
+	It("sheds non-critical requests first", func() {
+		fill("/models.Locket/Lock", 4)
+		Eventually(func() error {
+			return call("/models.Locket/FetchAll", func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		}).Should(Equal(models.ErrOverloaded))
+
+		Expect(call("/models.Locket/Lock", func(context.Context, interface{}) (interface{}, error) { return nil, nil })).To(Succeed())
+		Expect(metronClient.IncrementCounterCallCount()).To(BeNumerically(">", 0))
+		Expect(metronClient.IncrementCounterArgsForCall(0)).To(Equal("RequestsShed"))
+	})
+
+	It("sheds critical requests over the limit", func() {
+		fill("/locket.v2.Locket/Lock", 8)
+		Eventually(func() error {
+			return call("/locket.v2.Locket/Release", func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		}).Should(Equal(models.ErrOverloaded))
+	})
+
+	It("never sheds requests that wait for other clients", func() {
+		fill("/models.Locket/Lock", 8)
+		Eventually(func() error {
+			return call("/models.Locket/Lock", func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+		}).Should(Equal(models.ErrOverloaded))
+
+		Expect(call("/models.Locket/Acquire", func(context.Context, interface{}) (interface{}, error) { return nil, nil })).To(Succeed())
+	})
+
+	It("suggests when to retry shed requests", func() {
+		fill("/models.Locket/Lock", 8)
+
+		stream := &fakeTransportStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		Eventually(func() error {
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}, func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+			return err
+		}).Should(Equal(models.ErrOverloaded))
+
+		Expect(models.RetryAfterFromTrailer(stream.trailer)).To(Equal(overload.DefaultRetryAfter))
+	})
+
+	Describe("heartbeat interval hints", func() {
+		lock := func() *models.LockResponse {
+			resp, err := interceptor(context.Background(), &models.LockRequest{TtlInSeconds: 15}, &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}, func(context.Context, interface{}) (interface{}, error) {
+				return &models.LockResponse{}, nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return resp.(*models.LockResponse)
+		}
+
+		It("does not suggest one while the limit is at the maximum", func() {
+			Expect(lock().HeartbeatIntervalInMilliseconds).To(BeZero())
+		})
+
+		It("suggests a third of the ttl once the limit comes down", func() {
+			slowDown()
+			Expect(lock().HeartbeatIntervalInMilliseconds).To(Equal(int64(5000)))
+		})
+	})
+
+	Describe("LockDB", func() {
+		It("observes the latency of calls on a single key", func() {
+			fakeLockDB := &dbfakes.FakeLockDB{}
+			latency := time.Millisecond
+			fakeLockDB.LockStub = func(lager.Logger, *models.Resource, time.Duration) (*db.Lock, error) {
+				fakeClock.Increment(latency)
+				return nil, nil
+			}
+			lockDB := limiter.LockDB(fakeLockDB)
+
+			for i := 0; i < 100; i++ {
+				_, err := lockDB.Lock(logger, &models.Resource{Key: "key"}, time.Second)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			latency = 10 * time.Millisecond
+			for i := 0; i < 100; i++ {
+				_, err := lockDB.Lock(logger, &models.Resource{Key: "key"}, time.Second)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(limiter.Limit()).To(Equal(4))
+		})
+	})
+})
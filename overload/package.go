@@ -0,0 +1 @@
+package overload // import "code.cloudfoundry.org/locket/overload"
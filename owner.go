@@ -0,0 +1,117 @@
+package locket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nu7hatch/gouuid"
+)
+
+const (
+	// ProcessGUIDEnv and InstanceIndexEnv are read by NewOwner. Cloud
+	// Foundry sets both for app instances, and BOSH jobs can set them from
+	// spec.id and spec.index.
+	ProcessGUIDEnv   = "CF_INSTANCE_GUID"
+	InstanceIndexEnv = "CF_INSTANCE_INDEX"
+
+	ownerSeparator = "/"
+	suffixBytes    = 4
+)
+
+var ErrInvalidOwner = errors.New("owner must be component/host/process-guid/instance-index/suffix")
+
+// Owner identifies one running instance of a component. Two processes never
+// share an Owner, even when they run on the same host with the same
+// instance index, such as a component and its replacement during a
+// restart, because each gets its own random Suffix.
+type Owner struct {
+	Component     string
+	Host          string
+	ProcessGUID   string
+	InstanceIndex int
+	Suffix        string
+}
+
+// NewOwner returns an Owner for this process. The process GUID and instance
+// index are read from CF_INSTANCE_GUID and CF_INSTANCE_INDEX. Without them
+// a new GUID is generated and the index is 0.
+func NewOwner(component string) (Owner, error) {
+	if component == "" || strings.Contains(component, ownerSeparator) {
+		return Owner{}, fmt.Errorf("invalid owner component: %q", component)
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return Owner{}, err
+	}
+
+	processGUID := os.Getenv(ProcessGUIDEnv)
+	if processGUID == "" {
+		guid, err := uuid.NewV4()
+		if err != nil {
+			return Owner{}, err
+		}
+		processGUID = guid.String()
+	}
+
+	instanceIndex := 0
+	if index := os.Getenv(InstanceIndexEnv); index != "" {
+		instanceIndex, err = strconv.Atoi(index)
+		if err != nil {
+			return Owner{}, fmt.Errorf("invalid %s: %s", InstanceIndexEnv, err)
+		}
+	}
+
+	suffix := make([]byte, suffixBytes)
+	_, err = rand.Read(suffix)
+	if err != nil {
+		return Owner{}, err
+	}
+
+	return Owner{
+		Component:     component,
+		Host:          strings.Replace(host, ownerSeparator, "-", -1),
+		ProcessGUID:   strings.Replace(processGUID, ownerSeparator, "-", -1),
+		InstanceIndex: instanceIndex,
+		Suffix:        hex.EncodeToString(suffix),
+	}, nil
+}
+
+// String returns the owner to use in a Resource, for example
+// "rep/cell-z1-0/8c3f6e0a-4d1b-4b5e-9a2c-1f0e2d3c4b5a/0/9f86d081".
+func (o Owner) String() string {
+	return strings.Join([]string{
+		o.Component,
+		o.Host,
+		o.ProcessGUID,
+		strconv.Itoa(o.InstanceIndex),
+		o.Suffix,
+	}, ownerSeparator)
+}
+
+// ParseOwner parses an owner made by Owner.String, such as one read back
+// from a Fetch.
+func ParseOwner(owner string) (Owner, error) {
+	parts := strings.Split(owner, ownerSeparator)
+	if len(parts) != 5 {
+		return Owner{}, ErrInvalidOwner
+	}
+
+	instanceIndex, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return Owner{}, ErrInvalidOwner
+	}
+
+	return Owner{
+		Component:     parts[0],
+		Host:          parts[1],
+		ProcessGUID:   parts[2],
+		InstanceIndex: instanceIndex,
+		Suffix:        parts[4],
+	}, nil
+}
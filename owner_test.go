@@ -0,0 +1,86 @@
+package locket_test
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/locket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Owner", func() {
+	var (
+		previousGUID  string
+		previousIndex string
+	)
+
+	BeforeEach(func() {
+		previousGUID = os.Getenv(locket.ProcessGUIDEnv)
+		previousIndex = os.Getenv(locket.InstanceIndexEnv)
+		os.Setenv(locket.ProcessGUIDEnv, "8c3f6e0a-4d1b-4b5e-9a2c-1f0e2d3c4b5a")
+		os.Setenv(locket.InstanceIndexEnv, "2")
+	})
+
+	AfterEach(func() {
+		os.Setenv(locket.ProcessGUIDEnv, previousGUID)
+		os.Setenv(locket.InstanceIndexEnv, previousIndex)
+	})
+
+	It("identifies the process", func() {
+		owner, err := locket.NewOwner("rep")
+		Expect(err).NotTo(HaveOccurred())
+
+		host, err := os.Hostname()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(owner.Component).To(Equal("rep"))
+		Expect(owner.Host).To(Equal(host))
+		Expect(owner.ProcessGUID).To(Equal("8c3f6e0a-4d1b-4b5e-9a2c-1f0e2d3c4b5a"))
+		Expect(owner.InstanceIndex).To(Equal(2))
+		Expect(owner.Suffix).To(HaveLen(8))
+	})
+
+	It("gives every call its own owner", func() {
+		first, err := locket.NewOwner("rep")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := locket.NewOwner("rep")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first.String()).NotTo(Equal(second.String()))
+	})
+
+	It("generates a process guid when none is set", func() {
+		os.Unsetenv(locket.ProcessGUIDEnv)
+		os.Unsetenv(locket.InstanceIndexEnv)
+
+		owner, err := locket.NewOwner("rep")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner.ProcessGUID).NotTo(BeEmpty())
+		Expect(owner.InstanceIndex).To(Equal(0))
+	})
+
+	It("rejects an invalid instance index", func() {
+		os.Setenv(locket.InstanceIndexEnv, "first")
+		_, err := locket.NewOwner("rep")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects a component with a separator", func() {
+		_, err := locket.NewOwner("diego/rep")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("parses the owners it makes", func() {
+		owner, err := locket.NewOwner("rep")
+		Expect(err).NotTo(HaveOccurred())
+
+		parsed, err := locket.ParseOwner(owner.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(owner))
+	})
+
+	It("does not parse other owners", func() {
+		_, err := locket.ParseOwner("cell-1")
+		Expect(err).To(Equal(locket.ErrInvalidOwner))
+	})
+})
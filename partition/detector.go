@@ -0,0 +1,205 @@
+package partition
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const DefaultFailureThreshold = 3
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+var (
+	ErrPartitioned = grpc.Errorf(codes.Unavailable, "locket server cannot write to the database")
+
+	errWriteStalled = errors.New("previous sentinel write has not finished")
+)
+
+// Config enables the partition detector, which writes a sentinel row to the
+// database every Interval and fences the server off after FailureThreshold
+// failed writes in a row.
+type Config struct {
+	Interval         durationjson.Duration `json:"interval,omitempty"`
+	FailureThreshold int                   `json:"failure_threshold,omitempty"`
+}
+
+func (c Config) Enabled() bool {
+	return c.Interval > 0
+}
+
+type SentinelWriter interface {
+	WriteSentinel(logger lager.Logger, id string) error
+}
+
+type HealthStatusSetter interface {
+	SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus)
+}
+
+// Detector fences the server off from its clients while it cannot write to
+// the database. A server cut off from the database, or left with a replica
+// that only accepts reads, cannot hold on to the locks it granted, so
+// another server may already be granting them to someone else. Until a
+// write goes through again it rejects every request with ErrPartitioned,
+// rather than granting locks it cannot keep or answering reads that clients
+// would take as authoritative, and reports itself as not serving so that
+// load balancers send clients elsewhere.
+type Detector struct {
+	logger    lager.Logger
+	db        SentinelWriter
+	health    HealthStatusSetter
+	id        string
+	clock     clock.Clock
+	interval  time.Duration
+	threshold int
+
+	mutex       sync.Mutex
+	failures    int
+	partitioned bool
+	statuses    map[string]healthpb.HealthCheckResponse_ServingStatus
+}
+
+// NewDetector returns a detector that writes the sentinel of the server
+// identified by id to db. Statuses set on the detector are passed on to
+// health, except that every service is reported as not serving while the
+// server is partitioned.
+func NewDetector(logger lager.Logger, db SentinelWriter, health HealthStatusSetter, id string, clock clock.Clock, cfg Config) *Detector {
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+
+	return &Detector{
+		logger:    logger.Session("partition-detector", lager.Data{"id": id}),
+		db:        db,
+		health:    health,
+		id:        id,
+		clock:     clock,
+		interval:  time.Duration(cfg.Interval),
+		threshold: threshold,
+		statuses:  make(map[string]healthpb.HealthCheckResponse_ServingStatus),
+	}
+}
+
+// Partitioned reports whether the last FailureThreshold sentinel writes
+// failed.
+func (d *Detector) Partitioned() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.partitioned
+}
+
+func (d *Detector) SetServingStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.statuses[service] = status
+	if d.partitioned {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	d.health.SetServingStatus(service, status)
+}
+
+// Run writes the sentinel every interval. A write that has not finished
+// when the next one is due counts as failed, so that a database that stops
+// answering is caught as soon as one that returns errors.
+func (d *Detector) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := d.logger
+	logger.Info("started", lager.Data{"interval": d.interval.String(), "failure-threshold": d.threshold})
+	defer logger.Info("complete")
+
+	results := make(chan error, 1)
+	writing := false
+	write := func() {
+		if writing {
+			d.record(logger, errWriteStalled)
+			return
+		}
+		writing = true
+		go func() {
+			results <- d.db.WriteSentinel(logger, d.id)
+		}()
+	}
+
+	ticker := d.clock.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	write()
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+
+		case <-ticker.C():
+			write()
+
+		case err := <-results:
+			writing = false
+			d.record(logger, err)
+		}
+	}
+}
+
+func (d *Detector) record(logger lager.Logger, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err == nil {
+		d.failures = 0
+		if d.partitioned {
+			d.partitioned = false
+			logger.Info("recovered")
+			for service, status := range d.statuses {
+				d.health.SetServingStatus(service, status)
+			}
+		}
+		return
+	}
+
+	d.failures++
+	logger.Error("failed-to-write-sentinel", err, lager.Data{"failures": d.failures})
+	if d.failures < d.threshold || d.partitioned {
+		return
+	}
+
+	d.partitioned = true
+	logger.Info("partitioned", lager.Data{"failures": d.failures})
+	for service := range d.statuses {
+		d.health.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// NewInterceptor returns a unary interceptor that rejects requests with
+// ErrPartitioned while the server is partitioned.
+func (d *Detector) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if d.Partitioned() && !strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return nil, ErrPartitioned
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamInterceptor rejects new streams with ErrPartitioned while the
+// server is partitioned.
+func (d *Detector) NewStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if d.Partitioned() && !strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return ErrPartitioned
+		}
+		return handler(srv, stream)
+	}
+}
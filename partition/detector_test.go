@@ -0,0 +1,214 @@
+package partition_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/partition"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type fakeSentinelWriter struct {
+	mutex  sync.Mutex
+	err    error
+	block  chan struct{}
+	writes []string
+}
+
+func (w *fakeSentinelWriter) WriteSentinel(logger lager.Logger, id string) error {
+	w.mutex.Lock()
+	w.writes = append(w.writes, id)
+	err, block := w.err, w.block
+	w.mutex.Unlock()
+
+	if block != nil {
+		<-block
+	}
+	return err
+}
+
+func (w *fakeSentinelWriter) fail(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.err = err
+}
+
+func (w *fakeSentinelWriter) hang(block chan struct{}) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.block = block
+}
+
+func (w *fakeSentinelWriter) ids() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]string{}, w.writes...)
+}
+
+func (w *fakeSentinelWriter) writeCount() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return len(w.writes)
+}
+
+var _ = Describe("Detector", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeClock    *fakeclock.FakeClock
+		writer       *fakeSentinelWriter
+		healthServer *health.Server
+		detector     *partition.Detector
+		process      ifrit.Process
+	)
+
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := healthServer.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		return resp.Status
+	}
+
+	tick := func() {
+		writes := writer.writeCount()
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(writer.writeCount).Should(Equal(writes + 1))
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("partition")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		writer = &fakeSentinelWriter{}
+		healthServer = health.NewServer()
+
+		detector = partition.NewDetector(logger, writer, healthServer, "server-1", fakeClock, partition.Config{
+			Interval:         durationjson.Duration(time.Second),
+			FailureThreshold: 2,
+		})
+		detector.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	})
+
+	JustBeforeEach(func() {
+		process = ginkgomon.Invoke(detector)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("writes its sentinel when it starts and then every interval", func() {
+		Eventually(writer.writeCount).Should(Equal(1))
+		tick()
+		tick()
+		Expect(writer.ids()).To(Equal([]string{"server-1", "server-1", "server-1"}))
+		Expect(detector.Partitioned()).To(BeFalse())
+	})
+
+	Context("when writes keep failing", func() {
+		BeforeEach(func() {
+			writer.fail(errors.New("read-only"))
+		})
+
+		It("marks itself unhealthy after the failure threshold", func() {
+			Eventually(logger).Should(gbytes.Say("failed-to-write-sentinel"))
+			Expect(detector.Partitioned()).To(BeFalse())
+			Expect(status()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+
+			tick()
+			Eventually(detector.Partitioned).Should(BeTrue())
+			Expect(status()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+		})
+
+		It("keeps reporting not serving when others mark the server healthy", func() {
+			tick()
+			Eventually(detector.Partitioned).Should(BeTrue())
+
+			detector.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+			Expect(status()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+		})
+
+		It("recovers once a write goes through again", func() {
+			tick()
+			Eventually(detector.Partitioned).Should(BeTrue())
+
+			writer.fail(nil)
+			tick()
+			Eventually(detector.Partitioned).Should(BeFalse())
+			Expect(status()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+		})
+	})
+
+	Context("when a write does not return", func() {
+		var block chan struct{}
+
+		BeforeEach(func() {
+			block = make(chan struct{})
+			writer.hang(block)
+		})
+
+		AfterEach(func() {
+			close(block)
+		})
+
+		It("counts each interval it is still running as a failure", func() {
+			Eventually(writer.writeCount).Should(Equal(1))
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Consistently(detector.Partitioned).Should(BeFalse())
+			fakeClock.Increment(time.Second)
+			Eventually(detector.Partitioned).Should(BeTrue())
+			Expect(writer.writeCount()).To(Equal(1))
+		})
+	})
+
+	Describe("the interceptors", func() {
+		var (
+			unary  grpc.UnaryServerInterceptor
+			stream grpc.StreamServerInterceptor
+		)
+
+		BeforeEach(func() {
+			unary = detector.NewInterceptor()
+			stream = detector.NewStreamInterceptor()
+			writer.fail(errors.New("read-only"))
+		})
+
+		callUnary := func(method string) error {
+			_, err := unary(context.Background(), &models.LockRequest{}, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &models.LockResponse{}, nil
+			})
+			return err
+		}
+
+		callStream := func(method string) error {
+			return stream(nil, nil, &grpc.StreamServerInfo{FullMethod: method}, func(srv interface{}, stream grpc.ServerStream) error {
+				return nil
+			})
+		}
+
+		It("let requests through until the server is partitioned", func() {
+			Expect(callUnary("/models.Locket/Lock")).To(Succeed())
+			Expect(callStream("/models.Locket/KeepAlive")).To(Succeed())
+
+			tick()
+			Eventually(detector.Partitioned).Should(BeTrue())
+
+			Expect(callUnary("/models.Locket/Lock")).To(Equal(partition.ErrPartitioned))
+			Expect(callUnary("/models.Locket/Fetch")).To(Equal(partition.ErrPartitioned))
+			Expect(callStream("/models.Locket/KeepAlive")).To(Equal(partition.ErrPartitioned))
+			Expect(callUnary("/grpc.health.v1.Health/Check")).To(Succeed())
+		})
+	})
+})
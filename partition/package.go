@@ -0,0 +1 @@
+package partition // import "code.cloudfoundry.org/locket/partition"
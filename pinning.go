@@ -0,0 +1,100 @@
+package locket
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// PublicKeyPinPrefix starts a pin of the SHA-256 hash of the server's
+	// public key, in the form used by HTTP public key pinning. It survives a
+	// certificate rotation that keeps the key.
+	PublicKeyPinPrefix = "sha256/"
+	// CertificatePinPrefix starts a pin of the SHA-256 hash of the server's
+	// whole certificate, which changes with every rotation.
+	CertificatePinPrefix = "cert-sha256/"
+)
+
+var ErrInvalidLocketPin = errors.New("locket server pins must be sha256/ or cert-sha256/ followed by a base64 sha256 hash")
+
+// PinMismatchError is returned by the handshake when the server presents a
+// certificate that matches none of the pins, usually because the server
+// certificate was rotated and the pins were not updated.
+type PinMismatchError struct {
+	PublicKeyPin   string
+	CertificatePin string
+}
+
+func (e *PinMismatchError) Error() string {
+	return fmt.Sprintf(
+		"locket server certificate matches none of locket_server_pins, it has %s and %s: if the server certificate was rotated, update the pins",
+		e.PublicKeyPin,
+		e.CertificatePin,
+	)
+}
+
+// PublicKeyPin returns the pin of cert's public key.
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return PublicKeyPinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// CertificatePin returns the pin of cert itself.
+func CertificatePin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return CertificatePinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func validatePins(pins []string) error {
+	for _, pin := range pins {
+		var hash string
+		switch {
+		case strings.HasPrefix(pin, PublicKeyPinPrefix):
+			hash = strings.TrimPrefix(pin, PublicKeyPinPrefix)
+		case strings.HasPrefix(pin, CertificatePinPrefix):
+			hash = strings.TrimPrefix(pin, CertificatePinPrefix)
+		default:
+			return ErrInvalidLocketPin
+		}
+
+		sum, err := base64.StdEncoding.DecodeString(hash)
+		if err != nil || len(sum) != sha256.Size {
+			return ErrInvalidLocketPin
+		}
+	}
+	return nil
+}
+
+// VerifyPins returns a function suitable for use as
+// tls.Config.VerifyPeerCertificate that accepts the server's certificate
+// only if its public key or the certificate itself matches one of pins. It
+// runs after the usual chain verification, so pinning is in addition to
+// trusting the CA.
+func VerifyPins(pins []string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	pinned := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		pinned[pin] = true
+	}
+
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("locket server presented no certificate")
+		}
+
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+
+		publicKeyPin := PublicKeyPin(leaf)
+		certificatePin := CertificatePin(leaf)
+		if pinned[publicKeyPin] || pinned[certificatePin] {
+			return nil
+		}
+		return &PinMismatchError{PublicKeyPin: publicKeyPin, CertificatePin: certificatePin}
+	}
+}
@@ -0,0 +1,97 @@
+package locket_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"time"
+
+	"code.cloudfoundry.org/locket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server pinning", func() {
+	var (
+		key  *ecdsa.PrivateKey
+		cert *x509.Certificate
+	)
+
+	issue := func(key *ecdsa.PrivateKey, serial int64) *x509.Certificate {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "locket.service.cf.internal"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := x509.ParseCertificate(der)
+		Expect(err).NotTo(HaveOccurred())
+		return cert
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		cert = issue(key, 1)
+	})
+
+	It("accepts a certificate whose public key is pinned", func() {
+		verify := locket.VerifyPins([]string{locket.PublicKeyPin(cert)})
+		Expect(verify([][]byte{cert.Raw}, nil)).To(Succeed())
+	})
+
+	It("accepts a certificate that is pinned", func() {
+		verify := locket.VerifyPins([]string{locket.CertificatePin(cert)})
+		Expect(verify([][]byte{cert.Raw}, nil)).To(Succeed())
+	})
+
+	It("keeps accepting a rotated certificate with the same pinned key", func() {
+		verify := locket.VerifyPins([]string{locket.PublicKeyPin(cert)})
+		Expect(verify([][]byte{issue(key, 2).Raw}, nil)).To(Succeed())
+	})
+
+	It("rejects a rotated certificate when the certificate is pinned", func() {
+		verify := locket.VerifyPins([]string{locket.CertificatePin(cert)})
+		rotated := issue(key, 2)
+
+		err := verify([][]byte{rotated.Raw}, nil)
+		Expect(err).To(Equal(&locket.PinMismatchError{
+			PublicKeyPin:   locket.PublicKeyPin(rotated),
+			CertificatePin: locket.CertificatePin(rotated),
+		}))
+		Expect(err.Error()).To(ContainSubstring("update the pins"))
+	})
+
+	Describe("validation", func() {
+		var config locket.ClientLocketConfig
+
+		BeforeEach(func() {
+			config = locket.ClientLocketConfig{
+				LocketAddress:    "locket.service.cf.internal:8891",
+				LocketCACertFile: "ca.crt",
+				LocketAuthToken:  "token",
+			}
+		})
+
+		It("accepts well formed pins", func() {
+			config.LocketServerPins = []string{locket.PublicKeyPin(cert), locket.CertificatePin(cert)}
+			Expect(config.Validate()).To(Succeed())
+		})
+
+		It("rejects pins of an unknown kind", func() {
+			config.LocketServerPins = []string{"md5/abc"}
+			Expect(config.Validate()).To(Equal(locket.ErrInvalidLocketPin))
+		})
+
+		It("rejects pins that are not sha256 hashes", func() {
+			config.LocketServerPins = []string{"sha256/bm90IGEgaGFzaA=="}
+			Expect(config.Validate()).To(Equal(locket.ErrInvalidLocketPin))
+		})
+	})
+})
@@ -0,0 +1 @@
+package preflight // import "code.cloudfoundry.org/locket/preflight"
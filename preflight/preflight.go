@@ -0,0 +1,170 @@
+package preflight
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// ExpiryWarning is how long before its certificate expires CheckTLS starts
+// to warn about it.
+const ExpiryWarning = 30 * 24 * time.Hour
+
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type Report struct {
+	Passed bool    `json:"passed"`
+	Checks []Check `json:"checks"`
+}
+
+func NewReport() *Report {
+	return &Report{Passed: true, Checks: []Check{}}
+}
+
+// Add records checks in the report. Warnings are reported but do not fail
+// it.
+func (r *Report) Add(checks ...Check) {
+	for _, check := range checks {
+		if check.Status == StatusFail {
+			r.Passed = false
+		}
+		r.Checks = append(r.Checks, check)
+	}
+}
+
+func pass(name, detail string) Check {
+	return Check{Name: name, Status: StatusPass, Detail: detail}
+}
+
+func warn(name, detail string) Check {
+	return Check{Name: name, Status: StatusWarn, Detail: detail}
+}
+
+func fail(name string, err error) Check {
+	return Check{Name: name, Status: StatusFail, Detail: err.Error()}
+}
+
+// Skip is a warning that the named check could not be run.
+func Skip(name, reason string) Check {
+	return warn(name, "skipped: "+reason)
+}
+
+//go:generate counterfeiter . Database
+
+type Database interface {
+	Ping() error
+	PendingMigrations(logger lager.Logger) []string
+	CheckWritable(logger lager.Logger) error
+}
+
+// CheckDatabase checks that database can be reached, that its schema is
+// up to date and that locket can write to it. A schema that is not up to
+// date is a warning, as locket migrates it on start.
+func CheckDatabase(logger lager.Logger, database Database) []Check {
+	err := database.Ping()
+	if err != nil {
+		return []Check{fail("database-connectivity", err)}
+	}
+	checks := []Check{pass("database-connectivity", "")}
+
+	pending := database.PendingMigrations(logger)
+	if len(pending) > 0 {
+		checks = append(checks, warn("database-schema", "pending migrations: "+strings.Join(pending, ", ")))
+		// the probe needs every table to exist
+		return append(checks, Skip("database-permissions", "schema is not up to date"))
+	}
+	checks = append(checks, pass("database-schema", ""))
+
+	err = database.CheckWritable(logger)
+	if err != nil {
+		return append(checks, fail("database-permissions", err))
+	}
+	return append(checks, pass("database-permissions", ""))
+}
+
+// CheckTLS checks that the certificate in certFile matches keyFile, chains
+// to a CA in caFile, can be used by a server and is valid for each of
+// hostnames at now.
+func CheckTLS(certFile, keyFile, caFile string, hostnames []string, now time.Time) Check {
+	const name = "tls"
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fail(name, err)
+	}
+
+	caCert, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return fail(name, err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caCert) {
+		return fail(name, errors.New("unable to load ca cert file"))
+	}
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return fail(name, err)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, der := range certificate.Certificate[1:] {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fail(name, err)
+		}
+		intermediates.AddCert(cert)
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		return fail(name, err)
+	}
+
+	for _, hostname := range hostnames {
+		err = leaf.VerifyHostname(hostname)
+		if err != nil {
+			return fail(name, err)
+		}
+	}
+
+	if leaf.NotAfter.Sub(now) < ExpiryWarning {
+		return warn(name, fmt.Sprintf("certificate expires at %s", leaf.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	return pass(name, fmt.Sprintf("certificate expires at %s", leaf.NotAfter.UTC().Format(time.RFC3339)))
+}
+
+// CheckListen checks that address can be listened on, by listening on it and
+// closing the listener straight away.
+func CheckListen(address string) Check {
+	const name = "listen-address"
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fail(name, err)
+	}
+	listener.Close()
+	return pass(name, address)
+}
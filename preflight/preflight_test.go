@@ -0,0 +1,218 @@
+package preflight_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/preflight"
+	"code.cloudfoundry.org/locket/preflight/preflightfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preflight", func() {
+	Describe("Report", func() {
+		It("passes when nothing failed", func() {
+			report := preflight.NewReport()
+			report.Add(preflight.Check{Name: "a", Status: preflight.StatusPass}, preflight.Skip("b", "reason"))
+			Expect(report.Passed).To(BeTrue())
+			Expect(report.Checks).To(HaveLen(2))
+		})
+
+		It("fails when a check failed", func() {
+			report := preflight.NewReport()
+			report.Add(preflight.Check{Name: "a", Status: preflight.StatusFail})
+			Expect(report.Passed).To(BeFalse())
+		})
+	})
+
+	Describe("CheckDatabase", func() {
+		var (
+			logger       *lagertest.TestLogger
+			fakeDatabase *preflightfakes.FakeDatabase
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("preflight")
+			fakeDatabase = &preflightfakes.FakeDatabase{}
+		})
+
+		statuses := func(checks []preflight.Check) map[string]string {
+			result := map[string]string{}
+			for _, check := range checks {
+				result[check.Name] = check.Status
+			}
+			return result
+		}
+
+		It("passes when the database is reachable, up to date and writable", func() {
+			Expect(statuses(preflight.CheckDatabase(logger, fakeDatabase))).To(Equal(map[string]string{
+				"database-connectivity": preflight.StatusPass,
+				"database-schema":       preflight.StatusPass,
+				"database-permissions":  preflight.StatusPass,
+			}))
+		})
+
+		It("fails when the database cannot be reached", func() {
+			fakeDatabase.PingReturns(errors.New("boom"))
+			Expect(statuses(preflight.CheckDatabase(logger, fakeDatabase))).To(Equal(map[string]string{
+				"database-connectivity": preflight.StatusFail,
+			}))
+			Expect(fakeDatabase.CheckWritableCallCount()).To(Equal(0))
+		})
+
+		It("warns about pending migrations without probing permissions", func() {
+			fakeDatabase.PendingMigrationsReturns([]string{"add column locks.reserved_until"})
+			checks := preflight.CheckDatabase(logger, fakeDatabase)
+			Expect(statuses(checks)).To(Equal(map[string]string{
+				"database-connectivity": preflight.StatusPass,
+				"database-schema":       preflight.StatusWarn,
+				"database-permissions":  preflight.StatusWarn,
+			}))
+			Expect(checks[1].Detail).To(ContainSubstring("add column locks.reserved_until"))
+			Expect(fakeDatabase.CheckWritableCallCount()).To(Equal(0))
+		})
+
+		It("fails when the database cannot be written to", func() {
+			fakeDatabase.CheckWritableReturns(errors.New("permission denied"))
+			checks := preflight.CheckDatabase(logger, fakeDatabase)
+			Expect(statuses(checks)["database-permissions"]).To(Equal(preflight.StatusFail))
+			Expect(checks[2].Detail).To(Equal("permission denied"))
+		})
+	})
+
+	Describe("CheckTLS", func() {
+		var (
+			tmpDir string
+			now    time.Time
+
+			caKey    *ecdsa.PrivateKey
+			caCert   *x509.Certificate
+			certFile string
+			keyFile  string
+			caFile   string
+		)
+
+		writePEM := func(path, blockType string, der []byte) {
+			err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		issue := func(notAfter time.Time, usage x509.ExtKeyUsage) {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(2),
+				Subject:      pkix.Name{CommonName: "locket"},
+				DNSNames:     []string{"locket.service.cf.internal"},
+				IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+				NotBefore:    now.Add(-time.Hour),
+				NotAfter:     notAfter,
+				KeyUsage:     x509.KeyUsageDigitalSignature,
+				ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+			Expect(err).NotTo(HaveOccurred())
+			writePEM(certFile, "CERTIFICATE", der)
+
+			keyDER, err := x509.MarshalECPrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+			writePEM(keyFile, "EC PRIVATE KEY", keyDER)
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "preflight")
+			Expect(err).NotTo(HaveOccurred())
+			now = time.Now()
+			certFile = filepath.Join(tmpDir, "cert.crt")
+			keyFile = filepath.Join(tmpDir, "key.key")
+			caFile = filepath.Join(tmpDir, "ca.crt")
+
+			caKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			template := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{CommonName: "locket-ca"},
+				NotBefore:             now.Add(-time.Hour),
+				NotAfter:              now.Add(365 * 24 * time.Hour),
+				KeyUsage:              x509.KeyUsageCertSign,
+				BasicConstraintsValid: true,
+				IsCA:                  true,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+			Expect(err).NotTo(HaveOccurred())
+			caCert, err = x509.ParseCertificate(der)
+			Expect(err).NotTo(HaveOccurred())
+			writePEM(caFile, "CERTIFICATE", der)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("passes for a server certificate valid for the hostnames", func() {
+			issue(now.Add(90*24*time.Hour), x509.ExtKeyUsageServerAuth)
+			check := preflight.CheckTLS(certFile, keyFile, caFile, []string{"locket.service.cf.internal", "127.0.0.1"}, now)
+			Expect(check.Status).To(Equal(preflight.StatusPass))
+		})
+
+		It("fails when a hostname is not in the certificate", func() {
+			issue(now.Add(90*24*time.Hour), x509.ExtKeyUsageServerAuth)
+			check := preflight.CheckTLS(certFile, keyFile, caFile, []string{"bbs.service.cf.internal"}, now)
+			Expect(check.Status).To(Equal(preflight.StatusFail))
+			Expect(check.Detail).To(ContainSubstring("bbs.service.cf.internal"))
+		})
+
+		It("fails when the certificate cannot be used by a server", func() {
+			issue(now.Add(90*24*time.Hour), x509.ExtKeyUsageClientAuth)
+			check := preflight.CheckTLS(certFile, keyFile, caFile, nil, now)
+			Expect(check.Status).To(Equal(preflight.StatusFail))
+		})
+
+		It("fails when the certificate does not chain to the ca", func() {
+			issue(now.Add(90*24*time.Hour), x509.ExtKeyUsageServerAuth)
+			check := preflight.CheckTLS(certFile, keyFile, certFile, nil, now)
+			Expect(check.Status).To(Equal(preflight.StatusFail))
+		})
+
+		It("fails when the certificate has expired", func() {
+			issue(now.Add(time.Hour), x509.ExtKeyUsageServerAuth)
+			check := preflight.CheckTLS(certFile, keyFile, caFile, nil, now.Add(2*time.Hour))
+			Expect(check.Status).To(Equal(preflight.StatusFail))
+		})
+
+		It("warns when the certificate expires soon", func() {
+			issue(now.Add(24*time.Hour), x509.ExtKeyUsageServerAuth)
+			check := preflight.CheckTLS(certFile, keyFile, caFile, nil, now)
+			Expect(check.Status).To(Equal(preflight.StatusWarn))
+		})
+	})
+
+	Describe("CheckListen", func() {
+		It("passes when the address is free", func() {
+			Expect(preflight.CheckListen("127.0.0.1:0").Status).To(Equal(preflight.StatusPass))
+		})
+
+		It("fails when the address is taken", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			check := preflight.CheckListen(listener.Addr().String())
+			Expect(check.Status).To(Equal(preflight.StatusFail))
+		})
+	})
+})
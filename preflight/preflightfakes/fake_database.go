@@ -0,0 +1,153 @@
+// This file was generated by counterfeiter
+package preflightfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/preflight"
+)
+
+type FakeDatabase struct {
+	PingStub        func() error
+	pingMutex       sync.RWMutex
+	pingArgsForCall []struct{}
+	pingReturns     struct {
+		result1 error
+	}
+	PendingMigrationsStub        func(logger lager.Logger) []string
+	pendingMigrationsMutex       sync.RWMutex
+	pendingMigrationsArgsForCall []struct {
+		logger lager.Logger
+	}
+	pendingMigrationsReturns struct {
+		result1 []string
+	}
+	CheckWritableStub        func(logger lager.Logger) error
+	checkWritableMutex       sync.RWMutex
+	checkWritableArgsForCall []struct {
+		logger lager.Logger
+	}
+	checkWritableReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDatabase) Ping() error {
+	fake.pingMutex.Lock()
+	fake.pingArgsForCall = append(fake.pingArgsForCall, struct{}{})
+	fake.recordInvocation("Ping", []interface{}{})
+	fake.pingMutex.Unlock()
+	if fake.PingStub != nil {
+		return fake.PingStub()
+	} else {
+		return fake.pingReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) PingCallCount() int {
+	fake.pingMutex.RLock()
+	defer fake.pingMutex.RUnlock()
+	return len(fake.pingArgsForCall)
+}
+
+func (fake *FakeDatabase) PingReturns(result1 error) {
+	fake.PingStub = nil
+	fake.pingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) PendingMigrations(logger lager.Logger) []string {
+	fake.pendingMigrationsMutex.Lock()
+	fake.pendingMigrationsArgsForCall = append(fake.pendingMigrationsArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("PendingMigrations", []interface{}{logger})
+	fake.pendingMigrationsMutex.Unlock()
+	if fake.PendingMigrationsStub != nil {
+		return fake.PendingMigrationsStub(logger)
+	} else {
+		return fake.pendingMigrationsReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) PendingMigrationsCallCount() int {
+	fake.pendingMigrationsMutex.RLock()
+	defer fake.pendingMigrationsMutex.RUnlock()
+	return len(fake.pendingMigrationsArgsForCall)
+}
+
+func (fake *FakeDatabase) PendingMigrationsArgsForCall(i int) lager.Logger {
+	fake.pendingMigrationsMutex.RLock()
+	defer fake.pendingMigrationsMutex.RUnlock()
+	return fake.pendingMigrationsArgsForCall[i].logger
+}
+
+func (fake *FakeDatabase) PendingMigrationsReturns(result1 []string) {
+	fake.PendingMigrationsStub = nil
+	fake.pendingMigrationsReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeDatabase) CheckWritable(logger lager.Logger) error {
+	fake.checkWritableMutex.Lock()
+	fake.checkWritableArgsForCall = append(fake.checkWritableArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.recordInvocation("CheckWritable", []interface{}{logger})
+	fake.checkWritableMutex.Unlock()
+	if fake.CheckWritableStub != nil {
+		return fake.CheckWritableStub(logger)
+	} else {
+		return fake.checkWritableReturns.result1
+	}
+}
+
+func (fake *FakeDatabase) CheckWritableCallCount() int {
+	fake.checkWritableMutex.RLock()
+	defer fake.checkWritableMutex.RUnlock()
+	return len(fake.checkWritableArgsForCall)
+}
+
+func (fake *FakeDatabase) CheckWritableArgsForCall(i int) lager.Logger {
+	fake.checkWritableMutex.RLock()
+	defer fake.checkWritableMutex.RUnlock()
+	return fake.checkWritableArgsForCall[i].logger
+}
+
+func (fake *FakeDatabase) CheckWritableReturns(result1 error) {
+	fake.CheckWritableStub = nil
+	fake.checkWritableReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeDatabase) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.pingMutex.RLock()
+	defer fake.pingMutex.RUnlock()
+	fake.pendingMigrationsMutex.RLock()
+	defer fake.pendingMigrationsMutex.RUnlock()
+	fake.checkWritableMutex.RLock()
+	defer fake.checkWritableMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDatabase) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ preflight.Database = new(FakeDatabase)
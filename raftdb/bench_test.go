@@ -0,0 +1,35 @@
+package raftdb_test
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/bbs/guidprovider"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db/conformance"
+	"code.cloudfoundry.org/locket/raftdb"
+)
+
+func BenchmarkHeartbeat(b *testing.B) {
+	conformance.BenchmarkHeartbeat(b, newBenchmarkBackend(b))
+}
+
+func BenchmarkFetchAll10k(b *testing.B) {
+	conformance.BenchmarkFetchAll(b, newBenchmarkBackend(b), 10000)
+}
+
+func newBenchmarkBackend(b *testing.B) conformance.Backend {
+	fakeClock := fakeclock.NewFakeClock(time.Now())
+	raftDB, err := raftdb.NewInMemoryRaftDB(lager.NewLogger("benchmark"), guidprovider.DefaultGuidProvider, fakeClock)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { raftDB.Shutdown() })
+
+	err = raftDB.WaitForLeader(5 * time.Second)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return conformance.Backend{LockDB: raftDB, Clock: fakeClock}
+}
@@ -0,0 +1,34 @@
+package raftdb_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/guidprovider/fakes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db/conformance"
+	"code.cloudfoundry.org/locket/raftdb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RaftDB conformance", func() {
+	var raftDB *raftdb.RaftDB
+
+	AfterEach(func() {
+		Expect(raftDB.Shutdown()).To(Succeed())
+	})
+
+	conformance.ItBehavesLikeALockDB(func() conformance.Backend {
+		fakeGUIDProvider := &fakes.FakeGUIDProvider{}
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+		fakeClock := fakeclock.NewFakeClock(time.Now())
+
+		var err error
+		raftDB, err = raftdb.NewInMemoryRaftDB(lagertest.NewTestLogger("raft-db"), fakeGUIDProvider, fakeClock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(raftDB.WaitForLeader(5 * time.Second)).To(Succeed())
+
+		return conformance.Backend{LockDB: raftDB, Clock: fakeClock}
+	})
+})
@@ -0,0 +1,423 @@
+package raftdb
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/hashicorp/raft"
+)
+
+const (
+	lockOp          = "lock"
+	lockGroupOp     = "lock-group"
+	releaseOp       = "release"
+	lockSharedOp    = "lock-shared"
+	releaseSharedOp = "release-shared"
+	expireOp        = "expire"
+)
+
+// command is the entry written to the raft log. Everything that is not
+// deterministic (the current time, new guids) is decided by the node that
+// proposes the command so that every replica applies it identically.
+type command struct {
+	Op                string             `json:"op"`
+	Resource          *models.Resource   `json:"resource,omitempty"`
+	Resources         []*models.Resource `json:"resources,omitempty"`
+	TtlInSeconds      int64              `json:"ttl_in_seconds,omitempty"`
+	TtlInMilliseconds int64              `json:"ttl_in_milliseconds,omitempty"`
+	Guid              string             `json:"guid,omitempty"`
+	Capacity          int                `json:"capacity,omitempty"`
+	ExpectedValue     string             `json:"expected_value,omitempty"`
+	ExpectedToken     int64              `json:"expected_token,omitempty"`
+	Grace             int64              `json:"grace,omitempty"`
+	Now               int64              `json:"now"`
+}
+
+type lockRecord struct {
+	Key               string            `json:"key"`
+	Owner             string            `json:"owner"`
+	Value             string            `json:"value"`
+	Type              string            `json:"type"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	ModifiedIndex     int64             `json:"modified_index"`
+	ModifiedId        string            `json:"modified_id"`
+	TtlInSeconds      int64             `json:"ttl"`
+	TtlInMilliseconds int64             `json:"ttl_in_milliseconds,omitempty"`
+	FencingToken      int64             `json:"fencing_token,omitempty"`
+	ExpiresAt         int64             `json:"expires_at"`
+	AcquiredAt        int64             `json:"acquired_at,omitempty"`
+	ReservedUntil     int64             `json:"reserved_until,omitempty"`
+	Shared            bool              `json:"shared,omitempty"`
+}
+
+func (r *lockRecord) toLock() *db.Lock {
+	return &db.Lock{
+		Resource: &models.Resource{
+			Key:      r.Key,
+			Owner:    r.Owner,
+			Value:    r.Value,
+			Type:     r.Type,
+			TypeCode: models.GetTypeCode(r.Type),
+			Metadata: r.Metadata,
+		},
+		ModifiedIndex:     r.ModifiedIndex,
+		ModifiedId:        r.ModifiedId,
+		TtlInSeconds:      r.TtlInSeconds,
+		TtlInMilliseconds: r.TtlInMilliseconds,
+		FencingToken:      r.FencingToken,
+		ExpiresAt:         r.ExpiresAt,
+		AcquiredAt:        r.AcquiredAt,
+		ReservedUntil:     r.ReservedUntil,
+	}
+}
+
+func (r *lockRecord) expired(now int64) bool {
+	return r.ExpiresAt > 0 && r.ExpiresAt <= now
+}
+
+// reserved reports whether an expired lock can still only be taken again by
+// its owner.
+func (r *lockRecord) reserved(now int64) bool {
+	return r.ReservedUntil > now
+}
+
+type applyResult struct {
+	locks []*db.Lock
+	err   error
+}
+
+// fsm holds the exclusive lock for each key in locks, and the shared holds
+// for each key in shared, indexed by key and then owner.
+type fsm struct {
+	mutex  *sync.RWMutex
+	locks  map[string]*lockRecord
+	shared map[string]map[string]*lockRecord
+}
+
+func newFSM() *fsm {
+	return &fsm{
+		mutex:  &sync.RWMutex{},
+		locks:  make(map[string]*lockRecord),
+		shared: make(map[string]map[string]*lockRecord),
+	}
+}
+
+func (f *fsm) Apply(log *raft.Log) interface{} {
+	var cmd command
+	err := json.Unmarshal(log.Data, &cmd)
+	if err != nil {
+		return applyResult{err: err}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch cmd.Op {
+	case lockOp:
+		return f.applyLock(cmd, int64(log.Index))
+	case lockGroupOp:
+		return f.applyLockGroup(cmd, int64(log.Index))
+	case releaseOp:
+		return f.applyRelease(cmd)
+	case lockSharedOp:
+		return f.applyLockShared(cmd)
+	case releaseSharedOp:
+		return f.applyReleaseShared(cmd)
+	case expireOp:
+		return f.applyExpire(cmd)
+	default:
+		return applyResult{err: ErrUnknownCommand}
+	}
+}
+
+// applyLock grants or refreshes a lock. The fencing token of a newly granted
+// lock is the index of the raft log entry that granted it, which increases
+// monotonically and is identical on every replica.
+func (f *fsm) applyLock(cmd command, logIndex int64) applyResult {
+	resource := models.GetResource(cmd.Resource)
+
+	if holder := f.lockHolder(resource, cmd.Now); holder != nil {
+		return applyResult{locks: []*db.Lock{holder.toLock()}, err: models.ErrLockCollision}
+	}
+
+	return applyResult{locks: []*db.Lock{f.grantLock(cmd, resource, logIndex).toLock()}}
+}
+
+// applyLockGroup grants every lock in the command or, if any key is held by
+// another owner, none of them.
+func (f *fsm) applyLockGroup(cmd command, logIndex int64) applyResult {
+	resources := make([]*models.Resource, len(cmd.Resources))
+	for i := range cmd.Resources {
+		resources[i] = models.GetResource(cmd.Resources[i])
+		if holder := f.lockHolder(resources[i], cmd.Now); holder != nil {
+			return applyResult{locks: []*db.Lock{holder.toLock()}, err: models.ErrLockCollision}
+		}
+	}
+
+	locks := make([]*db.Lock, len(resources))
+	for i, resource := range resources {
+		locks[i] = f.grantLock(cmd, resource, logIndex).toLock()
+	}
+	return applyResult{locks: locks}
+}
+
+// lockHolder returns the record that prevents resource's owner from taking
+// an exclusive lock on its key, or nil if there is none.
+func (f *fsm) lockHolder(resource *models.Resource, now int64) *lockRecord {
+	if existing, ok := f.locks[resource.Key]; ok {
+		if existing.Owner != resource.Owner && (!existing.expired(now) || existing.reserved(now)) {
+			return existing
+		}
+	}
+
+	for owner, holder := range f.shared[resource.Key] {
+		if owner != resource.Owner && !holder.expired(now) {
+			return holder
+		}
+	}
+	return nil
+}
+
+func (f *fsm) grantLock(cmd command, resource *models.Resource, logIndex int64) *lockRecord {
+	var index int64
+	var id string
+	fencingToken := logIndex
+	acquiredAt := cmd.Now
+	if existing, ok := f.locks[resource.Key]; ok {
+		index = existing.ModifiedIndex
+		if existing.Owner == resource.Owner {
+			id = existing.ModifiedId
+			if existing.FencingToken > 0 {
+				fencingToken = existing.FencingToken
+			}
+			if existing.AcquiredAt > 0 && !existing.expired(cmd.Now) {
+				acquiredAt = existing.AcquiredAt
+			}
+		}
+	}
+
+	if id == "" {
+		id = cmd.Guid
+	}
+
+	record := &lockRecord{
+		Key:               resource.Key,
+		Owner:             resource.Owner,
+		Value:             resource.Value,
+		Type:              resource.Type,
+		Metadata:          resource.Metadata,
+		ModifiedIndex:     index + 1,
+		ModifiedId:        id,
+		TtlInSeconds:      cmd.TtlInSeconds,
+		TtlInMilliseconds: cmd.TtlInMilliseconds,
+		FencingToken:      fencingToken,
+		AcquiredAt:        acquiredAt,
+	}
+	record.ExpiresAt = cmd.Now + int64(record.toLock().TTL())
+	if cmd.Grace > 0 {
+		record.ReservedUntil = record.ExpiresAt + cmd.Grace
+	}
+	f.locks[record.Key] = record
+
+	return record
+}
+
+func (f *fsm) applyRelease(cmd command) applyResult {
+	existing, ok := f.locks[cmd.Resource.Key]
+	if !ok {
+		return applyResult{err: models.ErrResourceNotFound}
+	}
+
+	if existing.Owner != cmd.Resource.Owner {
+		return applyResult{err: models.ErrLockCollision}
+	}
+
+	condition := db.ReleaseCondition{Value: cmd.ExpectedValue, FencingToken: cmd.ExpectedToken}
+	if !condition.Matches(existing.toLock()) {
+		return applyResult{err: models.ErrReleaseConditionFailed}
+	}
+
+	delete(f.locks, cmd.Resource.Key)
+	return applyResult{}
+}
+
+// applyLockShared grants or refreshes a shared hold. Shared holds do not
+// carry a fencing token. When the command has a capacity, a new hold is only
+// granted while fewer than capacity live holds exist.
+func (f *fsm) applyLockShared(cmd command) applyResult {
+	resource := models.GetResource(cmd.Resource)
+
+	if existing, ok := f.locks[resource.Key]; ok {
+		if existing.Owner != resource.Owner && (!existing.expired(cmd.Now) || existing.reserved(cmd.Now)) {
+			return applyResult{locks: []*db.Lock{existing.toLock()}, err: models.ErrLockCollision}
+		}
+	}
+
+	holders, ok := f.shared[resource.Key]
+	if !ok {
+		holders = make(map[string]*lockRecord)
+		f.shared[resource.Key] = holders
+	}
+
+	var index int64
+	id := cmd.Guid
+	if existing, ok := holders[resource.Owner]; ok {
+		index, id = existing.ModifiedIndex, existing.ModifiedId
+	} else if cmd.Capacity > 0 {
+		var live *lockRecord
+		count := 0
+		for _, holder := range holders {
+			if !holder.expired(cmd.Now) {
+				live = holder
+				count++
+			}
+		}
+		if count >= cmd.Capacity {
+			return applyResult{locks: []*db.Lock{live.toLock()}, err: models.ErrLockCollision}
+		}
+	}
+
+	record := &lockRecord{
+		Key:               resource.Key,
+		Owner:             resource.Owner,
+		Value:             resource.Value,
+		Type:              resource.Type,
+		Metadata:          resource.Metadata,
+		ModifiedIndex:     index + 1,
+		ModifiedId:        id,
+		TtlInSeconds:      cmd.TtlInSeconds,
+		TtlInMilliseconds: cmd.TtlInMilliseconds,
+		Shared:            true,
+	}
+	record.ExpiresAt = cmd.Now + int64(record.toLock().TTL())
+	holders[record.Owner] = record
+
+	return applyResult{locks: []*db.Lock{record.toLock()}}
+}
+
+func (f *fsm) applyReleaseShared(cmd command) applyResult {
+	holders := f.shared[cmd.Resource.Key]
+	if _, ok := holders[cmd.Resource.Owner]; !ok {
+		return applyResult{err: models.ErrResourceNotFound}
+	}
+
+	delete(holders, cmd.Resource.Owner)
+	if len(holders) == 0 {
+		delete(f.shared, cmd.Resource.Key)
+	}
+	return applyResult{}
+}
+
+func (f *fsm) applyExpire(cmd command) applyResult {
+	var expired []*db.Lock
+	for key, record := range f.locks {
+		if record.expired(cmd.Now) && !record.reserved(cmd.Now) {
+			expired = append(expired, record.toLock())
+			delete(f.locks, key)
+		}
+	}
+	for key, holders := range f.shared {
+		for owner, record := range holders {
+			if record.expired(cmd.Now) {
+				expired = append(expired, record.toLock())
+				delete(holders, owner)
+			}
+		}
+		if len(holders) == 0 {
+			delete(f.shared, key)
+		}
+	}
+	return applyResult{locks: expired}
+}
+
+func (f *fsm) fetch(key string, now int64) (*db.Lock, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	record, ok := f.locks[key]
+	if !ok || record.expired(now) {
+		return nil, models.ErrResourceNotFound
+	}
+	return record.toLock(), nil
+}
+
+func (f *fsm) fetchAll(lockType string, now int64) []*db.Lock {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	var locks []*db.Lock
+	for _, record := range f.locks {
+		if record.expired(now) {
+			continue
+		}
+		if lockType != "" && record.Type != lockType {
+			continue
+		}
+		locks = append(locks, record.toLock())
+	}
+	return locks
+}
+
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	records := make([]lockRecord, 0, len(f.locks))
+	for _, record := range f.locks {
+		records = append(records, *record)
+	}
+	for _, holders := range f.shared {
+		for _, record := range holders {
+			records = append(records, *record)
+		}
+	}
+	return &fsmSnapshot{records: records}, nil
+}
+
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	var records []lockRecord
+	err := json.NewDecoder(rc).Decode(&records)
+	if err != nil {
+		return err
+	}
+
+	locks := make(map[string]*lockRecord, len(records))
+	shared := make(map[string]map[string]*lockRecord)
+	for i := range records {
+		record := &records[i]
+		if !record.Shared {
+			locks[record.Key] = record
+			continue
+		}
+		if shared[record.Key] == nil {
+			shared[record.Key] = make(map[string]*lockRecord)
+		}
+		shared[record.Key][record.Owner] = record
+	}
+
+	f.mutex.Lock()
+	f.locks = locks
+	f.shared = shared
+	f.mutex.Unlock()
+	return nil
+}
+
+type fsmSnapshot struct {
+	records []lockRecord
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	err := json.NewEncoder(sink).Encode(s.records)
+	if err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
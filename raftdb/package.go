@@ -0,0 +1 @@
+package raftdb // import "code.cloudfoundry.org/locket/raftdb"
@@ -0,0 +1,368 @@
+package raftdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/bbs/guidprovider"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	applyTimeout       = 5 * time.Second
+	transportTimeout   = 10 * time.Second
+	maxPool            = 3
+	snapshotsRetained  = 2
+	raftLogFilename    = "raft.db"
+	defaultRaftDataDir = "/var/vcap/store/locket/raft"
+
+	inMemoryElectionTimeout = 50 * time.Millisecond
+	leaderPollInterval      = 10 * time.Millisecond
+)
+
+// raftStore is both the log and the stable store of a raft server.
+type raftStore interface {
+	raft.LogStore
+	raft.StableStore
+}
+
+var (
+	ErrNotLeader      = grpc.Errorf(codes.Unavailable, "not the raft leader")
+	ErrUnknownCommand = errors.New("unknown raft command")
+)
+
+type Config struct {
+	BindAddress string   `json:"bind_address,omitempty"`
+	DataDir     string   `json:"data_dir,omitempty"`
+	Peers       []string `json:"peers,omitempty"`
+}
+
+// RaftDB is a LockDB that replicates lock state between locket servers using
+// an embedded raft log instead of an external SQL database. Writes must be
+// made on the leader; reads are served from the local replica.
+type RaftDB struct {
+	raft         *raft.Raft
+	fsm          *fsm
+	guidProvider guidprovider.GUIDProvider
+	clock        clock.Clock
+}
+
+func NewRaftDB(logger lager.Logger, config Config, guidProvider guidprovider.GUIDProvider, clock clock.Clock) (*RaftDB, error) {
+	logger = logger.Session("raft", lager.Data{"bind-address": config.BindAddress})
+
+	dataDir := config.DataDir
+	if dataDir == "" {
+		dataDir = defaultRaftDataDir
+	}
+
+	err := os.MkdirAll(dataDir, 0700)
+	if err != nil {
+		logger.Error("failed-to-create-data-dir", err)
+		return nil, err
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", config.BindAddress)
+	if err != nil {
+		logger.Error("failed-to-resolve-bind-address", err)
+		return nil, err
+	}
+
+	transport, err := raft.NewTCPTransport(config.BindAddress, addr, maxPool, transportTimeout, os.Stderr)
+	if err != nil {
+		logger.Error("failed-to-create-transport", err)
+		return nil, err
+	}
+
+	store, err := raftboltdb.NewBoltStore(filepath.Join(dataDir, raftLogFilename))
+	if err != nil {
+		logger.Error("failed-to-create-log-store", err)
+		return nil, err
+	}
+
+	snapshots, err := raft.NewFileSnapshotStore(dataDir, snapshotsRetained, os.Stderr)
+	if err != nil {
+		logger.Error("failed-to-create-snapshot-store", err)
+		return nil, err
+	}
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(config.BindAddress)
+
+	return newRaftDB(logger, raftConfig, store, snapshots, transport, config.Peers, guidProvider, clock)
+}
+
+// NewInMemoryRaftDB returns a RaftDB that is the only server in its cluster
+// and keeps its log and snapshots in memory, for tests and development.
+// Everything it holds is lost on Shutdown. Writes fail with ErrNotLeader
+// until it has elected itself leader, see WaitForLeader.
+func NewInMemoryRaftDB(logger lager.Logger, guidProvider guidprovider.GUIDProvider, clock clock.Clock) (*RaftDB, error) {
+	logger = logger.Session("raft", lager.Data{"in-memory": true})
+
+	store := raft.NewInmemStore()
+	addr, transport := raft.NewInmemTransport("")
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(addr)
+	// there are no other servers to hear from, so there is no reason to wait
+	// long before electing itself
+	raftConfig.HeartbeatTimeout = inMemoryElectionTimeout
+	raftConfig.ElectionTimeout = inMemoryElectionTimeout
+	raftConfig.LeaderLeaseTimeout = inMemoryElectionTimeout
+
+	return newRaftDB(logger, raftConfig, store, raft.NewInmemSnapshotStore(), transport, nil, guidProvider, clock)
+}
+
+func newRaftDB(
+	logger lager.Logger,
+	raftConfig *raft.Config,
+	store raftStore,
+	snapshots raft.SnapshotStore,
+	transport raft.Transport,
+	peers []string,
+	guidProvider guidprovider.GUIDProvider,
+	clock clock.Clock,
+) (*RaftDB, error) {
+	hasState, err := raft.HasExistingState(store, store, snapshots)
+	if err != nil {
+		logger.Error("failed-to-check-existing-state", err)
+		return nil, err
+	}
+
+	fsm := newFSM()
+	r, err := raft.NewRaft(raftConfig, fsm, store, store, snapshots, transport)
+	if err != nil {
+		logger.Error("failed-to-start-raft", err)
+		return nil, err
+	}
+
+	if !hasState {
+		servers := []raft.Server{{ID: raftConfig.LocalID, Address: transport.LocalAddr()}}
+		for _, peer := range peers {
+			if raft.ServerID(peer) == raftConfig.LocalID {
+				continue
+			}
+			servers = append(servers, raft.Server{ID: raft.ServerID(peer), Address: raft.ServerAddress(peer)})
+		}
+
+		logger.Info("bootstrapping-cluster", lager.Data{"peers": peers})
+		err = r.BootstrapCluster(raft.Configuration{Servers: servers}).Error()
+		if err != nil && err != raft.ErrCantBootstrap {
+			logger.Error("failed-to-bootstrap-cluster", err)
+			return nil, err
+		}
+	}
+
+	return &RaftDB{
+		raft:         r,
+		fsm:          fsm,
+		guidProvider: guidProvider,
+		clock:        clock,
+	}, nil
+}
+
+func (rdb *RaftDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	logger = logger.Session("lock", lager.Data{"key": resource.GetKey(), "owner": resource.GetOwner()})
+	return rdb.lock(logger, resource, ttl, 0)
+}
+
+func (rdb *RaftDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	logger = logger.Session("lock-with-grace", lager.Data{"key": resource.GetKey(), "owner": resource.GetOwner()})
+	return rdb.lock(logger, resource, ttl, grace)
+}
+
+func (rdb *RaftDB) lock(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	guid, err := rdb.guidProvider.NextGUID()
+	if err != nil {
+		logger.Error("failed-to-generate-guid", err)
+		return nil, err
+	}
+
+	ttlInSeconds, ttlInMilliseconds := db.NewTTL(ttl)
+	locks, err := rdb.apply(logger, command{
+		Op:                lockOp,
+		Resource:          resource,
+		TtlInSeconds:      ttlInSeconds,
+		TtlInMilliseconds: ttlInMilliseconds,
+		Guid:              guid,
+		Grace:             int64(grace),
+	})
+	if err == models.ErrLockCollision && len(locks) > 0 {
+		return locks[0], err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return locks[0], nil
+}
+
+// LockGroup locks every resource in a single raft log entry, so either all of
+// the locks are granted or none are.
+func (rdb *RaftDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error) {
+	logger = logger.Session("lock-group", lager.Data{"size": len(resources)})
+
+	guid, err := rdb.guidProvider.NextGUID()
+	if err != nil {
+		logger.Error("failed-to-generate-guid", err)
+		return nil, err
+	}
+
+	ttlInSeconds, ttlInMilliseconds := db.NewTTL(ttl)
+	return rdb.apply(logger, command{
+		Op:                lockGroupOp,
+		Resources:         resources,
+		TtlInSeconds:      ttlInSeconds,
+		TtlInMilliseconds: ttlInMilliseconds,
+		Guid:              guid,
+	})
+}
+
+func (rdb *RaftDB) Release(logger lager.Logger, resource *models.Resource) error {
+	return rdb.ReleaseIf(logger, resource, db.ReleaseCondition{})
+}
+
+func (rdb *RaftDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	logger = logger.Session("release-lock", lager.Data{"key": resource.GetKey(), "owner": resource.GetOwner()})
+
+	_, err := rdb.apply(logger, command{
+		Op:            releaseOp,
+		Resource:      resource,
+		ExpectedValue: condition.Value,
+		ExpectedToken: condition.FencingToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("released-lock")
+	return nil
+}
+
+func (rdb *RaftDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	logger = logger.Session("lock-shared", lager.Data{"key": resource.GetKey(), "owner": resource.GetOwner()})
+	return rdb.lockShared(logger, resource, 0, ttl)
+}
+
+func (rdb *RaftDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	logger = logger.Session("lock-semaphore", lager.Data{"key": resource.GetKey(), "owner": resource.GetOwner()})
+	return rdb.lockShared(logger, resource, capacity, ttl)
+}
+
+func (rdb *RaftDB) lockShared(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	guid, err := rdb.guidProvider.NextGUID()
+	if err != nil {
+		logger.Error("failed-to-generate-guid", err)
+		return nil, err
+	}
+
+	ttlInSeconds, ttlInMilliseconds := db.NewTTL(ttl)
+	locks, err := rdb.apply(logger, command{
+		Op:                lockSharedOp,
+		Resource:          resource,
+		TtlInSeconds:      ttlInSeconds,
+		TtlInMilliseconds: ttlInMilliseconds,
+		Guid:              guid,
+		Capacity:          capacity,
+	})
+	if err == models.ErrLockCollision && len(locks) > 0 {
+		return locks[0], err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return locks[0], nil
+}
+
+func (rdb *RaftDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	logger = logger.Session("release-shared-lock", lager.Data{"key": resource.GetKey(), "owner": resource.GetOwner()})
+
+	_, err := rdb.apply(logger, command{Op: releaseSharedOp, Resource: resource})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("released-shared-lock")
+	return nil
+}
+
+func (rdb *RaftDB) Fetch(logger lager.Logger, key string) (*db.Lock, error) {
+	return rdb.fsm.fetch(key, rdb.clock.Now().UnixNano())
+}
+
+func (rdb *RaftDB) FetchIncludingExpired(logger lager.Logger, key string) (*db.Lock, error) {
+	return rdb.fsm.fetch(key, 0)
+}
+
+func (rdb *RaftDB) FetchAll(logger lager.Logger, lockType string) ([]*db.Lock, error) {
+	return rdb.fsm.fetchAll(lockType, rdb.clock.Now().UnixNano()), nil
+}
+
+func (rdb *RaftDB) Count(logger lager.Logger, lockType string) (int, error) {
+	return len(rdb.fsm.fetchAll(lockType, rdb.clock.Now().UnixNano())), nil
+}
+
+// ExpireLocks removes expired locks. Only the leader proposes expirations, so
+// followers return no locks.
+func (rdb *RaftDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	if rdb.raft.State() != raft.Leader {
+		return nil, nil
+	}
+
+	logger = logger.Session("expire-locks")
+	return rdb.apply(logger, command{Op: expireOp})
+}
+
+// WaitForLeader waits up to timeout for this server to become the raft
+// leader, returning ErrNotLeader if it does not.
+func (rdb *RaftDB) WaitForLeader(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for rdb.raft.State() != raft.Leader {
+		if time.Now().After(deadline) {
+			return ErrNotLeader
+		}
+		time.Sleep(leaderPollInterval)
+	}
+	return nil
+}
+
+func (rdb *RaftDB) Shutdown() error {
+	return rdb.raft.Shutdown().Error()
+}
+
+func (rdb *RaftDB) apply(logger lager.Logger, cmd command) ([]*db.Lock, error) {
+	if rdb.raft.State() != raft.Leader {
+		return nil, ErrNotLeader
+	}
+
+	cmd.Now = rdb.clock.Now().UnixNano()
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		logger.Error("failed-to-marshal-command", err)
+		return nil, err
+	}
+
+	future := rdb.raft.Apply(data, applyTimeout)
+	err = future.Error()
+	if err != nil {
+		logger.Error("failed-to-apply-command", err)
+		if err == raft.ErrNotLeader || err == raft.ErrLeadershipLost {
+			return nil, ErrNotLeader
+		}
+		return nil, err
+	}
+
+	result := future.Response().(applyResult)
+	return result.locks, result.err
+}
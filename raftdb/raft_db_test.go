@@ -0,0 +1,459 @@
+package raftdb_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/bbs/guidprovider/fakes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/raftdb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RaftDB", func() {
+	var (
+		raftDB           *raftdb.RaftDB
+		logger           *lagertest.TestLogger
+		fakeClock        *fakeclock.FakeClock
+		fakeGUIDProvider *fakes.FakeGUIDProvider
+		dataDir          string
+		resource         *models.Resource
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		logger = lagertest.NewTestLogger("raft-db")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeGUIDProvider = &fakes.FakeGUIDProvider{}
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+
+		dataDir, err = ioutil.TempDir("", "raft")
+		Expect(err).NotTo(HaveOccurred())
+
+		config := raftdb.Config{
+			BindAddress: fmt.Sprintf("127.0.0.1:%d", 20000+GinkgoParallelNode()),
+			DataDir:     dataDir,
+		}
+
+		raftDB, err = raftdb.NewRaftDB(logger, config, fakeGUIDProvider, fakeClock)
+		Expect(err).NotTo(HaveOccurred())
+
+		// ensures raftDB matches the db.LockDB interface
+		var _ db.LockDB = raftDB
+
+		Eventually(func() error {
+			_, err := raftDB.Lock(logger, &models.Resource{Key: "warmup", Owner: "warmup", Type: models.LockType}, time.Second)
+			return err
+		}, 10*time.Second).Should(Succeed())
+		Expect(raftDB.Release(logger, &models.Resource{Key: "warmup", Owner: "warmup"})).To(Succeed())
+
+		resource = &models.Resource{
+			Key:   "quack",
+			Owner: "iamthelizardking",
+			Value: "i can do anything",
+			Type:  models.LockType,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(raftDB.Shutdown()).To(Succeed())
+		Expect(os.RemoveAll(dataDir)).To(Succeed())
+	})
+
+	Context("Lock", func() {
+		It("acquires the lock", func() {
+			lock, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ModifiedIndex).To(BeEquivalentTo(1))
+			Expect(lock.ModifiedId).To(Equal("new-guid"))
+			Expect(lock.TtlInSeconds).To(BeEquivalentTo(10))
+			Expect(lock.Owner).To(Equal(resource.Owner))
+			Expect(lock.FencingToken).To(BeNumerically(">", 0))
+			Expect(lock.ExpiresAt).To(Equal(fakeClock.Now().Add(10 * time.Second).UnixNano()))
+		})
+
+		It("stores the resource metadata", func() {
+			resource.Metadata = map[string]string{"az": "z1"}
+
+			_, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			lock, err := raftDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Metadata).To(Equal(map[string]string{"az": "z1"}))
+		})
+
+		It("keeps the fencing token when the owner refreshes the lock", func() {
+			lock, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			refreshed, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refreshed.FencingToken).To(Equal(lock.FencingToken))
+		})
+
+		It("keeps the time the lock was acquired when the owner refreshes it", func() {
+			lock, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.AcquiredAt).To(Equal(fakeClock.Now().UnixNano()))
+
+			fakeClock.Increment(5 * time.Second)
+
+			refreshed, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refreshed.AcquiredAt).To(Equal(lock.AcquiredAt))
+		})
+
+		Context("when the lock is held by another owner", func() {
+			var held *db.Lock
+
+			BeforeEach(func() {
+				var err error
+				held, err = raftDB.Lock(logger, resource, 10*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a collision error", func() {
+				_, err := raftDB.Lock(logger, &models.Resource{Key: "quack", Owner: "jim", Type: models.LockType}, 10*time.Second)
+				Expect(err).To(Equal(models.ErrLockCollision))
+			})
+
+			It("returns the current holder", func() {
+				holder, err := raftDB.Lock(logger, &models.Resource{Key: "quack", Owner: "jim", Type: models.LockType}, 10*time.Second)
+				Expect(err).To(Equal(models.ErrLockCollision))
+				Expect(holder.Owner).To(Equal(resource.Owner))
+				Expect(holder.FencingToken).To(Equal(held.FencingToken))
+			})
+
+			Context("and the lock has expired", func() {
+				BeforeEach(func() {
+					fakeGUIDProvider.NextGUIDReturns("another-new-guid", nil)
+					fakeClock.Increment(10 * time.Second)
+				})
+
+				It("grants the lock to the new owner", func() {
+					lock, err := raftDB.Lock(logger, &models.Resource{Key: "quack", Owner: "jim", Type: models.LockType}, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.ModifiedIndex).To(BeEquivalentTo(2))
+					Expect(lock.ModifiedId).To(Equal("another-new-guid"))
+				})
+
+				It("issues a greater fencing token", func() {
+					lock, err := raftDB.Lock(logger, &models.Resource{Key: "quack", Owner: "jim", Type: models.LockType}, 10*time.Second)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(lock.FencingToken).To(BeNumerically(">", held.FencingToken))
+				})
+			})
+		})
+	})
+
+	Context("LockWithGrace", func() {
+		var other *models.Resource
+
+		BeforeEach(func() {
+			other = &models.Resource{Key: resource.Key, Owner: "jim", Type: models.LockType}
+
+			_, err := raftDB.LockWithGrace(logger, resource, 10*time.Second, 5*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reserves the expired lock for its owner until the grace window ends", func() {
+			fakeClock.Increment(10 * time.Second)
+
+			holder, err := raftDB.Lock(logger, other, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(Equal(resource.Owner))
+
+			_, err = raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lets other owners take the lock once the grace window ends", func() {
+			fakeClock.Increment(15 * time.Second)
+
+			_, err := raftDB.Lock(logger, other, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("is not expired until the grace window ends", func() {
+			fakeClock.Increment(10 * time.Second)
+
+			locks, err := raftDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(BeEmpty())
+
+			fakeClock.Increment(5 * time.Second)
+
+			locks, err = raftDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+		})
+	})
+
+	Context("LockGroup", func() {
+		var other *models.Resource
+
+		BeforeEach(func() {
+			other = &models.Resource{Key: "another", Owner: resource.Owner, Type: models.LockType}
+		})
+
+		It("locks every resource", func() {
+			locks, err := raftDB.LockGroup(logger, []*models.Resource{resource, other}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(2))
+			Expect(locks[0].Key).To(Equal(resource.Key))
+			Expect(locks[1].Key).To(Equal(other.Key))
+		})
+
+		It("locks none of them when one is held by another owner", func() {
+			_, err := raftDB.Lock(logger, &models.Resource{Key: "another", Owner: "jim", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			locks, err := raftDB.LockGroup(logger, []*models.Resource{resource, other}, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(locks[0].Owner).To(Equal("jim"))
+
+			_, err = raftDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+	})
+
+	Context("LockShared", func() {
+		var reader *models.Resource
+
+		BeforeEach(func() {
+			reader = &models.Resource{Key: "quack", Owner: "reader-1", Type: models.LockType}
+		})
+
+		It("lets several owners hold the key", func() {
+			lock, err := raftDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.ModifiedIndex).To(BeEquivalentTo(1))
+			Expect(lock.FencingToken).To(BeZero())
+
+			_, err = raftDB.LockShared(logger, &models.Resource{Key: "quack", Owner: "reader-2", Type: models.LockType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("blocks an exclusive lock until the shared holds are released", func() {
+			_, err := raftDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			holder, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(Equal("reader-1"))
+
+			Expect(raftDB.ReleaseShared(logger, reader)).To(Succeed())
+
+			_, err = raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("blocks an exclusive lock until the shared holds expire", func() {
+			_, err := raftDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.Increment(10 * time.Second)
+
+			_, err = raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("is refused while another owner holds the key exclusively", func() {
+			_, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			holder, err := raftDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+			Expect(holder.Owner).To(Equal(resource.Owner))
+		})
+
+		It("is not reported by Fetch", func() {
+			_, err := raftDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = raftDB.Fetch(logger, reader.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+
+		It("expires shared holds whose ttl has passed", func() {
+			_, err := raftDB.LockShared(logger, reader, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.Increment(10 * time.Second)
+
+			locks, err := raftDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+			Expect(locks[0].Owner).To(Equal("reader-1"))
+		})
+
+		It("returns not found when releasing a hold that does not exist", func() {
+			err := raftDB.ReleaseShared(logger, reader)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+	})
+
+	Context("LockSemaphore", func() {
+		It("grants up to capacity owners a slot", func() {
+			_, err := raftDB.LockSemaphore(logger, &models.Resource{Key: "migrations", Owner: "a"}, 2, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = raftDB.LockSemaphore(logger, &models.Resource{Key: "migrations", Owner: "b"}, 2, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = raftDB.LockSemaphore(logger, &models.Resource{Key: "migrations", Owner: "c"}, 2, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+
+			_, err = raftDB.LockSemaphore(logger, &models.Resource{Key: "migrations", Owner: "a"}, 2, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("frees a slot once it is released", func() {
+			_, err := raftDB.LockSemaphore(logger, &models.Resource{Key: "migrations", Owner: "a"}, 1, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raftDB.ReleaseShared(logger, &models.Resource{Key: "migrations", Owner: "a"})).To(Succeed())
+
+			_, err = raftDB.LockSemaphore(logger, &models.Resource{Key: "migrations", Owner: "b"}, 1, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Fetch", func() {
+		BeforeEach(func() {
+			_, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the lock", func() {
+			lock, err := raftDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock.Resource).To(Equal(&models.Resource{
+				Key:      resource.Key,
+				Owner:    resource.Owner,
+				Value:    resource.Value,
+				Type:     resource.Type,
+				TypeCode: models.LOCK,
+			}))
+		})
+
+		It("returns not found for missing locks", func() {
+			_, err := raftDB.Fetch(logger, "missing")
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+	})
+
+	Context("FetchAll and Count", func() {
+		BeforeEach(func() {
+			_, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = raftDB.Lock(logger, &models.Resource{Key: "cell", Owner: "cell-1", Type: models.PresenceType}, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("filters by type", func() {
+			locks, err := raftDB.FetchAll(logger, models.PresenceType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+			Expect(locks[0].Key).To(Equal("cell"))
+
+			count, err := raftDB.Count(logger, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+	})
+
+	Context("Release", func() {
+		BeforeEach(func() {
+			_, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("removes the lock", func() {
+			Expect(raftDB.Release(logger, resource)).To(Succeed())
+			_, err := raftDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+
+		It("does not release locks held by another owner", func() {
+			err := raftDB.Release(logger, &models.Resource{Key: "quack", Owner: "jim"})
+			Expect(err).To(Equal(models.ErrLockCollision))
+		})
+
+		It("releases the lock when it matches the condition", func() {
+			lock, err := raftDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = raftDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: resource.Value, FencingToken: lock.FencingToken})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = raftDB.Fetch(logger, resource.Key)
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+		})
+
+		It("does not release the lock when it does not match the condition", func() {
+			err := raftDB.ReleaseIf(logger, resource, db.ReleaseCondition{Value: "something else"})
+			Expect(err).To(Equal(models.ErrReleaseConditionFailed))
+			_, err = raftDB.Fetch(logger, resource.Key)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("ExpireLocks", func() {
+		BeforeEach(func() {
+			_, err := raftDB.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("only expires locks whose ttl has passed", func() {
+			locks, err := raftDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(BeEmpty())
+
+			fakeClock.Increment(10 * time.Second)
+
+			locks, err = raftDB.ExpireLocks(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(HaveLen(1))
+			Expect(locks[0].Key).To(Equal(resource.Key))
+		})
+	})
+})
+
+var _ = Describe("In memory RaftDB", func() {
+	var (
+		logger *lagertest.TestLogger
+		raftDB *raftdb.RaftDB
+	)
+
+	BeforeEach(func() {
+		var err error
+		logger = lagertest.NewTestLogger("raft-db")
+		fakeGUIDProvider := &fakes.FakeGUIDProvider{}
+		fakeGUIDProvider.NextGUIDReturns("new-guid", nil)
+
+		raftDB, err = raftdb.NewInMemoryRaftDB(logger, fakeGUIDProvider, fakeclock.NewFakeClock(time.Now()))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(raftDB.Shutdown()).To(Succeed())
+	})
+
+	It("elects itself leader and serves locks", func() {
+		Expect(raftDB.WaitForLeader(5 * time.Second)).To(Succeed())
+
+		resource := &models.Resource{Key: "quack", Owner: "iamthelizardking", Type: models.LockType}
+		_, err := raftDB.Lock(logger, resource, 10*time.Second)
+		Expect(err).NotTo(HaveOccurred())
+
+		lock, err := raftDB.Fetch(logger, "quack")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lock.Owner).To(Equal("iamthelizardking"))
+	})
+})
@@ -0,0 +1,13 @@
+package raftdb_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRaftdb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RaftDB Suite")
+}
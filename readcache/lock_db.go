@@ -0,0 +1,339 @@
+package readcache
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/metrics/emitter"
+	"code.cloudfoundry.org/locket/models"
+)
+
+const (
+	cacheHits   = "ReadCacheHits"
+	cacheMisses = "ReadCacheMisses"
+)
+
+// maxCachedKeys bounds the number of keys whose Fetch result is cached, so
+// that clients fetching many different keys cannot grow the cache without
+// limit.
+const maxCachedKeys = 10000
+
+type fetchEntry struct {
+	lock      *db.Lock
+	err       error
+	fetchedAt time.Time
+}
+
+type fetchAllEntry struct {
+	locks     []*db.Lock
+	fetchedAt time.Time
+}
+
+// fetch tracks the Fetch queries in flight for a key, so that a write to
+// the key while they run stops them from caching what they read.
+type fetch struct {
+	count int
+	stale bool
+}
+
+type lockDB struct {
+	db.LockDB
+	clock        clock.Clock
+	metronClient emitter.Emitter
+	maxStaleness time.Duration
+
+	mutex sync.Mutex
+	locks map[string]fetchEntry
+	lists map[string]fetchAllEntry
+
+	fetching map[string]*fetch
+	// generation increases whenever the cached FetchAll results are
+	// invalidated, so that those in flight are not cached either
+	generation uint64
+}
+
+// NewLockDB wraps lockDB so that Fetch and FetchAll are answered from memory
+// when the same query was answered less than maxStaleness ago. Writes made
+// through the returned LockDB invalidate the results they affect, so the
+// cache is only stale for writes made elsewhere, such as on another server.
+// Locks that have expired since they were cached are never returned. Each
+// read emits a ReadCacheHits or ReadCacheMisses counter.
+func NewLockDB(lockDB db.LockDB, clock clock.Clock, metronClient emitter.Emitter, maxStaleness time.Duration) db.LockDB {
+	return &lockDB{
+		LockDB:       lockDB,
+		clock:        clock,
+		metronClient: metronClient,
+		maxStaleness: maxStaleness,
+		locks:        make(map[string]fetchEntry),
+		lists:        make(map[string]fetchAllEntry),
+		fetching:     make(map[string]*fetch),
+	}
+}
+
+func (c *lockDB) Fetch(logger lager.Logger, key string) (*db.Lock, error) {
+	now := c.clock.Now()
+
+	c.mutex.Lock()
+	entry, ok := c.locks[key]
+	if ok && c.fresh(entry.fetchedAt, now) && (entry.err != nil || !expired(entry.lock, now)) {
+		c.mutex.Unlock()
+		c.increment(logger, cacheHits)
+		return entry.lock, entry.err
+	}
+
+	f, ok := c.fetching[key]
+	if !ok {
+		f = &fetch{}
+		c.fetching[key] = f
+	}
+	f.count++
+	c.mutex.Unlock()
+
+	c.increment(logger, cacheMisses)
+	lock, err := c.LockDB.Fetch(logger, key)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	f.count--
+	if f.count == 0 {
+		delete(c.fetching, key)
+	}
+	if f.stale || (err != nil && err != models.ErrResourceNotFound) {
+		return lock, err
+	}
+
+	if len(c.locks) >= maxCachedKeys {
+		c.pruneLocks(now)
+	}
+	if len(c.locks) < maxCachedKeys {
+		c.locks[key] = fetchEntry{lock: lock, err: err, fetchedAt: now}
+	}
+
+	return lock, err
+}
+
+func (c *lockDB) FetchAll(logger lager.Logger, lockType string) ([]*db.Lock, error) {
+	now := c.clock.Now()
+
+	c.mutex.Lock()
+	entry, ok := c.lists[lockType]
+	generation := c.generation
+	c.mutex.Unlock()
+
+	if ok && c.fresh(entry.fetchedAt, now) {
+		c.increment(logger, cacheHits)
+
+		var locks []*db.Lock
+		for _, lock := range entry.locks {
+			if !expired(lock, now) {
+				locks = append(locks, lock)
+			}
+		}
+		return locks, nil
+	}
+
+	c.increment(logger, cacheMisses)
+	locks, err := c.LockDB.FetchAll(logger, lockType)
+	if err != nil {
+		return locks, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generation == generation {
+		c.lists[lockType] = fetchAllEntry{locks: locks, fetchedAt: now}
+	}
+
+	return locks, nil
+}
+
+func (c *lockDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	start := c.clock.Now()
+	lock, err := c.LockDB.Lock(logger, resource, ttl)
+	c.locked(start, lock, err, resource.GetKey())
+	return lock, err
+}
+
+func (c *lockDB) LockWithGrace(logger lager.Logger, resource *models.Resource, ttl, grace time.Duration) (*db.Lock, error) {
+	start := c.clock.Now()
+	lock, err := c.LockDB.LockWithGrace(logger, resource, ttl, grace)
+	c.locked(start, lock, err, resource.GetKey())
+	return lock, err
+}
+
+func (c *lockDB) LockGroup(logger lager.Logger, resources []*models.Resource, ttl time.Duration) ([]*db.Lock, error) {
+	start := c.clock.Now()
+	locks, err := c.LockDB.LockGroup(logger, resources, ttl)
+	if err != nil {
+		keys := make([]string, len(resources))
+		for i, resource := range resources {
+			keys[i] = resource.GetKey()
+		}
+		c.locked(start, nil, err, keys...)
+		return locks, err
+	}
+
+	for _, lock := range locks {
+		c.locked(start, lock, nil, lock.Key)
+	}
+	return locks, nil
+}
+
+func (c *lockDB) LockShared(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	defer c.invalidate(resource.GetKey())
+	return c.LockDB.LockShared(logger, resource, ttl)
+}
+
+func (c *lockDB) LockSemaphore(logger lager.Logger, resource *models.Resource, capacity int, ttl time.Duration) (*db.Lock, error) {
+	defer c.invalidate(resource.GetKey())
+	return c.LockDB.LockSemaphore(logger, resource, capacity, ttl)
+}
+
+func (c *lockDB) Release(logger lager.Logger, resource *models.Resource) error {
+	defer c.invalidate(resource.GetKey())
+	return c.LockDB.Release(logger, resource)
+}
+
+func (c *lockDB) ReleaseIf(logger lager.Logger, resource *models.Resource, condition db.ReleaseCondition) error {
+	defer c.invalidate(resource.GetKey())
+	return c.LockDB.ReleaseIf(logger, resource, condition)
+}
+
+func (c *lockDB) ReleaseShared(logger lager.Logger, resource *models.Resource) error {
+	defer c.invalidate(resource.GetKey())
+	return c.LockDB.ReleaseShared(logger, resource)
+}
+
+func (c *lockDB) ExpireLocks(logger lager.Logger) ([]*db.Lock, error) {
+	expired, err := c.LockDB.ExpireLocks(logger)
+
+	keys := make([]string, len(expired))
+	for i, lock := range expired {
+		keys[i] = lock.Key
+	}
+	c.invalidate(keys...)
+
+	return expired, err
+}
+
+// locked updates the cache after a write to keys that started at start and
+// returned lock. A collision writes nothing. When the write refreshed a hold
+// that is cached without changing it, the cached copies are replaced with
+// lock, so that the heartbeats that make up most writes keep the cache warm.
+// Anything else invalidates keys.
+func (c *lockDB) locked(start time.Time, lock *db.Lock, err error, keys ...string) {
+	if err == models.ErrLockCollision {
+		return
+	}
+	if err != nil || lock == nil || lock.AcquiredAt >= start.UnixNano() {
+		c.invalidate(keys...)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if f, ok := c.fetching[lock.Key]; ok {
+		f.stale = true
+	}
+
+	entry, ok := c.locks[lock.Key]
+	if ok && (entry.err != nil || !sameHold(entry.lock, lock)) {
+		c.invalidateLocked(lock.Key)
+		return
+	}
+
+	positions := make(map[string]int, len(c.lists))
+	for lockType, list := range c.lists {
+		if lockType != "" && lockType != lock.Type {
+			continue
+		}
+
+		position := -1
+		for i, cached := range list.locks {
+			if cached.Key == lock.Key {
+				position = i
+				break
+			}
+		}
+		if position < 0 || !sameHold(list.locks[position], lock) {
+			c.invalidateLocked(lock.Key)
+			return
+		}
+		positions[lockType] = position
+	}
+
+	if ok {
+		c.locks[lock.Key] = fetchEntry{lock: lock, fetchedAt: entry.fetchedAt}
+	}
+	for lockType, position := range positions {
+		list := c.lists[lockType]
+		locks := make([]*db.Lock, len(list.locks))
+		copy(locks, list.locks)
+		locks[position] = lock
+		c.lists[lockType] = fetchAllEntry{locks: locks, fetchedAt: list.fetchedAt}
+	}
+}
+
+// invalidate drops the cached results for keys and every cached FetchAll,
+// and stops reads that were in flight from caching what they return, since
+// they may have read the database before the write.
+func (c *lockDB) invalidate(keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.invalidateLocked(keys...)
+}
+
+func (c *lockDB) invalidateLocked(keys ...string) {
+	c.generation++
+	for _, key := range keys {
+		delete(c.locks, key)
+		if f, ok := c.fetching[key]; ok {
+			f.stale = true
+		}
+	}
+	c.lists = make(map[string]fetchAllEntry)
+}
+
+func (c *lockDB) pruneLocks(now time.Time) {
+	for key, entry := range c.locks {
+		if !c.fresh(entry.fetchedAt, now) {
+			delete(c.locks, key)
+		}
+	}
+}
+
+func (c *lockDB) fresh(fetchedAt, now time.Time) bool {
+	return now.Sub(fetchedAt) < c.maxStaleness
+}
+
+func (c *lockDB) increment(logger lager.Logger, counter string) {
+	err := c.metronClient.IncrementCounter(counter)
+	if err != nil {
+		logger.Error("failed-sending-read-cache-metric", err, lager.Data{"metric": counter})
+	}
+}
+
+// sameHold reports whether a and b are the same hold of a lock, with the
+// same contents, so that they only differ in their expiry.
+func sameHold(a, b *db.Lock) bool {
+	if a.Owner != b.Owner || a.ModifiedId != b.ModifiedId || a.FencingToken != b.FencingToken {
+		return false
+	}
+	if a.Value != b.Value || a.Type != b.Type || a.TTL() != b.TTL() {
+		return false
+	}
+	if len(a.Metadata) != 0 || len(b.Metadata) != 0 {
+		return reflect.DeepEqual(a.Metadata, b.Metadata)
+	}
+	return true
+}
+
+func expired(lock *db.Lock, now time.Time) bool {
+	return lock.ExpiresAt > 0 && lock.ExpiresAt <= now.UnixNano()
+}
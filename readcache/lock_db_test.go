@@ -0,0 +1,244 @@
+package readcache_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/db/dbfakes"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/readcache"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LockDB", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeLockDB       *dbfakes.FakeLockDB
+		fakeClock        *fakeclock.FakeClock
+		fakeMetronClient *mfakes.FakeIngressClient
+		cache            db.LockDB
+		resource         *models.Resource
+		held             *db.Lock
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("read-cache")
+		fakeLockDB = &dbfakes.FakeLockDB{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		cache = readcache.NewLockDB(fakeLockDB, fakeClock, fakeMetronClient, time.Second)
+
+		resource = &models.Resource{Key: "leader", Owner: "cell-1", Value: "10.0.0.1", Type: models.LockType, TypeCode: models.LOCK}
+		held = &db.Lock{
+			Resource:          resource,
+			ModifiedIndex:     1,
+			ModifiedId:        "guid",
+			TtlInSeconds:      10,
+			TtlInMilliseconds: 10000,
+			FencingToken:      1,
+			ExpiresAt:         fakeClock.Now().Add(10 * time.Second).UnixNano(),
+			AcquiredAt:        fakeClock.Now().UnixNano(),
+		}
+		fakeLockDB.FetchReturns(held, nil)
+		fakeLockDB.FetchAllReturns([]*db.Lock{held}, nil)
+	})
+
+	Describe("Fetch", func() {
+		It("answers the same fetch from memory until it is stale", func() {
+			for i := 0; i < 3; i++ {
+				lock, err := cache.Fetch(logger, "leader")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lock).To(Equal(held))
+			}
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(1))
+
+			fakeClock.Increment(time.Second)
+			_, err := cache.Fetch(logger, "leader")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+
+		It("emits cache hits and misses", func() {
+			cache.Fetch(logger, "leader")
+			cache.Fetch(logger, "leader")
+
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("ReadCacheMisses"))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal("ReadCacheHits"))
+		})
+
+		It("caches keys that are not held", func() {
+			fakeLockDB.FetchReturns(nil, models.ErrResourceNotFound)
+
+			_, err := cache.Fetch(logger, "leader")
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+			_, err = cache.Fetch(logger, "leader")
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(1))
+		})
+
+		It("does not cache other errors", func() {
+			fakeLockDB.FetchReturns(nil, errors.New("boom"))
+
+			cache.Fetch(logger, "leader")
+			cache.Fetch(logger, "leader")
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+
+		It("does not return a cached lock that has expired", func() {
+			held.ExpiresAt = fakeClock.Now().Add(500 * time.Millisecond).UnixNano()
+			cache.Fetch(logger, "leader")
+
+			fakeClock.Increment(500 * time.Millisecond)
+			fakeLockDB.FetchReturns(nil, models.ErrResourceNotFound)
+
+			_, err := cache.Fetch(logger, "leader")
+			Expect(err).To(Equal(models.ErrResourceNotFound))
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+
+		It("is invalidated by a release", func() {
+			cache.Fetch(logger, "leader")
+
+			Expect(cache.Release(logger, resource)).To(Succeed())
+			cache.Fetch(logger, "leader")
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+
+		It("is invalidated when another owner takes the lock", func() {
+			cache.Fetch(logger, "leader")
+			fakeClock.Increment(100 * time.Millisecond)
+
+			taken := &db.Lock{
+				Resource:     &models.Resource{Key: "leader", Owner: "cell-2", Type: models.LockType},
+				ModifiedId:   "other-guid",
+				FencingToken: 2,
+				AcquiredAt:   fakeClock.Now().UnixNano(),
+			}
+			fakeLockDB.LockReturns(taken, nil)
+			_, err := cache.Lock(logger, taken.Resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			cache.Fetch(logger, "leader")
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+
+		It("keeps the lock cached, with its new expiry, when its owner refreshes it", func() {
+			cache.Fetch(logger, "leader")
+			fakeClock.Increment(100 * time.Millisecond)
+
+			refreshed := *held
+			refreshed.ModifiedIndex = 2
+			refreshed.ExpiresAt = fakeClock.Now().Add(10 * time.Second).UnixNano()
+			fakeLockDB.LockReturns(&refreshed, nil)
+			_, err := cache.Lock(logger, resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			lock, err := cache.Fetch(logger, "leader")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock).To(Equal(&refreshed))
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(1))
+		})
+
+		It("is invalidated when its owner changes its value", func() {
+			cache.Fetch(logger, "leader")
+			fakeClock.Increment(100 * time.Millisecond)
+
+			changed := *held
+			changed.Resource = &models.Resource{Key: "leader", Owner: "cell-1", Value: "10.0.0.2", Type: models.LockType}
+			fakeLockDB.LockReturns(&changed, nil)
+			_, err := cache.Lock(logger, changed.Resource, 10*time.Second)
+			Expect(err).NotTo(HaveOccurred())
+
+			cache.Fetch(logger, "leader")
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+
+		It("is not changed by a collision", func() {
+			cache.Fetch(logger, "leader")
+
+			fakeLockDB.LockReturns(held, models.ErrLockCollision)
+			_, err := cache.Lock(logger, &models.Resource{Key: "leader", Owner: "cell-2"}, 10*time.Second)
+			Expect(err).To(Equal(models.ErrLockCollision))
+
+			cache.Fetch(logger, "leader")
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(1))
+		})
+
+		It("does not cache a fetch that ran while the key was written", func() {
+			fakeLockDB.FetchStub = func(logger lager.Logger, key string) (*db.Lock, error) {
+				Expect(cache.Release(logger, resource)).To(Succeed())
+				return held, nil
+			}
+			cache.Fetch(logger, "leader")
+
+			fakeLockDB.FetchStub = nil
+			cache.Fetch(logger, "leader")
+			Expect(fakeLockDB.FetchCallCount()).To(Equal(2))
+		})
+	})
+
+	Describe("FetchAll", func() {
+		It("answers the same fetch from memory until it is stale", func() {
+			locks, err := cache.FetchAll(logger, models.LockType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(ConsistOf(held))
+
+			locks, err = cache.FetchAll(logger, models.LockType)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(ConsistOf(held))
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(1))
+
+			cache.FetchAll(logger, models.PresenceType)
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(2))
+
+			fakeClock.Increment(time.Second)
+			cache.FetchAll(logger, models.LockType)
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(3))
+		})
+
+		It("leaves out cached locks that have expired", func() {
+			expiring := &db.Lock{
+				Resource:  &models.Resource{Key: "expiring", Owner: "cell-2", Type: models.LockType},
+				ExpiresAt: fakeClock.Now().Add(500 * time.Millisecond).UnixNano(),
+			}
+			fakeLockDB.FetchAllReturns([]*db.Lock{held, expiring}, nil)
+			cache.FetchAll(logger, "")
+
+			fakeClock.Increment(500 * time.Millisecond)
+			locks, err := cache.FetchAll(logger, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(ConsistOf(held))
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(1))
+		})
+
+		It("is invalidated by a write to any key", func() {
+			cache.FetchAll(logger, "")
+
+			Expect(cache.Release(logger, &models.Resource{Key: "other", Owner: "cell-2"})).To(Succeed())
+			cache.FetchAll(logger, "")
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(2))
+		})
+
+		It("keeps the list cached when an owner refreshes a lock in it", func() {
+			cache.FetchAll(logger, "")
+			fakeClock.Increment(100 * time.Millisecond)
+
+			refreshed := *held
+			refreshed.ExpiresAt = fakeClock.Now().Add(10 * time.Second).UnixNano()
+			fakeLockDB.LockReturns(&refreshed, nil)
+			cache.Lock(logger, resource, 10*time.Second)
+
+			locks, err := cache.FetchAll(logger, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locks).To(ConsistOf(&refreshed))
+			Expect(fakeLockDB.FetchAllCallCount()).To(Equal(1))
+		})
+	})
+})
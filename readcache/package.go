@@ -0,0 +1 @@
+package readcache // import "code.cloudfoundry.org/locket/readcache"
@@ -0,0 +1,13 @@
+package readcache_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestReadcache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Readcache Suite")
+}
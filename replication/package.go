@@ -0,0 +1 @@
+package replication // import "code.cloudfoundry.org/locket/replication"
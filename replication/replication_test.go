@@ -0,0 +1,219 @@
+package replication_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/replication"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	snapshots chan *admin.TTLSnapshot
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeServerStream) Send(snapshot *admin.TTLSnapshot) error {
+	s.snapshots <- snapshot
+	return nil
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	snapshots chan *admin.TTLSnapshot
+	ctx       context.Context
+}
+
+func (s *fakeClientStream) Recv() (*admin.TTLSnapshot, error) {
+	select {
+	case snapshot := <-s.snapshots:
+		return snapshot, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+type fakePeer struct {
+	err       error
+	snapshots chan *admin.TTLSnapshot
+	calls     int32
+}
+
+func (p *fakePeer) WatchTTLs(ctx context.Context, in *admin.WatchTTLsRequest, opts ...grpc.CallOption) (admin.Replication_WatchTTLsClient, error) {
+	atomic.AddInt32(&p.calls, 1)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &fakeClientStream{snapshots: p.snapshots, ctx: ctx}, nil
+}
+
+func (p *fakePeer) callCount() int {
+	return int(atomic.LoadInt32(&p.calls))
+}
+
+type fakeActive struct {
+	active int32
+}
+
+func (a *fakeActive) IsActive() bool {
+	return atomic.LoadInt32(&a.active) == 1
+}
+
+var _ = Describe("Replication", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeClock    *fakeclock.FakeClock
+		fakeLockPick *expirationfakes.FakeLockPick
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("replication")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeLockPick = &expirationfakes.FakeLockPick{}
+	})
+
+	Describe("the server", func() {
+		var (
+			stream *fakeServerStream
+			cancel context.CancelFunc
+			done   chan error
+		)
+
+		BeforeEach(func() {
+			fakeLockPick.RegisteredTTLsReturns([]expiration.RegisteredTTL{{
+				Key:           "cell-1",
+				Owner:         "rep-1",
+				Type:          "lock",
+				ModifiedId:    "guid",
+				ModifiedIndex: 3,
+				TtlInSeconds:  15,
+				ExpiresAt:     fakeClock.Now().Add(10 * time.Second),
+			}})
+
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			stream = &fakeServerStream{ctx: ctx, snapshots: make(chan *admin.TTLSnapshot, 10)}
+
+			server := replication.NewServer(logger, fakeLockPick, fakeClock, time.Second)
+			done = make(chan error, 1)
+			go func() {
+				done <- server.WatchTTLs(&admin.WatchTTLsRequest{}, stream)
+			}()
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("sends the registered ttls with the time left on each, then again every interval", func() {
+			Eventually(stream.snapshots).Should(Receive(Equal(&admin.TTLSnapshot{Ttls: []*admin.ReplicatedTTL{{
+				Key:                     "cell-1",
+				Owner:                   "rep-1",
+				Type:                    "lock",
+				ModifiedId:              "guid",
+				ModifiedIndex:           3,
+				TtlInSeconds:            15,
+				RemainingInMilliseconds: 10000,
+			}}})))
+
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			var snapshot *admin.TTLSnapshot
+			Eventually(stream.snapshots).Should(Receive(&snapshot))
+			Expect(snapshot.Ttls[0].RemainingInMilliseconds).To(Equal(int64(9000)))
+		})
+
+		It("stops when the watcher goes away", func() {
+			Eventually(stream.snapshots).Should(Receive())
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+	})
+
+	Describe("the replicator", func() {
+		var (
+			active  *fakeActive
+			peers   []*fakePeer
+			warmed  chan struct{}
+			process ifrit.Process
+		)
+
+		BeforeEach(func() {
+			active = &fakeActive{}
+			peers = []*fakePeer{
+				{err: errors.New("locket server is not active")},
+				{snapshots: make(chan *admin.TTLSnapshot)},
+			}
+			warmed = make(chan struct{}, 10)
+		})
+
+		JustBeforeEach(func() {
+			clients := make([]admin.ReplicationClient, len(peers))
+			for i, peer := range peers {
+				clients[i] = peer
+			}
+
+			warm := func(lager.Logger) { warmed <- struct{}{} }
+			runner := replication.NewReplicator(logger, clients, fakeLockPick, active, warm, fakeClock, time.Second)
+			process = ginkgomon.Invoke(runner)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Kill(process)
+		})
+
+		It("follows the active peer and restores its ttls", func() {
+			Eventually(peers[0].callCount).Should(Equal(1))
+			Eventually(logger).Should(gbytes.Say("watch-ended"))
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(peers[1].callCount).Should(Equal(1))
+
+			peers[1].snapshots <- &admin.TTLSnapshot{Ttls: []*admin.ReplicatedTTL{{
+				Key:                     "cell-1",
+				Owner:                   "rep-1",
+				ModifiedIndex:           3,
+				TtlInSeconds:            15,
+				RemainingInMilliseconds: 10000,
+			}}}
+
+			Eventually(warmed).Should(Receive())
+			Expect(fakeLockPick.RestoreTTLCallCount()).To(Equal(1))
+			_, ttl := fakeLockPick.RestoreTTLArgsForCall(0)
+			Expect(ttl).To(Equal(expiration.RegisteredTTL{
+				Key:           "cell-1",
+				Owner:         "rep-1",
+				ModifiedIndex: 3,
+				TtlInSeconds:  15,
+				ExpiresAt:     fakeClock.Now().Add(10 * time.Second),
+			}))
+		})
+
+		Context("when the server is active", func() {
+			BeforeEach(func() {
+				atomic.StoreInt32(&active.active, 1)
+			})
+
+			It("does not watch its peers", func() {
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				Consistently(peers[0].callCount).Should(Equal(0))
+			})
+		})
+	})
+})
@@ -0,0 +1,142 @@
+package replication
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models/admin"
+	"golang.org/x/net/context"
+)
+
+// ActiveChecker reports whether this server is the active one.
+type ActiveChecker interface {
+	IsActive() bool
+}
+
+type replicator struct {
+	logger        lager.Logger
+	peers         []admin.ReplicationClient
+	lockPick      expiration.LockPick
+	active        ActiveChecker
+	warm          func(lager.Logger)
+	clock         clock.Clock
+	retryInterval time.Duration
+}
+
+// NewReplicator returns a runner that, while active reports that this server
+// is on standby, watches the TTLs of the active server among peers and
+// restores them into lockPick, so that it takes over with the expiry of
+// every lock already scheduled. It tries the next peer every retryInterval
+// until one of them is active. warm, when it is not nil, is called after
+// each snapshot to keep other caches fresh. Once this server becomes active
+// it stops watching.
+func NewReplicator(
+	logger lager.Logger,
+	peers []admin.ReplicationClient,
+	lockPick expiration.LockPick,
+	active ActiveChecker,
+	warm func(lager.Logger),
+	clock clock.Clock,
+	retryInterval time.Duration,
+) *replicator {
+	return &replicator{
+		logger:        logger,
+		peers:         peers,
+		lockPick:      lockPick,
+		active:        active,
+		warm:          warm,
+		clock:         clock,
+		retryInterval: retryInterval,
+	}
+}
+
+func (r *replicator) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("replicator")
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	check := r.clock.NewTicker(r.retryInterval)
+	defer check.Stop()
+
+	var (
+		cancel  context.CancelFunc
+		done    = make(chan error, 1)
+		peer    = 0
+		waiting = false
+	)
+	stop := func() {
+		if cancel != nil {
+			cancel()
+			<-done
+			cancel = nil
+		}
+	}
+	defer stop()
+
+	close(ready)
+
+	for {
+		if cancel == nil && !waiting && !r.active.IsActive() {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func(client admin.ReplicationClient) {
+				done <- r.watch(logger, ctx, client)
+			}(r.peers[peer])
+		}
+
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+
+		case err := <-done:
+			cancel()
+			cancel = nil
+			logger.Info("watch-ended", lager.Data{"peer": peer, "error": err.Error()})
+			peer = (peer + 1) % len(r.peers)
+			waiting = true
+
+		case <-check.C():
+			waiting = false
+			if cancel != nil && r.active.IsActive() {
+				logger.Info("stopping-now-active")
+				stop()
+			}
+		}
+	}
+}
+
+func (r *replicator) watch(logger lager.Logger, ctx context.Context, client admin.ReplicationClient) error {
+	stream, err := client.WatchTTLs(ctx, &admin.WatchTTLsRequest{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		snapshot, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		now := r.clock.Now()
+		for _, ttl := range snapshot.Ttls {
+			r.lockPick.RestoreTTL(logger, expiration.RegisteredTTL{
+				Key:               ttl.Key,
+				Owner:             ttl.Owner,
+				Type:              ttl.Type,
+				ModifiedId:        ttl.ModifiedId,
+				ModifiedIndex:     ttl.ModifiedIndex,
+				TtlInSeconds:      ttl.TtlInSeconds,
+				TtlInMilliseconds: ttl.TtlInMilliseconds,
+				ExpiresAt:         now.Add(time.Duration(ttl.RemainingInMilliseconds) * time.Millisecond),
+			})
+		}
+
+		if r.warm != nil {
+			r.warm(logger)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package replication
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/models/admin"
+)
+
+const DefaultSnapshotInterval = time.Second
+
+// Config lists the other locket servers of an active/passive deployment.
+// While on standby, the server follows whichever of them is active.
+type Config struct {
+	PeerAddresses    []string              `json:"peer_addresses,omitempty"`
+	SnapshotInterval durationjson.Duration `json:"snapshot_interval,omitempty"`
+}
+
+func (c Config) Enabled() bool {
+	return len(c.PeerAddresses) > 0
+}
+
+type server struct {
+	logger   lager.Logger
+	lockPick expiration.LockPick
+	clock    clock.Clock
+	interval time.Duration
+}
+
+// NewServer returns the Replication service, which sends the TTLs
+// registered with lockPick to each watcher when it connects and then every
+// interval. Each TTL carries the time left until it expires rather than
+// when, so that the clocks of the two servers need not agree.
+func NewServer(logger lager.Logger, lockPick expiration.LockPick, clock clock.Clock, interval time.Duration) admin.ReplicationServer {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+
+	return &server{
+		logger:   logger.Session("replication-server"),
+		lockPick: lockPick,
+		clock:    clock,
+		interval: interval,
+	}
+}
+
+func (s *server) WatchTTLs(req *admin.WatchTTLsRequest, stream admin.Replication_WatchTTLsServer) error {
+	logger := s.logger.Session("watch-ttls")
+	logger.Info("started")
+	defer logger.Info("complete")
+
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		err := stream.Send(s.snapshot())
+		if err != nil {
+			logger.Error("failed-to-send-snapshot", err)
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C():
+		}
+	}
+}
+
+func (s *server) snapshot() *admin.TTLSnapshot {
+	now := s.clock.Now()
+	registered := s.lockPick.RegisteredTTLs()
+
+	snapshot := &admin.TTLSnapshot{Ttls: make([]*admin.ReplicatedTTL, 0, len(registered))}
+	for _, ttl := range registered {
+		snapshot.Ttls = append(snapshot.Ttls, &admin.ReplicatedTTL{
+			Key:                     ttl.Key,
+			Owner:                   ttl.Owner,
+			Type:                    ttl.Type,
+			ModifiedId:              ttl.ModifiedId,
+			ModifiedIndex:           ttl.ModifiedIndex,
+			TtlInSeconds:            ttl.TtlInSeconds,
+			TtlInMilliseconds:       ttl.TtlInMilliseconds,
+			RemainingInMilliseconds: int64(ttl.ExpiresAt.Sub(now) / time.Millisecond),
+		})
+	}
+	return snapshot
+}
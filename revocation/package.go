@@ -0,0 +1 @@
+package revocation // import "code.cloudfoundry.org/locket/revocation"
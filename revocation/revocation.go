@@ -0,0 +1,257 @@
+package revocation
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+	"golang.org/x/crypto/ocsp"
+)
+
+var ErrCertificateRevoked = errors.New("certificate has been revoked")
+
+type Config struct {
+	CRLFile            string                `json:"crl_file,omitempty"`
+	CRLURL             string                `json:"crl_url,omitempty"`
+	CRLRefreshInterval durationjson.Duration `json:"crl_refresh_interval,omitempty"`
+	OCSP               bool                  `json:"ocsp,omitempty"`
+	FailOpen           bool                  `json:"fail_open,omitempty"`
+}
+
+// Enabled returns true if any revocation source is configured.
+func (c Config) Enabled() bool {
+	return c.CRLFile != "" || c.CRLURL != "" || c.OCSP
+}
+
+// Checker rejects client certificates that appear on a configured CRL or
+// that their OCSP responder reports as revoked. Run it as an ifrit runner to
+// periodically reload the CRLs.
+type Checker struct {
+	logger     lager.Logger
+	clock      clock.Clock
+	config     Config
+	httpClient *http.Client
+
+	crls      []*pkix.CertificateList
+	crlMutex  *sync.RWMutex
+	ocspCache map[string]*ocsp.Response
+	ocspMutex *sync.Mutex
+}
+
+// NewChecker loads the configured CRLs and returns a Checker. It fails if a
+// configured CRL cannot be loaded.
+func NewChecker(logger lager.Logger, clock clock.Clock, config Config, httpClient *http.Client) (*Checker, error) {
+	c := &Checker{
+		logger:     logger.Session("revocation"),
+		clock:      clock,
+		config:     config,
+		httpClient: httpClient,
+		crlMutex:   &sync.RWMutex{},
+		ocspCache:  make(map[string]*ocsp.Response),
+		ocspMutex:  &sync.Mutex{},
+	}
+
+	err := c.reloadCRLs()
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// VerifyPeerCertificate is suitable for use as tls.Config.VerifyPeerCertificate.
+// It succeeds if the peer certificate is not revoked in at least one of the
+// verified chains.
+func (c *Checker) VerifyPeerCertificate(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	var err error
+	for _, chain := range verifiedChains {
+		if len(chain) < 2 {
+			continue
+		}
+
+		err = c.check(chain[0], chain[1])
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (c *Checker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := c.logger.Session("crl-refresher")
+
+	close(ready)
+
+	if c.config.CRLRefreshInterval <= 0 || (c.config.CRLFile == "" && c.config.CRLURL == "") {
+		<-signals
+		return nil
+	}
+
+	ticker := c.clock.NewTicker(time.Duration(c.config.CRLRefreshInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+		case <-ticker.C():
+			err := c.reloadCRLs()
+			if err != nil {
+				logger.Error("failed-to-reload-crls", err)
+			}
+		}
+	}
+}
+
+func (c *Checker) check(cert, issuer *x509.Certificate) error {
+	logger := c.logger.Session("check", lager.Data{"serial-number": cert.SerialNumber.String()})
+
+	if c.revokedByCRL(logger, cert, issuer) {
+		logger.Info("revoked-by-crl")
+		return ErrCertificateRevoked
+	}
+
+	if !c.config.OCSP || len(cert.OCSPServer) == 0 {
+		return nil
+	}
+
+	response, err := c.ocspResponse(cert, issuer)
+	if err != nil {
+		logger.Error("failed-to-check-ocsp", err)
+		if c.config.FailOpen {
+			return nil
+		}
+		return err
+	}
+
+	if response.Status == ocsp.Revoked {
+		logger.Info("revoked-by-ocsp")
+		return ErrCertificateRevoked
+	}
+	return nil
+}
+
+func (c *Checker) revokedByCRL(logger lager.Logger, cert, issuer *x509.Certificate) bool {
+	c.crlMutex.RLock()
+	defer c.crlMutex.RUnlock()
+
+	for _, crl := range c.crls {
+		if issuer.CheckCRLSignature(crl) != nil {
+			continue
+		}
+
+		if crl.HasExpired(c.clock.Now()) {
+			logger.Info("crl-has-expired")
+		}
+
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Checker) ocspResponse(cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	key := fmt.Sprintf("%x/%s", issuer.SubjectKeyId, cert.SerialNumber)
+
+	c.ocspMutex.Lock()
+	cached, ok := c.ocspCache[key]
+	c.ocspMutex.Unlock()
+	if ok && c.clock.Now().Before(cached.NextUpdate) {
+		return cached, nil
+	}
+
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected ocsp response status: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.NextUpdate.IsZero() {
+		c.ocspMutex.Lock()
+		c.ocspCache[key] = response
+		c.ocspMutex.Unlock()
+	}
+	return response, nil
+}
+
+func (c *Checker) reloadCRLs() error {
+	var crls []*pkix.CertificateList
+
+	if c.config.CRLFile != "" {
+		data, err := ioutil.ReadFile(c.config.CRLFile)
+		if err != nil {
+			return err
+		}
+
+		crl, err := x509.ParseCRL(data)
+		if err != nil {
+			return err
+		}
+		crls = append(crls, crl)
+	}
+
+	if c.config.CRLURL != "" {
+		crl, err := c.fetchCRL(c.config.CRLURL)
+		if err != nil {
+			return err
+		}
+		crls = append(crls, crl)
+	}
+
+	c.crlMutex.Lock()
+	c.crls = crls
+	c.crlMutex.Unlock()
+
+	c.logger.Info("loaded-crls", lager.Data{"count": len(crls)})
+	return nil
+}
+
+func (c *Checker) fetchCRL(url string) (*pkix.CertificateList, error) {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected crl response status: %d", resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCRL(data)
+}
@@ -0,0 +1,222 @@
+package revocation_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/revocation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/ocsp"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		config    revocation.Config
+		tmpDir    string
+
+		caCert    *x509.Certificate
+		caKey     crypto.Signer
+		revoked   *x509.Certificate
+		unrevoked *x509.Certificate
+
+		ocspServer *httptest.Server
+		ocspStatus int
+		ocspCalls  int
+
+		checker *revocation.Checker
+		err     error
+	)
+
+	newCert := func(serial int64, template *x509.Certificate, parent *x509.Certificate, signer crypto.Signer) (*x509.Certificate, crypto.Signer) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		template.SerialNumber = big.NewInt(serial)
+		template.NotBefore = fakeClock.Now().Add(-time.Hour)
+		template.NotAfter = fakeClock.Now().Add(time.Hour)
+		if parent == nil {
+			parent = template
+			signer = key
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := x509.ParseCertificate(der)
+		Expect(err).NotTo(HaveOccurred())
+		return cert, key
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("revocation")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		tmpDir, err = ioutil.TempDir("", "revocation")
+		Expect(err).NotTo(HaveOccurred())
+
+		ocspStatus = ocsp.Good
+		ocspCalls = 0
+		ocspServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ocspCalls++
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			request, err := ocsp.ParseRequest(body)
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := ocsp.CreateResponse(caCert, caCert, ocsp.Response{
+				Status:       ocspStatus,
+				SerialNumber: request.SerialNumber,
+				ThisUpdate:   fakeClock.Now(),
+				NextUpdate:   fakeClock.Now().Add(time.Minute),
+			}, caKey)
+			Expect(err).NotTo(HaveOccurred())
+			w.Write(response)
+		}))
+
+		caCert, caKey = newCert(1, &x509.Certificate{
+			Subject:               pkix.Name{CommonName: "ca"},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		}, nil, nil)
+		revoked, _ = newCert(2, &x509.Certificate{
+			Subject:    pkix.Name{CommonName: "revoked"},
+			OCSPServer: []string{ocspServer.URL},
+		}, caCert, caKey)
+		unrevoked, _ = newCert(3, &x509.Certificate{
+			Subject:    pkix.Name{CommonName: "unrevoked"},
+			OCSPServer: []string{ocspServer.URL},
+		}, caCert, caKey)
+
+		crl, err := caCert.CreateCRL(rand.Reader, caKey, []pkix.RevokedCertificate{
+			{SerialNumber: revoked.SerialNumber, RevocationTime: fakeClock.Now()},
+		}, fakeClock.Now(), fakeClock.Now().Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(tmpDir, "ca.crl"), crl, 0644)).To(Succeed())
+
+		config = revocation.Config{}
+	})
+
+	AfterEach(func() {
+		ocspServer.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	JustBeforeEach(func() {
+		checker, err = revocation.NewChecker(logger, fakeClock, config, http.DefaultClient)
+	})
+
+	verify := func(cert *x509.Certificate) error {
+		return checker.VerifyPeerCertificate(nil, [][]*x509.Certificate{{cert, caCert}})
+	}
+
+	Context("with a crl file", func() {
+		BeforeEach(func() {
+			config.CRLFile = filepath.Join(tmpDir, "ca.crl")
+		})
+
+		It("rejects revoked certificates", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verify(revoked)).To(Equal(revocation.ErrCertificateRevoked))
+		})
+
+		It("accepts certificates that are not revoked", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verify(unrevoked)).To(Succeed())
+		})
+
+		Context("when the crl file does not exist", func() {
+			BeforeEach(func() {
+				config.CRLFile = filepath.Join(tmpDir, "missing.crl")
+			})
+
+			It("fails to create the checker", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a crl url", func() {
+		var crlServer *httptest.Server
+
+		BeforeEach(func() {
+			crlServer = httptest.NewServer(http.FileServer(http.Dir(tmpDir)))
+			config.CRLURL = crlServer.URL + "/ca.crl"
+		})
+
+		AfterEach(func() {
+			crlServer.Close()
+		})
+
+		It("rejects revoked certificates", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verify(revoked)).To(Equal(revocation.ErrCertificateRevoked))
+			Expect(verify(unrevoked)).To(Succeed())
+		})
+	})
+
+	Context("with ocsp", func() {
+		BeforeEach(func() {
+			config.OCSP = true
+		})
+
+		It("accepts certificates the responder reports as good", func() {
+			Expect(verify(unrevoked)).To(Succeed())
+		})
+
+		It("caches responses until the next update", func() {
+			Expect(verify(unrevoked)).To(Succeed())
+			Expect(verify(unrevoked)).To(Succeed())
+			Expect(ocspCalls).To(Equal(1))
+
+			fakeClock.Increment(time.Minute)
+			Expect(verify(unrevoked)).To(Succeed())
+			Expect(ocspCalls).To(Equal(2))
+		})
+
+		Context("when the responder reports the certificate as revoked", func() {
+			BeforeEach(func() {
+				ocspStatus = ocsp.Revoked
+			})
+
+			It("rejects the certificate", func() {
+				Expect(verify(revoked)).To(Equal(revocation.ErrCertificateRevoked))
+			})
+		})
+
+		Context("when the responder is unavailable", func() {
+			BeforeEach(func() {
+				ocspServer.Close()
+			})
+
+			It("rejects the certificate", func() {
+				Expect(verify(unrevoked)).To(HaveOccurred())
+			})
+
+			Context("and the checker fails open", func() {
+				BeforeEach(func() {
+					config.FailOpen = true
+				})
+
+				It("accepts the certificate", func() {
+					Expect(verify(unrevoked)).To(Succeed())
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,18 @@
+# Shared by the BOSH backup and restore scripts. The locket job's templates
+# can override these defaults in the environment.
+
+LOCKET_DEBUG_ADDRESS=${LOCKET_DEBUG_ADDRESS:-127.0.0.1:17017}
+LOCKET_BIN=${LOCKET_BIN:-/var/vcap/packages/locket/bin/locket}
+LOCKET_CONFIG=${LOCKET_CONFIG:-/var/vcap/jobs/locket/config/locket.json}
+LOCKET_ARTIFACT=${BBR_ARTIFACT_DIRECTORY}/locks.json
+
+# quiesce holds the server's lock writes, once those in flight are done, and
+# expires the locks whose ttl has passed
+quiesce() {
+  curl --fail --silent --show-error -X POST http://$LOCKET_DEBUG_ADDRESS/debug/backup/quiesce
+}
+
+# unlock lets the held writes through
+unlock() {
+  curl --fail --silent --show-error -X POST http://$LOCKET_DEBUG_ADDRESS/debug/backup/unlock
+}
@@ -0,0 +1,39 @@
+#!/bin/bash
+
+# Runs the benchmarks on the working tree and on BASE_REF (by default the
+# latest tag), and compares them with benchstat so that regressions in ns/op
+# and allocs/op show up before a release.
+
+set -e
+
+BASE_REF=${1:-$(git describe --tags --abbrev=0)}
+PACKAGES="./handlers ./db ./raftdb ./expiration"
+COUNT=${COUNT:-10}
+
+ROOT=$(cd $(dirname $0)/.. && pwd)
+OUT=$(mktemp -d)
+BASE_TREE=$OUT/base
+
+go install golang.org/x/perf/cmd/benchstat@latest
+
+run_benchmarks() {
+  pushd $1 > /dev/null
+  go test -run '^$' -bench . -benchmem -count $COUNT $PACKAGES | tee $2
+  popd > /dev/null
+}
+
+git -C $ROOT worktree add --detach $BASE_TREE $BASE_REF
+trap "git -C $ROOT worktree remove --force $BASE_TREE" EXIT
+
+# the benchmarks may be newer than BASE_REF, so run the current ones there
+for file in db/conformance/benchmarks.go db/bench_test.go raftdb/bench_test.go handlers/bench_test.go expiration/bench_test.go; do
+  cp $ROOT/$file $BASE_TREE/$file
+done
+
+echo "Running benchmarks on $BASE_REF"
+run_benchmarks $BASE_TREE $OUT/old.txt
+
+echo "Running benchmarks on the working tree"
+run_benchmarks $ROOT $OUT/new.txt
+
+benchstat $OUT/old.txt $OUT/new.txt
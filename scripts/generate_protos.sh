@@ -1,4 +1,14 @@
 rm $GOPATH/bin/protoc-gen-gogoslick
 go install -v github.com/gogo/protobuf/protoc-gen-gogoslick
 echo "Generating pb.go files"
-protoc --proto_path=$GOPATH/src:$GOPATH/src/github.com/gogo/protobuf/protobuf/:$GOPATH/src/github.com/golang/protobuf/ptypes/duration/:. --gogoslick_out=plugins=grpc:. *.proto
+
+# map the well-known types onto gogo's implementations so that messages can
+# use them without mixing golang/protobuf and gogo generated code
+WKT_MAPPINGS=Mgoogle/protobuf/any.proto=github.com/gogo/protobuf/types
+WKT_MAPPINGS=$WKT_MAPPINGS,Mgoogle/protobuf/duration.proto=github.com/gogo/protobuf/types
+WKT_MAPPINGS=$WKT_MAPPINGS,Mgoogle/protobuf/empty.proto=github.com/gogo/protobuf/types
+WKT_MAPPINGS=$WKT_MAPPINGS,Mgoogle/protobuf/struct.proto=github.com/gogo/protobuf/types
+WKT_MAPPINGS=$WKT_MAPPINGS,Mgoogle/protobuf/timestamp.proto=github.com/gogo/protobuf/types
+WKT_MAPPINGS=$WKT_MAPPINGS,Mgoogle/protobuf/wrappers.proto=github.com/gogo/protobuf/types
+
+protoc --proto_path=$GOPATH/src:$GOPATH/src/github.com/gogo/protobuf/protobuf/:. --gogoslick_out=plugins=grpc,$WKT_MAPPINGS:. *.proto
@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/net/context"
+)
+
+// NewConnector returns a connector that opens each connection with the
+// connection string dsn returns at the time, so that connections opened
+// after a rotation use the new credentials.
+func NewConnector(d driver.Driver, dsn func() (string, error)) driver.Connector {
+	return &connector{driver: d, dsn: dsn}
+}
+
+type connector struct {
+	driver driver.Driver
+	dsn    func() (string, error)
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	dsn, err := c.dsn()
+	if err != nil {
+		return nil, err
+	}
+	return c.driver.Open(dsn)
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// WithDatabaseCredentials returns the connection string dsn, as prepared for
+// driverName, with username and password in place of any it already had.
+func WithDatabaseCredentials(driverName, dsn, username, password string) (string, error) {
+	switch driverName {
+	case "mysql":
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return "", err
+		}
+		cfg.User = username
+		cfg.Passwd = password
+		return cfg.FormatDSN(), nil
+	case "postgres":
+		// later settings override earlier ones in a key/value connection string
+		return fmt.Sprintf("%s user=%s password=%s", dsn, quotePostgresValue(username), quotePostgresValue(password)), nil
+	default:
+		return "", fmt.Errorf("unsupported database driver: %s", driverName)
+	}
+}
+
+func quotePostgresValue(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `'`, `\'`, -1)
+	return "'" + value + "'"
+}
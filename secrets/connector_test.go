@@ -0,0 +1,86 @@
+package secrets_test
+
+import (
+	"database/sql/driver"
+	"errors"
+
+	"code.cloudfoundry.org/locket/secrets"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+type recordingDriver struct {
+	dsns []string
+}
+
+func (d *recordingDriver) Open(dsn string) (driver.Conn, error) {
+	d.dsns = append(d.dsns, dsn)
+	return nil, errors.New("not a real database")
+}
+
+var _ = Describe("Connector", func() {
+	var (
+		fakeDriver *recordingDriver
+		password   string
+		dsnErr     error
+		connector  driver.Connector
+	)
+
+	BeforeEach(func() {
+		fakeDriver = &recordingDriver{}
+		password = "first-password"
+		dsnErr = nil
+		connector = secrets.NewConnector(fakeDriver, func() (string, error) {
+			return "user=locket password=" + password, dsnErr
+		})
+	})
+
+	It("opens every connection with the current connection string", func() {
+		connector.Connect(context.Background())
+		password = "second-password"
+		connector.Connect(context.Background())
+
+		Expect(fakeDriver.dsns).To(Equal([]string{
+			"user=locket password=first-password",
+			"user=locket password=second-password",
+		}))
+		Expect(connector.Driver()).To(BeIdenticalTo(fakeDriver))
+	})
+
+	Context("when the connection string cannot be built", func() {
+		BeforeEach(func() {
+			dsnErr = errors.New("boom")
+		})
+
+		It("does not open a connection", func() {
+			_, err := connector.Connect(context.Background())
+			Expect(err).To(MatchError("boom"))
+			Expect(fakeDriver.dsns).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("WithDatabaseCredentials", func() {
+	It("replaces the credentials in a mysql connection string", func() {
+		dsn, err := secrets.WithDatabaseCredentials("mysql", "old:secret@tcp(10.0.0.1:3306)/locket?parseTime=true", "locket", "p@ss:word")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dsn).To(Equal("locket:p@ss:word@tcp(10.0.0.1:3306)/locket?parseTime=true"))
+	})
+
+	It("returns an error for an invalid mysql connection string", func() {
+		_, err := secrets.WithDatabaseCredentials("mysql", "not a dsn", "locket", "password")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("overrides the credentials in a postgres connection string", func() {
+		dsn, err := secrets.WithDatabaseCredentials("postgres", "host=10.0.0.1 dbname=locket user=old", "locket", `it's a \ secret`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dsn).To(Equal(`host=10.0.0.1 dbname=locket user=old user='locket' password='it\'s a \\ secret'`))
+	})
+
+	It("returns an error for other drivers", func() {
+		_, err := secrets.WithDatabaseCredentials("sqlite3", "locket.db", "locket", "password")
+		Expect(err).To(MatchError("unsupported database driver: sqlite3"))
+	})
+})
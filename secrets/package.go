@@ -0,0 +1 @@
+package secrets // import "code.cloudfoundry.org/locket/secrets"
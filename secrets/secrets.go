@@ -0,0 +1,219 @@
+package secrets
+
+import (
+	"crypto/tls"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/credhub-cli/credhub"
+	"code.cloudfoundry.org/credhub-cli/credhub/auth"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager"
+)
+
+const DefaultRefreshInterval = 5 * time.Minute
+
+var ErrNoCertificate = errors.New("no certificate fetched from credhub")
+
+// Config names the CredHub credentials locket uses in place of secrets in
+// its config file. DatabaseCredentialName is a user credential holding the
+// database username and password, and TLSCredentialName is a certificate
+// credential holding the server's certificate and private key. Either can be
+// left out. The credentials are fetched again every RefreshInterval, so that
+// rotated credentials are picked up without a restart.
+type Config struct {
+	URL                    string                `json:"url,omitempty"`
+	CACertFile             string                `json:"ca_cert_file,omitempty"`
+	ClientID               string                `json:"client_id,omitempty"`
+	ClientSecret           string                `json:"client_secret,omitempty"`
+	DatabaseCredentialName string                `json:"database_credential_name,omitempty"`
+	TLSCredentialName      string                `json:"tls_credential_name,omitempty"`
+	RefreshInterval        durationjson.Duration `json:"refresh_interval,omitempty"`
+}
+
+// Enabled returns true if CredHub is configured.
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
+
+//go:generate counterfeiter . Client
+type Client interface {
+	GetLatestUser(name string) (credentials.User, error)
+	GetLatestCertificate(name string) (credentials.Certificate, error)
+}
+
+// NewClient returns a CredHub client that authenticates with the
+// configured UAA client.
+func NewClient(config Config) (Client, error) {
+	options := []credhub.Option{
+		credhub.Auth(auth.UaaClientCredentials(config.ClientID, config.ClientSecret)),
+	}
+	if config.CACertFile != "" {
+		caCert, err := ioutil.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, credhub.CaCerts(string(caCert)))
+	}
+
+	return credhub.New(config.URL, options...)
+}
+
+// Store holds the latest version of the configured credentials. Run it as
+// an ifrit runner to keep them up to date.
+type Store struct {
+	logger          lager.Logger
+	client          Client
+	config          Config
+	clock           clock.Clock
+	refreshInterval time.Duration
+
+	mutex                sync.RWMutex
+	databaseUsername     string
+	databasePassword     string
+	databaseCredentialID string
+	certificate          *tls.Certificate
+	certificateID        string
+}
+
+// NewStore fetches the configured credentials, and fails if any of them
+// cannot be fetched.
+func NewStore(logger lager.Logger, client Client, config Config, clock clock.Clock) (*Store, error) {
+	refreshInterval := time.Duration(config.RefreshInterval)
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+
+	s := &Store{
+		logger:          logger.Session("credhub"),
+		client:          client,
+		config:          config,
+		clock:           clock,
+		refreshInterval: refreshInterval,
+	}
+
+	err := s.refresh(s.logger)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RefreshInterval returns how often the credentials are fetched.
+func (s *Store) RefreshInterval() time.Duration {
+	return s.refreshInterval
+}
+
+// DatabaseCredentials returns the latest database username and password.
+func (s *Store) DatabaseCredentials() (string, string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.databaseUsername, s.databasePassword
+}
+
+// HasDatabaseCredentials reports whether the database credentials come from
+// CredHub.
+func (s *Store) HasDatabaseCredentials() bool {
+	return s.config.DatabaseCredentialName != ""
+}
+
+// HasCertificate reports whether the server certificate comes from CredHub.
+func (s *Store) HasCertificate() bool {
+	return s.config.TLSCredentialName != ""
+}
+
+// GetCertificate returns the latest server certificate. It is meant for
+// tls.Config's GetCertificate, so that new connections use a rotated
+// certificate.
+func (s *Store) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.certificate == nil {
+		return nil, ErrNoCertificate
+	}
+	return s.certificate, nil
+}
+
+func (s *Store) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := s.logger.Session("refresh")
+	logger.Info("started", lager.Data{"interval": s.refreshInterval.String()})
+	defer logger.Info("completed")
+
+	ticker := s.clock.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	close(ready)
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+		case <-ticker.C():
+			// keep using the credentials we have until CredHub is back
+			err := s.refresh(logger)
+			if err != nil {
+				logger.Error("failed-to-refresh-credentials", err)
+			}
+		}
+	}
+}
+
+func (s *Store) refresh(logger lager.Logger) error {
+	if s.config.DatabaseCredentialName != "" {
+		user, err := s.client.GetLatestUser(s.config.DatabaseCredentialName)
+		if err != nil {
+			logger.Error("failed-to-fetch-database-credentials", err, lager.Data{"name": s.config.DatabaseCredentialName})
+			return err
+		}
+
+		s.mutex.Lock()
+		rotated := s.databaseCredentialID != "" && s.databaseCredentialID != user.Id
+		s.databaseUsername = user.Value.Username
+		s.databasePassword = user.Value.Password
+		s.databaseCredentialID = user.Id
+		s.mutex.Unlock()
+
+		if rotated {
+			logger.Info("database-credentials-rotated", lager.Data{"name": s.config.DatabaseCredentialName})
+		}
+	}
+
+	if s.config.TLSCredentialName != "" {
+		cert, err := s.client.GetLatestCertificate(s.config.TLSCredentialName)
+		if err != nil {
+			logger.Error("failed-to-fetch-certificate", err, lager.Data{"name": s.config.TLSCredentialName})
+			return err
+		}
+
+		s.mutex.RLock()
+		unchanged := s.certificateID == cert.Id
+		s.mutex.RUnlock()
+		if unchanged {
+			return nil
+		}
+
+		keyPair, err := tls.X509KeyPair([]byte(cert.Value.Certificate), []byte(cert.Value.PrivateKey))
+		if err != nil {
+			logger.Error("invalid-certificate", err, lager.Data{"name": s.config.TLSCredentialName})
+			return err
+		}
+
+		s.mutex.Lock()
+		rotated := s.certificateID != ""
+		s.certificate = &keyPair
+		s.certificateID = cert.Id
+		s.mutex.Unlock()
+
+		if rotated {
+			logger.Info("certificate-rotated", lager.Data{"name": s.config.TLSCredentialName})
+		}
+	}
+
+	return nil
+}
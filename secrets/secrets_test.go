@@ -0,0 +1,232 @@
+package secrets_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/secrets"
+	"code.cloudfoundry.org/locket/secrets/secretsfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		fakeClient *secretsfakes.FakeClient
+		config     secrets.Config
+
+		store *secrets.Store
+		err   error
+	)
+
+	newUser := func(id, username, password string) credentials.User {
+		var user credentials.User
+		user.Id = id
+		user.Value.Username = username
+		user.Value.Password = password
+		return user
+	}
+
+	newCertificate := func(id, commonName string) credentials.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: commonName},
+			NotBefore:    fakeClock.Now().Add(-time.Hour),
+			NotAfter:     fakeClock.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		Expect(err).NotTo(HaveOccurred())
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cert credentials.Certificate
+		cert.Id = id
+		cert.Value.Certificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+		cert.Value.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+		return cert
+	}
+
+	commonName := func() string {
+		cert, err := store.GetCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		Expect(err).NotTo(HaveOccurred())
+		return parsed.Subject.CommonName
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("secrets")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeClient = &secretsfakes.FakeClient{}
+		fakeClient.GetLatestUserReturns(newUser("user-1", "locket", "first-password"), nil)
+		fakeClient.GetLatestCertificateReturns(newCertificate("cert-1", "first"), nil)
+
+		config = secrets.Config{
+			URL:                    "https://credhub.example.com:8844",
+			DatabaseCredentialName: "/cf/locket/database",
+			TLSCredentialName:      "/cf/locket/server",
+			RefreshInterval:        durationjson.Duration(time.Minute),
+		}
+	})
+
+	JustBeforeEach(func() {
+		store, err = secrets.NewStore(logger, fakeClient, config, fakeClock)
+	})
+
+	It("fetches the configured credentials", func() {
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.GetLatestUserCallCount()).To(Equal(1))
+		Expect(fakeClient.GetLatestUserArgsForCall(0)).To(Equal("/cf/locket/database"))
+		Expect(fakeClient.GetLatestCertificateCallCount()).To(Equal(1))
+		Expect(fakeClient.GetLatestCertificateArgsForCall(0)).To(Equal("/cf/locket/server"))
+
+		Expect(store.HasDatabaseCredentials()).To(BeTrue())
+		username, password := store.DatabaseCredentials()
+		Expect(username).To(Equal("locket"))
+		Expect(password).To(Equal("first-password"))
+
+		Expect(store.HasCertificate()).To(BeTrue())
+		Expect(commonName()).To(Equal("first"))
+		Expect(store.RefreshInterval()).To(Equal(time.Minute))
+	})
+
+	Context("when the refresh interval is not set", func() {
+		BeforeEach(func() {
+			config.RefreshInterval = 0
+		})
+
+		It("uses the default", func() {
+			Expect(store.RefreshInterval()).To(Equal(secrets.DefaultRefreshInterval))
+		})
+	})
+
+	Context("when only the database credential is configured", func() {
+		BeforeEach(func() {
+			config.TLSCredentialName = ""
+		})
+
+		It("does not fetch a certificate", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.GetLatestCertificateCallCount()).To(Equal(0))
+			Expect(store.HasCertificate()).To(BeFalse())
+
+			_, err := store.GetCertificate(nil)
+			Expect(err).To(Equal(secrets.ErrNoCertificate))
+		})
+	})
+
+	Context("when the database credentials cannot be fetched", func() {
+		BeforeEach(func() {
+			fakeClient.GetLatestUserReturns(credentials.User{}, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+
+	Context("when the certificate cannot be fetched", func() {
+		BeforeEach(func() {
+			fakeClient.GetLatestCertificateReturns(credentials.Certificate{}, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+
+	Context("when the certificate is invalid", func() {
+		BeforeEach(func() {
+			var cert credentials.Certificate
+			cert.Id = "cert-1"
+			cert.Value.Certificate = "not a certificate"
+			fakeClient.GetLatestCertificateReturns(cert, nil)
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when running", func() {
+		var process ifrit.Process
+
+		JustBeforeEach(func() {
+			Expect(err).NotTo(HaveOccurred())
+			process = ginkgomon.Invoke(store)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("picks up rotated credentials on every refresh", func() {
+			fakeClient.GetLatestUserReturns(newUser("user-2", "locket", "second-password"), nil)
+			fakeClient.GetLatestCertificateReturns(newCertificate("cert-2", "second"), nil)
+
+			fakeClock.WaitForWatcherAndIncrement(time.Minute)
+
+			Eventually(logger).Should(gbytes.Say("database-credentials-rotated"))
+			Eventually(logger).Should(gbytes.Say("certificate-rotated"))
+
+			_, password := store.DatabaseCredentials()
+			Expect(password).To(Equal("second-password"))
+			Expect(commonName()).To(Equal("second"))
+		})
+
+		It("keeps the certificate when it has not changed", func() {
+			first, err := store.GetCertificate(nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeClock.WaitForWatcherAndIncrement(time.Minute)
+			Eventually(fakeClient.GetLatestCertificateCallCount).Should(Equal(2))
+
+			second, err := store.GetCertificate(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(logger).NotTo(gbytes.Say("rotated"))
+		})
+
+		Context("when a refresh fails", func() {
+			It("keeps the credentials it has", func() {
+				fakeClient.GetLatestUserReturns(credentials.User{}, errors.New("credhub is down"))
+
+				fakeClock.WaitForWatcherAndIncrement(time.Minute)
+				Eventually(logger).Should(gbytes.Say("failed-to-refresh-credentials"))
+
+				username, password := store.DatabaseCredentials()
+				Expect(username).To(Equal("locket"))
+				Expect(password).To(Equal("first-password"))
+				Expect(commonName()).To(Equal("first"))
+				Consistently(process.Wait()).ShouldNot(Receive())
+			})
+		})
+	})
+})
+
+var _ = Describe("Config", func() {
+	It("is enabled when a URL is configured", func() {
+		Expect(secrets.Config{}.Enabled()).To(BeFalse())
+		Expect(secrets.Config{URL: "https://credhub.example.com:8844"}.Enabled()).To(BeTrue())
+	})
+})
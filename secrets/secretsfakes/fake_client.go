@@ -0,0 +1,124 @@
+// This file was generated by counterfeiter
+package secretsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/credhub-cli/credhub/credentials"
+	"code.cloudfoundry.org/locket/secrets"
+)
+
+type FakeClient struct {
+	GetLatestUserStub        func(name string) (credentials.User, error)
+	getLatestUserMutex       sync.RWMutex
+	getLatestUserArgsForCall []struct {
+		name string
+	}
+	getLatestUserReturns struct {
+		result1 credentials.User
+		result2 error
+	}
+	GetLatestCertificateStub        func(name string) (credentials.Certificate, error)
+	getLatestCertificateMutex       sync.RWMutex
+	getLatestCertificateArgsForCall []struct {
+		name string
+	}
+	getLatestCertificateReturns struct {
+		result1 credentials.Certificate
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeClient) GetLatestUser(name string) (credentials.User, error) {
+	fake.getLatestUserMutex.Lock()
+	fake.getLatestUserArgsForCall = append(fake.getLatestUserArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("GetLatestUser", []interface{}{name})
+	fake.getLatestUserMutex.Unlock()
+	if fake.GetLatestUserStub != nil {
+		return fake.GetLatestUserStub(name)
+	} else {
+		return fake.getLatestUserReturns.result1, fake.getLatestUserReturns.result2
+	}
+}
+
+func (fake *FakeClient) GetLatestUserCallCount() int {
+	fake.getLatestUserMutex.RLock()
+	defer fake.getLatestUserMutex.RUnlock()
+	return len(fake.getLatestUserArgsForCall)
+}
+
+func (fake *FakeClient) GetLatestUserArgsForCall(i int) string {
+	fake.getLatestUserMutex.RLock()
+	defer fake.getLatestUserMutex.RUnlock()
+	return fake.getLatestUserArgsForCall[i].name
+}
+
+func (fake *FakeClient) GetLatestUserReturns(result1 credentials.User, result2 error) {
+	fake.GetLatestUserStub = nil
+	fake.getLatestUserReturns = struct {
+		result1 credentials.User
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) GetLatestCertificate(name string) (credentials.Certificate, error) {
+	fake.getLatestCertificateMutex.Lock()
+	fake.getLatestCertificateArgsForCall = append(fake.getLatestCertificateArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("GetLatestCertificate", []interface{}{name})
+	fake.getLatestCertificateMutex.Unlock()
+	if fake.GetLatestCertificateStub != nil {
+		return fake.GetLatestCertificateStub(name)
+	} else {
+		return fake.getLatestCertificateReturns.result1, fake.getLatestCertificateReturns.result2
+	}
+}
+
+func (fake *FakeClient) GetLatestCertificateCallCount() int {
+	fake.getLatestCertificateMutex.RLock()
+	defer fake.getLatestCertificateMutex.RUnlock()
+	return len(fake.getLatestCertificateArgsForCall)
+}
+
+func (fake *FakeClient) GetLatestCertificateArgsForCall(i int) string {
+	fake.getLatestCertificateMutex.RLock()
+	defer fake.getLatestCertificateMutex.RUnlock()
+	return fake.getLatestCertificateArgsForCall[i].name
+}
+
+func (fake *FakeClient) GetLatestCertificateReturns(result1 credentials.Certificate, result2 error) {
+	fake.GetLatestCertificateStub = nil
+	fake.getLatestCertificateReturns = struct {
+		result1 credentials.Certificate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getLatestUserMutex.RLock()
+	defer fake.getLatestUserMutex.RUnlock()
+	fake.getLatestCertificateMutex.RLock()
+	defer fake.getLatestCertificateMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeClient) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ secrets.Client = new(FakeClient)
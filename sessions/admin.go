@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"code.cloudfoundry.org/locket/tokenauth"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// adminServicePrefix is the prefix of the methods of the Sessions service.
+const adminServicePrefix = "/locket.admin.Sessions/"
+
+var (
+	ErrNoAdminIdentities = errors.New("session admin needs at least one admin identity")
+	ErrNotAdmin          = grpc.Errorf(codes.PermissionDenied, "only session admins may list and disconnect sessions")
+)
+
+// ValidateAdminIdentities checks that there is at least one admin identity,
+// and that each is a valid glob pattern.
+func ValidateAdminIdentities(identities []string) error {
+	if len(identities) == 0 {
+		return ErrNoAdminIdentities
+	}
+	for _, pattern := range identities {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid admin identity pattern %q: %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+// NewAdminInterceptor returns an interceptor that rejects calls to the
+// Sessions service with ErrNotAdmin unless the client's identity matches one
+// of identities, which are glob patterns such as "operator-*". The Sessions
+// service is served on the same port as locks, so every other client is
+// kept out of it. It must come after the token authentication interceptor,
+// if there is one.
+func NewAdminInterceptor(identities []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, adminServicePrefix) && !isAdmin(identities, tokenauth.Identity(ctx)) {
+			return nil, ErrNotAdmin
+		}
+		return handler(ctx, req)
+	}
+}
+
+func isAdmin(identities []string, identity string) bool {
+	if identity == "" {
+		return false
+	}
+	for _, pattern := range identities {
+		if matched, _ := path.Match(pattern, identity); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package sessions_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+
+	"code.cloudfoundry.org/locket/sessions"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var _ = Describe("AdminInterceptor", func() {
+	var called bool
+
+	contextFor := func(identity string) context.Context {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: identity}}
+		return peer.NewContext(context.Background(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+		})
+	}
+
+	intercept := func(ctx context.Context, method string) error {
+		called = false
+		interceptor := sessions.NewAdminInterceptor([]string{"operator-*"})
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+		return err
+	}
+
+	It("lets admins call the Sessions service", func() {
+		Expect(intercept(contextFor("operator-1"), "/locket.admin.Sessions/ListSessions")).To(Succeed())
+		Expect(called).To(BeTrue())
+	})
+
+	It("rejects other clients", func() {
+		Expect(intercept(contextFor("rep-1"), "/locket.admin.Sessions/DisconnectSession")).To(Equal(sessions.ErrNotAdmin))
+		Expect(called).To(BeFalse())
+	})
+
+	It("rejects clients without an identity", func() {
+		Expect(intercept(context.Background(), "/locket.admin.Sessions/ListSessions")).To(Equal(sessions.ErrNotAdmin))
+	})
+
+	It("lets every client call other services", func() {
+		Expect(intercept(contextFor("rep-1"), "/models.Locket/Lock")).To(Succeed())
+		Expect(called).To(BeTrue())
+	})
+
+	Describe("ValidateAdminIdentities", func() {
+		It("requires an identity", func() {
+			Expect(sessions.ValidateAdminIdentities(nil)).To(Equal(sessions.ErrNoAdminIdentities))
+		})
+
+		It("rejects invalid patterns", func() {
+			Expect(sessions.ValidateAdminIdentities([]string{"operator-["})).To(MatchError(ContainSubstring("invalid admin identity pattern")))
+		})
+
+		It("accepts glob patterns", func() {
+			Expect(sessions.ValidateAdminIdentities([]string{"operator-*", "admin"})).To(Succeed())
+		})
+	})
+})
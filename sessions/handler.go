@@ -0,0 +1,61 @@
+package sessions
+
+import (
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type handler struct {
+	logger  lager.Logger
+	tracker *Tracker
+	locket  models.LocketServer
+}
+
+// NewHandler returns the Sessions admin service for tracker. Locks are
+// expired by releasing them through locket, so that waiters on them are
+// woken as for any other release.
+func NewHandler(logger lager.Logger, tracker *Tracker, locket models.LocketServer) admin.SessionsServer {
+	return &handler{
+		logger:  logger.Session("sessions-handler"),
+		tracker: tracker,
+		locket:  locket,
+	}
+}
+
+func (h *handler) ListSessions(ctx context.Context, req *admin.ListSessionsRequest) (*admin.ListSessionsResponse, error) {
+	return &admin.ListSessionsResponse{Sessions: h.tracker.Sessions()}, nil
+}
+
+func (h *handler) DisconnectSession(ctx context.Context, req *admin.DisconnectSessionRequest) (*admin.DisconnectSessionResponse, error) {
+	logger := h.logger.Session("disconnect-session", lager.Data{"address": req.Address, "expire-locks": req.ExpireLocks})
+
+	locks, err := h.tracker.Disconnect(req.Address)
+	if err != nil {
+		return nil, grpc.Errorf(codes.NotFound, err.Error())
+	}
+
+	resp := &admin.DisconnectSessionResponse{}
+	if !req.ExpireLocks {
+		return resp, nil
+	}
+
+	for _, lock := range locks {
+		_, err = h.locket.Release(ctx, &models.ReleaseRequest{Resource: &models.Resource{Key: lock.Key, Owner: lock.Owner}})
+		switch err {
+		case nil:
+			resp.ExpiredKeys = append(resp.ExpiredKeys, lock.Key)
+		case models.ErrResourceNotFound, models.ErrLockCollision:
+			// the lock expired or changed hands since the session took it
+		default:
+			logger.Error("failed-to-expire-lock", err, lager.Data{"key": lock.Key})
+			return nil, err
+		}
+	}
+
+	logger.Info("expired-locks", lager.Data{"keys": resp.ExpiredKeys})
+	return resp, nil
+}
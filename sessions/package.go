@@ -0,0 +1 @@
+package sessions // import "code.cloudfoundry.org/locket/sessions"
@@ -0,0 +1,186 @@
+package sessions_test
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/sessions"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+)
+
+type fakeLocket struct {
+	models.LocketServer
+	releases   []*models.ReleaseRequest
+	releaseErr map[string]error
+}
+
+func (f *fakeLocket) Release(ctx context.Context, req *models.ReleaseRequest) (*models.ReleaseResponse, error) {
+	f.releases = append(f.releases, req)
+	if err := f.releaseErr[req.Resource.Key]; err != nil {
+		return nil, err
+	}
+	return &models.ReleaseResponse{}, nil
+}
+
+var _ = Describe("Sessions", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeClock   *fakeclock.FakeClock
+		tracker     *sessions.Tracker
+		listener    net.Listener
+		client      net.Conn
+		server      net.Conn
+		interceptor grpc.UnaryServerInterceptor
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("sessions")
+		fakeClock = fakeclock.NewFakeClock(time.Unix(0, 1000))
+		tracker = sessions.NewTracker(logger, fakeClock)
+		interceptor = tracker.NewInterceptor()
+
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		listener = tracker.Listener(listener)
+
+		client, err = net.Dial("tcp", listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		server, err = listener.Accept()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		server.Close()
+		listener.Close()
+	})
+
+	call := func(req, resp interface{}, err error) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: server.RemoteAddr()})
+		interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return resp, err
+		})
+	}
+
+	lock := func(key, owner string, expiresAt int64) {
+		call(
+			&models.LockRequest{Resource: &models.Resource{Key: key, Owner: owner}},
+			&models.LockResponse{ExpiresAt: expiresAt},
+			nil,
+		)
+	}
+
+	It("lists connected clients", func() {
+		Expect(tracker.Sessions()).To(Equal([]*admin.Session{{
+			Address:     server.RemoteAddr().String(),
+			ConnectedAt: 1000,
+		}}))
+	})
+
+	It("forgets connections once they are closed", func() {
+		Expect(server.Close()).To(Succeed())
+		Expect(tracker.Sessions()).To(BeEmpty())
+	})
+
+	It("records the exclusive locks taken on the connection and when", func() {
+		fakeClock.Increment(time.Second)
+		lock("cell-1", "rep-1", 0)
+		call(
+			&models.LockGroupRequest{Resources: []*models.Resource{{Key: "a", Owner: "rep-1"}, {Key: "b", Owner: "rep-1"}}},
+			&models.LockGroupResponse{Locks: []*models.LockResponse{{}, {}}},
+			nil,
+		)
+		call(
+			&models.LockRequest{Resource: &models.Resource{Key: "shared", Owner: "rep-1"}, Mode: models.SHARED},
+			&models.LockResponse{},
+			nil,
+		)
+		call(
+			&models.LockRequest{Resource: &models.Resource{Key: "failed", Owner: "rep-1"}},
+			nil,
+			models.ErrLockCollision,
+		)
+
+		sessions := tracker.Sessions()
+		Expect(sessions).To(HaveLen(1))
+		Expect(sessions[0].LastHeartbeatAt).To(Equal(fakeClock.Now().UnixNano()))
+		Expect(sessions[0].Keys).To(Equal([]string{"a", "b", "cell-1"}))
+	})
+
+	It("forgets locks once they are released or expire", func() {
+		lock("cell-1", "rep-1", 0)
+		lock("cell-2", "rep-1", fakeClock.Now().Add(time.Second).UnixNano())
+		call(&models.ReleaseRequest{Resource: &models.Resource{Key: "cell-1", Owner: "rep-1"}}, &models.ReleaseResponse{}, nil)
+		Expect(tracker.Sessions()[0].Keys).To(Equal([]string{"cell-2"}))
+
+		fakeClock.Increment(time.Second)
+		Expect(tracker.Sessions()[0].Keys).To(BeEmpty())
+	})
+
+	Context("the handler", func() {
+		var (
+			locket  *fakeLocket
+			handler admin.SessionsServer
+		)
+
+		BeforeEach(func() {
+			locket = &fakeLocket{releaseErr: map[string]error{}}
+			handler = sessions.NewHandler(logger, tracker, locket)
+
+			lock("cell-1", "rep-1", 0)
+			lock("cell-2", "rep-1", 0)
+		})
+
+		It("lists sessions", func() {
+			resp, err := handler.ListSessions(context.Background(), &admin.ListSessionsRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Sessions).To(Equal(tracker.Sessions()))
+		})
+
+		It("closes the connection without expiring its locks", func() {
+			resp, err := handler.DisconnectSession(context.Background(), &admin.DisconnectSessionRequest{Address: server.RemoteAddr().String()})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.ExpiredKeys).To(BeEmpty())
+			Expect(locket.releases).To(BeEmpty())
+			Expect(tracker.Sessions()).To(BeEmpty())
+
+			_, err = client.Read(make([]byte, 1))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("expires the locks held through the connection", func() {
+			locket.releaseErr["cell-2"] = models.ErrResourceNotFound
+
+			resp, err := handler.DisconnectSession(context.Background(), &admin.DisconnectSessionRequest{Address: server.RemoteAddr().String(), ExpireLocks: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.ExpiredKeys).To(Equal([]string{"cell-1"}))
+			Expect(locket.releases).To(Equal([]*models.ReleaseRequest{
+				{Resource: &models.Resource{Key: "cell-1", Owner: "rep-1"}},
+				{Resource: &models.Resource{Key: "cell-2", Owner: "rep-1"}},
+			}))
+		})
+
+		It("returns the error when a lock cannot be expired", func() {
+			locket.releaseErr["cell-1"] = errors.New("boom")
+
+			_, err := handler.DisconnectSession(context.Background(), &admin.DisconnectSessionRequest{Address: server.RemoteAddr().String(), ExpireLocks: true})
+			Expect(err).To(MatchError("boom"))
+		})
+
+		It("returns NotFound for unknown addresses", func() {
+			_, err := handler.DisconnectSession(context.Background(), &admin.DisconnectSessionRequest{Address: "10.0.0.1:1234"})
+			Expect(grpc.Code(err)).To(Equal(codes.NotFound))
+		})
+	})
+})
@@ -0,0 +1,284 @@
+package sessions
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/admin"
+	"code.cloudfoundry.org/locket/models/v2"
+	"code.cloudfoundry.org/locket/tokenauth"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+var ErrSessionNotFound = errors.New("no session with that address")
+
+// Tracker keeps track of the clients connected to the server: the identity
+// each connection authenticated as, when it last took or refreshed a lock,
+// and the exclusive locks it holds. It only sees connections accepted by its
+// Listener, and locks taken and released through unary calls.
+type Tracker struct {
+	logger lager.Logger
+	clock  clock.Clock
+
+	mutex    sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	conn            net.Conn
+	identity        string
+	connectedAt     time.Time
+	lastHeartbeatAt time.Time
+	locks           map[string]heldLock
+}
+
+type heldLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// HeldLock is an exclusive lock held through a session.
+type HeldLock struct {
+	Key   string
+	Owner string
+}
+
+func NewTracker(logger lager.Logger, clock clock.Clock) *Tracker {
+	return &Tracker{
+		logger:   logger.Session("sessions"),
+		clock:    clock,
+		sessions: make(map[string]*session),
+	}
+}
+
+// Listener wraps lis so that the tracker sees every connection it accepts,
+// until the connection is closed.
+func (t *Tracker) Listener(lis net.Listener) net.Listener {
+	return &listener{Listener: lis, tracker: t}
+}
+
+// NewInterceptor returns an interceptor that records the identity of the
+// client on each call, and the exclusive locks it takes and releases. It
+// must come after the token authentication interceptor, if there is one.
+func (t *Tracker) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		address := connectionAddress(ctx)
+		t.identify(address, tokenauth.Identity(ctx))
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			t.observe(address, req, resp)
+		}
+		return resp, err
+	}
+}
+
+// Sessions returns the connected clients ordered by address, with the keys
+// of the locks they hold that have not expired.
+func (t *Tracker) Sessions() []*admin.Session {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	sessions := make([]*admin.Session, 0, len(t.sessions))
+	for address, s := range t.sessions {
+		session := &admin.Session{
+			Address:     address,
+			Identity:    s.identity,
+			ConnectedAt: s.connectedAt.UnixNano(),
+		}
+		if !s.lastHeartbeatAt.IsZero() {
+			session.LastHeartbeatAt = s.lastHeartbeatAt.UnixNano()
+		}
+		for _, lock := range s.heldLocks(now) {
+			session.Keys = append(session.Keys, lock.Key)
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Address < sessions[j].Address
+	})
+	return sessions
+}
+
+// Disconnect closes the connection from address, and returns the locks it
+// held that have not expired. It returns ErrSessionNotFound when no client
+// is connected from address.
+func (t *Tracker) Disconnect(address string) ([]HeldLock, error) {
+	t.mutex.Lock()
+	s, ok := t.sessions[address]
+	if ok {
+		delete(t.sessions, address)
+	}
+	t.mutex.Unlock()
+
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	t.logger.Info("disconnecting", lager.Data{"address": address, "identity": s.identity})
+	err := s.conn.Close()
+	if err != nil {
+		t.logger.Error("failed-to-close-connection", err, lager.Data{"address": address})
+	}
+	return s.heldLocks(t.clock.Now()), nil
+}
+
+func (t *Tracker) add(conn net.Conn) net.Conn {
+	tracked := &trackedConn{Conn: conn, tracker: t}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.sessions[conn.RemoteAddr().String()] = &session{
+		conn:        tracked,
+		connectedAt: t.clock.Now(),
+		locks:       make(map[string]heldLock),
+	}
+	return tracked
+}
+
+func (t *Tracker) remove(conn *trackedConn) {
+	address := conn.RemoteAddr().String()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if s, ok := t.sessions[address]; ok && s.conn == conn {
+		delete(t.sessions, address)
+	}
+}
+
+func (t *Tracker) identify(address, identity string) {
+	if identity == "" {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if s, ok := t.sessions[address]; ok {
+		s.identity = identity
+	}
+}
+
+func (t *Tracker) observe(address string, req, resp interface{}) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	s, ok := t.sessions[address]
+	if !ok {
+		return
+	}
+
+	now := t.clock.Now()
+	switch r := req.(type) {
+	case *models.LockRequest:
+		s.lastHeartbeatAt = now
+		if r.Mode == models.EXCLUSIVE {
+			s.hold(r.Resource, resp.(*models.LockResponse).GetExpiresAt())
+		}
+	case *models.LockGroupRequest:
+		s.lastHeartbeatAt = now
+		locks := resp.(*models.LockGroupResponse).GetLocks()
+		for i, resource := range r.Resources {
+			if i < len(locks) {
+				s.hold(resource, locks[i].GetExpiresAt())
+			}
+		}
+	case *models.ReleaseRequest:
+		if r.Mode == models.EXCLUSIVE {
+			s.release(r.Resource.GetKey(), r.Resource.GetOwner())
+		}
+	case *v2.LockRequest:
+		s.lastHeartbeatAt = now
+		if r.Mode == v2.EXCLUSIVE {
+			s.hold(&models.Resource{Key: r.Resource.GetKey(), Owner: r.Resource.GetOwner()}, resp.(*v2.LockResponse).GetExpiresAt())
+		}
+	case *v2.LockGroupRequest:
+		s.lastHeartbeatAt = now
+		locks := resp.(*v2.LockGroupResponse).GetLocks()
+		for i, resource := range r.Resources {
+			if i < len(locks) {
+				s.hold(&models.Resource{Key: resource.GetKey(), Owner: resource.GetOwner()}, locks[i].GetExpiresAt())
+			}
+		}
+	case *v2.ReleaseRequest:
+		if r.Mode == v2.EXCLUSIVE {
+			s.release(r.Resource.GetKey(), r.Resource.GetOwner())
+		}
+	}
+}
+
+// hold records that the session holds resource until expiresAt, a unix
+// timestamp in nanoseconds that is 0 for locks that do not expire.
+func (s *session) hold(resource *models.Resource, expiresAt int64) {
+	lock := heldLock{owner: resource.GetOwner()}
+	if expiresAt > 0 {
+		lock.expiresAt = time.Unix(0, expiresAt)
+	}
+	s.locks[resource.GetKey()] = lock
+}
+
+func (s *session) release(key, owner string) {
+	if s.locks[key].owner == owner {
+		delete(s.locks, key)
+	}
+}
+
+func (s *session) heldLocks(now time.Time) []HeldLock {
+	locks := make([]HeldLock, 0, len(s.locks))
+	for key, lock := range s.locks {
+		if lock.expiresAt.IsZero() || now.Before(lock.expiresAt) {
+			locks = append(locks, HeldLock{Key: key, Owner: lock.owner})
+		}
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Key < locks[j].Key
+	})
+	return locks
+}
+
+func connectionAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+type listener struct {
+	net.Listener
+	tracker *Tracker
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.tracker.add(conn), nil
+}
+
+type trackedConn struct {
+	net.Conn
+	tracker *Tracker
+	once    sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.tracker.remove(c)
+	})
+	return c.Conn.Close()
+}
@@ -0,0 +1,442 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/deadlock"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/handlers"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	contentionWindow = 5 * time.Minute
+	deadlockInterval = time.Second
+)
+
+var ErrInvalidConfig = errors.New("clients, servers, keys and steps must be positive, the tick and ttl must be positive, and probabilities must be between 0 and 1")
+
+// Config describes one schedule. Every random choice in a run is made from
+// Seed, so a schedule that breaks the invariant can be replayed exactly.
+type Config struct {
+	Seed    int64
+	Clients int
+	Servers int
+	Keys    int
+	Steps   int
+	// KeyPrefix is prepended to every key, so that schedules can share a
+	// backend without seeing each other's locks.
+	KeyPrefix string
+
+	// Tick is how far the clock moves each step, and TTL the ttl clients
+	// lock with. Clients refresh once half of the ttl has passed.
+	Tick time.Duration
+	TTL  time.Duration
+
+	// PartitionProbability is the chance each step of cutting the link
+	// between a random client and server, and HealProbability the chance of
+	// healing a cut link. A client whose server is unreachable moves on to
+	// the next one.
+	PartitionProbability float64
+	HealProbability      float64
+	// DropProbability is the chance that a request is handled but its
+	// response is lost, and DelayProbability the chance that the response
+	// arrives up to MaxDelaySteps steps late.
+	DropProbability  float64
+	DelayProbability float64
+	MaxDelaySteps    int
+	// SweepProbability is the chance each step of expiring locks, as the
+	// sweeper would, and ReleaseProbability the chance that a client holding
+	// a lock releases it rather than refreshing it.
+	SweepProbability   float64
+	ReleaseProbability float64
+}
+
+// DefaultConfig returns a small, hostile schedule for seed.
+func DefaultConfig(seed int64) Config {
+	return Config{
+		Seed:                 seed,
+		Clients:              4,
+		Servers:              2,
+		Keys:                 2,
+		Steps:                60,
+		KeyPrefix:            fmt.Sprintf("simulation-%d-", seed),
+		Tick:                 500 * time.Millisecond,
+		TTL:                  3 * time.Second,
+		PartitionProbability: 0.1,
+		HealProbability:      0.2,
+		DropProbability:      0.1,
+		DelayProbability:     0.1,
+		MaxDelaySteps:        8,
+		SweepProbability:     0.2,
+		ReleaseProbability:   0.05,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Clients <= 0 || c.Servers <= 0 || c.Keys <= 0 || c.Steps <= 0 || c.Tick <= 0 || c.TTL <= 0 || c.MaxDelaySteps < 0 {
+		return ErrInvalidConfig
+	}
+	for _, p := range []float64{c.PartitionProbability, c.HealProbability, c.DropProbability, c.DelayProbability, c.SweepProbability, c.ReleaseProbability} {
+		if p < 0 || p > 1 {
+			return ErrInvalidConfig
+		}
+	}
+	return nil
+}
+
+// Result counts what happened during a run.
+type Result struct {
+	Acquisitions int
+	Refreshes    int
+	Releases     int
+	Collisions   int
+	Unreachable  int
+	Dropped      int
+	Delayed      int
+	Sweeps       int
+}
+
+// Violation is returned by Run when more than one client believed it held
+// a key at once, or when the client that believed it held a key was not the
+// owner stored in the backend.
+type Violation struct {
+	Seed    int64
+	Step    int
+	Key     string
+	Holders []string
+	Stored  string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("seed %d, step %d: %s believed held by %s, stored owner %q", v.Seed, v.Step, v.Key, strings.Join(v.Holders, ", "), v.Stored)
+}
+
+// Run plays the schedule in cfg against lockDB, with one locket handler per
+// virtual server sharing it, and checks after every step that no key is
+// believed held by two clients at once. Calls are made one at a time from
+// the calling goroutine and time only moves when clock is incremented, so a
+// run is deterministic for a deterministic backend.
+func Run(logger lager.Logger, lockDB db.LockDB, clock *fakeclock.FakeClock, cfg Config) (*Result, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &simulation{
+		cfg:    cfg,
+		logger: logger.Session("simulation", lager.Data{"seed": cfg.Seed}),
+		lockDB: lockDB,
+		clock:  clock,
+		random: rand.New(rand.NewSource(cfg.Seed)),
+		result: &Result{},
+	}
+
+	metronClient := &mfakes.FakeIngressClient{}
+	for i := 0; i < cfg.Servers; i++ {
+		serverLogger := s.logger.Session("server", lager.Data{"server": i})
+		s.servers = append(s.servers, handlers.NewLocketHandler(
+			serverLogger,
+			lockDB,
+			expiration.NewNoopLockPick(),
+			contention.NewTracker(serverLogger, clock, metronClient, nil, contentionWindow),
+			deadlock.NewDetector(serverLogger, clock, deadlockInterval),
+			clock,
+			make(chan struct{}, 1),
+		))
+	}
+	for i := 0; i < cfg.Clients; i++ {
+		s.clients = append(s.clients, &client{
+			owner:  fmt.Sprintf("client-%d", i),
+			server: i % cfg.Servers,
+			holds:  make(map[string]*hold),
+		})
+	}
+	for i := 0; i < cfg.Keys; i++ {
+		s.keys = append(s.keys, fmt.Sprintf("%s%d", cfg.KeyPrefix, i))
+	}
+
+	for s.step = 0; s.step < cfg.Steps; s.step++ {
+		err = s.runStep()
+		if err != nil {
+			return s.result, err
+		}
+	}
+	return s.result, nil
+}
+
+type simulation struct {
+	cfg    Config
+	logger lager.Logger
+	lockDB db.LockDB
+	clock  *fakeclock.FakeClock
+	random *rand.Rand
+	result *Result
+
+	servers []models.LocketServer
+	clients []*client
+	keys    []string
+	cut     []link
+	pending []*delivery
+	step    int
+}
+
+type link struct {
+	client, server int
+}
+
+type client struct {
+	owner  string
+	server int
+	holds  map[string]*hold
+}
+
+// hold is what a client believes about its lock on a key. A lock granted to
+// a request sent at t is believed held until t plus the ttl, which is never
+// later than the server lets it expire.
+type hold struct {
+	held       bool
+	sentAt     time.Time
+	validUntil time.Time
+	// epoch changes whenever the client lets go of the lock, so that late
+	// responses to earlier requests are ignored.
+	epoch int
+}
+
+func (h *hold) believed(now time.Time) bool {
+	return h.held && now.Before(h.validUntil)
+}
+
+// delivery is the response to a lock request on its way back to a client.
+type delivery struct {
+	step   int
+	client *client
+	key    string
+	epoch  int
+	sentAt time.Time
+	err    error
+}
+
+func (c *client) hold(key string) *hold {
+	h, ok := c.holds[key]
+	if !ok {
+		h = &hold{}
+		c.holds[key] = h
+	}
+	return h
+}
+
+func (s *simulation) runStep() error {
+	s.clock.Increment(s.cfg.Tick)
+
+	s.deliverPending()
+
+	if s.chance(s.cfg.SweepProbability) {
+		s.result.Sweeps++
+		_, err := s.lockDB.ExpireLocks(s.logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.changePartitions()
+
+	for _, i := range s.random.Perm(len(s.clients)) {
+		s.act(s.clients[i])
+	}
+
+	return s.checkInvariant()
+}
+
+func (s *simulation) deliverPending() {
+	var later []*delivery
+	for _, d := range s.pending {
+		if d.step > s.step {
+			later = append(later, d)
+			continue
+		}
+		s.deliver(d)
+	}
+	s.pending = later
+}
+
+func (s *simulation) changePartitions() {
+	if len(s.cut) > 0 && s.chance(s.cfg.HealProbability) {
+		i := s.random.Intn(len(s.cut))
+		s.cut = append(s.cut[:i], s.cut[i+1:]...)
+	}
+	if s.chance(s.cfg.PartitionProbability) {
+		l := link{client: s.random.Intn(len(s.clients)), server: s.random.Intn(len(s.servers))}
+		if !s.partitioned(l) {
+			s.cut = append(s.cut, l)
+		}
+	}
+}
+
+func (s *simulation) partitioned(l link) bool {
+	for _, cut := range s.cut {
+		if cut == l {
+			return true
+		}
+	}
+	return false
+}
+
+// act makes client lock, refresh or release a random key, or do nothing if
+// its lock on the key is not due for a refresh.
+func (s *simulation) act(c *client) {
+	key := s.keys[s.random.Intn(len(s.keys))]
+	h := c.hold(key)
+	now := s.clock.Now()
+
+	if h.believed(now) {
+		if s.chance(s.cfg.ReleaseProbability) {
+			s.release(c, key, h)
+			return
+		}
+		if now.Sub(h.sentAt) < s.cfg.TTL/2 {
+			return
+		}
+	}
+
+	s.lock(c, key, h)
+}
+
+func (s *simulation) lock(c *client, key string, h *hold) {
+	server, ok := s.reach(c)
+	if !ok {
+		return
+	}
+
+	d := &delivery{client: c, key: key, epoch: h.epoch, sentAt: s.clock.Now()}
+	_, d.err = server.Lock(context.Background(), &models.LockRequest{
+		Resource:          &models.Resource{Key: key, Owner: c.owner, TypeCode: models.LOCK},
+		TtlInMilliseconds: int64(s.cfg.TTL / time.Millisecond),
+	})
+
+	switch {
+	case s.chance(s.cfg.DropProbability):
+		s.result.Dropped++
+	case s.cfg.MaxDelaySteps > 0 && s.chance(s.cfg.DelayProbability):
+		s.result.Delayed++
+		d.step = s.step + 1 + s.random.Intn(s.cfg.MaxDelaySteps)
+		s.pending = append(s.pending, d)
+	default:
+		s.deliver(d)
+	}
+}
+
+// deliver updates what the client believes from the response to a lock
+// request. A failed request only gives up the lock when another owner holds
+// it; otherwise the client keeps what it had until it runs out.
+func (s *simulation) deliver(d *delivery) {
+	h := d.client.hold(d.key)
+	if d.epoch != h.epoch {
+		return
+	}
+
+	if d.err != nil {
+		if grpc.Code(d.err) == codes.AlreadyExists {
+			s.result.Collisions++
+			h.held = false
+		}
+		return
+	}
+
+	validUntil := d.sentAt.Add(s.cfg.TTL)
+	if !validUntil.After(h.validUntil) {
+		return
+	}
+
+	if h.believed(s.clock.Now()) {
+		s.result.Refreshes++
+	} else {
+		s.result.Acquisitions++
+	}
+	h.held = true
+	h.sentAt = d.sentAt
+	h.validUntil = validUntil
+}
+
+// release lets go of the lock before asking for it to be released, so the
+// client never believes it holds a lock the server may have handed on.
+func (s *simulation) release(c *client, key string, h *hold) {
+	h.held = false
+	h.epoch++
+	s.result.Releases++
+
+	server, ok := s.reach(c)
+	if !ok {
+		return
+	}
+	server.Release(context.Background(), &models.ReleaseRequest{
+		Resource: &models.Resource{Key: key, Owner: c.owner},
+	})
+}
+
+// reach returns the server the client talks to, or moves the client on to
+// the next server if the link to it is cut.
+func (s *simulation) reach(c *client) (models.LocketServer, bool) {
+	if s.partitioned(link{client: s.clientIndex(c), server: c.server}) {
+		s.result.Unreachable++
+		c.server = (c.server + 1) % len(s.servers)
+		return nil, false
+	}
+	return s.servers[c.server], true
+}
+
+func (s *simulation) clientIndex(c *client) int {
+	for i, other := range s.clients {
+		if other == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *simulation) checkInvariant() error {
+	now := s.clock.Now()
+
+	for _, key := range s.keys {
+		var holders []string
+		for _, c := range s.clients {
+			if c.hold(key).believed(now) {
+				holders = append(holders, c.owner)
+			}
+		}
+		if len(holders) == 0 {
+			continue
+		}
+
+		var stored string
+		lock, err := s.lockDB.Fetch(s.logger, key)
+		if err == nil {
+			stored = lock.Owner
+		} else if err != models.ErrResourceNotFound {
+			return err
+		}
+
+		if len(holders) > 1 || holders[0] != stored {
+			violation := &Violation{Seed: s.cfg.Seed, Step: s.step, Key: key, Holders: holders, Stored: stored}
+			s.logger.Error("invariant-violated", violation)
+			return violation
+		}
+	}
+	return nil
+}
+
+func (s *simulation) chance(p float64) bool {
+	return p > 0 && s.random.Float64() < p
+}
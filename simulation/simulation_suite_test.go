@@ -0,0 +1,44 @@
+package simulation_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/guidprovider"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/raftdb"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+var (
+	logger    lager.Logger
+	fakeClock *fakeclock.FakeClock
+	raftDB    *raftdb.RaftDB
+)
+
+func TestSimulation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Simulation Suite")
+}
+
+// every schedule shares one backend, each with its own keys, as starting a
+// raft node per schedule would dominate the run time
+var _ = BeforeSuite(func() {
+	// schedules make hundreds of thousands of calls, so nothing is logged
+	logger = lager.NewLogger("simulation")
+	fakeClock = fakeclock.NewFakeClock(time.Now())
+
+	var err error
+	raftDB, err = raftdb.NewInMemoryRaftDB(logger, guidprovider.DefaultGuidProvider, fakeClock)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(raftDB.WaitForLeader(5 * time.Second)).To(Succeed())
+})
+
+var _ = AfterSuite(func() {
+	if raftDB != nil {
+		Expect(raftDB.Shutdown()).To(Succeed())
+	}
+})
@@ -0,0 +1,96 @@
+package simulation_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/db"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/simulation"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// schedules is how many randomized schedules are checked against the
+// in-memory backend on every test run.
+const schedules = 2000
+
+var _ = Describe("Simulation", func() {
+	It("never lets two clients hold a key at once", func() {
+		total := simulation.Result{}
+		for seed := int64(0); seed < schedules; seed++ {
+			result, err := simulation.Run(logger, raftDB, fakeClock, simulation.DefaultConfig(seed))
+			Expect(err).NotTo(HaveOccurred())
+
+			total.Acquisitions += result.Acquisitions
+			total.Collisions += result.Collisions
+			total.Unreachable += result.Unreachable
+			total.Delayed += result.Delayed
+			total.Releases += result.Releases
+		}
+
+		// the schedules are only worth something if they are hostile
+		Expect(total.Acquisitions).To(BeNumerically(">", schedules))
+		Expect(total.Collisions).NotTo(BeZero())
+		Expect(total.Unreachable).NotTo(BeZero())
+		Expect(total.Delayed).NotTo(BeZero())
+		Expect(total.Releases).NotTo(BeZero())
+	})
+
+	It("replays a schedule exactly from its seed", func() {
+		cfg := simulation.DefaultConfig(42)
+		cfg.KeyPrefix = "replay-first-"
+		first, err := simulation.Run(logger, raftDB, fakeClock, cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		cfg.KeyPrefix = "replay-second-"
+		second, err := simulation.Run(logger, raftDB, fakeClock, cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second).To(Equal(first))
+	})
+
+	It("catches a backend that hands out a held lock", func() {
+		broken := &grantingDB{LockDB: raftDB}
+
+		var violation *simulation.Violation
+		for seed := int64(0); seed < 100 && violation == nil; seed++ {
+			cfg := simulation.DefaultConfig(seed)
+			cfg.KeyPrefix = "broken-" + cfg.KeyPrefix
+			_, err := simulation.Run(logger, broken, fakeClock, cfg)
+			if err != nil {
+				Expect(err).To(BeAssignableToTypeOf(&simulation.Violation{}))
+				violation = err.(*simulation.Violation)
+			}
+		}
+
+		Expect(violation).NotTo(BeNil())
+		Expect(violation.Error()).To(ContainSubstring("believed held by"))
+	})
+
+	It("rejects invalid configs", func() {
+		cfg := simulation.DefaultConfig(1)
+		cfg.Servers = 0
+		_, err := simulation.Run(logger, raftDB, fakeClock, cfg)
+		Expect(err).To(Equal(simulation.ErrInvalidConfig))
+
+		cfg = simulation.DefaultConfig(1)
+		cfg.DropProbability = 2
+		_, err = simulation.Run(logger, raftDB, fakeClock, cfg)
+		Expect(err).To(Equal(simulation.ErrInvalidConfig))
+	})
+})
+
+// grantingDB tells the caller it got the lock even when another owner holds
+// it, without storing anything.
+type grantingDB struct {
+	db.LockDB
+}
+
+func (g *grantingDB) Lock(logger lager.Logger, resource *models.Resource, ttl time.Duration) (*db.Lock, error) {
+	lock, err := g.LockDB.Lock(logger, resource, ttl)
+	if err == models.ErrLockCollision {
+		return &db.Lock{Resource: resource}, nil
+	}
+	return lock, err
+}
@@ -0,0 +1 @@
+package slowlog // import "code.cloudfoundry.org/locket/slowlog"
@@ -0,0 +1,125 @@
+package slowlog
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type timingsKey struct{}
+
+// Timings accumulates how long a request spent in each phase of its
+// handling, so that slow requests can be broken down when they are logged.
+// Besides the phases tracked by the handler, it has the queue wait, the
+// time spent in the interceptors before the handler started, and the
+// serialization, the time marshalling the response.
+type Timings struct {
+	clock          clock.Clock
+	mutex          sync.Mutex
+	phases         map[string]time.Duration
+	start          time.Time
+	handlerStarted time.Time
+}
+
+// marshaler is implemented by the generated messages, which gRPC marshals
+// the same way.
+type marshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// Track starts timing phase for the request in ctx and returns a function
+// that stops the timer. It is a no-op when ctx has no Timings.
+func Track(ctx context.Context, phase string) func() {
+	timings, ok := ctx.Value(timingsKey{}).(*Timings)
+	if !ok {
+		return func() {}
+	}
+
+	start := timings.clock.Now()
+	return func() {
+		elapsed := timings.clock.Since(start)
+
+		timings.mutex.Lock()
+		timings.phases[phase] += elapsed
+		timings.mutex.Unlock()
+	}
+}
+
+func (t *Timings) markHandlerStarted() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.handlerStarted.IsZero() {
+		t.handlerStarted = t.clock.Now()
+	}
+}
+
+func (t *Timings) data(total time.Duration) lager.Data {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.handlerStarted.IsZero() {
+		t.phases["queue_wait"] += t.handlerStarted.Sub(t.start)
+	}
+
+	data := lager.Data{"total": total.String()}
+	other := total
+	for phase, duration := range t.phases {
+		data[phase] = duration.String()
+		other -= duration
+	}
+	data["other"] = other.String()
+	return data
+}
+
+// NewInterceptor returns a unary interceptor that logs a "slow-request" entry
+// with a timing breakdown for every RPC that takes longer than threshold. It
+// should come before the interceptors that can hold a request up, such as
+// the overload limiter, and NewHandlerStartInterceptor should come last, so
+// that the queue wait covers them. Only the responses of slow requests are
+// marshalled to time their serialization, which is then part of the total,
+// so fast requests are not marshalled twice.
+func NewInterceptor(logger lager.Logger, clock clock.Clock, threshold time.Duration) grpc.UnaryServerInterceptor {
+	logger = logger.Session("slow-rpc")
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := clock.Now()
+		timings := &Timings{clock: clock, phases: make(map[string]time.Duration), start: start}
+		ctx = context.WithValue(ctx, timingsKey{}, timings)
+
+		resp, err := handler(ctx, req)
+		total := clock.Since(start)
+
+		if total > threshold {
+			if m, ok := resp.(marshaler); ok && err == nil {
+				done := Track(ctx, "serialization")
+				m.Marshal()
+				done()
+				total = clock.Since(start)
+			}
+
+			data := timings.data(total)
+			data["method"] = info.FullMethod
+			data["threshold"] = threshold.String()
+			logger.Info("slow-request", data)
+		}
+
+		return resp, err
+	}
+}
+
+// NewHandlerStartInterceptor returns a unary interceptor that marks when the
+// handler of the request starts, ending its queue wait. It must be the last
+// interceptor.
+func NewHandlerStartInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timings, ok := ctx.Value(timingsKey{}).(*Timings); ok {
+			timings.markHandlerStarted()
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,138 @@
+package slowlog_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/slowlog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type slowMarshaler struct {
+	clock      *fakeclock.FakeClock
+	took       time.Duration
+	marshalled bool
+}
+
+func (m *slowMarshaler) Marshal() ([]byte, error) {
+	m.clock.Increment(m.took)
+	m.marshalled = true
+	return nil, nil
+}
+
+var _ = Describe("Slow RPC logging", func() {
+	var (
+		logger      *lagertest.TestLogger
+		fakeClock   *fakeclock.FakeClock
+		threshold   time.Duration
+		queueTime   time.Duration
+		dbTime      time.Duration
+		handlerTime time.Duration
+		resp        interface{}
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		threshold = time.Second
+		queueTime = 0
+		dbTime = 0
+		handlerTime = 0
+		resp = nil
+	})
+
+	JustBeforeEach(func() {
+		interceptor := slowlog.NewInterceptor(logger, fakeClock, threshold)
+		handlerStart := slowlog.NewHandlerStartInterceptor()
+		info := &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			fakeClock.Increment(queueTime)
+			return handlerStart(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				done := slowlog.Track(ctx, "db")
+				fakeClock.Increment(dbTime)
+				done()
+				fakeClock.Increment(handlerTime)
+				return resp, nil
+			})
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("when the request is faster than the threshold", func() {
+		BeforeEach(func() {
+			dbTime = 100 * time.Millisecond
+			handlerTime = 100 * time.Millisecond
+		})
+
+		It("does not log", func() {
+			Expect(logger.Logs()).To(BeEmpty())
+		})
+	})
+
+	Context("when the request is slower than the threshold", func() {
+		BeforeEach(func() {
+			dbTime = 2 * time.Second
+			handlerTime = 500 * time.Millisecond
+		})
+
+		It("logs the request with a timing breakdown", func() {
+			logs := logger.Logs()
+			Expect(logs).To(HaveLen(1))
+			Expect(logs[0].Message).To(Equal("test.slow-rpc.slow-request"))
+			Expect(logs[0].Data).To(HaveKeyWithValue("method", "/models.Locket/Lock"))
+			Expect(logs[0].Data).To(HaveKeyWithValue("total", "2.5s"))
+			Expect(logs[0].Data).To(HaveKeyWithValue("db", "2s"))
+			Expect(logs[0].Data).To(HaveKeyWithValue("other", "500ms"))
+		})
+
+		Context("when the request waited before its handler started", func() {
+			BeforeEach(func() {
+				queueTime = 300 * time.Millisecond
+			})
+
+			It("logs the queue wait", func() {
+				logs := logger.Logs()
+				Expect(logs[0].Data).To(HaveKeyWithValue("total", "2.8s"))
+				Expect(logs[0].Data).To(HaveKeyWithValue("queue_wait", "300ms"))
+				Expect(logs[0].Data).To(HaveKeyWithValue("other", "500ms"))
+			})
+		})
+
+		Context("when the response is a message", func() {
+			BeforeEach(func() {
+				resp = &slowMarshaler{clock: fakeClock, took: 200 * time.Millisecond}
+			})
+
+			It("logs how long the response takes to marshal", func() {
+				logs := logger.Logs()
+				Expect(logs[0].Data).To(HaveKeyWithValue("total", "2.7s"))
+				Expect(logs[0].Data).To(HaveKeyWithValue("serialization", "200ms"))
+				Expect(logs[0].Data).To(HaveKeyWithValue("other", "500ms"))
+			})
+		})
+	})
+
+	Context("when a fast request returns a message", func() {
+		var message *slowMarshaler
+
+		BeforeEach(func() {
+			dbTime = 100 * time.Millisecond
+			message = &slowMarshaler{clock: fakeClock}
+			resp = message
+		})
+
+		It("does not marshal it", func() {
+			Expect(message.marshalled).To(BeFalse())
+			Expect(logger.Logs()).To(BeEmpty())
+		})
+	})
+
+	It("does nothing when tracking outside of an intercepted request", func() {
+		done := slowlog.Track(context.Background(), "db")
+		done()
+	})
+})
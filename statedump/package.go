@@ -0,0 +1 @@
+package statedump // import "code.cloudfoundry.org/locket/statedump"
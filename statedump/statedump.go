@@ -0,0 +1,75 @@
+package statedump
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/debugserver"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/expiration"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/http_server"
+)
+
+// StatePath is the debug server path that serves the state dump.
+const StatePath = "/debug/state"
+
+// State is the in-memory state of the locket server as served by the
+// handler.
+type State struct {
+	RegisteredTTLs []expiration.RegisteredTTL     `json:"registered_ttls"`
+	WaitQueues     map[string][]contention.Waiter `json:"wait_queues"`
+}
+
+type handler struct {
+	logger   lager.Logger
+	lockPick expiration.LockPick
+	tracker  contention.Tracker
+}
+
+// NewHandler returns an http.Handler that writes the TTLs registered with
+// lockPick and the wait queues recorded by tracker as JSON.
+func NewHandler(logger lager.Logger, lockPick expiration.LockPick, tracker contention.Tracker) http.Handler {
+	return &handler{
+		logger:   logger.Session("state-dump"),
+		lockPick: lockPick,
+		tracker:  tracker,
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := State{
+		RegisteredTTLs: h.lockPick.RegisteredTTLs(),
+		WaitQueues:     h.tracker.WaitQueues(),
+	}
+	if state.RegisteredTTLs == nil {
+		state.RegisteredTTLs = []expiration.RegisteredTTL{}
+	}
+	if state.WaitQueues == nil {
+		state.WaitQueues = map[string][]contention.Waiter{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(state)
+	if err != nil {
+		h.logger.Error("failed-to-encode-state", err)
+	}
+}
+
+// Runner returns a debug server that serves the usual debugserver endpoints
+// along with the state dump at StatePath, and any other handlers by path.
+func Runner(address string, sink debugserver.ReconfigurableSinkInterface, stateHandler http.Handler, handlers map[string]http.Handler) ifrit.Runner {
+	mux := http.NewServeMux()
+	mux.Handle("/", debugserver.Handler(sink))
+	mux.Handle(StatePath, stateHandler)
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+	return http_server.New(address, mux)
+}
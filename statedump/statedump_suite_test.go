@@ -0,0 +1,13 @@
+package statedump_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStatedump(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Statedump Suite")
+}
@@ -0,0 +1,89 @@
+package statedump_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/contention/contentionfakes"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/expiration/expirationfakes"
+	"code.cloudfoundry.org/locket/statedump"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("State dump", func() {
+	var (
+		logger       *lagertest.TestLogger
+		fakeLockPick *expirationfakes.FakeLockPick
+		fakeTracker  *contentionfakes.FakeTracker
+		handler      http.Handler
+		recorder     *httptest.ResponseRecorder
+		method       string
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("statedump")
+		fakeLockPick = &expirationfakes.FakeLockPick{}
+		fakeTracker = &contentionfakes.FakeTracker{}
+		handler = statedump.NewHandler(logger, fakeLockPick, fakeTracker)
+		recorder = httptest.NewRecorder()
+		method = "GET"
+	})
+
+	JustBeforeEach(func() {
+		request, err := http.NewRequest(method, statedump.StatePath, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(recorder, request)
+	})
+
+	Context("when there is state to dump", func() {
+		var expiresAt, waitingSince time.Time
+
+		BeforeEach(func() {
+			expiresAt = time.Unix(1500000000, 0).UTC()
+			waitingSince = time.Unix(1499999990, 0).UTC()
+
+			fakeLockPick.RegisteredTTLsReturns([]expiration.RegisteredTTL{
+				{Key: "quack", Owner: "jim", Type: "lock", ModifiedId: "guid", ModifiedIndex: 3, TtlInSeconds: 10, ExpiresAt: expiresAt},
+			})
+			fakeTracker.WaitQueuesReturns(map[string][]contention.Waiter{
+				"quack": {{Owner: "bob", WaitingSince: waitingSince}},
+			})
+		})
+
+		It("writes the registered ttls and wait queues as json", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			var state statedump.State
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &state)).To(Succeed())
+			Expect(state.RegisteredTTLs).To(ConsistOf(expiration.RegisteredTTL{
+				Key: "quack", Owner: "jim", Type: "lock", ModifiedId: "guid", ModifiedIndex: 3, TtlInSeconds: 10, ExpiresAt: expiresAt,
+			}))
+			Expect(state.WaitQueues).To(HaveKeyWithValue("quack", []contention.Waiter{{Owner: "bob", WaitingSince: waitingSince}}))
+		})
+	})
+
+	Context("when there is no state", func() {
+		It("writes empty collections", func() {
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"registered_ttls": [], "wait_queues": {}}`))
+		})
+	})
+
+	Context("when the request is not a GET", func() {
+		BeforeEach(func() {
+			method = "POST"
+		})
+
+		It("rejects the request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(fakeLockPick.RegisteredTTLsCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1 @@
+package testserver // import "code.cloudfoundry.org/locket/testserver"
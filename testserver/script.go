@@ -0,0 +1,111 @@
+package testserver
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Names of the methods that can be scripted.
+const (
+	LockMethod      = "Lock"
+	AcquireMethod   = "Acquire"
+	ReleaseMethod   = "Release"
+	FetchMethod     = "Fetch"
+	FetchAllMethod  = "FetchAll"
+	LockGroupMethod = "LockGroup"
+)
+
+// Step scripts how the server answers a single call.
+type Step struct {
+	// Delay is waited, in real time, before the call is handled. The call
+	// fails early if its context is done first.
+	Delay time.Duration
+	// Err is returned instead of handling the call, for example
+	// models.ErrLockCollision.
+	Err error
+	// ErrAfterHandling is returned after the call is handled, so that the
+	// call takes effect but the client sees it fail, as when a response is
+	// lost.
+	ErrAfterHandling error
+}
+
+type scripts struct {
+	lock  sync.Mutex
+	steps map[string][]Step
+	calls map[string]int
+}
+
+func newScripts() *scripts {
+	return &scripts{
+		steps: map[string][]Step{},
+		calls: map[string]int{},
+	}
+}
+
+// Script makes the next calls to method follow steps, one step per call.
+// Once the steps are used up, calls are handled normally again. Steps
+// scripted for a method that still has steps left are added after them.
+func (s *Server) Script(method string, steps ...Step) {
+	s.scripts.lock.Lock()
+	defer s.scripts.lock.Unlock()
+	s.scripts.steps[method] = append(s.scripts.steps[method], steps...)
+}
+
+// ClearScripts drops every step that has not been used yet.
+func (s *Server) ClearScripts() {
+	s.scripts.lock.Lock()
+	defer s.scripts.lock.Unlock()
+	s.scripts.steps = map[string][]Step{}
+}
+
+// CallCount returns the number of calls made to method, scripted or not.
+func (s *Server) CallCount(method string) int {
+	s.scripts.lock.Lock()
+	defer s.scripts.lock.Unlock()
+	return s.scripts.calls[method]
+}
+
+func (s *scripts) next(method string) (Step, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls[method]++
+	steps := s.steps[method]
+	if len(steps) == 0 {
+		return Step{}, false
+	}
+	s.steps[method] = steps[1:]
+	return steps[0], true
+}
+
+func (s *scripts) interceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	step, ok := s.next(path.Base(info.FullMethod))
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	if step.Delay > 0 {
+		timer := time.NewTimer(step.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if step.Err != nil {
+		return nil, step.Err
+	}
+
+	resp, err := handler(ctx, req)
+	if err == nil && step.ErrAfterHandling != nil {
+		return nil, step.ErrAfterHandling
+	}
+	return resp, err
+}
@@ -0,0 +1,91 @@
+package testserver_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/testserver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var _ = Describe("Script", func() {
+	var (
+		server  *testserver.Server
+		request *models.LockRequest
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = testserver.Start(lagertest.NewTestLogger("testserver"), clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+
+		request = &models.LockRequest{
+			Resource:     &models.Resource{Key: "quack", Owner: "iamthelizardking", TypeCode: models.LOCK},
+			TtlInSeconds: 10,
+		}
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	It("follows the steps one call at a time, then handles calls normally", func() {
+		server.Script(testserver.LockMethod,
+			testserver.Step{Err: models.ErrLockCollision},
+			testserver.Step{Err: grpc.Errorf(codes.Unavailable, "boom")},
+		)
+
+		_, err := server.Client.Lock(context.Background(), request)
+		Expect(err).To(MatchError(ContainSubstring("lock-collision")))
+		_, err = server.Client.Lock(context.Background(), request)
+		Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+		_, err = server.Client.Lock(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(server.CallCount(testserver.LockMethod)).To(Equal(3))
+	})
+
+	It("does not handle calls that fail", func() {
+		server.Script(testserver.LockMethod, testserver.Step{Err: grpc.Errorf(codes.Unavailable, "boom")})
+
+		_, err := server.Client.Lock(context.Background(), request)
+		Expect(err).To(HaveOccurred())
+
+		_, err = server.Client.Fetch(context.Background(), &models.FetchRequest{Key: "quack"})
+		Expect(grpc.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("can fail calls after handling them", func() {
+		server.Script(testserver.LockMethod, testserver.Step{ErrAfterHandling: grpc.Errorf(codes.Unavailable, "lost")})
+
+		_, err := server.Client.Lock(context.Background(), request)
+		Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+
+		resp, err := server.Client.Fetch(context.Background(), &models.FetchRequest{Key: "quack"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Resource.Owner).To(Equal("iamthelizardking"))
+	})
+
+	It("delays calls", func() {
+		server.Script(testserver.FetchMethod, testserver.Step{Delay: 200 * time.Millisecond})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := server.Client.Fetch(ctx, &models.FetchRequest{Key: "quack"})
+		Expect(grpc.Code(err)).To(Equal(codes.DeadlineExceeded))
+	})
+
+	It("drops unused steps when cleared", func() {
+		server.Script(testserver.ReleaseMethod, testserver.Step{Err: grpc.Errorf(codes.Unavailable, "boom")})
+		server.ClearScripts()
+
+		_, err := server.Client.Release(context.Background(), &models.ReleaseRequest{Resource: request.Resource})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
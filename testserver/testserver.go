@@ -0,0 +1,189 @@
+package testserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/bbs/guidprovider"
+	"code.cloudfoundry.org/clock"
+	mfakes "code.cloudfoundry.org/go-loggregator/testhelpers/fakes/v1"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/contention"
+	"code.cloudfoundry.org/locket/deadlock"
+	"code.cloudfoundry.org/locket/expiration"
+	"code.cloudfoundry.org/locket/grpcserver"
+	"code.cloudfoundry.org/locket/handlers"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/raftdb"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/grouper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	leaderTimeout     = 10 * time.Second
+	dialTimeout       = 5 * time.Second
+	contentionWindow  = 5 * time.Minute
+	deadlockInterval  = time.Second
+	certificateExpiry = 24 * time.Hour
+)
+
+// Server is a locket server running in the current process, for integration
+// tests. It keeps its locks in memory and serves on an ephemeral port on the
+// loopback interface, with a self-signed certificate that Client trusts.
+// Calls can be scripted to fail or be slow, see Script.
+type Server struct {
+	// Address is the host and port the server listens on.
+	Address string
+	// Client is connected to the server and ready to use.
+	Client models.LocketClient
+	// ClientTLSConfig trusts the server's certificate, for connecting more
+	// clients.
+	ClientTLSConfig *tls.Config
+
+	raftDB  *raftdb.RaftDB
+	process ifrit.Process
+	conn    *grpc.ClientConn
+	scripts *scripts
+}
+
+// Start starts a server and waits until it is ready to take locks. Locks
+// expire according to clock, so a fake clock lets tests control expiry.
+// Stop must be called to free the server's port and goroutines.
+func Start(logger lager.Logger, clock clock.Clock) (*Server, error) {
+	logger = logger.Session("locket-test-server")
+
+	serverTLSConfig, clientTLSConfig, err := selfSignedTLSConfigs()
+	if err != nil {
+		logger.Error("failed-to-create-certificate", err)
+		return nil, err
+	}
+
+	raftDB, err := raftdb.NewInMemoryRaftDB(logger, guidprovider.DefaultGuidProvider, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	err = raftDB.WaitForLeader(leaderTimeout)
+	if err != nil {
+		logger.Error("failed-to-elect-leader", err)
+		raftDB.Shutdown()
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		logger.Error("failed-to-listen", err)
+		raftDB.Shutdown()
+		return nil, err
+	}
+
+	metronClient := &mfakes.FakeIngressClient{}
+	lockPick := expiration.NewNoopLockPick()
+	tracker := contention.NewTracker(logger, clock, metronClient, nil, contentionWindow)
+	detector := deadlock.NewDetector(logger, clock, deadlockInterval)
+	handler := handlers.NewLocketHandler(logger, raftDB, lockPick, tracker, detector, clock, make(chan struct{}))
+	scripts := newScripts()
+
+	grpcServer := grpcserver.NewGRPCServerWithListener(logger, listener, serverTLSConfig, handler, grpc.UnaryInterceptor(scripts.interceptor))
+	members := grouper.Members{
+		{"server", grpcServer.WithV2Server(handlers.NewV2Handler(handler))},
+		{"expiration", expiration.NewSweeper(logger, raftDB, clock, metronClient, nil, locket.SQLRetryInterval)},
+		{"deadlock-detector", detector},
+	}
+	process := ifrit.Invoke(grouper.NewOrdered(os.Interrupt, members))
+
+	server := &Server{
+		Address:         listener.Addr().String(),
+		ClientTLSConfig: clientTLSConfig,
+		raftDB:          raftDB,
+		process:         process,
+		scripts:         scripts,
+	}
+
+	server.conn, err = grpc.Dial(server.Address,
+		grpc.WithTransportCredentials(credentials.NewTLS(clientTLSConfig)),
+		grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout),
+	)
+	if err != nil {
+		logger.Error("failed-to-connect", err)
+		server.Stop()
+		return nil, err
+	}
+	server.Client = models.NewLocketClient(server.conn)
+
+	logger.Info("started", lager.Data{"address": server.Address})
+	return server, nil
+}
+
+// Stop stops the server and closes Client. Every lock is lost.
+func (s *Server) Stop() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.process.Signal(os.Interrupt)
+	<-s.process.Wait()
+	s.raftDB.Shutdown()
+}
+
+// selfSignedTLSConfigs returns a tls config for a server on the loopback
+// interface with a new self-signed certificate, and a client tls config that
+// trusts it.
+func selfSignedTLSConfigs() (*tls.Config, *tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "locket test server"},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(certificateExpiry),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+
+	serverTLSConfig := &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key, Leaf: certificate}},
+		MinVersion:   tls.VersionTLS12,
+	}
+	clientTLSConfig := &tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+	}
+	return serverTLSConfig, clientTLSConfig, nil
+}
@@ -0,0 +1,13 @@
+package testserver_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestTestserver(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Testserver Suite")
+}
@@ -0,0 +1,73 @@
+package testserver_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/testserver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+var _ = Describe("Testserver", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		server    *testserver.Server
+		resource  *models.Resource
+	)
+
+	BeforeEach(func() {
+		var err error
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		server, err = testserver.Start(lagertest.NewTestLogger("testserver"), fakeClock)
+		Expect(err).NotTo(HaveOccurred())
+
+		resource = &models.Resource{Key: "quack", Owner: "iamthelizardking", Value: "i can do anything", TypeCode: models.LOCK}
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	It("serves locks to its client", func() {
+		_, err := server.Client.Lock(context.Background(), &models.LockRequest{Resource: resource, TtlInSeconds: 10})
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := server.Client.Fetch(context.Background(), &models.FetchRequest{Key: "quack"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Resource.Owner).To(Equal("iamthelizardking"))
+
+		_, err = server.Client.Lock(context.Background(), &models.LockRequest{
+			Resource:     &models.Resource{Key: "quack", Owner: "someone-else", TypeCode: models.LOCK},
+			TtlInSeconds: 10,
+		})
+		Expect(grpc.Code(err)).To(Equal(codes.AlreadyExists))
+	})
+
+	It("expires locks according to its clock", func() {
+		_, err := server.Client.Lock(context.Background(), &models.LockRequest{Resource: resource, TtlInSeconds: 10})
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeClock.WaitForWatcherAndIncrement(11 * time.Second)
+
+		Eventually(func() codes.Code {
+			_, err := server.Client.Fetch(context.Background(), &models.FetchRequest{Key: "quack"})
+			return grpc.Code(err)
+		}).Should(Equal(codes.NotFound))
+	})
+
+	It("lets more clients connect with its tls config", func() {
+		conn, err := grpc.Dial(server.Address, grpc.WithTransportCredentials(credentials.NewTLS(server.ClientTLSConfig)), grpc.WithBlock())
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = models.NewLocketClient(conn).Lock(context.Background(), &models.LockRequest{Resource: resource, TtlInSeconds: 10})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
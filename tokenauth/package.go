@@ -0,0 +1 @@
+package tokenauth // import "code.cloudfoundry.org/locket/tokenauth"
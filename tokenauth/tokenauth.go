@@ -0,0 +1,211 @@
+package tokenauth
+
+import (
+	"crypto/subtle"
+	"io/ioutil"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	jwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "bearer "
+)
+
+var (
+	ErrMissingCredentials = grpc.Errorf(codes.Unauthenticated, "missing client certificate or bearer token")
+	ErrInvalidToken       = grpc.Errorf(codes.Unauthenticated, "invalid bearer token")
+)
+
+type Config struct {
+	StaticTokens           map[string]string `json:"static_tokens,omitempty"`
+	JWTIssuer              string            `json:"jwt_issuer,omitempty"`
+	JWTAudience            string            `json:"jwt_audience,omitempty"`
+	JWTVerificationKeyFile string            `json:"jwt_verification_key_file,omitempty"`
+}
+
+// Enabled returns true if clients may authenticate with a token.
+func (c Config) Enabled() bool {
+	return len(c.StaticTokens) > 0 || c.JWTVerificationKeyFile != ""
+}
+
+type identityKey struct{}
+
+// Identity returns the identity of the client that made the request: the
+// common name of its certificate, or the identity its bearer token was
+// issued to.
+func Identity(ctx context.Context) string {
+	if identity, ok := ctx.Value(identityKey{}).(string); ok {
+		return identity
+	}
+	return certificateIdentity(ctx)
+}
+
+// Authenticator accepts clients that either present a verified client
+// certificate or a bearer token in the request metadata.
+type Authenticator struct {
+	logger       lager.Logger
+	config       Config
+	verification interface{}
+}
+
+func NewAuthenticator(logger lager.Logger, config Config) (*Authenticator, error) {
+	a := &Authenticator{
+		logger: logger.Session("token-auth"),
+		config: config,
+	}
+
+	if config.JWTVerificationKeyFile != "" {
+		keyPEM, err := ioutil.ReadFile(config.JWTVerificationKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		a.verification, err = parseVerificationKey(keyPEM)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// NewInterceptor returns a unary interceptor that rejects requests from
+// clients without a certificate unless they present a valid token. The
+// client identity is made available through Identity.
+func (a *Authenticator) NewInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamInterceptor authenticates streams the same way NewInterceptor
+// authenticates unary requests. Clients are authenticated once, when they
+// open the stream.
+func (a *Authenticator) NewStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if certificateIdentity(ctx) != "" {
+		return ctx, nil
+	}
+
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, ErrMissingCredentials
+	}
+
+	identity, err := a.Authenticate(token)
+	if err != nil {
+		a.logger.Info("rejected-token", lager.Data{"method": method, "reason": err.Error()})
+		return nil, ErrInvalidToken
+	}
+
+	return context.WithValue(ctx, identityKey{}, identity), nil
+}
+
+// identityStream carries the identity of an authenticated client in the
+// context of its stream.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// Authenticate returns the identity the token was issued to.
+func (a *Authenticator) Authenticate(token string) (string, error) {
+	for identity, secret := range a.config.StaticTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			return identity, nil
+		}
+	}
+
+	if a.verification == nil {
+		return "", ErrInvalidToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			return a.verification, nil
+		default:
+			return nil, ErrInvalidToken
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !claims.VerifyIssuer(a.config.JWTIssuer, a.config.JWTIssuer != "") {
+		return "", ErrInvalidToken
+	}
+	if a.config.JWTAudience != "" && !claims.VerifyAudience(a.config.JWTAudience, true) {
+		return "", ErrInvalidToken
+	}
+
+	// UAA client credentials tokens identify the client with client_id
+	if clientID, ok := claims["client_id"].(string); ok && clientID != "" {
+		return clientID, nil
+	}
+	if subject, ok := claims["sub"].(string); ok && subject != "" {
+		return subject, nil
+	}
+	return "", ErrInvalidToken
+}
+
+func parseVerificationKey(keyPEM []byte) (interface{}, error) {
+	rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(keyPEM)
+	if err == nil {
+		return rsaKey, nil
+	}
+	return jwt.ParseECPublicKeyFromPEM(keyPEM)
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	values := md[authorizationHeader]
+	if len(values) == 0 || !strings.HasPrefix(strings.ToLower(values[0]), bearerPrefix) {
+		return "", false
+	}
+	return values[0][len(bearerPrefix):], true
+}
+
+func certificateIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
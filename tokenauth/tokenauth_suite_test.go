@@ -0,0 +1,13 @@
+package tokenauth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestTokenauth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tokenauth Suite")
+}
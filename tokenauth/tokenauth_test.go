@@ -0,0 +1,236 @@
+package tokenauth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/tokenauth"
+	jwt "github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+var _ = Describe("Token authentication", func() {
+	var (
+		logger        *lagertest.TestLogger
+		config        tokenauth.Config
+		signingKey    *rsa.PrivateKey
+		keyFile       string
+		authenticator *tokenauth.Authenticator
+		err           error
+	)
+
+	signedToken := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(signingKey)
+		Expect(err).NotTo(HaveOccurred())
+		return token
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("token-auth")
+
+		signingKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		publicKey, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		f, err := ioutil.TempFile("", "jwt-key")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pem.Encode(f, &pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})).To(Succeed())
+		Expect(f.Close()).To(Succeed())
+		keyFile = f.Name()
+
+		config = tokenauth.Config{
+			StaticTokens:           map[string]string{"bbs": "bbs-secret"},
+			JWTIssuer:              "https://uaa.example.com/oauth/token",
+			JWTAudience:            "locket",
+			JWTVerificationKeyFile: keyFile,
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(keyFile)
+	})
+
+	JustBeforeEach(func() {
+		authenticator, err = tokenauth.NewAuthenticator(logger, config)
+	})
+
+	Describe("Authenticate", func() {
+		var claims jwt.MapClaims
+
+		BeforeEach(func() {
+			claims = jwt.MapClaims{
+				"iss":       "https://uaa.example.com/oauth/token",
+				"aud":       "locket",
+				"client_id": "auctioneer",
+				"sub":       "auctioneer-subject",
+				"exp":       time.Now().Add(time.Hour).Unix(),
+			}
+		})
+
+		It("accepts static tokens", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authenticator.Authenticate("bbs-secret")).To(Equal("bbs"))
+		})
+
+		It("accepts jwts signed by the issuer and identifies the client", func() {
+			Expect(authenticator.Authenticate(signedToken(claims))).To(Equal("auctioneer"))
+		})
+
+		It("falls back to the subject when there is no client id", func() {
+			delete(claims, "client_id")
+			Expect(authenticator.Authenticate(signedToken(claims))).To(Equal("auctioneer-subject"))
+		})
+
+		It("rejects unknown tokens", func() {
+			_, err := authenticator.Authenticate("not-a-token")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects expired jwts", func() {
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			_, err := authenticator.Authenticate(signedToken(claims))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects jwts from another issuer", func() {
+			claims["iss"] = "https://evil.example.com"
+			_, err := authenticator.Authenticate(signedToken(claims))
+			Expect(err).To(Equal(tokenauth.ErrInvalidToken))
+		})
+
+		It("rejects jwts for another audience", func() {
+			claims["aud"] = "bbs"
+			_, err := authenticator.Authenticate(signedToken(claims))
+			Expect(err).To(Equal(tokenauth.ErrInvalidToken))
+		})
+
+		Context("when the verification key cannot be read", func() {
+			BeforeEach(func() {
+				config.JWTVerificationKeyFile = "/does/not/exist"
+			})
+
+			It("fails to create the authenticator", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("NewInterceptor", func() {
+		var (
+			ctx      context.Context
+			identity string
+			called   bool
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			called = false
+			identity = ""
+		})
+
+		intercept := func() error {
+			interceptor := authenticator.NewInterceptor()
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/models.Locket/Lock"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				identity = tokenauth.Identity(ctx)
+				return nil, nil
+			})
+			return err
+		}
+
+		It("rejects requests without credentials", func() {
+			Expect(intercept()).To(Equal(tokenauth.ErrMissingCredentials))
+			Expect(called).To(BeFalse())
+		})
+
+		It("accepts requests with a valid bearer token", func() {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer bbs-secret"))
+			Expect(intercept()).To(Succeed())
+			Expect(called).To(BeTrue())
+			Expect(identity).To(Equal("bbs"))
+		})
+
+		It("rejects requests with an invalid bearer token", func() {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer nope"))
+			Expect(intercept()).To(Equal(tokenauth.ErrInvalidToken))
+			Expect(called).To(BeFalse())
+		})
+
+		It("accepts requests from clients with a verified certificate", func() {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: "rep"}}
+			ctx = peer.NewContext(ctx, &peer.Peer{
+				AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{cert},
+					VerifiedChains:   [][]*x509.Certificate{{cert}},
+				}},
+			})
+			Expect(intercept()).To(Succeed())
+			Expect(identity).To(Equal("rep"))
+		})
+	})
+
+	Describe("NewStreamInterceptor", func() {
+		var (
+			ctx      context.Context
+			identity string
+			called   bool
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			called = false
+			identity = ""
+		})
+
+		intercept := func() error {
+			interceptor := authenticator.NewStreamInterceptor()
+			return interceptor(nil, &serverStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/models.Locket/KeepAlive"}, func(srv interface{}, stream grpc.ServerStream) error {
+				called = true
+				identity = tokenauth.Identity(stream.Context())
+				return nil
+			})
+		}
+
+		It("rejects streams without credentials", func() {
+			Expect(intercept()).To(Equal(tokenauth.ErrMissingCredentials))
+			Expect(called).To(BeFalse())
+		})
+
+		It("identifies clients with a valid bearer token", func() {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer bbs-secret"))
+			Expect(intercept()).To(Succeed())
+			Expect(called).To(BeTrue())
+			Expect(identity).To(Equal("bbs"))
+		})
+
+		It("rejects streams with an invalid bearer token", func() {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer nope"))
+			Expect(intercept()).To(Equal(tokenauth.ErrInvalidToken))
+			Expect(called).To(BeFalse())
+		})
+	})
+})
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
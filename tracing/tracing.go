@@ -0,0 +1,171 @@
+package tracing
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/context"
+)
+
+const instrumentationName = "code.cloudfoundry.org/locket"
+
+// FlushTimeout bounds how long Flush waits for spans to be exported.
+const FlushTimeout = 5 * time.Second
+
+type Config struct {
+	OTLPEndpoint string  `json:"otlp_endpoint,omitempty"`
+	Insecure     bool    `json:"insecure,omitempty"`
+	SampleRatio  float64 `json:"sample_ratio,omitempty"`
+}
+
+// Initialize installs a global tracer provider that exports spans to the
+// configured OTLP endpoint, and the W3C trace-context propagator. The
+// returned function flushes and stops the exporter. When no endpoint is
+// configured only the propagator is installed, so trace context is still
+// passed through to downstream services.
+func Initialize(logger lager.Logger, serviceName string, config Config) (func(context.Context) error, error) {
+	logger = logger.Session("tracing", lager.Data{"otlp-endpoint": config.OTLPEndpoint})
+
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if config.OTLPEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.OTLPEndpoint)}
+	if config.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(context.Background(), opts...)
+	if err != nil {
+		logger.Error("failed-to-create-exporter", err)
+		return nil, err
+	}
+
+	sampleRatio := config.SampleRatio
+	if sampleRatio == 0 {
+		sampleRatio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	logger.Info("initialized")
+	return provider.Shutdown, nil
+}
+
+// Flush calls the function returned by Initialize, waiting at most
+// FlushTimeout. Deferred calls do not run on os.Exit, so it must be called
+// before exiting.
+func Flush(shutdown func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), FlushTimeout)
+	defer cancel()
+	return shutdown(ctx)
+}
+
+// fatalFlushSink flushes the spans when a fatal error is logged.
+type fatalFlushSink struct {
+	shutdown func(context.Context) error
+}
+
+// NewFatalFlushSink returns a sink that calls Flush with shutdown when a
+// fatal error is logged. lager panics right after logging it, and a panic
+// in another goroutine than main's exits without running main's deferred
+// calls, so spans would otherwise be lost.
+func NewFatalFlushSink(shutdown func(context.Context) error) lager.Sink {
+	return fatalFlushSink{shutdown: shutdown}
+}
+
+func (s fatalFlushSink) Log(log lager.LogFormat) {
+	if log.LogLevel == lager.FATAL {
+		Flush(s.shutdown)
+	}
+}
+
+// StartSpan starts a span named name as a child of any span in ctx.
+func StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name)
+}
+
+// EndSpan records err on the span, if there is one, and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// contextLogger is a logger that carries the context of a span, through the
+// sessions made from it.
+type contextLogger struct {
+	lager.Logger
+	ctx context.Context
+}
+
+func (l contextLogger) Session(task string, data ...lager.Data) lager.Logger {
+	return contextLogger{Logger: l.Logger.Session(task, data...), ctx: l.ctx}
+}
+
+func (l contextLogger) WithData(data lager.Data) lager.Logger {
+	return contextLogger{Logger: l.Logger.WithData(data), ctx: l.ctx}
+}
+
+// WithContext returns a logger that carries ctx, so that code that takes a
+// logger but no context, such as the SQLDB, can trace its work as part of
+// the span in ctx with StartLoggerSpan and stop it when ctx is done. When
+// the span is not recorded and ctx cannot be canceled it returns logger
+// itself, so that untraced calls do not allocate.
+func WithContext(logger lager.Logger, ctx context.Context) lager.Logger {
+	if !trace.SpanFromContext(ctx).IsRecording() && ctx.Done() == nil {
+		return logger
+	}
+	return contextLogger{Logger: logger, ctx: ctx}
+}
+
+// LoggerContext returns the context carried by logger, or the background
+// context when it carries none.
+func LoggerContext(logger lager.Logger) context.Context {
+	if l, ok := logger.(contextLogger); ok {
+		return l.ctx
+	}
+	return context.Background()
+}
+
+// WithoutCancel returns a logger that carries the span of logger but not
+// its cancelation, for work that logger's request shares with others and
+// that must not be stopped when that request is.
+func WithoutCancel(logger lager.Logger) lager.Logger {
+	l, ok := logger.(contextLogger)
+	if !ok {
+		return logger
+	}
+	return WithContext(l.Logger, trace.ContextWithSpan(context.Background(), trace.SpanFromContext(l.ctx)))
+}
+
+// StartLoggerSpan starts a span named name as a child of the span carried
+// by logger, and returns a logger carrying the new span. When logger
+// carries no recorded span it starts none, so that work done outside of a
+// traced request is not traced on its own.
+func StartLoggerSpan(logger lager.Logger, name string) (lager.Logger, trace.Span) {
+	l, ok := logger.(contextLogger)
+	if !ok || !trace.SpanFromContext(l.ctx).IsRecording() {
+		return logger, trace.SpanFromContext(context.Background())
+	}
+
+	ctx, span := StartSpan(l.ctx, name)
+	return contextLogger{Logger: l.Logger, ctx: ctx}, span
+}
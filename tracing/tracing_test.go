@@ -0,0 +1,136 @@
+package tracing_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/tracing"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Tracing", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	})
+
+	It("records child spans under the parent in the context", func() {
+		ctx, parent := tracing.StartSpan(context.Background(), "parent")
+		_, child := tracing.StartSpan(ctx, "child")
+		tracing.EndSpan(child, nil)
+		tracing.EndSpan(parent, nil)
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name()).To(Equal("child"))
+		Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		Expect(spans[0].Status().Code).To(Equal(codes.Unset))
+	})
+
+	It("records errors on the span", func() {
+		_, span := tracing.StartSpan(context.Background(), "failing")
+		tracing.EndSpan(span, errors.New("boom"))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status().Code).To(Equal(codes.Error))
+		Expect(spans[0].Status().Description).To(Equal("boom"))
+	})
+
+	Describe("loggers carrying a span", func() {
+		var logger *lagertest.TestLogger
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+		})
+
+		It("records spans started from the logger and its sessions under the span", func() {
+			ctx, parent := tracing.StartSpan(context.Background(), "parent")
+			spanLogger := tracing.WithContext(logger, ctx).Session("db")
+
+			childLogger, child := tracing.StartLoggerSpan(spanLogger, "child")
+			_, grandchild := tracing.StartLoggerSpan(childLogger.WithData(lager.Data{"key": "value"}), "grandchild")
+			tracing.EndSpan(grandchild, nil)
+			tracing.EndSpan(child, nil)
+			tracing.EndSpan(parent, nil)
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(3))
+			Expect(spans[0].Name()).To(Equal("grandchild"))
+			Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+			Expect(spans[1].Parent().SpanID()).To(Equal(spans[2].SpanContext().SpanID()))
+		})
+
+		It("starts no span from a logger without one", func() {
+			_, span := tracing.StartLoggerSpan(logger, "orphan")
+			tracing.EndSpan(span, nil)
+
+			Expect(recorder.Ended()).To(BeEmpty())
+		})
+
+		It("returns the logger itself when the context has no span", func() {
+			Expect(tracing.WithContext(logger, context.Background())).To(BeIdenticalTo(logger))
+		})
+
+		It("carries a context that can be canceled, without starting spans from it", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancelLogger := tracing.WithContext(logger, ctx).Session("db")
+
+			_, span := tracing.StartLoggerSpan(cancelLogger, "untraced")
+			tracing.EndSpan(span, nil)
+			Expect(recorder.Ended()).To(BeEmpty())
+
+			cancel()
+			Expect(tracing.LoggerContext(cancelLogger).Err()).To(Equal(context.Canceled))
+			Expect(tracing.LoggerContext(logger)).To(Equal(context.Background()))
+		})
+
+		It("keeps the span but not the cancelation of a logger WithoutCancel", func() {
+			ctx, parent := tracing.StartSpan(context.Background(), "parent")
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			detached := tracing.WithoutCancel(tracing.WithContext(logger, ctx))
+			Expect(tracing.LoggerContext(detached).Err()).NotTo(HaveOccurred())
+
+			_, child := tracing.StartLoggerSpan(detached, "child")
+			tracing.EndSpan(child, nil)
+			tracing.EndSpan(parent, nil)
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[0].Parent().SpanID()).To(Equal(spans[1].SpanContext().SpanID()))
+		})
+	})
+
+	Describe("NewFatalFlushSink", func() {
+		var flushes int
+
+		BeforeEach(func() {
+			flushes = 0
+		})
+
+		flush := func(context.Context) error {
+			flushes++
+			return nil
+		}
+
+		It("flushes when a fatal error is logged", func() {
+			sink := tracing.NewFatalFlushSink(flush)
+			sink.Log(lager.LogFormat{LogLevel: lager.INFO})
+			Expect(flushes).To(Equal(0))
+
+			sink.Log(lager.LogFormat{LogLevel: lager.FATAL})
+			Expect(flushes).To(Equal(1))
+		})
+	})
+})
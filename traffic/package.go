@@ -0,0 +1 @@
+package traffic // import "code.cloudfoundry.org/locket/traffic"
@@ -0,0 +1,268 @@
+package traffic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const (
+	LockMethod     = "Lock"
+	ReleaseMethod  = "Release"
+	FetchMethod    = "Fetch"
+	FetchAllMethod = "FetchAll"
+)
+
+const (
+	DefaultMaxSizeInMegabytes = 100
+
+	// queueSize is how many records can wait for the writer before new ones
+	// are dropped
+	queueSize = 4096
+)
+
+// Record is one recorded RPC. Keys and owners are replaced by salted hashes,
+// so that a recording keeps which requests shared a key or an owner without
+// revealing either. Offset is the time since the recording started.
+type Record struct {
+	Offset            int64  `json:"offset_ns"`
+	Method            string `json:"method"`
+	KeyHash           string `json:"key_hash,omitempty"`
+	OwnerHash         string `json:"owner_hash,omitempty"`
+	TypeCode          int32  `json:"type_code,omitempty"`
+	TtlInMilliseconds int64  `json:"ttl_in_milliseconds,omitempty"`
+	Duration          int64  `json:"duration_ns"`
+	Status            string `json:"status"`
+}
+
+// Recorder writes a Record for every Lock, Release, Fetch and FetchAll call
+// it intercepts, one JSON document per line. The interceptor only queues the
+// records, and Run writes them, so that a slow disk never holds up a call.
+// When the writer falls a full queue behind, new records are dropped and
+// counted rather than waited for. Once the output reaches its maximum size,
+// recording stops.
+type Recorder struct {
+	logger lager.Logger
+	clock  clock.Clock
+	salt   []byte
+	start  time.Time
+
+	queue    chan *Record
+	dropped  int64
+	w        io.Writer
+	written  int64
+	maxBytes int64
+}
+
+// NewFileRecorder returns a Recorder that appends to the file at path until
+// the file holds maxSizeInMegabytes, or DefaultMaxSizeInMegabytes when it is
+// 0.
+func NewFileRecorder(logger lager.Logger, clock clock.Clock, path string, maxSizeInMegabytes int) (*Recorder, error) {
+	if maxSizeInMegabytes <= 0 {
+		maxSizeInMegabytes = DefaultMaxSizeInMegabytes
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r, err := NewRecorder(logger, clock, file, int64(maxSizeInMegabytes)*1024*1024)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.written = info.Size()
+	return r, nil
+}
+
+// NewRecorder returns a Recorder that writes up to maxBytes to w, or
+// without a limit when maxBytes is 0. Every Recorder hashes with a new
+// random salt, so hashes cannot be matched across recordings.
+func NewRecorder(logger lager.Logger, clock clock.Clock, w io.Writer, maxBytes int64) (*Recorder, error) {
+	salt := make([]byte, 32)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		logger:   logger.Session("traffic-recorder"),
+		clock:    clock,
+		salt:     salt,
+		start:    clock.Now(),
+		queue:    make(chan *Record, queueSize),
+		w:        w,
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// Run writes the queued records until it is signalled, then writes the
+// records still queued and closes the output if it is a file.
+func (r *Recorder) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	if closer, ok := r.w.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	close(ready)
+
+	for {
+		select {
+		case record := <-r.queue:
+			r.write(logger, record)
+		case sig := <-signals:
+			logger.Info("signalled", lager.Data{"signal": sig})
+			for {
+				select {
+				case record := <-r.queue:
+					r.write(logger, record)
+				default:
+					r.logDropped(logger)
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) write(logger lager.Logger, record *Record) {
+	r.logDropped(logger)
+
+	if r.maxBytes > 0 && r.written >= r.maxBytes {
+		return
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		logger.Error("failed-to-marshal-record", err)
+		return
+	}
+	line = append(line, '\n')
+
+	if r.maxBytes > 0 && r.written+int64(len(line)) > r.maxBytes {
+		logger.Info("recording-full", lager.Data{"max-bytes": r.maxBytes})
+		r.written = r.maxBytes
+		return
+	}
+
+	n, err := r.w.Write(line)
+	r.written += int64(n)
+	if err != nil {
+		logger.Error("failed-to-write-record", err)
+	}
+}
+
+// logDropped logs how many records were dropped since it was last called,
+// so that a burst of drops is logged once rather than once per record.
+func (r *Recorder) logDropped(logger lager.Logger) {
+	dropped := atomic.SwapInt64(&r.dropped, 0)
+	if dropped > 0 {
+		logger.Info("dropped-records", lager.Data{"count": dropped})
+	}
+}
+
+// NewInterceptor returns a unary interceptor that records the calls it
+// handles.
+func (r *Recorder) NewInterceptor() grpc.UnaryServerInterceptor {
+	return r.intercept
+}
+
+func (r *Recorder) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := r.clock.Now()
+	resp, err := handler(ctx, req)
+
+	record, ok := r.newRecord(info.FullMethod, req)
+	if !ok {
+		return resp, err
+	}
+	record.Offset = start.Sub(r.start).Nanoseconds()
+	record.Duration = r.clock.Since(start).Nanoseconds()
+	record.Status = grpc.Code(err).String()
+
+	select {
+	case r.queue <- record:
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+	}
+
+	return resp, err
+}
+
+func (r *Recorder) newRecord(fullMethod string, req interface{}) (*Record, bool) {
+	record := &Record{Method: path.Base(fullMethod)}
+
+	switch req := req.(type) {
+	case *models.LockRequest:
+		r.recordResource(record, req.GetResource())
+		record.TtlInMilliseconds = req.GetTtlInMilliseconds()
+		if record.TtlInMilliseconds == 0 {
+			record.TtlInMilliseconds = req.GetTtlInSeconds() * 1000
+		}
+	case *v2.LockRequest:
+		r.recordV2Resource(record, req.GetResource())
+		record.TtlInMilliseconds = req.GetTtlInMilliseconds()
+	case *models.ReleaseRequest:
+		r.recordResource(record, req.GetResource())
+	case *v2.ReleaseRequest:
+		r.recordV2Resource(record, req.GetResource())
+	case *models.FetchRequest:
+		record.KeyHash = r.hash(req.GetKey())
+	case *v2.FetchRequest:
+		record.KeyHash = r.hash(req.GetKey())
+	case *models.FetchAllRequest:
+		record.TypeCode = int32(req.GetTypeCode())
+	case *v2.FetchAllRequest:
+		record.TypeCode = int32(req.GetTypeCode())
+	default:
+		return nil, false
+	}
+
+	switch record.Method {
+	case LockMethod, ReleaseMethod, FetchMethod, FetchAllMethod:
+		return record, true
+	default:
+		// Acquire shares LockRequest, but blocks, so it cannot be replayed as a lock
+		return nil, false
+	}
+}
+
+func (r *Recorder) recordResource(record *Record, resource *models.Resource) {
+	record.KeyHash = r.hash(resource.GetKey())
+	record.OwnerHash = r.hash(resource.GetOwner())
+	record.TypeCode = int32(resource.GetTypeCode())
+}
+
+func (r *Recorder) recordV2Resource(record *Record, resource *v2.Resource) {
+	record.KeyHash = r.hash(resource.GetKey())
+	record.OwnerHash = r.hash(resource.GetOwner())
+	record.TypeCode = int32(resource.GetTypeCode())
+}
+
+func (r *Recorder) hash(s string) string {
+	h := sha256.New()
+	h.Write(r.salt)
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,133 @@
+package traffic_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/v2"
+	"code.cloudfoundry.org/locket/traffic"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		fakeClock   *fakeclock.FakeClock
+		out         *bytes.Buffer
+		maxBytes    int64
+		interceptor grpc.UnaryServerInterceptor
+		process     ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		out = &bytes.Buffer{}
+		maxBytes = 0
+	})
+
+	JustBeforeEach(func() {
+		recorder, err := traffic.NewRecorder(lagertest.NewTestLogger("traffic"), fakeClock, out, maxBytes)
+		Expect(err).NotTo(HaveOccurred())
+		interceptor = recorder.NewInterceptor()
+		process = ginkgomon.Invoke(recorder)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	call := func(method string, req interface{}, handlerErr error) {
+		fakeClock.Increment(time.Second)
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			fakeClock.Increment(5 * time.Millisecond)
+			return nil, handlerErr
+		})
+		if handlerErr == nil {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(Equal(handlerErr))
+		}
+	}
+
+	// records stops the recorder, which writes the records still queued, and
+	// reads what it wrote
+	records := func() []*traffic.Record {
+		ginkgomon.Interrupt(process)
+		records, err := traffic.ReadRecords(bytes.NewReader(out.Bytes()))
+		Expect(err).NotTo(HaveOccurred())
+		return records
+	}
+
+	It("records the method, timing and status of each call", func() {
+		call("/models.Locket/Lock", &models.LockRequest{
+			Resource:     &models.Resource{Key: "auctioneer", Owner: "auctioneer-0", TypeCode: models.LOCK},
+			TtlInSeconds: 15,
+		}, nil)
+		call("/models.Locket/Fetch", &models.FetchRequest{Key: "auctioneer"}, models.ErrResourceNotFound)
+
+		recorded := records()
+		Expect(recorded).To(HaveLen(2))
+
+		Expect(recorded[0].Method).To(Equal(traffic.LockMethod))
+		Expect(recorded[0].Offset).To(Equal(int64(time.Second)))
+		Expect(recorded[0].Duration).To(Equal(int64(5 * time.Millisecond)))
+		Expect(recorded[0].TtlInMilliseconds).To(Equal(int64(15000)))
+		Expect(recorded[0].TypeCode).To(Equal(int32(models.LOCK)))
+		Expect(recorded[0].Status).To(Equal("OK"))
+
+		Expect(recorded[1].Method).To(Equal(traffic.FetchMethod))
+		Expect(recorded[1].Offset).To(Equal(int64(2*time.Second + 5*time.Millisecond)))
+		Expect(recorded[1].Status).To(Equal("NotFound"))
+		Expect(recorded[1].KeyHash).To(Equal(recorded[0].KeyHash))
+	})
+
+	It("replaces keys and owners with hashes", func() {
+		call("/models.Locket/Lock", &models.LockRequest{
+			Resource:     &models.Resource{Key: "auctioneer", Owner: "auctioneer-0"},
+			TtlInSeconds: 15,
+		}, nil)
+		call("/locket.v2.Locket/Release", &v2.ReleaseRequest{
+			Resource: &v2.Resource{Key: "bbs", Owner: "auctioneer-0"},
+		}, nil)
+
+		recorded := records()
+		Expect(out.String()).NotTo(ContainSubstring("auctioneer"))
+		Expect(out.String()).NotTo(ContainSubstring("bbs"))
+
+		Expect(recorded[0].KeyHash).NotTo(BeEmpty())
+		Expect(recorded[1].KeyHash).NotTo(Equal(recorded[0].KeyHash))
+		Expect(recorded[1].OwnerHash).To(Equal(recorded[0].OwnerHash))
+	})
+
+	It("does not record calls that cannot be replayed", func() {
+		call("/models.Locket/Acquire", &models.LockRequest{Resource: &models.Resource{Key: "key"}}, nil)
+		call("/models.Locket/Stats", &models.StatsRequest{}, errors.New("boom"))
+
+		Expect(records()).To(BeEmpty())
+	})
+
+	Context("when the output has a maximum size", func() {
+		BeforeEach(func() {
+			maxBytes = 300
+		})
+
+		It("stops recording once another record would not fit", func() {
+			for i := 0; i < 5; i++ {
+				call("/models.Locket/Fetch", &models.FetchRequest{Key: "auctioneer"}, nil)
+			}
+
+			recorded := records()
+			Expect(recorded).NotTo(BeEmpty())
+			Expect(len(recorded)).To(BeNumerically("<", 5))
+			Expect(out.Len()).To(BeNumerically("<=", 300))
+		})
+	})
+})
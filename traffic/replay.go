@@ -0,0 +1,208 @@
+package traffic
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/loadgen"
+	"code.cloudfoundry.org/locket/models"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var ErrInvalidConfig = errors.New("invalid replay config")
+
+// ReplayConfig describes how to replay a recording. Speed scales the pace of
+// the recording, so that 2 replays it twice as fast. Keys and owners are the
+// recorded hashes prefixed with KeyPrefix, and each request times out after
+// Timeout, if it is set.
+type ReplayConfig struct {
+	Speed     float64
+	KeyPrefix string
+	Timeout   time.Duration
+}
+
+// Validate returns ErrInvalidConfig if c cannot replay a recording.
+func (c ReplayConfig) Validate() error {
+	if c.Speed <= 0 || c.Timeout < 0 {
+		return ErrInvalidConfig
+	}
+	return nil
+}
+
+// MethodReport summarizes the replayed calls of one method. Mismatches counts
+// the calls that ended with a different status than the recorded one.
+type MethodReport struct {
+	Requests        int             `json:"requests"`
+	Errors          int             `json:"errors"`
+	Mismatches      int             `json:"mismatches"`
+	RecordedLatency loadgen.Latency `json:"recorded_latency"`
+	Latency         loadgen.Latency `json:"latency"`
+}
+
+// Report summarizes a replay. Skipped counts records of methods that cannot
+// be replayed.
+type Report struct {
+	DurationInSeconds float64                  `json:"duration_in_seconds"`
+	Requests          int                      `json:"requests"`
+	Skipped           int                      `json:"skipped"`
+	Methods           map[string]*MethodReport `json:"methods"`
+}
+
+// ReadRecords reads the records written by a Recorder, ordered by offset.
+func ReadRecords(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	decoder := json.NewDecoder(r)
+	for {
+		record := &Record{}
+		err := decoder.Decode(record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Offset < records[j].Offset })
+	return records, nil
+}
+
+// Replay makes the recorded calls against client, each at its offset scaled
+// by the speed, until the records run out or ctx is done. Calls run
+// concurrently, as they did when they were recorded.
+func Replay(logger lager.Logger, ctx context.Context, client models.LocketClient, records []*Record, cfg ReplayConfig, clock clock.Clock) (*Report, error) {
+	logger = logger.Session("replay", lager.Data{"records": len(records), "speed": cfg.Speed})
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("starting")
+	r := &replayer{
+		client:    client,
+		cfg:       cfg,
+		clock:     clock,
+		latencies: make(map[string][]time.Duration),
+		recorded:  make(map[string][]time.Duration),
+		report:    &Report{Methods: make(map[string]*MethodReport)},
+	}
+
+	start := clock.Now()
+	wg := sync.WaitGroup{}
+	for _, record := range records {
+		if !r.waitUntil(ctx, start.Add(time.Duration(float64(record.Offset)/cfg.Speed))) {
+			break
+		}
+
+		wg.Add(1)
+		go func(record *Record) {
+			defer wg.Done()
+			r.replay(ctx, record)
+		}(record)
+	}
+	wg.Wait()
+
+	report := r.report
+	report.DurationInSeconds = clock.Since(start).Seconds()
+	for method, methodReport := range report.Methods {
+		methodReport.Latency = loadgen.Percentiles(r.latencies[method])
+		methodReport.RecordedLatency = loadgen.Percentiles(r.recorded[method])
+	}
+	logger.Info("complete", lager.Data{"requests": report.Requests, "skipped": report.Skipped})
+	return report, nil
+}
+
+type replayer struct {
+	client models.LocketClient
+	cfg    ReplayConfig
+	clock  clock.Clock
+
+	mutex     sync.Mutex
+	latencies map[string][]time.Duration
+	recorded  map[string][]time.Duration
+	report    *Report
+}
+
+// waitUntil waits until t, returning false if ctx is done first.
+func (r *replayer) waitUntil(ctx context.Context, t time.Time) bool {
+	wait := t.Sub(r.clock.Now())
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := r.clock.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+func (r *replayer) replay(ctx context.Context, record *Record) {
+	if r.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+
+	resource := &models.Resource{
+		Key:      r.cfg.KeyPrefix + record.KeyHash,
+		Owner:    r.cfg.KeyPrefix + record.OwnerHash,
+		TypeCode: models.TypeCode(record.TypeCode),
+	}
+
+	start := r.clock.Now()
+	var err error
+	switch record.Method {
+	case LockMethod:
+		_, err = r.client.Lock(ctx, &models.LockRequest{
+			Resource:          resource,
+			TtlInSeconds:      (record.TtlInMilliseconds + 999) / 1000,
+			TtlInMilliseconds: record.TtlInMilliseconds,
+		})
+	case ReleaseMethod:
+		_, err = r.client.Release(ctx, &models.ReleaseRequest{Resource: resource})
+	case FetchMethod:
+		_, err = r.client.Fetch(ctx, &models.FetchRequest{Key: resource.Key})
+	case FetchAllMethod:
+		_, err = r.client.FetchAll(ctx, &models.FetchAllRequest{TypeCode: resource.TypeCode})
+	default:
+		r.mutex.Lock()
+		r.report.Skipped++
+		r.mutex.Unlock()
+		return
+	}
+	latency := r.clock.Since(start)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	methodReport, ok := r.report.Methods[record.Method]
+	if !ok {
+		methodReport = &MethodReport{}
+		r.report.Methods[record.Method] = methodReport
+	}
+
+	r.report.Requests++
+	methodReport.Requests++
+	if err != nil {
+		methodReport.Errors++
+	}
+	if grpc.Code(err).String() != record.Status {
+		methodReport.Mismatches++
+	}
+	r.latencies[record.Method] = append(r.latencies[record.Method], latency)
+	r.recorded[record.Method] = append(r.recorded[record.Method], time.Duration(record.Duration))
+}
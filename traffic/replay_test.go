@@ -0,0 +1,95 @@
+package traffic_test
+
+import (
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/locket/traffic"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Replay", func() {
+	var (
+		fakeClient *modelsfakes.FakeLocketClient
+		records    []*traffic.Record
+		cfg        traffic.ReplayConfig
+	)
+
+	BeforeEach(func() {
+		fakeClient = &modelsfakes.FakeLocketClient{}
+		fakeClient.LockReturns(&models.LockResponse{}, nil)
+		fakeClient.ReleaseReturns(&models.ReleaseResponse{}, nil)
+		fakeClient.FetchReturns(nil, models.ErrResourceNotFound)
+		fakeClient.FetchAllReturns(&models.FetchAllResponse{}, nil)
+
+		var err error
+		records, err = traffic.ReadRecords(strings.NewReader(`
+{"offset_ns":2000000,"method":"Release","key_hash":"k1","owner_hash":"o1","duration_ns":1000,"status":"OK"}
+{"offset_ns":0,"method":"Lock","key_hash":"k1","owner_hash":"o1","type_code":2,"ttl_in_milliseconds":15000,"duration_ns":1000,"status":"OK"}
+{"offset_ns":1000000,"method":"Fetch","key_hash":"k2","duration_ns":1000,"status":"OK"}
+{"offset_ns":3000000,"method":"Stats","duration_ns":1000,"status":"OK"}
+`))
+		Expect(err).NotTo(HaveOccurred())
+		cfg = traffic.ReplayConfig{Speed: 2, KeyPrefix: "replay-"}
+	})
+
+	It("orders records by offset", func() {
+		Expect(records[0].Method).To(Equal(traffic.LockMethod))
+		Expect(records[1].Method).To(Equal(traffic.FetchMethod))
+		Expect(records[2].Method).To(Equal(traffic.ReleaseMethod))
+	})
+
+	It("makes the recorded calls with prefixed keys and owners", func() {
+		report, err := traffic.Replay(lagertest.NewTestLogger("traffic"), context.Background(), fakeClient, records, cfg, clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.LockCallCount()).To(Equal(1))
+		_, lockReq, _ := fakeClient.LockArgsForCall(0)
+		Expect(lockReq.Resource).To(Equal(&models.Resource{Key: "replay-k1", Owner: "replay-o1", TypeCode: models.PRESENCE}))
+		Expect(lockReq.TtlInSeconds).To(Equal(int64(15)))
+		Expect(lockReq.TtlInMilliseconds).To(Equal(int64(15000)))
+
+		Expect(fakeClient.FetchCallCount()).To(Equal(1))
+		_, fetchReq, _ := fakeClient.FetchArgsForCall(0)
+		Expect(fetchReq.Key).To(Equal("replay-k2"))
+
+		Expect(fakeClient.ReleaseCallCount()).To(Equal(1))
+		_, releaseReq, _ := fakeClient.ReleaseArgsForCall(0)
+		Expect(releaseReq.Resource.Key).To(Equal("replay-k1"))
+
+		Expect(report.Requests).To(Equal(3))
+		Expect(report.Skipped).To(Equal(1))
+		Expect(report.Methods[traffic.FetchMethod].Errors).To(Equal(1))
+		Expect(report.Methods[traffic.FetchMethod].Mismatches).To(Equal(1))
+		Expect(report.Methods[traffic.LockMethod].Mismatches).To(Equal(0))
+		Expect(report.Methods[traffic.LockMethod].RecordedLatency.Max).To(Equal(0.001))
+	})
+
+	It("keeps the pace of the recording, scaled by the speed", func() {
+		cfg.Speed = 0.01
+		start := time.Now()
+		_, err := traffic.Replay(lagertest.NewTestLogger("traffic"), context.Background(), fakeClient, records, cfg, clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 300*time.Millisecond))
+	})
+
+	It("stops when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		report, err := traffic.Replay(lagertest.NewTestLogger("traffic"), ctx, fakeClient, records, cfg, clock.NewClock())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Requests).To(Equal(0))
+	})
+
+	It("rejects an invalid config", func() {
+		cfg.Speed = 0
+		_, err := traffic.Replay(lagertest.NewTestLogger("traffic"), context.Background(), fakeClient, records, cfg, clock.NewClock())
+		Expect(err).To(Equal(traffic.ErrInvalidConfig))
+	})
+})
@@ -0,0 +1 @@
+package vaultpki // import "code.cloudfoundry.org/locket/vaultpki"
@@ -0,0 +1,152 @@
+package vaultpki
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// RetryInterval is how long the rotator waits to try again after failing to
+// renew its certificate.
+const RetryInterval = 30 * time.Second
+
+// Rotator holds a certificate issued by Vault and replaces it once two
+// thirds of its lifetime has passed. Its GetCertificate and
+// GetClientCertificate are meant for a tls.Config, so that new connections
+// use the latest certificate while open ones carry on with theirs.
+//
+// Run it as an ifrit runner to renew in the background. Without it, the
+// certificate is renewed during the first handshake that finds it due.
+type Rotator struct {
+	logger lager.Logger
+	issuer Issuer
+	clock  clock.Clock
+
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	renewAt     time.Time
+	nextAttempt time.Time
+}
+
+// NewRotator issues the first certificate, and fails if it cannot.
+func NewRotator(logger lager.Logger, issuer Issuer, clock clock.Clock) (*Rotator, error) {
+	r := &Rotator{
+		logger: logger.Session("vault-pki"),
+		issuer: issuer,
+		clock:  clock,
+	}
+
+	err := r.renew(r.logger)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate for a server.
+func (r *Rotator) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+// GetClientCertificate returns the current certificate for a client.
+func (r *Rotator) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *Rotator) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("rotate")
+	logger.Info("started")
+	defer logger.Info("completed")
+
+	close(ready)
+
+	for {
+		timer := r.clock.NewTimer(r.untilRenewal())
+
+		select {
+		case sig := <-signals:
+			timer.Stop()
+			logger.Info("signalled", lager.Data{"signal": sig})
+			return nil
+		case <-timer.C():
+			r.mutex.Lock()
+			r.renewIfDue(logger)
+			r.mutex.Unlock()
+		}
+	}
+}
+
+func (r *Rotator) current() *tls.Certificate {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.renewIfDue(r.logger)
+	return r.certificate
+}
+
+func (r *Rotator) untilRenewal() time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	next := r.renewAt
+	if r.nextAttempt.After(next) {
+		next = r.nextAttempt
+	}
+
+	wait := next.Sub(r.clock.Now())
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// renewIfDue must be called with the mutex held. On failure it keeps the
+// current certificate and waits RetryInterval before trying again.
+func (r *Rotator) renewIfDue(logger lager.Logger) {
+	now := r.clock.Now()
+	if now.Before(r.renewAt) || now.Before(r.nextAttempt) {
+		return
+	}
+
+	err := r.renew(logger)
+	if err != nil {
+		logger.Error("failed-to-renew-certificate", err, lager.Data{"retry-in": RetryInterval.String()})
+		r.nextAttempt = now.Add(RetryInterval)
+	}
+}
+
+func (r *Rotator) renew(logger lager.Logger) error {
+	issued, err := r.issuer.Issue()
+	if err != nil {
+		return err
+	}
+
+	chain := append([]string{issued.Certificate}, issued.CAChain...)
+	keyPair, err := tls.X509KeyPair([]byte(strings.Join(chain, "\n")), []byte(issued.PrivateKey))
+	if err != nil {
+		return err
+	}
+
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return err
+	}
+	keyPair.Leaf = leaf
+
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+	r.certificate = &keyPair
+	r.renewAt = leaf.NotBefore.Add(lifetime * 2 / 3)
+
+	logger.Info("issued-certificate", lager.Data{
+		"serial":      leaf.SerialNumber.String(),
+		"not-after":   leaf.NotAfter,
+		"renew-at":    r.renewAt,
+		"common-name": leaf.Subject.CommonName,
+	})
+	return nil
+}
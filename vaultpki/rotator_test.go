@@ -0,0 +1,171 @@
+package vaultpki_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/vaultpki"
+	"code.cloudfoundry.org/locket/vaultpki/vaultpkifakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+var _ = Describe("Rotator", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		fakeIssuer *vaultpkifakes.FakeIssuer
+		serial     int64
+
+		rotator *vaultpki.Rotator
+		err     error
+	)
+
+	// issue returns a certificate valid for the next three hours, so that it
+	// is due for renewal in two.
+	issue := func() (vaultpki.Certificate, error) {
+		serial++
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "locket"},
+			NotBefore:    fakeClock.Now(),
+			NotAfter:     fakeClock.Now().Add(3 * time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+		Expect(err).NotTo(HaveOccurred())
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		return vaultpki.Certificate{
+			Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		}, nil
+	}
+
+	currentSerial := func() int64 {
+		cert, err := rotator.GetCertificate(&tls.ClientHelloInfo{})
+		Expect(err).NotTo(HaveOccurred())
+		return cert.Leaf.SerialNumber.Int64()
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("vaultpki")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeIssuer = &vaultpkifakes.FakeIssuer{}
+		fakeIssuer.IssueStub = issue
+		serial = 0
+	})
+
+	JustBeforeEach(func() {
+		rotator, err = vaultpki.NewRotator(logger, fakeIssuer, fakeClock)
+	})
+
+	It("issues the first certificate", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeIssuer.IssueCallCount()).To(Equal(1))
+		Expect(currentSerial()).To(Equal(int64(1)))
+
+		clientCert, err := rotator.GetClientCertificate(&tls.CertificateRequestInfo{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clientCert.Leaf.SerialNumber.Int64()).To(Equal(int64(1)))
+	})
+
+	Context("when the first certificate cannot be issued", func() {
+		BeforeEach(func() {
+			fakeIssuer.IssueStub = nil
+			fakeIssuer.IssueReturns(vaultpki.Certificate{}, errors.New("boom"))
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+
+	Context("when Vault returns an invalid certificate", func() {
+		BeforeEach(func() {
+			fakeIssuer.IssueStub = nil
+			fakeIssuer.IssueReturns(vaultpki.Certificate{Certificate: "garbage", PrivateKey: "garbage"}, nil)
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("without running", func() {
+		It("renews during the first handshake after two thirds of the lifetime", func() {
+			fakeClock.Increment(2*time.Hour - time.Second)
+			Expect(currentSerial()).To(Equal(int64(1)))
+
+			fakeClock.Increment(time.Second)
+			Expect(currentSerial()).To(Equal(int64(2)))
+			Expect(fakeIssuer.IssueCallCount()).To(Equal(2))
+		})
+
+		It("keeps the current certificate and waits to retry when renewal fails", func() {
+			fakeIssuer.IssueStub = nil
+			fakeIssuer.IssueReturns(vaultpki.Certificate{}, errors.New("vault is sealed"))
+
+			fakeClock.Increment(2 * time.Hour)
+			Expect(currentSerial()).To(Equal(int64(1)))
+			Expect(currentSerial()).To(Equal(int64(1)))
+			Expect(fakeIssuer.IssueCallCount()).To(Equal(2))
+			Expect(logger).To(gbytes.Say("failed-to-renew-certificate"))
+
+			fakeIssuer.IssueStub = issue
+			fakeClock.Increment(vaultpki.RetryInterval)
+			Expect(currentSerial()).To(Equal(int64(2)))
+		})
+	})
+
+	Context("when running", func() {
+		var process ifrit.Process
+
+		JustBeforeEach(func() {
+			Expect(err).NotTo(HaveOccurred())
+			process = ginkgomon.Invoke(rotator)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("renews in the background", func() {
+			fakeClock.WaitForWatcherAndIncrement(2 * time.Hour)
+			Eventually(fakeIssuer.IssueCallCount).Should(Equal(2))
+
+			fakeClock.WaitForWatcherAndIncrement(2 * time.Hour)
+			Eventually(fakeIssuer.IssueCallCount).Should(Equal(3))
+			Expect(currentSerial()).To(Equal(int64(3)))
+		})
+
+		It("retries after a failed renewal", func() {
+			fakeIssuer.IssueStub = nil
+			fakeIssuer.IssueReturns(vaultpki.Certificate{}, errors.New("vault is sealed"))
+
+			fakeClock.WaitForWatcherAndIncrement(2 * time.Hour)
+			Eventually(logger).Should(gbytes.Say("failed-to-renew-certificate"))
+
+			fakeIssuer.IssueStub = issue
+			fakeClock.WaitForWatcherAndIncrement(vaultpki.RetryInterval)
+			Eventually(fakeIssuer.IssueCallCount).Should(Equal(3))
+			Expect(currentSerial()).To(Equal(int64(2)))
+		})
+	})
+})
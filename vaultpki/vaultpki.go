@@ -0,0 +1,159 @@
+package vaultpki
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/durationjson"
+	vault "github.com/hashicorp/vault/api"
+)
+
+const DefaultMount = "pki"
+
+var (
+	ErrMissingRole       = errors.New("vault pki role is required")
+	ErrMissingCommonName = errors.New("vault pki common name is required")
+	ErrNoCertificate     = errors.New("vault did not return a certificate")
+)
+
+// Config describes where and how to request certificates from a Vault PKI
+// secrets engine. Requests are made with Token, or with a token from an
+// AppRole login when AppRoleID is set.
+type Config struct {
+	Address         string                `json:"address,omitempty" yaml:"address,omitempty"`
+	CACertFile      string                `json:"ca_cert_file,omitempty" yaml:"ca_cert_file,omitempty"`
+	Token           string                `json:"token,omitempty" yaml:"token,omitempty"`
+	AppRoleID       string                `json:"approle_role_id,omitempty" yaml:"approle_role_id,omitempty"`
+	AppRoleSecretID string                `json:"approle_secret_id,omitempty" yaml:"approle_secret_id,omitempty"`
+	Mount           string                `json:"mount,omitempty" yaml:"mount,omitempty"`
+	Role            string                `json:"role,omitempty" yaml:"role,omitempty"`
+	CommonName      string                `json:"common_name,omitempty" yaml:"common_name,omitempty"`
+	AltNames        []string              `json:"alt_names,omitempty" yaml:"alt_names,omitempty"`
+	IPSANs          []string              `json:"ip_sans,omitempty" yaml:"ip_sans,omitempty"`
+	TTL             durationjson.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// Enabled returns true if Vault is configured.
+func (c Config) Enabled() bool {
+	return c.Address != ""
+}
+
+// Certificate is a PEM encoded certificate issued by Vault, with its
+// private key and the chain of CAs that issued it.
+type Certificate struct {
+	Certificate string
+	PrivateKey  string
+	CAChain     []string
+}
+
+//go:generate counterfeiter . Issuer
+
+// Issuer issues a new certificate every time it is called.
+type Issuer interface {
+	Issue() (Certificate, error)
+}
+
+type issuer struct {
+	client *vault.Client
+	config Config
+}
+
+// NewIssuer returns an Issuer that requests certificates for the configured
+// names from the configured role.
+func NewIssuer(config Config) (Issuer, error) {
+	if config.Role == "" {
+		return nil, ErrMissingRole
+	}
+	if config.CommonName == "" {
+		return nil, ErrMissingCommonName
+	}
+	if config.Mount == "" {
+		config.Mount = DefaultMount
+	}
+
+	vaultConfig := vault.DefaultConfig()
+	vaultConfig.Address = config.Address
+	if config.CACertFile != "" {
+		err := vaultConfig.ConfigureTLS(&vault.TLSConfig{CACert: config.CACertFile})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := vault.NewClient(vaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(config.Token)
+
+	return &issuer{client: client, config: config}, nil
+}
+
+func (i *issuer) Issue() (Certificate, error) {
+	if i.config.AppRoleID != "" {
+		err := i.login()
+		if err != nil {
+			return Certificate{}, err
+		}
+	}
+
+	request := map[string]interface{}{
+		"common_name": i.config.CommonName,
+		"format":      "pem",
+	}
+	if len(i.config.AltNames) > 0 {
+		request["alt_names"] = strings.Join(i.config.AltNames, ",")
+	}
+	if len(i.config.IPSANs) > 0 {
+		request["ip_sans"] = strings.Join(i.config.IPSANs, ",")
+	}
+	if i.config.TTL > 0 {
+		request["ttl"] = fmt.Sprintf("%ds", int64(i.config.TTL.Seconds()))
+	}
+
+	secret, err := i.client.Logical().Write(i.config.Mount+"/issue/"+i.config.Role, request)
+	if err != nil {
+		return Certificate{}, err
+	}
+	if secret == nil || secret.Data == nil {
+		return Certificate{}, ErrNoCertificate
+	}
+
+	cert, _ := secret.Data["certificate"].(string)
+	key, _ := secret.Data["private_key"].(string)
+	if cert == "" || key == "" {
+		return Certificate{}, ErrNoCertificate
+	}
+
+	var chain []string
+	if caChain, ok := secret.Data["ca_chain"].([]interface{}); ok {
+		for _, ca := range caChain {
+			if pem, ok := ca.(string); ok {
+				chain = append(chain, pem)
+			}
+		}
+	} else if issuingCA, ok := secret.Data["issuing_ca"].(string); ok {
+		chain = append(chain, issuingCA)
+	}
+
+	return Certificate{Certificate: cert, PrivateKey: key, CAChain: chain}, nil
+}
+
+// login exchanges the AppRole credentials for a token. Certificates are
+// renewed rarely, so a fresh login for each one saves renewing the token.
+func (i *issuer) login() error {
+	secret, err := i.client.Logical().Write("auth/approle/login", map[string]interface{}{
+		"role_id":   i.config.AppRoleID,
+		"secret_id": i.config.AppRoleSecretID,
+	})
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Auth == nil {
+		return errors.New("vault approle login returned no token")
+	}
+
+	i.client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
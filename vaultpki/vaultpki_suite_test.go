@@ -0,0 +1,13 @@
+package vaultpki_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestVaultPKI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "VaultPKI Suite")
+}
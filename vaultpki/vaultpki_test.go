@@ -0,0 +1,172 @@
+package vaultpki_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/locket/vaultpki"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type vaultRequest struct {
+	path  string
+	token string
+	body  map[string]interface{}
+}
+
+var _ = Describe("Issuer", func() {
+	var (
+		server       *httptest.Server
+		requestsLock sync.Mutex
+		requests     []vaultRequest
+		issueData    map[string]interface{}
+
+		config vaultpki.Config
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		issueData = map[string]interface{}{
+			"certificate": "leaf",
+			"private_key": "key",
+			"ca_chain":    []string{"intermediate", "root"},
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+
+			requestsLock.Lock()
+			requests = append(requests, vaultRequest{path: r.URL.Path, token: r.Header.Get("X-Vault-Token"), body: body})
+			requestsLock.Unlock()
+
+			switch r.URL.Path {
+			case "/v1/auth/approle/login":
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"auth": map[string]interface{}{"client_token": "approle-token"},
+				})
+			default:
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": issueData})
+			}
+		}))
+
+		config = vaultpki.Config{
+			Address:    server.URL,
+			Token:      "static-token",
+			Role:       "locket-server",
+			CommonName: "locket.service.cf.internal",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("issues a certificate from the role", func() {
+		issuer, err := vaultpki.NewIssuer(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		cert, err := issuer.Issue()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert).To(Equal(vaultpki.Certificate{
+			Certificate: "leaf",
+			PrivateKey:  "key",
+			CAChain:     []string{"intermediate", "root"},
+		}))
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].path).To(Equal("/v1/pki/issue/locket-server"))
+		Expect(requests[0].token).To(Equal("static-token"))
+		Expect(requests[0].body).To(Equal(map[string]interface{}{
+			"common_name": "locket.service.cf.internal",
+			"format":      "pem",
+		}))
+	})
+
+	Context("when names, a ttl and a mount are configured", func() {
+		BeforeEach(func() {
+			config.Mount = "pki_int"
+			config.AltNames = []string{"a.locket", "b.locket"}
+			config.IPSANs = []string{"10.0.0.1"}
+			config.TTL = durationjson.Duration(time.Hour)
+		})
+
+		It("requests them", func() {
+			issuer, err := vaultpki.NewIssuer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = issuer.Issue()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requests[0].path).To(Equal("/v1/pki_int/issue/locket-server"))
+			Expect(requests[0].body).To(HaveKeyWithValue("alt_names", "a.locket,b.locket"))
+			Expect(requests[0].body).To(HaveKeyWithValue("ip_sans", "10.0.0.1"))
+			Expect(requests[0].body).To(HaveKeyWithValue("ttl", "3600s"))
+		})
+	})
+
+	Context("when Vault only returns the issuing CA", func() {
+		BeforeEach(func() {
+			delete(issueData, "ca_chain")
+			issueData["issuing_ca"] = "root"
+		})
+
+		It("uses it as the chain", func() {
+			issuer, err := vaultpki.NewIssuer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			cert, err := issuer.Issue()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.CAChain).To(Equal([]string{"root"}))
+		})
+	})
+
+	Context("when Vault returns no certificate", func() {
+		BeforeEach(func() {
+			delete(issueData, "certificate")
+		})
+
+		It("returns an error", func() {
+			issuer, err := vaultpki.NewIssuer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = issuer.Issue()
+			Expect(err).To(Equal(vaultpki.ErrNoCertificate))
+		})
+	})
+
+	Context("when an AppRole is configured", func() {
+		BeforeEach(func() {
+			config.Token = ""
+			config.AppRoleID = "role-id"
+			config.AppRoleSecretID = "secret-id"
+		})
+
+		It("logs in before issuing", func() {
+			issuer, err := vaultpki.NewIssuer(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = issuer.Issue()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0].path).To(Equal("/v1/auth/approle/login"))
+			Expect(requests[0].body).To(Equal(map[string]interface{}{"role_id": "role-id", "secret_id": "secret-id"}))
+			Expect(requests[1].path).To(Equal("/v1/pki/issue/locket-server"))
+			Expect(requests[1].token).To(Equal("approle-token"))
+		})
+	})
+
+	It("requires a role and a common name", func() {
+		_, err := vaultpki.NewIssuer(vaultpki.Config{Address: server.URL, CommonName: "locket"})
+		Expect(err).To(Equal(vaultpki.ErrMissingRole))
+
+		_, err = vaultpki.NewIssuer(vaultpki.Config{Address: server.URL, Role: "locket"})
+		Expect(err).To(Equal(vaultpki.ErrMissingCommonName))
+	})
+})
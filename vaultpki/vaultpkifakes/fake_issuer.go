@@ -0,0 +1,70 @@
+// This file was generated by counterfeiter
+package vaultpkifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/locket/vaultpki"
+)
+
+type FakeIssuer struct {
+	IssueStub        func() (vaultpki.Certificate, error)
+	issueMutex       sync.RWMutex
+	issueArgsForCall []struct {
+	}
+	issueReturns struct {
+		result1 vaultpki.Certificate
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIssuer) Issue() (vaultpki.Certificate, error) {
+	fake.issueMutex.Lock()
+	fake.issueArgsForCall = append(fake.issueArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Issue", []interface{}{})
+	fake.issueMutex.Unlock()
+	if fake.IssueStub != nil {
+		return fake.IssueStub()
+	} else {
+		return fake.issueReturns.result1, fake.issueReturns.result2
+	}
+}
+
+func (fake *FakeIssuer) IssueCallCount() int {
+	fake.issueMutex.RLock()
+	defer fake.issueMutex.RUnlock()
+	return len(fake.issueArgsForCall)
+}
+
+func (fake *FakeIssuer) IssueReturns(result1 vaultpki.Certificate, result2 error) {
+	fake.IssueStub = nil
+	fake.issueReturns = struct {
+		result1 vaultpki.Certificate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIssuer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.issueMutex.RLock()
+	defer fake.issueMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeIssuer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ vaultpki.Issuer = new(FakeIssuer)
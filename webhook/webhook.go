@@ -0,0 +1,292 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket/events"
+)
+
+const (
+	EventHeader     = "X-Locket-Event"
+	SignatureHeader = "X-Locket-Signature"
+
+	DefaultMaxRetries = 5
+
+	queueSize      = 1024
+	requestTimeout = 10 * time.Second
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+)
+
+var (
+	ErrInvalidURL    = errors.New("webhook url must be an https url")
+	ErrInvalidCACert = errors.New("webhook ca cert file has no certificates")
+)
+
+// Config is a webhook endpoint. Only events for keys starting with one of
+// KeyPrefixes are sent, or every event when there are none. When Secret is
+// set, each request is signed with it. A failed delivery is retried up to
+// MaxRetries times, DefaultMaxRetries if it is not set.
+type Config struct {
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	KeyPrefixes []string `json:"key_prefixes,omitempty"`
+	CACertFile  string   `json:"ca_cert_file,omitempty"`
+	MaxRetries  int      `json:"max_retries,omitempty"`
+}
+
+// Notifier is an events.Sink that POSTs each event as JSON to the webhook
+// endpoints whose filters match it. Each endpoint has its own queue and
+// receives its events in order. When an endpoint falls a full queue behind,
+// new events for it are dropped and logged rather than holding up locks.
+//
+// Run it as an ifrit runner to deliver the queued events. The outbox
+// dispatcher calls Deliver instead, which does not use the queues.
+type Notifier struct {
+	logger    lager.Logger
+	clock     clock.Clock
+	endpoints []*endpoint
+}
+
+type endpoint struct {
+	config Config
+	client *http.Client
+	queue  chan events.Event
+}
+
+var _ events.Deliverer = &Notifier{}
+
+func NewNotifier(logger lager.Logger, clock clock.Clock, configs []Config) (*Notifier, error) {
+	n := &Notifier{
+		logger: logger.Session("webhook"),
+		clock:  clock,
+	}
+
+	for _, config := range configs {
+		u, err := url.Parse(config.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, ErrInvalidURL
+		}
+
+		client, err := newClient(config.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.MaxRetries <= 0 {
+			config.MaxRetries = DefaultMaxRetries
+		}
+
+		n.endpoints = append(n.endpoints, &endpoint{
+			config: config,
+			client: client,
+			queue:  make(chan events.Event, queueSize),
+		})
+	}
+
+	return n, nil
+}
+
+func newClient(caCertFile string) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caCertFile != "" {
+		caCert, err := ioutil.ReadFile(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, ErrInvalidCACert
+		}
+	}
+
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+// Emit queues event for every endpoint whose filters match it.
+func (n *Notifier) Emit(event events.Event) {
+	for _, e := range n.endpoints {
+		if !e.matches(event.Key) {
+			continue
+		}
+
+		select {
+		case e.queue <- event:
+		default:
+			n.logger.Error("dropped-event", errors.New("queue is full"), lager.Data{"url": e.config.URL, "type": event.Type, "key": event.Key})
+		}
+	}
+}
+
+// Deliver POSTs event once to every endpoint whose filters match it, and
+// returns the first error. Failed deliveries are not retried, as the outbox
+// holds the event and delivers it again later.
+func (n *Notifier) Deliver(event events.Event) error {
+	logger := n.logger.Session("deliver-event", lager.Data{"type": event.Type, "key": event.Key})
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("failed-to-marshal-event", err)
+		return err
+	}
+
+	var firstErr error
+	for _, e := range n.endpoints {
+		if !e.matches(event.Key) {
+			continue
+		}
+
+		_, err := e.post(event.Type, body)
+		if err != nil {
+			logger.Error("failed-to-deliver-event", err, lager.Data{"url": e.config.URL})
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (n *Notifier) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := n.logger
+	logger.Info("started", lager.Data{"endpoints": len(n.endpoints)})
+	defer logger.Info("completed")
+
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for _, e := range n.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			n.deliverQueued(logger.Session("deliver", lager.Data{"url": e.config.URL}), e, done)
+		}(e)
+	}
+
+	close(ready)
+
+	sig := <-signals
+	logger.Info("signalled", lager.Data{"signal": sig})
+	close(done)
+	wg.Wait()
+	return nil
+}
+
+func (n *Notifier) deliverQueued(logger lager.Logger, e *endpoint, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case event := <-e.queue:
+			n.deliver(logger, e, event, done)
+		}
+	}
+}
+
+// deliver POSTs event to e, retrying with exponential backoff until it is
+// accepted, the endpoint rejects it, the retries run out or done is closed.
+func (n *Notifier) deliver(logger lager.Logger, e *endpoint, event events.Event, done <-chan struct{}) {
+	logger = logger.Session("event", lager.Data{"type": event.Type, "key": event.Key})
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		logger.Error("failed-to-marshal-event", err)
+		return
+	}
+
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := e.post(event.Type, body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= e.config.MaxRetries {
+			logger.Error("failed-to-deliver-event", err, lager.Data{"attempts": attempt + 1})
+			return
+		}
+		logger.Debug("retrying", lager.Data{"error": err.Error(), "attempt": attempt + 1})
+
+		timer := n.clock.NewTimer(backoff)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// post sends body to the endpoint, and reports whether a failure is worth
+// retrying. Client errors other than 429 are not, as sending the same
+// request again will not help.
+func (e *endpoint) post(eventType string, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", e.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	if e.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(e.config.Secret, body))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, err
+}
+
+func (e *endpoint) matches(key string) bool {
+	if len(e.config.KeyPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range e.config.KeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value for body: sha256= followed by the
+// hex encoded HMAC-SHA256 of body keyed with secret. Receivers should compute
+// it themselves and compare it with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
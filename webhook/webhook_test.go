@@ -0,0 +1,187 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/locket/events"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/webhook"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+)
+
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+var _ = Describe("Notifier", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		server     *httptest.Server
+		statuses   chan int
+		deliveries chan delivery
+		caCertFile string
+		configs    []webhook.Config
+		notifier   *webhook.Notifier
+		process    ifrit.Process
+		event      events.Event
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("webhook")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		statuses = make(chan int, 10)
+		deliveries = make(chan delivery, 10)
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			deliveries <- delivery{header: r.Header, body: body}
+
+			select {
+			case status := <-statuses:
+				w.WriteHeader(status)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+
+		caFile, err := ioutil.TempFile("", "webhook-ca")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})).To(Succeed())
+		Expect(caFile.Close()).To(Succeed())
+		caCertFile = caFile.Name()
+
+		configs = []webhook.Config{{
+			URL:         server.URL + "/hooks/locket",
+			Secret:      "some-secret",
+			KeyPrefixes: []string{"cell-"},
+			CACertFile:  caCertFile,
+			MaxRetries:  2,
+		}}
+
+		event = events.Event{
+			Type:      events.ExpiredEvent,
+			Key:       "cell-1",
+			Owner:     "rep-1",
+			LockType:  models.PresenceType,
+			Timestamp: 1234,
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		notifier, err = webhook.NewNotifier(logger, fakeClock, configs)
+		Expect(err).NotTo(HaveOccurred())
+		process = ginkgomon.Invoke(notifier)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+		server.Close()
+		Expect(os.Remove(caCertFile)).To(Succeed())
+	})
+
+	It("posts matching events, signed with the secret", func() {
+		notifier.Emit(event)
+
+		var d delivery
+		Eventually(deliveries).Should(Receive(&d))
+		Expect(d.header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(d.header.Get(webhook.EventHeader)).To(Equal(events.ExpiredEvent))
+		Expect(d.header.Get(webhook.SignatureHeader)).To(Equal(webhook.Sign("some-secret", d.body)))
+
+		var delivered events.Event
+		Expect(json.Unmarshal(d.body, &delivered)).To(Succeed())
+		Expect(delivered).To(Equal(event))
+	})
+
+	It("does not post events for other keys", func() {
+		event.Key = "auctioneer"
+		notifier.Emit(event)
+
+		Consistently(deliveries).ShouldNot(Receive())
+	})
+
+	It("retries server errors with backoff, up to the max retries", func() {
+		statuses <- http.StatusServiceUnavailable
+		statuses <- http.StatusInternalServerError
+		statuses <- http.StatusBadGateway
+		notifier.Emit(event)
+
+		Eventually(deliveries).Should(Receive())
+		Consistently(deliveries).ShouldNot(Receive())
+
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(deliveries).Should(Receive())
+
+		fakeClock.WaitForWatcherAndIncrement(2 * time.Second)
+		Eventually(deliveries).Should(Receive())
+
+		Eventually(logger).Should(gbytes.Say("failed-to-deliver-event"))
+		Consistently(deliveries).ShouldNot(Receive())
+	})
+
+	It("does not retry events the endpoint rejects", func() {
+		statuses <- http.StatusBadRequest
+		notifier.Emit(event)
+
+		Eventually(deliveries).Should(Receive())
+		Eventually(logger).Should(gbytes.Say("failed-to-deliver-event"))
+		Expect(fakeClock.WatcherCount()).To(Equal(0))
+	})
+
+	Describe("Deliver", func() {
+		It("posts the event once and reports that it was delivered", func() {
+			Expect(notifier.Deliver(event)).To(Succeed())
+
+			var d delivery
+			Expect(deliveries).To(Receive(&d))
+			Expect(d.header.Get(webhook.SignatureHeader)).To(Equal(webhook.Sign("some-secret", d.body)))
+		})
+
+		It("returns the error without retrying when the endpoint fails", func() {
+			statuses <- http.StatusServiceUnavailable
+
+			Expect(notifier.Deliver(event)).To(MatchError("webhook returned status 503"))
+			Expect(deliveries).To(Receive())
+			Consistently(deliveries).ShouldNot(Receive())
+			Expect(fakeClock.WatcherCount()).To(Equal(0))
+		})
+
+		It("delivers nothing for other keys", func() {
+			event.Key = "auctioneer"
+			Expect(notifier.Deliver(event)).To(Succeed())
+			Expect(deliveries).NotTo(Receive())
+		})
+	})
+
+	Context("when the endpoint is not https", func() {
+		It("fails to create the notifier", func() {
+			configs[0].URL = "http://hooks.example.com/locket"
+			_, err := webhook.NewNotifier(logger, fakeClock, configs)
+			Expect(err).To(Equal(webhook.ErrInvalidURL))
+		})
+	})
+
+	Context("when the ca cert file has no certificates", func() {
+		It("fails to create the notifier", func() {
+			Expect(ioutil.WriteFile(caCertFile, []byte("not a cert"), 0600)).To(Succeed())
+			_, err := webhook.NewNotifier(logger, fakeClock, configs)
+			Expect(err).To(Equal(webhook.ErrInvalidCACert))
+		})
+	})
+})